@@ -6,16 +6,26 @@ package main
 import (
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/arctir/devgraph-cli/pkg/commands"
 	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/arctir/devgraph-cli/pkg/logging"
+	"github.com/arctir/devgraph-cli/pkg/plugin"
+	"github.com/arctir/devgraph-cli/pkg/telemetry"
+	"github.com/arctir/devgraph-cli/pkg/timing"
 	"github.com/arctir/devgraph-cli/pkg/util"
+	"github.com/fatih/color"
 )
 
+// processStart is when the process started, for --timings
+var processStart = time.Now()
+
 // Version information (set at build time via ldflags)
 var (
 	Version = "dev"
@@ -23,26 +33,21 @@ var (
 	Date    = "unknown"
 )
 
-// VersionCommand displays version information
-type VersionCommand struct{}
-
-// Run executes the version command
-func (v *VersionCommand) Run() error {
-	fmt.Printf("devgraph version %s\n", Version)
-	if Commit != "none" {
-		fmt.Printf("  commit: %s\n", Commit)
-	}
-	if Date != "unknown" {
-		fmt.Printf("  built: %s\n", Date)
-	}
-	return nil
-}
-
 // CLI represents the main command-line interface structure for Devgraph CLI.
 // It defines all available commands and their subcommands using Kong command-line parser.
 type CLI struct {
+	// ProfileCPU and ProfileMem write pprof profiles of the command
+	ProfileCPU string `kong:"name='profile-cpu',type='path',hidden,help='Write a CPU profile of the command to a file'"`
+	ProfileMem string `kong:"name='profile-mem',type='path',hidden,help='Write a memory profile to a file after the command runs'"`
+	// Timings prints how long the command spent on each phase
+	Timings bool `kong:"help='Print how long the command spent on authentication, API requests and local work'"`
+
+	// API sends raw requests to the Devgraph API
+	API commands.APICommand `kong:"cmd,name='api',help='Send an authenticated request to any API path'"`
 	// Auth handles authentication with Devgraph accounts
 	Auth commands.AuthCommand `kong:"cmd,help='Manage authentication with your Devgraph account'"`
+	// Browse explores the catalog in a terminal UI
+	Browse commands.BrowseCommand `kong:"cmd,help='Explore entities and relations interactively'"`
 	// Chat provides interactive AI chat functionality
 	Chat commands.Chat `kong:"cmd,help='Start an interactive chat with AI'"`
 	// Complete is a hidden command for dynamic shell completions
@@ -51,12 +56,20 @@ type CLI struct {
 	Completion commands.CompletionCommand `kong:"cmd,help='Generate shell completion scripts'"`
 	// Config manages CLI configuration settings
 	Config commands.ConfigCommand `kong:"cmd,help='Manage configuration settings'"`
+	// Dashboard opens the web console for the current environment
+	Dashboard commands.DashboardCommand `kong:"cmd,help='Open the web console for the current environment or an entity'"`
+	// Docs generates reference documentation from the command definitions
+	Docs commands.DocsCommand `kong:"cmd,help='Generate man pages and Markdown reference docs'"`
 	// Entity manages entities within Devgraph
 	Entity commands.EntityCommand `kong:"cmd,help='Manage entities for Devgraph'"`
 	// EntityDefinition manages entity definitions
 	EntityDefinition commands.EntityDefinitionCommand `kong:"cmd,help='Manage entity definitions for Devgraph'"`
 	// Environment manages Devgraph environments
 	Environment commands.EnvironmentCommand `kong:"cmd,name='env',help='Manage environments for Devgraph'"`
+	// Export writes entities in other systems' catalog formats
+	Export commands.ExportCommand `kong:"cmd,help='Export entities to other catalogs'"`
+	// Import creates entities from other systems' catalogs
+	Import commands.ImportCommand `kong:"cmd,help='Import entities from other catalogs'"`
 	// MCP manages Model Context Protocol resources
 	MCP commands.MCPCommand `kong:"cmd,help='Manage MCP resources for Devgraph'"`
 	// Model manages AI models and configurations
@@ -65,20 +78,30 @@ type CLI struct {
 	ModelProvider commands.ModelProviderCommand `kong:"cmd,name='modelprovider',help='Manage Model Provider resources for Devgraph'"`
 	// OAuthService manages OAuth service configurations
 	OAuthService commands.OAuthServiceCommand `kong:"cmd,name='oauthservice',help='Manage OAuth services for Devgraph'"`
+	// Plugin inspects dg-* extension executables
+	Plugin commands.PluginCommand `kong:"cmd,help='Manage dg-* plugins'"`
+	// Prompt prints the current context for shell prompts
+	Prompt commands.PromptCommand `kong:"cmd,help='Print the current context and environment for a shell prompt'"`
 	// Provider manages discovery providers
 	Provider commands.ProviderCommand `kong:"cmd,help='Manage discovery providers'"`
+	// Query finds entities with a query language
+	Query commands.QueryCommand `kong:"cmd,help='Find entities with a query'"`
 	// Relation manages entity relations
 	Relation commands.RelationCommand `kong:"cmd,help='Manage entity relations'"`
 	// Subscription manages subscription information
 	Subscription commands.SubscriptionCommand `kong:"cmd,help='Manage subscriptions'"`
 	// Suggestion manages chat suggestions
 	Suggestion commands.SuggestionCommand `kong:"cmd,help='Manage chat suggestions'"`
+	// Telemetry manages anonymous usage reporting
+	Telemetry commands.TelemetryCommand `kong:"cmd,help='Manage anonymous usage telemetry'"`
 	// Token manages API tokens for Devgraph
 	Token commands.TokenCommand `kong:"cmd,help='Manage opaque tokens for Devgraph'"`
 	// User manages users in the current environment
 	User commands.UserCommand `kong:"cmd,help='Manage users in the current environment'"`
 	// Version displays version information
-	Version VersionCommand `kong:"cmd,help='Show version information'"`
+	Version commands.VersionCommand `kong:"cmd,help='Show CLI and server version information'"`
+	// Webhook receives webhook deliveries for developing consumers
+	Webhook commands.WebhookCommand `kong:"cmd,help='Receive webhook deliveries'"`
 }
 
 // main is the entry point for the Devgraph CLI application.
@@ -86,27 +109,54 @@ type CLI struct {
 // and executes the requested command.
 func main() {
 
+	// Windows consoles only show colors once asked to
+	if !util.EnableVirtualTerminal() {
+		color.NoColor = true
+	}
+
 	cli := CLI{}
 
 	// Parse command-line arguments using Kong
-	ctx := kong.Parse(&cli,
+	parser, err := kong.New(&cli,
 		kong.Name("dg"),
 		kong.Description("Turn chaos into clarity"),
 		kong.UsageOnError(),
+		kong.Bind(commands.BuildInfo{Version: Version, Commit: Commit, Date: Date}),
 		kong.ConfigureHelp(kong.HelpOptions{
 			Compact:             false,
 			NoExpandSubcommands: true,
 		}),
 	)
+	if err != nil {
+		panic(err)
+	}
+	ctx, err := parser.Parse(os.Args[1:])
+	if err != nil {
+		// Commands that aren't built in may be plugins
+		if path, args, ok := plugin.Find(os.Args[1:]); ok {
+			os.Exit(runPlugin(path, args))
+		}
+		parser.FatalIfErrorf(err)
+	}
 
 	// Apply defaults to embedded Config structs after parsing
+	var target interface{}
 	if cmd := ctx.Selected(); cmd != nil {
-		applyConfigDefaults(cmd.Target)
+		if cmd.Target.CanAddr() {
+			target = cmd.Target.Addr().Interface()
+		} else {
+			target = cmd.Target.Interface()
+		}
+		applyConfigDefaults(target)
+		if err := configureLogging(target); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
 	}
 
 	// Show first-time setup guidance for commands that need authentication
-	// Skip for help, auth, completion, complete, and version commands since they don't require full config
-	if ctx.Command() != "help" && ctx.Command() != "completion" && ctx.Command() != "version" && !strings.HasPrefix(ctx.Command(), "auth") && !strings.HasPrefix(ctx.Command(), "complete") {
+	// Skip for help, auth, completion, complete, docs, plugin, prompt, telemetry, and version commands since they don't require full config
+	if ctx.Command() != "help" && ctx.Command() != "completion" && ctx.Command() != "version" && ctx.Command() != "prompt" && !strings.HasPrefix(ctx.Command(), "docs") && !strings.HasPrefix(ctx.Command(), "auth") && !strings.HasPrefix(ctx.Command(), "complete") && !strings.HasPrefix(ctx.Command(), "plugin") && !strings.HasPrefix(ctx.Command(), "telemetry") {
 		if shouldShowFirstTimeSetup() {
 			showFirstTimeSetupMessage()
 			return // Don't proceed with the command
@@ -114,7 +164,35 @@ func main() {
 	}
 
 	// Execute the requested command
-	err := ctx.Run()
+	if cli.Timings {
+		timing.Enable()
+	}
+	stopProfiles, err := timing.StartProfiles(cli.ProfileCPU, cli.ProfileMem)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+	start := time.Now()
+	err = ctx.Run()
+	run := time.Since(start)
+	if profileErr := stopProfiles(); profileErr != nil {
+		logging.Warn(profileErr.Error())
+	}
+	if cli.Timings {
+		timing.Report(os.Stderr, start.Sub(processStart), run)
+	}
+	// Completions and prompts run constantly, so they aren't reported, and
+	// neither are the background sends of other commands' events
+	if !strings.HasPrefix(ctx.Command(), "complete") && ctx.Command() != "prompt" && !strings.HasPrefix(ctx.Command(), "telemetry send") {
+		exitStatus := 0
+		if err != nil && !errors.Is(err, util.ErrDryRun) {
+			exitStatus = 1
+		}
+		recordTelemetry(target, ctx.Command(), run, exitStatus)
+	}
+	if errors.Is(err, util.ErrDryRun) {
+		return
+	}
 	if err != nil {
 		// Check if this is a warning-type error
 		var noEnvErr *util.NoEnvironmentError
@@ -149,31 +227,107 @@ func showFirstTimeSetupMessage() {
 	fmt.Println("  dg --help")
 }
 
-// applyConfigDefaults walks the struct and applies defaults to any embedded config.Config
-func applyConfigDefaults(target interface{}) {
+// commandConfigs returns the config.Config structs in a command, including
+// those embedded through wrappers like EnvWrapperCommand
+func commandConfigs(target interface{}) []*config.Config {
 	v := reflect.ValueOf(target)
 	if v.Kind() == reflect.Ptr {
 		v = v.Elem()
 	}
 	if v.Kind() != reflect.Struct {
-		return
+		return nil
 	}
 
+	var configs []*config.Config
 	t := v.Type()
 	for i := 0; i < v.NumField(); i++ {
 		field := v.Field(i)
 		fieldType := t.Field(i)
+		if !field.CanAddr() {
+			continue
+		}
 
 		// Check if this field is config.Config or embeds it
-		if fieldType.Type == reflect.TypeOf(config.Config{}) && field.CanAddr() {
+		if fieldType.Type == reflect.TypeOf(config.Config{}) {
 			if cfg, ok := field.Addr().Interface().(*config.Config); ok {
-				cfg.ApplyDefaults()
-			}
-		} else if fieldType.Anonymous && fieldType.Type == reflect.TypeOf(config.Config{}) {
-			// Handle anonymous/embedded Config
-			if cfg, ok := field.Addr().Interface().(*config.Config); ok {
-				cfg.ApplyDefaults()
+				configs = append(configs, cfg)
 			}
+		} else if fieldType.Anonymous && field.Kind() == reflect.Struct {
+			configs = append(configs, commandConfigs(field.Addr().Interface())...)
+		}
+	}
+	return configs
+}
+
+// applyConfigDefaults walks the struct and applies defaults to any embedded config.Config
+func applyConfigDefaults(target interface{}) {
+	for _, cfg := range commandConfigs(target) {
+		cfg.ApplyDefaults()
+	}
+}
+
+// recordTelemetry sends an anonymous usage event for the command when the
+// user has opted in with 'dg telemetry enable'. A background 'dg telemetry
+// send' delivers it, so the command exits without waiting.
+func recordTelemetry(target interface{}, command string, duration time.Duration, exitStatus int) {
+	userConfig, err := config.LoadUserConfig()
+	if err != nil || !telemetry.Enabled(userConfig.Settings) {
+		return
+	}
+
+	cfg := config.Config{}
+	if configs := commandConfigs(target); len(configs) > 0 {
+		cfg = *configs[0]
+	} else {
+		cfg.ApplyDefaults()
+	}
+	if cfg.Offline {
+		return
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return
+	}
+
+	event := telemetry.NewEvent(command, duration, exitStatus, Version)
+	if err := telemetry.Start(executable, event); err != nil {
+		logging.Debug("failed to send telemetry", "error", err)
+	}
+}
+
+// runPlugin runs a plugin executable with the current context in its
+// environment and returns the exit code to use
+func runPlugin(path string, args []string) int {
+	cfg := config.Config{Retries: -1}
+	cfg.ApplyDefaults()
+
+	exitCode, err := plugin.Run(path, args, plugin.Environment(cfg))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ failed to run plugin %s: %v\n", path, err)
+	}
+	return exitCode
+}
+
+// configureLogging sets up the shared logger from the command's log flags.
+// Each -v lowers the level by one step, and --debug shows debug messages.
+func configureLogging(target interface{}) error {
+	configs := commandConfigs(target)
+	if len(configs) == 0 {
+		return nil
+	}
+	cfg := configs[0]
+
+	level := slog.LevelWarn
+	if cfg.LogLevel != "" {
+		var err error
+		if level, err = logging.ParseLevel(cfg.LogLevel); err != nil {
+			return err
 		}
 	}
+	level -= slog.Level(4 * cfg.Verbose)
+	if cfg.Debug || level < slog.LevelDebug {
+		level = slog.LevelDebug
+	}
+
+	return logging.Configure(os.Stderr, level, cfg.LogFormat)
 }
@@ -1,10 +1,21 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/alecthomas/kong"
+	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/arctir/devgraph-cli/pkg/logging"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCLIStructure(t *testing.T) {
@@ -37,6 +48,30 @@ func TestMain_Integration(t *testing.T) {
 	assert.NotPanics(t, func() {
 		_ = cli
 	}, "CLI initialization should not panic")
+
+	// Kong rejects the CLI when flags collide, e.g. a command flag with one
+	// of the global config flags
+	parser, err := kong.New(&cli, kong.Name("dg"))
+	require.NoError(t, err)
+
+	// Commands are named after their fields unless they say otherwise, and
+	// the root flags work after them
+	for _, args := range [][]string{
+		{"export", "backstage", "catalog"},
+		{"version", "--timings", "--profile-cpu", "cpu.pprof", "--profile-mem", "mem.pprof"},
+		{"prompt", "--format", "{context}"},
+		{"docs", "man", "man"},
+		{"import", "backstage", "catalog"},
+		{"import", "github", "--org", "acme"},
+		{"import", "kubernetes"},
+		{"import", "terraform", "terraform.tfstate"},
+		// README examples of reading stdin for file arguments
+		{"entity", "create", "core", "v1", "default", "services", "-"},
+		{"entity-definition", "import-crd", "-"},
+	} {
+		_, err := parser.Parse(args)
+		assert.NoError(t, err, args)
+	}
 }
 
 // Test CLI help descriptions
@@ -67,3 +102,73 @@ func TestCLIHelpDescriptions(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigureLogging(t *testing.T) {
+	t.Cleanup(func() { _ = logging.Configure(os.Stderr, slog.LevelWarn, logging.FormatText) })
+
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want slog.Level
+	}{
+		{"default", config.Config{LogLevel: "warn"}, slog.LevelWarn},
+		{"verbose", config.Config{LogLevel: "warn", Verbose: 1}, slog.LevelInfo},
+		{"very verbose", config.Config{LogLevel: "warn", Verbose: 3}, slog.LevelDebug},
+		{"log level", config.Config{LogLevel: "error"}, slog.LevelError},
+		{"debug", config.Config{LogLevel: "error", Debug: true}, slog.LevelDebug},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := struct{ config.Config }{tt.cfg}
+			require.NoError(t, configureLogging(&cmd))
+
+			logger := logging.Logger()
+			assert.True(t, logger.Enabled(context.Background(), tt.want))
+			assert.False(t, logger.Enabled(context.Background(), tt.want-1))
+		})
+	}
+}
+
+// TestMain runs the CLI instead of the tests when DG_TEST_MAIN is set, so
+// tests can start the test binary as dg, as recordTelemetry does
+func TestMain(m *testing.M) {
+	if os.Getenv("DG_TEST_MAIN") != "" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestRecordTelemetry(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("DO_NOT_TRACK", "")
+	t.Setenv("DEVGRAPH_TELEMETRY", "")
+	t.Setenv("DG_TEST_MAIN", "1")
+
+	requests := make(chan map[string]any, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/events", r.URL.Path)
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests <- body
+	}))
+	defer srv.Close()
+
+	// Nothing is sent until the user opts in
+	recordTelemetry(nil, "token list", time.Second, 0)
+
+	require.NoError(t, config.SaveUserConfig(&config.UserConfig{Settings: config.UserSettings{Telemetry: true, TelemetryEndpoint: srv.URL + "/events"}}))
+	start := time.Now()
+	recordTelemetry(nil, "entity get <id>", time.Second, 1)
+	assert.Less(t, time.Since(start), time.Second, "the command shouldn't wait for the event to be sent")
+
+	select {
+	case request := <-requests:
+		assert.Equal(t, "entity get", request["command"])
+		assert.Equal(t, float64(1), request["exit_status"])
+		assert.Equal(t, Version, request["version"])
+	case <-time.After(10 * time.Second):
+		t.Fatal("the background process didn't send the event")
+	}
+}
@@ -20,13 +20,40 @@ const DefaultRedirectURL = "http://localhost:40000/callback"
 
 // AuthenticatedClient creates an HTTP client configured with authentication
 // for making requests to Devgraph API. The client automatically handles
-// token refresh and includes required headers.
-func AuthenticatedClient(c config.Config) (*http.Client, error) {
-	// Get the default environment UUID from user settings
-	environment := ""
-	userConfig, err := config.LoadUserConfig()
-	if err == nil {
-		environment = userConfig.Settings.DefaultEnvironment
+// token refresh and includes required headers. Each wrap is applied to the
+// underlying transport, so wrapping transports see requests as they are sent,
+// including those to the identity provider.
+func AuthenticatedClient(c config.Config, wrap ...func(http.RoundTripper) http.RoundTripper) (*http.Client, error) {
+	tokenManager, err := newTokenManager(c, wrap...)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := tokenManager.HTTPClient()
+	httpClient.Timeout = c.RequestTimeout()
+	return httpClient, nil
+}
+
+// CurrentToken returns a valid token for the Devgraph API, refreshing and
+// saving the stored credentials if they have expired
+func CurrentToken(c config.Config) (*oauth2.Token, error) {
+	tokenManager, err := newTokenManager(c)
+	if err != nil {
+		return nil, err
+	}
+	return tokenManager.Token()
+}
+
+// newTokenManager returns a token manager for the stored credentials that
+// talks to the identity provider through c's transport and the wraps
+func newTokenManager(c config.Config, wrap ...func(http.RoundTripper) http.RoundTripper) (*OIDCTokenManager, error) {
+	// Get the default environment UUID from user settings unless the config
+	// targets a specific environment
+	environment := c.EnvOverride
+	if environment == "" {
+		userConfig, err := config.LoadUserConfig()
+		if err == nil {
+			environment = userConfig.Settings.DefaultEnvironment
+		}
 	}
 	// Note: For some operations like listing environments, environment may be empty
 	// We'll pass empty string if not set
@@ -36,7 +63,15 @@ func AuthenticatedClient(c config.Config) (*http.Client, error) {
 		return nil, fmt.Errorf("failed to load credentials: %w", err)
 	}
 
-	endpoints, err := getWellKnownEndpoints(c.IssuerURL)
+	client, err := c.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range wrap {
+		client.Transport = w(client.Transport)
+	}
+
+	endpoints, err := getWellKnownEndpoints(client, c.IssuerURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get well-known endpoints: %w", err)
 	}
@@ -67,17 +102,16 @@ func AuthenticatedClient(c config.Config) (*http.Client, error) {
 		Expiry:       expTime,
 	}
 
-	provider, err := oidc.NewProvider(context.Background(), c.IssuerURL)
+	provider, err := oidc.NewProvider(oidc.ClientContext(context.Background(), client), c.IssuerURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create OIDC provider: %w", err)
 	}
 
-	tokenManager := NewOIDCTokenManager(
+	return newOIDCTokenManager(
+		client,
 		oauth2Config,
 		token,
 		provider,
 		environment,
-	)
-	httpClient := tokenManager.HTTPClient()
-	return httpClient, nil
+	), nil
 }
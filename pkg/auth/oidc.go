@@ -8,6 +8,7 @@ import (
 	"net/url"
 
 	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/arctir/devgraph-cli/pkg/logging"
 	oidc "github.com/coreos/go-oidc/v3/oidc"
 	"github.com/int128/oauth2cli"
 	"github.com/int128/oauth2cli/oauth2params"
@@ -43,7 +44,7 @@ type WellKnownConfig struct {
 	// Add more fields as needed (e.g., "end_session_endpoint")
 }
 
-func getWellKnownEndpoints(issuerURL string) (*WellKnownConfig, error) {
+func getWellKnownEndpoints(client *http.Client, issuerURL string) (*WellKnownConfig, error) {
 	// Parse the issuer URL
 	u, err := url.Parse(issuerURL)
 	if err != nil {
@@ -54,7 +55,7 @@ func getWellKnownEndpoints(issuerURL string) (*WellKnownConfig, error) {
 	u.Path += "/.well-known/openid-configuration"
 
 	// Make the HTTP request
-	resp, err := http.Get(u.String())
+	resp, err := client.Get(u.String())
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch well-known config: %w", err)
 	}
@@ -96,18 +97,25 @@ func Logout(config config.Config) error {
 	}
 
 	// Get OIDC well-known configuration
-	wellKnown, err := getWellKnownEndpoints(config.IssuerURL)
+	client, err := config.HTTPClient()
 	if err != nil {
-		fmt.Printf("Warning: Could not retrieve logout endpoint: %v\n", err)
+		logging.Warn("could not create HTTP client", "error", err)
+		fmt.Println("Clearing local credentials...")
+		return ClearCredentials()
+	}
+
+	wellKnown, err := getWellKnownEndpoints(client, config.IssuerURL)
+	if err != nil {
+		logging.Warn("could not retrieve logout endpoint", "error", err)
 		fmt.Println("Clearing local credentials...")
 		return ClearCredentials()
 	}
 
 	// Try to call the OIDC end session endpoint if available
 	if endSessionURL := getEndSessionEndpoint(wellKnown); endSessionURL != "" {
-		err := callEndSessionEndpoint(endSessionURL, creds.IDToken, DefaultRedirectURL)
+		err := callEndSessionEndpoint(client, endSessionURL, creds.IDToken, DefaultRedirectURL)
 		if err != nil {
-			fmt.Printf("Warning: OIDC logout failed: %v\n", err)
+			logging.Warn("OIDC logout failed", "error", err)
 			fmt.Println("Clearing local credentials anyway...")
 		} else {
 			fmt.Println("Successfully logged out of OIDC provider.")
@@ -150,9 +158,7 @@ func getEndSessionEndpoint(wellKnown *WellKnownConfig) string {
 }
 
 // callEndSessionEndpoint calls the OIDC end session endpoint
-func callEndSessionEndpoint(endSessionURL, idToken, redirectURL string) error {
-	client := &http.Client{}
-
+func callEndSessionEndpoint(client *http.Client, endSessionURL, idToken, redirectURL string) error {
 	// Build logout URL with parameters
 	u, err := url.Parse(endSessionURL)
 	if err != nil {
@@ -250,13 +256,18 @@ type UserInfo struct {
 }
 
 func Authenticate(a config.Config) (*oauth2.Token, error) {
-	ctx := context.Background()
+	client, err := a.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	// The token exchange uses the client from the context
+	ctx := oidc.ClientContext(context.Background(), client)
 	ready := make(chan string, 1)
 	tokenChan := make(chan *oauth2.Token, 1)
 	defer close(ready)
 	defer close(tokenChan)
 
-	providerConfig, err := getWellKnownEndpoints(a.IssuerURL)
+	providerConfig, err := getWellKnownEndpoints(client, a.IssuerURL)
 	if err != nil {
 		fmt.Println("Error:", err)
 		return nil, err
@@ -384,7 +395,7 @@ func Authenticate(a config.Config) (*oauth2.Token, error) {
 			fmt.Println("Opening browser for authentication...")
 			fmt.Printf("URL: %s\n", url)
 			if err := browser.OpenURL(url); err != nil {
-				fmt.Printf("⚠️  Could not open browser automatically: %s\n", err)
+				logging.Warn("could not open browser automatically", "error", err)
 				fmt.Println("Please open the URL above manually in your browser.")
 			}
 			fmt.Println("⏳ Waiting for authentication to complete...")
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/arctir/devgraph-cli/pkg/timing"
 	"github.com/coreos/go-oidc/v3/oidc"
 	"golang.org/x/oauth2"
 )
@@ -51,18 +52,26 @@ type OIDCTokenManager struct {
 	tokenSrc            oauth2.TokenSource    // Underlying token source for refresh
 	oidcVerifier        *oidc.IDTokenVerifier // Optional: for ID token verification
 	devgraphEnvironment string                // Devgraph environment ID for API calls
+	transport           http.RoundTripper     // Base transport for API calls
 }
 
 // NewOIDCTokenManager creates a new token manager with the provided initial token.
 // It sets up automatic token refresh and optionally configures ID token verification
 // if an OIDC provider is provided.
 func NewOIDCTokenManager(config oauth2.Config, initialToken *oauth2.Token, provider *oidc.Provider, devgraphEnvironment string) *OIDCTokenManager {
-	ctx := context.Background()
+	return newOIDCTokenManager(http.DefaultClient, config, initialToken, provider, devgraphEnvironment)
+}
+
+// newOIDCTokenManager creates a token manager that refreshes tokens and makes
+// API calls through client's transport
+func newOIDCTokenManager(client *http.Client, config oauth2.Config, initialToken *oauth2.Token, provider *oidc.Provider, devgraphEnvironment string) *OIDCTokenManager {
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, client)
 	mgr := &OIDCTokenManager{
 		config:              config,
 		token:               initialToken,
 		tokenSrc:            oauth2.ReuseTokenSource(initialToken, config.TokenSource(ctx, initialToken)),
 		devgraphEnvironment: devgraphEnvironment,
+		transport:           client.Transport,
 	}
 	if provider != nil {
 		mgr.oidcVerifier = provider.Verifier(&oidc.Config{ClientID: config.ClientID})
@@ -72,6 +81,7 @@ func NewOIDCTokenManager(config oauth2.Config, initialToken *oauth2.Token, provi
 
 // Token implements oauth2.TokenSource, refreshing the token as needed
 func (m *OIDCTokenManager) Token() (*oauth2.Token, error) {
+	defer timing.Start(timing.Auth)()
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -124,8 +134,12 @@ func (m *OIDCTokenManager) GetCurrentToken() *oauth2.Token {
 
 // HTTPClient returns an HTTP client that auto-refreshes the token
 func (m *OIDCTokenManager) HTTPClient() *http.Client {
+	base := m.transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
 	transport := &DevgraphTransport{
-		Transport: http.DefaultTransport,
+		Transport: base,
 		Headers: map[string]string{
 			"Content-Type":         "application/json",
 			"Accept":               "application/json",
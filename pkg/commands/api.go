@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/arctir/devgraph-cli/pkg/util"
+)
+
+// APICommand sends an authenticated request to any API path, for endpoints
+// that don't have a command yet
+type APICommand struct {
+	EnvWrapperCommand
+	Method  string   `arg:"" help:"HTTP method (GET, POST, PUT, PATCH, DELETE)."`
+	Path    string   `arg:"" help:"API path including any query string, e.g. /api/v1/entities?limit=10."`
+	Data    string   `help:"Request body, or @file to read it from a file (@- for stdin)."`
+	Header  []string `short:"H" sep:"none" help:"Extra request header as 'Name: value'. Can be repeated."`
+	Include bool     `short:"i" help:"Print the response status and headers."`
+	Raw     bool     `help:"Print the response body as is instead of indenting JSON."`
+}
+
+func (a *APICommand) Run() error {
+	method := strings.ToUpper(a.Method)
+	if !strings.HasPrefix(a.Path, "/") {
+		return fmt.Errorf("path must start with '/'")
+	}
+
+	body, err := a.requestBody()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := util.ResolveContextConfig(a.Config)
+	if err != nil {
+		return err
+	}
+	client, err := util.GetAuthenticatedHTTPClient(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(context.Background(), method, strings.TrimSuffix(cfg.ApiURL, "/")+a.Path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	for _, header := range a.Header {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok {
+			return fmt.Errorf("invalid header '%s': expected 'Name: value'", header)
+		}
+		req.Header.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if a.Include {
+		fmt.Printf("%s %s\n", resp.Proto, resp.Status)
+		names := make([]string, 0, len(resp.Header))
+		for name := range resp.Header {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for _, value := range resp.Header[name] {
+				fmt.Printf("%s: %s\n", name, value)
+			}
+		}
+		fmt.Println()
+	}
+
+	var indented bytes.Buffer
+	if !a.Raw && json.Indent(&indented, respBody, "", "  ") == nil {
+		fmt.Println(strings.TrimSpace(indented.String()))
+	} else if len(respBody) > 0 {
+		os.Stdout.Write(respBody)
+		if !bytes.HasSuffix(respBody, []byte("\n")) {
+			fmt.Println()
+		}
+	}
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("request failed: %s", resp.Status)
+	}
+	return nil
+}
+
+// requestBody returns the body given with --data, reading it from a file or
+// stdin when it starts with @
+func (a *APICommand) requestBody() ([]byte, error) {
+	if a.Data == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(a.Data, "@") {
+		return []byte(a.Data), nil
+	}
+
+	data, err := util.ReadFileOrStdin(strings.TrimPrefix(a.Data, "@"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPICommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities", http.StatusOK, map[string]any{"primary_entities": []any{}})
+
+	cmd := APICommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Method: "get", Path: "/api/v1/entities?limit=10", Header: []string{"X-Trace: a, b"}}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"primary_entities\": []\n}\n", output)
+
+	request := srv.requireRequest(http.MethodGet, "/api/v1/entities")
+	assert.Equal(t, "limit=10", request.Query)
+	assert.Equal(t, "a, b", request.Header.Get("X-Trace"))
+	assert.Equal(t, "Bearer test-id-token", request.Header.Get("Authorization"))
+	assert.Equal(t, testEnvironmentID, request.Header.Get("Devgraph-Environment"))
+}
+
+func TestAPICommand_BodyFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "body.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"title": "Hello"}`), 0600))
+
+	srv, cfg := newTestAPI(t)
+	srv.handle("POST /api/v1/chat-suggestions", http.StatusUnprocessableEntity, map[string]any{"detail": "bad"})
+
+	cmd := APICommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Method: "POST", Path: "/api/v1/chat-suggestions", Data: "@" + path, Include: true}
+	output, err := captureOutput(t, cmd.Run)
+	assert.ErrorContains(t, err, "request failed: 422 Unprocessable Entity")
+	assert.Contains(t, output, "HTTP/1.1 422 Unprocessable Entity\n")
+	assert.Contains(t, output, "\nContent-Type: application/json\n")
+	assert.Contains(t, output, "\"detail\": \"bad\"")
+
+	request := srv.requireRequest(http.MethodPost, "/api/v1/chat-suggestions")
+	assert.Equal(t, "Hello", request.JSON(t)["title"])
+	assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+}
+
+func TestAPICommand_DebugFile(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/tokens", http.StatusOK, []any{})
+
+	cfg.DebugFile = filepath.Join(t.TempDir(), "debug.log")
+	cmd := APICommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Method: "GET", Path: "/api/v1/tokens"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.NotContains(t, output, "HTTP Request")
+
+	log, err := os.ReadFile(cfg.DebugFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(log), `"type":"request"`)
+	assert.Contains(t, string(log), `"url":"`+srv.server.URL+`/api/v1/tokens"`)
+	assert.Contains(t, string(log), `"Authorization":["[REDACTED]"]`)
+	assert.NotContains(t, string(log), "test-id-token")
+}
@@ -0,0 +1,208 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/arctir/devgraph-cli/pkg/logging"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// testEnvironmentID is the default environment of the config written by
+// newTestAPI
+const testEnvironmentID = "11111111-1111-1111-1111-111111111111"
+
+// apiRequest is a request received by a testAPI
+type apiRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   []byte
+}
+
+// JSON decodes the request body
+func (r apiRequest) JSON(t *testing.T) map[string]any {
+	t.Helper()
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(r.Body, &body), "request body: %s", r.Body)
+	return body
+}
+
+// testAPI is a fake Devgraph API for exercising commands end to end. It also
+// acts as the OIDC issuer so authenticated clients can be created against it.
+type testAPI struct {
+	t      *testing.T
+	server *httptest.Server
+	mux    *http.ServeMux
+
+	mu       sync.Mutex
+	requests []apiRequest
+}
+
+// newTestAPI starts a fake API and writes a user config with a context and
+// credentials for it to a temporary config directory. The returned config
+// points at the fake API.
+func newTestAPI(t *testing.T) (*testAPI, config.Config) {
+	t.Helper()
+	t.Cleanup(setupTempConfig(t))
+
+	srv := &testAPI{t: t, mux: http.NewServeMux()}
+	srv.server = httptest.NewServer(http.HandlerFunc(srv.serve))
+	t.Cleanup(srv.server.Close)
+
+	// A current context pointing at the fake API, so commands that apply
+	// config defaults themselves still talk to it
+	claims := jwt.MapClaims{"exp": float64(time.Now().Add(time.Hour).Unix())}
+	userConfig, err := config.LoadUserConfig()
+	require.NoError(t, err)
+	userConfig.SetCluster("test", srv.server.URL, srv.server.URL, "test-client")
+	userConfig.SetUser("test", "test-access-token", "", "test-id-token", &claims)
+	userConfig.SetContext("test", "test", "test", testEnvironmentID)
+	require.NoError(t, userConfig.UseContext("test"))
+	userConfig.Settings.DefaultEnvironment = testEnvironmentID
+	require.NoError(t, config.SaveUserConfig(userConfig))
+
+	require.NoError(t, config.SaveCredentials(config.Credentials{
+		AccessToken: "test-access-token",
+		IDToken:     "test-id-token",
+		Claims:      &claims,
+	}))
+
+	return srv, config.Config{
+		ApiURL:    srv.server.URL,
+		IssuerURL: srv.server.URL,
+		ClientID:  "test-client",
+	}
+}
+
+func (a *testAPI) serve(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/.well-known/openid-configuration" {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"issuer":                 a.server.URL,
+			"authorization_endpoint": a.server.URL + "/authorize",
+			"token_endpoint":         a.server.URL + "/token",
+			"jwks_uri":               a.server.URL + "/jwks",
+		})
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	a.mu.Lock()
+	a.requests = append(a.requests, apiRequest{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	a.mu.Unlock()
+
+	a.mux.ServeHTTP(w, r)
+}
+
+// handle responds to requests matching pattern (e.g. "GET /api/v1/tokens")
+// with status and body encoded as JSON. A nil body sends no content.
+func (a *testAPI) handle(pattern string, status int, body any) {
+	a.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, status, body)
+	})
+}
+
+// received returns the requests made with the given method and path
+func (a *testAPI) received(method, path string) []apiRequest {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var matched []apiRequest
+	for _, r := range a.requests {
+		if r.Method == method && r.Path == path {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// requireRequest asserts exactly one request was made with the given method
+// and path and returns it
+func (a *testAPI) requireRequest(method, path string) apiRequest {
+	a.t.Helper()
+	matched := a.received(method, path)
+	require.Len(a.t, matched, 1, "expected one %s %s request", method, path)
+	return matched[0]
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	if body == nil {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// captureLogs sends warnings and errors logged during the test to the
+// returned buffer
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var logs bytes.Buffer
+	require.NoError(t, logging.Configure(&logs, slog.LevelWarn, logging.FormatText))
+	t.Cleanup(func() {
+		_ = logging.Configure(os.Stderr, slog.LevelWarn, logging.FormatText)
+	})
+	return &logs
+}
+
+// captureOutput runs fn and returns what it printed to stdout
+func captureOutput(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	old := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = old }()
+
+	output := make(chan string)
+	go func() {
+		var b strings.Builder
+		_, _ = io.Copy(&b, r)
+		output <- b.String()
+	}()
+
+	runErr := fn()
+	w.Close()
+	os.Stdout = old
+	return <-output, runErr
+}
+
+// withStdin makes input the process's stdin until the test ends
+func withStdin(t *testing.T, input string) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	go func() {
+		_, _ = w.WriteString(input)
+		w.Close()
+	}()
+
+	old := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = old
+		r.Close()
+	})
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/arctir/devgraph-cli/pkg/auth"
 	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/arctir/devgraph-cli/pkg/logging"
 	"github.com/arctir/devgraph-cli/pkg/util"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -229,8 +230,12 @@ func (a *AuthLoginCommand) Run() error {
 	} else if a.Cluster != "" {
 		// Use explicitly specified cluster
 		a.Config.ApiURL = a.Cluster
+		httpClient, err := a.Config.HTTPClient()
+		if err != nil {
+			return err
+		}
 		fmt.Printf("Fetching OIDC configuration from %s...\n", a.Cluster)
-		issuerURL, clientID, err := config.FetchOIDCConfig(a.Cluster)
+		issuerURL, clientID, err := config.FetchOIDCConfig(httpClient, a.Cluster)
 		if err != nil {
 			return fmt.Errorf("failed to fetch OIDC configuration: %w", err)
 		}
@@ -240,11 +245,15 @@ func (a *AuthLoginCommand) Run() error {
 		// Default to production
 		prodConfig := config.EnvironmentConfigMap["production"]
 		a.Config.ApiURL = prodConfig.ApiURL
+		httpClient, err := a.Config.HTTPClient()
+		if err != nil {
+			return err
+		}
 		fmt.Printf("Fetching OIDC configuration from %s...\n", prodConfig.ApiURL)
-		issuerURL, clientID, err := config.FetchOIDCConfig(prodConfig.ApiURL)
+		issuerURL, clientID, err := config.FetchOIDCConfig(httpClient, prodConfig.ApiURL)
 		if err != nil {
 			// Fall back to hardcoded values if API is unavailable
-			fmt.Printf("⚠️  Could not fetch OIDC config from API, using defaults: %v\n", err)
+			logging.Warn("could not fetch OIDC config from API, using defaults", "error", err)
 			a.Config.IssuerURL = prodConfig.IssuerURL
 			a.Config.ClientID = prodConfig.ClientID
 		} else {
@@ -306,7 +315,7 @@ func (a *AuthLoginCommand) Run() error {
 	// Auto-configure environment after successful login
 	fmt.Println("🌍 Setting up your environment...")
 	if err := configureEnvironmentAfterLogin(a.Config); err != nil {
-		fmt.Printf("⚠️  Could not configure environment: %v\n", err)
+		logging.Warn("could not configure environment", "error", err)
 		fmt.Println("   You can set it later with: dg config set-context <name> --env <env>")
 	}
 	fmt.Println()
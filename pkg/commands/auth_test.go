@@ -18,6 +18,7 @@ func setupTempConfig(t *testing.T) func() {
 	originalXDG := os.Getenv("XDG_CONFIG_HOME")
 	tempDir := t.TempDir()
 	os.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
 	return func() {
 		if originalXDG == "" {
 			os.Unsetenv("XDG_CONFIG_HOME")
@@ -0,0 +1,656 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
+	"github.com/arctir/devgraph-cli/pkg/util"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+	"github.com/fatih/color"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+	"slices"
+)
+
+// BrowseCommand explores the catalog in a terminal UI: a fuzzy-searchable
+// entity list that can be narrowed to a namespace and kind, the selected
+// entity's details, and its relations, which can be followed to the entities
+// they point to
+type BrowseCommand struct {
+	EnvWrapperCommand
+	Label         string `flag:"label,l" help:"Only browse entities matching this label selector."`
+	FieldSelector string `flag:"field-selector,f" help:"Only browse entities matching this field selector."`
+	Namespace     string `help:"Start with the list narrowed to this namespace (n changes it while browsing)."`
+	Kind          string `help:"Start with the list narrowed to this kind (t changes it while browsing)."`
+}
+
+var reverse = color.New(color.ReverseVideo).SprintFunc()
+
+// catalogBrowser is the state of a browse session. It's driven by key names
+// from readKey and drawn by render, so it doesn't depend on a terminal.
+type catalogBrowser struct {
+	entities  []api.EntityResponse
+	index     map[string]int
+	relations map[string][]api.EntityRelationResponse
+
+	// namespaces and kinds are those of the entities, sorted, and
+	// namespace and kind narrow the list to one of each when set
+	namespaces []string
+	kinds      []string
+	namespace  string
+	kind       string
+
+	search    string
+	searching bool
+	// visible holds the indexes of the entities matching search, best
+	// match first, and cursor is the selected position in it
+	visible []int
+	cursor  int
+
+	// focusRelations is set when the relations pane has the keyboard
+	focusRelations bool
+	relCursor      int
+
+	// history holds the IDs of the entities relations were followed from
+	history []string
+	status  string
+	quit    bool
+}
+
+func (b *BrowseCommand) Run() error {
+	in, out := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+	if !term.IsTerminal(in) || !term.IsTerminal(out) {
+		return fmt.Errorf("dg browse needs an interactive terminal; use dg entity list or dg query in scripts")
+	}
+
+	client, err := util.GetAuthenticatedClient(b.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+	params := api.GetEntitiesParams{IncludeRelations: api.NewOptBool(true)}
+	if b.Label != "" {
+		params.Label = api.NewOptString(b.Label)
+	}
+	if b.FieldSelector != "" {
+		params.FieldSelector = api.NewOptString(b.FieldSelector)
+	}
+	br, err := loadCatalogBrowser(context.Background(), client, params)
+	if err != nil {
+		return err
+	}
+	if err := br.scope(b.Namespace, b.Kind); err != nil {
+		return err
+	}
+
+	state, err := term.MakeRaw(in)
+	if err != nil {
+		return fmt.Errorf("failed to set up terminal: %w", err)
+	}
+	defer func() { _ = term.Restore(in, state) }()
+
+	// Draw on the alternate screen, so the shell is left as it was
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	reader := bufio.NewReader(os.Stdin)
+	for !br.quit {
+		width, height, err := term.GetSize(out)
+		if err != nil {
+			width, height = 80, 24
+		}
+		fmt.Print("\x1b[H\x1b[2J" + strings.Join(br.render(width, height), "\r\n"))
+
+		key, err := readKey(reader)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		br.handleKey(key)
+	}
+	return nil
+}
+
+// loadCatalogBrowser fetches the entities matching params, with their
+// relations
+func loadCatalogBrowser(ctx context.Context, client *api.Client, params api.GetEntitiesParams) (*catalogBrowser, error) {
+	entities, relations, err := queryEntities(ctx, client, params, queryClause{})
+	if err != nil {
+		return nil, err
+	}
+	if len(entities) == 0 {
+		return nil, fmt.Errorf("no entities found")
+	}
+	return newCatalogBrowser(entities, relations), nil
+}
+
+func newCatalogBrowser(entities []api.EntityResponse, relations []api.EntityRelationResponse) *catalogBrowser {
+	b := &catalogBrowser{index: map[string]int{}, relations: map[string][]api.EntityRelationResponse{}}
+	for _, entity := range entities {
+		if _, ok := b.index[entity.ID]; !ok {
+			b.index[entity.ID] = len(b.entities)
+			b.entities = append(b.entities, entity)
+		}
+	}
+	sort.SliceStable(b.entities, func(i, j int) bool {
+		return browseLabel(b.entities[i]) < browseLabel(b.entities[j])
+	})
+	namespaces, kinds := map[string]bool{}, map[string]bool{}
+	for i, entity := range b.entities {
+		b.index[entity.ID] = i
+		namespaces[entity.Metadata.Namespace] = true
+		kinds[entity.Kind] = true
+	}
+	b.namespaces = sortedKeys(namespaces)
+	b.kinds = sortedKeys(kinds)
+
+	// Pages can repeat relations, so each is kept once per entity
+	seen := map[FilteredEntityRelation]bool{}
+	for _, rel := range relations {
+		key := FilteredEntityRelation{Relation: rel.Relation, Source: rel.Source.ID, Target: rel.Target.ID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		b.relations[rel.Source.ID] = append(b.relations[rel.Source.ID], rel)
+		if rel.Target.ID != rel.Source.ID {
+			b.relations[rel.Target.ID] = append(b.relations[rel.Target.ID], rel)
+		}
+	}
+
+	b.applySearch()
+	return b
+}
+
+// sortedKeys returns the keys of set, sorted
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// scope narrows the list to a namespace and kind, either of which may be
+// empty for all of them
+func (b *catalogBrowser) scope(namespace, kind string) error {
+	for _, check := range []struct {
+		name, value string
+		values      []string
+	}{{"namespace", namespace, b.namespaces}, {"kind", kind, b.kinds}} {
+		if check.value != "" && !slices.Contains(check.values, check.value) {
+			return fmt.Errorf("no entities found in %s %s", check.name, check.value)
+		}
+	}
+	b.namespace, b.kind = namespace, kind
+	b.applySearch()
+	return nil
+}
+
+// cycleScope returns the value after current in values, stepping by step,
+// with "" for all of them before the first
+func cycleScope(values []string, current string, step int) string {
+	options := append([]string{""}, values...)
+	i := slices.Index(options, current)
+	return options[((i+step)%len(options)+len(options))%len(options)]
+}
+
+// browseLabel is how an entity is listed, and what searches match
+func browseLabel(entity api.EntityResponse) string {
+	return fmt.Sprintf("%s %s/%s", entity.Kind, entity.Metadata.Namespace, entity.Metadata.Name)
+}
+
+// selected returns the selected entity, if any entity matches the search
+func (b *catalogBrowser) selected() (api.EntityResponse, bool) {
+	if len(b.visible) == 0 {
+		return api.EntityResponse{}, false
+	}
+	return b.entities[b.visible[b.cursor]], true
+}
+
+// applySearch lists the entities matching search and selects the best match
+func (b *catalogBrowser) applySearch() {
+	type match struct{ index, score int }
+	var matches []match
+	for i, entity := range b.entities {
+		if (b.namespace != "" && entity.Metadata.Namespace != b.namespace) || (b.kind != "" && entity.Kind != b.kind) {
+			continue
+		}
+		if score, ok := fuzzyScore(b.search, browseLabel(entity)); ok {
+			matches = append(matches, match{i, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	b.visible = b.visible[:0]
+	for _, m := range matches {
+		b.visible = append(b.visible, m.index)
+	}
+	b.cursor = 0
+	b.relCursor = 0
+	b.focusRelations = false
+}
+
+// fuzzyScore reports whether the characters of pattern appear in text in
+// order, ignoring case, and how well they match: runs of consecutive
+// characters and matches at the start of words score higher. Each place the
+// first character appears is tried as the start of the match.
+func fuzzyScore(pattern, text string) (int, bool) {
+	pattern, text = strings.ToLower(pattern), strings.ToLower(text)
+	if pattern == "" {
+		return 0, true
+	}
+	first, _ := utf8.DecodeRuneInString(pattern)
+	best, matched := 0, false
+	for start, c := range text {
+		if c != first {
+			continue
+		}
+		if score, ok := fuzzyScoreFrom(pattern, text, start); ok && (!matched || score > best) {
+			best, matched = score, true
+		}
+	}
+	return best, matched
+}
+
+// fuzzyScoreFrom matches pattern against text greedily, starting at start
+func fuzzyScoreFrom(pattern, text string, start int) (int, bool) {
+	score, previous, position := 0, -2, start
+	for _, p := range pattern {
+		found := strings.IndexRune(text[position:], p)
+		if found < 0 {
+			return 0, false
+		}
+		at := position + found
+		score++
+		if at == previous+1 {
+			score += 3
+		}
+		if at == 0 || strings.ContainsRune(" /-_.", rune(text[at-1])) {
+			score += 3
+		}
+		previous = at
+		position = at + utf8.RuneLen(p)
+	}
+	return score, true
+}
+
+// handleKey updates the browser for a key from readKey
+func (b *catalogBrowser) handleKey(key string) {
+	b.status = ""
+	if b.searching {
+		switch key {
+		case "ctrl+c":
+			b.quit = true
+		case "esc":
+			b.searching = false
+			b.search = ""
+			b.applySearch()
+		case "enter":
+			b.searching = false
+		case "backspace":
+			if b.search != "" {
+				_, size := utf8.DecodeLastRuneInString(b.search)
+				b.search = b.search[:len(b.search)-size]
+				b.applySearch()
+			}
+		case "up", "down":
+			b.move(key)
+		default:
+			if utf8.RuneCountInString(key) == 1 {
+				b.search += key
+				b.applySearch()
+			}
+		}
+		return
+	}
+
+	switch key {
+	case "q", "ctrl+c":
+		b.quit = true
+	case "/":
+		b.searching = true
+		b.focusRelations = false
+	case "up", "down", "k", "j", "home", "end", "g", "G", "pgup", "pgdown":
+		b.move(key)
+	case "tab":
+		if b.focusRelations || len(b.selectedRelations()) > 0 {
+			b.focusRelations = !b.focusRelations
+		}
+	case "enter", "right", "l":
+		if b.focusRelations {
+			b.follow()
+		} else if len(b.selectedRelations()) > 0 {
+			b.focusRelations = true
+			b.relCursor = 0
+		}
+	case "left", "h", "esc":
+		b.focusRelations = false
+	case "backspace", "b":
+		b.back()
+	case "n", "N":
+		step := 1
+		if key == "N" {
+			step = -1
+		}
+		b.namespace = cycleScope(b.namespaces, b.namespace, step)
+		b.applySearch()
+	case "t", "T":
+		step := 1
+		if key == "T" {
+			step = -1
+		}
+		b.kind = cycleScope(b.kinds, b.kind, step)
+		b.applySearch()
+	}
+}
+
+// move moves the selection of the focused pane
+func (b *catalogBrowser) move(key string) {
+	cursor, count := &b.cursor, len(b.visible)
+	if b.focusRelations {
+		cursor, count = &b.relCursor, len(b.selectedRelations())
+	}
+	switch key {
+	case "up", "k":
+		*cursor--
+	case "down", "j":
+		*cursor++
+	case "pgup":
+		*cursor -= 10
+	case "pgdown":
+		*cursor += 10
+	case "home", "g":
+		*cursor = 0
+	case "end", "G":
+		*cursor = count - 1
+	}
+	*cursor = max(0, min(*cursor, count-1))
+	if !b.focusRelations {
+		b.relCursor = 0
+	}
+}
+
+// selectedRelations returns the relations of the selected entity, outgoing
+// ones first
+func (b *catalogBrowser) selectedRelations() []api.EntityRelationResponse {
+	entity, ok := b.selected()
+	if !ok {
+		return nil
+	}
+	relations := append([]api.EntityRelationResponse(nil), b.relations[entity.ID]...)
+	sort.SliceStable(relations, func(i, j int) bool {
+		return relations[i].Source.ID == entity.ID && relations[j].Source.ID != entity.ID
+	})
+	return relations
+}
+
+// follow selects the entity at the other end of the selected relation
+func (b *catalogBrowser) follow() {
+	entity, ok := b.selected()
+	relations := b.selectedRelations()
+	if !ok || b.relCursor >= len(relations) {
+		return
+	}
+	rel := relations[b.relCursor]
+	other := rel.Target.ID
+	if other == entity.ID {
+		other = rel.Source.ID
+	}
+	if _, ok := b.index[other]; !ok {
+		b.status = fmt.Sprintf("%s isn't loaded; browse without filters to follow it", other)
+		return
+	}
+	b.history = append(b.history, entity.ID)
+	b.selectID(other)
+}
+
+// back returns to the entity the last relation was followed from
+func (b *catalogBrowser) back() {
+	if len(b.history) == 0 {
+		return
+	}
+	id := b.history[len(b.history)-1]
+	b.history = b.history[:len(b.history)-1]
+	b.selectID(id)
+}
+
+// selectID clears the search and selects the entity with the given ID,
+// widening the list to every namespace and kind if it's outside them
+func (b *catalogBrowser) selectID(id string) {
+	b.search = ""
+	if entity := b.entities[b.index[id]]; (b.namespace != "" && entity.Metadata.Namespace != b.namespace) || (b.kind != "" && entity.Kind != b.kind) {
+		b.namespace, b.kind = "", ""
+	}
+	b.applySearch()
+	for i, index := range b.visible {
+		if b.entities[index].ID == id {
+			b.cursor = i
+		}
+	}
+}
+
+// render draws the browser as lines of the given width, height lines tall
+func (b *catalogBrowser) render(width, height int) []string {
+	width, height = max(width, 40), max(height, 6)
+	bodyHeight := height - 2
+
+	header := fmt.Sprintf("%s  %d/%d entities", bold("dg browse"), len(b.visible), len(b.entities))
+	if b.namespace != "" {
+		header += "  namespace:" + b.namespace
+	}
+	if b.kind != "" {
+		header += "  kind:" + b.kind
+	}
+	if b.searching || b.search != "" {
+		header += "  /" + b.search
+		if b.searching {
+			header += "▏"
+		}
+	}
+	if len(b.history) > 0 {
+		header += gray(fmt.Sprintf("  (%d back)", len(b.history)))
+	}
+	lines := []string{header}
+
+	listWidth := max(24, width*2/5)
+	detailWidth := width - listWidth - 3
+	list := b.renderList(listWidth, bodyHeight)
+	detail := b.renderDetail(detailWidth, bodyHeight)
+	for i := 0; i < bodyHeight; i++ {
+		lines = append(lines, list[i]+gray(" │ ")+detail[i])
+	}
+
+	footer := gray("↑/↓ move  / search  n namespace  t kind  enter relations  tab switch pane  b back  q quit")
+	if b.searching {
+		footer = gray("type to search  enter done  esc clear")
+	}
+	if b.status != "" {
+		footer = yellow(b.status)
+	}
+	return append(lines, footer)
+}
+
+func (b *catalogBrowser) renderList(width, height int) []string {
+	offset := max(0, b.cursor-height+1)
+	lines := make([]string, height)
+	for i := range lines {
+		if offset+i >= len(b.visible) {
+			lines[i] = fitWidth("", width)
+			continue
+		}
+		entity := b.entities[b.visible[offset+i]]
+		line := fitWidth(" "+browseLabel(entity), width)
+		if offset+i == b.cursor {
+			if b.focusRelations {
+				line = bold(line)
+			} else {
+				line = reverse(line)
+			}
+		}
+		lines[i] = line
+	}
+	if len(b.visible) == 0 {
+		lines[0] = fitWidth(" No matching entities", width)
+	}
+	return lines
+}
+
+func (b *catalogBrowser) renderDetail(width, height int) []string {
+	lines := make([]string, 0, height)
+	entity, ok := b.selected()
+	if !ok {
+		for len(lines) < height {
+			lines = append(lines, "")
+		}
+		return lines
+	}
+
+	relations := b.selectedRelations()
+	relationHeight := min(len(relations)+1, height/2)
+	if len(relations) == 0 {
+		relationHeight = 0
+	}
+
+	details := []string{
+		bold(fitWidth(entity.Metadata.Name, width)),
+		fitWidth("ID:        "+entity.ID, width),
+		fitWidth("Kind:      "+entity.ApiVersion+" "+entity.Kind, width),
+		fitWidth("Namespace: "+entity.Metadata.Namespace, width),
+	}
+	if labels, ok := entity.Metadata.Labels.Get(); ok && len(labels) > 0 {
+		var pairs []string
+		for k, v := range labels {
+			pairs = append(pairs, k+"="+v)
+		}
+		sort.Strings(pairs)
+		details = append(details, fitWidth("Labels:    "+strings.Join(pairs, ", "), width))
+	}
+	if spec, ok := entity.Spec.Get(); ok && len(spec) > 0 {
+		details = append(details, "", bold("Spec:"))
+		if data, err := yaml.Marshal(devgraph.CleanSpec(spec)); err == nil {
+			for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+				details = append(details, fitWidth("  "+line, width))
+			}
+		}
+	}
+	for _, line := range details {
+		if len(lines) == height-relationHeight {
+			break
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) < height-relationHeight {
+		lines = append(lines, "")
+	}
+	if relationHeight == 0 {
+		return lines
+	}
+
+	lines = append(lines, bold(fmt.Sprintf("Relations (%d)", len(relations))))
+	rows := relationHeight - 1
+	offset := max(0, b.relCursor-rows+1)
+	for i := offset; i < offset+rows; i++ {
+		if i >= len(relations) {
+			lines = append(lines, "")
+			continue
+		}
+		rel := relations[i]
+		line := "→ " + rel.Relation + "  " + relationRefLabel(rel.Target)
+		if rel.Source.ID != entity.ID {
+			line = "← " + rel.Relation + "  " + relationRefLabel(rel.Source)
+		}
+		line = fitWidth(line, width)
+		if b.focusRelations && i == b.relCursor {
+			line = reverse(line)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func relationRefLabel(ref api.EntityReferenceResponse) string {
+	return fmt.Sprintf("%s %s/%s", ref.Kind, ref.Namespace.Or("default"), ref.Name)
+}
+
+// fitWidth truncates or pads s to width characters
+func fitWidth(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) > width {
+		return string(runes[:max(0, width-1)]) + "…"
+	}
+	return s + strings.Repeat(" ", width-len(runes))
+}
+
+// readKey reads a key press from a terminal in raw mode, returning printable
+// characters as themselves and other keys by name, e.g. "up" or "ctrl+c"
+func readKey(r *bufio.Reader) (string, error) {
+	c, _, err := r.ReadRune()
+	if err != nil {
+		return "", err
+	}
+	switch c {
+	case 3:
+		return "ctrl+c", nil
+	case 14:
+		return "down", nil
+	case 16:
+		return "up", nil
+	case '\t':
+		return "tab", nil
+	case '\r', '\n':
+		return "enter", nil
+	case 8, 127:
+		return "backspace", nil
+	case 27:
+		// A lone escape is the escape key; otherwise it starts a sequence
+		if r.Buffered() == 0 {
+			return "esc", nil
+		}
+		next, _, err := r.ReadRune()
+		if err != nil || (next != '[' && next != 'O') {
+			return "esc", err
+		}
+		var sequence strings.Builder
+		for {
+			c, _, err := r.ReadRune()
+			if err != nil {
+				return "", err
+			}
+			sequence.WriteRune(c)
+			if c >= 0x40 && c <= 0x7e {
+				break
+			}
+		}
+		switch sequence.String() {
+		case "A":
+			return "up", nil
+		case "B":
+			return "down", nil
+		case "C":
+			return "right", nil
+		case "D":
+			return "left", nil
+		case "H", "1~":
+			return "home", nil
+		case "F", "4~":
+			return "end", nil
+		case "5~":
+			return "pgup", nil
+		case "6~":
+			return "pgdown", nil
+		}
+		return "", nil
+	}
+	return string(c), nil
+}
@@ -0,0 +1,222 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/arctir/devgraph-cli/pkg/util"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	_, ok := fuzzyScore("pay", "Service default/payments-api")
+	assert.True(t, ok)
+	_, ok = fuzzyScore("paz", "Service default/payments-api")
+	assert.False(t, ok)
+	_, ok = fuzzyScore("", "anything")
+	assert.True(t, ok)
+
+	// Consecutive matches at the start of a word beat scattered ones
+	prefix, _ := fuzzyScore("api", "Service default/api-gateway")
+	scattered, _ := fuzzyScore("api", "Service default/payments-index")
+	assert.Greater(t, prefix, scattered)
+}
+
+func TestReadKey(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("a\x1b[A\x1b[B\r\x7f\t\x03é/"))
+	var keys []string
+	for {
+		key, err := readKey(reader)
+		if err != nil {
+			break
+		}
+		keys = append(keys, key)
+	}
+	assert.Equal(t, []string{"a", "up", "down", "enter", "backspace", "tab", "ctrl+c", "é", "/"}, keys)
+}
+
+func browseFixtures() ([]map[string]any, []map[string]any) {
+	db := testEntity("orders-db")
+	db["kind"] = "Database"
+	db["id"] = "core/v1/database/default/orders-db"
+	payments := testEntity("payments-api")
+	payments["spec"] = map[string]any{"language": "go"}
+	web := testEntity("web")
+
+	dependsOnDB := testRelation("DEPENDS_ON", "payments-api", "orders-db")
+	dependsOnDB["target"] = map[string]any{"apiVersion": "core/v1", "kind": "Database", "name": "orders-db", "namespace": "default", "id": "core/v1/database/default/orders-db"}
+	return []map[string]any{web, payments, db}, []map[string]any{
+		dependsOnDB,
+		testRelation("DEPENDS_ON", "web", "payments-api"),
+		testRelation("DEPENDS_ON", "web", "ledger"),
+	}
+}
+
+func TestLoadCatalogBrowser(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	entities, relations := browseFixtures()
+	srv.handle("GET /api/v1/entities/", http.StatusOK, map[string]any{"primary_entities": entities, "relations": relations})
+
+	client, err := util.GetAuthenticatedClient(cfg)
+	require.NoError(t, err)
+	params := api.GetEntitiesParams{IncludeRelations: api.NewOptBool(true), Label: api.NewOptString("team=payments")}
+	b, err := loadCatalogBrowser(context.Background(), client, params)
+	require.NoError(t, err)
+
+	query, err := url.ParseQuery(srv.requireRequest(http.MethodGet, "/api/v1/entities/").Query)
+	require.NoError(t, err)
+	assert.Equal(t, "true", query.Get("include_relations"))
+	assert.Equal(t, "team=payments", query.Get("label"))
+
+	// Entities are listed by kind, namespace and name
+	var labels []string
+	for _, index := range b.visible {
+		labels = append(labels, browseLabel(b.entities[index]))
+	}
+	assert.Equal(t, []string{"Database default/orders-db", "Service default/payments-api", "Service default/web"}, labels)
+	assert.Len(t, b.relations["core/v1/service/default/payments-api"], 2)
+}
+
+// testCatalogBrowser returns a browser over entities and relations as the
+// API returns them
+func testCatalogBrowser(t *testing.T, entities, relations []map[string]any) *catalogBrowser {
+	var parsedEntities []api.EntityResponse
+	var parsedRelations []api.EntityRelationResponse
+	data, _ := json.Marshal(entities)
+	require.NoError(t, json.Unmarshal(data, &parsedEntities))
+	data, _ = json.Marshal(relations)
+	require.NoError(t, json.Unmarshal(data, &parsedRelations))
+	return newCatalogBrowser(parsedEntities, parsedRelations)
+}
+
+func TestCatalogBrowser_Navigation(t *testing.T) {
+	entities, relations := browseFixtures()
+	b := testCatalogBrowser(t, entities, relations)
+
+	selectedName := func() string {
+		entity, ok := b.selected()
+		require.True(t, ok)
+		return entity.Metadata.Name
+	}
+	keys := func(keys ...string) {
+		for _, key := range keys {
+			b.handleKey(key)
+		}
+	}
+
+	// Search narrows the list to the best matches
+	keys("/", "w", "e", "b", "enter")
+	assert.Equal(t, "web", selectedName())
+	assert.Len(t, b.visible, 1)
+	assert.False(t, b.searching)
+
+	// Following a relation selects the entity at the other end
+	keys("enter")
+	require.True(t, b.focusRelations)
+	keys("enter")
+	assert.Equal(t, "payments-api", selectedName())
+	assert.Empty(t, b.search)
+	assert.Len(t, b.visible, 3)
+	assert.Equal(t, []string{"core/v1/service/default/web"}, b.history)
+
+	// Outgoing relations are listed first, then incoming ones
+	keys("tab")
+	frame := strings.Join(b.render(120, 20), "\n")
+	assert.Contains(t, frame, "→ DEPENDS_ON  Database default/orders-db")
+	assert.Contains(t, frame, "← DEPENDS_ON  Service default/web")
+	assert.Contains(t, frame, "language: go")
+	keys("down", "enter")
+	assert.Equal(t, "web", selectedName())
+
+	// Relations to entities that weren't loaded can't be followed
+	keys("tab", "down", "enter")
+	assert.Contains(t, b.status, "core/v1/service/default/ledger isn't loaded")
+	assert.Equal(t, "web", selectedName())
+
+	// Going back returns to where relations were followed from
+	keys("esc", "b")
+	assert.Equal(t, "payments-api", selectedName())
+	keys("b")
+	assert.Equal(t, "web", selectedName())
+	assert.Empty(t, b.history)
+
+	keys("q")
+	assert.True(t, b.quit)
+}
+
+func TestCatalogBrowser_Render(t *testing.T) {
+	b := newCatalogBrowser([]api.EntityResponse{{ID: "core/v1/service/default/a", Kind: "Service", Metadata: api.EntityMetadata{Name: strings.Repeat("a", 100), Namespace: "default"}}}, nil)
+	lines := b.render(80, 10)
+	require.Len(t, lines, 10)
+	assert.Contains(t, lines[0], "1/1 entities")
+
+	b.handleKey("/")
+	b.handleKey("z")
+	lines = b.render(80, 10)
+	assert.Contains(t, lines[0], "0/1 entities")
+	assert.Contains(t, lines[1], "No matching entities")
+}
+
+func TestCatalogBrowser_Scope(t *testing.T) {
+	entities, relations := browseFixtures()
+	cart := testEntity("cart")
+	cart["id"] = "core/v1/service/shop/cart"
+	cart["metadata"].(map[string]any)["namespace"] = "shop"
+	b := testCatalogBrowser(t, append(entities, cart), relations)
+	assert.Equal(t, []string{"default", "shop"}, b.namespaces)
+	assert.Equal(t, []string{"Database", "Service"}, b.kinds)
+
+	listed := func() []string {
+		var names []string
+		for _, index := range b.visible {
+			names = append(names, b.entities[index].Metadata.Name)
+		}
+		return names
+	}
+
+	// n and t step through the namespaces and kinds, then back to all of them
+	b.handleKey("n")
+	assert.Equal(t, []string{"orders-db", "payments-api", "web"}, listed())
+	b.handleKey("n")
+	assert.Equal(t, []string{"cart"}, listed())
+	assert.Contains(t, b.render(120, 10)[0], "namespace:shop")
+	b.handleKey("n")
+	assert.Len(t, b.visible, 4)
+	b.handleKey("N")
+	assert.Equal(t, "shop", b.namespace)
+
+	b.handleKey("N")
+	b.handleKey("t")
+	b.handleKey("T")
+	b.handleKey("T")
+	assert.Equal(t, "Service", b.kind)
+	assert.Equal(t, []string{"payments-api", "web"}, listed())
+	assert.Contains(t, b.render(120, 10)[0], "namespace:default  kind:Service")
+
+	// Search works within the scope
+	for _, key := range []string{"/", "p", "a", "y", "enter"} {
+		b.handleKey(key)
+	}
+	assert.Equal(t, []string{"payments-api"}, listed())
+
+	// Following a relation out of the scope widens it
+	b.handleKey("enter")
+	b.handleKey("enter")
+	entity, ok := b.selected()
+	require.True(t, ok)
+	assert.Equal(t, "orders-db", entity.Metadata.Name)
+	assert.Empty(t, b.namespace)
+	assert.Empty(t, b.kind)
+	assert.Len(t, b.visible, 4)
+
+	require.NoError(t, b.scope("shop", ""))
+	assert.Equal(t, []string{"cart"}, listed())
+	assert.EqualError(t, b.scope("", "Queue"), "no entities found in kind Queue")
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
 	"github.com/arctir/devgraph-cli/pkg/util"
 	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
 )
@@ -15,11 +16,31 @@ import (
 type CompleteCommand struct {
 	config.Config
 	ResourceType string `arg:"" help:"Type of resource to complete (contexts, clusters, users, environments, tokens, providers, mcps, models, entities, entity-definitions)"`
+
+	// Completion scripts pass on the --context and --env of the command
+	// being typed, so what's completed is what that command would see
+	Context string `name:"context" help:"Context to complete resources from"`
+	Env     string `name:"env" help:"Environment name, slug, or UUID to complete resources from"`
 }
 
 // Run executes the completion lookup and prints results to stdout.
 func (c *CompleteCommand) Run() error {
+	if c.Context != "" {
+		if err := config.OverrideContext(c.Context); err != nil {
+			return nil // Silently fail for completions
+		}
+		defer func() { _ = config.OverrideContext("") }()
+	}
 	c.Config.ApplyDefaults()
+	// Completions run on every tab press, so recent responses will do. The
+	// cache is keyed by URL, credentials and environment, so each context and
+	// environment has its own.
+	c.Config.CacheResponses = true
+	if c.Env != "" {
+		if err := devgraph.ResolveEnvironment(&c.Config, c.Env); err != nil {
+			return nil
+		}
+	}
 
 	switch c.ResourceType {
 	// Local config resources (no API call needed)
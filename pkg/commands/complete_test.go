@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const stagingEnvironmentID = "22222222-2222-2222-2222-222222222222"
+
+// newCompleteTestAPI is newTestAPI with a staging environment, a token in
+// each environment, and a staging context with its own user
+func newCompleteTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	srv, _ := newTestAPI(t)
+	staging := testEnvironment()
+	staging["id"] = stagingEnvironmentID
+	staging["name"] = "Staging"
+	staging["slug"] = "staging"
+	srv.handle("GET /api/v1/environments", http.StatusOK, []map[string]any{testEnvironment(), staging})
+	srv.mux.HandleFunc("GET /api/v1/tokens", func(w http.ResponseWriter, r *http.Request) {
+		name := "production-token"
+		if r.Header.Get("Devgraph-Environment") == stagingEnvironmentID {
+			name = "staging-token"
+		}
+		writeJSON(w, http.StatusOK, []map[string]any{{
+			"id":      "33333333-3333-3333-3333-333333333333",
+			"name":    name,
+			"user_id": "user_1",
+			"token":   "secret",
+		}})
+	})
+
+	claims := jwt.MapClaims{"exp": float64(time.Now().Add(time.Hour).Unix())}
+	userConfig, err := config.LoadUserConfig()
+	require.NoError(t, err)
+	userConfig.SetUser("staging", "staging-access-token", "", "staging-id-token", &claims)
+	userConfig.SetContext("staging", "test", "staging", stagingEnvironmentID)
+	require.NoError(t, config.SaveUserConfig(userConfig))
+	return srv
+}
+
+func TestCompleteCommand_Env(t *testing.T) {
+	srv := newCompleteTestAPI(t)
+
+	cmd := CompleteCommand{ResourceType: "tokens", Env: "staging"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Equal(t, "staging-token\n", output)
+
+	request := srv.requireRequest(http.MethodGet, "/api/v1/tokens")
+	assert.Equal(t, stagingEnvironmentID, request.Header.Get("Devgraph-Environment"))
+	assert.Equal(t, "Bearer test-id-token", request.Header.Get("Authorization"))
+
+	// The cache is per environment, so the current one is asked for its own
+	cmd = CompleteCommand{ResourceType: "tokens"}
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Equal(t, "production-token\n", output)
+	assert.Len(t, srv.received(http.MethodGet, "/api/v1/tokens"), 2)
+}
+
+func TestCompleteCommand_Context(t *testing.T) {
+	srv := newCompleteTestAPI(t)
+
+	cmd := CompleteCommand{ResourceType: "tokens", Context: "staging"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Equal(t, "staging-token\n", output)
+
+	// The context's credentials and environment are used
+	request := srv.requireRequest(http.MethodGet, "/api/v1/tokens")
+	assert.Equal(t, stagingEnvironmentID, request.Header.Get("Devgraph-Environment"))
+	assert.Equal(t, "Bearer staging-id-token", request.Header.Get("Authorization"))
+
+	// Only for the completion: the current context is unchanged
+	userConfig, err := config.LoadUserConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "test", userConfig.CurrentContext)
+	assert.Equal(t, testEnvironmentID, userConfig.Settings.DefaultEnvironment)
+}
+
+func TestCompleteCommand_UnknownScope(t *testing.T) {
+	srv := newCompleteTestAPI(t)
+
+	for _, cmd := range []CompleteCommand{
+		{ResourceType: "tokens", Context: "missing"},
+		{ResourceType: "tokens", Env: "missing"},
+	} {
+		output, err := captureOutput(t, cmd.Run)
+		require.NoError(t, err, "completions fail silently")
+		assert.Empty(t, output)
+	}
+	assert.Empty(t, srv.received(http.MethodGet, "/api/v1/tokens"))
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/alecthomas/kong"
 )
@@ -21,10 +22,13 @@ func (c *CompletionCommand) Run(ctx *kong.Context) error {
 	// Auto-detect shell if not specified
 	shell := c.Shell
 	if shell == "" {
-		shell = detectShell()
+		shell = detectShell(runtime.GOOS)
 		if shell == "" {
 			return fmt.Errorf("unable to detect shell type. Please specify one of: bash, zsh, fish, powershell")
 		}
+		if shell == "cmd" {
+			return fmt.Errorf("cmd.exe doesn't support completions. Run this from PowerShell, or use 'dg completion powershell'")
+		}
 	}
 
 	// Validate shell type
@@ -53,14 +57,25 @@ func (c *CompletionCommand) Run(ctx *kong.Context) error {
 	return nil
 }
 
-// detectShell attempts to detect the current shell from the SHELL environment variable
-func detectShell() string {
+// detectShell attempts to detect the current shell from the SHELL environment
+// variable. Windows shells don't set it, except for those like Git Bash, so
+// there PowerShell is told from cmd.exe by PSModulePath: PowerShell adds the
+// user's and its own module directories to the system's one.
+func detectShell(goos string) string {
 	shellPath := os.Getenv("SHELL")
 	if shellPath == "" {
-		return ""
+		if goos != "windows" {
+			return ""
+		}
+		if len(strings.Split(os.Getenv("PSModulePath"), ";")) >= 3 {
+			return "powershell"
+		}
+		return "cmd"
 	}
 
-	shellName := filepath.Base(shellPath)
+	// Paths on Windows can use either separator
+	shellName := shellPath[strings.LastIndexAny(shellPath, `/\`)+1:]
+	shellName = strings.TrimSuffix(strings.ToLower(shellName), ".exe")
 	switch shellName {
 	case "bash":
 		return "bash"
@@ -96,10 +111,29 @@ func generateBashCompletion(ctx *kong.Context) string {
 	commands := getCommands()
 	return fmt.Sprintf(`# bash completion for %s
 
-# Helper function to get dynamic completions
+# Helper function to get dynamic completions for the context and environment
+# of the command being typed
 _%s_dynamic() {
     local resource_type="$1"
-    %s complete "$resource_type" 2>/dev/null
+    local scope=() i value
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        case "${COMP_WORDS[i]}" in
+            --context|--env)
+                ((i + 1 < COMP_CWORD)) || continue
+                value="${COMP_WORDS[i+1]}"
+                # --env=staging is split into three words at the =
+                if [[ "$value" == "=" ]]; then
+                    ((i + 2 < COMP_CWORD)) || continue
+                    value="${COMP_WORDS[i+2]}"
+                fi
+                scope+=("${COMP_WORDS[i]}=$value")
+                ;;
+            --context=*|--env=*)
+                scope+=("${COMP_WORDS[i]}")
+                ;;
+        esac
+    done
+    %s complete "$resource_type" "${scope[@]}" 2>/dev/null
 }
 
 _%s_completions() {
@@ -160,7 +194,7 @@ _%s_completions() {
                             local clusters=$(_%s_dynamic clusters)
                             COMPREPLY=( $(compgen -W "${clusters}" -- ${cur}) )
                         else
-                            COMPREPLY=( $(compgen -W "--server --issuer-url --client-id --help" -- ${cur}) )
+                            COMPREPLY=( $(compgen -W "--server --issuer-url --client-id --certificate-authority --insecure-skip-tls-verify --console-url --help" -- ${cur}) )
                         fi
                         ;;
                     delete-user)
@@ -190,31 +224,36 @@ _%s_completions() {
             ;;
         env)
             if [[ ${COMP_CWORD} -eq 2 ]]; then
-                COMPREPLY=( $(compgen -W "current list --help" -- ${cur}) )
+                COMPREPLY=( $(compgen -W "create current list describe use clone diff settings delete --help" -- ${cur}) )
+            elif [[ ${COMP_CWORD} -eq 3 && ( "${COMP_WORDS[2]}" == "use" || "${COMP_WORDS[2]}" == "describe" ) ]]; then
+                local envs=$(_%s_dynamic environments)
+                COMPREPLY=( $(compgen -W "${envs}" -- ${cur}) )
+            elif [[ ${COMP_CWORD} -eq 3 && "${COMP_WORDS[2]}" == "settings" ]]; then
+                COMPREPLY=( $(compgen -W "get set --help" -- ${cur}) )
             else
                 COMPREPLY=( $(compgen -W "--help" -- ${cur}) )
             fi
             ;;
         user)
             if [[ ${COMP_CWORD} -eq 2 ]]; then
-                COMPREPLY=( $(compgen -W "list add remove --help" -- ${cur}) )
+                COMPREPLY=( $(compgen -W "list get add remove update-role invite audit --help" -- ${cur}) )
             else
                 COMPREPLY=( $(compgen -W "--help" -- ${cur}) )
             fi
             ;;
         suggestion)
             if [[ ${COMP_CWORD} -eq 2 ]]; then
-                COMPREPLY=( $(compgen -W "list create delete --help" -- ${cur}) )
+                COMPREPLY=( $(compgen -W "list create delete export import --help" -- ${cur}) )
             else
                 COMPREPLY=( $(compgen -W "--help" -- ${cur}) )
             fi
             ;;
         entity-definition)
             if [[ ${COMP_CWORD} -eq 2 ]]; then
-                COMPREPLY=( $(compgen -W "list get create update delete --help" -- ${cur}) )
+                COMPREPLY=( $(compgen -W "list get create update delete generate validate diff versions example export apply import-crd --help" -- ${cur}) )
             elif [[ ${COMP_CWORD} -eq 3 ]]; then
                 case "${COMP_WORDS[2]}" in
-                    get|update|delete)
+                    get|update|delete|generate|example|export)
                         local defs=$(_%s_dynamic entity-definitions)
                         COMPREPLY=( $(compgen -W "${defs}" -- ${cur}) )
                         ;;
@@ -329,8 +368,50 @@ _%s_completions() {
             fi
             ;;
         subscription)
+            if [[ ${COMP_CWORD} -eq 2 ]]; then
+                COMPREPLY=( $(compgen -W "list get usage --help" -- ${cur}) )
+            else
+                COMPREPLY=( $(compgen -W "--help" -- ${cur}) )
+            fi
+            ;;
+        export)
+            if [[ ${COMP_CWORD} -eq 2 ]]; then
+                COMPREPLY=( $(compgen -W "backstage --help" -- ${cur}) )
+            else
+                COMPREPLY=( $(compgen -W "--name --label -l --field-selector -f --default-owner --default-lifecycle --help" -- ${cur}) )
+            fi
+            ;;
+        import)
+            if [[ ${COMP_CWORD} -eq 2 ]]; then
+                COMPREPLY=( $(compgen -W "backstage github kubernetes terraform --help" -- ${cur}) )
+            else
+                COMPREPLY=( $(compgen -W "--workers -w --enforce-limits --dry-run --kubeconfig --context --namespace -n --all-namespaces -A --org --token --base-url --archived --help" -- ${cur}) )
+            fi
+            ;;
+        plugin)
             if [[ ${COMP_CWORD} -eq 2 ]]; then
                 COMPREPLY=( $(compgen -W "list --help" -- ${cur}) )
+            else
+                COMPREPLY=( $(compgen -W "--output -o --help" -- ${cur}) )
+            fi
+            ;;
+        docs)
+            if [[ ${COMP_CWORD} -eq 2 ]]; then
+                COMPREPLY=( $(compgen -W "man markdown --help" -- ${cur}) )
+            else
+                COMPREPLY=( $(compgen -d -- ${cur}) )
+            fi
+            ;;
+        webhook)
+            if [[ ${COMP_CWORD} -eq 2 ]]; then
+                COMPREPLY=( $(compgen -W "listen --help" -- ${cur}) )
+            else
+                COMPREPLY=( $(compgen -W "--address --status --help" -- ${cur}) )
+            fi
+            ;;
+        telemetry)
+            if [[ ${COMP_CWORD} -eq 2 ]]; then
+                COMPREPLY=( $(compgen -W "enable disable status --help" -- ${cur}) )
             else
                 COMPREPLY=( $(compgen -W "--help" -- ${cur}) )
             fi
@@ -381,17 +462,30 @@ complete -F _%s_completions %s
 `, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, commands,
 		ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name,
 		ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name,
-		ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name)
+		ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name)
 }
 
 // generateZshCompletion generates a zsh completion script
 func generateZshCompletion(ctx *kong.Context) string {
 	return fmt.Sprintf(`#compdef %s
 
-# Helper function to get dynamic completions
+# Helper function to get dynamic completions for the context and environment
+# of the command being typed
 _%s_dynamic() {
     local resource_type="$1"
-    %s complete "$resource_type" 2>/dev/null
+    local -a scope
+    local i
+    for ((i = 2; i < CURRENT; i++)); do
+        case "${words[i]}" in
+            --context|--env)
+                ((i + 1 < CURRENT)) && scope+=("${words[i]}=${words[i+1]}")
+                ;;
+            --context=*|--env=*)
+                scope+=("${words[i]}")
+                ;;
+        esac
+    done
+    %s complete "$resource_type" "${scope[@]}" 2>/dev/null
 }
 
 _%s() {
@@ -429,22 +523,30 @@ _%s() {
             esac
             ;;
         env)
-            _arguments "1: :(current list)"
+            case $line[2] in
+                use|describe)
+                    local envs; envs=(${(f)"$(_%s_dynamic environments)"})
+                    _arguments "1: :($envs)"
+                    ;;
+                *)
+                    _arguments "1: :(create current list describe use clone diff settings delete)"
+                    ;;
+            esac
             ;;
         user)
-            _arguments "1: :(list add remove)"
+            _arguments "1: :(list get add remove update-role invite audit)"
             ;;
         suggestion)
-            _arguments "1: :(list create delete)"
+            _arguments "1: :(list create delete export import)"
             ;;
         entity-definition)
             case $line[2] in
-                get|update|delete)
+                get|update|delete|generate|example|export)
                     local defs; defs=(${(f)"$(_%s_dynamic entity-definitions)"})
                     _arguments "1: :($defs)"
                     ;;
                 *)
-                    _arguments "1: :(list get create update delete)"
+                    _arguments "1: :(list get create update delete generate validate diff versions example export apply import-crd)"
                     ;;
             esac
             ;;
@@ -526,8 +628,26 @@ _%s() {
             esac
             ;;
         subscription)
+            _arguments "1: :(list get usage)"
+            ;;
+        export)
+            _arguments "1: :(backstage)"
+            ;;
+        import)
+            _arguments "1: :(backstage github kubernetes terraform)"
+            ;;
+        plugin)
             _arguments "1: :(list)"
             ;;
+        docs)
+            _arguments "1: :(man markdown)" "2:directory:_files -/"
+            ;;
+        webhook)
+            _arguments "1: :(listen)"
+            ;;
+        telemetry)
+            _arguments "1: :(enable disable status)"
+            ;;
         completion)
             _arguments "1: :(bash zsh fish powershell)" "--install[Install completion script]"
             ;;
@@ -545,7 +665,7 @@ _%s "$@"
 `, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name,
 		ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name,
 		ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name,
-		ctx.Model.Name, ctx.Model.Name, ctx.Model.Name,
+		ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name,
 		getCommandsWithDescriptions(), ctx.Model.Name, ctx.Model.Name)
 }
 
@@ -556,9 +676,22 @@ func generateFishCompletion(ctx *kong.Context) string {
 # Remove default completions
 complete -c %s -e
 
-# Helper function for dynamic completions
+# Helper function for dynamic completions for the context and environment of
+# the command being typed
 function __%s_dynamic
-    %s complete $argv[1] 2>/dev/null
+    set -l scope
+    set -l tokens (commandline -opc)
+    for i in (seq 2 (count $tokens))
+        switch $tokens[$i]
+            case --context --env
+                if test $i -lt (count $tokens)
+                    set -a scope $tokens[$i]=$tokens[(math $i + 1)]
+                end
+            case '--context=*' '--env=*'
+                set -a scope $tokens[$i]
+        end
+    end
+    %s complete $argv[1] $scope 2>/dev/null
 end
 
 # Top-level commands
@@ -575,9 +708,20 @@ complete -c %s -f -n "__fish_use_subcommand" -a "model" -d "Manage Model resourc
 complete -c %s -f -n "__fish_use_subcommand" -a "oauthservice" -d "Manage OAuth services"
 complete -c %s -f -n "__fish_use_subcommand" -a "subscription" -d "Manage subscriptions"
 complete -c %s -f -n "__fish_use_subcommand" -a "suggestion" -d "Manage chat suggestions"
+complete -c %s -f -n "__fish_use_subcommand" -a "telemetry" -d "Manage anonymous usage telemetry"
+complete -c %s -f -n "__fish_use_subcommand" -a "export" -d "Export entities to other catalogs"
+complete -c %s -f -n "__fish_use_subcommand" -a "import" -d "Import entities from other catalogs"
+complete -c %s -f -n "__fish_use_subcommand" -a "plugin" -d "Manage dg-* plugins"
 complete -c %s -f -n "__fish_use_subcommand" -a "provider" -d "Manage discovery providers"
 complete -c %s -f -n "__fish_use_subcommand" -a "user" -d "Manage users in the current environment"
 complete -c %s -f -n "__fish_use_subcommand" -a "completion" -d "Generate shell completion scripts"
+complete -c %s -f -n "__fish_use_subcommand" -a "api" -d "Send an authenticated request to any API path"
+complete -c %s -f -n "__fish_use_subcommand" -a "query" -d "Find entities with a query"
+complete -c %s -f -n "__fish_use_subcommand" -a "browse" -d "Explore the catalog interactively"
+complete -c %s -f -n "__fish_use_subcommand" -a "dashboard" -d "Open the web console"
+complete -c %s -f -n "__fish_use_subcommand" -a "docs" -d "Generate man pages and Markdown reference docs"
+complete -c %s -f -n "__fish_use_subcommand" -a "webhook" -d "Receive webhook deliveries"
+complete -c %s -f -n "__fish_use_subcommand" -a "prompt" -d "Print the current context for a shell prompt"
 
 # Auth subcommands
 complete -c %s -f -n "__fish_seen_subcommand_from auth" -a "login" -d "Authenticate with your account"
@@ -630,21 +774,63 @@ complete -c %s -f -n "__fish_seen_subcommand_from token" -a "update" -d "Update
 complete -c %s -f -n "__fish_seen_subcommand_from token; and __fish_seen_subcommand_from get update delete" -a "(__%s_dynamic tokens)"
 
 # Env subcommands
+complete -c %s -f -n "__fish_seen_subcommand_from env" -a "create" -d "Create an environment"
 complete -c %s -f -n "__fish_seen_subcommand_from env" -a "current" -d "Display current environment"
 complete -c %s -f -n "__fish_seen_subcommand_from env" -a "list" -d "List environments"
+complete -c %s -f -n "__fish_seen_subcommand_from env" -a "describe" -d "Describe an environment"
+complete -c %s -f -n "__fish_seen_subcommand_from env" -a "use" -d "Switch to an environment"
+complete -c %s -f -n "__fish_seen_subcommand_from env" -a "clone" -d "Clone catalog data to another environment"
+complete -c %s -f -n "__fish_seen_subcommand_from env" -a "diff" -d "Compare the catalogs of two environments"
+complete -c %s -f -n "__fish_seen_subcommand_from env" -a "settings" -d "Manage environment settings"
+complete -c %s -f -n "__fish_seen_subcommand_from env; and __fish_seen_subcommand_from settings" -a "get set"
+complete -c %s -f -n "__fish_seen_subcommand_from env" -a "delete" -d "Delete an environment"
+complete -c %s -f -n "__fish_seen_subcommand_from env; and __fish_seen_subcommand_from use describe" -a "(__%s_dynamic environments)"
 
 # User subcommands
 complete -c %s -f -n "__fish_seen_subcommand_from user" -a "list" -d "List users"
+complete -c %s -f -n "__fish_seen_subcommand_from user" -a "get" -d "Show user details"
 complete -c %s -f -n "__fish_seen_subcommand_from user" -a "add" -d "Invite a user"
 complete -c %s -f -n "__fish_seen_subcommand_from user" -a "remove" -d "Remove a user"
+complete -c %s -f -n "__fish_seen_subcommand_from user" -a "update-role" -d "Change a user's role"
+complete -c %s -f -n "__fish_seen_subcommand_from user" -a "invite" -d "Manage pending invitations"
+complete -c %s -f -n "__fish_seen_subcommand_from user" -a "audit" -d "Export members and pending invitations"
+complete -c %s -f -n "__fish_seen_subcommand_from user; and __fish_seen_subcommand_from invite" -a "resend revoke"
 
 # Suggestion subcommands
 complete -c %s -f -n "__fish_seen_subcommand_from suggestion" -a "list" -d "List chat suggestions"
 complete -c %s -f -n "__fish_seen_subcommand_from suggestion" -a "create" -d "Create a chat suggestion"
 complete -c %s -f -n "__fish_seen_subcommand_from suggestion" -a "delete" -d "Delete a chat suggestion"
+complete -c %s -f -n "__fish_seen_subcommand_from suggestion" -a "export" -d "Export chat suggestions as YAML"
+complete -c %s -f -n "__fish_seen_subcommand_from suggestion" -a "import" -d "Import chat suggestions from YAML"
 
 # Subscription subcommands
 complete -c %s -f -n "__fish_seen_subcommand_from subscription" -a "list" -d "List subscriptions"
+complete -c %s -f -n "__fish_seen_subcommand_from subscription" -a "get" -d "Show subscription details"
+complete -c %s -f -n "__fish_seen_subcommand_from subscription" -a "usage" -d "Show current usage"
+
+# Export subcommands
+complete -c %s -f -n "__fish_seen_subcommand_from export" -a "backstage" -d "Export Backstage catalog files"
+
+# Import subcommands
+complete -c %s -f -n "__fish_seen_subcommand_from import" -a "backstage" -d "Import Backstage catalog files"
+complete -c %s -f -n "__fish_seen_subcommand_from import" -a "github" -d "Import a GitHub organization"
+complete -c %s -f -n "__fish_seen_subcommand_from import" -a "kubernetes" -d "Import Kubernetes workloads, services and ingresses"
+complete -c %s -f -n "__fish_seen_subcommand_from import" -a "terraform" -d "Import resources from Terraform state"
+
+# Plugin subcommands
+complete -c %s -f -n "__fish_seen_subcommand_from plugin" -a "list" -d "List plugins found on PATH"
+
+# Docs subcommands
+complete -c %s -f -n "__fish_seen_subcommand_from docs" -a "man" -d "Write a man page for every command"
+complete -c %s -f -n "__fish_seen_subcommand_from docs" -a "markdown" -d "Write a Markdown reference page for every command"
+
+# Webhook subcommands
+complete -c %s -f -n "__fish_seen_subcommand_from webhook" -a "listen" -d "Print deliveries received on a local port"
+
+# Telemetry subcommands
+complete -c %s -f -n "__fish_seen_subcommand_from telemetry" -a "enable" -d "Send anonymous usage data"
+complete -c %s -f -n "__fish_seen_subcommand_from telemetry" -a "disable" -d "Stop sending usage data"
+complete -c %s -f -n "__fish_seen_subcommand_from telemetry" -a "status" -d "Show telemetry status"
 
 # Completion subcommands
 complete -c %s -f -n "__fish_seen_subcommand_from completion" -a "bash" -d "Generate bash completion"
@@ -664,17 +850,19 @@ complete -c %s -f -n "__fish_seen_subcommand_from completion" -l "install" -d "I
 		ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name,
 		ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name,
 		ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name,
-		ctx.Model.Name)
+		ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name,
+		ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name, ctx.Model.Name)
 }
 
 // generatePowershellCompletion generates a PowerShell completion script
 func generatePowershellCompletion(ctx *kong.Context) string {
 	return fmt.Sprintf(`# PowerShell completion for %s
 
-# Helper function for dynamic completions
+# Helper function for dynamic completions. $completionScope is the --context
+# and --env of the command being typed, set by the argument completer.
 function Get-%sDynamic {
     param($ResourceType)
-    $result = & %s complete $ResourceType 2>$null
+    $result = & %s complete $ResourceType @completionScope 2>$null
     if ($result) {
         return $result -split [char]10 | Where-Object { $_ -ne '' }
     }
@@ -685,6 +873,20 @@ Register-ArgumentCompleter -Native -CommandName '%s' -ScriptBlock {
     param($wordToComplete, $commandAst, $cursorPosition)
 
     $commandElements = $commandAst.CommandElements
+    $completionScope = @(
+        for ($i = 1; $i -lt $commandElements.Count; $i++) {
+            $element = $commandElements[$i]
+            if ($element.Extent.EndOffset -ge $cursorPosition) {
+                break
+            }
+            $text = $element.Extent.Text
+            if (($text -eq '--context' -or $text -eq '--env') -and $i + 1 -lt $commandElements.Count -and $commandElements[$i + 1].Extent.EndOffset -lt $cursorPosition) {
+                $text + '=' + $commandElements[$i + 1].Extent.Text
+            } elseif ($text -like '--context=*' -or $text -like '--env=*') {
+                $text
+            }
+        }
+    )
     $command = @(
         '%s'
         for ($i = 1; $i -lt $commandElements.Count; $i++) {
@@ -714,9 +916,20 @@ Register-ArgumentCompleter -Native -CommandName '%s' -ScriptBlock {
                 @{Text='oauthservice'; Description='Manage OAuth services'},
                 @{Text='subscription'; Description='Manage subscriptions'},
                 @{Text='suggestion'; Description='Manage chat suggestions'},
+                @{Text='telemetry'; Description='Manage anonymous usage telemetry'},
+                @{Text='export'; Description='Export entities to other catalogs'},
+                @{Text='import'; Description='Import entities from other catalogs'},
+                @{Text='plugin'; Description='Manage dg-* plugins'},
                 @{Text='provider'; Description='Manage discovery providers'},
                 @{Text='user'; Description='Manage users in the current environment'},
-                @{Text='completion'; Description='Generate shell completion scripts'}
+                @{Text='completion'; Description='Generate shell completion scripts'},
+                @{Text='api'; Description='Send an authenticated request to any API path'},
+                @{Text='query'; Description='Find entities with a query'},
+                @{Text='browse'; Description='Explore the catalog interactively'},
+                @{Text='dashboard'; Description='Open the web console'},
+                @{Text='docs'; Description='Generate man pages and Markdown reference docs'},
+                @{Text='webhook'; Description='Receive webhook deliveries'},
+                @{Text='prompt'; Description='Print the current context for a shell prompt'}
             )
         }
         2 {
@@ -747,22 +960,35 @@ Register-ArgumentCompleter -Native -CommandName '%s' -ScriptBlock {
                 }
                 'env' {
                     $completions = @(
+                        @{Text='create'; Description='Create an environment'},
                         @{Text='current'; Description='Display current environment'},
-                        @{Text='list'; Description='List environments'}
+                        @{Text='list'; Description='List environments'},
+                        @{Text='describe'; Description='Describe an environment'},
+                        @{Text='use'; Description='Switch to an environment'},
+                        @{Text='clone'; Description='Clone catalog data to another environment'},
+                        @{Text='diff'; Description='Compare the catalogs of two environments'},
+                        @{Text='settings'; Description='Manage environment settings'},
+                        @{Text='delete'; Description='Delete an environment'}
                     )
                 }
                 'user' {
                     $completions = @(
                         @{Text='list'; Description='List users'},
+                        @{Text='get'; Description='Show user details'},
                         @{Text='add'; Description='Invite a user'},
-                        @{Text='remove'; Description='Remove a user'}
+                        @{Text='remove'; Description='Remove a user'},
+                        @{Text='update-role'; Description="Change a user's role"},
+                        @{Text='invite'; Description='Manage pending invitations'},
+                        @{Text='audit'; Description='Export members and pending invitations'}
                     )
                 }
                 'suggestion' {
                     $completions = @(
                         @{Text='list'; Description='List chat suggestions'},
                         @{Text='create'; Description='Create a chat suggestion'},
-                        @{Text='delete'; Description='Delete a chat suggestion'}
+                        @{Text='delete'; Description='Delete a chat suggestion'},
+                        @{Text='export'; Description='Export chat suggestions as YAML'},
+                        @{Text='import'; Description='Import chat suggestions from YAML'}
                     )
                 }
                 {$_ -in @('entity-definition','entity','mcp','modelprovider','model','oauthservice','provider')} {
@@ -785,7 +1011,45 @@ Register-ArgumentCompleter -Native -CommandName '%s' -ScriptBlock {
                 }
                 'subscription' {
                     $completions = @(
-                        @{Text='list'; Description='List subscriptions'}
+                        @{Text='list'; Description='List subscriptions'},
+                        @{Text='get'; Description='Show subscription details'},
+                        @{Text='usage'; Description='Show current usage'}
+                    )
+                }
+                'export' {
+                    $completions = @(
+                        @{Text='backstage'; Description='Export Backstage catalog files'}
+                    )
+                }
+                'import' {
+                    $completions = @(
+                        @{Text='backstage'; Description='Import Backstage catalog files'},
+                        @{Text='github'; Description='Import a GitHub organization'},
+                        @{Text='kubernetes'; Description='Import Kubernetes workloads, services and ingresses'},
+                        @{Text='terraform'; Description='Import resources from Terraform state'}
+                    )
+                }
+                'plugin' {
+                    $completions = @(
+                        @{Text='list'; Description='List plugins found on PATH'}
+                    )
+                }
+                'docs' {
+                    $completions = @(
+                        @{Text='man'; Description='Write a man page for every command'},
+                        @{Text='markdown'; Description='Write a Markdown reference page for every command'}
+                    )
+                }
+                'webhook' {
+                    $completions = @(
+                        @{Text='listen'; Description='Print deliveries received on a local port'}
+                    )
+                }
+                'telemetry' {
+                    $completions = @(
+                        @{Text='enable'; Description='Send anonymous usage data'},
+                        @{Text='disable'; Description='Stop sending usage data'},
+                        @{Text='status'; Description='Show telemetry status'}
                     )
                 }
                 'completion' {
@@ -914,12 +1178,13 @@ func installCompletion(shell, script string) error {
 		instructions = "Fish will automatically load completions from this location"
 
 	case "powershell":
-		if runtime.GOOS == "windows" {
-			path = filepath.Join(homeDir, "Documents/PowerShell/Scripts/dg-completion.ps1")
-		} else {
-			path = filepath.Join(homeDir, ".config/powershell/Scripts/dg-completion.ps1")
+		profileDir, err := powershellProfileDir(runtime.GOOS, homeDir)
+		if err != nil {
+			return err
 		}
-		instructions = "Add this line to your PowerShell profile:\n  . " + path
+		path = filepath.Join(profileDir, "Scripts", "dg-completion.ps1")
+		profile := filepath.Join(profileDir, "Microsoft.PowerShell_profile.ps1")
+		instructions = fmt.Sprintf("Add this line to your PowerShell profile (%s):\n  . '%s'", profile, path)
 
 	default:
 		return fmt.Errorf("unsupported shell: %s", shell)
@@ -943,9 +1208,35 @@ func installCompletion(shell, script string) error {
 	return nil
 }
 
+// powershellProfileDir returns the directory holding the current user's
+// PowerShell profile. On Windows it's in the Documents folder, which may have
+// been moved (to OneDrive, for example), and Windows PowerShell 5 uses a
+// different directory than PowerShell 7. It's the first directory of
+// PSModulePath that PowerShell adds to the environment, such as
+// Documents\WindowsPowerShell\Modules. Elsewhere it follows XDG.
+func powershellProfileDir(goos, homeDir string) (string, error) {
+	if goos != "windows" {
+		configDir := os.Getenv("XDG_CONFIG_HOME")
+		if configDir == "" {
+			configDir = filepath.Join(homeDir, ".config")
+		}
+		return filepath.Join(configDir, "powershell"), nil
+	}
+
+	documents, err := documentsDir(homeDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the Documents folder: %w", err)
+	}
+	edition := "PowerShell"
+	if modules := strings.Split(os.Getenv("PSModulePath"), ";"); len(modules) > 0 && strings.Contains(modules[0], "WindowsPowerShell") {
+		edition = "WindowsPowerShell"
+	}
+	return filepath.Join(documents, edition), nil
+}
+
 // getCommands returns a space-separated list of top-level commands
 func getCommands() string {
-	return "chat auth config token env entity-definition entity mcp modelprovider model oauthservice subscription suggestion provider user completion"
+	return "chat auth config token env entity-definition entity mcp modelprovider model oauthservice subscription suggestion telemetry export import plugin provider user completion api query browse dashboard docs webhook prompt"
 }
 
 // getCommandsWithDescriptions returns command list formatted for zsh completion with descriptions
@@ -963,7 +1254,18 @@ func getCommandsWithDescriptions() string {
         'oauthservice:Manage OAuth services'
         'subscription:Manage subscriptions'
         'suggestion:Manage chat suggestions'
+        'telemetry:Manage anonymous usage telemetry'
+        'export:Export entities to other catalogs'
+        'import:Import entities from other catalogs'
+        'plugin:Manage dg-* plugins'
         'provider:Manage discovery providers'
         'user:Manage users in the current environment'
-        'completion:Generate shell completion scripts'`
+        'completion:Generate shell completion scripts'
+        'api:Send an authenticated request to any API path'
+        'query:Find entities with a query'
+        'browse:Explore the catalog interactively'
+        'dashboard:Open the web console'
+        'docs:Generate man pages and Markdown reference docs'
+        'webhook:Receive webhook deliveries'
+        'prompt:Print the current context for a shell prompt'`
 }
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package commands
+
+import "path/filepath"
+
+// documentsDir returns the user's Documents folder
+func documentsDir(homeDir string) (string, error) {
+	return filepath.Join(homeDir, "Documents"), nil
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectShell(t *testing.T) {
+	tests := []struct {
+		goos         string
+		shell        string
+		psModulePath string
+		want         string
+	}{
+		{"linux", "/bin/zsh", "", "zsh"},
+		{"darwin", "/opt/homebrew/bin/fish", "", "fish"},
+		{"linux", "/usr/bin/pwsh", "", "powershell"},
+		{"linux", "", "", ""},
+		{"linux", "/bin/tcsh", "", ""},
+		// Git Bash sets SHELL
+		{"windows", `C:\Program Files\Git\usr\bin\bash.exe`, "", "bash"},
+		// PowerShell adds its own module directories to the system's
+		{"windows", "", `C:\Users\a\Documents\PowerShell\Modules;C:\Program Files\PowerShell\Modules;c:\program files\powershell\7\Modules;C:\WINDOWS\system32\WindowsPowerShell\v1.0\Modules`, "powershell"},
+		{"windows", "", `C:\Program Files\WindowsPowerShell\Modules;C:\WINDOWS\system32\WindowsPowerShell\v1.0\Modules`, "cmd"},
+	}
+	for _, test := range tests {
+		t.Setenv("SHELL", test.shell)
+		t.Setenv("PSModulePath", test.psModulePath)
+		assert.Equal(t, test.want, detectShell(test.goos), "%s %q", test.goos, test.shell)
+	}
+}
+
+func TestPowershellProfileDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	dir, err := powershellProfileDir("linux", home)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".config", "powershell"), dir)
+
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+	dir, err = powershellProfileDir("darwin", home)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, "config", "powershell"), dir)
+
+	// Windows PowerShell 5 puts its user modules first
+	t.Setenv("PSModulePath", `C:\Users\a\Documents\WindowsPowerShell\Modules;C:\Program Files\WindowsPowerShell\Modules`)
+	dir, err = powershellProfileDir("windows", home)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, "Documents", "WindowsPowerShell"), dir)
+
+	t.Setenv("PSModulePath", `C:\Users\a\Documents\PowerShell\Modules;C:\Program Files\PowerShell\Modules`)
+	dir, err = powershellProfileDir("windows", home)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, "Documents", "PowerShell"), dir)
+}
@@ -0,0 +1,9 @@
+package commands
+
+import "golang.org/x/sys/windows"
+
+// documentsDir returns the user's Documents folder, wherever it has been
+// moved to
+func documentsDir(string) (string, error) {
+	return windows.KnownFolderPath(windows.FOLDERID_Documents, 0)
+}
@@ -1,15 +1,15 @@
 package commands
 
 import (
-	"encoding/json"
 	"fmt"
 	"sort"
 
 	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/arctir/devgraph-cli/pkg/logging"
+	"github.com/arctir/devgraph-cli/pkg/output"
 	"github.com/arctir/devgraph-cli/pkg/util"
 	"github.com/fatih/color"
 	"github.com/golang-jwt/jwt/v5"
-	"gopkg.in/yaml.v3"
 )
 
 type ConfigCommand struct {
@@ -63,6 +63,10 @@ type SetClusterCommand struct {
 	Server    string `flag:"server" help:"API server URL."`
 	IssuerURL string `flag:"issuer-url" help:"OIDC issuer URL."`
 	ClientID  string `flag:"client-id" help:"OAuth client ID."`
+
+	CertificateAuthority  string `flag:"certificate-authority" type:"path" help:"PEM file of additional certificate authorities to trust for this cluster."`
+	InsecureSkipTLSVerify *bool  `flag:"insecure-skip-tls-verify" negatable:"" help:"Skip TLS certificate verification for this cluster (insecure)."`
+	ConsoleURL            string `flag:"console-url" help:"Web console URL, if it isn't the server URL with api. replaced by app."`
 }
 
 // SetCredentialsCommand sets user credentials
@@ -91,13 +95,6 @@ func (g *GetContextsCommand) Run() error {
 	}
 	sort.Strings(names)
 
-	if g.Output == "name" {
-		for _, name := range names {
-			fmt.Println(name)
-		}
-		return nil
-	}
-
 	// Build context data
 	type contextOutput struct {
 		Current     bool   `json:"current" yaml:"current"`
@@ -128,40 +125,27 @@ func (g *GetContextsCommand) Run() error {
 		})
 	}
 
-	switch g.Output {
-	case "json":
-		output, err := json.MarshalIndent(contexts, "", "  ")
-		if err != nil {
-			return fmt.Errorf("failed to marshal JSON: %w", err)
-		}
-		fmt.Println(string(output))
-	case "yaml":
-		output, err := yaml.Marshal(contexts)
-		if err != nil {
-			return fmt.Errorf("failed to marshal YAML: %w", err)
-		}
-		fmt.Print(string(output))
-	default:
-		// Table output
-		headers := []string{"Current", "Name", "Cluster", "User", "Environment"}
-		data := make([]map[string]interface{}, 0, len(contexts))
-		for _, ctx := range contexts {
-			current := ""
-			if ctx.Current {
-				current = "*"
-			}
-			data = append(data, map[string]interface{}{
-				"Current":     current,
-				"Name":        ctx.Name,
-				"Cluster":     ctx.Cluster,
-				"User":        ctx.User,
-				"Environment": ctx.Environment,
-			})
+	headers := []string{"Current", "Name", "Cluster", "User", "Environment"}
+	data := make([]map[string]interface{}, 0, len(contexts))
+	for _, ctx := range contexts {
+		current := ""
+		if ctx.Current {
+			current = "*"
 		}
-		displayEntityTable(data, headers)
+		data = append(data, map[string]interface{}{
+			"Current":     current,
+			"Name":        ctx.Name,
+			"Cluster":     ctx.Cluster,
+			"User":        ctx.User,
+			"Environment": ctx.Environment,
+		})
 	}
-
-	return nil
+	return output.Print(g.Output, output.Result{
+		Data:    contexts,
+		Headers: headers,
+		Rows:    data,
+		Names:   names,
+	})
 }
 
 func (c *CurrentContextCommand) Run() error {
@@ -334,6 +318,26 @@ func (s *SetClusterCommand) Run() error {
 
 	userConfig.SetCluster(s.Cluster, server, issuerURL, clientID)
 
+	// TLS and console settings are kept unless given
+	cluster := userConfig.Clusters[s.Cluster]
+	if exists {
+		cluster.CertificateAuthority = existingCluster.CertificateAuthority
+		cluster.InsecureSkipTLSVerify = existingCluster.InsecureSkipTLSVerify
+		cluster.ConsoleURL = existingCluster.ConsoleURL
+	}
+	if s.ConsoleURL != "" {
+		cluster.ConsoleURL = s.ConsoleURL
+	}
+	if s.CertificateAuthority != "" {
+		cluster.CertificateAuthority = s.CertificateAuthority
+	}
+	if s.InsecureSkipTLSVerify != nil {
+		cluster.InsecureSkipTLSVerify = *s.InsecureSkipTLSVerify
+	}
+	if cluster.InsecureSkipTLSVerify {
+		logging.Warn("TLS certificate verification is disabled for this cluster", "cluster", s.Cluster)
+	}
+
 	if err := config.SaveUserConfig(userConfig); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
@@ -433,7 +437,7 @@ func (g *GetClustersCommand) Run() error {
 		})
 	}
 
-	displayEntityTable(data, headers)
+	output.PrintTable(headers, data)
 	return nil
 }
 
@@ -509,7 +513,7 @@ func (g *GetUsersCommand) Run() error {
 		})
 	}
 
-	displayEntityTable(data, headers)
+	output.PrintTable(headers, data)
 	return nil
 }
 
@@ -3,7 +3,9 @@ package commands
 import (
 	"testing"
 
+	"github.com/arctir/devgraph-cli/pkg/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestConfigCommand_Structure(t *testing.T) {
@@ -89,3 +91,53 @@ func TestSetCredentialsCommand_Structure(t *testing.T) {
 	assert.IsType(t, "", setCredsCmd.RefreshToken)
 	assert.IsType(t, "", setCredsCmd.IDToken)
 }
+
+func TestSetClusterCommand_TLS(t *testing.T) {
+	t.Cleanup(setupTempConfig(t))
+
+	logs := captureLogs(t)
+
+	insecure := true
+	cmd := SetClusterCommand{Cluster: "corp", Server: "https://api.corp.example", CertificateAuthority: "/etc/corp-ca.pem", InsecureSkipTLSVerify: &insecure}
+	_, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Equal(t, "Warning: TLS certificate verification is disabled for this cluster cluster=corp\n", logs.String())
+
+	// Changing the server keeps the TLS settings
+	cmd = SetClusterCommand{Cluster: "corp", Server: "https://api2.corp.example"}
+	_, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	userConfig, err := config.LoadUserConfig()
+	require.NoError(t, err)
+	cluster := userConfig.Clusters["corp"]
+	assert.Equal(t, "https://api2.corp.example", cluster.Server)
+	assert.Equal(t, "/etc/corp-ca.pem", cluster.CertificateAuthority)
+	assert.True(t, cluster.InsecureSkipTLSVerify)
+
+	insecure = false
+	cmd = SetClusterCommand{Cluster: "corp", InsecureSkipTLSVerify: &insecure}
+	_, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	userConfig, err = config.LoadUserConfig()
+	require.NoError(t, err)
+	assert.False(t, userConfig.Clusters["corp"].InsecureSkipTLSVerify)
+}
+
+func TestSetClusterCommand_ConsoleURL(t *testing.T) {
+	t.Cleanup(setupTempConfig(t))
+
+	cmd := SetClusterCommand{Cluster: "corp", Server: "https://devgraph.corp.example", ConsoleURL: "https://ui.corp.example"}
+	_, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	// Changing the server keeps the console URL
+	cmd = SetClusterCommand{Cluster: "corp", Server: "https://devgraph2.corp.example"}
+	_, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	userConfig, err := config.LoadUserConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "https://ui.corp.example", userConfig.Clusters["corp"].ConsoleURL)
+}
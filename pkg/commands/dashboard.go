@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/arctir/devgraph-cli/pkg/logging"
+)
+
+// DashboardCommand opens the web console at the current environment, or at
+// one of its entities
+type DashboardCommand struct {
+	EnvWrapperCommand
+	Entity string `arg:"" optional:"" help:"ID of an entity to open, as <group>/<version>/<plural>/<namespace>/<name>."`
+	URL    bool   `flag:"url" help:"Print the URL instead of opening it."`
+}
+
+func (d *DashboardCommand) Run() error {
+	link, err := d.dashboardURL()
+	if err != nil {
+		return err
+	}
+
+	if d.URL {
+		fmt.Println(link)
+		return nil
+	}
+
+	fmt.Printf("Opening %s\n", link)
+	if err := openBrowser(link); err != nil {
+		logging.Warn("could not open browser automatically", "error", err)
+		fmt.Println("Please open the URL above manually in your browser.")
+	}
+	return nil
+}
+
+// dashboardURL returns the console URL of the current environment, or of
+// the entity when one was given
+func (d *DashboardCommand) dashboardURL() (string, error) {
+	console, err := d.Config.WebConsoleURL()
+	if err != nil {
+		return "", err
+	}
+	environment, err := defaultEnvironmentUUID(d.Config)
+	if err != nil {
+		return "", err
+	}
+
+	link := fmt.Sprintf("%s/environments/%s", console, environment)
+	if d.Entity == "" {
+		return link, nil
+	}
+
+	group, version, plural, namespace, name, err := parseEntityID(d.Entity)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/entities/%s/%s/%s/%s/%s", link, group, version, plural, namespace, name), nil
+}
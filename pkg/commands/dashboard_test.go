@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pkg/browser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	cfg.ConsoleURL = "https://console.example.com/"
+
+	var opened []string
+	openBrowser = func(url string) error {
+		opened = append(opened, url)
+		return nil
+	}
+	t.Cleanup(func() { openBrowser = browser.OpenURL })
+
+	cmd := DashboardCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	environmentURL := "https://console.example.com/environments/" + testEnvironmentID
+	assert.Equal(t, []string{environmentURL}, opened)
+	assert.Equal(t, "Opening "+environmentURL+"\n", output)
+
+	// Entities are deep-linked, and --url only prints the link
+	cmd.Entity = "entity://core/v1/services/default/payments-api"
+	cmd.URL = true
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Equal(t, environmentURL+"/entities/core/v1/services/default/payments-api\n", output)
+	assert.Len(t, opened, 1)
+
+	// --env links to the environment it names
+	cmd.Entity = ""
+	cmd.Config.EnvOverride = "22222222-2222-2222-2222-222222222222"
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Equal(t, "https://console.example.com/environments/22222222-2222-2222-2222-222222222222\n", output)
+
+	cmd.Entity = "payments-api"
+	_, err = captureOutput(t, cmd.Run)
+	assert.ErrorContains(t, err, "invalid entity ID format")
+
+	// Building links doesn't need the API
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	assert.Empty(t, srv.requests)
+}
+
+func TestDashboardCommand_BrowserFails(t *testing.T) {
+	_, cfg := newTestAPI(t)
+	cfg.ApiURL = "https://api.devgraph.ai"
+
+	openBrowser = func(string) error { return errors.New("no display") }
+	t.Cleanup(func() { openBrowser = browser.OpenURL })
+	logs := captureLogs(t)
+
+	cmd := DashboardCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Opening https://app.devgraph.ai/environments/"+testEnvironmentID)
+	assert.Contains(t, output, "Please open the URL above manually")
+	assert.Contains(t, logs.String(), "could not open browser automatically")
+}
+
+func TestDashboardCommand_UnknownConsole(t *testing.T) {
+	_, cfg := newTestAPI(t)
+
+	cmd := DashboardCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}}
+	_, err := captureOutput(t, cmd.Run)
+	assert.ErrorContains(t, err, "--console-url")
+}
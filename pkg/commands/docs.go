@@ -0,0 +1,275 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/kong"
+)
+
+// DocsCommand generates reference documentation from the CLI's own command
+// definitions, so it always matches the binary that wrote it
+type DocsCommand struct {
+	Man      DocsManCommand      `kong:"cmd,help='Write a man page for every command'"`
+	Markdown DocsMarkdownCommand `kong:"cmd,help='Write a Markdown reference page for every command'"`
+}
+
+type DocsManCommand struct {
+	Dir string `kong:"arg,type='path',help='Directory to write the man pages to. It is created if needed.'"`
+}
+
+type DocsMarkdownCommand struct {
+	Dir string `kong:"arg,type='path',help='Directory to write the Markdown pages to. It is created if needed.'"`
+}
+
+func (d *DocsManCommand) Run(ctx *kong.Context, build BuildInfo) error {
+	return writeCommandDocs(d.Dir, ctx.Model.Node, ".1", "man pages", func(doc commandDoc) []byte {
+		return renderManPage(doc, build)
+	})
+}
+
+func (d *DocsMarkdownCommand) Run(ctx *kong.Context) error {
+	return writeCommandDocs(d.Dir, ctx.Model.Node, ".md", "Markdown pages", renderMarkdownPage)
+}
+
+// commandDoc is what the reference documentation says about a command
+type commandDoc struct {
+	// Path is the command's words, starting with the application name
+	Path        []string
+	Help        string
+	Detail      string
+	Aliases     []string
+	Arguments   []*kong.Positional
+	Flags       []*kong.Flag
+	GlobalFlags []*kong.Flag
+	Commands    []*kong.Node
+}
+
+// name is the page name for the command, as in dg-entity-list
+func (d commandDoc) name() string {
+	return strings.Join(d.Path, "-")
+}
+
+// usage is the command's synopsis, as in dg entity get [flags] <entity-id>
+func (d commandDoc) usage() string {
+	usage := strings.Join(d.Path, " ")
+	for _, flags := range [][]*kong.Flag{d.GlobalFlags, d.Flags} {
+		for _, flag := range flags {
+			if flag.Required {
+				usage += " " + flag.Summary()
+			}
+		}
+	}
+	if len(d.Flags)+len(d.GlobalFlags) > 0 {
+		usage += " [flags]"
+	}
+	for _, arg := range d.Arguments {
+		usage += " " + arg.Summary()
+	}
+	if len(d.Commands) > 0 {
+		usage += " <command>"
+	}
+	return usage
+}
+
+// documentCommands returns the documentation of root and every command
+// beneath it that isn't hidden
+func documentCommands(root *kong.Node) []commandDoc {
+	var docs []commandDoc
+	var visit func(node *kong.Node, path []string, inherited []*kong.Flag)
+	visit = func(node *kong.Node, path []string, inherited []*kong.Flag) {
+		doc := commandDoc{
+			Path:        path,
+			Help:        node.Help,
+			Detail:      node.Detail,
+			Aliases:     node.Aliases,
+			Arguments:   node.Positional,
+			Flags:       visibleFlags(node.Flags),
+			GlobalFlags: inherited,
+		}
+		for _, child := range node.Children {
+			if !child.Hidden {
+				doc.Commands = append(doc.Commands, child)
+			}
+		}
+		docs = append(docs, doc)
+
+		// Flags of a command apply to the commands beneath it
+		inherited = append(append([]*kong.Flag{}, inherited...), doc.Flags...)
+		for _, child := range doc.Commands {
+			visit(child, append(append([]string{}, path...), child.Name), inherited)
+		}
+	}
+	visit(root, []string{root.Name}, nil)
+	return docs
+}
+
+// visibleFlags returns the flags that aren't hidden
+func visibleFlags(flags []*kong.Flag) []*kong.Flag {
+	var visible []*kong.Flag
+	for _, flag := range flags {
+		if !flag.Hidden {
+			visible = append(visible, flag)
+		}
+	}
+	return visible
+}
+
+// writeCommandDocs writes a page rendered by render for every command to
+// dir, replacing pages written before
+func writeCommandDocs(dir string, root *kong.Node, ext, kind string, render func(commandDoc) []byte) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	docs := documentCommands(root)
+	for _, doc := range docs {
+		path := filepath.Join(dir, doc.name()+ext)
+		if err := os.WriteFile(path, render(doc), 0644); err != nil { // #nosec G306 - documentation is meant to be readable
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	fmt.Printf("✅ Wrote %d %s to %s\n", len(docs), kind, dir)
+	return nil
+}
+
+// renderManPage renders doc as a roff man page in section 1
+func renderManPage(doc commandDoc, build BuildInfo) []byte {
+	var b bytes.Buffer
+	date := ""
+	if build.Date != "unknown" && len(build.Date) >= len("2006-01-02") {
+		date = build.Date[:len("2006-01-02")]
+	}
+	fmt.Fprintf(&b, ".TH \"%s\" \"1\" \"%s\" \"dg %s\" \"Devgraph Manual\"\n",
+		strings.ToUpper(doc.name()), date, roffEscape(build.Version))
+
+	b.WriteString(".SH NAME\n")
+	fmt.Fprintf(&b, "%s \\- %s\n", roffEscape(doc.name()), roffEscape(doc.Help))
+
+	b.WriteString(".SH SYNOPSIS\n")
+	fmt.Fprintf(&b, "\\fB%s\\fR\n", roffEscape(doc.usage()))
+
+	if doc.Detail != "" || doc.Help != "" {
+		b.WriteString(".SH DESCRIPTION\n")
+		description := doc.Detail
+		if description == "" {
+			description = doc.Help
+		}
+		b.WriteString(roffEscape(description) + "\n")
+	}
+	if len(doc.Aliases) > 0 {
+		fmt.Fprintf(&b, ".PP\nAlso available as: %s\n", roffEscape(strings.Join(doc.Aliases, ", ")))
+	}
+
+	if len(doc.Arguments) > 0 {
+		b.WriteString(".SH ARGUMENTS\n")
+		for _, arg := range doc.Arguments {
+			fmt.Fprintf(&b, ".TP\n\\fI%s\\fR\n%s\n", roffEscape(arg.Summary()), roffEscape(arg.Help))
+		}
+	}
+
+	writeFlags := func(title string, flags []*kong.Flag) {
+		if len(flags) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, ".SH %s\n", title)
+		for _, flag := range flags {
+			fmt.Fprintf(&b, ".TP\n\\fB%s\\fR\n%s\n", roffEscape(flag.String()), roffEscape(flag.Help))
+		}
+	}
+	writeFlags("OPTIONS", doc.Flags)
+	writeFlags("GLOBAL OPTIONS", doc.GlobalFlags)
+
+	if len(doc.Commands) > 0 {
+		b.WriteString(".SH COMMANDS\n")
+		for _, child := range doc.Commands {
+			fmt.Fprintf(&b, ".TP\n\\fB%s\\fR(1)\n%s\n", roffEscape(doc.name()+"-"+child.Name), roffEscape(child.Help))
+		}
+	}
+
+	if len(doc.Path) > 1 {
+		b.WriteString(".SH SEE ALSO\n")
+		fmt.Fprintf(&b, "\\fB%s\\fR(1)\n", roffEscape(strings.Join(doc.Path[:len(doc.Path)-1], "-")))
+	}
+	return b.Bytes()
+}
+
+// roffEscape escapes text for a roff man page. Lines can't start with a
+// period or quote, which roff would read as requests.
+func roffEscape(text string) string {
+	text = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(text)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderMarkdownPage renders doc as a Markdown reference page, linking to
+// the pages of the commands around it
+func renderMarkdownPage(doc commandDoc) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n\n", strings.Join(doc.Path, " "))
+	if doc.Help != "" {
+		fmt.Fprintf(&b, "%s\n\n", markdownText(doc.Help))
+	}
+	if doc.Detail != "" {
+		fmt.Fprintf(&b, "%s\n\n", markdownText(doc.Detail))
+	}
+	fmt.Fprintf(&b, "## Usage\n\n```\n%s\n```\n\n", doc.usage())
+	if len(doc.Aliases) > 0 {
+		fmt.Fprintf(&b, "Also available as: `%s`\n\n", strings.Join(doc.Aliases, "`, `"))
+	}
+
+	if len(doc.Arguments) > 0 {
+		b.WriteString("## Arguments\n\n| Argument | Description |\n| --- | --- |\n")
+		for _, arg := range doc.Arguments {
+			fmt.Fprintf(&b, "| `%s` | %s |\n", arg.Summary(), markdownCell(arg.Help))
+		}
+		b.WriteString("\n")
+	}
+
+	writeFlags := func(title string, flags []*kong.Flag) {
+		if len(flags) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "## %s\n\n| Flag | Description |\n| --- | --- |\n", title)
+		for _, flag := range flags {
+			fmt.Fprintf(&b, "| `%s` | %s |\n", flag.String(), markdownCell(flag.Help))
+		}
+		b.WriteString("\n")
+	}
+	writeFlags("Options", doc.Flags)
+	writeFlags("Global options", doc.GlobalFlags)
+
+	if len(doc.Commands) > 0 {
+		b.WriteString("## Commands\n\n| Command | Description |\n| --- | --- |\n")
+		for _, child := range doc.Commands {
+			fmt.Fprintf(&b, "| [%s %s](%s-%s.md) | %s |\n",
+				strings.Join(doc.Path, " "), child.Name, doc.name(), child.Name, markdownCell(child.Help))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(doc.Path) > 1 {
+		parent := doc.Path[:len(doc.Path)-1]
+		fmt.Fprintf(&b, "## See also\n\n- [%s](%s.md)\n", strings.Join(parent, " "), strings.Join(parent, "-"))
+	}
+	return append(bytes.TrimRight(b.Bytes(), "\n"), '\n')
+}
+
+// markdownText escapes text so placeholders like <name> aren't read as HTML
+func markdownText(text string) string {
+	return strings.NewReplacer("<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// markdownCell escapes text for a Markdown table cell
+func markdownCell(text string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(markdownText(text))
+}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/kong"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// docsTestCLI is a small command tree to generate documentation for
+type docsTestCLI struct {
+	Timings bool `help:"Print timings"`
+
+	Entity struct {
+		Get struct {
+			ID     string `arg:"" help:"Entity ID in the format <group>/<version>"`
+			Output string `short:"o" default:"table" help:"Output format | table or json"`
+			Secret bool   `hidden:"" help:"Not documented"`
+		} `cmd:"" help:"Get an entity"`
+	} `cmd:"" help:"Manage entities"`
+
+	Internal struct{} `cmd:"" hidden:"" help:"Not documented"`
+
+	Docs DocsCommand `cmd:"" help:"Generate reference docs"`
+}
+
+// runDocs generates the docs of docsTestCLI with args
+func runDocs(t *testing.T, args ...string) {
+	t.Helper()
+	parser, err := kong.New(&docsTestCLI{}, kong.Name("dg"), kong.Bind(BuildInfo{Version: "1.2.3", Date: "2026-01-02T03:04:05Z"}))
+	require.NoError(t, err)
+	ctx, err := parser.Parse(args)
+	require.NoError(t, err)
+	_, err = captureOutput(t, func() error { return ctx.Run() })
+	require.NoError(t, err)
+}
+
+func TestDocsManCommand(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "man1")
+	runDocs(t, "docs", "man", dir)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var pages []string
+	for _, entry := range entries {
+		pages = append(pages, entry.Name())
+	}
+	// Hidden commands aren't documented
+	assert.ElementsMatch(t, []string{"dg.1", "dg-entity.1", "dg-entity-get.1", "dg-docs.1", "dg-docs-man.1", "dg-docs-markdown.1"}, pages)
+
+	page, err := os.ReadFile(filepath.Join(dir, "dg-entity-get.1"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), `.TH "DG-ENTITY-GET" "1" "2026-01-02" "dg 1.2.3" "Devgraph Manual"`)
+	assert.Contains(t, string(page), "dg\\-entity\\-get \\- Get an entity\n")
+	assert.Contains(t, string(page), ".SH SYNOPSIS\n\\fBdg entity get [flags] <id>\\fR\n")
+	assert.Contains(t, string(page), ".TP\n\\fB\\-o, \\-\\-output=\"table\"\\fR\nOutput format | table or json\n")
+	assert.Contains(t, string(page), ".SH GLOBAL OPTIONS\n")
+	assert.Contains(t, string(page), "\\fB\\-\\-timings\\fR\n")
+	assert.NotContains(t, string(page), "secret")
+	assert.Contains(t, string(page), ".SH SEE ALSO\n\\fBdg\\-entity\\fR(1)\n")
+
+	page, err = os.ReadFile(filepath.Join(dir, "dg-entity.1"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), ".SH COMMANDS\n.TP\n\\fBdg\\-entity\\-get\\fR(1)\nGet an entity\n")
+}
+
+func TestDocsMarkdownCommand(t *testing.T) {
+	dir := t.TempDir()
+	runDocs(t, "docs", "markdown", dir)
+
+	page, err := os.ReadFile(filepath.Join(dir, "dg-entity-get.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# dg entity get\n"+
+		"\n"+
+		"Get an entity\n"+
+		"\n"+
+		"## Usage\n"+
+		"\n"+
+		"```\n"+
+		"dg entity get [flags] <id>\n"+
+		"```\n"+
+		"\n"+
+		"## Arguments\n"+
+		"\n"+
+		"| Argument | Description |\n"+
+		"| --- | --- |\n"+
+		"| `<id>` | Entity ID in the format &lt;group&gt;/&lt;version&gt; |\n"+
+		"\n"+
+		"## Options\n"+
+		"\n"+
+		"| Flag | Description |\n"+
+		"| --- | --- |\n"+
+		"| `-o, --output=\"table\"` | Output format \\| table or json |\n"+
+		"\n"+
+		"## Global options\n"+
+		"\n"+
+		"| Flag | Description |\n"+
+		"| --- | --- |\n"+
+		"| `-h, --help` | Show context-sensitive help. |\n"+
+		"| `--timings` | Print timings |\n"+
+		"\n"+
+		"## See also\n"+
+		"\n"+
+		"- [dg entity](dg-entity.md)\n", string(page))
+
+	page, err = os.ReadFile(filepath.Join(dir, "dg.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "| [dg entity](dg-entity.md) | Manage entities |\n")
+	assert.NotContains(t, string(page), "internal")
+}
+
+func TestRoffEscape(t *testing.T) {
+	assert.Equal(t, `\-\-env`, roffEscape("--env"))
+	assert.Equal(t, `C:\eUsers`, roffEscape(`C:\Users`))
+	assert.Equal(t, "first\n\\&.second\n\\&'third", roffEscape("first\n.second\n'third"))
+}
@@ -5,12 +5,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
 	"sync"
 
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
+	"github.com/arctir/devgraph-cli/pkg/logging"
+	"github.com/arctir/devgraph-cli/pkg/output"
 	"github.com/arctir/devgraph-cli/pkg/util"
 	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
-	"github.com/fatih/color"
+	"golang.org/x/term"
 	"gopkg.in/yaml.v3"
 )
 
@@ -29,305 +33,54 @@ func parseEntityID(entityID string) (group, version, plural, namespace, name str
 	return parts[0], parts[1], parts[2], parts[3], parts[4], nil
 }
 
-// FilteredEntity represents an entity with only the required fields
-type FilteredEntity struct {
-	ApiVersion string      `json:"apiVersion" yaml:"apiVersion"`
-	Kind       string      `json:"kind" yaml:"kind"`
-	Metadata   interface{} `json:"metadata" yaml:"metadata"`
-	Spec       interface{} `json:"spec,omitempty" yaml:"spec,omitempty"`
-	Status     interface{} `json:"status,omitempty" yaml:"status,omitempty"`
-}
-
-// FilteredEntityDefinition represents an entity definition with only the required fields
-type FilteredEntityDefinition struct {
-	Group       string      `json:"group" yaml:"group"`
-	Kind        string      `json:"kind" yaml:"kind"`
-	ListKind    string      `json:"listKind" yaml:"listKind"`
-	Plural      string      `json:"plural,omitempty" yaml:"plural,omitempty"`
-	Singular    string      `json:"singular" yaml:"singular"`
-	Name        string      `json:"name,omitempty" yaml:"name,omitempty"`
-	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
-	Spec        interface{} `json:"spec" yaml:"spec"`
-	Storage     bool        `json:"storage,omitempty" yaml:"storage,omitempty"`
-	Served      bool        `json:"served,omitempty" yaml:"served,omitempty"`
-}
-
-// FilteredEntityRelation represents an entity relation with only the required fields
-type FilteredEntityRelation struct {
-	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
-	Relation  string `json:"relation" yaml:"relation"`
-	Source    string `json:"source" yaml:"source"`
-	Target    string `json:"target" yaml:"target"`
-}
-
-// filterEntity creates a FilteredEntity with only the required fields
-func filterEntity(entity api.EntityResponse) FilteredEntity {
-	filtered := FilteredEntity{
-		ApiVersion: entity.ApiVersion,
-		Kind:       entity.Kind,
-		Metadata:   cleanMetadata(entity.Metadata),
-	}
-
-	// Extract actual values from optional types
-	if entity.Spec.IsSet() {
-		if spec, ok := entity.Spec.Get(); ok {
-			filtered.Spec = cleanSpec(spec)
-		}
-	}
-
-	if entity.Status.IsSet() {
-		if status, ok := entity.Status.Get(); ok {
-			filtered.Status = cleanStatus(status)
-		}
-	}
-
-	return filtered
-}
-
-// filterEntityDefinition creates a FilteredEntityDefinition with only the required fields
-func filterEntityDefinition(def api.EntityDefinitionResponse) FilteredEntityDefinition {
-	filtered := FilteredEntityDefinition{
-		Group:    def.Group,
-		Kind:     def.Kind,
-		ListKind: def.ListKind,
-		Singular: def.Singular,
-		Spec:     cleanDefinitionSpec(def.Spec),
-	}
-
-	// Handle optional plural
-	if def.Plural.IsSet() {
-		if plural, ok := def.Plural.Get(); ok {
-			filtered.Plural = plural
-		}
-	}
-
-	// Handle optional name
-	if def.Name.IsSet() {
-		if name, ok := def.Name.Get(); ok {
-			filtered.Name = name
-		}
-	}
-
-	// Handle optional description
-	if def.Description.IsSet() {
-		if desc, ok := def.Description.Get(); ok {
-			filtered.Description = desc
-		}
-	}
-
-	// Handle optional storage
-	if def.Storage.IsSet() {
-		if storage, ok := def.Storage.Get(); ok {
-			filtered.Storage = storage
-		}
-	}
-
-	// Handle optional served
-	if def.Served.IsSet() {
-		if served, ok := def.Served.Get(); ok {
-			filtered.Served = served
-		}
-	}
-
-	return filtered
-}
-
-// cleanDefinitionSpec processes definition spec fields
-func cleanDefinitionSpec(spec api.EntityDefinitionResponseSpec) map[string]interface{} {
-	result := make(map[string]interface{})
-
-	// Convert spec to map for processing
-	specBytes, err := json.Marshal(spec)
-	if err != nil {
-		return result
-	}
-
-	var specMap map[string]interface{}
-	if err := json.Unmarshal(specBytes, &specMap); err != nil {
-		return result
-	}
-
-	// Process each field to clean up byte arrays and optional wrappers
-	for key, value := range specMap {
-		result[key] = cleanValue(value)
-	}
-
-	return result
-}
-
-// filterEntityRelation creates a FilteredEntityRelation with only the required fields
-func filterEntityRelation(rel api.EntityRelationResponse) FilteredEntityRelation {
-	filtered := FilteredEntityRelation{
-		Relation: rel.Relation,
-		Source:   rel.Source.ID,
-		Target:   rel.Target.ID,
-	}
-
-	// Handle optional namespace
-	if rel.Namespace.IsSet() {
-		if ns, ok := rel.Namespace.Get(); ok {
-			filtered.Namespace = ns
-		}
-	}
-
-	return filtered
-}
-
-// cleanMetadata removes the 'set' wrapper from optional fields in metadata
-func cleanMetadata(metadata api.EntityMetadata) map[string]interface{} {
-	result := map[string]interface{}{
-		"name":      metadata.Name,
-		"namespace": metadata.Namespace,
-	}
-
-	// Handle optional labels
-	if metadata.Labels.IsSet() {
-		if labels, ok := metadata.Labels.Get(); ok && len(labels) > 0 {
-			result["labels"] = labels
-		}
-	}
-
-	// Handle optional annotations
-	if metadata.Annotations.IsSet() {
-		if annotations, ok := metadata.Annotations.Get(); ok && len(annotations) > 0 {
-			result["annotations"] = annotations
-		}
-	}
-
-	return result
-}
-
-// cleanSpec processes spec fields to ensure proper serialization
-func cleanSpec(spec api.EntityResponseSpec) map[string]interface{} {
-	result := make(map[string]interface{})
-
-	// Convert spec to map for processing
-	specBytes, err := json.Marshal(spec)
-	if err != nil {
-		return result
-	}
-
-	var specMap map[string]interface{}
-	if err := json.Unmarshal(specBytes, &specMap); err != nil {
-		return result
-	}
-
-	// Process each field to clean up byte arrays and optional wrappers
-	for key, value := range specMap {
-		result[key] = cleanValue(value)
-	}
-
-	return result
-}
-
-// cleanStatus processes status fields to ensure proper serialization
-func cleanStatus(status api.EntityStatus) map[string]interface{} {
-	result := make(map[string]interface{})
-
-	// Convert status to map for processing
-	statusBytes, err := json.Marshal(status)
-	if err != nil {
-		return result
-	}
-
-	var statusMap map[string]interface{}
-	if err := json.Unmarshal(statusBytes, &statusMap); err != nil {
-		return result
-	}
-
-	// Process each field to clean up byte arrays and optional wrappers
-	for key, value := range statusMap {
-		result[key] = cleanValue(value)
-	}
-
-	return result
-}
-
-// cleanValue recursively cleans values by converting byte arrays to strings
-// and removing 'set' wrappers from optional fields
-func cleanValue(value interface{}) interface{} {
-	switch v := value.(type) {
-	case map[string]interface{}:
-		// Check if this is an optional wrapper with 'set' and 'value' fields
-		if set, hasSet := v["set"].(bool); hasSet && set {
-			if val, hasValue := v["value"]; hasValue {
-				return cleanValue(val)
-			}
-		}
-
-		// Otherwise, recursively clean the map
-		cleaned := make(map[string]interface{})
-		for key, val := range v {
-			cleaned[key] = cleanValue(val)
-		}
-		return cleaned
-
-	case []interface{}:
-		// Check if this looks like a byte array (all numbers 0-255)
-		if len(v) > 0 {
-			allBytes := true
-			bytes := make([]byte, len(v))
-			for i, item := range v {
-				if num, ok := item.(float64); ok && num >= 0 && num <= 255 && num == float64(int(num)) {
-					bytes[i] = byte(num)
-				} else {
-					allBytes = false
-					break
-				}
-			}
-
-			if allBytes {
-				return string(bytes)
-			}
-		}
-
-		// Otherwise, recursively clean the array
-		cleaned := make([]interface{}, len(v))
-		for i, val := range v {
-			cleaned[i] = cleanValue(val)
-		}
-		return cleaned
-
-	default:
-		return v
-	}
-}
-
-// displayEntityList displays a list of entities in a table format
-func displayEntityList(entities []api.EntityResponse) error {
-	if len(entities) == 0 {
-		fmt.Println("No entities found.")
-		return nil
-	}
-
-	return displayEntitiesAsTable(entities)
-}
-
-// displayEntitiesAsTable displays entities in table format
-func displayEntitiesAsTable(entities []api.EntityResponse) error {
-	// Prepare data for table display
-	headers := []string{"Entity ID", "Name", "Namespace", "API Version", "Kind"}
-	data := make([]map[string]interface{}, len(entities))
+// Backups are written in the devgraph package's formats, so backups made by
+// dg and by programs using the package can be restored by either
+type (
+	FilteredEntity           = devgraph.Entity
+	FilteredEntityDefinition = devgraph.EntityDefinition
+	FilteredEntityRelation   = devgraph.EntityRelation
+)
 
+// entityResult is entities as printed by the list commands, named by their
+// entity IDs
+func entityResult(entities []api.EntityResponse) output.Result {
+	structured := make([]FilteredEntity, len(entities))
+	rows := make([]map[string]any, len(entities))
+	names := make([]string, len(entities))
 	for i, entity := range entities {
+		structured[i] = devgraph.NewEntity(entity)
 		// Use the entity ID provided by the API response
-		data[i] = map[string]interface{}{
+		rows[i] = map[string]any{
 			"Entity ID":   entity.ID,
 			"Name":        entity.Name,
 			"Namespace":   entity.Namespace,
 			"API Version": entity.ApiVersion,
 			"Kind":        entity.Kind,
 		}
+		names[i] = entity.ID
 	}
 
-	displayEntityTable(data, headers)
-	return nil
+	return output.Result{
+		Data:    structured,
+		Headers: []string{"Entity ID", "Name", "Namespace", "API Version", "Kind"},
+		Rows:    rows,
+		Wide:    []string{"Entity ID"},
+		Empty:   "No entities found.",
+		Names:   names,
+	}
+}
+
+// displayEntityList displays a list of entities in format
+func displayEntityList(entities []api.EntityResponse, format string) error {
+	return output.Print(format, entityResult(entities))
 }
 
 // displaySingleEntity displays a single entity in the specified format with filtered fields
 func displaySingleEntity(entity api.EntityResponse, outputFormat string) error {
-	// First convert to JSON to get clean serialization
-	jsonData, err := json.Marshal(entity)
+	// First convert to JSON to get clean serialization. The generated
+	// encoder has a pointer receiver, and optional fields that aren't set
+	// only encode through it.
+	jsonData, err := json.Marshal(&entity)
 	if err != nil {
 		return fmt.Errorf("failed to marshal entity to JSON: %w", err)
 	}
@@ -353,146 +106,24 @@ func displaySingleEntity(entity api.EntityResponse, outputFormat string) error {
 		filteredMap["status"] = status
 	}
 
-	switch strings.ToLower(outputFormat) {
-	case "yaml", "yml":
-		yamlData, err := yaml.Marshal(filteredMap)
-		if err != nil {
-			return fmt.Errorf("failed to marshal entity to YAML: %w", err)
-		}
-		fmt.Print(string(yamlData))
-	case "json":
-		fallthrough
-	default:
-		jsonData, err := json.MarshalIndent(filteredMap, "", "  ")
-		if err != nil {
-			return fmt.Errorf("failed to marshal entity to JSON: %w", err)
-		}
-		fmt.Println(string(jsonData))
-	}
-
-	return nil
-}
-
-// displayEntityTable creates a table for entities with no truncation on Entity ID column
-func displayEntityTable(data []map[string]interface{}, headers []string) {
-	if len(data) == 0 {
-		fmt.Println("No data to display.")
-		return
-	}
-
-	// Calculate column widths
-	colWidths := make([]int, len(headers))
-
-	// Initialize with header widths
-	for i, header := range headers {
-		colWidths[i] = len(header)
-	}
-
-	// Check data widths
-	for _, row := range data {
-		for i, header := range headers {
-			if val, ok := row[header]; ok {
-				var valueStr string
-				switch v := val.(type) {
-				case string:
-					valueStr = v
-				case int:
-					valueStr = fmt.Sprintf("%d", v)
-				case float64:
-					valueStr = fmt.Sprintf("%.2f", v)
-				default:
-					valueStr = fmt.Sprintf("%v", v)
-				}
-
-				// Don't truncate Entity ID column, but limit other columns for readability
-				if header != "Entity ID" {
-					maxWidth := 60
-					if len(valueStr) > maxWidth {
-						valueStr = valueStr[:maxWidth-3] + "..."
-					}
-				}
-
-				if len(valueStr) > colWidths[i] {
-					colWidths[i] = len(valueStr)
-				}
-			}
-		}
-	}
-
-	// Add some spacing
-	fmt.Println()
-
-	// Print headers with color
-	headerColor := color.New(color.FgBlue, color.Bold)
-	for i, header := range headers {
-		if i > 0 {
-			fmt.Print("  ")
-		}
-		coloredHeader := headerColor.Sprint(header)
-		fmt.Print(coloredHeader)
-		padding := colWidths[i] - len(header)
-		if padding > 0 {
-			fmt.Print(strings.Repeat(" ", padding))
-		}
-	}
-	fmt.Println()
-
-	// Print separator line
-	for i := range headers {
-		if i > 0 {
-			fmt.Print("  ")
-		}
-		fmt.Print(strings.Repeat("─", colWidths[i]))
-	}
-	fmt.Println()
-
-	// Print data rows
-	gray := color.New(color.FgHiBlack)
-	for _, row := range data {
-		for i, header := range headers {
-			if i > 0 {
-				fmt.Print("  ")
-			}
-
-			var valueStr string
-			if val, ok := row[header]; ok {
-				switch v := val.(type) {
-				case string:
-					valueStr = v
-				case int:
-					valueStr = fmt.Sprintf("%d", v)
-				case float64:
-					valueStr = fmt.Sprintf("%.2f", v)
-				default:
-					valueStr = fmt.Sprintf("%v", v)
-				}
-			} else {
-				valueStr = gray.Sprint("-")
-			}
-
-			// Don't truncate Entity ID column
-			if header != "Entity ID" {
-				maxWidth := 60
-				if len(valueStr) > maxWidth {
-					valueStr = valueStr[:maxWidth-3] + "..."
-				}
-			}
-
-			fmt.Printf("%-*s", colWidths[i], valueStr)
-		}
-		fmt.Println()
-	}
-
-	// Add spacing after
-	fmt.Println()
+	// As a table, the entity is the row it has in entity list
+	result := entityResult([]api.EntityResponse{entity})
+	result.Data = filteredMap
+	return output.Print(outputFormat, result)
 }
 
 type EntityCommand struct {
 	Create        EntityCreateCommand        `cmd:"create" help:"Create a new entity."`
+	Apply         EntityApplyCommand         `cmd:"apply" help:"Create or update the entities in manifest files."`
+	Diff          EntityDiffCommand          `cmd:"diff" help:"Show how the entities in manifest files differ from the server."`
+	Update        EntityUpdateCommand        `cmd:"update" help:"Replace an entity with the one in a file."`
+	Patch         EntityPatchCommand         `cmd:"patch" help:"Change fields of an entity with a JSON merge patch."`
+	Edit          EntityEditCommand          `cmd:"edit" help:"Edit an entity in your editor and update it with what you save."`
 	List          EntityListCommand          `cmd:"" help:"List entities."`
 	Get           EntityGetCommand           `cmd:"get" help:"Get an entity by ID."`
 	Delete        EntityDeleteCommand        `cmd:"delete" help:"Delete an entity by ID."`
 	Relationships EntityRelationshipsCommand `cmd:"relationships" help:"Show relationships for an entity."`
+	Graph         EntityGraphCommand         `cmd:"graph" help:"Write entities and their relations as a DOT, Mermaid or GraphML graph."`
 	Backup        EntityBackupCommand        `cmd:"backup" help:"Backup entities to a directory."`
 	Restore       EntityRestoreCommand       `cmd:"restore" help:"Restore entities from a backup directory."`
 }
@@ -503,7 +134,7 @@ type EntityCreateCommand struct {
 	Version   string `arg:"" required:"" help:"Version of the entity (e.g., v1, v1beta1)."`
 	Namespace string `arg:"" required:"" help:"Namespace of the entity."`
 	Plural    string `arg:"" required:"" help:"Plural form of the entity kind (e.g., deployments, services)."`
-	FileName  string `arg:"" required:"" help:"Path to the entity JSON file."`
+	FileName  string `arg:"" required:"" help:"Path to the entity JSON file, or - to read it from stdin."`
 }
 
 type EntityListCommand struct {
@@ -513,12 +144,12 @@ type EntityListCommand struct {
 	FieldSelector string `flag:"field-selector,f" help:"Filter entities by field selector (e.g., 'spec.metadata.owner=team-a')."`
 	Limit         int    `flag:"limit" default:"1000" help:"Maximum number of entities to return."`
 	Offset        int    `flag:"offset" default:"0" help:"Offset for pagination."`
+	All           bool   `help:"List every matching entity, reading page after page until there are no more (--limit and --offset are ignored)."`
 }
 
 type EntityGetCommand struct {
 	EnvWrapperCommand
 	EntityID string `arg:"" required:"" help:"Entity ID in the format [entity://]<group>/<version>/<plural>/<namespace>/<name>."`
-	Output   string `flag:"output,o" default:"json" help:"Output format: json, yaml."`
 }
 
 type EntityDeleteCommand struct {
@@ -529,7 +160,6 @@ type EntityDeleteCommand struct {
 type EntityRelationshipsCommand struct {
 	EnvWrapperCommand
 	EntityID string `arg:"" required:"" help:"Entity ID in the format [entity://]<group>/<version>/<plural>/<namespace>/<name>."`
-	Output   string `flag:"output,o" default:"table" help:"Output format: table, json, yaml."`
 }
 
 type EntityBackupCommand struct {
@@ -543,9 +173,9 @@ type EntityBackupCommand struct {
 
 type EntityRestoreCommand struct {
 	EnvWrapperCommand
-	InputDir string `arg:"" required:"" help:"Path to backup directory to restore."`
-	DryRun   bool   `flag:"dry-run" help:"Show what would be restored without actually restoring."`
-	Workers  int    `flag:"workers,w" default:"10" help:"Number of concurrent workers for restore operations."`
+	InputDir      string `arg:"" required:"" help:"Path to backup directory to restore."`
+	Workers       int    `flag:"workers,w" help:"Number of concurrent workers for restore operations (defaults to the default_workers setting, or 10)."`
+	EnforceLimits bool   `flag:"enforce-limits" help:"Abort instead of warning when the restore would exceed the plan's entity quota."`
 }
 
 func (e *EntityCreateCommand) Run() error {
@@ -554,7 +184,7 @@ func (e *EntityCreateCommand) Run() error {
 		return fmt.Errorf("failed to create authenticated client: %w", err)
 	}
 
-	data, err := os.ReadFile(e.FileName)
+	data, err := util.ReadFileOrStdin(e.FileName)
 	if err != nil {
 		return fmt.Errorf("failed to read file %s: %w", e.FileName, err)
 	}
@@ -587,6 +217,27 @@ func (e *EntityCreateCommand) Run() error {
 }
 
 func (e *EntityListCommand) Run() error {
+	format := e.OutputFormat(output.Table)
+	if err := output.Check(format, output.Table, output.JSON, output.YAML, output.Name); err != nil {
+		return err
+	}
+
+	if e.Config.Offline {
+		snapshot, err := loadOfflineSnapshot(e.Config)
+		if err != nil {
+			return err
+		}
+		limit, offset := e.Limit, e.Offset
+		if e.All {
+			limit, offset = 0, 0
+		}
+		entities, err := snapshot.listEntities(e.Name, e.Label, e.FieldSelector, limit, offset)
+		if err != nil {
+			return err
+		}
+		return displayEntityList(entities, format)
+	}
+
 	client, err := util.GetAuthenticatedClient(e.Config)
 	if err != nil {
 		return fmt.Errorf("failed to create authenticated client: %w", err)
@@ -605,6 +256,17 @@ func (e *EntityListCommand) Run() error {
 	if e.FieldSelector != "" {
 		params.FieldSelector = api.NewOptString(e.FieldSelector)
 	}
+
+	if e.All {
+		progress, done := entityProgress()
+		entities, _, err := devgraph.ListAllEntitiesWithProgress(context.Background(), client, params, progress)
+		done()
+		if err != nil {
+			return err
+		}
+		return displayEntityList(entities, format)
+	}
+
 	if e.Limit > 0 {
 		params.Limit = api.NewOptInt(e.Limit)
 	}
@@ -622,24 +284,21 @@ func (e *EntityListCommand) Run() error {
 	case *api.EntityResultSetResponse:
 		// EntityResultSetResponse contains PrimaryEntities, RelatedEntities, and Relations
 		// For the list command, we're primarily interested in PrimaryEntities
-		entities := r.PrimaryEntities
-		if len(entities) == 0 {
-			fmt.Println("No entities found.")
-			return nil
+		if e.Limit > 0 && len(r.PrimaryEntities) == e.Limit {
+			logging.Warn(fmt.Sprintf("showing the first %d entities; there may be more (use --all to list every one)", e.Limit))
 		}
-		return displayEntityList(entities)
+		return displayEntityList(r.PrimaryEntities, format)
 	case *api.GetEntitiesNotFound:
-		fmt.Println("No entities found.")
-		return nil
+		return displayEntityList(nil, format)
 	default:
 		return fmt.Errorf("unexpected response type: %T", resp)
 	}
 }
 
 func (e *EntityGetCommand) Run() error {
-	client, err := util.GetAuthenticatedClient(e.Config)
-	if err != nil {
-		return fmt.Errorf("failed to create authenticated client: %w", err)
+	format := e.OutputFormat(output.JSON)
+	if err := output.Check(format, output.Table, output.JSON, output.YAML, output.Name); err != nil {
+		return err
 	}
 
 	// Parse the entity ID to extract individual components
@@ -648,6 +307,23 @@ func (e *EntityGetCommand) Run() error {
 		return err
 	}
 
+	if e.Config.Offline {
+		snapshot, err := loadOfflineSnapshot(e.Config)
+		if err != nil {
+			return err
+		}
+		entity, ok := snapshot.entity(group, version, plural, namespace, name)
+		if !ok {
+			return fmt.Errorf("entity not found")
+		}
+		return displaySingleEntity(entity, format)
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
 	params := api.GetEntityParams{
 		Group:     group,
 		Version:   version,
@@ -662,7 +338,7 @@ func (e *EntityGetCommand) Run() error {
 	// Check if response is successful
 	switch r := resp.(type) {
 	case *api.EntityWithRelationsResponse:
-		return displaySingleEntity(r.Entity, e.Output)
+		return displaySingleEntity(r.Entity, format)
 	case *api.GetEntityNotFound:
 		return fmt.Errorf("entity not found")
 	case *api.HTTPValidationError:
@@ -708,9 +384,8 @@ func (e *EntityDeleteCommand) Run() error {
 }
 
 func (e *EntityRelationshipsCommand) Run() error {
-	client, err := util.GetAuthenticatedClient(e.Config)
-	if err != nil {
-		return fmt.Errorf("failed to create authenticated client: %w", err)
+	if err := output.Check(e.OutputFormat(output.Table), output.Table, output.JSON, output.YAML); err != nil {
+		return err
 	}
 
 	// Parse the entity ID to extract individual components
@@ -722,60 +397,54 @@ func (e *EntityRelationshipsCommand) Run() error {
 	// Build the entity reference
 	entityRef := fmt.Sprintf("%s/%s/%s/%s/%s", group, version, plural, namespace, name)
 
-	// Instead of trying to filter with field selectors, let's get all entities and filter relationships
-	params := api.GetEntitiesParams{
-		Limit: api.NewOptInt(1000), // Get more results to ensure we capture all relationships
+	if e.Config.Offline {
+		snapshot, err := loadOfflineSnapshot(e.Config)
+		if err != nil {
+			return err
+		}
+		return e.displayRelationships(relationsOf(snapshot.Relations, entityRef), entityRef)
 	}
 
-	resp, err := client.GetEntities(context.Background(), params)
+	client, err := util.GetAuthenticatedClient(e.Config)
 	if err != nil {
-		return fmt.Errorf("failed to get entities: %w", err)
+		return fmt.Errorf("failed to create authenticated client: %w", err)
 	}
 
-	// Handle the response
-	switch r := resp.(type) {
-	case *api.EntityResultSetResponse:
-		// Filter relations that involve our target entity
-		var relevantRelations []api.EntityRelationResponse
-		for _, relation := range r.Relations {
-			if relation.Source.ID == entityRef || relation.Target.ID == entityRef {
-				relevantRelations = append(relevantRelations, relation)
-			}
-		}
-
-		if len(relevantRelations) == 0 {
-			fmt.Printf("No relationships found for entity: %s\n", e.EntityID)
-			return nil
-		}
-
-		return e.displayRelationships(relevantRelations, entityRef)
-	case *api.GetEntitiesNotFound:
-		fmt.Printf("No relationships found for entity: %s\n", e.EntityID)
-		return nil
-	default:
-		return fmt.Errorf("unexpected response type: %T", resp)
+	// The entity comes with its relations in both directions, so there's no
+	// need to read the whole catalog to find them
+	entity, err := getEntityWithRelations(context.Background(), client, api.GetEntityParams{
+		Group:     group,
+		Version:   version,
+		Kind:      plural,
+		Namespace: namespace,
+		Name:      name,
+	})
+	if err != nil {
+		return err
+	}
+	if entity == nil {
+		return fmt.Errorf("entity not found: %s", e.EntityID)
 	}
-}
 
-func (e *EntityRelationshipsCommand) displayRelationships(relations []api.EntityRelationResponse, targetEntityRef string) error {
-	if len(relations) == 0 {
-		fmt.Printf("No relationships found for entity: %s\n", e.EntityID)
-		return nil
+	// Relations name the entity by the ID the API gives it
+	if entity.Entity.ID != "" {
+		entityRef = entity.Entity.ID
 	}
+	return e.displayRelationships(relationsOf(entity.Relations, entityRef), entityRef)
+}
 
-	switch strings.ToLower(e.Output) {
-	case "table":
-		return e.displayRelationshipsAsTable(relations, targetEntityRef)
-	case "yaml", "yml":
-		return e.displayRelationshipsAsYAML(relations)
-	case "json":
-		return e.displayRelationshipsAsJSON(relations)
-	default:
-		return fmt.Errorf("unsupported output format: %s", e.Output)
+// relationsOf returns the relations with entityRef as their source or target
+func relationsOf(relations []api.EntityRelationResponse, entityRef string) []api.EntityRelationResponse {
+	relevant := []api.EntityRelationResponse{}
+	for _, relation := range relations {
+		if relation.Source.ID == entityRef || relation.Target.ID == entityRef {
+			relevant = append(relevant, relation)
+		}
 	}
+	return relevant
 }
 
-func (e *EntityRelationshipsCommand) displayRelationshipsAsTable(relations []api.EntityRelationResponse, targetEntityRef string) error {
+func (e *EntityRelationshipsCommand) displayRelationships(relations []api.EntityRelationResponse, targetEntityRef string) error {
 	headers := []string{"Direction", "Relation Type", "Related Entity", "Namespace"}
 	data := make([]map[string]interface{}, 0)
 
@@ -809,33 +478,13 @@ func (e *EntityRelationshipsCommand) displayRelationshipsAsTable(relations []api
 		})
 	}
 
-	if len(data) == 0 {
-		fmt.Printf("No relationships found for entity: %s\n", e.EntityID)
-		return nil
-	}
-
-	displayEntityTable(data, headers)
-	return nil
-}
-
-func (e *EntityRelationshipsCommand) displayRelationshipsAsYAML(relations []api.EntityRelationResponse) error {
-	yamlData, err := yaml.Marshal(relations)
-	if err != nil {
-		return fmt.Errorf("failed to marshal relationships to YAML: %w", err)
-	}
-
-	fmt.Print(string(yamlData))
-	return nil
-}
-
-func (e *EntityRelationshipsCommand) displayRelationshipsAsJSON(relations []api.EntityRelationResponse) error {
-	jsonData, err := json.MarshalIndent(relations, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal relationships to JSON: %w", err)
-	}
-
-	fmt.Println(string(jsonData))
-	return nil
+	return output.Print(e.OutputFormat(output.Table), output.Result{
+		Data:    relations,
+		Headers: headers,
+		Rows:    data,
+		Wide:    []string{"Related Entity"},
+		Empty:   fmt.Sprintf("No relationships found for entity: %s", e.EntityID),
+	})
 }
 
 func (e *EntityBackupCommand) Run() error {
@@ -844,217 +493,26 @@ func (e *EntityBackupCommand) Run() error {
 		return fmt.Errorf("failed to create authenticated client: %w", err)
 	}
 
-	// Create backup directory structure
-	err = os.MkdirAll(e.OutputDir, 0755)
-	if err != nil {
-		return fmt.Errorf("failed to create backup directory: %w", err)
-	}
-
-	definitionsDir := fmt.Sprintf("%s/definitions", e.OutputDir)
-	err = os.MkdirAll(definitionsDir, 0755)
-	if err != nil {
-		return fmt.Errorf("failed to create definitions directory: %w", err)
-	}
-
-	entitiesDir := fmt.Sprintf("%s/entities", e.OutputDir)
-	err = os.MkdirAll(entitiesDir, 0755)
-	if err != nil {
-		return fmt.Errorf("failed to create entities directory: %w", err)
-	}
-
-	relationsDir := fmt.Sprintf("%s/relations", e.OutputDir)
-	err = os.MkdirAll(relationsDir, 0755)
-	if err != nil {
-		return fmt.Errorf("failed to create relations directory: %w", err)
-	}
-
-	// Determine file extension
-	ext := ".yaml"
-	if e.Format == "json" {
-		ext = ".json"
-	}
-
-	// Fetch and backup entity definitions
-	defResp, err := client.GetEntityDefinitions(context.Background())
+	progress, done := entityProgress()
+	result, err := devgraph.BackupEnvironment(context.Background(), client, e.OutputDir, devgraph.BackupOptions{
+		Format:        e.Format,
+		Name:          e.Name,
+		Label:         e.Label,
+		FieldSelector: e.FieldSelector,
+		Progress:      progress,
+	})
+	done()
 	if err != nil {
-		return fmt.Errorf("failed to get entity definitions: %w", err)
-	}
-
-	var definitions []api.EntityDefinitionResponse
-	switch r := defResp.(type) {
-	case *api.GetEntityDefinitionsOKApplicationJSON:
-		definitions = *r
-	case *api.GetEntityDefinitionsNotFound:
-		fmt.Println("No entity definitions found.")
-	default:
-		return fmt.Errorf("unexpected response type for definitions: %T", defResp)
-	}
-
-	// Write entity definitions
-	defSuccessCount := 0
-	for _, def := range definitions {
-		filtered := filterEntityDefinition(def)
-
-		// Create filename: <group>_<kind>.<ext>
-		filename := fmt.Sprintf("%s_%s%s",
-			def.Group,
-			strings.ToLower(def.Kind),
-			ext)
-
-		filepath := fmt.Sprintf("%s/%s", definitionsDir, filename)
-
-		// Marshal definition
-		var data []byte
-		switch e.Format {
-		case "json":
-			data, err = json.MarshalIndent(filtered, "", "  ")
-		case "yaml":
-			data, err = yaml.Marshal(filtered)
-		default:
-			return fmt.Errorf("unsupported format: %s (use json or yaml)", e.Format)
-		}
-
-		if err != nil {
-			fmt.Printf("Warning: failed to marshal definition %s/%s: %v\n", def.Group, def.Kind, err)
-			continue
-		}
-
-		// Write to file
-		err = os.WriteFile(filepath, data, 0600)
-		if err != nil {
-			fmt.Printf("Warning: failed to write definition %s/%s: %v\n", def.Group, def.Kind, err)
-			continue
-		}
-
-		defSuccessCount++
-	}
-
-	// Build query parameters for entities
-	params := api.GetEntitiesParams{}
-
-	if e.Name != "" {
-		params.Name = api.NewOptString(e.Name)
-	}
-	if e.Label != "" {
-		params.Label = api.NewOptString(e.Label)
-	}
-	if e.FieldSelector != "" {
-		params.FieldSelector = api.NewOptString(e.FieldSelector)
-	}
-
-	// Fetch all entities
-	resp, err := client.GetEntities(context.Background(), params)
-	if err != nil {
-		return fmt.Errorf("failed to get entities: %w", err)
-	}
-
-	var entities []api.EntityResponse
-	switch r := resp.(type) {
-	case *api.EntityResultSetResponse:
-		entities = r.PrimaryEntities
-	case *api.GetEntitiesNotFound:
-		fmt.Println("No entities found to backup.")
-	default:
-		return fmt.Errorf("unexpected response type: %T", resp)
-	}
-
-	// Write each entity to a separate file
-	entitySuccessCount := 0
-	for _, entity := range entities {
-		filtered := filterEntity(entity)
-
-		// Create filename: <group>_<version>_<namespace>_<kind>_<name>.<ext>
-		filename := fmt.Sprintf("%s_%s_%s_%s_%s%s",
-			entity.Group,
-			entity.Version,
-			entity.Namespace,
-			strings.ToLower(entity.Kind),
-			entity.Name,
-			ext)
-
-		filepath := fmt.Sprintf("%s/%s", entitiesDir, filename)
-
-		// Marshal entity
-		var data []byte
-		switch e.Format {
-		case "json":
-			data, err = json.MarshalIndent(filtered, "", "  ")
-		case "yaml":
-			data, err = yaml.Marshal(filtered)
-		default:
-			return fmt.Errorf("unsupported format: %s (use json or yaml)", e.Format)
-		}
-
-		if err != nil {
-			fmt.Printf("Warning: failed to marshal entity %s/%s: %v\n", entity.Namespace, entity.Name, err)
-			continue
-		}
-
-		// Write to file
-		err = os.WriteFile(filepath, data, 0600)
-		if err != nil {
-			fmt.Printf("Warning: failed to write entity %s/%s: %v\n", entity.Namespace, entity.Name, err)
-			continue
-		}
-
-		entitySuccessCount++
-	}
-
-	// Fetch all entities again to get their relations
-	// We need to get all relations from the entity result set
-	allParams := api.GetEntitiesParams{
-		Limit: api.NewOptInt(10000), // Get a large number to capture all relations
-	}
-
-	allResp, err := client.GetEntities(context.Background(), allParams)
-	if err != nil {
-		fmt.Printf("Warning: failed to get relations: %v\n", err)
-	}
-
-	var relations []api.EntityRelationResponse
-	switch r := allResp.(type) {
-	case *api.EntityResultSetResponse:
-		relations = r.Relations
+		return err
 	}
 
-	// Write relationships
-	relSuccessCount := 0
-	if len(relations) > 0 {
-		// Write all relations to a single file
-		var filteredRelations []FilteredEntityRelation
-		for _, rel := range relations {
-			filteredRelations = append(filteredRelations, filterEntityRelation(rel))
-		}
-
-		filename := fmt.Sprintf("relations%s", ext)
-		filepath := fmt.Sprintf("%s/%s", relationsDir, filename)
-
-		// Marshal relations
-		var data []byte
-		switch e.Format {
-		case "json":
-			data, err = json.MarshalIndent(filteredRelations, "", "  ")
-		case "yaml":
-			data, err = yaml.Marshal(filteredRelations)
-		default:
-			return fmt.Errorf("unsupported format: %s (use json or yaml)", e.Format)
-		}
-
-		if err != nil {
-			fmt.Printf("Warning: failed to marshal relations: %v\n", err)
-		} else {
-			// Write to file
-			err = os.WriteFile(filepath, data, 0600)
-			if err != nil {
-				fmt.Printf("Warning: failed to write relations: %v\n", err)
-			} else {
-				relSuccessCount = len(filteredRelations)
-			}
-		}
+	// Keep the whole catalog to read with --offline
+	if result.Catalog != nil {
+		saveOfflineSnapshot(e.Config, e.OutputDir, result.Catalog, result.CatalogRelations)
 	}
 
 	fmt.Printf("Successfully backed up %d definitions, %d entities, and %d relations to %s\n",
-		defSuccessCount, entitySuccessCount, relSuccessCount, e.OutputDir)
+		result.Definitions, result.Entities, result.Relations, e.OutputDir)
 	return nil
 }
 
@@ -1087,14 +545,14 @@ func (e *EntityRestoreCommand) Run() error {
 			filepath := fmt.Sprintf("%s/%s", definitionsDir, filename)
 			data, err := os.ReadFile(filepath)
 			if err != nil {
-				fmt.Printf("Warning: failed to read definition file %s: %v\n", filename, err)
+				logging.Warn("failed to read definition file", "file", filename, "error", err)
 				continue
 			}
 
 			var def FilteredEntityDefinition
 			err = yaml.Unmarshal(data, &def)
 			if err != nil {
-				fmt.Printf("Warning: failed to parse definition file %s: %v\n", filename, err)
+				logging.Warn("failed to parse definition file", "file", filename, "error", err)
 				continue
 			}
 
@@ -1122,14 +580,14 @@ func (e *EntityRestoreCommand) Run() error {
 			filepath := fmt.Sprintf("%s/%s", entitiesDir, filename)
 			data, err := os.ReadFile(filepath)
 			if err != nil {
-				fmt.Printf("Warning: failed to read entity file %s: %v\n", filename, err)
+				logging.Warn("failed to read entity file", "file", filename, "error", err)
 				continue
 			}
 
 			var entity FilteredEntity
 			err = yaml.Unmarshal(data, &entity)
 			if err != nil {
-				fmt.Printf("Warning: failed to parse entity file %s: %v\n", filename, err)
+				logging.Warn("failed to parse entity file", "file", filename, "error", err)
 				continue
 			}
 
@@ -1157,14 +615,14 @@ func (e *EntityRestoreCommand) Run() error {
 			filepath := fmt.Sprintf("%s/%s", e.InputDir, filename)
 			data, err := os.ReadFile(filepath)
 			if err != nil {
-				fmt.Printf("Warning: failed to read file %s: %v\n", filename, err)
+				logging.Warn("failed to read file", "file", filename, "error", err)
 				continue
 			}
 
 			var entity FilteredEntity
 			err = yaml.Unmarshal(data, &entity)
 			if err != nil {
-				fmt.Printf("Warning: failed to parse file %s: %v\n", filename, err)
+				logging.Warn("failed to parse file", "file", filename, "error", err)
 				continue
 			}
 
@@ -1191,14 +649,14 @@ func (e *EntityRestoreCommand) Run() error {
 			filepath := fmt.Sprintf("%s/%s", relationsDir, filename)
 			data, err := os.ReadFile(filepath)
 			if err != nil {
-				fmt.Printf("Warning: failed to read relations file %s: %v\n", filename, err)
+				logging.Warn("failed to read relations file", "file", filename, "error", err)
 				continue
 			}
 
 			var rels []FilteredEntityRelation
 			err = yaml.Unmarshal(data, &rels)
 			if err != nil {
-				fmt.Printf("Warning: failed to parse relations file %s: %v\n", filename, err)
+				logging.Warn("failed to parse relations file", "file", filename, "error", err)
 				continue
 			}
 
@@ -1206,31 +664,85 @@ func (e *EntityRestoreCommand) Run() error {
 		}
 	}
 
+	if err := checkQuota(client, "entities", int64(len(entities)), e.EnforceLimits); err != nil {
+		return err
+	}
+
 	if e.DryRun {
-		fmt.Printf("Dry run: Would restore %d definitions, %d entities, and %d relations:\n", len(definitions), len(entities), len(relations))
-		for _, def := range definitions {
-			fmt.Printf("  Definition: %s/%s\n", def.Group, def.Kind)
-		}
-		for _, entity := range entities {
-			if metadata, ok := entity.Metadata.(map[string]interface{}); ok {
-				fmt.Printf("  Entity: %s/%s (%s)\n", metadata["namespace"], metadata["name"], entity.Kind)
-			}
+		printCatalogPlan("restore", definitions, entities, relations)
+		return nil
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	return restoreCatalog(ctx, client, definitions, nil, entities, relations, e.Config.WorkerCount(e.Workers))
+}
+
+// interruptContext returns a context cancelled by the first Ctrl+C, so bulk
+// commands can stop starting new requests and report what didn't happen. A
+// second Ctrl+C quits straight away. stop releases the signal.
+func interruptContext() (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	go func() {
+		select {
+		case <-signals:
+			signal.Stop(signals)
+			fmt.Fprintln(os.Stderr, "\nInterrupted: finishing requests in progress (Ctrl+C again to quit now)")
+			cancel()
+		case <-ctx.Done():
 		}
-		for _, rel := range relations {
-			fmt.Printf("  Relation: %s -> %s (%s)\n", rel.Source, rel.Target, rel.Relation)
+	}()
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
+
+// entityProgress reports how many entities have been read as pages of them
+// arrive: on stderr when it's a terminal, overwriting the line each time, and
+// otherwise as info logs. done clears the line.
+func entityProgress() (report func(entities int), done func()) {
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return func(entities int) {
+			logging.Info("read entities", "entities", entities)
+		}, func() {}
+	}
+
+	width := 0
+	report = func(entities int) {
+		line := fmt.Sprintf("Read %d entities...", entities)
+		width = len(line)
+		fmt.Fprint(os.Stderr, "\r"+line)
+	}
+	done = func() {
+		if width > 0 {
+			fmt.Fprint(os.Stderr, "\r"+strings.Repeat(" ", width)+"\r")
 		}
-		return nil
 	}
+	return report, done
+}
 
+// restoreCatalog creates the given definitions, entities, and relations, in
+// that order, using a pool of concurrent workers for each stage. Entities may
+// also be of the known definitions, which already exist. It reports progress
+// as it goes and returns an error if anything failed to restore.
+//
+// Once ctx is cancelled, workers finish the requests they've started but
+// don't start new ones, and the rest are counted as cancelled.
+func restoreCatalog(ctx context.Context, client *api.Client, definitions, known []FilteredEntityDefinition, entities []FilteredEntity, relations []FilteredEntityRelation, workers int) error {
 	// Restore entity definitions first with concurrent workers
 	defSuccessCount := 0
 	defFailCount := 0
+	defCancelCount := 0
 
 	if len(definitions) > 0 {
 		type defResult struct {
-			def     FilteredEntityDefinition
-			success bool
-			err     error
+			def       FilteredEntityDefinition
+			success   bool
+			cancelled bool
+			err       error
 		}
 
 		defChan := make(chan FilteredEntityDefinition, len(definitions))
@@ -1238,52 +750,21 @@ func (e *EntityRestoreCommand) Run() error {
 
 		// Start worker pool
 		var wg sync.WaitGroup
-		for i := 0; i < e.Workers; i++ {
+		for i := 0; i < workers; i++ {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
 				for def := range defChan {
-					// Convert definition to API type
-					apiDef := &api.EntityDefinitionSpec{
-						Group:    def.Group,
-						Kind:     def.Kind,
-						ListKind: def.ListKind,
-						Singular: def.Singular,
-					}
-
-					// Handle optional plural
-					if def.Plural != "" {
-						apiDef.Plural.SetTo(def.Plural)
-					}
-
-					// Handle optional name
-					if def.Name != "" {
-						apiDef.Name.SetTo(def.Name)
-					}
-
-					// Handle optional description
-					if def.Description != "" {
-						apiDef.Description.SetTo(def.Description)
-					}
-
-					// Convert spec
-					if def.Spec != nil {
-						if specBytes, err := json.Marshal(def.Spec); err == nil {
-							var defSpec api.EntityDefinitionSpecSpec
-							if err := json.Unmarshal(specBytes, &defSpec); err == nil {
-								apiDef.Spec = defSpec
-							}
-						}
-					}
-
-					// Handle optional storage
-					if def.Storage {
-						apiDef.Storage.SetTo(def.Storage)
+					if ctx.Err() != nil {
+						resultChan <- defResult{def: def, cancelled: true}
+						continue
 					}
 
-					// Handle optional served
-					if def.Served {
-						apiDef.Served.SetTo(def.Served)
+					// Convert definition to API type
+					apiDef, err := newEntityDefinitionSpec(def)
+					if err != nil {
+						resultChan <- defResult{def: def, err: err}
+						continue
 					}
 
 					// Create definition via API
@@ -1321,7 +802,9 @@ func (e *EntityRestoreCommand) Run() error {
 
 		// Collect results
 		for result := range resultChan {
-			if result.success {
+			if result.cancelled {
+				defCancelCount++
+			} else if result.success {
 				fmt.Printf("✅ Restored definition %s/%s\n", result.def.Group, result.def.Kind)
 				defSuccessCount++
 			} else {
@@ -1335,9 +818,9 @@ func (e *EntityRestoreCommand) Run() error {
 		}
 	}
 
-	// Build a map of kind to plural from the restored definitions
+	// Build a map of kind to plural from the restored and known definitions
 	kindToPluralMap := make(map[string]string)
-	for _, def := range definitions {
+	for _, def := range append(known, definitions...) {
 		key := fmt.Sprintf("%s/%s", def.Group, def.Kind)
 		plural := def.Plural
 		if plural == "" {
@@ -1350,30 +833,37 @@ func (e *EntityRestoreCommand) Run() error {
 	// Restore entities with concurrent workers
 	entitySuccessCount := 0
 	entityFailCount := 0
+	entityCancelCount := 0
 
 	if len(entities) > 0 {
-		type entityResult struct {
+		type restoreOutcome struct {
 			namespace string
 			name      string
 			kind      string
 			success   bool
+			cancelled bool
 			err       error
 		}
 
 		entityChan := make(chan FilteredEntity, len(entities))
-		resultChan := make(chan entityResult, len(entities))
+		resultChan := make(chan restoreOutcome, len(entities))
 
 		// Start worker pool
 		var wg sync.WaitGroup
-		for i := 0; i < e.Workers; i++ {
+		for i := 0; i < workers; i++ {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
 				for entity := range entityChan {
+					if ctx.Err() != nil {
+						resultChan <- restoreOutcome{cancelled: true}
+						continue
+					}
+
 					// Extract metadata
 					metadata, ok := entity.Metadata.(map[string]interface{})
 					if !ok {
-						resultChan <- entityResult{
+						resultChan <- restoreOutcome{
 							success: false,
 							err:     fmt.Errorf("invalid metadata format"),
 						}
@@ -1383,88 +873,22 @@ func (e *EntityRestoreCommand) Run() error {
 					namespace, _ := metadata["namespace"].(string)
 					name, _ := metadata["name"].(string)
 
-					// Split apiVersion into group and version
-					parts := strings.Split(entity.ApiVersion, "/")
-					var group, version string
-					if len(parts) == 2 {
-						group = parts[0]
-						version = parts[1]
-					} else {
-						version = parts[0]
-						group = "core"
-					}
-
-					// Look up plural from definitions map
-					key := fmt.Sprintf("%s/%s", group, entity.Kind)
-					plural, ok := kindToPluralMap[key]
+					// Look up plural from definitions map, falling back to
+					// simple pluralization if the definition isn't found
+					group, _, ok := strings.Cut(entity.ApiVersion, "/")
 					if !ok {
-						// Fall back to simple pluralization if definition not found
-						plural = strings.ToLower(entity.Kind) + "s"
-					}
-
-					// Convert entity to API Entity type
-					apiEntity := &api.Entity{
-						ApiVersion: entity.ApiVersion,
-						Kind:       entity.Kind,
-					}
-
-					// Convert metadata
-					if metadataBytes, err := json.Marshal(entity.Metadata); err == nil {
-						var entityMetadata api.EntityMetadata
-						if err := json.Unmarshal(metadataBytes, &entityMetadata); err == nil {
-							apiEntity.Metadata = entityMetadata
-						}
-					}
-
-					// Convert spec if present
-					if entity.Spec != nil {
-						if specBytes, err := json.Marshal(entity.Spec); err == nil {
-							var entitySpec api.EntitySpec
-							if err := json.Unmarshal(specBytes, &entitySpec); err == nil {
-								apiEntity.Spec.SetTo(entitySpec)
-							}
-						}
-					}
-
-					// Convert status if present
-					if entity.Status != nil {
-						if statusBytes, err := json.Marshal(entity.Status); err == nil {
-							var entityStatus api.EntityStatus
-							if err := json.Unmarshal(statusBytes, &entityStatus); err == nil {
-								apiEntity.Status.SetTo(entityStatus)
-							}
-						}
-					}
-
-					// Create entity via API
-					params := api.CreateEntityParams{
-						Group:     group,
-						Version:   version,
-						Namespace: namespace,
-						Plural:    plural,
+						group = "core"
 					}
+					plural := kindToPluralMap[fmt.Sprintf("%s/%s", group, entity.Kind)]
 
-					resp, err := client.CreateEntity(context.Background(), apiEntity, params)
-
-					result := entityResult{
+					_, err := devgraph.ApplyEntity(context.Background(), client, entity, plural)
+					resultChan <- restoreOutcome{
 						namespace: namespace,
 						name:      name,
 						kind:      entity.Kind,
+						success:   err == nil,
+						err:       err,
 					}
-
-					if err != nil {
-						result.err = err
-						result.success = false
-					} else {
-						switch resp.(type) {
-						case *api.EntityResponse:
-							result.success = true
-						default:
-							result.success = false
-							result.err = fmt.Errorf("unexpected response type")
-						}
-					}
-					resultChan <- result
 				}
 			}()
 		}
@@ -1483,7 +907,9 @@ func (e *EntityRestoreCommand) Run() error {
 
 		// Collect results
 		for result := range resultChan {
-			if result.success {
+			if result.cancelled {
+				entityCancelCount++
+			} else if result.success {
 				fmt.Printf("✅ Restored %s/%s (%s)\n", result.namespace, result.name, result.kind)
 				entitySuccessCount++
 			} else {
@@ -1500,14 +926,16 @@ func (e *EntityRestoreCommand) Run() error {
 	// Restore relationships after entities with concurrent workers
 	relSuccessCount := 0
 	relFailCount := 0
+	relCancelCount := 0
 
 	if len(relations) > 0 {
 		type relResult struct {
-			source   string
-			target   string
-			relation string
-			success  bool
-			err      error
+			source    string
+			target    string
+			relation  string
+			success   bool
+			cancelled bool
+			err       error
 		}
 
 		relChan := make(chan FilteredEntityRelation, len(relations))
@@ -1515,11 +943,16 @@ func (e *EntityRestoreCommand) Run() error {
 
 		// Start worker pool
 		var wg sync.WaitGroup
-		for i := 0; i < e.Workers; i++ {
+		for i := 0; i < workers; i++ {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
 				for rel := range relChan {
+					if ctx.Err() != nil {
+						resultChan <- relResult{cancelled: true}
+						continue
+					}
+
 					// Parse source and target entity IDs
 					sourceParts := strings.Split(rel.Source, "/")
 					targetParts := strings.Split(rel.Target, "/")
@@ -1568,30 +1001,14 @@ func (e *EntityRestoreCommand) Run() error {
 					apiRel.Namespace.SetTo(namespace)
 
 					// Create relation via API with namespace parameter
-					params := api.CreateEntityRelationParams{
-						Namespace: namespace,
-					}
-					resp, err := client.CreateEntityRelation(context.Background(), apiRel, params)
-
-					result := relResult{
+					err := createRelationRequest(context.Background(), client, apiRel, namespace)
+					resultChan <- relResult{
 						source:   rel.Source,
 						target:   rel.Target,
 						relation: rel.Relation,
+						success:  err == nil,
+						err:      err,
 					}
-
-					if err != nil {
-						result.err = err
-						result.success = false
-					} else {
-						switch resp.(type) {
-						case *api.EntityRelationResponse:
-							result.success = true
-						default:
-							result.success = false
-							result.err = fmt.Errorf("unexpected response type")
-						}
-					}
-					resultChan <- result
 				}
 			}()
 		}
@@ -1610,7 +1027,9 @@ func (e *EntityRestoreCommand) Run() error {
 
 		// Collect results
 		for result := range resultChan {
-			if result.success {
+			if result.cancelled {
+				relCancelCount++
+			} else if result.success {
 				fmt.Printf("✅ Restored relation %s -> %s (%s)\n", result.source, result.target, result.relation)
 				relSuccessCount++
 			} else {
@@ -1624,6 +1043,15 @@ func (e *EntityRestoreCommand) Run() error {
 		}
 	}
 
+	if ctx.Err() != nil {
+		fmt.Printf("\nRestore interrupted:\n")
+		fmt.Printf("  Definitions: %d succeeded, %d failed, %d cancelled\n", defSuccessCount, defFailCount, defCancelCount)
+		fmt.Printf("  Entities: %d succeeded, %d failed, %d cancelled\n", entitySuccessCount, entityFailCount, entityCancelCount)
+		fmt.Printf("  Relations: %d succeeded, %d failed, %d cancelled\n", relSuccessCount, relFailCount, relCancelCount)
+		return fmt.Errorf("interrupted: %d definitions, %d entities, and %d relations were not restored",
+			defFailCount+defCancelCount, entityFailCount+entityCancelCount, relFailCount+relCancelCount)
+	}
+
 	fmt.Printf("\nRestore complete:\n")
 	fmt.Printf("  Definitions: %d succeeded, %d failed\n", defSuccessCount, defFailCount)
 	fmt.Printf("  Entities: %d succeeded, %d failed\n", entitySuccessCount, entityFailCount)
@@ -0,0 +1,402 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
+	"github.com/arctir/devgraph-cli/pkg/util"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+	"gopkg.in/yaml.v3"
+)
+
+// EntityApplyCommand creates the entities in manifest files, or updates them
+// if they exist. The API has no update operation, so a changed entity is
+// deleted and recreated, and its relations are recreated with it.
+type EntityApplyCommand struct {
+	EnvWrapperCommand
+	File []string `short:"f" required:"" help:"Entity manifest file, directory of .json/.yaml files, or - to read from stdin (can be specified multiple times)."`
+}
+
+// entityManifest is an entity read from a manifest file, with where it's
+// stored
+type entityManifest struct {
+	File   string
+	Entity FilteredEntity
+	Params api.GetEntityParams
+}
+
+// ID returns the manifest's entity ID, as entity get takes it
+func (m entityManifest) ID() string {
+	return formatEntityID(m.Params)
+}
+
+// entityApplySummary counts the outcomes of applying entities
+type entityApplySummary struct {
+	Created, Updated, Unchanged, Failed int
+}
+
+// result prints the summary and returns an error if any entity couldn't be
+// applied
+func (s entityApplySummary) result(dryRun bool) error {
+	created, updated := "Created", "updated"
+	if dryRun {
+		created, updated = "Would create", "would update"
+	}
+	fmt.Printf("\n%s %d, %s %d, unchanged %d, failed %d\n", created, s.Created, updated, s.Updated, s.Unchanged, s.Failed)
+	if s.Failed > 0 {
+		return fmt.Errorf("%d entity(s) could not be applied", s.Failed)
+	}
+	return nil
+}
+
+// Run reads every manifest before changing anything, so a typo in one file
+// doesn't leave the others half applied
+func (e *EntityApplyCommand) Run() error {
+	manifests, err := readEntityManifests(e.File)
+	if err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	if err := resolveEntityManifests(ctx, client, manifests); err != nil {
+		return err
+	}
+
+	var summary entityApplySummary
+	for _, m := range manifests {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("interrupted: %w", err)
+		}
+
+		existing, err := getEntityWithRelations(ctx, client, m.Params)
+		if err != nil {
+			fmt.Printf("✗ %s: %v\n", m.ID(), err)
+			summary.Failed++
+			continue
+		}
+
+		switch {
+		case existing == nil && e.DryRun:
+			fmt.Printf("Would create %s\n", m.ID())
+			summary.Created++
+		case existing == nil:
+			if _, err := devgraph.ApplyEntity(ctx, client, m.Entity, m.Params.Kind); err != nil {
+				fmt.Printf("✗ %s: %v\n", m.ID(), err)
+				summary.Failed++
+				continue
+			}
+			fmt.Printf("✅ Created %s\n", m.ID())
+			summary.Created++
+		default:
+			changed, err := entityChanged(m.Entity, existing.Entity)
+			if err != nil {
+				fmt.Printf("✗ %s: %v\n", m.ID(), err)
+				summary.Failed++
+				continue
+			}
+			switch {
+			case !changed:
+				fmt.Printf("  %s unchanged\n", m.ID())
+				summary.Unchanged++
+			case e.DryRun:
+				fmt.Printf("Would update %s\n", m.ID())
+				summary.Updated++
+			default:
+				if err := replaceEntity(ctx, client, m, existing); err != nil {
+					fmt.Printf("✗ %s: %v\n", m.ID(), err)
+					summary.Failed++
+					continue
+				}
+				fmt.Printf("✅ Updated %s\n", m.ID())
+				summary.Updated++
+			}
+		}
+	}
+	return summary.result(e.DryRun)
+}
+
+// readEntityManifests reads the entities in manifest files, directories of
+// them, or stdin for -
+func readEntityManifests(paths []string) ([]entityManifest, error) {
+	var manifests []entityManifest
+	for _, path := range paths {
+		files := []string{path}
+		if path != "-" {
+			var err error
+			if files, err = definitionFiles(path); err != nil {
+				return nil, err
+			}
+		}
+		for _, file := range files {
+			data, err := util.ReadFileOrStdin(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read file %s: %w", file, err)
+			}
+			entities, err := parseEntityManifests(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+			for _, entity := range entities {
+				manifests = append(manifests, entityManifest{File: file, Entity: entity})
+			}
+		}
+	}
+	if len(manifests) == 0 {
+		return nil, fmt.Errorf("no entities found in %s", strings.Join(paths, ", "))
+	}
+	return manifests, nil
+}
+
+// resolveEntityManifests sets where each manifest's entity is stored, with
+// plurals from the server's entity definitions
+func resolveEntityManifests(ctx context.Context, client *api.Client, manifests []entityManifest) error {
+	definitions, err := listEntityDefinitions(ctx, client)
+	if err != nil {
+		return err
+	}
+	plurals := make(map[string]string)
+	for _, def := range definitions {
+		if plural, ok := def.Plural.Get(); ok && plural != "" {
+			plurals[fmt.Sprintf("%s/%s", def.Group, def.Kind)] = plural
+		}
+	}
+	for i := range manifests {
+		if manifests[i].Params, err = entityManifestParams(manifests[i].Entity, plurals); err != nil {
+			return fmt.Errorf("%s: %w", manifests[i].File, err)
+		}
+	}
+	return nil
+}
+
+// parseEntityManifests reads the entities in a YAML or JSON file, which may
+// hold several YAML documents
+func parseEntityManifests(data []byte) ([]FilteredEntity, error) {
+	var entities []FilteredEntity
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for i := 1; ; i++ {
+		var entity FilteredEntity
+		err := decoder.Decode(&entity)
+		if errors.Is(err, io.EOF) {
+			return entities, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse entity %d: %w", i, err)
+		}
+		if entity.ApiVersion == "" && entity.Kind == "" && entity.Metadata == nil && entity.Spec == nil && entity.Status == nil {
+			// An empty document, as between two ---
+			continue
+		}
+		entities = append(entities, entity)
+	}
+}
+
+// entityManifestParams works out where an entity is stored from its
+// apiVersion, kind and metadata. The plural comes from the kind's definition,
+// or the kind with an s added. A missing namespace is set to default.
+func entityManifestParams(entity FilteredEntity, plurals map[string]string) (api.GetEntityParams, error) {
+	if entity.ApiVersion == "" || entity.Kind == "" {
+		return api.GetEntityParams{}, fmt.Errorf("apiVersion and kind are required")
+	}
+	metadata, ok := entity.Metadata.(map[string]interface{})
+	if !ok {
+		return api.GetEntityParams{}, fmt.Errorf("invalid metadata format")
+	}
+	name, _ := metadata["name"].(string)
+	if name == "" {
+		return api.GetEntityParams{}, fmt.Errorf("metadata.name is required")
+	}
+	namespace, _ := metadata["namespace"].(string)
+	if namespace == "" {
+		namespace = "default"
+		metadata["namespace"] = namespace
+	}
+
+	// An apiVersion without a group is in the core group
+	group, version, ok := strings.Cut(entity.ApiVersion, "/")
+	if !ok {
+		group, version = "core", entity.ApiVersion
+	}
+	plural := plurals[fmt.Sprintf("%s/%s", group, entity.Kind)]
+	if plural == "" {
+		plural = strings.ToLower(entity.Kind) + "s"
+	}
+
+	return api.GetEntityParams{
+		Group:     group,
+		Version:   version,
+		Kind:      plural,
+		Namespace: namespace,
+		Name:      name,
+	}, nil
+}
+
+// getEntityWithRelations returns the entity named by params with its
+// relations, or nil if there isn't one
+func getEntityWithRelations(ctx context.Context, client *api.Client, params api.GetEntityParams) (*api.EntityWithRelationsResponse, error) {
+	resp, err := client.GetEntity(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity: %w", err)
+	}
+	switch r := resp.(type) {
+	case *api.EntityWithRelationsResponse:
+		return r, nil
+	case *api.GetEntityNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
+	}
+}
+
+// entityChanged reports whether applying entity would change existing. Only
+// the fields a manifest sets are compared: status is left alone unless the
+// manifest has one, and empty labels, annotations and specs match missing
+// ones.
+func entityChanged(entity FilteredEntity, existing api.EntityResponse) (bool, error) {
+	desired, actual, err := comparableEntities(entity, existing)
+	if err != nil {
+		return false, err
+	}
+	return !reflect.DeepEqual(desired, actual), nil
+}
+
+// comparableEntities returns entity and existing as entityChanged compares
+// them
+func comparableEntities(entity FilteredEntity, existing api.EntityResponse) (desired, actual map[string]any, err error) {
+	current := devgraph.NewEntity(existing)
+	if entity.Status == nil {
+		current.Status = nil
+	}
+
+	if desired, err = comparableEntity(entity); err != nil {
+		return nil, nil, err
+	}
+	if actual, err = comparableEntity(current); err != nil {
+		return nil, nil, err
+	}
+	return desired, actual, nil
+}
+
+// comparableEntity returns entity as plain JSON values, with the metadata
+// fields the API stores and without empty values
+func comparableEntity(entity FilteredEntity) (map[string]any, error) {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	metadata, _ := fields["metadata"].(map[string]any)
+	stored := map[string]any{}
+	for _, key := range []string{"name", "namespace", "labels", "annotations"} {
+		if value, ok := metadata[key]; ok {
+			stored[key] = value
+		}
+	}
+	fields["metadata"] = stored
+
+	for _, m := range []map[string]any{fields, stored} {
+		for key, value := range m {
+			if value == nil {
+				delete(m, key)
+			} else if nested, ok := value.(map[string]any); ok && len(nested) == 0 {
+				delete(m, key)
+			}
+		}
+	}
+	return fields, nil
+}
+
+// replaceEntity deletes existing and creates m's entity in its place,
+// putting the original back if the create fails. Relations that went with
+// the old entity are then recreated.
+func replaceEntity(ctx context.Context, client *api.Client, m entityManifest, existing *api.EntityWithRelationsResponse) error {
+	if err := deleteEntity(ctx, client, m.Params); err != nil {
+		return fmt.Errorf("failed to delete entity: %w", err)
+	}
+	if _, err := devgraph.ApplyEntity(ctx, client, m.Entity, m.Params.Kind); err != nil {
+		if _, restoreErr := devgraph.ApplyEntity(ctx, client, devgraph.NewEntity(existing.Entity), m.Params.Kind); restoreErr != nil {
+			return fmt.Errorf("failed to create updated entity: %w (restoring the original also failed: %v)", err, restoreErr)
+		}
+		err = fmt.Errorf("failed to create updated entity, original restored: %w", err)
+		if relErr := restoreEntityRelations(ctx, client, m.Params, existing.Relations); relErr != nil {
+			return fmt.Errorf("%w (%v)", err, relErr)
+		}
+		return err
+	}
+	return restoreEntityRelations(ctx, client, m.Params, existing.Relations)
+}
+
+// deleteEntity deletes the entity named by params
+func deleteEntity(ctx context.Context, client *api.Client, params api.GetEntityParams) error {
+	resp, err := client.DeleteEntity(ctx, api.DeleteEntityParams(params))
+	if err != nil {
+		return err
+	}
+	switch resp.(type) {
+	case *api.DeleteEntityNoContent:
+		return nil
+	case *api.DeleteEntityNotFound:
+		return fmt.Errorf("entity not found")
+	default:
+		return fmt.Errorf("unexpected response type: %T", resp)
+	}
+}
+
+// restoreEntityRelations recreates the relations in relations that the
+// entity named by params no longer has, with their metadata and spec
+func restoreEntityRelations(ctx context.Context, client *api.Client, params api.GetEntityParams, relations []api.EntityRelationResponse) error {
+	if len(relations) == 0 {
+		return nil
+	}
+	current, err := getEntityWithRelations(ctx, client, params)
+	if err != nil {
+		return err
+	}
+	kept := make(map[devgraph.EntityRelation]bool)
+	if current != nil {
+		for _, rel := range current.Relations {
+			kept[devgraph.NewEntityRelation(rel)] = true
+		}
+	}
+
+	failed := 0
+	for _, rel := range relations {
+		key := devgraph.NewEntityRelation(rel)
+		if kept[key] {
+			continue
+		}
+		relation, namespace, err := newEntityRelation(key)
+		if err == nil {
+			relation.Metadata = rel.Metadata
+			if spec, ok := rel.Spec.Get(); ok {
+				relation.Spec = api.NewOptEntityRelationSpec(api.EntityRelationSpec(spec))
+			}
+			err = createRelationRequest(ctx, client, relation, namespace)
+		}
+		if err != nil {
+			fmt.Printf("✗ Failed to recreate relation %s -> %s (%s): %v\n", key.Source, key.Target, key.Relation, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d relation(s) could not be recreated", failed)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/arctir/devgraph-cli/pkg/util"
+	"github.com/fatih/color"
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+)
+
+// EntityDiffCommand shows what entity apply would change, as a unified diff
+// of each entity on the server against its manifest. Fields apply leaves
+// alone, like status when the manifest has none, aren't shown.
+type EntityDiffCommand struct {
+	EnvWrapperCommand
+	File     []string `short:"f" required:"" help:"Entity manifest file, directory of .json/.yaml files, or - to read from stdin (can be specified multiple times)."`
+	ExitCode bool     `help:"Exit with an error when any entity differs from the server, for CI checks."`
+}
+
+func (e *EntityDiffCommand) Run() error {
+	manifests, err := readEntityManifests(e.File)
+	if err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	if err := resolveEntityManifests(ctx, client, manifests); err != nil {
+		return err
+	}
+
+	changed := 0
+	for _, m := range manifests {
+		existing, err := getEntityWithRelations(ctx, client, m.Params)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.ID(), err)
+		}
+
+		desired, err := comparableEntity(m.Entity)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.ID(), err)
+		}
+		var actual map[string]any
+		from := "a/" + m.ID()
+		if existing == nil {
+			from = "/dev/null"
+		} else if desired, actual, err = comparableEntities(m.Entity, existing.Entity); err != nil {
+			return fmt.Errorf("%s: %w", m.ID(), err)
+		}
+
+		diff, err := unifiedYAMLDiff(actual, desired, from, "b/"+m.ID())
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.ID(), err)
+		}
+		if diff == "" {
+			continue
+		}
+		changed++
+		printUnifiedDiff(diff)
+	}
+
+	fmt.Printf("\n%d of %d entity(s) differ from the server\n", changed, len(manifests))
+	if e.ExitCode && changed > 0 {
+		return fmt.Errorf("%d entity(s) differ from the server", changed)
+	}
+	return nil
+}
+
+// unifiedYAMLDiff returns a unified diff from one document to another as
+// YAML, or "" if they're the same. A nil document is empty.
+func unifiedYAMLDiff(from, to map[string]any, fromFile, toFile string) (string, error) {
+	lines := func(document map[string]any) ([]string, error) {
+		if document == nil {
+			return nil, nil
+		}
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(document); err != nil {
+			return nil, fmt.Errorf("failed to marshal entity: %w", err)
+		}
+		// The YAML ends with a newline, after which SplitAfter adds ""
+		split := strings.SplitAfter(buf.String(), "\n")
+		return split[:len(split)-1], nil
+	}
+
+	a, err := lines(from)
+	if err != nil {
+		return "", err
+	}
+	b, err := lines(to)
+	if err != nil {
+		return "", err
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        a,
+		B:        b,
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+}
+
+// printUnifiedDiff prints a unified diff with added lines in green, removed
+// ones in red and hunk headers in cyan, as git diff does
+func printUnifiedDiff(diff string) {
+	headerColor := color.New(color.Bold)
+	hunkColor := color.New(color.FgCyan)
+	addedColor := color.New(color.FgGreen)
+	deletedColor := color.New(color.FgRed)
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			fmt.Print(headerColor.Sprint(line))
+		case strings.HasPrefix(line, "@@"):
+			fmt.Print(hunkColor.Sprint(line))
+		case strings.HasPrefix(line, "+"):
+			fmt.Print(addedColor.Sprint(line))
+		case strings.HasPrefix(line, "-"):
+			fmt.Print(deletedColor.Sprint(line))
+		default:
+			fmt.Print(line)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
+	"github.com/arctir/devgraph-cli/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+// EntityEditCommand opens an entity in an editor as YAML and updates it with
+// what's saved, as kubectl edit does. A save that can't be read reopens the
+// editor with the error, until it's fixed or saved again unchanged.
+type EntityEditCommand struct {
+	EnvWrapperCommand
+	EntityID string `arg:"" required:"" help:"Entity ID in the format [entity://]<group>/<version>/<plural>/<namespace>/<name>."`
+}
+
+// entityEditHeader explains the file opened by entity edit
+const entityEditHeader = `# Edit the entity below and save to update it. Lines beginning with '#' are
+# ignored, and an empty file cancels the edit. apiVersion, kind, name and
+# namespace can't be changed.
+#
+`
+
+func (e *EntityEditCommand) Run() error {
+	params, err := entityParams(e.EntityID)
+	if err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	ctx := context.Background()
+	existing, err := getEntityWithRelations(ctx, client, params)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("entity not found")
+	}
+
+	data, err := yaml.Marshal(devgraph.NewEntity(existing.Entity))
+	if err != nil {
+		return fmt.Errorf("failed to marshal entity: %w", err)
+	}
+	body := strings.TrimSpace(string(data))
+
+	var editErr error
+	for {
+		content := entityEditHeader
+		if editErr != nil {
+			content += "# The edit couldn't be saved:\n"
+			for _, line := range strings.Split(editErr.Error(), "\n") {
+				content += "#   " + line + "\n"
+			}
+			content += "#\n"
+		}
+
+		edited, err := util.EditYAML(content + body + "\n")
+		if err != nil {
+			return err
+		}
+		edited = trimLeadingComments(edited)
+		if edited == "" {
+			fmt.Println("Edit cancelled, no changes made.")
+			return nil
+		}
+		if editErr != nil && edited == body {
+			// Saved again without fixing what was wrong
+			return fmt.Errorf("edit cancelled: %w", editErr)
+		}
+		body = edited
+
+		entity, err := parseEditedEntity(edited)
+		if err == nil {
+			err = checkEntityIdentity(params, entity, existing.Entity)
+		}
+		if err != nil {
+			editErr = err
+			continue
+		}
+		return updateEntity(ctx, client, e.DryRun, params, entity, existing)
+	}
+}
+
+// parseEditedEntity reads the entity saved by entity edit
+func parseEditedEntity(edited string) (FilteredEntity, error) {
+	entities, err := parseEntityManifests([]byte(edited))
+	if err != nil {
+		return FilteredEntity{}, err
+	}
+	if len(entities) != 1 {
+		return FilteredEntity{}, fmt.Errorf("expected one entity, found %d", len(entities))
+	}
+	return entities[0], nil
+}
+
+// trimLeadingComments removes the comment lines at the start of text, where
+// entity edit explains the file, and surrounding whitespace
+func trimLeadingComments(text string) string {
+	lines := strings.Split(text, "\n")
+	for len(lines) > 0 && strings.HasPrefix(strings.TrimSpace(lines[0]), "#") {
+		lines = lines[1:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
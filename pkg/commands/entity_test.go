@@ -0,0 +1,747 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"encoding/json"
+	"fmt"
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
+	"github.com/arctir/devgraph-cli/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func TestEntityCreateCommand_Stdin(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("POST /api/v1/entities/core/v1/namespace/default/services", http.StatusCreated, testEntity("web"))
+	withStdin(t, `{"apiVersion": "core/v1", "kind": "Service", "metadata": {"name": "web", "namespace": "default"}}`)
+
+	cmd := EntityCreateCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Group:             "core",
+		Version:           "v1",
+		Namespace:         "default",
+		Plural:            "services",
+		FileName:          "-",
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Entity 'web' created")
+
+	body := srv.requireRequest(http.MethodPost, "/api/v1/entities/core/v1/namespace/default/services").JSON(t)
+	assert.Equal(t, "Service", body["kind"])
+	assert.Equal(t, "web", body["metadata"].(map[string]any)["name"])
+}
+
+func TestEntityListCommand_Output(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/", http.StatusOK, map[string]any{
+		"primary_entities": []any{testEntity("web"), testEntity("api")},
+	})
+
+	cfg.Output = "name"
+	cmd := EntityListCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Label: "team=web"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Equal(t, "core/v1/service/default/web\ncore/v1/service/default/api\n", output)
+	assert.Equal(t, "label=team%3Dweb", srv.requireRequest(http.MethodGet, "/api/v1/entities/").Query)
+
+	cmd.Output = "yaml"
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	var entities []FilteredEntity
+	require.NoError(t, yaml.Unmarshal([]byte(output), &entities))
+	require.Len(t, entities, 2)
+	assert.Equal(t, "Service", entities[0].Kind)
+
+	// Templates print each entity
+	cmd.Output = `jsonpath={.metadata.name}{" "}{.kind}`
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Equal(t, "web Service\napi Service\n", output)
+
+	cmd.Output = "go-template={{.metadata.namespace}}/{{.metadata.name}}"
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Equal(t, "default/web\ndefault/api\n", output)
+
+	// Invalid templates fail before any request is made
+	requests := len(srv.received(http.MethodGet, "/api/v1/entities/"))
+	cmd.Output = "jsonpath={.metadata.name"
+	_, err = captureOutput(t, cmd.Run)
+	assert.ErrorContains(t, err, "invalid jsonpath template")
+	assert.Len(t, srv.received(http.MethodGet, "/api/v1/entities/"), requests)
+
+	cmd.Output = "xml"
+	_, err = captureOutput(t, cmd.Run)
+	assert.EqualError(t, err, "unsupported output format: xml (use table, json, yaml, name, jsonpath=TEMPLATE or go-template=TEMPLATE)")
+}
+
+func TestEntityGetCommand_Output(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/core/v1/services/default/web", http.StatusOK, map[string]any{
+		"entity": testEntity("web"),
+	})
+
+	// Entities print as JSON unless --output says otherwise
+	cmd := EntityGetCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, EntityID: "core/v1/services/default/web"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, `"kind": "Service"`)
+	srv.requireRequest(http.MethodGet, "/api/v1/entities/core/v1/services/default/web")
+
+	cmd.Output = "table"
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Regexp(t, `core/v1/service/default/web\s+web\s+default`, output)
+
+	cmd.Output = "name"
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Equal(t, "core/v1/service/default/web\n", output)
+}
+
+func TestRestoreCatalog_Interrupted(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	client, err := util.GetAuthenticatedClient(cfg)
+	require.NoError(t, err)
+
+	// Interrupt while the first entity is being created
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	srv.mux.HandleFunc("POST /api/v1/entities/{group}/{version}/namespace/{namespace}/{plural}", func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"apiVersion":"core/v1","kind":"Service","metadata":{"name":"web","namespace":"default"},"id":"core/v1/service/default/web","plural":"services","group":"core","version":"v1","name":"web","namespace":"default"}`))
+	})
+
+	var entities []FilteredEntity
+	for _, name := range []string{"web", "api", "db"} {
+		entities = append(entities, FilteredEntity{
+			ApiVersion: "core/v1",
+			Kind:       "Service",
+			Metadata:   map[string]interface{}{"name": name, "namespace": "default"},
+		})
+	}
+	relations := []FilteredEntityRelation{{Relation: "DEPENDS_ON", Source: "core/v1/service/default/web", Target: "core/v1/service/default/api"}}
+
+	output, err := captureOutput(t, func() error {
+		return restoreCatalog(ctx, client, nil, nil, entities, relations, 1)
+	})
+	assert.EqualError(t, err, "interrupted: 0 definitions, 2 entities, and 1 relations were not restored")
+
+	// The request in progress finishes and is reported, and nothing else
+	// is sent
+	assert.Contains(t, output, "✅ Restored default/web (Service)")
+	assert.Contains(t, output, "Restore interrupted:")
+	assert.Contains(t, output, "Entities: 1 succeeded, 0 failed, 2 cancelled")
+	assert.Contains(t, output, "Relations: 0 succeeded, 0 failed, 1 cancelled")
+	assert.NotContains(t, output, "Restore complete")
+	assert.Len(t, srv.received(http.MethodPost, "/api/v1/entities/core/v1/namespace/default/services"), 1)
+	assert.Empty(t, srv.received(http.MethodPost, "/api/v1/entities/relations"))
+}
+
+func TestInterruptContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("interrupts can't be sent to the current process on Windows")
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	process, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, process.Signal(os.Interrupt))
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context wasn't cancelled by the interrupt")
+	}
+
+	// Stopping without an interrupt cancels too, releasing the context
+	ctx, stop = interruptContext()
+	stop()
+	assert.Error(t, ctx.Err())
+}
+
+func TestEntityApplyCommand_Create(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	definition := testDefinition("apps", "Database", map[string]any{"type": "object"})
+	definition["plural"] = "databases"
+	srv.handle("GET /api/v1/entities/definitions", http.StatusOK, []map[string]any{definition})
+	srv.handle("GET /api/v1/entities/{group}/{version}/{plural}/{namespace}/{name}", http.StatusNotFound, nil)
+	srv.handle("POST /api/v1/entities/core/v1/namespace/payments/services", http.StatusCreated, testEntity("web"))
+	srv.handle("POST /api/v1/entities/apps/v1/namespace/default/databases", http.StatusCreated, testEntity("orders"))
+
+	// One file of two documents, the second without a namespace
+	dir := t.TempDir()
+	manifest := `apiVersion: core/v1
+kind: Service
+metadata:
+  name: web
+  namespace: payments
+  labels:
+    team: payments
+spec:
+  port: 80
+---
+apiVersion: apps/v1
+kind: Database
+metadata:
+  name: orders
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "catalog.yaml"), []byte(manifest), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a manifest"), 0o644))
+
+	cmd := EntityApplyCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		File:              []string{dir},
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "✅ Created core/v1/services/payments/web")
+	assert.Contains(t, output, "✅ Created apps/v1/databases/default/orders")
+	assert.Contains(t, output, "Created 2, updated 0, unchanged 0, failed 0")
+
+	srv.requireRequest(http.MethodGet, "/api/v1/entities/core/v1/services/payments/web")
+	body := srv.requireRequest(http.MethodPost, "/api/v1/entities/core/v1/namespace/payments/services").JSON(t)
+	assert.Equal(t, "Service", body["kind"])
+	assert.Equal(t, map[string]any{"name": "web", "namespace": "payments", "labels": map[string]any{"team": "payments"}}, body["metadata"])
+	assert.Equal(t, map[string]any{"port": 80.0}, body["spec"])
+
+	// The plural comes from the kind's definition
+	body = srv.requireRequest(http.MethodPost, "/api/v1/entities/apps/v1/namespace/default/databases").JSON(t)
+	assert.Equal(t, "default", body["metadata"].(map[string]any)["namespace"])
+}
+
+func TestEntityApplyCommand_Update(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	existing := testEntity("web")
+	existing["metadata"] = map[string]any{"name": "web", "namespace": "default", "labels": map[string]any{"team": "payments"}}
+	existing["spec"] = map[string]any{"port": 80}
+	relation := map[string]any{
+		"relation": "DEPENDS_ON",
+		"source":   map[string]any{"apiVersion": "core/v1", "kind": "Service", "name": "web", "id": "core/v1/services/default/web"},
+		"target":   map[string]any{"apiVersion": "core/v1", "kind": "Service", "name": "db", "id": "core/v1/services/default/db"},
+		"metadata": map[string]any{"labels": map[string]any{"tier": "1"}},
+	}
+
+	srv.handle("GET /api/v1/entities/definitions", http.StatusOK, []map[string]any{})
+	gets := 0
+	srv.mux.HandleFunc("GET /api/v1/entities/core/v1/services/default/web", func(w http.ResponseWriter, r *http.Request) {
+		// The relation went with the deleted entity
+		gets++
+		relations := []map[string]any{relation}
+		if gets > 1 {
+			relations = []map[string]any{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"entity": existing, "related_entities": []any{}, "relations": relations})
+	})
+	srv.handle("DELETE /api/v1/entities/core/v1/services/default/web", http.StatusNoContent, nil)
+	srv.handle("POST /api/v1/entities/core/v1/namespace/default/services", http.StatusCreated, testEntity("web"))
+	srv.handle("POST /api/v1/entities/relations", http.StatusCreated, relation)
+	withStdin(t, `{"apiVersion": "core/v1", "kind": "Service", "metadata": {"name": "web", "labels": {"team": "payments"}}, "spec": {"port": 8080}}`)
+
+	cmd := EntityApplyCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		File:              []string{"-"},
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "✅ Updated core/v1/services/default/web")
+
+	srv.requireRequest(http.MethodDelete, "/api/v1/entities/core/v1/services/default/web")
+	body := srv.requireRequest(http.MethodPost, "/api/v1/entities/core/v1/namespace/default/services").JSON(t)
+	assert.Equal(t, map[string]any{"port": 8080.0}, body["spec"])
+
+	rel := srv.requireRequest(http.MethodPost, "/api/v1/entities/relations").JSON(t)
+	assert.Equal(t, "DEPENDS_ON", rel["relation"])
+	assert.Equal(t, map[string]any{"labels": map[string]any{"tier": "1"}}, rel["metadata"])
+
+	// Applying what's there changes nothing
+	withStdin(t, `{"apiVersion": "core/v1", "kind": "Service", "metadata": {"name": "web", "labels": {"team": "payments"}, "annotations": {}}, "spec": {"port": 80}}`)
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "core/v1/services/default/web unchanged")
+	assert.Len(t, srv.received(http.MethodDelete, "/api/v1/entities/core/v1/services/default/web"), 1)
+}
+
+func TestEntityDiffCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	existing := testEntity("web")
+	existing["metadata"] = map[string]any{"name": "web", "namespace": "default", "labels": map[string]any{"team": "payments"}}
+	existing["spec"] = map[string]any{"port": 80, "protocol": "http"}
+	existing["status"] = map[string]any{"healthy": true}
+	same := testEntity("api")
+	same["spec"] = map[string]any{"port": 9000}
+
+	srv.handle("GET /api/v1/entities/definitions", http.StatusOK, []map[string]any{})
+	srv.handle("GET /api/v1/entities/core/v1/services/default/web", http.StatusOK, testEntityWithRelations(existing))
+	srv.handle("GET /api/v1/entities/core/v1/services/default/api", http.StatusOK, testEntityWithRelations(same))
+	srv.handle("GET /api/v1/entities/core/v1/services/default/queue", http.StatusNotFound, nil)
+
+	manifest := `apiVersion: core/v1
+kind: Service
+metadata:
+  name: web
+  labels:
+    team: payments
+spec:
+  port: 8080
+  protocol: http
+---
+apiVersion: core/v1
+kind: Service
+metadata:
+  name: api
+spec:
+  port: 9000
+---
+apiVersion: core/v1
+kind: Service
+metadata:
+  name: queue
+`
+	withStdin(t, manifest)
+	cmd := EntityDiffCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		File:              []string{"-"},
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	// The server's status isn't shown, since apply leaves it alone
+	assert.Contains(t, output, `--- a/core/v1/services/default/web
++++ b/core/v1/services/default/web
+@@ -6,5 +6,5 @@
+   name: web
+   namespace: default
+ spec:
+-  port: 80
++  port: 8080
+   protocol: http
+`)
+	assert.NotContains(t, output, "healthy")
+	assert.NotContains(t, output, "default/api")
+	assert.Contains(t, output, `--- /dev/null
++++ b/core/v1/services/default/queue
+@@ -0,0 +1,5 @@
++apiVersion: core/v1
++kind: Service
+`)
+	assert.Contains(t, output, "2 of 3 entity(s) differ from the server")
+
+	// Only reads are sent
+	srv.requireRequest(http.MethodGet, "/api/v1/entities/core/v1/services/default/web")
+	srv.requireRequest(http.MethodGet, "/api/v1/entities/core/v1/services/default/queue")
+	assert.Empty(t, srv.received(http.MethodPost, "/api/v1/entities/core/v1/namespace/default/services"))
+	assert.Empty(t, srv.received(http.MethodDelete, "/api/v1/entities/core/v1/services/default/web"))
+
+	// --exit-code fails when anything differs
+	withStdin(t, manifest)
+	cmd.ExitCode = true
+	_, err = captureOutput(t, cmd.Run)
+	assert.EqualError(t, err, "2 entity(s) differ from the server")
+}
+
+func TestEntityApplyCommand_RestoresOnFailure(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	existing := testEntity("web")
+	existing["spec"] = map[string]any{"port": 80}
+	srv.handle("GET /api/v1/entities/definitions", http.StatusOK, []map[string]any{})
+	srv.handle("GET /api/v1/entities/core/v1/services/default/web", http.StatusOK, testEntityWithRelations(existing))
+	srv.handle("DELETE /api/v1/entities/core/v1/services/default/web", http.StatusNoContent, nil)
+	srv.mux.HandleFunc("POST /api/v1/entities/core/v1/namespace/default/services", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if body["spec"].(map[string]any)["port"] == "broken" {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"detail": []any{}})
+			return
+		}
+		writeJSON(w, http.StatusCreated, existing)
+	})
+	withStdin(t, `{"apiVersion": "core/v1", "kind": "Service", "metadata": {"name": "web"}, "spec": {"port": "broken"}}`)
+
+	cmd := EntityApplyCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		File:              []string{"-"},
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.ErrorContains(t, err, "1 entity(s) could not be applied")
+	assert.Contains(t, output, "original restored")
+
+	creates := srv.received(http.MethodPost, "/api/v1/entities/core/v1/namespace/default/services")
+	require.Len(t, creates, 2)
+	assert.Equal(t, map[string]any{"port": 80.0}, creates[1].JSON(t)["spec"])
+}
+
+func TestEntityApplyCommand_DryRun(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	existing := testEntity("web")
+	srv.handle("GET /api/v1/entities/definitions", http.StatusOK, []map[string]any{})
+	srv.handle("GET /api/v1/entities/core/v1/services/default/web", http.StatusOK, testEntityWithRelations(existing))
+	srv.handle("GET /api/v1/entities/core/v1/services/default/api", http.StatusNotFound, nil)
+	withStdin(t, `apiVersion: core/v1
+kind: Service
+metadata: {name: web, namespace: default}
+spec: {port: 80}
+---
+apiVersion: core/v1
+kind: Service
+metadata: {name: api, namespace: default}
+`)
+
+	cfg.DryRun = true
+	cmd := EntityApplyCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		File:              []string{"-"},
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Would update core/v1/services/default/web")
+	assert.Contains(t, output, "Would create core/v1/services/default/api")
+	assert.Contains(t, output, "Would create 1, would update 1, unchanged 0, failed 0")
+	assert.Empty(t, srv.received(http.MethodDelete, "/api/v1/entities/core/v1/services/default/web"))
+	assert.Empty(t, srv.received(http.MethodPost, "/api/v1/entities/core/v1/namespace/default/services"))
+}
+
+func TestEntityApplyCommand_Invalid(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	withStdin(t, `{"apiVersion": "core/v1", "kind": "Service", "metadata": {"namespace": "default"}}`)
+
+	cmd := EntityApplyCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		File:              []string{"-"},
+	}
+	srv.handle("GET /api/v1/entities/definitions", http.StatusOK, []map[string]any{})
+	_, err := captureOutput(t, cmd.Run)
+	require.ErrorContains(t, err, "-: metadata.name is required")
+	assert.Empty(t, srv.received(http.MethodPost, "/api/v1/entities/core/v1/namespace/default/services"))
+}
+
+// testEntityWithRelations returns entity as entity get returns it, without
+// relations
+func testEntityWithRelations(entity map[string]any) map[string]any {
+	return map[string]any{"entity": entity, "related_entities": []any{}, "relations": []any{}}
+}
+
+func TestEntityUpdateCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	existing := testEntity("web")
+	existing["spec"] = map[string]any{"port": 80}
+	srv.handle("GET /api/v1/entities/core/v1/services/default/web", http.StatusOK, testEntityWithRelations(existing))
+	srv.handle("DELETE /api/v1/entities/core/v1/services/default/web", http.StatusNoContent, nil)
+	srv.handle("POST /api/v1/entities/core/v1/namespace/default/services", http.StatusCreated, testEntity("web"))
+
+	// The file only needs what's changing
+	withStdin(t, "spec:\n  port: 8080\n")
+	cmd := EntityUpdateCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		EntityID:          "core/v1/services/default/web",
+		File:              "-",
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Entity 'web' updated successfully in namespace 'default'")
+
+	srv.requireRequest(http.MethodDelete, "/api/v1/entities/core/v1/services/default/web")
+	body := srv.requireRequest(http.MethodPost, "/api/v1/entities/core/v1/namespace/default/services").JSON(t)
+	assert.Equal(t, "core/v1", body["apiVersion"])
+	assert.Equal(t, "Service", body["kind"])
+	assert.Equal(t, map[string]any{"name": "web", "namespace": "default"}, body["metadata"])
+	assert.Equal(t, map[string]any{"port": 8080.0}, body["spec"])
+
+	// An update can't move the entity
+	withStdin(t, "metadata:\n  name: api\n")
+	_, err = captureOutput(t, cmd.Run)
+	require.EqualError(t, err, "metadata.name can't be changed from web to api")
+	assert.Len(t, srv.received(http.MethodDelete, "/api/v1/entities/core/v1/services/default/web"), 1)
+}
+
+func TestEntityUpdateCommand_NotFound(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/core/v1/services/default/web", http.StatusNotFound, nil)
+	withStdin(t, "spec:\n  port: 8080\n")
+
+	cmd := EntityUpdateCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		EntityID:          "core/v1/services/default/web",
+		File:              "-",
+	}
+	_, err := captureOutput(t, cmd.Run)
+	require.EqualError(t, err, "entity not found")
+	assert.Empty(t, srv.received(http.MethodDelete, "/api/v1/entities/core/v1/services/default/web"))
+}
+
+func TestEntityPatchCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	existing := testEntity("web")
+	existing["metadata"] = map[string]any{"name": "web", "namespace": "default", "labels": map[string]any{"team": "payments", "tier": "1"}}
+	existing["spec"] = map[string]any{"port": 80, "replicas": 2}
+	srv.handle("GET /api/v1/entities/core/v1/services/default/web", http.StatusOK, testEntityWithRelations(existing))
+	srv.handle("DELETE /api/v1/entities/core/v1/services/default/web", http.StatusNoContent, nil)
+	srv.handle("POST /api/v1/entities/core/v1/namespace/default/services", http.StatusCreated, testEntity("web"))
+
+	cmd := EntityPatchCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		EntityID:          "core/v1/services/default/web",
+		Patch:             `{"metadata": {"labels": {"tier": null, "owner": "alice"}}, "spec": {"replicas": 3}}`,
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Entity 'web' updated successfully")
+
+	body := srv.requireRequest(http.MethodPost, "/api/v1/entities/core/v1/namespace/default/services").JSON(t)
+	assert.Equal(t, map[string]any{
+		"name":      "web",
+		"namespace": "default",
+		"labels":    map[string]any{"team": "payments", "owner": "alice"},
+	}, body["metadata"])
+	assert.Equal(t, map[string]any{"port": 80.0, "replicas": 3.0}, body["spec"])
+
+	// Patches that change nothing send nothing
+	cmd.Patch = `{"spec": {"port": 80}}`
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "is unchanged")
+	assert.Len(t, srv.received(http.MethodDelete, "/api/v1/entities/core/v1/services/default/web"), 1)
+
+	cmd.Patch = `{"kind": "Database"}`
+	_, err = captureOutput(t, cmd.Run)
+	require.EqualError(t, err, "kind can't be changed from Service to Database")
+
+	cmd.Patch = `[1]`
+	require.EqualError(t, cmd.Run(), "invalid --patch: must be a JSON object")
+}
+
+func TestMergePatch(t *testing.T) {
+	// Examples from RFC 7396
+	tests := []struct {
+		target, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		var target, patch any
+		require.NoError(t, json.Unmarshal([]byte(tt.target), &target))
+		require.NoError(t, json.Unmarshal([]byte(tt.patch), &patch))
+		got, err := json.Marshal(mergePatch(target, patch))
+		require.NoError(t, err)
+		assert.JSONEq(t, tt.want, string(got), "%s patched with %s", tt.target, tt.patch)
+	}
+}
+
+// editorKeeps is a save for fakeEditor that leaves the file as it was opened
+const editorKeeps = "<unchanged>"
+
+// fakeEditor sets EDITOR to a script that saves each of saves in turn, and
+// returns a function for what the editor was given on each run
+func fakeEditor(t *testing.T, saves ...string) func(run int) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake editor is a shell script")
+	}
+
+	dir := t.TempDir()
+	for i, save := range saves {
+		if save != editorKeeps {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("save-%d", i+1)), []byte(save), 0600))
+		}
+	}
+	script := fmt.Sprintf(`#!/bin/sh
+n=$(($(cat %[1]s/count 2>/dev/null || echo 0) + 1))
+echo $n > %[1]s/count
+cp "$1" %[1]s/opened-$n
+if [ -f %[1]s/save-$n ]; then cp %[1]s/save-$n "$1"; fi
+`, dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "editor"), []byte(script), 0700)) // #nosec G306 - test executable
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", filepath.Join(dir, "editor"))
+
+	return func(run int) string {
+		data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("opened-%d", run)))
+		require.NoError(t, err, "editor run %d", run)
+		return string(data)
+	}
+}
+
+func TestEntityEditCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	existing := testEntity("web")
+	existing["spec"] = map[string]any{"port": 80}
+	srv.handle("GET /api/v1/entities/core/v1/services/default/web", http.StatusOK, testEntityWithRelations(existing))
+	srv.handle("DELETE /api/v1/entities/core/v1/services/default/web", http.StatusNoContent, nil)
+	srv.handle("POST /api/v1/entities/core/v1/namespace/default/services", http.StatusCreated, testEntity("web"))
+
+	opened := fakeEditor(t, `apiVersion: core/v1
+kind: Service
+metadata:
+  name: web
+  namespace: default
+spec:
+  port: 8080
+`)
+	cmd := EntityEditCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		EntityID:          "core/v1/services/default/web",
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Entity 'web' updated successfully")
+
+	assert.Contains(t, opened(1), "# Edit the entity below")
+	assert.Contains(t, opened(1), "kind: Service\n")
+	assert.Contains(t, opened(1), "port: 80\n")
+
+	srv.requireRequest(http.MethodDelete, "/api/v1/entities/core/v1/services/default/web")
+	body := srv.requireRequest(http.MethodPost, "/api/v1/entities/core/v1/namespace/default/services").JSON(t)
+	assert.Equal(t, map[string]any{"port": 8080.0}, body["spec"])
+}
+
+func TestEntityEditCommand_Retry(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/core/v1/services/default/web", http.StatusOK, testEntityWithRelations(testEntity("web")))
+	srv.handle("DELETE /api/v1/entities/core/v1/services/default/web", http.StatusNoContent, nil)
+	srv.handle("POST /api/v1/entities/core/v1/namespace/default/services", http.StatusCreated, testEntity("web"))
+
+	// A parse error, then a rename, are both sent back to the editor
+	opened := fakeEditor(t,
+		"kind: Service\nmetadata: [\n",
+		"apiVersion: core/v1\nkind: Service\nmetadata: {name: api, namespace: default}\n",
+		"apiVersion: core/v1\nkind: Service\nmetadata: {name: web, namespace: default}\nspec: {port: 80}\n",
+	)
+	cmd := EntityEditCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		EntityID:          "core/v1/services/default/web",
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Entity 'web' updated successfully")
+
+	assert.Contains(t, opened(2), "# The edit couldn't be saved:\n#   failed to parse entity 1")
+	assert.Contains(t, opened(2), "metadata: [\n")
+	assert.Contains(t, opened(3), "#   metadata.name can't be changed from web to api\n")
+	assert.Len(t, srv.received(http.MethodPost, "/api/v1/entities/core/v1/namespace/default/services"), 1)
+}
+
+func TestEntityEditCommand_NoChanges(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/core/v1/services/default/web", http.StatusOK, testEntityWithRelations(testEntity("web")))
+
+	cmd := EntityEditCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		EntityID:          "core/v1/services/default/web",
+	}
+
+	// Closing the editor without changes
+	fakeEditor(t, editorKeeps)
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "is unchanged")
+
+	// Emptying the file
+	fakeEditor(t, "# all gone\n")
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Edit cancelled, no changes made.")
+
+	// Saving the same mistake twice
+	fakeEditor(t, "apiVersion: core/v1\nkind: Database\nmetadata: {name: web, namespace: default}\n", editorKeeps)
+	_, err = captureOutput(t, cmd.Run)
+	require.EqualError(t, err, "edit cancelled: kind can't be changed from Service to Database")
+
+	assert.Empty(t, srv.received(http.MethodDelete, "/api/v1/entities/core/v1/services/default/web"))
+	assert.Empty(t, srv.received(http.MethodPost, "/api/v1/entities/core/v1/namespace/default/services"))
+}
+
+func TestEntityRelationshipsCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	reference := func(name string) map[string]any {
+		return map[string]any{"apiVersion": "core/v1", "kind": "Service", "name": name, "id": "core/v1/service/default/" + name}
+	}
+	response := testEntityWithRelations(testEntity("web"))
+	response["relations"] = []map[string]any{
+		{"relation": "DEPENDS_ON", "source": reference("web"), "target": reference("db"), "namespace": "default"},
+		{"relation": "CALLS", "source": reference("frontend"), "target": reference("web"), "namespace": "default"},
+	}
+	srv.handle("GET /api/v1/entities/core/v1/services/default/web", http.StatusOK, response)
+	srv.handle("GET /api/v1/entities/core/v1/services/default/gone", http.StatusNotFound, nil)
+
+	cfg.Output = "table"
+	cmd := EntityRelationshipsCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		EntityID:          "core/v1/services/default/web",
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Regexp(t, `Outgoing\s+DEPENDS_ON\s+core/v1/service/default/db`, output)
+	assert.Regexp(t, `Incoming\s+CALLS\s+core/v1/service/default/frontend`, output)
+
+	// Only the entity is read, not the catalog
+	srv.requireRequest(http.MethodGet, "/api/v1/entities/core/v1/services/default/web")
+	assert.Empty(t, srv.received(http.MethodGet, "/api/v1/entities"))
+	assert.Empty(t, srv.received(http.MethodGet, "/api/v1/entities/"))
+
+	cmd.EntityID = "core/v1/services/default/gone"
+	_, err = captureOutput(t, cmd.Run)
+	require.EqualError(t, err, "entity not found: core/v1/services/default/gone")
+}
+
+func TestEntityListCommand_All(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	total := devgraph.PageSize + 5
+	srv.mux.HandleFunc("GET /api/v1/entities/", func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		entities := []map[string]any{}
+		for i := offset; i < min(offset+limit, total); i++ {
+			entities = append(entities, testEntity(fmt.Sprintf("svc-%d", i)))
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"primary_entities": entities})
+	})
+
+	cfg.Output = "name"
+	cmd := EntityListCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Label:             "team=payments",
+		Limit:             10,
+		All:               true,
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(output), "\n"), total)
+
+	// Pages follow each other until one comes back short; --limit is ignored
+	requests := srv.received(http.MethodGet, "/api/v1/entities/")
+	require.Len(t, requests, 1+devgraph.PageRequests)
+	assert.Equal(t, "label=team%3Dpayments&limit=1000&offset=0", requests[0].Query)
+
+	// Without --all, a full page warns that there may be more
+	logs := captureLogs(t)
+	cmd.All = false
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(output), "\n"), 10)
+	assert.Contains(t, logs.String(), "showing the first 10 entities")
+}
@@ -0,0 +1,231 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
+	"github.com/arctir/devgraph-cli/pkg/util"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+)
+
+// EntityUpdateCommand replaces an entity with the one in a file. Like entity
+// apply, it deletes and recreates the entity, since the API has no update
+// operation.
+type EntityUpdateCommand struct {
+	EnvWrapperCommand
+	EntityID string `arg:"" required:"" help:"Entity ID in the format [entity://]<group>/<version>/<plural>/<namespace>/<name>."`
+	File     string `short:"f" required:"" help:"Entity YAML or JSON file, or - to read it from stdin. apiVersion, kind, name and namespace default to the entity's."`
+}
+
+// EntityPatchCommand changes fields of an entity with a JSON merge patch
+// (RFC 7396): objects in the patch are merged into the entity, other values
+// replace what's there, and nulls remove fields.
+type EntityPatchCommand struct {
+	EnvWrapperCommand
+	EntityID string `arg:"" required:"" help:"Entity ID in the format [entity://]<group>/<version>/<plural>/<namespace>/<name>."`
+	Patch    string `required:"" help:"JSON merge patch to apply, e.g. '{\"spec\":{\"replicas\":3}}'."`
+}
+
+func (e *EntityUpdateCommand) Run() error {
+	params, err := entityParams(e.EntityID)
+	if err != nil {
+		return err
+	}
+
+	data, err := util.ReadFileOrStdin(e.File)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", e.File, err)
+	}
+	entities, err := parseEntityManifests(data)
+	if err != nil {
+		return err
+	}
+	if len(entities) != 1 {
+		return fmt.Errorf("%s must hold exactly one entity, found %d", e.File, len(entities))
+	}
+	entity := entities[0]
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	ctx := context.Background()
+	existing, err := getEntityWithRelations(ctx, client, params)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("entity not found")
+	}
+
+	// Fill in what the file leaves out from the entity being updated
+	if entity.ApiVersion == "" {
+		entity.ApiVersion = existing.Entity.ApiVersion
+	}
+	if entity.Kind == "" {
+		entity.Kind = existing.Entity.Kind
+	}
+	if entity.Metadata == nil {
+		entity.Metadata = map[string]interface{}{}
+	}
+	if metadata, ok := entity.Metadata.(map[string]interface{}); ok {
+		if name, _ := metadata["name"].(string); name == "" {
+			metadata["name"] = params.Name
+		}
+		if namespace, _ := metadata["namespace"].(string); namespace == "" {
+			metadata["namespace"] = params.Namespace
+		}
+	}
+
+	return updateEntity(ctx, client, e.DryRun, params, entity, existing)
+}
+
+func (e *EntityPatchCommand) Run() error {
+	params, err := entityParams(e.EntityID)
+	if err != nil {
+		return err
+	}
+
+	var patch any
+	if err := json.Unmarshal([]byte(e.Patch), &patch); err != nil {
+		return fmt.Errorf("invalid --patch: %w", err)
+	}
+	if _, ok := patch.(map[string]any); !ok {
+		return fmt.Errorf("invalid --patch: must be a JSON object")
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	ctx := context.Background()
+	existing, err := getEntityWithRelations(ctx, client, params)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("entity not found")
+	}
+
+	// Patch the entity as entity get prints it
+	data, err := json.Marshal(devgraph.NewEntity(existing.Entity))
+	if err != nil {
+		return fmt.Errorf("failed to marshal entity: %w", err)
+	}
+	var current any
+	if err := json.Unmarshal(data, &current); err != nil {
+		return fmt.Errorf("failed to unmarshal entity: %w", err)
+	}
+	if data, err = json.Marshal(mergePatch(current, patch)); err != nil {
+		return fmt.Errorf("failed to marshal patched entity: %w", err)
+	}
+	var entity FilteredEntity
+	if err := json.Unmarshal(data, &entity); err != nil {
+		return fmt.Errorf("invalid patched entity: %w", err)
+	}
+
+	return updateEntity(ctx, client, e.DryRun, params, entity, existing)
+}
+
+// entityParams parses an entity ID into the parameters that name it
+func entityParams(entityID string) (api.GetEntityParams, error) {
+	group, version, plural, namespace, name, err := parseEntityID(entityID)
+	if err != nil {
+		return api.GetEntityParams{}, err
+	}
+	return api.GetEntityParams{
+		Group:     group,
+		Version:   version,
+		Kind:      plural, // Kind is synonymous with plural
+		Namespace: namespace,
+		Name:      name,
+	}, nil
+}
+
+// formatEntityID returns the entity ID naming the entity params names
+func formatEntityID(params api.GetEntityParams) string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s", params.Group, params.Version, params.Kind, params.Namespace, params.Name)
+}
+
+// updateEntity replaces existing, the entity named by params, with entity.
+// entity must be the same kind of entity in the same place, since changing
+// those would create a different entity rather than update this one.
+func updateEntity(ctx context.Context, client *api.Client, dryRun bool, params api.GetEntityParams, entity FilteredEntity, existing *api.EntityWithRelationsResponse) error {
+	if err := checkEntityIdentity(params, entity, existing.Entity); err != nil {
+		return err
+	}
+
+	changed, err := entityChanged(entity, existing.Entity)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		fmt.Printf("Entity '%s' in namespace '%s' is unchanged.\n", params.Name, params.Namespace)
+		return nil
+	}
+	if dryRun {
+		fmt.Printf("Dry run: Would update entity '%s' in namespace '%s'.\n", params.Name, params.Namespace)
+		return nil
+	}
+
+	m := entityManifest{Entity: entity, Params: params}
+	if err := replaceEntity(ctx, client, m, existing); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Entity '%s' updated successfully in namespace '%s'.\n", params.Name, params.Namespace)
+	return nil
+}
+
+// checkEntityIdentity returns an error if entity isn't stored where params
+// says existing is, or isn't of the same kind
+func checkEntityIdentity(params api.GetEntityParams, entity FilteredEntity, existing api.EntityResponse) error {
+	if entity.Metadata == nil {
+		return fmt.Errorf("metadata is required")
+	}
+	metadata, ok := entity.Metadata.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid metadata format")
+	}
+	name, _ := metadata["name"].(string)
+	namespace, _ := metadata["namespace"].(string)
+	group, version, ok := strings.Cut(entity.ApiVersion, "/")
+	if !ok {
+		group, version = "core", entity.ApiVersion
+	}
+	switch {
+	case group != params.Group || version != params.Version:
+		return fmt.Errorf("apiVersion can't be changed from %s to %s", existing.ApiVersion, entity.ApiVersion)
+	case entity.Kind != existing.Kind:
+		return fmt.Errorf("kind can't be changed from %s to %s", existing.Kind, entity.Kind)
+	case name != params.Name:
+		return fmt.Errorf("metadata.name can't be changed from %s to %s", params.Name, name)
+	case namespace != params.Namespace:
+		return fmt.Errorf("metadata.namespace can't be changed from %s to %s", params.Namespace, namespace)
+	}
+	return nil
+}
+
+// mergePatch applies a JSON merge patch (RFC 7396) to target
+func mergePatch(target, patch any) any {
+	fields, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	merged, ok := target.(map[string]any)
+	if !ok {
+		merged = map[string]any{}
+	}
+	for key, value := range fields {
+		if value == nil {
+			delete(merged, key)
+		} else {
+			merged[key] = mergePatch(merged[key], value)
+		}
+	}
+	return merged
+}
@@ -1,29 +1,49 @@
 package commands
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/arctir/devgraph-cli/pkg/output"
 	"github.com/arctir/devgraph-cli/pkg/util"
 	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
 	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
 )
 
 type EntityDefinitionCommand struct {
-	Create EntityDefinitionCreateCommand `cmd:"create" help:"Create a new entity definition."`
-	List   EntityDefinitionListCommand   `cmd:"" help:"List entity definitions."`
-	Get    EntityDefinitionGetCommand    `cmd:"get" help:"Get an entity definition by ID."`
-	Delete EntityDefinitionDeleteCommand `cmd:"delete" help:"Delete an entity definition by ID."`
+	Create    EntityDefinitionCreateCommand    `cmd:"create" help:"Create a new entity definition."`
+	List      EntityDefinitionListCommand      `cmd:"" help:"List entity definitions."`
+	Get       EntityDefinitionGetCommand       `cmd:"get" help:"Get an entity definition by ID."`
+	Delete    EntityDefinitionDeleteCommand    `cmd:"delete" help:"Delete an entity definition by ID."`
+	Generate  EntityDefinitionGenerateCommand  `cmd:"generate" help:"Generate Go or TypeScript types for an entity definition's spec."`
+	Validate  EntityDefinitionValidateCommand  `cmd:"validate" help:"Check entity definition files for errors before creating them."`
+	Diff      EntityDefinitionDiffCommand      `cmd:"diff" help:"Compare entity definition files with the definitions on the server."`
+	Versions  EntityDefinitionVersionsCommand  `cmd:"versions" help:"List the versions of an entity definition."`
+	Example   EntityDefinitionExampleCommand   `cmd:"example" help:"Print an example entity for an entity definition."`
+	Export    EntityDefinitionExportCommand    `cmd:"export" help:"Export an entity definition as a Kubernetes CRD or OpenAPI document."`
+	Apply     EntityDefinitionApplyCommand     `cmd:"apply" help:"Create the entity definitions in a file or directory that don't exist yet."`
+	ImportCRD EntityDefinitionImportCRDCommand `cmd:"import-crd" help:"Create entity definitions from Kubernetes CustomResourceDefinitions."`
 }
 
 type EntityDefinitionCreateCommand struct {
 	EnvWrapperCommand
-	FileName string `arg:"" required:"" help:"Path to the entity definition JSON file."`
+	FileName    string `arg:"" optional:"" help:"Path to the entity definition JSON or YAML file, or - to read it from stdin."`
+	FromSchema  string `flag:"from-schema" help:"Create the definition from a JSON Schema file describing the entity spec (- reads it from stdin)."`
+	Group       string `flag:"group" help:"Group of the definition (with --from-schema)."`
+	Kind        string `flag:"kind" help:"Kind of the definition (with --from-schema)."`
+	Version     string `flag:"version" default:"v1" help:"Version of the definition (with --from-schema)."`
+	Singular    string `flag:"singular" help:"Singular name (with --from-schema, defaults to the lowercased kind)."`
+	Plural      string `flag:"plural" help:"Plural name (with --from-schema, defaults to the singular name with an 's')."`
+	Description string `flag:"description" help:"Description (with --from-schema, defaults to the schema description)."`
 }
 
 type EntityDefinitionListCommand struct {
 	EnvWrapperCommand
-	Output string `short:"o" help:"Output format: table, json, yaml" default:"table"`
 }
 
 type EntityDefinitionGetCommand struct {
@@ -37,9 +57,155 @@ type EntityDefinitionDeleteCommand struct {
 }
 
 func (e *EntityDefinitionCreateCommand) Run() error {
+	var def FilteredEntityDefinition
+	var err error
+	switch {
+	case e.FileName != "" && e.FromSchema != "":
+		return fmt.Errorf("specify either a definition file or --from-schema, not both")
+	case e.FromSchema != "":
+		def, err = e.definitionFromSchema()
+	case e.FileName != "":
+		def, err = readEntityDefinitionFile(e.FileName)
+	default:
+		return fmt.Errorf("a definition file or --from-schema is required")
+	}
+	if err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	created, err := createEntityDefinition(context.Background(), client, def)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Entity definition '%s/%s' created successfully with ID %s.\n", created.Group, created.Kind, created.ID)
 	return nil
 }
 
+// createEntityDefinition creates a definition
+func createEntityDefinition(ctx context.Context, client *api.Client, def FilteredEntityDefinition) (*api.EntityDefinitionResponse, error) {
+	apiDef, err := newEntityDefinitionSpec(def)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.CreateEntityDefinition(ctx, apiDef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create entity definition: %w", err)
+	}
+	switch r := resp.(type) {
+	case *api.EntityDefinitionResponse:
+		return r, nil
+	case *api.HTTPValidationError:
+		return nil, fmt.Errorf("validation error: %v", r.Detail)
+	default:
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
+	}
+}
+
+// definitionFromSchema builds a definition from the command's JSON Schema
+// file and naming flags
+func (e *EntityDefinitionCreateCommand) definitionFromSchema() (FilteredEntityDefinition, error) {
+	if e.Group == "" || e.Kind == "" {
+		return FilteredEntityDefinition{}, fmt.Errorf("--group and --kind are required with --from-schema")
+	}
+
+	data, err := util.ReadFileOrStdin(e.FromSchema)
+	if err != nil {
+		return FilteredEntityDefinition{}, fmt.Errorf("failed to read schema file: %w", err)
+	}
+	spec, err := schemaToDefinitionSpec(data)
+	if err != nil {
+		return FilteredEntityDefinition{}, err
+	}
+
+	def := FilteredEntityDefinition{
+		Group:       e.Group,
+		Kind:        e.Kind,
+		Singular:    e.Singular,
+		Plural:      e.Plural,
+		Name:        e.Version,
+		Description: e.Description,
+		Spec:        spec,
+	}
+	if def.Description == "" {
+		def.Description, _ = spec["description"].(string)
+	}
+	definitionNames(&def)
+	return def, nil
+}
+
+// readEntityDefinitionFile reads a definition in the format written by
+// entity backup, deriving any names left out from its kind
+func readEntityDefinitionFile(path string) (FilteredEntityDefinition, error) {
+	def, err := parseEntityDefinitionFile(path, false)
+	if err != nil {
+		return FilteredEntityDefinition{}, err
+	}
+	if def.Group == "" || def.Kind == "" {
+		return FilteredEntityDefinition{}, fmt.Errorf("definition file must set group and kind")
+	}
+	definitionNames(&def)
+	return def, nil
+}
+
+// parseEntityDefinitionFile reads a definition file as is. strict rejects
+// unknown fields.
+func parseEntityDefinitionFile(path string, strict bool) (FilteredEntityDefinition, error) {
+	data, err := util.ReadFileOrStdin(path)
+	if err != nil {
+		return FilteredEntityDefinition{}, fmt.Errorf("failed to read definition file: %w", err)
+	}
+
+	var def FilteredEntityDefinition
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(strict)
+	if err := decoder.Decode(&def); err != nil {
+		return FilteredEntityDefinition{}, fmt.Errorf("failed to parse definition file: %w", err)
+	}
+	return def, nil
+}
+
+// newEntityDefinitionSpec converts a definition to its API create request
+func newEntityDefinitionSpec(def FilteredEntityDefinition) (*api.EntityDefinitionSpec, error) {
+	apiDef := &api.EntityDefinitionSpec{
+		Group:    def.Group,
+		Kind:     def.Kind,
+		ListKind: def.ListKind,
+		Singular: def.Singular,
+		Spec:     api.EntityDefinitionSpecSpec{},
+	}
+	if def.Plural != "" {
+		apiDef.Plural.SetTo(def.Plural)
+	}
+	if def.Name != "" {
+		apiDef.Name.SetTo(def.Name)
+	}
+	if def.Description != "" {
+		apiDef.Description.SetTo(def.Description)
+	}
+	if def.Spec != nil {
+		specBytes, err := json.Marshal(def.Spec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode spec of %s/%s: %w", def.Group, def.Kind, err)
+		}
+		if err := json.Unmarshal(specBytes, &apiDef.Spec); err != nil {
+			return nil, fmt.Errorf("spec of %s/%s must be an object: %w", def.Group, def.Kind, err)
+		}
+	}
+	if def.Storage {
+		apiDef.Storage.SetTo(def.Storage)
+	}
+	if def.Served {
+		apiDef.Served.SetTo(def.Served)
+	}
+	return apiDef, nil
+}
+
 func (e *EntityDefinitionListCommand) Run() error {
 	client, err := util.GetAuthenticatedClient(e.Config)
 	if err != nil {
@@ -54,10 +220,6 @@ func (e *EntityDefinitionListCommand) Run() error {
 	switch r := resp.(type) {
 	case *api.GetEntityDefinitionsOKApplicationJSON:
 		defs := []api.EntityDefinitionResponse(*r)
-		if len(defs) == 0 {
-			fmt.Println("No entity definitions found.")
-			return nil
-		}
 
 		type defOutput struct {
 			ID          string `json:"id" yaml:"id"`
@@ -67,6 +229,7 @@ func (e *EntityDefinitionListCommand) Run() error {
 
 		structured := make([]defOutput, len(defs))
 		tableData := make([]map[string]any, len(defs))
+		names := make([]string, len(defs))
 		for i, def := range defs {
 			version := ""
 			if def.Name.IsSet() {
@@ -91,10 +254,16 @@ func (e *EntityDefinitionListCommand) Run() error {
 				"Type":        typeStr,
 				"Description": description,
 			}
+			names[i] = def.ID.String()
 		}
 
-		headers := []string{"ID", "Type", "Description"}
-		return util.FormatOutput(e.Output, structured, headers, tableData)
+		return output.Print(e.OutputFormat(output.Table), output.Result{
+			Data:    structured,
+			Headers: []string{"ID", "Type", "Description"},
+			Rows:    tableData,
+			Empty:   "No entity definitions found.",
+			Names:   names,
+		})
 	default:
 		return fmt.Errorf("failed to fetch entity definitions")
 	}
@@ -133,3 +302,151 @@ func (e *EntityDefinitionDeleteCommand) Run() error {
 
 	return nil
 }
+
+// listEntityDefinitions returns every entity definition in the environment
+func listEntityDefinitions(ctx context.Context, client *api.Client) ([]api.EntityDefinitionResponse, error) {
+	resp, err := client.GetEntityDefinitions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entity definitions: %w", err)
+	}
+	switch r := resp.(type) {
+	case *api.GetEntityDefinitionsOKApplicationJSON:
+		return *r, nil
+	case *api.GetEntityDefinitionsNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
+	}
+}
+
+// findEntityDefinition finds a definition by ID, group/kind or
+// group/version/kind. When group/kind matches several versions the storage
+// version is used.
+func findEntityDefinition(defs []api.EntityDefinitionResponse, ref string) (*api.EntityDefinitionResponse, error) {
+	matches, err := matchEntityDefinitions(defs, ref)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	for _, def := range matches {
+		if def.Storage.Or(true) {
+			return def, nil
+		}
+	}
+	return nil, fmt.Errorf("entity definition '%s' has several versions; specify one as <group>/<version>/<kind>", ref)
+}
+
+// matchEntityDefinitions returns the definitions matching an ID,
+// group/version/kind, or group/kind, which matches every version
+func matchEntityDefinitions(defs []api.EntityDefinitionResponse, ref string) ([]*api.EntityDefinitionResponse, error) {
+	if id, err := uuid.Parse(ref); err == nil {
+		for i := range defs {
+			if defs[i].ID == id {
+				return []*api.EntityDefinitionResponse{&defs[i]}, nil
+			}
+		}
+		return nil, fmt.Errorf("entity definition '%s' not found", ref)
+	}
+
+	parts := strings.Split(ref, "/")
+	var group, version, kind string
+	switch len(parts) {
+	case 2:
+		group, kind = parts[0], parts[1]
+	case 3:
+		group, version, kind = parts[0], parts[1], parts[2]
+	default:
+		return nil, fmt.Errorf("invalid entity definition '%s': expected <group>/<kind>, <group>/<version>/<kind> or an ID", ref)
+	}
+
+	var matches []*api.EntityDefinitionResponse
+	for i := range defs {
+		def := &defs[i]
+		if def.Group != group || !strings.EqualFold(def.Kind, kind) {
+			continue
+		}
+		if version != "" && def.Name.Or("v1") != version {
+			continue
+		}
+		matches = append(matches, def)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("entity definition '%s' not found", ref)
+	}
+	return matches, nil
+}
+
+// EntityDefinitionVersionsCommand lists the versions of a definition
+type EntityDefinitionVersionsCommand struct {
+	EnvWrapperCommand
+	Definition string `arg:"" required:"" help:"Entity definition as <group>/<kind>."`
+}
+
+// definitionVersion is one version of an entity definition
+type definitionVersion struct {
+	ID          string `json:"id" yaml:"id"`
+	Version     string `json:"version" yaml:"version"`
+	Served      bool   `json:"served" yaml:"served"`
+	Storage     bool   `json:"storage" yaml:"storage"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+func (e *EntityDefinitionVersionsCommand) Run() error {
+	format := e.OutputFormat(output.Table)
+	if err := output.Check(format, output.Table, output.JSON, output.YAML, output.Name); err != nil {
+		return err
+	}
+
+	group, kind, ok := strings.Cut(e.Definition, "/")
+	if !ok || group == "" || kind == "" || strings.Contains(kind, "/") {
+		return fmt.Errorf("invalid entity definition '%s': expected <group>/<kind>", e.Definition)
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+	defs, err := listEntityDefinitions(context.Background(), client)
+	if err != nil {
+		return err
+	}
+
+	versions := []definitionVersion{}
+	for _, def := range defs {
+		if def.Group != group || !strings.EqualFold(def.Kind, kind) {
+			continue
+		}
+		versions = append(versions, definitionVersion{
+			ID:          def.ID.String(),
+			Version:     def.Name.Or("v1"),
+			Served:      def.Served.Or(true),
+			Storage:     def.Storage.Or(true),
+			Description: def.Description.Or(""),
+		})
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("entity definition '%s' not found", e.Definition)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+
+	tableData := make([]map[string]any, len(versions))
+	names := make([]string, len(versions))
+	for i, v := range versions {
+		tableData[i] = map[string]any{
+			"Version":     v.Version,
+			"Served":      v.Served,
+			"Storage":     v.Storage,
+			"ID":          v.ID,
+			"Description": v.Description,
+		}
+		names[i] = v.Version
+	}
+	return output.Print(format, output.Result{
+		Data:    versions,
+		Headers: []string{"Version", "Served", "Storage", "ID", "Description"},
+		Rows:    tableData,
+		Names:   names,
+	})
+}
@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+
+	"github.com/arctir/devgraph-cli/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+// EntityDefinitionImportCRDCommand creates definitions from Kubernetes CRDs
+type EntityDefinitionImportCRDCommand struct {
+	EnvWrapperCommand
+	Source string   `arg:"" required:"" help:"CRD YAML file (- reads it from stdin), or a kubectl context to read CRDs from."`
+	Name   []string `flag:"name" help:"Only import the CRDs with these names (e.g. widgets.example.com)."`
+}
+
+// crdList is a list of CRDs as returned by kubectl
+type crdList struct {
+	Kind  string        `yaml:"kind"`
+	Items []crdDocument `yaml:"items"`
+}
+
+func (e *EntityDefinitionImportCRDCommand) Run() error {
+	data, err := e.readCRDs()
+	if err != nil {
+		return err
+	}
+	crds, err := parseCRDs(data)
+	if err != nil {
+		return err
+	}
+
+	var defs []FilteredEntityDefinition
+	for _, crd := range crds {
+		name, _ := crd.Metadata["name"].(string)
+		if len(e.Name) > 0 && !slices.Contains(e.Name, name) {
+			continue
+		}
+		defs = append(defs, crdDefinitions(crd)...)
+	}
+	if len(defs) == 0 {
+		return fmt.Errorf("no CustomResourceDefinitions found in %s", e.Source)
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	var summary definitionApplySummary
+	if err := applyEntityDefinitions(context.Background(), client, defs, e.DryRun, &summary); err != nil {
+		return err
+	}
+	return summary.result(e.DryRun)
+}
+
+// readCRDs reads the source file, or if there is no such file, gets the
+// CRDs of the kubectl context with that name
+func (e *EntityDefinitionImportCRDCommand) readCRDs() ([]byte, error) {
+	data, err := util.ReadFileOrStdin(e.Source)
+	if err == nil {
+		return data, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", e.Source, err)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("kubectl", "--context", e.Source, "get", "customresourcedefinitions", "-o", "yaml")
+	cmd.Stderr = &stderr
+	data, err = cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("'%s' is not a file, and reading CRDs from kubectl context '%s' failed: %w: %s", e.Source, e.Source, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return data, nil
+}
+
+// parseCRDs reads the CRDs in a YAML stream of CRDs and lists of CRDs
+func parseCRDs(data []byte) ([]crdDocument, error) {
+	var crds []crdDocument
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				return crds, nil
+			}
+			return nil, fmt.Errorf("failed to parse CRDs: %w", err)
+		}
+
+		var list crdList
+		if err := node.Decode(&list); err != nil {
+			return nil, fmt.Errorf("failed to parse CRDs: %w", err)
+		}
+		switch list.Kind {
+		case "List", "CustomResourceDefinitionList":
+			for _, crd := range list.Items {
+				if crd.Kind == "CustomResourceDefinition" {
+					crds = append(crds, crd)
+				}
+			}
+		case "CustomResourceDefinition":
+			var crd crdDocument
+			if err := node.Decode(&crd); err != nil {
+				return nil, fmt.Errorf("failed to parse CRD: %w", err)
+			}
+			crds = append(crds, crd)
+		}
+	}
+}
+
+// crdDefinitions converts each version of a CRD to an entity definition,
+// using the schema of the resource's spec as the definition spec
+func crdDefinitions(crd crdDocument) []FilteredEntityDefinition {
+	defs := make([]FilteredEntityDefinition, 0, len(crd.Spec.Versions))
+	for _, v := range crd.Spec.Versions {
+		schema := v.Schema.OpenAPIV3Schema
+		spec := map[string]any{"type": "object"}
+		if properties, ok := schema["properties"].(map[string]any); ok {
+			if s, ok := properties["spec"].(map[string]any); ok {
+				spec = s
+			}
+		}
+		description, _ := schema["description"].(string)
+
+		defs = append(defs, FilteredEntityDefinition{
+			Group:       crd.Spec.Group,
+			Kind:        crd.Spec.Names.Kind,
+			ListKind:    crd.Spec.Names.ListKind,
+			Plural:      crd.Spec.Names.Plural,
+			Singular:    crd.Spec.Names.Singular,
+			Name:        v.Name,
+			Description: description,
+			Spec:        spec,
+			Served:      v.Served,
+			Storage:     v.Storage,
+		})
+		definitionNames(&defs[len(defs)-1])
+	}
+	return defs
+}
@@ -0,0 +1,322 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
+	"github.com/arctir/devgraph-cli/pkg/logging"
+	"github.com/arctir/devgraph-cli/pkg/util"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+	"github.com/fatih/color"
+)
+
+// EntityDefinitionDiffCommand compares definition files with the server
+type EntityDefinitionDiffCommand struct {
+	EnvWrapperCommand
+	Path string `arg:"" required:"" help:"Entity definition file, or directory of .json/.yaml files."`
+}
+
+// definitionFieldChange is a difference in one field of a definition
+type definitionFieldChange struct {
+	Field  string
+	Local  any
+	Remote any
+}
+
+func (e *EntityDefinitionDiffCommand) Run() error {
+	files, err := definitionFiles(e.Path)
+	if err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+	existing, err := listEntityDefinitions(context.Background(), client)
+	if err != nil {
+		return err
+	}
+
+	addedColor := color.New(color.FgGreen)
+	deletedColor := color.New(color.FgRed)
+	changedColor := color.New(color.FgYellow)
+
+	changed := 0
+	for _, file := range files {
+		local, err := readEntityDefinitionFile(file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		version := local.Name
+		if version == "" {
+			version = "v1"
+		}
+		ref := fmt.Sprintf("%s/%s/%s", local.Group, version, local.Kind)
+
+		remote, err := findEntityDefinition(existing, ref)
+		if err != nil {
+			fmt.Print(addedColor.Sprintf("+ %s (%s): not on the server\n", ref, file))
+			changed++
+			continue
+		}
+
+		changes, err := diffEntityDefinitions(local, devgraph.NewEntityDefinition(*remote))
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if len(changes) == 0 {
+			fmt.Printf("  %s (%s): up to date\n", ref, file)
+			continue
+		}
+
+		changed++
+		fmt.Print(changedColor.Sprintf("~ %s (%s): %d field(s) differ\n", ref, file, len(changes)))
+		for _, c := range changes {
+			switch {
+			case c.Remote == nil:
+				fmt.Print(addedColor.Sprintf("    + %s: %s\n", c.Field, diffValue(c.Local)))
+			case c.Local == nil:
+				fmt.Print(deletedColor.Sprintf("    - %s: %s\n", c.Field, diffValue(c.Remote)))
+			default:
+				fmt.Printf("    ~ %s: %s -> %s\n", c.Field, diffValue(c.Remote), diffValue(c.Local))
+			}
+		}
+	}
+
+	fmt.Printf("\n%d of %d definition(s) differ from the server\n", changed, len(files))
+	return nil
+}
+
+// definitionFiles returns path if it's a file, or the .json, .yaml and .yml
+// files in it if it's a directory
+func definitionFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var files []string
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".json", ".yaml", ".yml":
+			if !entry.IsDir() {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no definition files found in %s", path)
+	}
+	return files, nil
+}
+
+// diffEntityDefinitions returns the fields that differ between a local and a
+// remote definition, by dotted path
+func diffEntityDefinitions(local, remote FilteredEntityDefinition) ([]definitionFieldChange, error) {
+	// Create leaves these unset unless given, and the server defaults them
+	local.Storage, local.Served = true, true
+	if local.Name == "" {
+		local.Name = "v1"
+	}
+
+	localFields, err := flattenFields(local)
+	if err != nil {
+		return nil, err
+	}
+	remoteFields, err := flattenFields(remote)
+	if err != nil {
+		return nil, err
+	}
+	return diffFields(localFields, remoteFields), nil
+}
+
+// diffFields returns the fields whose values differ between two flattened
+// documents, sorted by path
+func diffFields(localFields, remoteFields map[string]any) []definitionFieldChange {
+	fields := map[string]bool{}
+	for f := range localFields {
+		fields[f] = true
+	}
+	for f := range remoteFields {
+		fields[f] = true
+	}
+
+	var changes []definitionFieldChange
+	for f := range fields {
+		l, r := localFields[f], remoteFields[f]
+		if diffValue(l) != diffValue(r) {
+			changes = append(changes, definitionFieldChange{Field: f, Local: l, Remote: r})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// flattenFields maps the dotted path of every leaf value in a definition or
+// entity to the value. Lists are leaves.
+func flattenFields(document any) (map[string]any, error) {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode document: %w", err)
+	}
+	var value map[string]any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("failed to decode document: %w", err)
+	}
+
+	fields := map[string]any{}
+	var flatten func(prefix string, v any)
+	flatten = func(prefix string, v any) {
+		m, ok := v.(map[string]any)
+		if !ok || len(m) == 0 {
+			fields[prefix] = v
+			return
+		}
+		for key, sub := range m {
+			flatten(prefix+"."+key, sub)
+		}
+	}
+	for key, v := range value {
+		flatten(key, v)
+	}
+	return fields, nil
+}
+
+// diffValue formats a field value for display and comparison
+func diffValue(v any) string {
+	if v == nil {
+		return "<unset>"
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// EntityDefinitionApplyCommand creates the definitions in files that aren't
+// on the server yet
+type EntityDefinitionApplyCommand struct {
+	EnvWrapperCommand
+	Path string `arg:"" required:"" help:"Entity definition file, or directory of .json/.yaml files."`
+}
+
+// Run creates new definitions and skips identical ones. Changed definitions
+// are reported but not updated, since the API can't update a definition and
+// deleting one also deletes its other versions.
+func (e *EntityDefinitionApplyCommand) Run() error {
+	files, err := definitionFiles(e.Path)
+	if err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	var summary definitionApplySummary
+	var defs []FilteredEntityDefinition
+	for _, file := range files {
+		def, err := readEntityDefinitionFile(file)
+		if err != nil {
+			fmt.Printf("✗ %s: %v\n", file, err)
+			summary.Failed++
+			continue
+		}
+		defs = append(defs, def)
+	}
+
+	if err := applyEntityDefinitions(context.Background(), client, defs, e.DryRun, &summary); err != nil {
+		return err
+	}
+	return summary.result(e.DryRun)
+}
+
+// definitionApplySummary counts the outcomes of applying definitions
+type definitionApplySummary struct {
+	Created, Unchanged, Changed, Failed int
+}
+
+// result prints the summary and returns an error if any definition
+// couldn't be applied
+func (s definitionApplySummary) result(dryRun bool) error {
+	verb := "Created"
+	if dryRun {
+		verb = "Would create"
+	}
+	fmt.Printf("\n%s %d, unchanged %d, changed %d, failed %d\n", verb, s.Created, s.Unchanged, s.Changed, s.Failed)
+	if s.Failed > 0 || s.Changed > 0 {
+		return fmt.Errorf("%d definition(s) could not be applied", s.Failed+s.Changed)
+	}
+	return nil
+}
+
+// applyEntityDefinitions creates the definitions that aren't on the server,
+// skipping identical ones and reporting changed ones
+func applyEntityDefinitions(ctx context.Context, client *api.Client, defs []FilteredEntityDefinition, dryRun bool, summary *definitionApplySummary) error {
+	existing, err := listEntityDefinitions(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	for _, local := range defs {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("interrupted: %w", err)
+		}
+
+		version := local.Name
+		if version == "" {
+			version = "v1"
+		}
+		ref := fmt.Sprintf("%s/%s/%s", local.Group, version, local.Kind)
+
+		if remote, err := findEntityDefinition(existing, ref); err == nil {
+			changes, err := diffEntityDefinitions(local, devgraph.NewEntityDefinition(*remote))
+			if err != nil {
+				fmt.Printf("✗ %s: %v\n", ref, err)
+				summary.Failed++
+				continue
+			}
+			if len(changes) == 0 {
+				fmt.Printf("  %s unchanged\n", ref)
+				summary.Unchanged++
+				continue
+			}
+			logging.Warn(fmt.Sprintf("%s differs from the server in %d field(s) and can't be updated in place; see 'dg entity-definition diff'", ref, len(changes)))
+			summary.Changed++
+			continue
+		}
+
+		if dryRun {
+			fmt.Printf("Would create %s\n", ref)
+			summary.Created++
+			continue
+		}
+		if _, err := createEntityDefinition(ctx, client, local); err != nil {
+			fmt.Printf("✗ %s: %v\n", ref, err)
+			summary.Failed++
+			continue
+		}
+		fmt.Printf("✅ Created %s\n", ref)
+		summary.Created++
+	}
+	return nil
+}
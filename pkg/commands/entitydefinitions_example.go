@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
+	"github.com/arctir/devgraph-cli/pkg/output"
+	"github.com/arctir/devgraph-cli/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+// EntityDefinitionExampleCommand prints an example entity for a definition
+type EntityDefinitionExampleCommand struct {
+	EnvWrapperCommand
+	Definition string `arg:"" required:"" help:"Entity definition as <group>/<kind>, <group>/<version>/<kind> or ID."`
+	Name       string `flag:"name" help:"Name of the example entity (defaults to example-<singular>)."`
+	Namespace  string `flag:"namespace,n" default:"default" help:"Namespace of the example entity."`
+}
+
+// exampleStringFormats are example values for string formats
+var exampleStringFormats = map[string]string{
+	"date":      "2024-01-01",
+	"date-time": "2024-01-01T00:00:00Z",
+	"email":     "user@example.com",
+	"hostname":  "example.com",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"uri":       "https://example.com",
+	"url":       "https://example.com",
+	"uuid":      "00000000-0000-0000-0000-000000000000",
+}
+
+func (e *EntityDefinitionExampleCommand) Run() error {
+	format := e.OutputFormat(output.JSON)
+	if err := output.Check(format, output.JSON, output.YAML); err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+	defs, err := listEntityDefinitions(context.Background(), client)
+	if err != nil {
+		return err
+	}
+	def, err := findEntityDefinition(defs, e.Definition)
+	if err != nil {
+		return err
+	}
+
+	name := e.Name
+	if name == "" {
+		name = "example-" + def.Singular
+	}
+	entity := FilteredEntity{
+		ApiVersion: fmt.Sprintf("%s/%s", def.Group, def.Name.Or("v1")),
+		Kind:       def.Kind,
+		Metadata: map[string]any{
+			"name":      name,
+			"namespace": e.Namespace,
+		},
+		Spec: exampleValue("spec", devgraph.CleanDefinitionSpec(def.Spec)),
+	}
+
+	switch format {
+	case output.JSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entity)
+	case output.YAML, "yml":
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		return encoder.Encode(entity)
+	default:
+		return output.Print(format, output.Result{Data: entity})
+	}
+}
+
+// exampleValue returns an example value for a schema: its default or first
+// example or enum value if it has one, otherwise a placeholder of its type.
+// Objects include every property.
+func exampleValue(name string, schema map[string]any) any {
+	if v, ok := schema["default"]; ok {
+		return v
+	}
+	if v, ok := schema["example"]; ok {
+		return v
+	}
+	if values, ok := schema["examples"].([]any); ok && len(values) > 0 {
+		return values[0]
+	}
+	if values, ok := schema["enum"].([]any); ok && len(values) > 0 {
+		return values[0]
+	}
+
+	switch schemaTypeName(schema) {
+	case "string":
+		if v, ok := exampleStringFormats[fmt.Sprint(schema["format"])]; ok {
+			return v
+		}
+		return "<" + name + ">"
+	case "integer", "number":
+		if v, ok := schema["minimum"]; ok {
+			return v
+		}
+		return 0
+	case "boolean":
+		return false
+	case "array":
+		items, _ := schema["items"].(map[string]any)
+		if items == nil {
+			return []any{}
+		}
+		return []any{exampleValue(name, items)}
+	case "object":
+		names, properties, _ := schemaProperties(schema)
+		example := make(map[string]any, len(names))
+		for _, prop := range names {
+			example[prop] = exampleValue(prop, properties[prop])
+		}
+		if values, ok := schema["additionalProperties"].(map[string]any); ok && len(names) == 0 {
+			example["key"] = exampleValue("value", values)
+		}
+		return example
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
+	"github.com/arctir/devgraph-cli/pkg/output"
+	"github.com/arctir/devgraph-cli/pkg/util"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+	"gopkg.in/yaml.v3"
+)
+
+// EntityDefinitionExportCommand exports definitions in other schema formats
+type EntityDefinitionExportCommand struct {
+	EnvWrapperCommand
+	Definition string `arg:"" required:"" help:"Entity definition as <group>/<kind> (all versions), <group>/<version>/<kind> or ID."`
+	Format     string `flag:"format" required:"" enum:"crd,openapi" help:"Export format: crd, openapi."`
+}
+
+// crdDocument is a Kubernetes CustomResourceDefinition
+type crdDocument struct {
+	ApiVersion string         `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string         `json:"kind" yaml:"kind"`
+	Metadata   map[string]any `json:"metadata" yaml:"metadata"`
+	Spec       crdSpec        `json:"spec" yaml:"spec"`
+}
+
+type crdSpec struct {
+	Group    string       `json:"group" yaml:"group"`
+	Names    crdNames     `json:"names" yaml:"names"`
+	Scope    string       `json:"scope" yaml:"scope"`
+	Versions []crdVersion `json:"versions" yaml:"versions"`
+}
+
+type crdNames struct {
+	Kind     string `json:"kind" yaml:"kind"`
+	ListKind string `json:"listKind" yaml:"listKind"`
+	Plural   string `json:"plural" yaml:"plural"`
+	Singular string `json:"singular" yaml:"singular"`
+}
+
+type crdVersion struct {
+	Name    string    `json:"name" yaml:"name"`
+	Served  bool      `json:"served" yaml:"served"`
+	Storage bool      `json:"storage" yaml:"storage"`
+	Schema  crdSchema `json:"schema" yaml:"schema"`
+}
+
+type crdSchema struct {
+	OpenAPIV3Schema map[string]any `json:"openAPIV3Schema" yaml:"openAPIV3Schema"`
+}
+
+func (e *EntityDefinitionExportCommand) Run() error {
+	format := e.OutputFormat(output.YAML)
+	if err := output.Check(format, output.JSON, output.YAML); err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+	defs, err := listEntityDefinitions(context.Background(), client)
+	if err != nil {
+		return err
+	}
+	matches, err := matchEntityDefinitions(defs, e.Definition)
+	if err != nil {
+		return err
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Name.Or("v1") < matches[j].Name.Or("v1") })
+
+	var document any
+	if e.Format == "openapi" {
+		document = definitionOpenAPI(matches)
+	} else {
+		document = definitionCRD(matches)
+	}
+
+	switch format {
+	case output.JSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(document)
+	case output.YAML, "yml":
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		return encoder.Encode(document)
+	default:
+		return output.Print(format, output.Result{Data: document})
+	}
+}
+
+// definitionCRD converts the versions of a definition to a CRD
+func definitionCRD(versions []*api.EntityDefinitionResponse) crdDocument {
+	def := versions[0]
+	filtered := devgraph.NewEntityDefinition(*def)
+	definitionNames(&filtered)
+
+	crd := crdDocument{
+		ApiVersion: "apiextensions.k8s.io/v1",
+		Kind:       "CustomResourceDefinition",
+		Metadata:   map[string]any{"name": filtered.Plural + "." + def.Group},
+		Spec: crdSpec{
+			Group: def.Group,
+			Names: crdNames{
+				Kind:     def.Kind,
+				ListKind: filtered.ListKind,
+				Plural:   filtered.Plural,
+				Singular: filtered.Singular,
+			},
+			Scope: "Namespaced",
+		},
+	}
+	for _, v := range versions {
+		schema := map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"spec": devgraph.CleanDefinitionSpec(v.Spec)},
+		}
+		if description := v.Description.Or(""); description != "" {
+			schema["description"] = description
+		}
+		crd.Spec.Versions = append(crd.Spec.Versions, crdVersion{
+			Name:    v.Name.Or("v1"),
+			Served:  v.Served.Or(true),
+			Storage: v.Storage.Or(true),
+			Schema:  crdSchema{OpenAPIV3Schema: schema},
+		})
+	}
+	return crd
+}
+
+// definitionOpenAPI converts the versions of a definition to an OpenAPI
+// document with a schema for each version's entities and their spec
+func definitionOpenAPI(versions []*api.EntityDefinitionResponse) map[string]any {
+	def := versions[0]
+	schemas := map[string]any{}
+	for _, v := range versions {
+		name := v.Kind
+		if len(versions) > 1 {
+			name += exportedName(v.Name.Or("v1"))
+		}
+		apiVersion := fmt.Sprintf("%s/%s", v.Group, v.Name.Or("v1"))
+
+		schemas[name+"Spec"] = devgraph.CleanDefinitionSpec(v.Spec)
+		entity := map[string]any{
+			"type":     "object",
+			"required": []string{"apiVersion", "kind", "metadata"},
+			"properties": map[string]any{
+				"apiVersion": map[string]any{"type": "string", "enum": []string{apiVersion}},
+				"kind":       map[string]any{"type": "string", "enum": []string{v.Kind}},
+				"metadata": map[string]any{
+					"type":     "object",
+					"required": []string{"name", "namespace"},
+					"properties": map[string]any{
+						"name":        map[string]any{"type": "string"},
+						"namespace":   map[string]any{"type": "string"},
+						"labels":      map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+						"annotations": map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+					},
+				},
+				"spec": map[string]any{"$ref": "#/components/schemas/" + name + "Spec"},
+			},
+		}
+		if description := v.Description.Or(""); description != "" {
+			entity["description"] = description
+		}
+		schemas[name] = entity
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   fmt.Sprintf("%s/%s", def.Group, def.Kind),
+			"version": versions[len(versions)-1].Name.Or("v1"),
+		},
+		"paths":      map[string]any{},
+		"components": map[string]any{"schemas": schemas},
+	}
+}
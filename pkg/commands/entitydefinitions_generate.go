@@ -0,0 +1,342 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
+	"github.com/arctir/devgraph-cli/pkg/util"
+)
+
+// EntityDefinitionGenerateCommand generates types for a definition's spec
+type EntityDefinitionGenerateCommand struct {
+	EnvWrapperCommand
+	Definition string `arg:"" required:"" help:"Entity definition as <group>/<kind>, <group>/<version>/<kind> or ID."`
+	Lang       string `flag:"lang" required:"" enum:"go,typescript" help:"Language to generate: go, typescript."`
+	Package    string `flag:"package" default:"catalog" help:"Package name of generated Go code."`
+}
+
+func (e *EntityDefinitionGenerateCommand) Run() error {
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	defs, err := listEntityDefinitions(context.Background(), client)
+	if err != nil {
+		return err
+	}
+	def, err := findEntityDefinition(defs, e.Definition)
+	if err != nil {
+		return err
+	}
+
+	source := fmt.Sprintf("%s/%s/%s", def.Group, def.Name.Or("v1"), def.Kind)
+	spec := devgraph.CleanDefinitionSpec(def.Spec)
+	switch e.Lang {
+	case "typescript":
+		fmt.Print(generateTypeScript(source, def.Kind+"Spec", spec))
+	default:
+		code, err := generateGo(source, e.Package, def.Kind+"Spec", spec)
+		if err != nil {
+			return err
+		}
+		fmt.Print(code)
+	}
+	return nil
+}
+
+// schemaType is an object schema that gets its own generated type
+type schemaType struct {
+	Name   string
+	Schema map[string]any
+}
+
+// typeGenerator walks a definition spec, naming nested object schemas after
+// the property they are found in
+type typeGenerator struct {
+	queue []schemaType
+	names map[string]bool
+}
+
+func newTypeGenerator(name string, spec map[string]any) *typeGenerator {
+	return &typeGenerator{
+		queue: []schemaType{{Name: name, Schema: spec}},
+		names: map[string]bool{name: true},
+	}
+}
+
+// next returns the next type to generate, if any
+func (g *typeGenerator) next() (schemaType, bool) {
+	if len(g.queue) == 0 {
+		return schemaType{}, false
+	}
+	t := g.queue[0]
+	g.queue = g.queue[1:]
+	return t, true
+}
+
+// nested queues an object schema for generation and returns its type name
+func (g *typeGenerator) nested(name string, schema map[string]any) string {
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	g.names[unique] = true
+	g.queue = append(g.queue, schemaType{Name: unique, Schema: schema})
+	return unique
+}
+
+// schemaProperties returns the properties of an object schema by name and
+// which of them are required
+func schemaProperties(schema map[string]any) ([]string, map[string]map[string]any, map[string]bool) {
+	properties := map[string]map[string]any{}
+	if p, ok := schema["properties"].(map[string]any); ok {
+		for name, property := range p {
+			if s, ok := property.(map[string]any); ok {
+				properties[name] = s
+			}
+		}
+	}
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	required := map[string]bool{}
+	if r, ok := schema["required"].([]any); ok {
+		for _, name := range r {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+	return names, properties, required
+}
+
+// schemaTypeName returns the type of a schema, treating schemas with
+// properties as objects
+func schemaTypeName(schema map[string]any) string {
+	if t, ok := schema["type"].(string); ok {
+		return t
+	}
+	if _, ok := schema["properties"]; ok {
+		return "object"
+	}
+	return ""
+}
+
+// goInitialisms are written in upper case in Go identifiers
+var goInitialisms = map[string]bool{"api": true, "dns": true, "http": true, "https": true, "id": true, "ip": true, "json": true, "sql": true, "ssh": true, "tls": true, "ui": true, "uri": true, "url": true, "uuid": true}
+
+// exportedName converts a property name to an exported identifier
+func exportedName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, word := range words {
+		if goInitialisms[strings.ToLower(word)] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	if b.Len() == 0 || unicode.IsDigit([]rune(b.String())[0]) {
+		return "Field" + b.String()
+	}
+	return b.String()
+}
+
+// writeComment writes a description as a comment with the given prefix
+func writeComment(b *strings.Builder, indent, prefix, description string) {
+	for _, line := range strings.Split(strings.TrimSpace(description), "\n") {
+		fmt.Fprintf(b, "%s%s%s\n", indent, prefix, strings.TrimSpace(line))
+	}
+}
+
+// generateGo generates Go structs for a definition spec
+func generateGo(source, pkg, name string, spec map[string]any) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by dg entity-definition generate from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&b, "package %s\n", pkg)
+
+	g := newTypeGenerator(name, spec)
+	for t, ok := g.next(); ok; t, ok = g.next() {
+		b.WriteString("\n")
+		if description, _ := t.Schema["description"].(string); description != "" {
+			writeComment(&b, "", "// ", t.Name+" is "+lowerFirst(description))
+		} else {
+			fmt.Fprintf(&b, "// %s is generated from %s\n", t.Name, source)
+		}
+		fmt.Fprintf(&b, "type %s struct {\n", t.Name)
+
+		names, properties, required := schemaProperties(t.Schema)
+		for _, prop := range names {
+			schema := properties[prop]
+			field := exportedName(prop)
+			typ := g.goType(t.Name+field, schema)
+			tag := prop
+			if !required[prop] {
+				tag += ",omitempty"
+			}
+			if nullable, _ := schema["nullable"].(bool); (nullable || !required[prop]) && isGoScalar(typ) {
+				typ = "*" + typ
+			}
+			if description, _ := schema["description"].(string); description != "" {
+				writeComment(&b, "\t", "// ", description)
+			}
+			fmt.Fprintf(&b, "\t%s %s `json:\"%s\" yaml:\"%s\"`\n", field, typ, tag, tag)
+		}
+		b.WriteString("}\n")
+	}
+
+	code, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return "", fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return string(code), nil
+}
+
+// goType returns the Go type of a schema, queueing nested structs
+func (g *typeGenerator) goType(name string, schema map[string]any) string {
+	switch schemaTypeName(schema) {
+	case "string":
+		return "string"
+	case "integer":
+		return "int64"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		items, _ := schema["items"].(map[string]any)
+		if items == nil {
+			return "[]any"
+		}
+		return "[]" + g.goType(name, items)
+	case "object":
+		if _, ok := schema["properties"]; ok {
+			return g.nested(name, schema)
+		}
+		if values, ok := schema["additionalProperties"].(map[string]any); ok {
+			return "map[string]" + g.goType(name+"Value", values)
+		}
+		return "map[string]any"
+	default:
+		return "any"
+	}
+}
+
+// isGoScalar reports whether a Go type is a value type, which needs a
+// pointer to tell unset from the zero value
+func isGoScalar(typ string) bool {
+	return !strings.HasPrefix(typ, "[]") && !strings.HasPrefix(typ, "map[") && typ != "any"
+}
+
+// generateTypeScript generates TypeScript interfaces for a definition spec
+func generateTypeScript(source, name string, spec map[string]any) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by dg entity-definition generate from %s. DO NOT EDIT.\n", source)
+
+	g := newTypeGenerator(name, spec)
+	for t, ok := g.next(); ok; t, ok = g.next() {
+		b.WriteString("\n")
+		if description, _ := t.Schema["description"].(string); description != "" {
+			b.WriteString("/**\n")
+			writeComment(&b, "", " * ", description)
+			b.WriteString(" */\n")
+		}
+		fmt.Fprintf(&b, "export interface %s {\n", t.Name)
+
+		names, properties, required := schemaProperties(t.Schema)
+		for _, prop := range names {
+			schema := properties[prop]
+			typ := g.tsType(t.Name+exportedName(prop), schema)
+			if nullable, _ := schema["nullable"].(bool); nullable {
+				typ += " | null"
+			}
+			optional := ""
+			if !required[prop] {
+				optional = "?"
+			}
+			if description, _ := schema["description"].(string); description != "" {
+				fmt.Fprintf(&b, "  /** %s */\n", strings.Join(strings.Fields(description), " "))
+			}
+			fmt.Fprintf(&b, "  %s%s: %s;\n", tsPropertyName(prop), optional, typ)
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+// tsType returns the TypeScript type of a schema, queueing nested interfaces
+func (g *typeGenerator) tsType(name string, schema map[string]any) string {
+	if values, ok := schema["enum"].([]any); ok && len(values) > 0 {
+		literals := make([]string, len(values))
+		for i, v := range values {
+			if s, ok := v.(string); ok {
+				literals[i] = fmt.Sprintf("%q", s)
+			} else {
+				literals[i] = fmt.Sprint(v)
+			}
+		}
+		return strings.Join(literals, " | ")
+	}
+
+	switch schemaTypeName(schema) {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		items, _ := schema["items"].(map[string]any)
+		if items == nil {
+			return "unknown[]"
+		}
+		item := g.tsType(name, items)
+		if strings.Contains(item, " ") {
+			return "Array<" + item + ">"
+		}
+		return item + "[]"
+	case "object":
+		if _, ok := schema["properties"]; ok {
+			return g.nested(name, schema)
+		}
+		if values, ok := schema["additionalProperties"].(map[string]any); ok {
+			return "Record<string, " + g.tsType(name+"Value", values) + ">"
+		}
+		return "Record<string, unknown>"
+	default:
+		return "unknown"
+	}
+}
+
+// tsPropertyName quotes property names that aren't valid identifiers
+func tsPropertyName(name string) string {
+	for i, r := range name {
+		if !(unicode.IsLetter(r) || r == '_' || r == '$' || i > 0 && unicode.IsDigit(r)) {
+			return fmt.Sprintf("%q", name)
+		}
+	}
+	return name
+}
+
+// lowerFirst lower cases the first letter of s
+func lowerFirst(s string) string {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return s
+	}
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
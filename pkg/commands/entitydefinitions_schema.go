@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jsonSchemaOnlyKeywords are JSON Schema keywords that have no equivalent in
+// the OpenAPI schema dialect used by entity definition specs
+var jsonSchemaOnlyKeywords = []string{"$schema", "$id", "$comment", "$defs", "definitions", "examples"}
+
+// schemaToDefinitionSpec converts a JSON Schema document describing an
+// entity's spec into an entity definition spec. Local $refs are inlined,
+// nullable type unions become nullable, and const becomes a single-value enum.
+func schemaToDefinitionSpec(data []byte) (map[string]any, error) {
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON schema: %w", err)
+	}
+
+	defs := map[string]any{}
+	for _, key := range []string{"definitions", "$defs"} {
+		if d, ok := schema[key].(map[string]any); ok {
+			for name, def := range d {
+				defs["#/"+key+"/"+name] = def
+			}
+		}
+	}
+
+	converted, err := convertSchemaNode(schema, defs, nil)
+	if err != nil {
+		return nil, err
+	}
+	spec := converted.(map[string]any)
+
+	switch spec["type"] {
+	case nil:
+		spec["type"] = "object"
+	case "object":
+	default:
+		return nil, fmt.Errorf("schema must describe an object, got type %v", spec["type"])
+	}
+	return spec, nil
+}
+
+// convertSchemaNode converts one schema node, recursing into subschemas.
+// refs holds the $refs being expanded, to reject recursive schemas.
+func convertSchemaNode(node any, defs map[string]any, refs []string) (any, error) {
+	schema, ok := node.(map[string]any)
+	if !ok {
+		return node, nil
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		def, found := defs[ref]
+		if !found {
+			return nil, fmt.Errorf("unsupported $ref %q: only local definitions are supported", ref)
+		}
+		for _, r := range refs {
+			if r == ref {
+				return nil, fmt.Errorf("recursive $ref %q is not supported", ref)
+			}
+		}
+		resolved, err := convertSchemaNode(def, defs, append(refs, ref))
+		if err != nil {
+			return nil, err
+		}
+		// Keywords next to the $ref, like a description, override the target
+		result := resolved.(map[string]any)
+		for key, value := range schema {
+			if key != "$ref" {
+				result[key] = value
+			}
+		}
+		return convertSchemaNode(result, defs, refs)
+	}
+
+	result := make(map[string]any, len(schema))
+	for key, value := range schema {
+		result[key] = value
+	}
+	for _, key := range jsonSchemaOnlyKeywords {
+		delete(result, key)
+	}
+
+	if types, ok := result["type"].([]any); ok {
+		var nonNull []string
+		for _, t := range types {
+			if s, _ := t.(string); s == "null" {
+				result["nullable"] = true
+			} else {
+				nonNull = append(nonNull, fmt.Sprint(t))
+			}
+		}
+		if len(nonNull) != 1 {
+			return nil, fmt.Errorf("type unions are not supported: %v", types)
+		}
+		result["type"] = nonNull[0]
+	}
+
+	if value, ok := result["const"]; ok {
+		result["enum"] = []any{value}
+		delete(result, "const")
+	}
+
+	var err error
+	for _, key := range []string{"properties", "patternProperties"} {
+		if properties, ok := result[key].(map[string]any); ok {
+			converted := make(map[string]any, len(properties))
+			for name, property := range properties {
+				if converted[name], err = convertSchemaNode(property, defs, refs); err != nil {
+					return nil, fmt.Errorf("%s: %w", name, err)
+				}
+			}
+			result[key] = converted
+		}
+	}
+	for _, key := range []string{"items", "additionalProperties", "not"} {
+		if sub, ok := result[key].(map[string]any); ok {
+			if result[key], err = convertSchemaNode(sub, defs, refs); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		if subs, ok := result[key].([]any); ok {
+			converted := make([]any, len(subs))
+			for i, sub := range subs {
+				if converted[i], err = convertSchemaNode(sub, defs, refs); err != nil {
+					return nil, err
+				}
+			}
+			result[key] = converted
+		}
+	}
+	return result, nil
+}
+
+// definitionNames fills in the names of a definition derived from its kind
+func definitionNames(def *FilteredEntityDefinition) {
+	if def.Singular == "" {
+		def.Singular = strings.ToLower(def.Kind)
+	}
+	if def.Plural == "" {
+		def.Plural = def.Singular + "s"
+	}
+	if def.ListKind == "" {
+		def.ListKind = def.Kind + "List"
+	}
+}
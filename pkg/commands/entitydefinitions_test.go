@@ -0,0 +1,590 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// testDefinition returns an entity definition as returned by the API
+func testDefinition(group, kind string, spec map[string]any) map[string]any {
+	return map[string]any{
+		"id":          "22222222-2222-2222-2222-222222222222",
+		"group":       group,
+		"kind":        kind,
+		"list_kind":   kind + "List",
+		"singular":    "service",
+		"plural":      "services",
+		"name":        "v1",
+		"description": "A service",
+		"spec":        spec,
+		"storage":     true,
+		"served":      true,
+	}
+}
+
+func TestEntityDefinitionCreateCommand_FromSchema(t *testing.T) {
+	schema := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"description": "A deployable service",
+		"type": "object",
+		"required": ["owner"],
+		"properties": {
+			"owner": {"$ref": "#/$defs/team", "description": "Owning team"},
+			"tier": {"type": ["integer", "null"]},
+			"lifecycle": {"const": "production"},
+			"tags": {"type": "array", "items": {"$ref": "#/$defs/team"}}
+		},
+		"$defs": {"team": {"type": "string", "minLength": 1}}
+	}`
+	path := filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, os.WriteFile(path, []byte(schema), 0600))
+
+	srv, cfg := newTestAPI(t)
+	srv.handle("POST /api/v1/entities/definitions", http.StatusCreated, testDefinition("apps", "Service", map[string]any{"type": "object"}))
+
+	cmd := EntityDefinitionCreateCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		FromSchema:        path,
+		Group:             "apps",
+		Kind:              "Service",
+		Version:           "v1",
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Entity definition 'apps/Service' created successfully")
+
+	body := srv.requireRequest(http.MethodPost, "/api/v1/entities/definitions").JSON(t)
+	assert.Equal(t, "apps", body["group"])
+	assert.Equal(t, "Service", body["kind"])
+	assert.Equal(t, "ServiceList", body["list_kind"])
+	assert.Equal(t, "service", body["singular"])
+	assert.Equal(t, "services", body["plural"])
+	assert.Equal(t, "v1", body["name"])
+	assert.Equal(t, "A deployable service", body["description"])
+	assert.Equal(t, map[string]any{
+		"description": "A deployable service",
+		"type":        "object",
+		"required":    []any{"owner"},
+		"properties": map[string]any{
+			"owner":     map[string]any{"type": "string", "minLength": float64(1), "description": "Owning team"},
+			"tier":      map[string]any{"type": "integer", "nullable": true},
+			"lifecycle": map[string]any{"enum": []any{"production"}},
+			"tags":      map[string]any{"type": "array", "items": map[string]any{"type": "string", "minLength": float64(1)}},
+		},
+	}, body["spec"])
+}
+
+func TestEntityDefinitionCreateCommand_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "service.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("group: apps\nkind: Service\nspec:\n  type: object\n"), 0600))
+
+	srv, cfg := newTestAPI(t)
+	srv.handle("POST /api/v1/entities/definitions", http.StatusCreated, testDefinition("apps", "Service", map[string]any{"type": "object"}))
+
+	cmd := EntityDefinitionCreateCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, FileName: path}
+	_, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	body := srv.requireRequest(http.MethodPost, "/api/v1/entities/definitions").JSON(t)
+	assert.Equal(t, "ServiceList", body["list_kind"])
+	assert.Equal(t, map[string]any{"type": "object"}, body["spec"])
+}
+
+func TestEntityDefinitionCreateCommand_Stdin(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("POST /api/v1/entities/definitions", http.StatusCreated, testDefinition("apps", "Service", map[string]any{"type": "object"}))
+	withStdin(t, "group: apps\nkind: Service\nspec:\n  type: object\n")
+
+	cmd := EntityDefinitionCreateCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, FileName: "-"}
+	_, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	body := srv.requireRequest(http.MethodPost, "/api/v1/entities/definitions").JSON(t)
+	assert.Equal(t, "apps", body["group"])
+	assert.Equal(t, "Service", body["kind"])
+}
+
+func TestSchemaToDefinitionSpec_Errors(t *testing.T) {
+	tests := map[string]string{
+		`{"type": "string"}`: "must describe an object",
+		`{"properties": {"a": {"$ref": "https://example.com/a.json"}}}`:                                  "only local definitions",
+		`{"properties": {"a": {"$ref": "#/$defs/a"}}, "$defs": {"a": {"items": {"$ref": "#/$defs/a"}}}}`: "recursive $ref",
+		`{"properties": {"a": {"type": ["string", "integer"]}}}`:                                         "type unions are not supported",
+	}
+	for schema, want := range tests {
+		_, err := schemaToDefinitionSpec([]byte(schema))
+		assert.ErrorContains(t, err, want, schema)
+	}
+}
+
+// testServiceSpec is a definition spec exercising nested types
+var testServiceSpec = map[string]any{
+	"type":     "object",
+	"required": []any{"owner"},
+	"properties": map[string]any{
+		"owner":     map[string]any{"type": "string", "description": "Owning team"},
+		"replicas":  map[string]any{"type": "integer"},
+		"repo-url":  map[string]any{"type": "string", "nullable": true},
+		"lifecycle": map[string]any{"type": "string", "enum": []any{"experimental", "production"}},
+		"ports": map[string]any{"type": "array", "items": map[string]any{
+			"type":       "object",
+			"required":   []any{"port"},
+			"properties": map[string]any{"port": map[string]any{"type": "integer"}, "name": map[string]any{"type": "string"}},
+		}},
+		"labels": map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+	},
+}
+
+func TestEntityDefinitionGenerateCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/definitions", http.StatusOK, []map[string]any{testDefinition("apps", "Service", testServiceSpec)})
+
+	cmd := EntityDefinitionGenerateCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Definition: "apps/Service", Lang: "go", Package: "catalog"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	srv.requireRequest(http.MethodGet, "/api/v1/entities/definitions")
+
+	assert.Equal(t, "// Code generated by dg entity-definition generate from apps/v1/Service. DO NOT EDIT.\n"+
+		"\n"+
+		"package catalog\n"+
+		"\n"+
+		"// ServiceSpec is generated from apps/v1/Service\n"+
+		"type ServiceSpec struct {\n"+
+		"\tLabels    map[string]string `json:\"labels,omitempty\" yaml:\"labels,omitempty\"`\n"+
+		"\tLifecycle *string           `json:\"lifecycle,omitempty\" yaml:\"lifecycle,omitempty\"`\n"+
+		"\t// Owning team\n"+
+		"\tOwner    string             `json:\"owner\" yaml:\"owner\"`\n"+
+		"\tPorts    []ServiceSpecPorts `json:\"ports,omitempty\" yaml:\"ports,omitempty\"`\n"+
+		"\tReplicas *int64             `json:\"replicas,omitempty\" yaml:\"replicas,omitempty\"`\n"+
+		"\tRepoURL  *string            `json:\"repo-url,omitempty\" yaml:\"repo-url,omitempty\"`\n"+
+		"}\n"+
+		"\n"+
+		"// ServiceSpecPorts is generated from apps/v1/Service\n"+
+		"type ServiceSpecPorts struct {\n"+
+		"\tName *string `json:\"name,omitempty\" yaml:\"name,omitempty\"`\n"+
+		"\tPort int64   `json:\"port\" yaml:\"port\"`\n"+
+		"}\n", output)
+
+	cmd.Lang = "typescript"
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "export interface ServiceSpec {")
+	assert.Contains(t, output, "  /** Owning team */\n  owner: string;")
+	assert.Contains(t, output, "  replicas?: number;")
+	assert.Contains(t, output, "  \"repo-url\"?: string | null;")
+	assert.Contains(t, output, "  lifecycle?: \"experimental\" | \"production\";")
+	assert.Contains(t, output, "  ports?: ServiceSpecPorts[];")
+	assert.Contains(t, output, "  labels?: Record<string, string>;")
+	assert.Contains(t, output, "export interface ServiceSpecPorts {\n  name?: string;\n  port: number;\n}")
+}
+
+func TestFindEntityDefinition(t *testing.T) {
+	v1 := api.EntityDefinitionResponse{ID: uuid.New(), Group: "apps", Kind: "Service", Name: api.NewOptString("v1"), Storage: api.NewOptBool(false)}
+	v2 := api.EntityDefinitionResponse{ID: uuid.New(), Group: "apps", Kind: "Service", Name: api.NewOptString("v2"), Storage: api.NewOptBool(true)}
+	defs := []api.EntityDefinitionResponse{v1, v2}
+
+	def, err := findEntityDefinition(defs, "apps/Service")
+	require.NoError(t, err)
+	assert.Equal(t, v2.ID, def.ID)
+
+	def, err = findEntityDefinition(defs, "apps/v1/service")
+	require.NoError(t, err)
+	assert.Equal(t, v1.ID, def.ID)
+
+	def, err = findEntityDefinition(defs, v1.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, v1.ID, def.ID)
+
+	_, err = findEntityDefinition(defs, "apps/Team")
+	assert.ErrorContains(t, err, "not found")
+	_, err = findEntityDefinition(defs, "Service")
+	assert.ErrorContains(t, err, "invalid entity definition")
+}
+
+func TestEntityDefinitionValidateCommand(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yaml")
+	require.NoError(t, os.WriteFile(valid, []byte(`group: apps
+kind: Team
+listKind: TeamList
+singular: team
+plural: teams
+spec:
+  type: object
+  properties:
+    members: {type: array, items: {type: string}}
+`), 0600))
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte(`group: Apps
+kind: Service
+singular: service
+plural: services
+spec:
+  type: object
+  required: [owner, tier]
+  properties:
+    owner: {type: text}
+    tags: {type: array}
+`), 0600))
+	unknown := filepath.Join(dir, "unknown.yaml")
+	require.NoError(t, os.WriteFile(unknown, []byte("group: apps\nkind: Job\nlist_kind: JobList\n"), 0600))
+
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/definitions", http.StatusOK, []map[string]any{testDefinition("apps", "Service", testServiceSpec)})
+
+	cmd := EntityDefinitionValidateCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Files: []string{valid}}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "1 definition file(s) are valid")
+	srv.requireRequest(http.MethodGet, "/api/v1/entities/definitions")
+
+	cmd.Files = []string{invalid, unknown}
+	cmd.Output = "json"
+	output, err = captureOutput(t, cmd.Run)
+	assert.ErrorContains(t, err, "found 4 error(s)")
+
+	var problems []definitionProblem
+	require.NoError(t, json.Unmarshal([]byte(output), &problems), output)
+	assert.Equal(t, []definitionProblem{
+		{File: invalid, Level: "error", Field: "group", Message: "group 'Apps' must be lowercase alphanumerics, '-' and '.'"},
+		{File: invalid, Level: "warning", Field: "listKind", Message: "listKind is not set; create will use 'ServiceList'"},
+		{File: invalid, Level: "error", Field: "spec.properties.owner.type", Message: "unknown type text"},
+		{File: invalid, Level: "warning", Field: "spec.properties.tags.items", Message: "array has no items schema"},
+		{File: invalid, Level: "error", Field: "spec.required", Message: "required property 'tier' is not defined"},
+		{File: unknown, Level: "error", Message: problems[5].Message},
+	}, problems)
+	assert.Contains(t, problems[5].Message, "field list_kind not found")
+
+	// The server already has apps/v1/Service
+	cmd.Files = []string{invalid}
+	require.NoError(t, os.WriteFile(invalid, []byte("group: apps\nkind: Service\nlistKind: ServiceList\nsingular: service\nplural: services\nspec: {type: object}\n"), 0600))
+	output, err = captureOutput(t, cmd.Run)
+	assert.ErrorContains(t, err, "found 1 error(s)")
+	assert.Contains(t, output, "apps/v1/Service already exists")
+
+	cmd.Offline = true
+	_, err = captureOutput(t, cmd.Run)
+	assert.NoError(t, err)
+}
+
+func TestEntityDefinitionDiffCommand(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "service.yaml"), []byte(`group: apps
+kind: Service
+description: A service
+spec:
+  type: object
+  required: [owner]
+  properties:
+    owner: {type: string, description: Owning team}
+    replicas: {type: number}
+    tier: {type: integer}
+`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "team.json"), []byte(`{"group": "apps", "kind": "Team", "spec": {"type": "object"}}`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a definition"), 0600))
+
+	srv, cfg := newTestAPI(t)
+	remote := testDefinition("apps", "Service", map[string]any{
+		"type":     "object",
+		"required": []any{"owner"},
+		"properties": map[string]any{
+			"owner":    map[string]any{"type": "string", "description": "Owning team"},
+			"replicas": map[string]any{"type": "integer"},
+			"repo":     map[string]any{"type": "string"},
+		},
+	})
+	srv.handle("GET /api/v1/entities/definitions", http.StatusOK, []map[string]any{remote})
+
+	cmd := EntityDefinitionDiffCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Path: dir}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	srv.requireRequest(http.MethodGet, "/api/v1/entities/definitions")
+
+	assert.Contains(t, output, "~ apps/v1/Service ("+filepath.Join(dir, "service.yaml")+"): 3 field(s) differ\n"+
+		"    ~ spec.properties.replicas.type: integer -> number\n"+
+		"    - spec.properties.repo.type: string\n"+
+		"    + spec.properties.tier.type: integer\n")
+	assert.Contains(t, output, "+ apps/v1/Team ("+filepath.Join(dir, "team.json")+"): not on the server")
+	assert.Contains(t, output, "2 of 2 definition(s) differ from the server")
+
+	changes, err := diffEntityDefinitions(devgraph.NewEntityDefinition(mustDefinition(t, remote)), devgraph.NewEntityDefinition(mustDefinition(t, remote)))
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+// mustDefinition decodes an API definition fixture
+func mustDefinition(t *testing.T, fixture map[string]any) api.EntityDefinitionResponse {
+	t.Helper()
+	data, err := json.Marshal(fixture)
+	require.NoError(t, err)
+	var def api.EntityDefinitionResponse
+	require.NoError(t, json.Unmarshal(data, &def))
+	return def
+}
+
+func TestEntityDefinitionVersionsCommand(t *testing.T) {
+	v1 := testDefinition("apps", "Service", map[string]any{"type": "object"})
+	v1["storage"] = false
+	v2 := testDefinition("apps", "Service", map[string]any{"type": "object"})
+	v2["id"] = "33333333-3333-3333-3333-333333333333"
+	v2["name"] = "v2"
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/definitions", http.StatusOK, []map[string]any{v2, testDefinition("apps", "Team", map[string]any{}), v1})
+
+	cfg.Output = "json"
+	cmd := EntityDefinitionVersionsCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Definition: "apps/service"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	srv.requireRequest(http.MethodGet, "/api/v1/entities/definitions")
+
+	var versions []definitionVersion
+	require.NoError(t, json.Unmarshal([]byte(output), &versions), output)
+	assert.Equal(t, []definitionVersion{
+		{ID: "22222222-2222-2222-2222-222222222222", Version: "v1", Served: true, Storage: false, Description: "A service"},
+		{ID: "33333333-3333-3333-3333-333333333333", Version: "v2", Served: true, Storage: true, Description: "A service"},
+	}, versions)
+
+	cmd.Definition = "apps/Job"
+	_, err = captureOutput(t, cmd.Run)
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestEntityDefinitionExampleCommand(t *testing.T) {
+	spec := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"owner":     map[string]any{"type": "string"},
+			"replicas":  map[string]any{"type": "integer", "default": 3},
+			"homepage":  map[string]any{"type": "string", "format": "uri"},
+			"lifecycle": map[string]any{"type": "string", "enum": []any{"experimental", "production"}},
+			"ports":     map[string]any{"type": "array", "items": map[string]any{"type": "object", "properties": map[string]any{"port": map[string]any{"type": "integer", "minimum": 1}}}},
+			"labels":    map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+			"critical":  map[string]any{"type": "boolean"},
+		},
+	}
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/definitions", http.StatusOK, []map[string]any{testDefinition("apps", "Service", spec)})
+
+	cfg.Output = "json"
+	cmd := EntityDefinitionExampleCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Definition: "apps/Service", Namespace: "default"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	srv.requireRequest(http.MethodGet, "/api/v1/entities/definitions")
+
+	assert.JSONEq(t, `{
+		"apiVersion": "apps/v1",
+		"kind": "Service",
+		"metadata": {"name": "example-service", "namespace": "default"},
+		"spec": {
+			"owner": "<owner>",
+			"replicas": 3,
+			"homepage": "https://example.com",
+			"lifecycle": "experimental",
+			"ports": [{"port": 1}],
+			"labels": {"key": "<value>"},
+			"critical": false
+		}
+	}`, output)
+
+	// The example can be passed to entity create as is
+	var entity api.Entity
+	require.NoError(t, json.Unmarshal([]byte(output), &entity))
+	assert.Equal(t, "example-service", entity.Metadata.Name)
+}
+
+func TestEntityDefinitionExportCommand_CRD(t *testing.T) {
+	v1 := testDefinition("apps.example.com", "Service", map[string]any{"type": "object"})
+	v1["storage"] = false
+	v2 := testDefinition("apps.example.com", "Service", testServiceSpec)
+	v2["name"] = "v2"
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/definitions", http.StatusOK, []map[string]any{v2, v1})
+
+	cfg.Output = "yaml"
+	cmd := EntityDefinitionExportCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Definition: "apps.example.com/Service", Format: "crd"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	srv.requireRequest(http.MethodGet, "/api/v1/entities/definitions")
+
+	var crd map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(output), &crd), output)
+	assert.Equal(t, "apiextensions.k8s.io/v1", crd["apiVersion"])
+	assert.Equal(t, "CustomResourceDefinition", crd["kind"])
+	assert.Equal(t, map[string]any{"name": "services.apps.example.com"}, crd["metadata"])
+
+	spec := crd["spec"].(map[string]any)
+	assert.Equal(t, "apps.example.com", spec["group"])
+	assert.Equal(t, map[string]any{"kind": "Service", "listKind": "ServiceList", "plural": "services", "singular": "service"}, spec["names"])
+	assert.Equal(t, "Namespaced", spec["scope"])
+
+	versions := spec["versions"].([]any)
+	require.Len(t, versions, 2)
+	first := versions[0].(map[string]any)
+	assert.Equal(t, "v1", first["name"])
+	assert.Equal(t, false, first["storage"])
+	second := versions[1].(map[string]any)
+	assert.Equal(t, "v2", second["name"])
+	assert.Equal(t, true, second["storage"])
+	schema := second["schema"].(map[string]any)["openAPIV3Schema"].(map[string]any)
+	assert.Equal(t, "A service", schema["description"])
+	assert.Equal(t, "object", schema["properties"].(map[string]any)["spec"].(map[string]any)["type"])
+}
+
+func TestEntityDefinitionExportCommand_OpenAPI(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/definitions", http.StatusOK, []map[string]any{testDefinition("apps", "Service", testServiceSpec)})
+
+	cfg.Output = "json"
+	cmd := EntityDefinitionExportCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Definition: "apps/v1/Service", Format: "openapi"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	var document map[string]any
+	require.NoError(t, json.Unmarshal([]byte(output), &document), output)
+	assert.Equal(t, "3.0.3", document["openapi"])
+	assert.Equal(t, map[string]any{"title": "apps/Service", "version": "v1"}, document["info"])
+
+	schemas := document["components"].(map[string]any)["schemas"].(map[string]any)
+	assert.Len(t, schemas, 2)
+	entity := schemas["Service"].(map[string]any)
+	properties := entity["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "string", "enum": []any{"apps/v1"}}, properties["apiVersion"])
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/ServiceSpec"}, properties["spec"])
+	assert.Equal(t, []any{"owner"}, schemas["ServiceSpec"].(map[string]any)["required"])
+}
+
+func TestEntityDefinitionApplyCommand(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "service.yaml"), []byte("group: apps\nkind: Service\ndescription: A service\nspec: {type: object}\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "team.yaml"), []byte("group: apps\nkind: Team\nspec: {type: object}\n"), 0600))
+
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/definitions", http.StatusOK, []map[string]any{testDefinition("apps", "Service", map[string]any{"type": "object"})})
+	srv.handle("POST /api/v1/entities/definitions", http.StatusCreated, testDefinition("apps", "Team", map[string]any{"type": "object"}))
+
+	cmd := EntityDefinitionApplyCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Path: dir}
+	cmd.DryRun = true
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "  apps/v1/Service unchanged")
+	assert.Contains(t, output, "Would create apps/v1/Team")
+	assert.Empty(t, srv.received(http.MethodPost, "/api/v1/entities/definitions"))
+
+	cmd.DryRun = false
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "✅ Created apps/v1/Team")
+	assert.Contains(t, output, "Created 1, unchanged 1, changed 0, failed 0")
+	body := srv.requireRequest(http.MethodPost, "/api/v1/entities/definitions").JSON(t)
+	assert.Equal(t, "Team", body["kind"])
+	assert.Equal(t, "teams", body["plural"])
+
+	// A changed definition is reported, not recreated
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "service.yaml"), []byte("group: apps\nkind: Service\ndescription: A changed service\nspec: {type: object}\n"), 0600))
+	require.NoError(t, os.Remove(filepath.Join(dir, "team.yaml")))
+	logs := captureLogs(t)
+	_, err = captureOutput(t, cmd.Run)
+	assert.ErrorContains(t, err, "1 definition(s) could not be applied")
+	assert.Contains(t, logs.String(), "Warning: apps/v1/Service differs from the server in 1 field(s)")
+	assert.Len(t, srv.received(http.MethodPost, "/api/v1/entities/definitions"), 1)
+}
+
+func TestEntityDefinitionImportCRDCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crds.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+  annotations:
+    controller-gen.kubebuilder.io/version: v0.14.0
+spec:
+  group: example.com
+  names:
+    kind: Widget
+    listKind: WidgetList
+    plural: widgets
+    singular: widget
+    shortNames: [wd]
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        description: A widget
+        type: object
+        properties:
+          apiVersion: {type: string}
+          spec:
+            type: object
+            required: [size]
+            properties:
+              size: {type: integer}
+          status: {type: object}
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: apiextensions.k8s.io/v1
+  kind: CustomResourceDefinition
+  metadata:
+    name: gadgets.example.com
+  spec:
+    group: example.com
+    names: {kind: Gadget, plural: gadgets}
+    versions:
+    - name: v1alpha1
+      served: true
+      storage: true
+      schema:
+        openAPIV3Schema: {type: object}
+`), 0600))
+
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/definitions", http.StatusOK, []map[string]any{})
+	srv.handle("POST /api/v1/entities/definitions", http.StatusCreated, testDefinition("example.com", "Widget", map[string]any{"type": "object"}))
+
+	cmd := EntityDefinitionImportCRDCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Source: path}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "✅ Created example.com/v1/Widget")
+	assert.Contains(t, output, "✅ Created example.com/v1alpha1/Gadget")
+
+	requests := srv.received(http.MethodPost, "/api/v1/entities/definitions")
+	require.Len(t, requests, 2)
+	widget := requests[0].JSON(t)
+	assert.Equal(t, "example.com", widget["group"])
+	assert.Equal(t, "Widget", widget["kind"])
+	assert.Equal(t, "WidgetList", widget["list_kind"])
+	assert.Equal(t, "widget", widget["singular"])
+	assert.Equal(t, "widgets", widget["plural"])
+	assert.Equal(t, "v1", widget["name"])
+	assert.Equal(t, "A widget", widget["description"])
+	assert.Equal(t, map[string]any{
+		"type":       "object",
+		"required":   []any{"size"},
+		"properties": map[string]any{"size": map[string]any{"type": "integer"}},
+	}, widget["spec"])
+
+	gadget := requests[1].JSON(t)
+	assert.Equal(t, "GadgetList", gadget["list_kind"])
+	assert.Equal(t, "gadget", gadget["singular"])
+	assert.Equal(t, map[string]any{"type": "object"}, gadget["spec"])
+
+	cmd.Name = []string{"gadgets.example.com"}
+	cmd.DryRun = true
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Equal(t, "Would create example.com/v1alpha1/Gadget\n\nWould create 1, unchanged 0, changed 0, failed 0\n", output)
+}
@@ -0,0 +1,252 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/arctir/devgraph-cli/pkg/output"
+	"github.com/arctir/devgraph-cli/pkg/util"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+)
+
+// EntityDefinitionValidateCommand checks definition files before they're
+// created
+type EntityDefinitionValidateCommand struct {
+	EnvWrapperCommand
+	Files []string `arg:"" required:"" help:"Entity definition JSON or YAML files to validate."`
+}
+
+// definitionProblem is an issue found in a definition file
+type definitionProblem struct {
+	File    string `json:"file" yaml:"file"`
+	Level   string `json:"level" yaml:"level"`
+	Field   string `json:"field,omitempty" yaml:"field,omitempty"`
+	Message string `json:"message" yaml:"message"`
+}
+
+var (
+	definitionGroupPattern   = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	definitionKindPattern    = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+	definitionNamePattern    = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+	definitionVersionPattern = regexp.MustCompile(`^v[0-9]+((alpha|beta)[0-9]+)?$`)
+	schemaTypes              = map[string]bool{"object": true, "array": true, "string": true, "integer": true, "number": true, "boolean": true}
+)
+
+func (e *EntityDefinitionValidateCommand) Run() error {
+	format := e.OutputFormat(output.Table)
+	if err := output.Check(format, output.Table, output.JSON, output.YAML); err != nil {
+		return err
+	}
+
+	var existing []api.EntityDefinitionResponse
+	if !e.Offline {
+		client, err := util.GetAuthenticatedClient(e.Config)
+		if err != nil {
+			return fmt.Errorf("failed to create authenticated client: %w", err)
+		}
+		existing, err = listEntityDefinitions(context.Background(), client)
+		if err != nil {
+			return err
+		}
+	}
+
+	var problems []definitionProblem
+	for _, file := range e.Files {
+		def, err := parseEntityDefinitionFile(file, true)
+		if err != nil {
+			problems = append(problems, definitionProblem{File: file, Level: "error", Message: err.Error()})
+			continue
+		}
+		for _, p := range validateEntityDefinition(def, existing, !e.Offline) {
+			p.File = file
+			problems = append(problems, p)
+		}
+	}
+
+	errCount := 0
+	for _, p := range problems {
+		if p.Level == "error" {
+			errCount++
+		}
+	}
+
+	if format != output.Table {
+		if problems == nil {
+			problems = []definitionProblem{}
+		}
+		if err := output.Print(format, output.Result{Data: problems}); err != nil {
+			return err
+		}
+	} else if len(problems) == 0 {
+		fmt.Printf("✅ %d definition file(s) are valid.\n", len(e.Files))
+	} else {
+		tableData := make([]map[string]any, len(problems))
+		for i, p := range problems {
+			tableData[i] = map[string]any{"File": p.File, "Level": p.Level, "Field": p.Field, "Message": p.Message}
+		}
+		output.PrintTable([]string{"File", "Level", "Field", "Message"}, tableData)
+	}
+
+	if errCount > 0 {
+		return fmt.Errorf("found %d error(s) in entity definitions", errCount)
+	}
+	return nil
+}
+
+// validateEntityDefinition checks a definition's names and spec, and when
+// checkExisting is set, whether it clashes with existing definitions
+func validateEntityDefinition(def FilteredEntityDefinition, existing []api.EntityDefinitionResponse, checkExisting bool) []definitionProblem {
+	var problems []definitionProblem
+	add := func(level, field, format string, args ...any) {
+		problems = append(problems, definitionProblem{Level: level, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case def.Group == "":
+		add("error", "group", "group is required")
+	case !definitionGroupPattern.MatchString(def.Group):
+		add("error", "group", "group '%s' must be lowercase alphanumerics, '-' and '.'", def.Group)
+	}
+	switch {
+	case def.Kind == "":
+		add("error", "kind", "kind is required")
+	case !definitionKindPattern.MatchString(def.Kind):
+		add("error", "kind", "kind '%s' must be alphanumeric and start with an upper case letter", def.Kind)
+	}
+
+	derived := def
+	definitionNames(&derived)
+	for _, name := range []struct{ field, value, derived string }{
+		{"singular", def.Singular, derived.Singular},
+		{"plural", def.Plural, derived.Plural},
+		{"listKind", def.ListKind, derived.ListKind},
+	} {
+		switch {
+		case name.value == "":
+			add("warning", name.field, "%s is not set; create will use '%s'", name.field, name.derived)
+		case name.field != "listKind" && !definitionNamePattern.MatchString(name.value):
+			add("error", name.field, "%s '%s' must be lowercase alphanumerics and '-'", name.field, name.value)
+		}
+	}
+	if def.Singular != "" && def.Singular == def.Plural {
+		add("warning", "plural", "plural is the same as singular")
+	}
+	if def.Name != "" && !definitionVersionPattern.MatchString(def.Name) {
+		add("warning", "name", "version '%s' doesn't look like v1, v2beta1, ...", def.Name)
+	}
+
+	if def.Spec == nil {
+		add("error", "spec", "spec is required")
+	} else if spec, ok := def.Spec.(map[string]any); !ok {
+		add("error", "spec", "spec must be an object schema")
+	} else {
+		if t := schemaTypeName(spec); t != "object" && t != "" {
+			add("error", "spec.type", "spec must describe an object, got type '%s'", t)
+		}
+		for _, p := range validateSchema("spec", spec) {
+			add(p.Level, p.Field, "%s", p.Message)
+		}
+	}
+
+	if !checkExisting {
+		return problems
+	}
+	version := derived.Name
+	if version == "" {
+		version = "v1"
+	}
+	for _, other := range existing {
+		if other.Group != def.Group {
+			continue
+		}
+		if other.Kind == def.Kind {
+			if other.Name.Or("v1") == version {
+				add("error", "kind", "%s/%s/%s already exists (ID %s)", def.Group, version, def.Kind, other.ID)
+			}
+			continue
+		}
+		if plural := other.Plural.Or(""); plural != "" && plural == derived.Plural {
+			add("error", "plural", "plural '%s' is already used by %s/%s", plural, other.Group, other.Kind)
+		}
+	}
+	return problems
+}
+
+// validateSchema checks the structure of a definition spec schema
+func validateSchema(path string, schema map[string]any) []definitionProblem {
+	var problems []definitionProblem
+	add := func(level, field, format string, args ...any) {
+		problems = append(problems, definitionProblem{Level: level, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if t, ok := schema["type"]; ok {
+		if s, _ := t.(string); !schemaTypes[s] {
+			add("error", path+".type", "unknown type %v", t)
+		}
+	}
+
+	properties := map[string]any{}
+	if p, ok := schema["properties"]; ok {
+		if properties, ok = p.(map[string]any); !ok {
+			add("error", path+".properties", "properties must be an object")
+		}
+	}
+	for _, name := range sortedPropertyNames(properties) {
+		property, ok := properties[name].(map[string]any)
+		if !ok {
+			add("error", path+".properties."+name, "property must be a schema object")
+			continue
+		}
+		problems = append(problems, validateSchema(path+".properties."+name, property)...)
+	}
+
+	if r, ok := schema["required"]; ok {
+		names, ok := r.([]any)
+		if !ok {
+			add("error", path+".required", "required must be a list of property names")
+		}
+		for _, name := range names {
+			s, ok := name.(string)
+			if !ok {
+				add("error", path+".required", "required must be a list of property names")
+				continue
+			}
+			if _, found := properties[s]; !found {
+				add("error", path+".required", "required property '%s' is not defined", s)
+			}
+		}
+	}
+
+	if e, ok := schema["enum"]; ok {
+		if values, ok := e.([]any); !ok || len(values) == 0 {
+			add("error", path+".enum", "enum must be a non-empty list")
+		}
+	}
+
+	if items, ok := schema["items"]; ok {
+		if sub, ok := items.(map[string]any); ok {
+			problems = append(problems, validateSchema(path+".items", sub)...)
+		} else {
+			add("error", path+".items", "items must be a schema object")
+		}
+	} else if schemaTypeName(schema) == "array" {
+		add("warning", path+".items", "array has no items schema")
+	}
+
+	if values, ok := schema["additionalProperties"].(map[string]any); ok {
+		problems = append(problems, validateSchema(path+".additionalProperties", values)...)
+	}
+	return problems
+}
+
+// sortedPropertyNames returns the names of schema properties in order
+func sortedPropertyNames(properties map[string]any) []string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,17 +1,34 @@
 package commands
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
+	"github.com/arctir/devgraph-cli/pkg/logging"
+	"github.com/arctir/devgraph-cli/pkg/output"
 	"github.com/arctir/devgraph-cli/pkg/util"
 	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
 	"github.com/google/uuid"
 )
 
-// getDefaultEnvironment returns the default environment UUID from user settings
-func getDefaultEnvironment() (string, error) {
+// getDefaultEnvironment returns the environment UUID to use: the --env
+// override if one was given, otherwise the default from user settings
+func getDefaultEnvironment(cfg config.Config) (string, error) {
+	if cfg.EnvOverride != "" {
+		return cfg.EnvOverride, nil
+	}
 	userConfig, err := config.LoadUserConfig()
 	if err != nil {
 		return "", fmt.Errorf("failed to load user config: %w", err)
@@ -22,21 +39,36 @@ func getDefaultEnvironment() (string, error) {
 	return userConfig.Settings.DefaultEnvironment, nil
 }
 
+// defaultEnvironmentUUID returns the UUID of the environment targeted by cfg
+func defaultEnvironmentUUID(cfg config.Config) (uuid.UUID, error) {
+	environment, err := getDefaultEnvironment(cfg)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	envUUID, err := uuid.Parse(environment)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("invalid environment UUID: %w", err)
+	}
+	return envUUID, nil
+}
+
 type EnvironmentListCommand struct {
 	config.Config
-	Output string `short:"o" help:"Output format: table, json, yaml" default:"table"`
 }
 
 type EnvironmentUserListCommand struct {
 	EnvWrapperCommand
 	Invited bool   `short:"i" help:"Show only pending invitations"`
-	Output  string `short:"o" help:"Output format: table, json, yaml" default:"table"`
+	Role    string `help:"Only show users with this role (e.g. admin, member)"`
+	Status  string `help:"Only show users with this status"`
 }
 
 type EnvironmentUserAddCommand struct {
 	EnvWrapperCommand
-	Email string `arg:"" required:"" help:"Email address of user to invite"`
-	Role  string `short:"r" help:"Role for the user" default:"member"`
+	Email         string `arg:"" optional:"" help:"Email address of user to invite"`
+	Role          string `short:"r" help:"Role for the user (default role for --from-file rows without one)" default:"member"`
+	FromFile      string `flag:"from-file,f" help:"CSV file of users to invite, one 'email,role' per line, or - to read it from stdin"`
+	EnforceLimits bool   `flag:"enforce-limits" help:"Abort instead of warning when --from-file would exceed the plan's seat quota"`
 }
 
 type EnvironmentUserRemoveCommand struct {
@@ -44,7 +76,52 @@ type EnvironmentUserRemoveCommand struct {
 	UserID string `arg:"" required:"" help:"User ID to remove"`
 }
 
-type EnvironmentCurrentCommand struct{}
+// EnvironmentUserUpdateRoleCommand changes the role of a member of the
+// current environment
+type EnvironmentUserUpdateRoleCommand struct {
+	EnvWrapperCommand
+	User string `arg:"" required:"" help:"User ID or email address"`
+	Role string `arg:"" required:"" help:"New role for the user (member or admin)"`
+}
+
+// EnvironmentUserGetCommand shows a single member or pending invitation
+type EnvironmentUserGetCommand struct {
+	EnvWrapperCommand
+	User string `arg:"" required:"" help:"User ID or email address"`
+}
+
+// EnvironmentUserAuditCommand exports when current members joined and
+// pending invitations were sent
+type EnvironmentUserAuditCommand struct {
+	EnvWrapperCommand
+	Format string    `flag:"format" enum:"csv,json" default:"csv" help:"Export format: csv, json"`
+	File   string    `flag:"file,f" help:"Write the export to a file instead of stdout"`
+	Since  time.Time `flag:"since" optional:"" format:"2006-01-02" help:"Only include events on or after this date (YYYY-MM-DD)"`
+}
+
+// EnvironmentInviteResendCommand re-sends a pending invitation by replacing
+// it with a new one
+type EnvironmentInviteResendCommand struct {
+	EnvWrapperCommand
+	InvitationID string `arg:"" required:"" help:"ID of the pending invitation"`
+}
+
+// EnvironmentInviteRevokeCommand cancels a pending invitation
+type EnvironmentInviteRevokeCommand struct {
+	EnvWrapperCommand
+	InvitationID string `arg:"" required:"" help:"ID of the pending invitation"`
+}
+
+// EnvironmentInviteCommand manages pending invitations to the current
+// environment
+type EnvironmentInviteCommand struct {
+	Resend EnvironmentInviteResendCommand `cmd:"resend" help:"Resend a pending invitation"`
+	Revoke EnvironmentInviteRevokeCommand `cmd:"revoke" help:"Revoke a pending invitation"`
+}
+
+type EnvironmentCurrentCommand struct {
+	config.Config
+}
 
 type EnvironmentDeleteCommand struct {
 	EnvWrapperCommand
@@ -52,39 +129,425 @@ type EnvironmentDeleteCommand struct {
 	Confirm       bool   `short:"y" help:"Skip confirmation prompt"`
 }
 
+// EnvironmentCreateCommand creates a new environment. The server derives
+// the slug from the name and the plan from the subscription.
+type EnvironmentCreateCommand struct {
+	config.Config
+	Name         string   `arg:"" required:"" help:"Name of the environment"`
+	Subscription string   `help:"Subscription to bill the environment to, by ID or Stripe subscription ID (defaults to your only subscription)"`
+	InstanceURL  string   `name:"instance-url" help:"URL of the Devgraph instance for the environment (defaults to the configured API URL)"`
+	Invite       []string `help:"Email addresses to invite to the new environment (can be specified multiple times)"`
+	Use          bool     `help:"Set the new environment on the current context"`
+}
+
+// EnvironmentUseCommand switches the current context to another environment
+type EnvironmentUseCommand struct {
+	config.Config
+	Environment string `arg:"" required:"" help:"Environment name, slug, or UUID"`
+}
+
+// EnvironmentDescribeCommand shows status, plan, and membership details for
+// an environment
+type EnvironmentDescribeCommand struct {
+	config.Config
+	Environment string `arg:"" optional:"" help:"Environment UUID, slug, or name (defaults to the current environment)"`
+}
+
+// EnvironmentCloneCommand copies catalog data from one environment to another
+type EnvironmentCloneCommand struct {
+	config.Config
+	Source          string   `arg:"" required:"" help:"Source environment UUID, slug, or name"`
+	Target          string   `arg:"" required:"" help:"Target environment UUID, slug, or name"`
+	Name            string   `flag:"name,n" help:"Only clone entities with this name."`
+	Label           string   `flag:"label,l" help:"Only clone entities matching this label selector."`
+	FieldSelector   string   `flag:"field-selector,f" help:"Only clone entities matching this field selector."`
+	MapNamespace    []string `flag:"map-namespace" help:"Remap a namespace while cloning (format: source=target, repeatable)."`
+	SkipDefinitions bool     `flag:"skip-definitions" help:"Don't clone entity definitions."`
+	SkipRelations   bool     `flag:"skip-relations" help:"Don't clone relations."`
+	Workers         int      `flag:"workers,w" help:"Number of concurrent workers for create operations (defaults to the default_workers setting, or 10)."`
+	EnforceLimits   bool     `flag:"enforce-limits" help:"Abort instead of warning when the clone would exceed the target's entity quota."`
+}
+
+// EnvironmentSettingsGetCommand shows environment-level settings
+type EnvironmentSettingsGetCommand struct {
+	EnvWrapperCommand
+	Key string `arg:"" optional:"" help:"Setting to show (discovery_enabled, discovery_image_id); all settings if omitted"`
+}
+
+// EnvironmentSettingsSetCommand updates environment-level settings
+type EnvironmentSettingsSetCommand struct {
+	EnvWrapperCommand
+	Settings []string `arg:"" required:"" help:"Settings as key=value pairs (discovery_enabled=true|false, discovery_image_id=<uuid>|null)"`
+}
+
+// EnvironmentSettingsCommand manages settings of the current environment
+type EnvironmentSettingsCommand struct {
+	Get EnvironmentSettingsGetCommand `cmd:"get" help:"Show environment settings"`
+	Set EnvironmentSettingsSetCommand `cmd:"set" help:"Update environment settings"`
+}
+
 type EnvironmentCommand struct {
-	Current EnvironmentCurrentCommand `cmd:"current" help:"Display the current environment"`
-	List    EnvironmentListCommand    `cmd:"list" help:"List all environments for Devgraph"`
-	Delete  EnvironmentDeleteCommand  `cmd:"delete" help:"Delete an environment (WARNING: May be permanent after grace period)"`
+	Create   EnvironmentCreateCommand   `cmd:"create" help:"Create a new environment"`
+	Current  EnvironmentCurrentCommand  `cmd:"current" help:"Display the current environment"`
+	List     EnvironmentListCommand     `cmd:"list" help:"List all environments for Devgraph"`
+	Describe EnvironmentDescribeCommand `cmd:"describe" help:"Show status, plan, and membership details for an environment"`
+	Use      EnvironmentUseCommand      `cmd:"use" help:"Switch the current context to another environment"`
+	Clone    EnvironmentCloneCommand    `cmd:"clone" help:"Copy entity definitions, entities, and relations to another environment"`
+	Diff     EnvironmentDiffCommand     `cmd:"diff" help:"Compare the entity definitions, entities, and relations of two environments"`
+	Settings EnvironmentSettingsCommand `cmd:"settings" help:"Manage settings of the current environment"`
+	Delete   EnvironmentDeleteCommand   `cmd:"delete" help:"Delete an environment (WARNING: May be permanent after grace period)"`
 }
 
 // UserCommand manages users in the current environment
 type UserCommand struct {
-	List   EnvironmentUserListCommand   `cmd:"list" help:"List users in the current environment"`
-	Add    EnvironmentUserAddCommand    `cmd:"add" help:"Invite a user to the current environment"`
-	Remove EnvironmentUserRemoveCommand `cmd:"remove" help:"Remove a user from the current environment"`
+	List       EnvironmentUserListCommand       `cmd:"list" help:"List users in the current environment"`
+	Get        EnvironmentUserGetCommand        `cmd:"get" help:"Show details of a user in the current environment"`
+	Add        EnvironmentUserAddCommand        `cmd:"add" help:"Invite a user to the current environment"`
+	Remove     EnvironmentUserRemoveCommand     `cmd:"remove" help:"Remove a user from the current environment"`
+	UpdateRole EnvironmentUserUpdateRoleCommand `cmd:"update-role" help:"Change the role of a user in the current environment"`
+	Invite     EnvironmentInviteCommand         `cmd:"invite" help:"Manage pending invitations"`
+	Audit      EnvironmentUserAuditCommand      `cmd:"audit" help:"Export current members and pending invitations"`
 }
 
-func (e *EnvironmentCurrentCommand) Run() error {
+// setCurrentEnvironment points the current context at the given environment
+// UUID, also updating the default environment setting for backward
+// compatibility
+func setCurrentEnvironment(environmentID string) error {
 	userConfig, err := config.LoadUserConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	if userConfig.CurrentContext != "" {
+		ctx, ok := userConfig.Contexts[userConfig.CurrentContext]
+		if !ok {
+			return fmt.Errorf("context '%s' not found", userConfig.CurrentContext)
+		}
+		ctx.Environment = environmentID
+	}
+	userConfig.Settings.DefaultEnvironment = environmentID
+
+	if err := config.SaveUserConfig(userConfig); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+func (e *EnvironmentCreateCommand) Run() error {
+	e.Config.ApplyDefaults()
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	ctx := context.Background()
+	stripeSubscriptionID, err := resolveStripeSubscription(ctx, client, e.Subscription)
+	if err != nil {
+		return err
+	}
+
+	instanceURL := e.InstanceURL
+	if instanceURL == "" {
+		instanceURL = e.Config.ApiURL
+	}
+
+	request := &api.EnvironmentCreate{
+		Name:                 e.Name,
+		InvitedUsers:         e.Invite,
+		StripeSubscriptionID: stripeSubscriptionID,
+		InstanceURL:          instanceURL,
+	}
+	if request.InvitedUsers == nil {
+		request.InvitedUsers = []string{}
+	}
+
+	resp, err := client.CreateEnvironment(ctx, request)
+	if err != nil {
+		return fmt.Errorf("failed to create environment: %w", err)
+	}
+
+	var created *api.EnvironmentResponse
+	switch r := resp.(type) {
+	case *api.EnvironmentResponse:
+		created = r
+	case *api.CreateEnvironmentNotFound:
+		return fmt.Errorf("failed to create environment: not found")
+	case *api.HTTPValidationError:
+		return fmt.Errorf("validation error: %v", r.Detail)
+	default:
+		return fmt.Errorf("unexpected response type: %T", resp)
+	}
+
+	fmt.Printf("✅ Environment '%s' (%s) created successfully with ID: %s\n", created.Name, created.Slug, created.ID)
+
+	if e.Use {
+		if err := setCurrentEnvironment(created.ID.String()); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Switched to environment '%s'.\n", created.Name)
+	}
+	return nil
+}
+
+// resolveStripeSubscription returns the Stripe subscription ID for ref,
+// which may be a subscription ID or a Stripe subscription ID. With no ref,
+// the user's only subscription is used.
+func resolveStripeSubscription(ctx context.Context, client *api.Client, ref string) (string, error) {
+	response, err := client.GetSubscriptions(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get subscriptions: %w", err)
+	}
+
+	var subscriptions []api.SubscriptionResponse
+	switch r := response.(type) {
+	case *api.GetSubscriptionsOKApplicationJSON:
+		subscriptions = *r
+	case *api.GetSubscriptionsNotFound:
+	default:
+		return "", fmt.Errorf("unexpected response type: %T", response)
+	}
+
+	if ref == "" {
+		switch len(subscriptions) {
+		case 0:
+			return "", fmt.Errorf("no subscriptions found; a subscription is required to create an environment")
+		case 1:
+			return subscriptions[0].StripeSubscriptionID, nil
+		default:
+			ids := make([]string, len(subscriptions))
+			for i, sub := range subscriptions {
+				ids[i] = sub.ID.String()
+			}
+			return "", fmt.Errorf("multiple subscriptions found; choose one with --subscription (one of: %s)", strings.Join(ids, ", "))
+		}
+	}
+
+	for _, sub := range subscriptions {
+		if sub.ID.String() == ref || sub.StripeSubscriptionID == ref {
+			return sub.StripeSubscriptionID, nil
+		}
+	}
+	return "", fmt.Errorf("subscription '%s' not found", ref)
+}
+
+func (e *EnvironmentUseCommand) Run() error {
+	e.Config.ApplyDefaults()
+
+	envs, err := util.GetEnvironments(e.Config)
+	if err != nil {
+		return err
+	}
+
+	env, err := util.FindEnvironment(*envs, e.Environment)
+	if err != nil {
+		return err
+	}
+
+	if err := setCurrentEnvironment(env.ID.String()); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Switched to environment '%s' (%s).\n", env.Name, env.Slug)
+	return nil
+}
+
+type environmentDescription struct {
+	ID                 string `json:"id" yaml:"id"`
+	Name               string `json:"name" yaml:"name"`
+	Slug               string `json:"slug" yaml:"slug"`
+	Status             string `json:"status" yaml:"status"`
+	Plan               string `json:"plan,omitempty" yaml:"plan,omitempty"`
+	SubscriptionStatus string `json:"subscription_status,omitempty" yaml:"subscription_status,omitempty"`
+	Members            int    `json:"members" yaml:"members"`
+	PendingInvitations int    `json:"pending_invitations" yaml:"pending_invitations"`
+	// Usage is the consumption of each metered entitlement, such as
+	// entities and seats, against the plan's limits
+	Usage map[string]usageMetric `json:"usage,omitempty" yaml:"usage,omitempty"`
+}
+
+func (e *EnvironmentDescribeCommand) Run() error {
+	e.Config.ApplyDefaults()
+
+	format := e.OutputFormat(output.Table)
+	if err := output.Check(format, output.Table, output.JSON, output.YAML, output.Name); err != nil {
+		return err
+	}
+
+	identifier := e.Environment
+	if identifier == "" {
+		current, err := getDefaultEnvironment(e.Config)
+		if err != nil {
+			return err
+		}
+		identifier = current
+	}
+
+	envs, err := util.GetEnvironments(e.Config)
+	if err != nil {
+		return err
+	}
+	env, err := util.FindEnvironment(*envs, identifier)
+	if err != nil {
+		return err
+	}
+
+	desc := environmentDescription{
+		ID:   env.ID.String(),
+		Name: env.Name,
+		Slug: env.Slug,
+	}
+
+	// Entitlements are read for the environment the client targets, so
+	// scope it to the one being described rather than the current context
+	envConfig := e.Config
+	envConfig.EnvOverride = env.ID.String()
+	client, err := util.GetAuthenticatedClient(envConfig)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	statusResp, err := client.GetEnvironmentStatus(ctx, api.GetEnvironmentStatusParams{EnvID: env.ID})
+	if err != nil {
+		return fmt.Errorf("failed to get environment status: %w", err)
+	}
+	switch r := statusResp.(type) {
+	case *api.EnvironmentStatusResponse:
+		desc.Status = r.Status
+	case *api.GetEnvironmentStatusNotFound:
+		return fmt.Errorf("environment '%s' not found", identifier)
+	default:
+		return fmt.Errorf("unexpected response type: %T", statusResp)
+	}
+
+	sub, err := environmentSubscription(ctx, client, env.ID)
+	if err != nil {
+		return err
+	}
+	if sub != nil {
+		if sub.PlanName.Set {
+			desc.Plan = sub.PlanName.Value
+		}
+		desc.SubscriptionStatus = string(sub.Status)
+	}
+
+	usersResp, err := client.ListEnvironmentUsers(ctx, api.ListEnvironmentUsersParams{EnvironmentID: env.ID})
+	if err != nil {
+		return fmt.Errorf("failed to list environment users: %w", err)
+	}
+	if users, ok := usersResp.(*api.ListEnvironmentUsersOKApplicationJSON); ok {
+		desc.Members = len(*users)
+	} else {
+		return fmt.Errorf("unexpected response type: %T", usersResp)
+	}
+
+	invitesResp, err := client.GetPendingInvitations(ctx, api.GetPendingInvitationsParams{EnvironmentID: env.ID})
+	if err != nil {
+		return fmt.Errorf("failed to list pending invitations: %w", err)
+	}
+	if invites, ok := invitesResp.(*api.GetPendingInvitationsOKApplicationJSON); ok {
+		desc.PendingInvitations = len(*invites)
+	} else {
+		return fmt.Errorf("unexpected response type: %T", invitesResp)
+	}
+
+	desc.Usage, err = getEntitlementUsage(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	if format != output.Table {
+		return output.Print(format, output.Result{Data: desc, Names: []string{desc.ID}})
+	}
+
+	fmt.Printf("Name:          %s\n", desc.Name)
+	fmt.Printf("Slug:          %s\n", desc.Slug)
+	fmt.Printf("ID:            %s\n", desc.ID)
+	fmt.Printf("Status:        %s\n", util.OrDash(desc.Status))
+	fmt.Printf("Plan:          %s\n", util.OrDash(desc.Plan))
+	fmt.Printf("Subscription:  %s\n", util.OrDash(desc.SubscriptionStatus))
+	fmt.Printf("Members:       %d\n", desc.Members)
+	fmt.Printf("Invitations:   %d pending\n", desc.PendingInvitations)
+	if len(desc.Usage) > 0 {
+		names := make([]string, 0, len(desc.Usage))
+		for name := range desc.Usage {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Println("Usage:")
+		for _, name := range names {
+			metric := desc.Usage[name]
+			limit := "unlimited"
+			if metric.Limit != nil {
+				limit = fmt.Sprintf("%d", *metric.Limit)
+			}
+			fmt.Printf("  %-12s %d / %s\n", name+":", metric.Used, limit)
+		}
+	}
+	return nil
+}
+
+// currentContextEnvironment returns the environment UUID the current context
+// points to
+func currentContextEnvironment() (string, error) {
+	userConfig, err := config.LoadUserConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+
 	if userConfig.CurrentContext == "" {
-		return fmt.Errorf("no current context set")
+		return "", fmt.Errorf("no current context set")
 	}
 
 	context, ok := userConfig.Contexts[userConfig.CurrentContext]
 	if !ok {
-		return fmt.Errorf("context '%s' not found", userConfig.CurrentContext)
+		return "", fmt.Errorf("context '%s' not found", userConfig.CurrentContext)
 	}
 
 	if context.Environment == "" {
-		return fmt.Errorf("no environment set for context '%s'. Use 'dg config set-context %s --env <env>' to set an environment", userConfig.CurrentContext, userConfig.CurrentContext)
+		return "", fmt.Errorf("no environment set for context '%s'. Use 'dg config set-context %s --env <env>' to set an environment", userConfig.CurrentContext, userConfig.CurrentContext)
+	}
+	return context.Environment, nil
+}
+
+func (e *EnvironmentCurrentCommand) Run() error {
+	e.Config.ApplyDefaults()
+
+	environmentID, err := currentContextEnvironment()
+	if err != nil {
+		return err
+	}
+
+	type currentOutput struct {
+		ID   string `json:"id" yaml:"id"`
+		Name string `json:"name,omitempty" yaml:"name,omitempty"`
+		Slug string `json:"slug,omitempty" yaml:"slug,omitempty"`
+	}
+	current := currentOutput{ID: environmentID}
+
+	// Resolve the UUID to something readable, falling back to the raw ID if
+	// the environment can't be looked up
+	envs, err := util.GetEnvironments(e.Config)
+	if err != nil {
+		logging.Warn("could not look up environment details", "error", err)
+	} else if env, err := util.FindEnvironment(*envs, environmentID); err != nil {
+		logging.Warn("environment is not accessible", "environment", environmentID, "error", err)
+	} else {
+		current.Name = env.Name
+		current.Slug = env.Slug
+	}
+
+	if format := e.OutputFormat(output.Table); format != output.Table {
+		return output.Print(format, output.Result{Data: current, Names: []string{current.ID}})
 	}
 
-	fmt.Println(context.Environment)
+	if current.Name == "" {
+		fmt.Println(current.ID)
+		return nil
+	}
+	fmt.Printf("%s (%s) %s\n", current.Name, current.Slug, current.ID)
 	return nil
 }
 
@@ -95,35 +558,64 @@ func (e *EnvironmentListCommand) Run() error {
 		return err
 	}
 
-	if envs == nil || len(*envs) == 0 {
-		fmt.Println("No environments found.")
-		return nil
-	}
+	// Not having a current environment isn't an error when listing
+	currentID, _ := currentContextEnvironment()
 
 	// Build structured data for json/yaml output
 	type envOutput struct {
-		ID   string `json:"id" yaml:"id"`
-		Name string `json:"name" yaml:"name"`
-		Slug string `json:"slug" yaml:"slug"`
+		Current bool   `json:"current" yaml:"current"`
+		ID      string `json:"id" yaml:"id"`
+		Name    string `json:"name" yaml:"name"`
+		Slug    string `json:"slug" yaml:"slug"`
 	}
 
-	structured := make([]envOutput, len(*envs))
-	tableData := make([]map[string]any, len(*envs))
-	for i, env := range *envs {
+	var environments []api.EnvironmentResponse
+	if envs != nil {
+		environments = *envs
+	}
+	structured := make([]envOutput, len(environments))
+	tableData := make([]map[string]any, len(environments))
+	names := make([]string, len(environments))
+	for i, env := range environments {
+		isCurrent := env.ID.String() == currentID
 		structured[i] = envOutput{
-			ID:   env.ID.String(),
-			Name: env.Name,
-			Slug: env.Slug,
+			Current: isCurrent,
+			ID:      env.ID.String(),
+			Name:    env.Name,
+			Slug:    env.Slug,
+		}
+		current := ""
+		if isCurrent {
+			current = "*"
 		}
 		tableData[i] = map[string]any{
-			"ID":   env.ID.String(),
-			"Name": env.Name,
-			"Slug": env.Slug,
+			"Current": current,
+			"ID":      env.ID.String(),
+			"Name":    env.Name,
+			"Slug":    env.Slug,
 		}
+		names[i] = env.ID.String()
 	}
 
-	headers := []string{"ID", "Name", "Slug"}
-	return util.FormatOutput(e.Output, structured, headers, tableData)
+	return output.Print(e.OutputFormat(output.Table), output.Result{
+		Data:    structured,
+		Headers: []string{"Current", "ID", "Name", "Slug"},
+		Rows:    tableData,
+		Empty:   "No environments found.",
+		Names:   names,
+	})
+}
+
+// matches reports whether a user or invitation passes the --role and
+// --status filters
+func (e *EnvironmentUserListCommand) matches(role, status string) bool {
+	if e.Role != "" && !strings.EqualFold(role, e.Role) {
+		return false
+	}
+	if e.Status != "" && !strings.EqualFold(status, e.Status) {
+		return false
+	}
+	return true
 }
 
 func (e *EnvironmentUserListCommand) Run() error {
@@ -132,7 +624,7 @@ func (e *EnvironmentUserListCommand) Run() error {
 		return err
 	}
 
-	environment, err := getDefaultEnvironment()
+	environment, err := getDefaultEnvironment(e.Config)
 	if err != nil {
 		return err
 	}
@@ -154,12 +646,12 @@ func (e *EnvironmentUserListCommand) Run() error {
 
 		switch r := resp.(type) {
 		case *api.GetPendingInvitationsOKApplicationJSON:
-			invites := []api.PendingInvitationResponse(*r)
-			if len(invites) == 0 {
-				fmt.Println("No pending invitations found in this environment.")
-				return nil
+			var invites []api.PendingInvitationResponse
+			for _, invite := range *r {
+				if e.matches(string(invite.Role), string(invite.Status)) {
+					invites = append(invites, invite)
+				}
 			}
-
 			type inviteOutput struct {
 				ID     string `json:"id" yaml:"id"`
 				Email  string `json:"email" yaml:"email"`
@@ -169,6 +661,7 @@ func (e *EnvironmentUserListCommand) Run() error {
 
 			structured := make([]inviteOutput, len(invites))
 			tableData := make([]map[string]any, len(invites))
+			names := make([]string, len(invites))
 			for i, invite := range invites {
 				structured[i] = inviteOutput{
 					ID:     invite.ID,
@@ -182,10 +675,16 @@ func (e *EnvironmentUserListCommand) Run() error {
 					"Role":   invite.Role,
 					"Status": invite.Status,
 				}
+				names[i] = invite.ID
 			}
 
-			headers := []string{"ID", "Email", "Role", "Status"}
-			return util.FormatOutput(e.Output, structured, headers, tableData)
+			return output.Print(e.OutputFormat(output.Table), output.Result{
+				Data:    structured,
+				Headers: []string{"ID", "Email", "Role", "Status"},
+				Rows:    tableData,
+				Empty:   "No pending invitations found in this environment.",
+				Names:   names,
+			})
 		default:
 			return fmt.Errorf("failed to list pending invitations")
 		}
@@ -202,12 +701,12 @@ func (e *EnvironmentUserListCommand) Run() error {
 	// Check if response is successful
 	switch r := resp.(type) {
 	case *api.ListEnvironmentUsersOKApplicationJSON:
-		users := []api.EnvironmentUserResponse(*r)
-		if len(users) == 0 {
-			fmt.Println("No users found in this environment.")
-			return nil
+		var users []api.EnvironmentUserResponse
+		for _, user := range *r {
+			if e.matches(string(user.Role), string(user.Status)) {
+				users = append(users, user)
+			}
 		}
-
 		type userOutput struct {
 			ID     string `json:"id" yaml:"id"`
 			Email  string `json:"email" yaml:"email"`
@@ -217,6 +716,7 @@ func (e *EnvironmentUserListCommand) Run() error {
 
 		structured := make([]userOutput, len(users))
 		tableData := make([]map[string]any, len(users))
+		names := make([]string, len(users))
 		for i, user := range users {
 			structured[i] = userOutput{
 				ID:     user.ID,
@@ -230,78 +730,227 @@ func (e *EnvironmentUserListCommand) Run() error {
 				"Role":   user.Role,
 				"Status": user.Status,
 			}
+			names[i] = user.ID
 		}
 
-		headers := []string{"ID", "Email", "Role", "Status"}
-		return util.FormatOutput(e.Output, structured, headers, tableData)
+		return output.Print(e.OutputFormat(output.Table), output.Result{
+			Data:    structured,
+			Headers: []string{"ID", "Email", "Role", "Status"},
+			Rows:    tableData,
+			Empty:   "No users found in this environment.",
+			Names:   names,
+		})
 	default:
 		return fmt.Errorf("failed to list environment users")
 	}
 }
 
-func (e *EnvironmentUserAddCommand) Run() error {
-	client, err := util.GetAuthenticatedClient(e.Config)
-	if err != nil {
-		return err
-	}
+// userInvite is one row of a bulk invitation file
+type userInvite struct {
+	Line  int
+	Email string
+	Role  string
+}
 
-	environment, err := getDefaultEnvironment()
-	if err != nil {
-		return err
-	}
+// parseUserInvites reads "email,role" rows from a CSV file. A header row,
+// blank lines, and lines starting with # are skipped; rows without a role
+// use defaultRole.
+func parseUserInvites(r io.Reader, defaultRole string) ([]userInvite, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
 
-	ctx := context.TODO()
-	invite := api.EnvironmentUserInvite{
-		EmailAddress: e.Email,
-		Role:         api.NewOptEnvironmentUserInviteRole(api.EnvironmentUserInviteRole(e.Role)),
+	var invites []userInvite
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		email := strings.TrimSpace(record[0])
+		if email == "" {
+			continue
+		}
+		if len(invites) == 0 && strings.EqualFold(email, "email") {
+			continue
+		}
+		if !strings.Contains(email, "@") {
+			return nil, fmt.Errorf("line %d: invalid email address '%s'", line, email)
+		}
+		if len(record) > 2 {
+			return nil, fmt.Errorf("line %d: expected 'email,role', got %d fields", line, len(record))
+		}
+
+		role := defaultRole
+		if len(record) == 2 && strings.TrimSpace(record[1]) != "" {
+			role = strings.TrimSpace(record[1])
+		}
+		invites = append(invites, userInvite{Line: line, Email: email, Role: role})
 	}
+	return invites, nil
+}
 
-	envUUID, err := uuid.Parse(environment)
-	if err != nil {
-		return fmt.Errorf("invalid environment UUID: %w", err)
+// inviteEnvironmentUser invites a single user to the environment and
+// returns the pending invitation
+func inviteEnvironmentUser(client *api.Client, envUUID uuid.UUID, email, role string) (*api.EnvironmentUserResponse, error) {
+	invite := api.EnvironmentUserInvite{
+		EmailAddress: email,
+		Role:         api.NewOptEnvironmentUserInviteRole(api.EnvironmentUserInviteRole(role)),
 	}
 	params := api.InviteEnvironmentUserParams{
 		EnvironmentID: envUUID,
 	}
-	resp, err := client.InviteEnvironmentUser(ctx, &invite, params)
+	resp, err := client.InviteEnvironmentUser(context.TODO(), &invite, params)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Check if response is successful
-	switch resp.(type) {
+	switch r := resp.(type) {
 	case *api.EnvironmentUserResponse:
-		// Success
+		return r, nil
+	case *api.InviteEnvironmentUserNotFound:
+		return nil, fmt.Errorf("environment not found")
+	case *api.HTTPValidationError:
+		return nil, fmt.Errorf("validation error: %v", r.Detail)
 	default:
-		return fmt.Errorf("failed to invite user")
+		return nil, fmt.Errorf("failed to invite user")
 	}
-
-	fmt.Printf("✅ Invited '%s' to environment with role '%s'.\n", e.Email, e.Role)
-	return nil
 }
 
-func (e *EnvironmentUserRemoveCommand) Run() error {
+func (e *EnvironmentUserAddCommand) Run() error {
+	if (e.Email == "") == (e.FromFile == "") {
+		return fmt.Errorf("specify either an email address or --from-file")
+	}
+
 	client, err := util.GetAuthenticatedClient(e.Config)
 	if err != nil {
 		return err
 	}
 
-	environment, err := getDefaultEnvironment()
+	environment, err := getDefaultEnvironment(e.Config)
 	if err != nil {
 		return err
 	}
 
-	ctx := context.TODO()
 	envUUID, err := uuid.Parse(environment)
 	if err != nil {
 		return fmt.Errorf("invalid environment UUID: %w", err)
 	}
-	params := api.DeleteEnvironmentUserParams{
-		EnvironmentID: envUUID,
-		UserID:        e.UserID,
+
+	if e.FromFile != "" {
+		return e.inviteFromFile(client, envUUID)
 	}
-	resp, err := client.DeleteEnvironmentUser(ctx, params)
-	if err != nil {
+
+	if _, err := inviteEnvironmentUser(client, envUUID, e.Email, e.Role); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Invited '%s' to environment with role '%s'.\n", e.Email, e.Role)
+	return nil
+}
+
+// inviteFromFile invites every user listed in the --from-file CSV in one
+// bulk request, reporting the outcome of each row
+func (e *EnvironmentUserAddCommand) inviteFromFile(client *api.Client, envUUID uuid.UUID) error {
+	data, err := util.ReadFileOrStdin(e.FromFile)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", e.FromFile, err)
+	}
+
+	invites, err := parseUserInvites(bytes.NewReader(data), e.Role)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", e.FromFile, err)
+	}
+	if len(invites) == 0 {
+		fmt.Println("No users found to invite.")
+		return nil
+	}
+
+	request := &api.EnvironmentUserBulkInvite{
+		Invitations: make([]api.EnvironmentUserInvite, len(invites)),
+	}
+	for i, invite := range invites {
+		role := api.EnvironmentUserInviteRole(invite.Role)
+		if err := role.Validate(); err != nil {
+			return fmt.Errorf("line %d: invalid role '%s' (one of: member, admin)", invite.Line, invite.Role)
+		}
+		request.Invitations[i] = api.EnvironmentUserInvite{
+			EmailAddress: invite.Email,
+			Role:         api.NewOptEnvironmentUserInviteRole(role),
+		}
+	}
+
+	if err := checkQuota(client, "seats", int64(len(invites)), e.EnforceLimits); err != nil {
+		return err
+	}
+
+	resp, err := client.BulkInviteEnvironmentUsers(context.TODO(), request, api.BulkInviteEnvironmentUsersParams{
+		EnvironmentID: envUUID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to invite users: %w", err)
+	}
+
+	invited := make(map[string]bool)
+	switch r := resp.(type) {
+	case *api.BulkInviteEnvironmentUsersCreatedApplicationJSON:
+		for _, user := range *r {
+			invited[strings.ToLower(user.EmailAddress)] = true
+		}
+	case *api.BulkInviteEnvironmentUsersNotFound:
+		return fmt.Errorf("environment not found")
+	case *api.HTTPValidationError:
+		return fmt.Errorf("validation error: %v", r.Detail)
+	default:
+		return fmt.Errorf("unexpected response type: %T", resp)
+	}
+
+	// The server returns the users it invited; anyone missing was skipped
+	failed := 0
+	for _, invite := range invites {
+		if !invited[strings.ToLower(invite.Email)] {
+			fmt.Printf("❌ Line %d: '%s' was not invited\n", invite.Line, invite.Email)
+			failed++
+			continue
+		}
+		fmt.Printf("✅ Line %d: invited '%s' with role '%s'\n", invite.Line, invite.Email, invite.Role)
+	}
+
+	fmt.Printf("\nInvited %d of %d users.\n", len(invites)-failed, len(invites))
+	if failed > 0 {
+		return fmt.Errorf("%d invitations failed", failed)
+	}
+	return nil
+}
+
+func (e *EnvironmentUserRemoveCommand) Run() error {
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return err
+	}
+
+	environment, err := getDefaultEnvironment(e.Config)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.TODO()
+	envUUID, err := uuid.Parse(environment)
+	if err != nil {
+		return fmt.Errorf("invalid environment UUID: %w", err)
+	}
+	params := api.DeleteEnvironmentUserParams{
+		EnvironmentID: envUUID,
+		UserID:        e.UserID,
+	}
+	resp, err := client.DeleteEnvironmentUser(ctx, params)
+	if err != nil {
 		return err
 	}
 
@@ -317,6 +966,480 @@ func (e *EnvironmentUserRemoveCommand) Run() error {
 	return nil
 }
 
+// confirmsEnvironmentDeletion reports whether the typed confirmation matches
+// the environment's name or slug exactly
+func confirmsEnvironmentDeletion(confirmation string, env *api.EnvironmentResponse) bool {
+	confirmation = strings.TrimSpace(confirmation)
+	return confirmation != "" && (confirmation == env.Name || confirmation == env.Slug)
+}
+
+// findEnvironmentUser returns the member of the environment whose ID or email
+// address matches identifier. Emails are compared case-insensitively.
+func findEnvironmentUser(client *api.Client, envUUID uuid.UUID, identifier string) (*api.EnvironmentUserResponse, error) {
+	user, err := lookupEnvironmentUser(context.TODO(), client, envUUID, identifier)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user '%s' not found in this environment", identifier)
+	}
+	return user, nil
+}
+
+// lookupEnvironmentUser returns the member with the given user ID or email
+// address, or nil if there is none
+func lookupEnvironmentUser(ctx context.Context, client *api.Client, envUUID uuid.UUID, identifier string) (*api.EnvironmentUserResponse, error) {
+	if !strings.Contains(identifier, "@") {
+		resp, err := client.GetEnvironmentUser(ctx, api.GetEnvironmentUserParams{
+			EnvironmentID: envUUID,
+			UserID:        identifier,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		switch r := resp.(type) {
+		case *api.EnvironmentUserResponse:
+			return r, nil
+		case *api.GetEnvironmentUserNotFound:
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("unexpected response type: %T", resp)
+		}
+	}
+
+	resp, err := client.ListEnvironmentUsers(ctx, api.ListEnvironmentUsersParams{
+		EnvironmentID: envUUID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list environment users: %w", err)
+	}
+
+	r, ok := resp.(*api.ListEnvironmentUsersOKApplicationJSON)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
+	}
+	for i, user := range *r {
+		if strings.EqualFold(user.EmailAddress, identifier) {
+			return &(*r)[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (e *EnvironmentUserUpdateRoleCommand) Run() error {
+	role := api.EnvironmentUserUpdateRole(e.Role)
+	if err := role.Validate(); err != nil {
+		return fmt.Errorf("invalid role '%s' (one of: member, admin)", e.Role)
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return err
+	}
+
+	environment, err := getDefaultEnvironment(e.Config)
+	if err != nil {
+		return err
+	}
+	envUUID, err := uuid.Parse(environment)
+	if err != nil {
+		return fmt.Errorf("invalid environment UUID: %w", err)
+	}
+
+	user, err := findEnvironmentUser(client, envUUID, e.User)
+	if err != nil {
+		return err
+	}
+
+	if string(user.Role) == e.Role {
+		fmt.Printf("User '%s' already has role '%s'.\n", user.EmailAddress, e.Role)
+		return nil
+	}
+
+	resp, err := client.UpdateEnvironmentUser(context.TODO(), &api.EnvironmentUserUpdate{Role: role}, api.UpdateEnvironmentUserParams{
+		EnvironmentID: envUUID,
+		UserID:        user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+
+	switch r := resp.(type) {
+	case *api.EnvironmentUserResponse:
+		fmt.Printf("✅ Changed role of '%s' from '%s' to '%s'.\n", user.EmailAddress, user.Role, r.Role)
+		return nil
+	case *api.UpdateEnvironmentUserNotFound:
+		return fmt.Errorf("user '%s' not found in this environment", e.User)
+	case *api.HTTPValidationError:
+		return fmt.Errorf("validation error: %v", r.Detail)
+	default:
+		return fmt.Errorf("unexpected response type: %T", resp)
+	}
+}
+
+// userDetail is the combined membership and invitation state of a user
+type userDetail struct {
+	ID              string `json:"id,omitempty" yaml:"id,omitempty"`
+	Email           string `json:"email" yaml:"email"`
+	Role            string `json:"role" yaml:"role"`
+	Status          string `json:"status" yaml:"status"`
+	JoinedAt        string `json:"joined_at,omitempty" yaml:"joined_at,omitempty"`
+	PendingInvite   bool   `json:"pending_invite" yaml:"pending_invite"`
+	InvitationID    string `json:"invitation_id,omitempty" yaml:"invitation_id,omitempty"`
+	InviteExpiresAt string `json:"invite_expires_at,omitempty" yaml:"invite_expires_at,omitempty"`
+}
+
+// formatUnixTime renders a Unix timestamp from the API as RFC 3339
+func formatUnixTime(seconds int) string {
+	return time.Unix(int64(seconds), 0).UTC().Format(time.RFC3339)
+}
+
+func (e *EnvironmentUserGetCommand) Run() error {
+	format := e.OutputFormat(output.Table)
+	if err := output.Check(format, output.Table, output.JSON, output.YAML, output.Name); err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return err
+	}
+
+	environment, err := getDefaultEnvironment(e.Config)
+	if err != nil {
+		return err
+	}
+	envUUID, err := uuid.Parse(environment)
+	if err != nil {
+		return fmt.Errorf("invalid environment UUID: %w", err)
+	}
+
+	ctx := context.TODO()
+	user, err := lookupEnvironmentUser(ctx, client, envUUID, e.User)
+	if err != nil {
+		return err
+	}
+
+	var detail *userDetail
+	if user != nil {
+		detail = &userDetail{
+			ID:       user.ID,
+			Email:    user.EmailAddress,
+			Role:     user.Role,
+			Status:   user.Status,
+			JoinedAt: formatUnixTime(user.CreatedAt),
+		}
+	}
+
+	// A user may have a pending invitation instead of (or as well as) a
+	// membership, e.g. when re-invited with a different role
+	resp, err := client.GetPendingInvitations(ctx, api.GetPendingInvitationsParams{
+		EnvironmentID: envUUID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list pending invitations: %w", err)
+	}
+	invites, ok := resp.(*api.GetPendingInvitationsOKApplicationJSON)
+	if !ok {
+		return fmt.Errorf("unexpected response type: %T", resp)
+	}
+	email := e.User
+	if detail != nil {
+		email = detail.Email
+	}
+	for _, invite := range *invites {
+		if invite.ID != e.User && !strings.EqualFold(invite.EmailAddress, email) {
+			continue
+		}
+		if detail == nil {
+			detail = &userDetail{
+				Email:  invite.EmailAddress,
+				Role:   invite.Role,
+				Status: invite.Status,
+			}
+		}
+		detail.PendingInvite = true
+		detail.InvitationID = invite.ID
+		if expiresAt, ok := invite.ExpiresAt.Get(); ok {
+			detail.InviteExpiresAt = formatUnixTime(expiresAt)
+		}
+		break
+	}
+
+	if detail == nil {
+		return fmt.Errorf("user '%s' not found in this environment", e.User)
+	}
+
+	if format != output.Table {
+		// Users who haven't accepted their invitation are named by it
+		name := detail.ID
+		if name == "" {
+			name = detail.InvitationID
+		}
+		return output.Print(format, output.Result{Data: detail, Names: []string{name}})
+	}
+
+	fmt.Printf("ID:             %s\n", util.OrDash(detail.ID))
+	fmt.Printf("Email:          %s\n", detail.Email)
+	fmt.Printf("Role:           %s\n", detail.Role)
+	fmt.Printf("Status:         %s\n", detail.Status)
+	fmt.Printf("Joined:         %s\n", util.OrDash(detail.JoinedAt))
+	if detail.PendingInvite {
+		fmt.Printf("Pending invite: yes (%s)\n", detail.InvitationID)
+		if detail.InviteExpiresAt != "" {
+			fmt.Printf("Invite expires: %s\n", detail.InviteExpiresAt)
+		}
+	} else {
+		fmt.Println("Pending invite: no")
+	}
+	return nil
+}
+
+// membershipEvent is one entry in the membership audit export. The API
+// doesn't keep a membership history, so entries record when each current
+// member joined and when each pending invitation was sent.
+type membershipEvent struct {
+	Timestamp time.Time  `json:"timestamp"`
+	Action    string     `json:"action"`
+	User      string     `json:"user"`
+	Role      string     `json:"role"`
+	Status    string     `json:"status"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// writeMembershipEventsCSV writes events as CSV with a header row
+func writeMembershipEventsCSV(w io.Writer, events []membershipEvent) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"timestamp", "action", "user", "role", "status", "expires_at"}); err != nil {
+		return err
+	}
+	for _, event := range events {
+		expiresAt := ""
+		if event.ExpiresAt != nil {
+			expiresAt = event.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+		record := []string{event.Timestamp.UTC().Format(time.RFC3339), event.Action, event.User, event.Role, event.Status, expiresAt}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// membershipEvents builds audit entries from the current members and
+// pending invitations of an environment
+func membershipEvents(ctx context.Context, client *api.Client, envUUID uuid.UUID) ([]membershipEvent, error) {
+	var events []membershipEvent
+
+	usersResp, err := client.ListEnvironmentUsers(ctx, api.ListEnvironmentUsersParams{
+		EnvironmentID: envUUID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list environment users: %w", err)
+	}
+	users, ok := usersResp.(*api.ListEnvironmentUsersOKApplicationJSON)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type: %T", usersResp)
+	}
+	for _, user := range *users {
+		events = append(events, membershipEvent{
+			Timestamp: time.Unix(int64(user.CreatedAt), 0).UTC(),
+			Action:    "member_joined",
+			User:      user.EmailAddress,
+			Role:      user.Role,
+			Status:    user.Status,
+		})
+	}
+
+	invitesResp, err := client.GetPendingInvitations(ctx, api.GetPendingInvitationsParams{
+		EnvironmentID: envUUID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending invitations: %w", err)
+	}
+	invites, ok := invitesResp.(*api.GetPendingInvitationsOKApplicationJSON)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type: %T", invitesResp)
+	}
+	for _, invite := range *invites {
+		event := membershipEvent{
+			Timestamp: time.Unix(int64(invite.CreatedAt), 0).UTC(),
+			Action:    "invitation_sent",
+			User:      invite.EmailAddress,
+			Role:      invite.Role,
+			Status:    invite.Status,
+		}
+		if expires, ok := invite.ExpiresAt.Get(); ok {
+			expiresAt := time.Unix(int64(expires), 0).UTC()
+			event.ExpiresAt = &expiresAt
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (e *EnvironmentUserAuditCommand) Run() error {
+	envUUID, err := defaultEnvironmentUUID(e.Config)
+	if err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return err
+	}
+
+	events, err := membershipEvents(context.TODO(), client, envUUID)
+	if err != nil {
+		return err
+	}
+	if !e.Since.IsZero() {
+		filtered := events[:0]
+		for _, event := range events {
+			if !event.Timestamp.Before(e.Since) {
+				filtered = append(filtered, event)
+			}
+		}
+		events = filtered
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+
+	out := io.Writer(os.Stdout)
+	if e.File != "" {
+		file, err := os.Create(e.File)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", e.File, err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	switch e.Format {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if events == nil {
+			events = []membershipEvent{}
+		}
+		if err := encoder.Encode(events); err != nil {
+			return fmt.Errorf("failed to write audit export: %w", err)
+		}
+	default:
+		if err := writeMembershipEventsCSV(out, events); err != nil {
+			return fmt.Errorf("failed to write audit export: %w", err)
+		}
+	}
+
+	if e.File != "" {
+		fmt.Printf("✅ Exported %d membership events to %s\n", len(events), e.File)
+	}
+	return nil
+}
+
+// findPendingInvitation returns the pending invitation with the given ID
+func findPendingInvitation(ctx context.Context, client *api.Client, envUUID uuid.UUID, invitationID string) (*api.PendingInvitationResponse, error) {
+	resp, err := client.GetPendingInvitations(ctx, api.GetPendingInvitationsParams{EnvironmentID: envUUID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending invitations: %w", err)
+	}
+
+	r, ok := resp.(*api.GetPendingInvitationsOKApplicationJSON)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
+	}
+	for i, invite := range *r {
+		if invite.ID == invitationID {
+			return &(*r)[i], nil
+		}
+	}
+	return nil, fmt.Errorf("pending invitation '%s' not found", invitationID)
+}
+
+// revokeInvitation deletes a pending invitation
+func revokeInvitation(ctx context.Context, client *api.Client, envUUID uuid.UUID, invitationID string) error {
+	resp, err := client.DeleteEnvironmentInvitation(ctx, api.DeleteEnvironmentInvitationParams{
+		EnvironmentID: envUUID,
+		InvitationID:  invitationID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+
+	switch r := resp.(type) {
+	case *api.DeleteEnvironmentInvitationNoContent:
+		return nil
+	case *api.DeleteEnvironmentInvitationNotFound:
+		return fmt.Errorf("pending invitation '%s' not found", invitationID)
+	case *api.HTTPValidationError:
+		return fmt.Errorf("validation error: %v", r.Detail)
+	default:
+		return fmt.Errorf("unexpected response type: %T", resp)
+	}
+}
+
+// Run resends an invitation by revoking it and inviting the same address
+// again with the same role, since the API has no resend operation
+func (e *EnvironmentInviteResendCommand) Run() error {
+	envUUID, err := defaultEnvironmentUUID(e.Config)
+	if err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.TODO()
+	invite, err := findPendingInvitation(ctx, client, envUUID, e.InvitationID)
+	if err != nil {
+		return err
+	}
+
+	// Resending is two requests, and the second depends on the first
+	// succeeding, so describe both rather than sending either
+	if e.DryRun {
+		fmt.Println("Dry run: Would resend the invitation in 2 steps:")
+		fmt.Printf("  Revoke invitation '%s'\n", invite.ID)
+		fmt.Printf("  Invite '%s' again as %s\n", invite.EmailAddress, invite.Role)
+		return nil
+	}
+
+	if err := revokeInvitation(ctx, client, envUUID, invite.ID); err != nil {
+		return err
+	}
+	resent, err := inviteEnvironmentUser(client, envUUID, invite.EmailAddress, invite.Role)
+	if err != nil {
+		return fmt.Errorf("revoked invitation '%s' but failed to invite '%s' again: %w", invite.ID, invite.EmailAddress, err)
+	}
+
+	fmt.Printf("✅ Invitation to '%s' resent successfully with ID: %s\n", invite.EmailAddress, resent.ID)
+	return nil
+}
+
+func (e *EnvironmentInviteRevokeCommand) Run() error {
+	envUUID, err := defaultEnvironmentUUID(e.Config)
+	if err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return err
+	}
+
+	if err := revokeInvitation(context.TODO(), client, envUUID, e.InvitationID); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Invitation '%s' revoked successfully.\n", e.InvitationID)
+	return nil
+}
+
 func (e *EnvironmentDeleteCommand) Run() error {
 	client, err := util.GetAuthenticatedClient(e.Config)
 	if err != nil {
@@ -329,6 +1452,17 @@ func (e *EnvironmentDeleteCommand) Run() error {
 		return fmt.Errorf("invalid environment UUID: %w", err)
 	}
 
+	// Look the environment up first so the user confirms against its name
+	// rather than an opaque ID
+	envs, err := util.GetEnvironments(e.Config)
+	if err != nil {
+		return err
+	}
+	env, err := util.FindEnvironment(*envs, envUUID.String())
+	if err != nil {
+		return err
+	}
+
 	// Display BIG WARNING
 	fmt.Println("╔════════════════════════════════════════════════════════════════════════════╗")
 	fmt.Println("║                            ⚠️  WARNING ⚠️                                    ║")
@@ -352,18 +1486,28 @@ func (e *EnvironmentDeleteCommand) Run() error {
 	fmt.Println("╚════════════════════════════════════════════════════════════════════════════╝")
 	fmt.Println()
 
+	fmt.Printf("Environment: %s (%s)\n", env.Name, env.Slug)
+	fmt.Printf("ID:          %s\n", env.ID)
+
+	usersResp, err := client.ListEnvironmentUsers(ctx, api.ListEnvironmentUsersParams{EnvironmentID: env.ID})
+	if users, ok := usersResp.(*api.ListEnvironmentUsersOKApplicationJSON); err == nil && ok {
+		fmt.Printf("Users:       %d\n", len(*users))
+	} else {
+		fmt.Println("Users:       unknown")
+	}
+	fmt.Println()
+
 	// Prompt for confirmation unless -y flag is used
 	if !e.Confirm {
-		fmt.Printf("Environment ID: %s\n\n", e.EnvironmentID)
-		fmt.Print("Type 'DELETE' (all caps) to confirm deletion: ")
+		fmt.Printf("Type the environment name (%s) or slug (%s) to confirm deletion: ", env.Name, env.Slug)
 
-		var confirmation string
-		_, err := fmt.Scanln(&confirmation)
-		if err != nil {
+		reader := bufio.NewReader(os.Stdin)
+		confirmation, err := reader.ReadString('\n')
+		if err != nil && confirmation == "" {
 			return fmt.Errorf("failed to read confirmation: %w", err)
 		}
 
-		if confirmation != "DELETE" {
+		if !confirmsEnvironmentDeletion(confirmation, env) {
 			fmt.Println("❌ Deletion cancelled.")
 			return nil
 		}
@@ -393,3 +1537,352 @@ func (e *EnvironmentDeleteCommand) Run() error {
 		return fmt.Errorf("unexpected response when deleting environment")
 	}
 }
+
+// parseNamespaceMappings parses source=target namespace pairs
+func parseNamespaceMappings(pairs []string) (map[string]string, error) {
+	mapping := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		source, target, ok := strings.Cut(pair, "=")
+		if !ok || source == "" || target == "" {
+			return nil, fmt.Errorf("invalid namespace mapping '%s' (expected source=target)", pair)
+		}
+		mapping[source] = target
+	}
+	return mapping, nil
+}
+
+// relationEntityKey normalizes an entity reference of the form
+// [entity://]<group>/<version>/<kind>/<namespace>/<name> for comparison
+func relationEntityKey(id string) string {
+	return strings.ToLower(strings.TrimPrefix(id, "entity://"))
+}
+
+// filteredEntityRef returns the apiVersion/kind/namespace/name reference of
+// an entity, normalized like relation endpoints
+func filteredEntityRef(entity FilteredEntity) string {
+	metadata, _ := entity.Metadata.(map[string]interface{})
+	namespace, _ := metadata["namespace"].(string)
+	name, _ := metadata["name"].(string)
+	return relationEntityKey(fmt.Sprintf("%s/%s/%s/%s", entity.ApiVersion, entity.Kind, namespace, name))
+}
+
+// catalogRelationsWithin returns the relations whose source and target are
+// both among the given entities, so a filtered clone doesn't create relations
+// to entities that don't exist in the target
+func catalogRelationsWithin(relations []FilteredEntityRelation, entities []FilteredEntity) []FilteredEntityRelation {
+	keys := make(map[string]bool, len(entities))
+	for _, entity := range entities {
+		keys[filteredEntityRef(entity)] = true
+	}
+
+	var within []FilteredEntityRelation
+	for _, rel := range relations {
+		if keys[relationEntityKey(rel.Source)] && keys[relationEntityKey(rel.Target)] {
+			within = append(within, rel)
+		}
+	}
+	return within
+}
+
+// remapRelationNamespace rewrites the namespace segment of a relation
+// endpoint using mapping
+func remapRelationNamespace(id string, mapping map[string]string) string {
+	prefix := ""
+	if strings.HasPrefix(id, "entity://") {
+		prefix = "entity://"
+	}
+	parts := strings.Split(strings.TrimPrefix(id, prefix), "/")
+	if len(parts) != 5 {
+		return id
+	}
+	if target, ok := mapping[parts[3]]; ok {
+		parts[3] = target
+	}
+	return prefix + strings.Join(parts, "/")
+}
+
+// remapCatalogNamespaces moves entities and relations between namespaces
+// according to mapping, in place
+func remapCatalogNamespaces(entities []FilteredEntity, relations []FilteredEntityRelation, mapping map[string]string) {
+	if len(mapping) == 0 {
+		return
+	}
+	for _, entity := range entities {
+		metadata, ok := entity.Metadata.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if namespace, ok := metadata["namespace"].(string); ok {
+			if target, ok := mapping[namespace]; ok {
+				metadata["namespace"] = target
+			}
+		}
+	}
+	for i := range relations {
+		if target, ok := mapping[relations[i].Namespace]; ok {
+			relations[i].Namespace = target
+		}
+		relations[i].Source = remapRelationNamespace(relations[i].Source, mapping)
+		relations[i].Target = remapRelationNamespace(relations[i].Target, mapping)
+	}
+}
+
+// fetchCatalogEntities reads every entity matching params, along with the
+// relations between them when params includes relations
+func fetchCatalogEntities(ctx context.Context, client *api.Client, params api.GetEntitiesParams) ([]FilteredEntity, []FilteredEntityRelation, error) {
+	all, allRelations, err := devgraph.ListAllEntities(ctx, client, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var entities []FilteredEntity
+	for _, entity := range all {
+		entities = append(entities, devgraph.NewEntity(entity))
+	}
+	var relations []FilteredEntityRelation
+	for _, rel := range allRelations {
+		relations = append(relations, devgraph.NewEntityRelation(rel))
+	}
+	return entities, relations, nil
+}
+
+func (e *EnvironmentCloneCommand) Run() error {
+	e.Config.ApplyDefaults()
+
+	mapping, err := parseNamespaceMappings(e.MapNamespace)
+	if err != nil {
+		return err
+	}
+
+	envs, err := util.GetEnvironments(e.Config)
+	if err != nil {
+		return err
+	}
+	source, err := util.FindEnvironment(*envs, e.Source)
+	if err != nil {
+		return fmt.Errorf("invalid source environment: %w", err)
+	}
+	target, err := util.FindEnvironment(*envs, e.Target)
+	if err != nil {
+		return fmt.Errorf("invalid target environment: %w", err)
+	}
+	if source.ID == target.ID {
+		return fmt.Errorf("source and target environments are the same")
+	}
+
+	sourceConfig := e.Config
+	sourceConfig.EnvOverride = source.ID.String()
+	sourceClient, err := util.GetAuthenticatedClient(sourceConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	var definitions []FilteredEntityDefinition
+	if !e.SkipDefinitions {
+		defResp, err := sourceClient.GetEntityDefinitions(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get entity definitions: %w", err)
+		}
+		switch r := defResp.(type) {
+		case *api.GetEntityDefinitionsOKApplicationJSON:
+			for _, def := range *r {
+				definitions = append(definitions, devgraph.NewEntityDefinition(def))
+			}
+		case *api.GetEntityDefinitionsNotFound:
+		default:
+			return fmt.Errorf("unexpected response type for definitions: %T", defResp)
+		}
+	}
+
+	params := api.GetEntitiesParams{
+		IncludeRelations: api.NewOptBool(!e.SkipRelations),
+	}
+	if e.Name != "" {
+		params.Name = api.NewOptString(e.Name)
+	}
+	if e.Label != "" {
+		params.Label = api.NewOptString(e.Label)
+	}
+	if e.FieldSelector != "" {
+		params.FieldSelector = api.NewOptString(e.FieldSelector)
+	}
+
+	entities, relations, err := fetchCatalogEntities(ctx, sourceClient, params)
+	if err != nil {
+		return err
+	}
+
+	relations = catalogRelationsWithin(relations, entities)
+	remapCatalogNamespaces(entities, relations, mapping)
+
+	fmt.Printf("Cloning from '%s' (%s) to '%s' (%s)\n", source.Name, source.Slug, target.Name, target.Slug)
+
+	targetConfig := e.Config
+	targetConfig.EnvOverride = target.ID.String()
+	targetClient, err := util.GetAuthenticatedClient(targetConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	if err := checkQuota(targetClient, "entities", int64(len(entities)), e.EnforceLimits); err != nil {
+		return err
+	}
+
+	if e.DryRun {
+		printCatalogPlan("clone", definitions, entities, relations)
+		return nil
+	}
+
+	return restoreCatalog(ctx, targetClient, definitions, nil, entities, relations, e.Config.WorkerCount(e.Workers))
+}
+
+// environmentSettings are the settings of an environment that can be
+// managed from the CLI
+type environmentSettings struct {
+	DiscoveryEnabled bool   `json:"discovery_enabled" yaml:"discovery_enabled"`
+	DiscoveryImageID string `json:"discovery_image_id,omitempty" yaml:"discovery_image_id,omitempty"`
+}
+
+// environmentSettingKeys are the names accepted by env settings get and set
+var environmentSettingKeys = []string{"discovery_enabled", "discovery_image_id"}
+
+// values returns the settings by name, as shown by env settings get
+func (s environmentSettings) values() map[string]string {
+	return map[string]string{
+		"discovery_enabled":  strconv.FormatBool(s.DiscoveryEnabled),
+		"discovery_image_id": util.OrDash(s.DiscoveryImageID),
+	}
+}
+
+// newEnvironmentSettings converts the discovery settings returned by the API
+func newEnvironmentSettings(r *api.EnvironmentDiscoverySettingsResponse) environmentSettings {
+	settings := environmentSettings{DiscoveryEnabled: r.DiscoveryEnabled}
+	if imageID, ok := r.DiscoveryImageID.Get(); ok {
+		settings.DiscoveryImageID = imageID.String()
+	}
+	return settings
+}
+
+// parseEnvironmentSettings builds a settings update from key=value pairs.
+// An empty or "null" discovery_image_id clears the image.
+func parseEnvironmentSettings(pairs []string) (*api.EnvironmentDiscoverySettingsUpdate, error) {
+	update := &api.EnvironmentDiscoverySettingsUpdate{}
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid setting '%s', expected 'key=value'", pair)
+		}
+		switch strings.TrimSpace(key) {
+		case "discovery_enabled":
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid discovery_enabled '%s': expected true or false", value)
+			}
+			update.DiscoveryEnabled = api.NewOptNilBool(enabled)
+		case "discovery_image_id":
+			if value == "" || value == "null" {
+				update.DiscoveryImageID.SetToNull()
+				continue
+			}
+			imageID, err := uuid.Parse(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid discovery_image_id: %w", err)
+			}
+			update.DiscoveryImageID = api.NewOptNilUUID(imageID)
+		default:
+			return nil, fmt.Errorf("unknown setting '%s' (one of: %s)", key, strings.Join(environmentSettingKeys, ", "))
+		}
+	}
+	return update, nil
+}
+
+func (e *EnvironmentSettingsGetCommand) Run() error {
+	envUUID, err := defaultEnvironmentUUID(e.Config)
+	if err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.GetEnvironmentDiscoverySettings(context.Background(), api.GetEnvironmentDiscoverySettingsParams{EnvID: envUUID})
+	if err != nil {
+		return fmt.Errorf("failed to get environment settings: %w", err)
+	}
+
+	var settings environmentSettings
+	switch r := resp.(type) {
+	case *api.EnvironmentDiscoverySettingsResponse:
+		settings = newEnvironmentSettings(r)
+	case *api.GetEnvironmentDiscoverySettingsNotFound:
+		return fmt.Errorf("environment not found")
+	default:
+		return fmt.Errorf("unexpected response type: %T", resp)
+	}
+
+	values := settings.values()
+	if e.Key != "" {
+		value, ok := values[e.Key]
+		if !ok {
+			return fmt.Errorf("unknown setting '%s' (one of: %s)", e.Key, strings.Join(environmentSettingKeys, ", "))
+		}
+		if format := e.OutputFormat(output.Table); format != output.Table {
+			return output.Print(format, output.Result{Data: map[string]string{e.Key: value}})
+		}
+		fmt.Println(value)
+		return nil
+	}
+
+	tableData := make([]map[string]any, len(environmentSettingKeys))
+	for i, key := range environmentSettingKeys {
+		tableData[i] = map[string]any{
+			"Setting": key,
+			"Value":   values[key],
+		}
+	}
+
+	return output.Print(e.OutputFormat(output.Table), output.Result{
+		Data:    settings,
+		Headers: []string{"Setting", "Value"},
+		Rows:    tableData,
+	})
+}
+
+func (e *EnvironmentSettingsSetCommand) Run() error {
+	update, err := parseEnvironmentSettings(e.Settings)
+	if err != nil {
+		return err
+	}
+
+	envUUID, err := defaultEnvironmentUUID(e.Config)
+	if err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.UpdateEnvironmentDiscoverySettings(context.Background(), update, api.UpdateEnvironmentDiscoverySettingsParams{EnvID: envUUID})
+	if err != nil {
+		return fmt.Errorf("failed to update environment settings: %w", err)
+	}
+
+	switch r := resp.(type) {
+	case *api.EnvironmentDiscoverySettingsResponse:
+		fmt.Printf("✅ Updated %d environment setting(s) successfully.\n", len(e.Settings))
+		return nil
+	case *api.UpdateEnvironmentDiscoverySettingsNotFound:
+		return fmt.Errorf("environment not found")
+	case *api.HTTPValidationError:
+		return fmt.Errorf("validation error: %v", r.Detail)
+	default:
+		return fmt.Errorf("unexpected response type: %T", resp)
+	}
+}
@@ -0,0 +1,249 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
+	"github.com/arctir/devgraph-cli/pkg/output"
+	"github.com/arctir/devgraph-cli/pkg/util"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+	"github.com/fatih/color"
+)
+
+// EnvironmentDiffCommand compares the catalogs of two environments, as a
+// check before promoting changes from one to the other
+type EnvironmentDiffCommand struct {
+	config.Config
+	Source        string `arg:"" required:"" help:"Environment UUID, slug, or name to compare from"`
+	Target        string `arg:"" required:"" help:"Environment UUID, slug, or name to compare with"`
+	Name          string `flag:"name,n" help:"Only compare entities with this name."`
+	Label         string `flag:"label,l" help:"Only compare entities matching this label selector."`
+	FieldSelector string `flag:"field-selector,f" help:"Only compare entities matching this field selector."`
+}
+
+// environmentDiff is how the target environment's catalog differs from the
+// source's
+type environmentDiff struct {
+	Source      string      `json:"source" yaml:"source"`
+	Target      string      `json:"target" yaml:"target"`
+	Definitions catalogDiff `json:"definitions" yaml:"definitions"`
+	Entities    catalogDiff `json:"entities" yaml:"entities"`
+	Relations   catalogDiff `json:"relations" yaml:"relations"`
+}
+
+// catalogDiff lists the items only in the target (added), only in the
+// source (removed), and in both with different fields (changed)
+type catalogDiff struct {
+	Added   []string        `json:"added" yaml:"added"`
+	Removed []string        `json:"removed" yaml:"removed"`
+	Changed []catalogChange `json:"changed" yaml:"changed"`
+}
+
+// catalogChange is an item whose fields differ between environments
+type catalogChange struct {
+	ID     string               `json:"id" yaml:"id"`
+	Fields []catalogFieldChange `json:"fields" yaml:"fields"`
+}
+
+// catalogFieldChange is a field's value in each environment. A nil value
+// means the field isn't set there.
+type catalogFieldChange struct {
+	Field  string `json:"field" yaml:"field"`
+	Source any    `json:"source,omitempty" yaml:"source,omitempty"`
+	Target any    `json:"target,omitempty" yaml:"target,omitempty"`
+}
+
+// environmentCatalog is an environment's catalog keyed by ID, with the
+// documents compared for each definition and entity
+type environmentCatalog struct {
+	definitions map[string]any
+	entities    map[string]any
+	relations   map[string]any
+}
+
+func (e *EnvironmentDiffCommand) Run() error {
+	e.Config.ApplyDefaults()
+
+	// The diff is printed as text rather than a table
+	format := e.OutputFormat("text")
+	if err := output.Check(format, "text", output.JSON, output.YAML); err != nil {
+		return err
+	}
+
+	envs, err := util.GetEnvironments(e.Config)
+	if err != nil {
+		return err
+	}
+	source, err := util.FindEnvironment(*envs, e.Source)
+	if err != nil {
+		return fmt.Errorf("invalid source environment: %w", err)
+	}
+	target, err := util.FindEnvironment(*envs, e.Target)
+	if err != nil {
+		return fmt.Errorf("invalid target environment: %w", err)
+	}
+	if source.ID == target.ID {
+		return fmt.Errorf("source and target environments are the same")
+	}
+
+	params := api.GetEntitiesParams{IncludeRelations: api.NewOptBool(true)}
+	if e.Name != "" {
+		params.Name = api.NewOptString(e.Name)
+	}
+	if e.Label != "" {
+		params.Label = api.NewOptString(e.Label)
+	}
+	if e.FieldSelector != "" {
+		params.FieldSelector = api.NewOptString(e.FieldSelector)
+	}
+
+	sourceCatalog, err := e.fetchCatalog(source.ID.String(), params)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", source.Name, err)
+	}
+	targetCatalog, err := e.fetchCatalog(target.ID.String(), params)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", target.Name, err)
+	}
+
+	diff := environmentDiff{Source: source.Slug, Target: target.Slug}
+	if diff.Definitions, err = diffCatalogItems(sourceCatalog.definitions, targetCatalog.definitions); err != nil {
+		return err
+	}
+	if diff.Entities, err = diffCatalogItems(sourceCatalog.entities, targetCatalog.entities); err != nil {
+		return err
+	}
+	if diff.Relations, err = diffCatalogItems(sourceCatalog.relations, targetCatalog.relations); err != nil {
+		return err
+	}
+
+	if format != "text" {
+		return output.Print(format, output.Result{Data: diff})
+	}
+	fmt.Printf("Comparing '%s' (%s) to '%s' (%s)\n", source.Name, source.Slug, target.Name, target.Slug)
+	printCatalogDiff("Definitions", diff.Definitions)
+	printCatalogDiff("Entities", diff.Entities)
+	printCatalogDiff("Relations", diff.Relations)
+	return nil
+}
+
+// fetchCatalog reads the definitions, entities, and relations of the
+// environment with the given UUID
+func (e *EnvironmentDiffCommand) fetchCatalog(environmentID string, params api.GetEntitiesParams) (*environmentCatalog, error) {
+	cfg := e.Config
+	cfg.EnvOverride = environmentID
+	client, err := util.GetAuthenticatedClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	ctx := context.Background()
+	definitions, err := listEntityDefinitions(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	entities, relations, err := fetchCatalogEntities(ctx, client, params)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := &environmentCatalog{
+		definitions: map[string]any{},
+		entities:    map[string]any{},
+		relations:   map[string]any{},
+	}
+	for _, def := range definitions {
+		filtered := devgraph.NewEntityDefinition(def)
+		version := filtered.Name
+		if version == "" {
+			version = "v1"
+		}
+		catalog.definitions[fmt.Sprintf("%s/%s/%s", filtered.Group, version, filtered.Kind)] = filtered
+	}
+	for _, entity := range entities {
+		// Status is reported by each environment, so it isn't compared
+		entity.Status = nil
+		catalog.entities[filteredEntityRef(entity)] = entity
+	}
+	for _, rel := range catalogRelationsWithin(relations, entities) {
+		ref := fmt.Sprintf("%s %s -> %s", rel.Relation, relationEntityKey(rel.Source), relationEntityKey(rel.Target))
+		catalog.relations[ref] = nil
+	}
+	return catalog, nil
+}
+
+// diffCatalogItems compares the items of two environments by ID. Items in
+// both are compared field by field.
+func diffCatalogItems(source, target map[string]any) (catalogDiff, error) {
+	diff := catalogDiff{Added: []string{}, Removed: []string{}, Changed: []catalogChange{}}
+	for id, targetItem := range target {
+		sourceItem, ok := source[id]
+		if !ok {
+			diff.Added = append(diff.Added, id)
+			continue
+		}
+		if sourceItem == nil && targetItem == nil {
+			continue
+		}
+
+		sourceFields, err := flattenFields(sourceItem)
+		if err != nil {
+			return diff, err
+		}
+		targetFields, err := flattenFields(targetItem)
+		if err != nil {
+			return diff, err
+		}
+		// diffFields reports target values as local and source values as
+		// remote, as if the target were being applied over the source
+		if changes := diffFields(targetFields, sourceFields); len(changes) > 0 {
+			change := catalogChange{ID: id}
+			for _, c := range changes {
+				change.Fields = append(change.Fields, catalogFieldChange{Field: c.Field, Source: c.Remote, Target: c.Local})
+			}
+			diff.Changed = append(diff.Changed, change)
+		}
+	}
+	for id := range source {
+		if _, ok := target[id]; !ok {
+			diff.Removed = append(diff.Removed, id)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].ID < diff.Changed[j].ID })
+	return diff, nil
+}
+
+// printCatalogDiff prints one section of an environment diff in the style
+// of 'dg entity-definition diff'
+func printCatalogDiff(title string, diff catalogDiff) {
+	addedColor := color.New(color.FgGreen)
+	removedColor := color.New(color.FgRed)
+	changedColor := color.New(color.FgYellow)
+
+	fmt.Printf("\n%s: %d added, %d removed, %d changed\n", title, len(diff.Added), len(diff.Removed), len(diff.Changed))
+	for _, id := range diff.Added {
+		fmt.Print(addedColor.Sprintf("+ %s\n", id))
+	}
+	for _, id := range diff.Removed {
+		fmt.Print(removedColor.Sprintf("- %s\n", id))
+	}
+	for _, change := range diff.Changed {
+		fmt.Print(changedColor.Sprintf("~ %s: %d field(s) differ\n", change.ID, len(change.Fields)))
+		for _, c := range change.Fields {
+			switch {
+			case c.Source == nil:
+				fmt.Print(addedColor.Sprintf("    + %s: %s\n", c.Field, diffValue(c.Target)))
+			case c.Target == nil:
+				fmt.Print(removedColor.Sprintf("    - %s: %s\n", c.Field, diffValue(c.Source)))
+			default:
+				fmt.Printf("    ~ %s: %s -> %s\n", c.Field, diffValue(c.Source), diffValue(c.Target))
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testStagingEnvironmentID = "22222222-2222-2222-2222-222222222222"
+
+// serveEnvironmentCatalogs serves a catalog for production and staging,
+// chosen by the Devgraph-Environment header
+func serveEnvironmentCatalogs(srv *testAPI) {
+	staging := testEnvironment()
+	staging["id"] = testStagingEnvironmentID
+	staging["name"] = "Staging"
+	staging["slug"] = "staging"
+	srv.handle("GET /api/v1/environments", http.StatusOK, []map[string]any{testEnvironment(), staging})
+
+	srv.mux.HandleFunc("GET /api/v1/entities/definitions", func(w http.ResponseWriter, r *http.Request) {
+		definitions := []map[string]any{testDefinition("core", "Service", map[string]any{"type": "object"})}
+		if r.Header.Get("Devgraph-Environment") == testStagingEnvironmentID {
+			definitions[0]["description"] = "A deployable service"
+			definitions = append(definitions, testDefinition("core", "Team", map[string]any{"type": "object"}))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(definitions)
+	})
+
+	srv.mux.HandleFunc("GET /api/v1/entities/", func(w http.ResponseWriter, r *http.Request) {
+		web := testEntity("web")
+		api := testEntity("api")
+		db := testEntity("db")
+		entities := []any{web, api, testEntity("legacy")}
+		relations := []any{testRelation("DEPENDS_ON", "web", "api")}
+		if r.Header.Get("Devgraph-Environment") == testStagingEnvironmentID {
+			api["spec"] = map[string]any{"owner": "payments"}
+			api["status"] = map[string]any{"phase": "Ready"}
+			entities = []any{web, api, db}
+			relations = append(relations, testRelation("DEPENDS_ON", "api", "db"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"primary_entities": entities, "relations": relations})
+	})
+}
+
+func TestEnvironmentDiffCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	serveEnvironmentCatalogs(srv)
+
+	cfg.Output = "json"
+	cmd := EnvironmentDiffCommand{Config: cfg, Source: "production", Target: "staging", Label: "team=payments"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	var diff environmentDiff
+	require.NoError(t, json.Unmarshal([]byte(output), &diff))
+	assert.Equal(t, "production", diff.Source)
+	assert.Equal(t, "staging", diff.Target)
+
+	assert.Equal(t, []string{"core/v1/Team"}, diff.Definitions.Added)
+	assert.Empty(t, diff.Definitions.Removed)
+	require.Len(t, diff.Definitions.Changed, 1)
+	assert.Equal(t, catalogFieldChange{Field: "description", Source: "A service", Target: "A deployable service"}, diff.Definitions.Changed[0].Fields[0])
+
+	assert.Equal(t, []string{"core/v1/service/default/db"}, diff.Entities.Added)
+	assert.Equal(t, []string{"core/v1/service/default/legacy"}, diff.Entities.Removed)
+	// Status differs too, but each environment reports its own
+	require.Len(t, diff.Entities.Changed, 1)
+	assert.Equal(t, catalogChange{
+		ID:     "core/v1/service/default/api",
+		Fields: []catalogFieldChange{{Field: "spec.owner", Target: "payments"}},
+	}, diff.Entities.Changed[0])
+
+	assert.Equal(t, []string{"DEPENDS_ON core/v1/service/default/api -> core/v1/service/default/db"}, diff.Relations.Added)
+	assert.Empty(t, diff.Relations.Removed)
+
+	// Each environment's catalog is read with its own header and the filters
+	var environments []string
+	for _, request := range srv.received(http.MethodGet, "/api/v1/entities/") {
+		environments = append(environments, request.Header.Get("Devgraph-Environment"))
+		assert.Contains(t, request.Query, "label=team%3Dpayments")
+		assert.Contains(t, request.Query, "include_relations=true")
+	}
+	assert.Equal(t, []string{testEnvironmentID, testStagingEnvironmentID}, environments)
+}
+
+func TestEnvironmentDiffCommand_Text(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	serveEnvironmentCatalogs(srv)
+
+	cfg.Output = "text"
+	cmd := EnvironmentDiffCommand{Config: cfg, Source: "production", Target: "staging"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Comparing 'Production' (production) to 'Staging' (staging)")
+	assert.Contains(t, output, "Definitions: 1 added, 0 removed, 1 changed")
+	assert.Contains(t, output, "    ~ description: A service -> A deployable service")
+	assert.Contains(t, output, "Entities: 1 added, 1 removed, 1 changed")
+	assert.Contains(t, output, "- core/v1/service/default/legacy")
+	assert.Contains(t, output, "    + spec.owner: payments")
+	assert.Contains(t, output, "Relations: 1 added, 0 removed, 0 changed")
+}
+
+func TestEnvironmentDiffCommand_SameEnvironment(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	serveEnvironmentCatalogs(srv)
+
+	cmd := EnvironmentDiffCommand{Config: cfg, Source: "production", Target: testEnvironmentID}
+	_, err := captureOutput(t, cmd.Run)
+	assert.ErrorContains(t, err, "source and target environments are the same")
+}
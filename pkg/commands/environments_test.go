@@ -0,0 +1,531 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
+	"github.com/arctir/devgraph-cli/pkg/util"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNamespaceMappings(t *testing.T) {
+	mapping, err := parseNamespaceMappings([]string{"default=staging", "team-a=team-b"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"default": "staging", "team-a": "team-b"}, mapping)
+
+	_, err = parseNamespaceMappings([]string{"default"})
+	assert.Error(t, err)
+	_, err = parseNamespaceMappings([]string{"=staging"})
+	assert.Error(t, err)
+}
+
+func TestCloneCatalogHelpers(t *testing.T) {
+	entities := []FilteredEntity{
+		{ApiVersion: "core/v1", Kind: "Service", Metadata: map[string]interface{}{"namespace": "default", "name": "api"}},
+		{ApiVersion: "core/v1", Kind: "Team", Metadata: map[string]interface{}{"namespace": "default", "name": "platform"}},
+	}
+	relations := []FilteredEntityRelation{
+		{Namespace: "default", Relation: "ownedBy", Source: "core/v1/Service/default/api", Target: "core/v1/Team/default/platform"},
+		{Namespace: "default", Relation: "dependsOn", Source: "core/v1/Service/default/api", Target: "core/v1/Service/default/db"},
+	}
+
+	within := catalogRelationsWithin(relations, entities)
+	require.Len(t, within, 1, "relations to entities outside the clone are dropped")
+	assert.Equal(t, "ownedBy", within[0].Relation)
+
+	remapCatalogNamespaces(entities, within, map[string]string{"default": "staging"})
+	assert.Equal(t, "staging", entities[0].Metadata.(map[string]interface{})["namespace"])
+	assert.Equal(t, "staging", within[0].Namespace)
+	assert.Equal(t, "core/v1/Service/staging/api", within[0].Source)
+	assert.Equal(t, "core/v1/Team/staging/platform", within[0].Target)
+
+	assert.Equal(t, "entity://core/v1/Team/ops/x", remapRelationNamespace("entity://core/v1/Team/dev/x", map[string]string{"dev": "ops"}))
+}
+
+func TestConfirmsEnvironmentDeletion(t *testing.T) {
+	env := &api.EnvironmentResponse{Name: "Production", Slug: "prod"}
+
+	assert.True(t, confirmsEnvironmentDeletion("Production\n", env))
+	assert.True(t, confirmsEnvironmentDeletion("  prod  ", env))
+	assert.False(t, confirmsEnvironmentDeletion("DELETE", env))
+	assert.False(t, confirmsEnvironmentDeletion("production", env))
+	assert.False(t, confirmsEnvironmentDeletion("", &api.EnvironmentResponse{}))
+}
+
+func TestParseUserInvites(t *testing.T) {
+	input := "email,role\n" +
+		"alice@example.com,admin\n" +
+		"\n" +
+		"# contractors\n" +
+		"bob@example.com\n" +
+		"carol@example.com, \n"
+
+	invites, err := parseUserInvites(strings.NewReader(input), "member")
+	require.NoError(t, err)
+	assert.Equal(t, []userInvite{
+		{Line: 2, Email: "alice@example.com", Role: "admin"},
+		{Line: 5, Email: "bob@example.com", Role: "member"},
+		{Line: 6, Email: "carol@example.com", Role: "member"},
+	}, invites)
+
+	_, err = parseUserInvites(strings.NewReader("not-an-email,admin\n"), "member")
+	assert.Error(t, err)
+
+	_, err = parseUserInvites(strings.NewReader("a@example.com,admin,extra\n"), "member")
+	assert.Error(t, err)
+}
+
+func TestEnvironmentCreateCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/subscriptions", http.StatusOK, []map[string]any{
+		{"id": "55555555-5555-5555-5555-555555555555", "stripe_subscription_id": "sub_123", "status": "active"},
+	})
+	srv.handle("POST /api/v1/environments", http.StatusCreated, map[string]any{
+		"id":                    "66666666-6666-6666-6666-666666666666",
+		"name":                  "Staging",
+		"slug":                  "staging",
+		"clerk_organization_id": "org_1",
+		"customer_id":           "cus_1",
+		"subscription_id":       "55555555-5555-5555-5555-555555555555",
+	})
+
+	cmd := EnvironmentCreateCommand{
+		Config: cfg,
+		Name:   "Staging",
+		Invite: []string{"dev@example.com"},
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	body := srv.requireRequest(http.MethodPost, "/api/v1/environments").JSON(t)
+	assert.Equal(t, "Staging", body["name"])
+	assert.Equal(t, "sub_123", body["stripe_subscription_id"])
+	assert.Equal(t, cfg.ApiURL, body["instance_url"])
+	assert.Equal(t, []any{"dev@example.com"}, body["invited_users"])
+	assert.Contains(t, output, "66666666-6666-6666-6666-666666666666")
+}
+
+func TestEnvironmentCreateCommand_AmbiguousSubscription(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/subscriptions", http.StatusOK, []map[string]any{
+		{"id": "55555555-5555-5555-5555-555555555555", "stripe_subscription_id": "sub_1", "status": "active"},
+		{"id": "77777777-7777-7777-7777-777777777777", "stripe_subscription_id": "sub_2", "status": "active"},
+	})
+
+	cmd := EnvironmentCreateCommand{Config: cfg, Name: "Staging"}
+	_, err := captureOutput(t, cmd.Run)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--subscription")
+	assert.Empty(t, srv.received(http.MethodPost, "/api/v1/environments"))
+
+	cmd.Subscription = "sub_2"
+	_, err = captureOutput(t, cmd.Run)
+	require.Error(t, err, "no create handler is registered")
+	body := srv.requireRequest(http.MethodPost, "/api/v1/environments").JSON(t)
+	assert.Equal(t, "sub_2", body["stripe_subscription_id"])
+}
+
+// testEntity returns an entity as returned by the entities API
+func testEntity(name string) map[string]any {
+	return map[string]any{
+		"apiVersion": "core/v1",
+		"kind":       "Service",
+		"metadata":   map[string]any{"name": name, "namespace": "default"},
+		"id":         "core/v1/service/default/" + name,
+		"plural":     "services",
+		"group":      "core",
+		"version":    "v1",
+		"name":       name,
+		"namespace":  "default",
+	}
+}
+
+func TestFetchCatalogEntities_Paginates(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	relation := map[string]any{
+		"relation": "DEPENDS_ON",
+		"source":   map[string]any{"apiVersion": "core/v1", "kind": "Service", "name": "svc-0", "id": "core/v1/service/default/svc-0"},
+		"target":   map[string]any{"apiVersion": "core/v1", "kind": "Service", "name": "svc-1000", "id": "core/v1/service/default/svc-1000"},
+	}
+	srv.mux.HandleFunc("GET /api/v1/entities/", func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		entities := []map[string]any{}
+		for i := offset; i < min(offset+devgraph.PageSize, devgraph.PageSize+1); i++ {
+			entities = append(entities, testEntity(fmt.Sprintf("svc-%d", i)))
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"primary_entities": entities,
+			"related_entities": []any{},
+			"relations":        []any{relation},
+		})
+	})
+
+	client, err := util.GetAuthenticatedClient(cfg)
+	require.NoError(t, err)
+	entities, relations, err := fetchCatalogEntities(context.Background(), client, api.GetEntitiesParams{})
+	require.NoError(t, err)
+
+	// The first page is full, so the next batch of pages is requested at once
+	var queries []string
+	for _, request := range srv.received(http.MethodGet, "/api/v1/entities/") {
+		queries = append(queries, request.Query)
+	}
+	assert.ElementsMatch(t, []string{
+		"limit=1000&offset=0",
+		"limit=1000&offset=1000",
+		"limit=1000&offset=2000",
+		"limit=1000&offset=3000",
+		"limit=1000&offset=4000",
+	}, queries)
+	assert.Equal(t, "limit=1000&offset=0", queries[0])
+	assert.Len(t, entities, devgraph.PageSize+1)
+	assert.Len(t, relations, 1, "relations repeated across pages are only cloned once")
+}
+
+// testEnvironment is the environment newTestAPI makes the default, as
+// returned by the environments API
+func testEnvironment() map[string]any {
+	return map[string]any{
+		"id":                    testEnvironmentID,
+		"name":                  "Production",
+		"slug":                  "production",
+		"clerk_organization_id": "org_1",
+		"customer_id":           "cus_1",
+		"subscription_id":       "55555555-5555-5555-5555-555555555555",
+	}
+}
+
+// testEnvironmentUser returns a member or pending invitation as returned by
+// the environment users API
+func testEnvironmentUser(id, email, role, status string) map[string]any {
+	return map[string]any{
+		"id":            id,
+		"email_address": email,
+		"role":          role,
+		"status":        status,
+		"created_at":    1740830400,
+		"updated_at":    1740830400,
+	}
+}
+
+func TestEnvironmentDescribeCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	staging := testEnvironment()
+	staging["id"] = testStagingEnvironmentID
+	staging["name"] = "Staging"
+	staging["slug"] = "staging"
+	srv.handle("GET /api/v1/environments", http.StatusOK, []map[string]any{testEnvironment(), staging})
+	srv.handle("GET /api/v1/environments/"+testStagingEnvironmentID+"/status", http.StatusOK, map[string]any{"status": "ready"})
+	srv.handle("GET /api/v1/subscriptions", http.StatusOK, []map[string]any{{
+		"id":                     "55555555-5555-5555-5555-555555555555",
+		"stripe_subscription_id": "sub_123",
+		"environment_ids":        []string{testStagingEnvironmentID},
+		"status":                 "active",
+		"plan_name":              "Team",
+	}})
+	srv.handle("GET /api/v1/environments/"+testStagingEnvironmentID+"/users", http.StatusOK, []map[string]any{
+		testEnvironmentUser("user_1", "a@example.com", "admin", "active"),
+		testEnvironmentUser("user_2", "b@example.com", "member", "active"),
+	})
+	srv.handle("GET /api/v1/environments/"+testStagingEnvironmentID+"/users/pending", http.StatusOK, []map[string]any{
+		testEnvironmentUser("inv_1", "c@example.com", "member", "pending"),
+	})
+	srv.handle("GET /api/v1/entitlements", http.StatusOK, map[string]any{
+		"entitlements": map[string]any{
+			"entities": map[string]any{"limit_value": 1000, "current_usage": 250},
+			"seats":    map[string]any{"current_usage": 2},
+			"sso":      map[string]any{"enabled": true},
+		},
+	})
+
+	cfg.Output = "json"
+	cmd := EnvironmentDescribeCommand{Config: cfg, Environment: "staging"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	srv.requireRequest(http.MethodGet, "/api/v1/environments/"+testStagingEnvironmentID+"/status")
+	request := srv.requireRequest(http.MethodGet, "/api/v1/entitlements")
+	assert.Equal(t, testStagingEnvironmentID, request.Header.Get("Devgraph-Environment"),
+		"usage should be read for the described environment, not the current one")
+
+	limit := int64(1000)
+	var desc environmentDescription
+	require.NoError(t, json.Unmarshal([]byte(output), &desc))
+	assert.Equal(t, environmentDescription{
+		ID:                 testStagingEnvironmentID,
+		Name:               "Staging",
+		Slug:               "staging",
+		Status:             "ready",
+		Plan:               "Team",
+		SubscriptionStatus: "active",
+		Members:            2,
+		PendingInvitations: 1,
+		Usage: map[string]usageMetric{
+			"entities": {Used: 250, Limit: &limit},
+			"seats":    {Used: 2},
+		},
+	}, desc)
+
+	cmd.Output = "table"
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "entities:    250 / 1000")
+	assert.Contains(t, output, "seats:       2 / unlimited")
+}
+
+func TestEnvironmentDeleteCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/environments", http.StatusOK, []map[string]any{testEnvironment()})
+	srv.handle("GET /api/v1/environments/"+testEnvironmentID+"/users", http.StatusOK, []map[string]any{
+		testEnvironmentUser("user_1", "a@example.com", "admin", "active"),
+	})
+	srv.handle("DELETE /api/v1/environments/"+testEnvironmentID, http.StatusNoContent, nil)
+
+	cmd := EnvironmentDeleteCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, EnvironmentID: testEnvironmentID, Confirm: true}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	srv.requireRequest(http.MethodDelete, "/api/v1/environments/"+testEnvironmentID)
+	assert.Contains(t, output, "Users:       1")
+	assert.Contains(t, output, "marked for deletion")
+}
+
+func TestEnvironmentSettingsGetCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/environments/"+testEnvironmentID+"/discovery-settings", http.StatusOK, map[string]any{
+		"discovery_enabled":  true,
+		"discovery_image_id": "33333333-3333-3333-3333-333333333333",
+	})
+
+	cmd := EnvironmentSettingsGetCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Key: "discovery_image_id"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Equal(t, "33333333-3333-3333-3333-333333333333\n", output)
+
+	cmd.Key = "retention"
+	_, err = captureOutput(t, cmd.Run)
+	assert.ErrorContains(t, err, "unknown setting 'retention'")
+}
+
+func TestEnvironmentSettingsSetCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("PATCH /api/v1/environments/"+testEnvironmentID+"/discovery-settings", http.StatusOK, map[string]any{
+		"discovery_enabled":  false,
+		"discovery_image_id": nil,
+	})
+
+	cmd := EnvironmentSettingsSetCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Settings:          []string{"discovery_enabled=false", "discovery_image_id=null"},
+	}
+	_, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	body := srv.requireRequest(http.MethodPatch, "/api/v1/environments/"+testEnvironmentID+"/discovery-settings").JSON(t)
+	assert.Equal(t, map[string]any{"discovery_enabled": false, "discovery_image_id": nil}, body)
+
+	cmd.Settings = []string{"discovery_enabled=maybe"}
+	_, err = captureOutput(t, cmd.Run)
+	assert.ErrorContains(t, err, "invalid discovery_enabled")
+	assert.Len(t, srv.received(http.MethodPatch, "/api/v1/environments/"+testEnvironmentID+"/discovery-settings"), 1)
+}
+
+func TestEnvironmentUserUpdateRoleCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/environments/"+testEnvironmentID+"/users", http.StatusOK, []map[string]any{
+		testEnvironmentUser("user_1", "a@example.com", "member", "active"),
+	})
+	srv.handle("PUT /api/v1/environments/"+testEnvironmentID+"/users/user_1", http.StatusOK,
+		testEnvironmentUser("user_1", "a@example.com", "admin", "active"))
+
+	cmd := EnvironmentUserUpdateRoleCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		User:              "A@example.com",
+		Role:              "admin",
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	body := srv.requireRequest(http.MethodPut, "/api/v1/environments/"+testEnvironmentID+"/users/user_1").JSON(t)
+	assert.Equal(t, map[string]any{"role": "admin"}, body)
+	assert.Contains(t, output, "from 'member' to 'admin'")
+
+	cmd.Role = "owner"
+	_, err = captureOutput(t, cmd.Run)
+	assert.ErrorContains(t, err, "invalid role 'owner'")
+}
+
+func TestEnvironmentUserAddCommand_FromFile(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("POST /api/v1/environments/"+testEnvironmentID+"/users/bulk-invite", http.StatusCreated, []map[string]any{
+		testEnvironmentUser("inv_1", "a@example.com", "admin", "pending"),
+	})
+
+	file := filepath.Join(t.TempDir(), "users.csv")
+	require.NoError(t, os.WriteFile(file, []byte("email,role\na@example.com,admin\nb@example.com\n"), 0o600))
+
+	cmd := EnvironmentUserAddCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Role:              "member",
+		FromFile:          file,
+	}
+	output, err := captureOutput(t, cmd.Run)
+	assert.EqualError(t, err, "1 invitations failed")
+
+	body := srv.requireRequest(http.MethodPost, "/api/v1/environments/"+testEnvironmentID+"/users/bulk-invite").JSON(t)
+	assert.Equal(t, []any{
+		map[string]any{"email_address": "a@example.com", "role": "admin"},
+		map[string]any{"email_address": "b@example.com", "role": "member"},
+	}, body["invitations"])
+	assert.Contains(t, output, "✅ Line 2: invited 'a@example.com' with role 'admin'")
+	assert.Contains(t, output, "❌ Line 3: 'b@example.com' was not invited")
+}
+
+func TestEnvironmentUserAddCommand_FromFileInvalidRole(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+
+	file := filepath.Join(t.TempDir(), "users.csv")
+	require.NoError(t, os.WriteFile(file, []byte("a@example.com,owner\n"), 0o600))
+
+	cmd := EnvironmentUserAddCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Role:              "member",
+		FromFile:          file,
+	}
+	_, err := captureOutput(t, cmd.Run)
+	assert.EqualError(t, err, "line 1: invalid role 'owner' (one of: member, admin)")
+	assert.Empty(t, srv.received(http.MethodPost, "/api/v1/environments/"+testEnvironmentID+"/users/bulk-invite"))
+}
+
+func TestEnvironmentInviteRevokeCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("DELETE /api/v1/environments/"+testEnvironmentID+"/users/invitations/inv_1", http.StatusNoContent, nil)
+
+	cmd := EnvironmentInviteRevokeCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, InvitationID: "inv_1"}
+	_, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	srv.requireRequest(http.MethodDelete, "/api/v1/environments/"+testEnvironmentID+"/users/invitations/inv_1")
+
+	cmd.InvitationID = "inv_2"
+	_, err = captureOutput(t, cmd.Run)
+	assert.EqualError(t, err, "pending invitation 'inv_2' not found")
+}
+
+func TestEnvironmentInviteResendCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/environments/"+testEnvironmentID+"/users/pending", http.StatusOK, []map[string]any{
+		testEnvironmentUser("inv_1", "c@example.com", "admin", "pending"),
+	})
+	srv.handle("DELETE /api/v1/environments/"+testEnvironmentID+"/users/invitations/inv_1", http.StatusNoContent, nil)
+	srv.handle("POST /api/v1/environments/"+testEnvironmentID+"/users/invite", http.StatusCreated,
+		testEnvironmentUser("inv_9", "c@example.com", "admin", "pending"))
+
+	cmd := EnvironmentInviteResendCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, InvitationID: "inv_1"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	srv.requireRequest(http.MethodDelete, "/api/v1/environments/"+testEnvironmentID+"/users/invitations/inv_1")
+	body := srv.requireRequest(http.MethodPost, "/api/v1/environments/"+testEnvironmentID+"/users/invite").JSON(t)
+	assert.Equal(t, map[string]any{"email_address": "c@example.com", "role": "admin"}, body)
+	assert.Contains(t, output, "resent successfully with ID: inv_9")
+}
+
+func TestEnvironmentInviteResendCommand_DryRun(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/environments/"+testEnvironmentID+"/users/pending", http.StatusOK, []map[string]any{
+		testEnvironmentUser("inv_1", "c@example.com", "admin", "pending"),
+	})
+
+	cfg.DryRun = true
+	cmd := EnvironmentInviteResendCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, InvitationID: "inv_1"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	assert.Empty(t, srv.received(http.MethodDelete, "/api/v1/environments/"+testEnvironmentID+"/users/invitations/inv_1"))
+	assert.Empty(t, srv.received(http.MethodPost, "/api/v1/environments/"+testEnvironmentID+"/users/invite"))
+	assert.Contains(t, output, "Revoke invitation 'inv_1'")
+	assert.Contains(t, output, "Invite 'c@example.com' again as admin")
+}
+
+func TestEnvironmentUserGetCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/environments/"+testEnvironmentID+"/users/user_1", http.StatusOK,
+		testEnvironmentUser("user_1", "a@example.com", "member", "active"))
+	invite := testEnvironmentUser("inv_1", "a@example.com", "admin", "pending")
+	invite["expires_at"] = 1741435200
+	srv.handle("GET /api/v1/environments/"+testEnvironmentID+"/users/pending", http.StatusOK, []map[string]any{invite})
+
+	cfg.Output = "json"
+	cmd := EnvironmentUserGetCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, User: "user_1"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	srv.requireRequest(http.MethodGet, "/api/v1/environments/"+testEnvironmentID+"/users/user_1")
+	var detail userDetail
+	require.NoError(t, json.Unmarshal([]byte(output), &detail))
+	assert.Equal(t, userDetail{
+		ID:              "user_1",
+		Email:           "a@example.com",
+		Role:            "member",
+		Status:          "active",
+		JoinedAt:        "2025-03-01T12:00:00Z",
+		PendingInvite:   true,
+		InvitationID:    "inv_1",
+		InviteExpiresAt: "2025-03-08T12:00:00Z",
+	}, detail)
+}
+
+func TestEnvironmentUserGetCommand_NotFound(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/environments/"+testEnvironmentID+"/users/pending", http.StatusOK, []map[string]any{})
+
+	cmd := EnvironmentUserGetCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, User: "user_9"}
+	_, err := captureOutput(t, cmd.Run)
+	assert.EqualError(t, err, "user 'user_9' not found in this environment")
+}
+
+func TestEnvironmentUserGetCommand_ServerError(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/environments/"+testEnvironmentID+"/users", http.StatusInternalServerError, map[string]any{"detail": "boom"})
+
+	cmd := EnvironmentUserGetCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, User: "a@example.com"}
+	_, err := captureOutput(t, cmd.Run)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to list environment users")
+	assert.Empty(t, srv.received(http.MethodGet, "/api/v1/environments/"+testEnvironmentID+"/users/pending"))
+}
+
+func TestEnvironmentUserAuditCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	member := testEnvironmentUser("user_1", "a@example.com", "admin", "active")
+	member["created_at"] = 1735732800
+	srv.handle("GET /api/v1/environments/"+testEnvironmentID+"/users", http.StatusOK, []map[string]any{
+		member,
+		testEnvironmentUser("user_2", "b@example.com", "member", "active"),
+	})
+	invite := testEnvironmentUser("inv_1", "c@example.com", "member", "pending")
+	invite["expires_at"] = 1741435200
+	srv.handle("GET /api/v1/environments/"+testEnvironmentID+"/users/pending", http.StatusOK, []map[string]any{invite})
+
+	cmd := EnvironmentUserAuditCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Format:            "csv",
+		Since:             time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Equal(t, "timestamp,action,user,role,status,expires_at\n"+
+		"2025-03-01T12:00:00Z,member_joined,b@example.com,member,active,\n"+
+		"2025-03-01T12:00:00Z,invitation_sent,c@example.com,member,pending,2025-03-08T12:00:00Z\n", output)
+}
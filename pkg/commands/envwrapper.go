@@ -1,26 +1,48 @@
 package commands
 
 import (
+	"fmt"
+
 	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
 	"github.com/arctir/devgraph-cli/pkg/util"
+	"github.com/google/uuid"
 )
 
 type EnvWrapperCommand struct {
 	config.Config
+	Env string `name:"env" help:"Environment name, slug, or UUID to use for this command (overrides the current context)"`
 }
 
-func (e *EnvWrapperCommand) BeforeApply() error {
+// AfterApply runs once flags are parsed so an --env override can be taken
+// into account
+func (e *EnvWrapperCommand) AfterApply() error {
 	// Apply defaults from environment config map
 	e.Config.ApplyDefaults()
 
+	// There's no API to resolve names with offline, so --env has to be the
+	// UUID the snapshot was saved under
+	if e.Config.Offline {
+		if e.Env != "" {
+			if _, err := uuid.Parse(e.Env); err != nil {
+				return fmt.Errorf("invalid --env: use the environment's UUID with --offline")
+			}
+			e.Config.EnvOverride = e.Env
+		}
+		return nil
+	}
+
 	// Skip environment check if not authenticated
 	// This allows commands to proceed and let main.go handle first-time setup
-	if !util.IsAuthenticated() {
+	if e.Env == "" && !util.IsAuthenticated() {
 		return nil
 	}
 
-	ok, err := util.CheckEnvironment(&e.Config)
-	if !ok {
+	// An explicit --env replaces the saved environment for this command only
+	if err := devgraph.ResolveEnvironment(&e.Config, e.Env); err != nil {
+		if e.Env != "" {
+			return fmt.Errorf("invalid --env: %w", err)
+		}
 		return err
 	}
 	return nil
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arctir/devgraph-cli/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvWrapperCommand_EnvOverride(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	staging := testEnvironment()
+	staging["id"] = "22222222-2222-2222-2222-222222222222"
+	staging["name"] = "Staging"
+	staging["slug"] = "staging"
+	srv.handle("GET /api/v1/environments", http.StatusOK, []map[string]any{testEnvironment(), staging})
+	srv.handle("GET /api/v1/environments/22222222-2222-2222-2222-222222222222/users", http.StatusOK, []map[string]any{})
+
+	cfg.Output = "json"
+	cmd := EnvironmentUserListCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg, Env: "staging"},
+	}
+	require.NoError(t, cmd.AfterApply())
+	assert.Equal(t, "22222222-2222-2222-2222-222222222222", cmd.Config.EnvOverride)
+
+	_, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	request := srv.requireRequest(http.MethodGet, "/api/v1/environments/22222222-2222-2222-2222-222222222222/users")
+	assert.Equal(t, "22222222-2222-2222-2222-222222222222", request.Header.Get("Devgraph-Environment"))
+}
+
+func TestEnvWrapperCommand_UnknownEnv(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/environments", http.StatusOK, []map[string]any{testEnvironment()})
+
+	cmd := EnvWrapperCommand{Config: cfg, Env: "missing"}
+	err := cmd.AfterApply()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --env")
+	assert.Empty(t, cmd.Config.EnvOverride)
+}
+
+func TestEnvWrapperCommand_CachesEnvironments(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/environments", http.StatusOK, []map[string]any{testEnvironment()})
+
+	cfg.CacheTTL = time.Minute
+	for i := 0; i < 2; i++ {
+		cmd := EnvWrapperCommand{Config: cfg, Env: testEnvironmentID}
+		require.NoError(t, cmd.AfterApply())
+		assert.Equal(t, testEnvironmentID, cmd.Config.EnvOverride)
+	}
+	srv.requireRequest(http.MethodGet, "/api/v1/environments")
+
+	// Creating an environment makes the next lookup ask the API again
+	srv.handle("POST /api/v1/environments", http.StatusCreated, testEnvironment())
+	client, err := util.GetAuthenticatedHTTPClient(cfg)
+	require.NoError(t, err)
+	resp, err := client.Post(srv.server.URL+"/api/v1/environments", "application/json", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	cmd := EnvWrapperCommand{Config: cfg, Env: testEnvironmentID}
+	require.NoError(t, cmd.AfterApply())
+	assert.Len(t, srv.received(http.MethodGet, "/api/v1/environments"), 2)
+}
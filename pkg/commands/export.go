@@ -0,0 +1,6 @@
+package commands
+
+// ExportCommand writes entities in other systems' formats
+type ExportCommand struct {
+	Backstage ExportBackstageCommand `cmd:"backstage" help:"Write entities as Backstage catalog-info.yaml files."`
+}
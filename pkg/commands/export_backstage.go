@@ -0,0 +1,279 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
+	"github.com/arctir/devgraph-cli/pkg/util"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+	"gopkg.in/yaml.v3"
+)
+
+// ExportBackstageCommand writes entities as Backstage catalog files, so a
+// Backstage catalog can be kept in sync from Devgraph
+type ExportBackstageCommand struct {
+	EnvWrapperCommand
+	OutputDir     string `arg:"" required:"" help:"Directory to write the catalog files to."`
+	Name          string `flag:"name" help:"Filter entities by name."`
+	Label         string `flag:"label,l" help:"Filter entities by label selector."`
+	FieldSelector string `flag:"field-selector,f" help:"Filter entities by field selector."`
+	Owner         string `flag:"default-owner" default:"unknown" help:"Owner of entities without an OWNED_BY relation, for kinds Backstage requires one of."`
+	Lifecycle     string `flag:"default-lifecycle" default:"unknown" help:"Lifecycle of components and APIs that don't have one."`
+}
+
+// backstageExportKinds maps entity kinds to the Backstage kinds they're
+// exported as. Kinds not listed become Resources.
+var backstageExportKinds = map[string]string{
+	"api":         "API",
+	"application": "Component",
+	"app":         "Component",
+	"component":   "Component",
+	"domain":      "Domain",
+	"group":       "Group",
+	"library":     "Component",
+	"repository":  "Component",
+	"service":     "Component",
+	"system":      "System",
+	"team":        "Group",
+	"user":        "User",
+	"website":     "Component",
+}
+
+// backstageExport is an entity being exported
+type backstageExport struct {
+	entity api.EntityResponse
+	doc    backstageEntity
+}
+
+func (e *ExportBackstageCommand) Run() error {
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	params := api.GetEntitiesParams{IncludeRelations: api.NewOptBool(true)}
+	if e.Name != "" {
+		params.Name = api.NewOptString(e.Name)
+	}
+	if e.Label != "" {
+		params.Label = api.NewOptString(e.Label)
+	}
+	if e.FieldSelector != "" {
+		params.FieldSelector = api.NewOptString(e.FieldSelector)
+	}
+	entities, relations, err := queryEntities(context.Background(), client, params, queryClause{})
+	if err != nil {
+		return err
+	}
+	if len(entities) == 0 {
+		return fmt.Errorf("no entities found to export")
+	}
+
+	docs := convertToBackstage(entities, relations, e.Owner, e.Lifecycle)
+	return writeBackstageCatalog(e.OutputDir, docs)
+}
+
+// convertToBackstage converts entities to Backstage entities, setting the
+// spec fields Backstage keeps relations in from the entities' relations
+func convertToBackstage(entities []api.EntityResponse, relations []api.EntityRelationResponse, owner, lifecycle string) []backstageEntity {
+	exports := make(map[string]*backstageExport, len(entities))
+	ids := make([]string, 0, len(entities))
+	for _, entity := range entities {
+		if _, ok := exports[entity.ID]; ok {
+			continue
+		}
+		exports[entity.ID] = &backstageExport{entity: entity, doc: backstageDocument(entity)}
+		ids = append(ids, entity.ID)
+	}
+
+	for _, rel := range relations {
+		source, target := exports[rel.Source.ID], exports[rel.Target.ID]
+		if source == nil || target == nil {
+			continue
+		}
+		relation := strings.ReplaceAll(strings.ToUpper(rel.Relation), "_", "")
+		switch relation {
+		case "OWNEDBY":
+			source.doc.Spec["owner"] = backstageRef(target.doc)
+		case "OWNS":
+			target.doc.Spec["owner"] = backstageRef(source.doc)
+		case "PARTOF":
+			field := map[string]string{
+				"Component/System":    "system",
+				"API/System":          "system",
+				"Resource/System":     "system",
+				"System/Domain":       "domain",
+				"Domain/Domain":       "subdomainOf",
+				"Component/Component": "subcomponentOf",
+			}[source.doc.Kind+"/"+target.doc.Kind]
+			if field != "" {
+				source.doc.Spec[field] = backstageRef(target.doc)
+			}
+		case "PROVIDESAPI", "CONSUMESAPI":
+			if source.doc.Kind == "Component" && target.doc.Kind == "API" {
+				field := map[string]string{"PROVIDESAPI": "providesApis", "CONSUMESAPI": "consumesApis"}[relation]
+				appendBackstageRef(source.doc.Spec, field, target.doc)
+			}
+		case "DEPENDSON":
+			if source.doc.Kind == "Component" || source.doc.Kind == "Resource" {
+				appendBackstageRef(source.doc.Spec, "dependsOn", target.doc)
+			}
+		case "MEMBEROF":
+			if source.doc.Kind == "User" && target.doc.Kind == "Group" {
+				appendBackstageRef(source.doc.Spec, "memberOf", target.doc)
+			}
+		case "CHILDOF":
+			if source.doc.Kind == "Group" && target.doc.Kind == "Group" {
+				source.doc.Spec["parent"] = backstageRef(target.doc)
+				appendBackstageRef(target.doc.Spec, "children", source.doc)
+			}
+		}
+	}
+
+	docs := make([]backstageEntity, 0, len(ids))
+	for _, id := range ids {
+		doc := exports[id].doc
+		backstageDefaults(&doc, owner, lifecycle)
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// backstageDocument converts an entity to a Backstage entity. Entities
+// imported from Backstage keep their kind; other kinds are mapped to the
+// closest Backstage kind, keeping the original as the spec type.
+func backstageDocument(entity api.EntityResponse) backstageEntity {
+	var doc backstageEntity
+	doc.APIVersion = backstageGroup + "/" + backstageVersion
+	doc.Metadata.Name = entity.Metadata.Name
+	doc.Metadata.Namespace = entity.Metadata.Namespace
+	doc.Spec = map[string]any{}
+	if spec, ok := entity.Spec.Get(); ok {
+		doc.Spec = devgraph.CleanSpec(spec)
+	}
+
+	if strings.HasPrefix(entity.ApiVersion, "backstage.io/") {
+		doc.Kind = entity.Kind
+	} else if kind, ok := backstageExportKinds[strings.ToLower(entity.Kind)]; ok {
+		doc.Kind = kind
+	} else {
+		doc.Kind = "Resource"
+	}
+	if doc.Kind != entity.Kind {
+		if _, ok := doc.Spec["type"]; !ok {
+			doc.Spec["type"] = strings.ToLower(entity.Kind)
+		}
+	}
+
+	if labels, ok := entity.Metadata.Labels.Get(); ok && len(labels) > 0 {
+		doc.Metadata.Labels = labels
+	}
+	annotations := map[string]string{"devgraph.io/entity-id": entity.ID}
+	if existing, ok := entity.Metadata.Annotations.Get(); ok {
+		for k, v := range existing {
+			annotations[k] = v
+		}
+	}
+	// Imports keep Backstage's title, description and tags as annotations
+	doc.Metadata.Title = annotations["backstage.io/title"]
+	doc.Metadata.Description = annotations["backstage.io/description"]
+	if tags := annotations["backstage.io/tags"]; tags != "" {
+		doc.Metadata.Tags = strings.Split(tags, ",")
+	}
+	delete(annotations, "backstage.io/title")
+	delete(annotations, "backstage.io/description")
+	delete(annotations, "backstage.io/tags")
+	doc.Metadata.Annotations = annotations
+	return doc
+}
+
+// backstageDefaults fills in the spec fields Backstage requires
+func backstageDefaults(doc *backstageEntity, owner, lifecycle string) {
+	setDefault := func(field string, value any) {
+		if _, ok := doc.Spec[field]; !ok {
+			doc.Spec[field] = value
+		}
+	}
+	switch doc.Kind {
+	case "Component", "API":
+		setDefault("type", "unknown")
+		setDefault("lifecycle", lifecycle)
+		setDefault("owner", owner)
+		if doc.Kind == "API" {
+			setDefault("definition", "unknown")
+		}
+	case "Resource":
+		setDefault("type", "unknown")
+		setDefault("owner", owner)
+	case "System", "Domain":
+		setDefault("owner", owner)
+	case "Group":
+		setDefault("type", "team")
+		setDefault("children", []string{})
+	case "User":
+		setDefault("memberOf", []string{})
+	}
+}
+
+// backstageRef returns a Backstage reference to doc, kind:namespace/name
+func backstageRef(doc backstageEntity) string {
+	return fmt.Sprintf("%s:%s/%s", strings.ToLower(doc.Kind), doc.Metadata.Namespace, doc.Metadata.Name)
+}
+
+// appendBackstageRef adds a reference to doc to a list field of spec
+func appendBackstageRef(spec map[string]any, field string, doc backstageEntity) {
+	refs, _ := spec[field].([]string)
+	spec[field] = append(refs, backstageRef(doc))
+}
+
+// writeBackstageCatalog writes each entity to <namespace>/<kind>/<name>.yaml
+// in dir, along with a catalog-info.yaml Location listing them all, which
+// is the one file to register with Backstage
+func writeBackstageCatalog(dir string, docs []backstageEntity) error {
+	var targets []string
+	for _, doc := range docs {
+		target := filepath.Join(doc.Metadata.Namespace, strings.ToLower(doc.Kind), doc.Metadata.Name+".yaml")
+		path := filepath.Join(dir, target)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := writeYAMLFile(path, doc); err != nil {
+			return err
+		}
+		targets = append(targets, "./"+filepath.ToSlash(target))
+	}
+	sort.Strings(targets)
+
+	location := map[string]any{
+		"apiVersion": backstageGroup + "/" + backstageVersion,
+		"kind":       "Location",
+		"metadata": map[string]any{
+			"name":        "devgraph",
+			"description": "Entities exported from Devgraph",
+		},
+		"spec": map[string]any{"targets": targets},
+	}
+	if err := writeYAMLFile(filepath.Join(dir, "catalog-info.yaml"), location); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Exported %d entities to %s\n", len(docs), dir)
+	fmt.Printf("Register %s with Backstage to import them\n", filepath.Join(dir, "catalog-info.yaml"))
+	return nil
+}
+
+func writeYAMLFile(path string, value any) error {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExportBackstageCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	db := testEntity("orders-db")
+	db["kind"] = "Database"
+	db["id"] = "core/v1/database/default/orders-db"
+	team := testEntity("payments")
+	team["kind"] = "Team"
+	team["id"] = "core/v1/team/default/payments"
+	payments := testEntity("payments-api")
+	payments["spec"] = map[string]any{"language": "go"}
+	payments["metadata"] = map[string]any{"name": "payments-api", "namespace": "default", "labels": map[string]any{"team": "payments"}}
+	component := testEntity("checkout")
+	component["apiVersion"] = "backstage.io/v1alpha1"
+	component["kind"] = "Component"
+	component["id"] = "backstage.io/v1alpha1/components/default/checkout"
+	component["metadata"] = map[string]any{
+		"name":      "checkout",
+		"namespace": "default",
+		"annotations": map[string]any{
+			"backstage.io/title":      "Checkout",
+			"backstage.io/tags":       "web,frontend",
+			"github.com/project-slug": "acme/checkout",
+		},
+	}
+	component["spec"] = map[string]any{"type": "website", "lifecycle": "production"}
+
+	dependsOnDB := testRelation("DEPENDS_ON", "payments-api", "orders-db")
+	dependsOnDB["target"] = map[string]any{"apiVersion": "core/v1", "kind": "Database", "name": "orders-db", "id": "core/v1/database/default/orders-db"}
+	ownedByTeam := testRelation("OWNED_BY", "payments-api", "payments")
+	ownedByTeam["target"] = map[string]any{"apiVersion": "core/v1", "kind": "Team", "name": "payments", "id": "core/v1/team/default/payments"}
+	// Relations to entities that aren't exported are left out
+	dependsOnMissing := testRelation("DEPENDS_ON", "payments-api", "ledger")
+
+	var queries []url.Values
+	srv.mux.HandleFunc("GET /api/v1/entities/", func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query())
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"primary_entities": []map[string]any{payments, db, team, component},
+			"relations":        []map[string]any{dependsOnDB, ownedByTeam, dependsOnMissing},
+		})
+	})
+
+	dir := t.TempDir()
+	cmd := ExportBackstageCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		OutputDir:         dir,
+		Label:             "team=payments",
+		Owner:             "group:default/platform",
+		Lifecycle:         "experimental",
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Exported 4 entities")
+
+	require.Len(t, queries, 1)
+	assert.Equal(t, "true", queries[0].Get("include_relations"))
+	assert.Equal(t, "team=payments", queries[0].Get("label"))
+
+	readYAML := func(path string) map[string]any {
+		data, err := os.ReadFile(filepath.Join(dir, path))
+		require.NoError(t, err)
+		var doc map[string]any
+		require.NoError(t, yaml.Unmarshal(data, &doc))
+		return doc
+	}
+
+	location := readYAML("catalog-info.yaml")
+	assert.Equal(t, "Location", location["kind"])
+	assert.Equal(t, map[string]any{"targets": []any{
+		"./default/component/checkout.yaml",
+		"./default/component/payments-api.yaml",
+		"./default/group/payments.yaml",
+		"./default/resource/orders-db.yaml",
+	}}, location["spec"])
+
+	service := readYAML("default/component/payments-api.yaml")
+	assert.Equal(t, "backstage.io/v1alpha1", service["apiVersion"])
+	assert.Equal(t, map[string]any{
+		"language":  "go",
+		"type":      "service",
+		"lifecycle": "experimental",
+		"owner":     "group:default/payments",
+		"dependsOn": []any{"resource:default/orders-db"},
+	}, service["spec"])
+	metadata := service["metadata"].(map[string]any)
+	assert.Equal(t, map[string]any{"team": "payments"}, metadata["labels"])
+	assert.Equal(t, map[string]any{"devgraph.io/entity-id": "core/v1/service/default/payments-api"}, metadata["annotations"])
+
+	resource := readYAML("default/resource/orders-db.yaml")
+	assert.Equal(t, map[string]any{"type": "database", "owner": "group:default/platform"}, resource["spec"])
+
+	group := readYAML("default/group/payments.yaml")
+	assert.Equal(t, map[string]any{"type": "team", "children": []any{}}, group["spec"])
+
+	// Entities imported from Backstage get back their title and tags
+	checkout := readYAML("default/component/checkout.yaml")
+	metadata = checkout["metadata"].(map[string]any)
+	assert.Equal(t, "Checkout", metadata["title"])
+	assert.Equal(t, []any{"web", "frontend"}, metadata["tags"])
+	assert.Equal(t, map[string]any{
+		"devgraph.io/entity-id":   "backstage.io/v1alpha1/components/default/checkout",
+		"github.com/project-slug": "acme/checkout",
+	}, metadata["annotations"])
+	assert.Equal(t, "website", checkout["spec"].(map[string]any)["type"])
+}
+
+func TestExportBackstageCommand_NoEntities(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/", http.StatusOK, map[string]any{"primary_entities": []map[string]any{}})
+
+	cmd := ExportBackstageCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, OutputDir: t.TempDir()}
+	_, err := captureOutput(t, cmd.Run)
+	assert.ErrorContains(t, err, "no entities found to export")
+}
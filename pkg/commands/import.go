@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/arctir/devgraph-cli/pkg/util"
+)
+
+// ImportCommand creates entities and relations from other systems' catalogs
+type ImportCommand struct {
+	Backstage  ImportBackstageCommand  `cmd:"backstage" help:"Import Backstage catalog-info.yaml files from a directory or URL."`
+	Kubernetes ImportKubernetesCommand `cmd:"kubernetes" help:"Import a Kubernetes cluster's workloads, services and ingresses."`
+	GitHub     ImportGitHubCommand     `cmd:"github" name:"github" help:"Import a GitHub organization's repositories, teams and members."`
+	Terraform  ImportTerraformCommand  `cmd:"terraform" help:"Import the resources in a Terraform state file or backend."`
+}
+
+// importedCatalog is what an importer found in its source: definitions for
+// the kinds it creates, the entities, and the relations between them
+type importedCatalog struct {
+	Definitions []FilteredEntityDefinition
+	Entities    []FilteredEntity
+	Relations   []FilteredEntityRelation
+}
+
+// importCatalog creates the definitions the server doesn't have yet, then the
+// entities and relations of catalog
+func importCatalog(cfg config.Config, catalog importedCatalog, workers int, enforceLimits bool) error {
+	if len(catalog.Entities) == 0 {
+		return fmt.Errorf("no entities found to import")
+	}
+
+	client, err := util.GetAuthenticatedClient(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	if err := checkQuota(client, "entities", int64(len(catalog.Entities)), enforceLimits); err != nil {
+		return err
+	}
+
+	if cfg.DryRun {
+		printCatalogPlan("import", catalog.Definitions, catalog.Entities, catalog.Relations)
+		return nil
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	// Definitions that already exist are left alone, so importing again
+	// only adds what's new
+	var summary definitionApplySummary
+	if err := applyEntityDefinitions(ctx, client, catalog.Definitions, false, &summary); err != nil {
+		return err
+	}
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d definition(s) could not be created", summary.Failed)
+	}
+
+	return restoreCatalog(ctx, client, nil, catalog.Definitions, catalog.Entities, catalog.Relations, cfg.WorkerCount(workers))
+}
+
+// printCatalogPlan prints the definitions, entities and relations a dry run
+// would have created
+func printCatalogPlan(verb string, definitions []FilteredEntityDefinition, entities []FilteredEntity, relations []FilteredEntityRelation) {
+	fmt.Printf("Dry run: Would %s %d definitions, %d entities, and %d relations:\n", verb, len(definitions), len(entities), len(relations))
+	for _, def := range definitions {
+		fmt.Printf("  Definition: %s/%s\n", def.Group, def.Kind)
+	}
+	for _, entity := range entities {
+		if metadata, ok := entity.Metadata.(map[string]interface{}); ok {
+			fmt.Printf("  Entity: %s/%s (%s)\n", metadata["namespace"], metadata["name"], entity.Kind)
+		}
+	}
+	for _, rel := range relations {
+		fmt.Printf("  Relation: %s -> %s (%s)\n", rel.Source, rel.Target, rel.Relation)
+	}
+}
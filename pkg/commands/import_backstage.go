@@ -0,0 +1,456 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/arctir/devgraph-cli/pkg/logging"
+	"github.com/arctir/devgraph-cli/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+// backstageGroup and backstageVersion are the group and version imported
+// Backstage entities are created under, which keeps their Backstage
+// apiVersion
+const (
+	backstageGroup   = "backstage.io"
+	backstageVersion = "v1alpha1"
+)
+
+// ImportBackstageCommand converts a Backstage catalog to entities and
+// relations
+type ImportBackstageCommand struct {
+	EnvWrapperCommand
+	Source        string `arg:"" required:"" help:"Directory to search for catalog YAML files, a catalog file, the URL of one, or - to read one from stdin."`
+	Workers       int    `flag:"workers,w" help:"Number of concurrent workers (defaults to the default_workers setting, or 10)."`
+	EnforceLimits bool   `flag:"enforce-limits" help:"Abort instead of warning when the import would exceed the plan's entity quota."`
+}
+
+// backstageEntity is an entity in a Backstage catalog file
+type backstageEntity struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name        string            `yaml:"name"`
+		Namespace   string            `yaml:"namespace,omitempty"`
+		Title       string            `yaml:"title,omitempty"`
+		Description string            `yaml:"description,omitempty"`
+		Labels      map[string]string `yaml:"labels,omitempty"`
+		Annotations map[string]string `yaml:"annotations,omitempty"`
+		Tags        []string          `yaml:"tags,omitempty"`
+	} `yaml:"metadata"`
+	Spec map[string]any `yaml:"spec,omitempty"`
+}
+
+// backstageKind describes a Backstage kind and the spec fields of its
+// definition. Fields listed in arrays hold lists of strings.
+type backstageKind struct {
+	Kind   string
+	Fields []string
+	Arrays []string
+}
+
+// backstageKinds are the Backstage kinds that are imported, by lower case name
+var backstageKinds = map[string]backstageKind{
+	"component": {Kind: "Component", Fields: []string{"type", "lifecycle", "owner", "system", "subcomponentOf"}, Arrays: []string{"providesApis", "consumesApis", "dependsOn", "dependencyOf"}},
+	"api":       {Kind: "API", Fields: []string{"type", "lifecycle", "owner", "system", "definition"}},
+	"resource":  {Kind: "Resource", Fields: []string{"type", "owner", "system"}, Arrays: []string{"dependsOn", "dependencyOf"}},
+	"system":    {Kind: "System", Fields: []string{"type", "owner", "domain"}},
+	"domain":    {Kind: "Domain", Fields: []string{"type", "owner", "subdomainOf"}},
+	"group":     {Kind: "Group", Fields: []string{"type", "parent"}, Arrays: []string{"children", "members"}},
+	"user":      {Kind: "User", Arrays: []string{"memberOf"}},
+}
+
+// backstageRelation maps a spec field referring to other entities to a
+// relation. Reverse relations point from the referenced entity to the one
+// with the field.
+type backstageRelation struct {
+	Field       string
+	Relation    string
+	DefaultKind string
+	Reverse     bool
+}
+
+var backstageRelations = []backstageRelation{
+	{Field: "owner", Relation: "OWNED_BY", DefaultKind: "group"},
+	{Field: "system", Relation: "PART_OF", DefaultKind: "system"},
+	{Field: "domain", Relation: "PART_OF", DefaultKind: "domain"},
+	{Field: "subdomainOf", Relation: "PART_OF", DefaultKind: "domain"},
+	{Field: "subcomponentOf", Relation: "PART_OF", DefaultKind: "component"},
+	{Field: "providesApis", Relation: "PROVIDES_API", DefaultKind: "api"},
+	{Field: "consumesApis", Relation: "CONSUMES_API", DefaultKind: "api"},
+	{Field: "dependsOn", Relation: "DEPENDS_ON", DefaultKind: "component"},
+	{Field: "dependencyOf", Relation: "DEPENDS_ON", DefaultKind: "component", Reverse: true},
+	{Field: "parent", Relation: "CHILD_OF", DefaultKind: "group"},
+	{Field: "children", Relation: "CHILD_OF", DefaultKind: "group", Reverse: true},
+	{Field: "memberOf", Relation: "MEMBER_OF", DefaultKind: "group"},
+	{Field: "members", Relation: "MEMBER_OF", DefaultKind: "user", Reverse: true},
+}
+
+func (i *ImportBackstageCommand) Run() error {
+	client, err := i.Config.HTTPClient()
+	if err != nil {
+		return err
+	}
+
+	reader := &backstageReader{client: client, visited: map[string]bool{}}
+	if err := reader.readSource(i.Source); err != nil {
+		return err
+	}
+
+	catalog := convertBackstageEntities(reader.entities)
+	fmt.Printf("Found %d entities and %d relations in %s\n", len(catalog.Entities), len(catalog.Relations), i.Source)
+	return importCatalog(i.Config, catalog, i.Workers, i.EnforceLimits)
+}
+
+// backstageReader collects the entities of catalog files, following the
+// targets of Location entities
+type backstageReader struct {
+	client   *http.Client
+	visited  map[string]bool
+	entities []backstageEntity
+}
+
+func (r *backstageReader) readSource(source string) error {
+	if isURL(source) {
+		return r.readURL(source)
+	}
+	if source == util.StdinFile {
+		// Relative location targets are relative to the working directory
+		data, err := util.ReadFileOrStdin(source)
+		if err != nil {
+			return fmt.Errorf("failed to read stdin: %w", err)
+		}
+		return r.readData("stdin", ".", data, true)
+	}
+
+	info, err := os.Stat(source)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", source, err)
+	}
+	if !info.IsDir() {
+		return r.readFile(source, true)
+	}
+
+	return filepath.WalkDir(source, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != source && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".yaml") && !strings.HasSuffix(path, ".yml") {
+			return nil
+		}
+		// Directories hold all sorts of YAML, so files that aren't catalog
+		// files are skipped
+		return r.readFile(path, false)
+	})
+}
+
+func (r *backstageReader) readFile(path string, strict bool) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if r.visited[abs] {
+		return nil
+	}
+	r.visited[abs] = true
+
+	data, err := os.ReadFile(abs) // #nosec G304 - reading user-specified catalog files
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return r.readData(path, filepath.Dir(abs), data, strict)
+}
+
+// readData collects the entities of the catalog file named name, following
+// relative location targets from dir
+func (r *backstageReader) readData(name, dir string, data []byte, strict bool) error {
+	entities, err := parseBackstageEntities(data)
+	if err != nil {
+		if !strict {
+			logging.Debug("skipping file that isn't a Backstage catalog file", "file", name, "error", err)
+			return nil
+		}
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	return r.add(entities, func(target string) error {
+		if isURL(target) {
+			return r.readURL(target)
+		}
+		return r.readFile(filepath.Join(dir, target), true)
+	})
+}
+
+func (r *backstageReader) readURL(rawURL string) error {
+	if r.visited[rawURL] {
+		return nil
+	}
+	r.visited[rawURL] = true
+
+	resp, err := r.client.Get(rawURL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status from %s: %s", rawURL, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	entities, err := parseBackstageEntities(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", rawURL, err)
+	}
+
+	base, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	return r.add(entities, func(target string) error {
+		ref, err := url.Parse(target)
+		if err != nil {
+			return fmt.Errorf("invalid location target %q: %w", target, err)
+		}
+		return r.readURL(base.ResolveReference(ref).String())
+	})
+}
+
+// add collects entities, reading the targets of Locations with follow
+func (r *backstageReader) add(entities []backstageEntity, follow func(target string) error) error {
+	for _, entity := range entities {
+		if !strings.EqualFold(entity.Kind, "Location") {
+			r.entities = append(r.entities, entity)
+			continue
+		}
+
+		var targets []string
+		if target, ok := entity.Spec["target"].(string); ok {
+			targets = append(targets, target)
+		}
+		targets = append(targets, stringList(entity.Spec["targets"])...)
+		for _, target := range targets {
+			// Globs and other location types can't be followed from here
+			if strings.Contains(target, "*") {
+				logging.Warn("skipping location target with a wildcard", "target", target)
+				continue
+			}
+			if err := follow(target); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// parseBackstageEntities reads the Backstage entities in a YAML stream,
+// failing if there are none
+func parseBackstageEntities(data []byte) ([]backstageEntity, error) {
+	var entities []backstageEntity
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var entity backstageEntity
+		if err := decoder.Decode(&entity); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if !strings.HasPrefix(entity.APIVersion, "backstage.io/") || entity.Kind == "" {
+			continue
+		}
+		entities = append(entities, entity)
+	}
+	if len(entities) == 0 {
+		return nil, fmt.Errorf("no Backstage entities found")
+	}
+	return entities, nil
+}
+
+// convertBackstageEntities converts Backstage entities to entities, the
+// definitions of their kinds, and the relations between them. Kinds that
+// aren't imported and relations to entities outside the import are skipped.
+func convertBackstageEntities(entities []backstageEntity) importedCatalog {
+	var catalog importedCatalog
+	ids := map[string]bool{}
+	kinds := map[string]bool{}
+	for _, entity := range entities {
+		kind, ok := backstageKinds[strings.ToLower(entity.Kind)]
+		if !ok {
+			logging.Warn("skipping Backstage entity of unsupported kind", "kind", entity.Kind, "name", entity.Metadata.Name)
+			continue
+		}
+		if entity.Metadata.Namespace == "" {
+			entity.Metadata.Namespace = "default"
+		}
+		id := backstageEntityID(strings.ToLower(entity.Kind), entity.Metadata.Namespace, entity.Metadata.Name)
+		if ids[id] {
+			logging.Warn("skipping duplicate Backstage entity", "id", id)
+			continue
+		}
+		ids[id] = true
+
+		if !kinds[kind.Kind] {
+			kinds[kind.Kind] = true
+			catalog.Definitions = append(catalog.Definitions, backstageDefinition(kind))
+		}
+		catalog.Entities = append(catalog.Entities, backstageToEntity(kind, entity))
+	}
+
+	seen := map[FilteredEntityRelation]bool{}
+	skipped := 0
+	for _, entity := range entities {
+		if _, ok := backstageKinds[strings.ToLower(entity.Kind)]; !ok {
+			continue
+		}
+		namespace := entity.Metadata.Namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+		id := backstageEntityID(strings.ToLower(entity.Kind), namespace, entity.Metadata.Name)
+
+		for _, field := range backstageRelations {
+			for _, ref := range stringList(entity.Spec[field.Field]) {
+				target := parseBackstageRef(ref, field.DefaultKind, namespace)
+				if !ids[target] {
+					skipped++
+					continue
+				}
+				rel := FilteredEntityRelation{Relation: field.Relation, Source: id, Target: target}
+				if field.Reverse {
+					rel.Source, rel.Target = target, id
+				}
+				if !seen[rel] {
+					seen[rel] = true
+					catalog.Relations = append(catalog.Relations, rel)
+				}
+			}
+		}
+	}
+	if skipped > 0 {
+		logging.Warn(fmt.Sprintf("skipped %d relation(s) to entities that aren't part of the import", skipped))
+	}
+
+	sort.Slice(catalog.Definitions, func(i, j int) bool { return catalog.Definitions[i].Kind < catalog.Definitions[j].Kind })
+	return catalog
+}
+
+// backstageDefinition returns the definition of an imported Backstage kind
+func backstageDefinition(kind backstageKind) FilteredEntityDefinition {
+	properties := map[string]any{}
+	for _, field := range kind.Fields {
+		properties[field] = map[string]any{"type": "string"}
+	}
+	for _, field := range kind.Arrays {
+		properties[field] = map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+	}
+	if kind.Kind == "Group" || kind.Kind == "User" {
+		properties["profile"] = map[string]any{"type": "object"}
+	}
+
+	def := FilteredEntityDefinition{
+		Group:       backstageGroup,
+		Kind:        kind.Kind,
+		Name:        backstageVersion,
+		Description: "Backstage " + kind.Kind,
+		Spec:        map[string]any{"type": "object", "properties": properties},
+	}
+	definitionNames(&def)
+	return def
+}
+
+// backstageToEntity converts a Backstage entity. Its title, description and
+// tags, which entities don't have, are kept as annotations.
+func backstageToEntity(kind backstageKind, entity backstageEntity) FilteredEntity {
+	metadata := map[string]interface{}{
+		"name":      entity.Metadata.Name,
+		"namespace": entity.Metadata.Namespace,
+	}
+	if len(entity.Metadata.Labels) > 0 {
+		metadata["labels"] = entity.Metadata.Labels
+	}
+
+	annotations := map[string]string{}
+	for k, v := range entity.Metadata.Annotations {
+		annotations[k] = v
+	}
+	if entity.Metadata.Title != "" {
+		annotations["backstage.io/title"] = entity.Metadata.Title
+	}
+	if entity.Metadata.Description != "" {
+		annotations["backstage.io/description"] = entity.Metadata.Description
+	}
+	if len(entity.Metadata.Tags) > 0 {
+		annotations["backstage.io/tags"] = strings.Join(entity.Metadata.Tags, ",")
+	}
+	if len(annotations) > 0 {
+		metadata["annotations"] = annotations
+	}
+
+	result := FilteredEntity{
+		ApiVersion: backstageGroup + "/" + backstageVersion,
+		Kind:       kind.Kind,
+		Metadata:   metadata,
+	}
+	if len(entity.Spec) > 0 {
+		result.Spec = entity.Spec
+	}
+	return result
+}
+
+// backstageEntityID returns the ID an imported Backstage entity gets
+func backstageEntityID(kind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%ss/%s/%s", backstageGroup, backstageVersion, kind, namespace, name)
+}
+
+// parseBackstageRef converts a Backstage entity reference, [kind:][namespace/]name,
+// to an entity ID, using defaultKind and namespace for the parts it omits
+func parseBackstageRef(ref, defaultKind, namespace string) string {
+	kind := defaultKind
+	if k, rest, ok := strings.Cut(ref, ":"); ok {
+		kind, ref = strings.ToLower(k), rest
+	}
+	if ns, name, ok := strings.Cut(ref, "/"); ok {
+		namespace, ref = ns, name
+	}
+	return backstageEntityID(kind, namespace, ref)
+}
+
+// stringList returns a YAML value that is a string or a list of strings as a
+// list
+func stringList(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBackstageCatalog = `apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: payments
+  description: Takes payments
+  tags: [java, payments]
+  annotations:
+    github.com/project-slug: example/payments
+spec:
+  type: service
+  lifecycle: production
+  owner: team-payments
+  system: billing
+  providesApis: [payments-api]
+  dependsOn: [resource:payments-db, component:missing]
+---
+apiVersion: backstage.io/v1alpha1
+kind: API
+metadata:
+  name: payments-api
+spec:
+  type: openapi
+  lifecycle: production
+  owner: group:default/team-payments
+  definition: "openapi: 3.0.0"
+---
+apiVersion: backstage.io/v1alpha1
+kind: Template
+metadata:
+  name: scaffold
+`
+
+// handleCatalogImport makes srv accept the requests an import makes
+func handleCatalogImport(srv *testAPI) {
+	srv.handle("GET /api/v1/entities/definitions", http.StatusOK, []map[string]any{})
+	srv.handle("POST /api/v1/entities/definitions", http.StatusCreated, testDefinition("backstage.io", "Component", map[string]any{"type": "object"}))
+	srv.handle("POST /api/v1/entities/{group}/{version}/namespace/{namespace}/{plural}", http.StatusCreated, testEntity("payments"))
+	srv.handle("POST /api/v1/entities/relations", http.StatusCreated, testRelation("DEPENDS_ON", "payments", "payments-db"))
+}
+
+func TestImportBackstageCommand(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "catalog-info.yaml"), []byte(testBackstageCatalog), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "infra"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "infra", "catalog-info.yaml"), []byte(`apiVersion: backstage.io/v1alpha1
+kind: Resource
+metadata:
+  name: payments-db
+spec:
+  type: database
+  owner: team-payments
+`), 0600))
+	// YAML that isn't a catalog file is skipped
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte("services: {}\n"), 0600))
+
+	srv, cfg := newTestAPI(t)
+	handleCatalogImport(srv)
+
+	cmd := ImportBackstageCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Source: dir, Workers: 2}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Found 3 entities and 2 relations")
+
+	var kinds []any
+	for _, request := range srv.received(http.MethodPost, "/api/v1/entities/definitions") {
+		body := request.JSON(t)
+		assert.Equal(t, "backstage.io", body["group"])
+		assert.Equal(t, "v1alpha1", body["name"])
+		kinds = append(kinds, body["kind"])
+	}
+	assert.Equal(t, []any{"API", "Component", "Resource"}, kinds)
+
+	component := srv.requireRequest(http.MethodPost, "/api/v1/entities/backstage.io/v1alpha1/namespace/default/components").JSON(t)
+	assert.Equal(t, "backstage.io/v1alpha1", component["apiVersion"])
+	assert.Equal(t, "Component", component["kind"])
+	assert.Equal(t, map[string]any{
+		"name":      "payments",
+		"namespace": "default",
+		"annotations": map[string]any{
+			"github.com/project-slug":  "example/payments",
+			"backstage.io/description": "Takes payments",
+			"backstage.io/tags":        "java,payments",
+		},
+	}, component["metadata"])
+	assert.Equal(t, "service", component["spec"].(map[string]any)["type"])
+	srv.requireRequest(http.MethodPost, "/api/v1/entities/backstage.io/v1alpha1/namespace/default/apis")
+	srv.requireRequest(http.MethodPost, "/api/v1/entities/backstage.io/v1alpha1/namespace/default/resources")
+
+	// Owners and systems aren't part of the import, so only these relations
+	// are created
+	var relations []string
+	for _, request := range srv.received(http.MethodPost, "/api/v1/entities/relations") {
+		body := request.JSON(t)
+		source := body["source"].(map[string]any)
+		target := body["target"].(map[string]any)
+		relations = append(relations, source["name"].(string)+" "+body["relation"].(string)+" "+target["name"].(string))
+	}
+	sort.Strings(relations)
+	assert.Equal(t, []string{"payments DEPENDS_ON payments-db", "payments PROVIDES_API payments-api"}, relations)
+}
+
+func TestImportBackstageCommand_Stdin(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	handleCatalogImport(srv)
+	withStdin(t, testBackstageCatalog)
+
+	cmd := ImportBackstageCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Source: "-", Workers: 2}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Found 2 entities and 1 relations in -")
+
+	component := srv.requireRequest(http.MethodPost, "/api/v1/entities/backstage.io/v1alpha1/namespace/default/components").JSON(t)
+	assert.Equal(t, "payments", component["metadata"].(map[string]any)["name"])
+}
+
+func TestImportBackstageCommand_URL(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	handleCatalogImport(srv)
+	srv.mux.HandleFunc("GET /catalog/all.yaml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`apiVersion: backstage.io/v1alpha1
+kind: Location
+metadata:
+  name: all
+spec:
+  targets: [./payments/catalog-info.yaml]
+`))
+	})
+	srv.mux.HandleFunc("GET /catalog/payments/catalog-info.yaml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testBackstageCatalog))
+	})
+
+	cmd := ImportBackstageCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Source: srv.server.URL + "/catalog/all.yaml", Workers: 1}
+	cmd.DryRun = true
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Dry run: Would import 2 definitions, 2 entities, and 1 relations:")
+	assert.Contains(t, output, "Entity: default/payments-api (API)")
+	assert.Contains(t, output, "Relation: backstage.io/v1alpha1/components/default/payments -> backstage.io/v1alpha1/apis/default/payments-api (PROVIDES_API)")
+	assert.Empty(t, srv.received(http.MethodPost, "/api/v1/entities/definitions"))
+}
+
+func TestParseBackstageRef(t *testing.T) {
+	assert.Equal(t, "backstage.io/v1alpha1/groups/default/team-a", parseBackstageRef("team-a", "group", "default"))
+	assert.Equal(t, "backstage.io/v1alpha1/users/default/jane", parseBackstageRef("user:jane", "group", "default"))
+	assert.Equal(t, "backstage.io/v1alpha1/systems/other/billing", parseBackstageRef("System:other/billing", "group", "default"))
+}
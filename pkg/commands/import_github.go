@@ -0,0 +1,318 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// githubGroup and githubVersion are the group and version imported GitHub
+// entities are created under
+const (
+	githubGroup   = "github"
+	githubVersion = "v1"
+)
+
+// githubKinds are the kinds of imported GitHub entities and their plurals
+var githubKinds = map[string]string{
+	"Organization": "organizations",
+	"Repository":   "repositories",
+	"Team":         "teams",
+	"User":         "users",
+}
+
+// ImportGitHubCommand creates entities for an organization's repositories,
+// teams and members
+type ImportGitHubCommand struct {
+	EnvWrapperCommand
+	Org           string `flag:"org" required:"" help:"GitHub organization to import."`
+	Token         string `flag:"token" help:"GitHub token with read access to the organization (or set GITHUB_TOKEN)."`
+	BaseURL       string `flag:"base-url" default:"https://api.github.com" help:"GitHub API URL, for GitHub Enterprise Server."`
+	Namespace     string `flag:"namespace,n" default:"default" help:"Namespace to create the entities in."`
+	Archived      bool   `flag:"archived" help:"Also import archived repositories."`
+	Workers       int    `flag:"workers,w" help:"Number of concurrent workers (defaults to the default_workers setting, or 10)."`
+	EnforceLimits bool   `flag:"enforce-limits" help:"Abort instead of warning when the import would exceed the plan's entity quota."`
+}
+
+type githubRepository struct {
+	Name          string   `json:"name"`
+	FullName      string   `json:"full_name"`
+	Description   string   `json:"description"`
+	HTMLURL       string   `json:"html_url"`
+	DefaultBranch string   `json:"default_branch"`
+	Language      string   `json:"language"`
+	Visibility    string   `json:"visibility"`
+	Archived      bool     `json:"archived"`
+	Topics        []string `json:"topics"`
+	Permissions   struct {
+		Admin    bool `json:"admin"`
+		Maintain bool `json:"maintain"`
+	} `json:"permissions"`
+}
+
+type githubTeam struct {
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	HTMLURL     string `json:"html_url"`
+	Parent      *struct {
+		Slug string `json:"slug"`
+	} `json:"parent"`
+}
+
+type githubOrganization struct {
+	Login       string `json:"login"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	HTMLURL     string `json:"html_url"`
+}
+
+type githubUser struct {
+	Login   string `json:"login"`
+	HTMLURL string `json:"html_url"`
+}
+
+// githubClient reads from the GitHub REST API
+type githubClient struct {
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+func (i *ImportGitHubCommand) Run() error {
+	token := i.Token
+	if token == "" {
+		token = os.Getenv("GITHUB_TOKEN")
+	}
+	if token == "" {
+		return fmt.Errorf("a GitHub token is required: use --token or set GITHUB_TOKEN")
+	}
+
+	client, err := i.Config.HTTPClient()
+	if err != nil {
+		return err
+	}
+	gh := githubClient{client: client, baseURL: strings.TrimSuffix(i.BaseURL, "/"), token: token}
+
+	catalog, err := gh.importOrg(i.Org, i.Namespace, i.Archived)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Found %d entities and %d relations in %s\n", len(catalog.Entities), len(catalog.Relations), i.Org)
+	return importCatalog(i.Config, catalog, i.Workers, i.EnforceLimits)
+}
+
+// importOrg reads an organization's repositories, teams and members. Teams
+// own the repositories they administer or maintain, and organization owners
+// own the organization.
+func (g githubClient) importOrg(org, namespace string, archived bool) (importedCatalog, error) {
+	var catalog importedCatalog
+	for _, kind := range []string{"Organization", "Repository", "Team", "User"} {
+		catalog.Definitions = append(catalog.Definitions, githubDefinition(kind))
+	}
+
+	escapedOrg := url.PathEscape(org)
+	var organization githubOrganization
+	if _, err := g.get(g.baseURL+"/orgs/"+escapedOrg, &organization); err != nil {
+		return importedCatalog{}, err
+	}
+	orgID := githubEntityID("Organization", namespace, organization.Login)
+	orgSpec := map[string]any{"url": organization.HTMLURL}
+	if organization.Name != "" {
+		orgSpec["displayName"] = organization.Name
+	}
+	if organization.Description != "" {
+		orgSpec["description"] = organization.Description
+	}
+	catalog.Entities = append(catalog.Entities, githubEntity("Organization", namespace, organization.Login, orgSpec))
+
+	var repos []githubRepository
+	if err := g.list("/orgs/"+escapedOrg+"/repos?type=all", &repos); err != nil {
+		return importedCatalog{}, err
+	}
+	imported := map[string]bool{}
+	for _, repo := range repos {
+		if repo.Archived && !archived {
+			continue
+		}
+		imported[repo.Name] = true
+		spec := map[string]any{"url": repo.HTMLURL, "fullName": repo.FullName}
+		for key, value := range map[string]string{"description": repo.Description, "defaultBranch": repo.DefaultBranch, "language": repo.Language, "visibility": repo.Visibility} {
+			if value != "" {
+				spec[key] = value
+			}
+		}
+		if len(repo.Topics) > 0 {
+			spec["topics"] = repo.Topics
+		}
+		if repo.Archived {
+			spec["archived"] = true
+		}
+		repoID := githubEntityID("Repository", namespace, repo.Name)
+		catalog.Entities = append(catalog.Entities, githubEntity("Repository", namespace, repo.Name, spec))
+		catalog.Relations = append(catalog.Relations, FilteredEntityRelation{Relation: "PART_OF", Source: repoID, Target: orgID})
+	}
+
+	var teams []githubTeam
+	if err := g.list("/orgs/"+escapedOrg+"/teams", &teams); err != nil {
+		return importedCatalog{}, err
+	}
+	users := map[string]bool{}
+	addUser := func(user githubUser) {
+		if !users[user.Login] {
+			users[user.Login] = true
+			catalog.Entities = append(catalog.Entities, githubEntity("User", namespace, user.Login, map[string]any{"url": user.HTMLURL}))
+		}
+	}
+
+	for _, team := range teams {
+		teamID := githubEntityID("Team", namespace, team.Slug)
+		spec := map[string]any{"displayName": team.Name, "url": team.HTMLURL}
+		if team.Description != "" {
+			spec["description"] = team.Description
+		}
+		catalog.Entities = append(catalog.Entities, githubEntity("Team", namespace, team.Slug, spec))
+		catalog.Relations = append(catalog.Relations, FilteredEntityRelation{Relation: "PART_OF", Source: teamID, Target: orgID})
+		if team.Parent != nil {
+			catalog.Relations = append(catalog.Relations, FilteredEntityRelation{Relation: "CHILD_OF", Source: teamID, Target: githubEntityID("Team", namespace, team.Parent.Slug)})
+		}
+
+		teamPath := "/orgs/" + escapedOrg + "/teams/" + url.PathEscape(team.Slug)
+		var members []githubUser
+		if err := g.list(teamPath+"/members", &members); err != nil {
+			return importedCatalog{}, err
+		}
+		for _, member := range members {
+			addUser(member)
+			catalog.Relations = append(catalog.Relations, FilteredEntityRelation{Relation: "MEMBER_OF", Source: githubEntityID("User", namespace, member.Login), Target: teamID})
+		}
+
+		var teamRepos []githubRepository
+		if err := g.list(teamPath+"/repos", &teamRepos); err != nil {
+			return importedCatalog{}, err
+		}
+		for _, repo := range teamRepos {
+			if imported[repo.Name] && (repo.Permissions.Admin || repo.Permissions.Maintain) {
+				catalog.Relations = append(catalog.Relations, FilteredEntityRelation{Relation: "OWNED_BY", Source: githubEntityID("Repository", namespace, repo.Name), Target: teamID})
+			}
+		}
+	}
+
+	var owners []githubUser
+	if err := g.list("/orgs/"+escapedOrg+"/members?role=admin", &owners); err != nil {
+		return importedCatalog{}, err
+	}
+	for _, owner := range owners {
+		addUser(owner)
+		catalog.Relations = append(catalog.Relations, FilteredEntityRelation{Relation: "OWNED_BY", Source: orgID, Target: githubEntityID("User", namespace, owner.Login)})
+	}
+
+	return catalog, nil
+}
+
+// githubNextLink finds the URL of the next page in a Link header
+var githubNextLink = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// list gets every page of a GitHub list endpoint into items
+func (g githubClient) list(path string, items any) error {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	next := g.baseURL + path + separator + "per_page=100"
+
+	var all []json.RawMessage
+	for next != "" {
+		var page []json.RawMessage
+		var err error
+		if next, err = g.get(next, &page); err != nil {
+			return err
+		}
+		all = append(all, page...)
+	}
+
+	data, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, items)
+}
+
+// get decodes the response to a GitHub API request into value, returning
+// the URL of the next page, if any
+func (g githubClient) get(rawURL string, value any) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+g.token)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status from GitHub for %s: %s", req.URL.Path, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(value); err != nil {
+		return "", fmt.Errorf("failed to parse GitHub response for %s: %w", req.URL.Path, err)
+	}
+
+	if match := githubNextLink.FindStringSubmatch(resp.Header.Get("Link")); match != nil {
+		return match[1], nil
+	}
+	return "", nil
+}
+
+// githubDefinition returns the definition of an imported GitHub kind
+func githubDefinition(kind string) FilteredEntityDefinition {
+	properties := map[string]any{"url": map[string]any{"type": "string"}}
+	switch kind {
+	case "Organization":
+		properties["displayName"] = map[string]any{"type": "string"}
+		properties["description"] = map[string]any{"type": "string"}
+	case "Repository":
+		for _, field := range []string{"fullName", "description", "defaultBranch", "language", "visibility"} {
+			properties[field] = map[string]any{"type": "string"}
+		}
+		properties["topics"] = map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+		properties["archived"] = map[string]any{"type": "boolean"}
+	case "Team":
+		properties["displayName"] = map[string]any{"type": "string"}
+		properties["description"] = map[string]any{"type": "string"}
+	}
+
+	def := FilteredEntityDefinition{
+		Group:       githubGroup,
+		Kind:        kind,
+		Plural:      githubKinds[kind],
+		Name:        githubVersion,
+		Description: "GitHub " + strings.ToLower(kind),
+		Spec:        map[string]any{"type": "object", "properties": properties},
+	}
+	definitionNames(&def)
+	return def
+}
+
+func githubEntity(kind, namespace, name string, spec map[string]any) FilteredEntity {
+	return FilteredEntity{
+		ApiVersion: githubGroup + "/" + githubVersion,
+		Kind:       kind,
+		Metadata: map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+		},
+		Spec: spec,
+	}
+}
+
+func githubEntityID(kind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s", githubGroup, githubVersion, githubKinds[kind], namespace, name)
+}
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestGitHub starts a fake GitHub API for the acme organization
+func newTestGitHub(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	var github *httptest.Server
+	respond := func(pattern string, body any) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer gh-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Path != "/orgs/acme" {
+				assert.Equal(t, "100", r.URL.Query().Get("per_page"), r.URL.Path)
+			}
+			writeJSON(w, http.StatusOK, body)
+		})
+	}
+
+	respond("GET /orgs/acme", map[string]any{"login": "acme", "name": "Acme Inc", "html_url": "https://github.com/acme"})
+	// Repositories come in two pages
+	mux.HandleFunc("GET /orgs/acme/repos", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "all", r.URL.Query().Get("type"))
+		if r.URL.Query().Get("page") == "2" {
+			writeJSON(w, http.StatusOK, []map[string]any{{"name": "old", "full_name": "acme/old", "archived": true}})
+			return
+		}
+		w.Header().Set("Link", `<`+github.URL+`/orgs/acme/repos?type=all&per_page=100&page=2>; rel="next"`)
+		writeJSON(w, http.StatusOK, []map[string]any{{
+			"name":           "payments",
+			"full_name":      "acme/payments",
+			"html_url":       "https://github.com/acme/payments",
+			"default_branch": "main",
+			"language":       "Go",
+			"topics":         []string{"payments"},
+		}})
+	})
+	respond("GET /orgs/acme/teams", []map[string]any{
+		{"slug": "platform", "name": "Platform", "html_url": "https://github.com/orgs/acme/teams/platform"},
+		{"slug": "payments", "name": "Payments", "parent": map[string]any{"slug": "platform"}},
+	})
+	respond("GET /orgs/acme/teams/platform/members", []map[string]any{{"login": "alice"}})
+	respond("GET /orgs/acme/teams/platform/repos", []map[string]any{{"name": "payments", "permissions": map[string]any{"push": true}}})
+	respond("GET /orgs/acme/teams/payments/members", []map[string]any{{"login": "bob"}})
+	respond("GET /orgs/acme/teams/payments/repos", []map[string]any{
+		{"name": "payments", "permissions": map[string]any{"admin": true}},
+		{"name": "old", "permissions": map[string]any{"admin": true}},
+	})
+	respond("GET /orgs/acme/members", []map[string]any{{"login": "alice"}})
+
+	github = httptest.NewServer(mux)
+	t.Cleanup(github.Close)
+	return github
+}
+
+func TestImportGitHubCommand(t *testing.T) {
+	github := newTestGitHub(t)
+	srv, cfg := newTestAPI(t)
+	handleCatalogImport(srv)
+	t.Setenv("GITHUB_TOKEN", "gh-token")
+
+	cmd := ImportGitHubCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Org:               "acme",
+		BaseURL:           github.URL,
+		Namespace:         "default",
+		Workers:           1,
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Found 6 entities and 8 relations in acme")
+
+	org := srv.requireRequest(http.MethodPost, "/api/v1/entities/github/v1/namespace/default/organizations").JSON(t)
+	assert.Equal(t, map[string]any{"url": "https://github.com/acme", "displayName": "Acme Inc"}, org["spec"])
+	repo := srv.requireRequest(http.MethodPost, "/api/v1/entities/github/v1/namespace/default/repositories").JSON(t)
+	assert.Equal(t, "payments", repo["metadata"].(map[string]any)["name"])
+	assert.Equal(t, map[string]any{
+		"url":           "https://github.com/acme/payments",
+		"fullName":      "acme/payments",
+		"defaultBranch": "main",
+		"language":      "Go",
+		"topics":        []any{"payments"},
+	}, repo["spec"])
+	assert.Len(t, srv.received(http.MethodPost, "/api/v1/entities/github/v1/namespace/default/teams"), 2)
+	assert.Len(t, srv.received(http.MethodPost, "/api/v1/entities/github/v1/namespace/default/users"), 2)
+
+	var relations []string
+	for _, request := range srv.received(http.MethodPost, "/api/v1/entities/relations") {
+		body := request.JSON(t)
+		relations = append(relations, body["source"].(map[string]any)["name"].(string)+" "+body["relation"].(string)+" "+body["target"].(map[string]any)["name"].(string))
+	}
+	sort.Strings(relations)
+	assert.Equal(t, []string{
+		"acme OWNED_BY alice",
+		"alice MEMBER_OF platform",
+		"bob MEMBER_OF payments",
+		"payments CHILD_OF platform",
+		"payments OWNED_BY payments",
+		"payments PART_OF acme",
+		"payments PART_OF acme",
+		"platform PART_OF acme",
+	}, relations)
+}
+
+func TestImportGitHubCommand_Errors(t *testing.T) {
+	github := newTestGitHub(t)
+	t.Setenv("GITHUB_TOKEN", "")
+
+	cmd := ImportGitHubCommand{Org: "acme", BaseURL: github.URL}
+	_, err := captureOutput(t, cmd.Run)
+	assert.ErrorContains(t, err, "a GitHub token is required")
+
+	cmd.Token = "wrong"
+	_, err = captureOutput(t, cmd.Run)
+	assert.ErrorContains(t, err, "unexpected status from GitHub for /orgs/acme: 401 Unauthorized")
+}
@@ -0,0 +1,358 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// kubernetesGroup and kubernetesVersion are the group and version imported
+// Kubernetes resources are created under
+const (
+	kubernetesGroup   = "kubernetes"
+	kubernetesVersion = "v1"
+)
+
+// kubernetesPlurals are the resource kinds that are imported and their
+// plurals
+var kubernetesPlurals = map[string]string{
+	"Deployment":  "deployments",
+	"StatefulSet": "statefulsets",
+	"DaemonSet":   "daemonsets",
+	"Service":     "services",
+	"Ingress":     "ingresses",
+}
+
+// ImportKubernetesCommand registers a cluster's workloads, services and
+// ingresses as entities
+type ImportKubernetesCommand struct {
+	EnvWrapperCommand
+	Kubeconfig    string `flag:"kubeconfig" type:"path" help:"Path to the kubeconfig file to use (defaults to kubectl's)."`
+	Context       string `flag:"context" help:"Kubeconfig context of the cluster to import (defaults to the current context)."`
+	Namespace     string `flag:"namespace,n" help:"Only import resources in this namespace (defaults to the context's namespace)."`
+	AllNamespaces bool   `flag:"all-namespaces,A" help:"Import resources in all namespaces."`
+	Workers       int    `flag:"workers,w" help:"Number of concurrent workers (defaults to the default_workers setting, or 10)."`
+	EnforceLimits bool   `flag:"enforce-limits" help:"Abort instead of warning when the import would exceed the plan's entity quota."`
+}
+
+// kubernetesResource is the part of a Kubernetes resource an import uses
+type kubernetesResource struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name            string            `yaml:"name"`
+		Namespace       string            `yaml:"namespace"`
+		Labels          map[string]string `yaml:"labels"`
+		OwnerReferences []struct {
+			Kind string `yaml:"kind"`
+			Name string `yaml:"name"`
+		} `yaml:"ownerReferences"`
+	} `yaml:"metadata"`
+	Spec map[string]any `yaml:"spec"`
+}
+
+func (k *ImportKubernetesCommand) Run() error {
+	data, err := k.readResources()
+	if err != nil {
+		return err
+	}
+
+	var list struct {
+		Items []kubernetesResource `yaml:"items"`
+	}
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("failed to parse kubectl output: %w", err)
+	}
+
+	catalog := convertKubernetesResources(list.Items, k.Context)
+	fmt.Printf("Found %d resources and %d relations\n", len(catalog.Entities), len(catalog.Relations))
+	return importCatalog(k.Config, catalog, k.Workers, k.EnforceLimits)
+}
+
+// readResources gets the resources to import from kubectl
+func (k *ImportKubernetesCommand) readResources() ([]byte, error) {
+	var plurals []string
+	for _, plural := range kubernetesPlurals {
+		plurals = append(plurals, plural)
+	}
+	sort.Strings(plurals)
+	args := []string{"get", strings.Join(plurals, ","), "-o", "yaml"}
+	if k.Kubeconfig != "" {
+		args = append(args, "--kubeconfig", k.Kubeconfig)
+	}
+	if k.Context != "" {
+		args = append(args, "--context", k.Context)
+	}
+	if k.AllNamespaces {
+		args = append(args, "--all-namespaces")
+	} else if k.Namespace != "" {
+		args = append(args, "--namespace", k.Namespace)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("kubectl", args...)
+	cmd.Stderr = &stderr
+	data, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resources with kubectl: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return data, nil
+}
+
+// convertKubernetesResources converts resources to entities, the
+// definitions of their kinds, and relations for the services that select
+// workloads, the ingresses that route to services, and owner references
+func convertKubernetesResources(resources []kubernetesResource, cluster string) importedCatalog {
+	var catalog importedCatalog
+	ids := map[string]bool{}
+	kinds := map[string]bool{}
+	for _, resource := range resources {
+		if _, ok := kubernetesPlurals[resource.Kind]; !ok {
+			continue
+		}
+		id := kubernetesEntityID(resource.Kind, resource.Metadata.Namespace, resource.Metadata.Name)
+		if ids[id] {
+			continue
+		}
+		ids[id] = true
+
+		if !kinds[resource.Kind] {
+			kinds[resource.Kind] = true
+			catalog.Definitions = append(catalog.Definitions, kubernetesDefinition(resource.Kind))
+		}
+		catalog.Entities = append(catalog.Entities, kubernetesToEntity(resource, cluster))
+	}
+
+	for _, resource := range resources {
+		id := kubernetesEntityID(resource.Kind, resource.Metadata.Namespace, resource.Metadata.Name)
+		if !ids[id] {
+			continue
+		}
+		namespace := resource.Metadata.Namespace
+
+		for _, owner := range resource.Metadata.OwnerReferences {
+			if target := kubernetesEntityID(owner.Kind, namespace, owner.Name); ids[target] {
+				catalog.Relations = append(catalog.Relations, FilteredEntityRelation{Relation: "OWNED_BY", Source: id, Target: target})
+			}
+		}
+
+		switch resource.Kind {
+		case "Service":
+			selector := stringMap(resource.Spec["selector"])
+			if len(selector) == 0 {
+				continue
+			}
+			for _, workload := range resources {
+				if workload.Metadata.Namespace != namespace || !isKubernetesWorkload(workload.Kind) {
+					continue
+				}
+				if selectorMatches(selector, podLabels(workload)) {
+					target := kubernetesEntityID(workload.Kind, namespace, workload.Metadata.Name)
+					catalog.Relations = append(catalog.Relations, FilteredEntityRelation{Relation: "ROUTES_TO", Source: id, Target: target})
+				}
+			}
+		case "Ingress":
+			seen := map[string]bool{}
+			for _, service := range ingressServices(resource.Spec) {
+				target := kubernetesEntityID("Service", namespace, service)
+				if ids[target] && !seen[target] {
+					seen[target] = true
+					catalog.Relations = append(catalog.Relations, FilteredEntityRelation{Relation: "ROUTES_TO", Source: id, Target: target})
+				}
+			}
+		}
+	}
+
+	sort.Slice(catalog.Definitions, func(i, j int) bool { return catalog.Definitions[i].Kind < catalog.Definitions[j].Kind })
+	return catalog
+}
+
+// kubernetesEntityID returns the ID an imported Kubernetes resource gets
+func kubernetesEntityID(kind, namespace, name string) string {
+	plural, ok := kubernetesPlurals[kind]
+	if !ok {
+		plural = strings.ToLower(kind) + "s"
+	}
+	return fmt.Sprintf("%s/%s/%s/%s/%s", kubernetesGroup, kubernetesVersion, plural, namespace, name)
+}
+
+// kubernetesDefinition returns the definition of an imported Kubernetes kind
+func kubernetesDefinition(kind string) FilteredEntityDefinition {
+	stringList := map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+	properties := map[string]any{"cluster": map[string]any{"type": "string"}}
+	switch kind {
+	case "Service":
+		properties["type"] = map[string]any{"type": "string"}
+		properties["ports"] = stringList
+	case "Ingress":
+		properties["hosts"] = stringList
+	default:
+		properties["replicas"] = map[string]any{"type": "integer"}
+		properties["images"] = stringList
+	}
+
+	def := FilteredEntityDefinition{
+		Group:       kubernetesGroup,
+		Kind:        kind,
+		Name:        kubernetesVersion,
+		Plural:      kubernetesPlurals[kind],
+		Description: "Kubernetes " + kind,
+		Spec:        map[string]any{"type": "object", "properties": properties},
+	}
+	definitionNames(&def)
+	return def
+}
+
+// kubernetesToEntity converts a resource to an entity whose spec summarizes
+// it: images and replicas of workloads, ports of services and hosts of
+// ingresses
+func kubernetesToEntity(resource kubernetesResource, cluster string) FilteredEntity {
+	metadata := map[string]interface{}{
+		"name":      resource.Metadata.Name,
+		"namespace": resource.Metadata.Namespace,
+	}
+	if len(resource.Metadata.Labels) > 0 {
+		metadata["labels"] = resource.Metadata.Labels
+	}
+
+	spec := map[string]any{}
+	if cluster != "" {
+		spec["cluster"] = cluster
+	}
+	switch resource.Kind {
+	case "Service":
+		if serviceType, ok := resource.Spec["type"].(string); ok {
+			spec["type"] = serviceType
+		}
+		var ports []string
+		for _, port := range anyList(resource.Spec["ports"]) {
+			p := stringMap(port)
+			protocol := p["protocol"]
+			if protocol == "" {
+				protocol = "TCP"
+			}
+			ports = append(ports, p["port"]+"/"+protocol)
+		}
+		if len(ports) > 0 {
+			spec["ports"] = ports
+		}
+	case "Ingress":
+		var hosts []string
+		for _, rule := range anyList(resource.Spec["rules"]) {
+			if host := stringMap(rule)["host"]; host != "" {
+				hosts = append(hosts, host)
+			}
+		}
+		if len(hosts) > 0 {
+			spec["hosts"] = hosts
+		}
+	default:
+		if replicas, ok := resource.Spec["replicas"].(int); ok {
+			spec["replicas"] = replicas
+		}
+		var images []string
+		podSpec, _ := nestedMap(resource.Spec, "template", "spec")
+		for _, container := range anyList(podSpec["containers"]) {
+			if image := stringMap(container)["image"]; image != "" {
+				images = append(images, image)
+			}
+		}
+		if len(images) > 0 {
+			spec["images"] = images
+		}
+	}
+
+	entity := FilteredEntity{
+		ApiVersion: kubernetesGroup + "/" + kubernetesVersion,
+		Kind:       resource.Kind,
+		Metadata:   metadata,
+	}
+	if len(spec) > 0 {
+		entity.Spec = spec
+	}
+	return entity
+}
+
+func isKubernetesWorkload(kind string) bool {
+	return kind == "Deployment" || kind == "StatefulSet" || kind == "DaemonSet"
+}
+
+// podLabels returns the labels of a workload's pod template
+func podLabels(workload kubernetesResource) map[string]string {
+	metadata, _ := nestedMap(workload.Spec, "template", "metadata")
+	return stringMap(metadata["labels"])
+}
+
+// selectorMatches reports whether labels has every label of selector
+func selectorMatches(selector, labels map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// ingressServices returns the names of the services an ingress routes to
+func ingressServices(spec map[string]any) []string {
+	var services []string
+	if backend, ok := nestedMap(spec, "defaultBackend", "service"); ok {
+		if name, ok := backend["name"].(string); ok {
+			services = append(services, name)
+		}
+	}
+	for _, rule := range anyList(spec["rules"]) {
+		routes, _ := nestedMap(asMap(rule), "http")
+		for _, path := range anyList(routes["paths"]) {
+			if backend, ok := nestedMap(asMap(path), "backend", "service"); ok {
+				if name, ok := backend["name"].(string); ok {
+					services = append(services, name)
+				}
+			}
+		}
+	}
+	return services
+}
+
+// nestedMap follows keys through nested maps
+func nestedMap(m map[string]any, keys ...string) (map[string]any, bool) {
+	for _, key := range keys {
+		next, ok := m[key].(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		m = next
+	}
+	return m, true
+}
+
+func asMap(value any) map[string]any {
+	m, _ := value.(map[string]any)
+	return m
+}
+
+func anyList(value any) []any {
+	list, _ := value.([]any)
+	return list
+}
+
+// stringMap returns the scalar values of a map as strings
+func stringMap(value any) map[string]string {
+	m, ok := value.(map[string]any)
+	if !ok {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		switch v.(type) {
+		case map[string]any, []any:
+			continue
+		}
+		result[k] = fmt.Sprint(v)
+	}
+	return result
+}
@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKubernetesResources = `apiVersion: v1
+kind: List
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: payments
+    namespace: shop
+    labels: {app: payments}
+  spec:
+    replicas: 3
+    selector:
+      matchLabels: {app: payments}
+    template:
+      metadata:
+        labels: {app: payments, tier: backend}
+      spec:
+        containers:
+        - name: app
+          image: example/payments:1.2.3
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: payments
+    namespace: shop
+  spec:
+    type: ClusterIP
+    selector: {app: payments}
+    ports:
+    - port: 80
+      protocol: TCP
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: external
+    namespace: shop
+  spec:
+    type: ExternalName
+- apiVersion: networking.k8s.io/v1
+  kind: Ingress
+  metadata:
+    name: shop
+    namespace: shop
+  spec:
+    rules:
+    - host: shop.example.com
+      http:
+        paths:
+        - path: /pay
+          backend:
+            service: {name: payments, port: {number: 80}}
+        - path: /other
+          backend:
+            service: {name: missing, port: {number: 80}}
+`
+
+// fakeKubectl puts a kubectl on PATH that records its arguments and prints
+// output
+func fakeKubectl(t *testing.T, output string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake kubectl is a shell script")
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "output.yaml"), []byte(output), 0600))
+	script := "#!/bin/sh\necho \"$@\" > \"" + filepath.Join(dir, "args") + "\"\ncat \"" + filepath.Join(dir, "output.yaml") + "\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kubectl"), []byte(script), 0700)) // #nosec G306 - test executable
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return filepath.Join(dir, "args")
+}
+
+func TestImportKubernetesCommand(t *testing.T) {
+	argsFile := fakeKubectl(t, testKubernetesResources)
+	srv, cfg := newTestAPI(t)
+	handleCatalogImport(srv)
+
+	cmd := ImportKubernetesCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Context:           "prod",
+		Namespace:         "shop",
+		Workers:           1,
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Found 4 resources and 2 relations")
+
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	assert.Equal(t, "get daemonsets,deployments,ingresses,services,statefulsets -o yaml --context prod --namespace shop\n", string(args))
+
+	var plurals []any
+	for _, request := range srv.received(http.MethodPost, "/api/v1/entities/definitions") {
+		plurals = append(plurals, request.JSON(t)["plural"])
+	}
+	assert.Equal(t, []any{"deployments", "ingresses", "services"}, plurals)
+
+	deployment := srv.requireRequest(http.MethodPost, "/api/v1/entities/kubernetes/v1/namespace/shop/deployments").JSON(t)
+	assert.Equal(t, map[string]any{
+		"cluster":  "prod",
+		"replicas": float64(3),
+		"images":   []any{"example/payments:1.2.3"},
+	}, deployment["spec"])
+	assert.Equal(t, map[string]any{"app": "payments"}, deployment["metadata"].(map[string]any)["labels"])
+	assert.Len(t, srv.received(http.MethodPost, "/api/v1/entities/kubernetes/v1/namespace/shop/services"), 2)
+	ingress := srv.requireRequest(http.MethodPost, "/api/v1/entities/kubernetes/v1/namespace/shop/ingresses").JSON(t)
+	assert.Equal(t, []any{"shop.example.com"}, ingress["spec"].(map[string]any)["hosts"])
+
+	var relations []string
+	for _, request := range srv.received(http.MethodPost, "/api/v1/entities/relations") {
+		body := request.JSON(t)
+		source := body["source"].(map[string]any)
+		target := body["target"].(map[string]any)
+		relations = append(relations, source["kind"].(string)+"/"+source["name"].(string)+" "+body["relation"].(string)+" "+target["kind"].(string)+"/"+target["name"].(string))
+	}
+	sort.Strings(relations)
+	assert.Equal(t, []string{
+		"ingresses/shop ROUTES_TO services/payments",
+		"services/payments ROUTES_TO deployments/payments",
+	}, relations)
+}
+
+func TestImportKubernetesCommand_KubectlFails(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake kubectl is a shell script")
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kubectl"), []byte("#!/bin/sh\necho 'context \"nope\" does not exist' >&2\nexit 1\n"), 0700)) // #nosec G306 - test executable
+	t.Setenv("PATH", dir)
+
+	cmd := ImportKubernetesCommand{Context: "nope", AllNamespaces: true}
+	_, err := captureOutput(t, cmd.Run)
+	assert.ErrorContains(t, err, `context "nope" does not exist`)
+}
@@ -0,0 +1,250 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/arctir/devgraph-cli/pkg/util"
+)
+
+// terraformGroup and terraformVersion are the group and version imported
+// Terraform resources are created under
+const (
+	terraformGroup   = "terraform"
+	terraformVersion = "v1"
+)
+
+// ImportTerraformCommand creates entities for the resources Terraform
+// manages, with relations for their dependencies
+type ImportTerraformCommand struct {
+	EnvWrapperCommand
+	Source        string `arg:"" required:"" help:"Terraform state file (- reads it from stdin), or a Terraform working directory whose state is read with 'terraform state pull' (from any backend)."`
+	Namespace     string `flag:"namespace,n" default:"default" help:"Namespace to create the entities in."`
+	Workers       int    `flag:"workers,w" help:"Number of concurrent workers (defaults to the default_workers setting, or 10)."`
+	EnforceLimits bool   `flag:"enforce-limits" help:"Abort instead of warning when the import would exceed the plan's entity quota."`
+}
+
+// terraformState is the part of a Terraform state file an import uses
+type terraformState struct {
+	Version   int `json:"version"`
+	Resources []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Provider  string `json:"provider"`
+		Instances []struct {
+			IndexKey     any            `json:"index_key"`
+			Attributes   map[string]any `json:"attributes"`
+			Dependencies []string       `json:"dependencies"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// terraformResource is an instance of a managed resource
+type terraformResource struct {
+	Address      string
+	Resource     string
+	Module       string
+	Type         string
+	Provider     string
+	Attributes   map[string]any
+	Dependencies []string
+}
+
+func (i *ImportTerraformCommand) Run() error {
+	data, err := i.readState()
+	if err != nil {
+		return err
+	}
+
+	var state terraformState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse Terraform state: %w", err)
+	}
+	if state.Version != 4 {
+		return fmt.Errorf("unsupported Terraform state version %d (expected 4)", state.Version)
+	}
+
+	catalog := convertTerraformState(state, i.Namespace)
+	fmt.Printf("Found %d managed resources and %d dependencies\n", len(catalog.Entities), len(catalog.Relations))
+	return importCatalog(i.Config, catalog, i.Workers, i.EnforceLimits)
+}
+
+// readState reads the source state file, or pulls the state of the source
+// working directory
+func (i *ImportTerraformCommand) readState() ([]byte, error) {
+	info, err := os.Stat(i.Source)
+	if err != nil && i.Source != util.StdinFile {
+		return nil, fmt.Errorf("failed to read %s: %w", i.Source, err)
+	}
+	if i.Source == util.StdinFile || !info.IsDir() {
+		data, err := util.ReadFileOrStdin(i.Source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", i.Source, err)
+		}
+		return data, nil
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("terraform", "-chdir="+i.Source, "state", "pull")
+	cmd.Stderr = &stderr
+	data, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull Terraform state in %s: %w: %s", i.Source, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return data, nil
+}
+
+// terraformResources lists the instances of managed resources in state.
+// Data sources aren't infrastructure Terraform manages, so they're left out.
+func terraformResources(state terraformState) []terraformResource {
+	var resources []terraformResource
+	for _, r := range state.Resources {
+		if r.Mode != "managed" {
+			continue
+		}
+		resource := r.Type + "." + r.Name
+		if r.Module != "" {
+			resource = r.Module + "." + resource
+		}
+		for _, instance := range r.Instances {
+			address := resource
+			switch key := instance.IndexKey.(type) {
+			case string:
+				address += fmt.Sprintf("[%q]", key)
+			case float64:
+				address += fmt.Sprintf("[%d]", int(key))
+			}
+			resources = append(resources, terraformResource{
+				Address:      address,
+				Resource:     resource,
+				Module:       r.Module,
+				Type:         r.Type,
+				Provider:     terraformProvider(r.Provider),
+				Attributes:   instance.Attributes,
+				Dependencies: instance.Dependencies,
+			})
+		}
+	}
+	return resources
+}
+
+// convertTerraformState converts the managed resources in state to entities
+// in namespace, with DEPENDS_ON relations for their dependencies
+func convertTerraformState(state terraformState, namespace string) importedCatalog {
+	resources := terraformResources(state)
+
+	// Dependencies name resources, which stand for all their instances
+	instances := map[string][]string{}
+	var catalog importedCatalog
+	for _, resource := range resources {
+		id := terraformEntityID(namespace, resource.Address)
+		instances[resource.Resource] = append(instances[resource.Resource], id)
+		catalog.Entities = append(catalog.Entities, terraformToEntity(resource, namespace))
+	}
+	if len(catalog.Entities) > 0 {
+		catalog.Definitions = []FilteredEntityDefinition{terraformDefinition()}
+	}
+
+	for _, resource := range resources {
+		source := terraformEntityID(namespace, resource.Address)
+		seen := map[string]bool{}
+		for _, dependency := range resource.Dependencies {
+			for _, target := range instances[dependency] {
+				if !seen[target] && target != source {
+					seen[target] = true
+					catalog.Relations = append(catalog.Relations, FilteredEntityRelation{Relation: "DEPENDS_ON", Source: source, Target: target})
+				}
+			}
+		}
+	}
+
+	sort.Slice(catalog.Relations, func(i, j int) bool {
+		if catalog.Relations[i].Source != catalog.Relations[j].Source {
+			return catalog.Relations[i].Source < catalog.Relations[j].Source
+		}
+		return catalog.Relations[i].Target < catalog.Relations[j].Target
+	})
+	return catalog
+}
+
+// terraformDefinition returns the definition of imported Terraform resources
+func terraformDefinition() FilteredEntityDefinition {
+	properties := map[string]any{}
+	for _, field := range []string{"address", "type", "provider", "module", "id", "arn"} {
+		properties[field] = map[string]any{"type": "string"}
+	}
+	properties["tags"] = map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}}
+
+	def := FilteredEntityDefinition{
+		Group:       terraformGroup,
+		Kind:        "Resource",
+		Name:        terraformVersion,
+		Description: "Infrastructure managed by Terraform",
+		Spec:        map[string]any{"type": "object", "properties": properties},
+	}
+	definitionNames(&def)
+	return def
+}
+
+// terraformToEntity converts a resource instance to an entity, keeping its
+// address, type, provider, cloud ID and tags
+func terraformToEntity(resource terraformResource, namespace string) FilteredEntity {
+	spec := map[string]any{
+		"address":  resource.Address,
+		"type":     resource.Type,
+		"provider": resource.Provider,
+	}
+	if resource.Module != "" {
+		spec["module"] = resource.Module
+	}
+	for _, field := range []string{"id", "arn"} {
+		if value, ok := resource.Attributes[field].(string); ok && value != "" {
+			spec[field] = value
+		}
+	}
+	if tags := stringMap(resource.Attributes["tags"]); len(tags) > 0 {
+		spec["tags"] = tags
+	}
+
+	return FilteredEntity{
+		ApiVersion: terraformGroup + "/" + terraformVersion,
+		Kind:       "Resource",
+		Metadata: map[string]interface{}{
+			"name":      terraformEntityName(resource.Address),
+			"namespace": namespace,
+		},
+		Spec: spec,
+	}
+}
+
+// terraformNameInvalid matches runs of characters entity names can't have
+var terraformNameInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+// terraformEntityName turns a resource address like module.net.aws_subnet.private["a"]
+// into an entity name like module-net-aws-subnet-private-a
+func terraformEntityName(address string) string {
+	return strings.Trim(terraformNameInvalid.ReplaceAllString(strings.ToLower(address), "-"), "-")
+}
+
+func terraformEntityID(namespace, address string) string {
+	return fmt.Sprintf("%s/%s/resources/%s/%s", terraformGroup, terraformVersion, namespace, terraformEntityName(address))
+}
+
+// terraformProvider returns the provider source of a state provider
+// reference like provider["registry.terraform.io/hashicorp/aws"].alias
+func terraformProvider(provider string) string {
+	if _, rest, ok := strings.Cut(provider, `provider["`); ok {
+		if source, _, ok := strings.Cut(rest, `"]`); ok {
+			return source
+		}
+	}
+	return provider
+}
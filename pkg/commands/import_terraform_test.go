@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTerraformState = `{
+  "version": 4,
+  "terraform_version": "1.9.0",
+  "resources": [
+    {
+      "mode": "data",
+      "type": "aws_ami",
+      "name": "ubuntu",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [{"attributes": {"id": "ami-123"}}]
+    },
+    {
+      "module": "module.network",
+      "mode": "managed",
+      "type": "aws_vpc",
+      "name": "main",
+      "provider": "module.network.provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [{"attributes": {"id": "vpc-1", "arn": "arn:aws:ec2:us-east-1:1:vpc/vpc-1", "tags": {"team": "platform"}}}]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "web",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"].east",
+      "instances": [
+        {"index_key": 0, "attributes": {"id": "i-1"}, "dependencies": ["data.aws_ami.ubuntu", "module.network.aws_vpc.main"]},
+        {"index_key": 1, "attributes": {"id": "i-2"}, "dependencies": ["module.network.aws_vpc.main"]}
+      ]
+    }
+  ]
+}`
+
+func TestImportTerraformCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "terraform.tfstate")
+	require.NoError(t, os.WriteFile(path, []byte(testTerraformState), 0600))
+
+	srv, cfg := newTestAPI(t)
+	handleCatalogImport(srv)
+
+	cmd := ImportTerraformCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Source: path, Namespace: "infra", Workers: 1}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Found 3 managed resources and 2 dependencies")
+
+	definition := srv.requireRequest(http.MethodPost, "/api/v1/entities/definitions").JSON(t)
+	assert.Equal(t, "terraform", definition["group"])
+	assert.Equal(t, "Resource", definition["kind"])
+
+	var names []any
+	var vpc map[string]any
+	for _, request := range srv.received(http.MethodPost, "/api/v1/entities/terraform/v1/namespace/infra/resources") {
+		body := request.JSON(t)
+		name := body["metadata"].(map[string]any)["name"]
+		names = append(names, name)
+		if name == "module-network-aws-vpc-main" {
+			vpc = body
+		}
+	}
+	assert.ElementsMatch(t, []any{"module-network-aws-vpc-main", "aws-instance-web-0", "aws-instance-web-1"}, names)
+	require.NotNil(t, vpc)
+	assert.Equal(t, map[string]any{
+		"address":  "module.network.aws_vpc.main",
+		"type":     "aws_vpc",
+		"provider": "registry.terraform.io/hashicorp/aws",
+		"module":   "module.network",
+		"id":       "vpc-1",
+		"arn":      "arn:aws:ec2:us-east-1:1:vpc/vpc-1",
+		"tags":     map[string]any{"team": "platform"},
+	}, vpc["spec"])
+
+	var relations []string
+	for _, request := range srv.received(http.MethodPost, "/api/v1/entities/relations") {
+		body := request.JSON(t)
+		relations = append(relations, body["source"].(map[string]any)["name"].(string)+" "+body["relation"].(string)+" "+body["target"].(map[string]any)["name"].(string))
+	}
+	assert.ElementsMatch(t, []string{
+		"aws-instance-web-0 DEPENDS_ON module-network-aws-vpc-main",
+		"aws-instance-web-1 DEPENDS_ON module-network-aws-vpc-main",
+	}, relations)
+}
+
+func TestImportTerraformCommand_Backend(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake terraform is a shell script")
+	}
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "state.json"), []byte(testTerraformState), 0600))
+	script := "#!/bin/sh\n[ \"$1 $2 $3\" = \"-chdir=" + bin + " state pull\" ] || exit 2\ncat \"" + filepath.Join(bin, "state.json") + "\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "terraform"), []byte(script), 0700)) // #nosec G306 - test executable
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	srv, cfg := newTestAPI(t)
+	cmd := ImportTerraformCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Source: bin, Namespace: "default", Workers: 1}
+	cmd.DryRun = true
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Dry run: Would import 1 definitions, 3 entities, and 2 relations:")
+	assert.Contains(t, output, "Relation: terraform/v1/resources/default/aws-instance-web-0 -> terraform/v1/resources/default/module-network-aws-vpc-main (DEPENDS_ON)")
+	assert.Empty(t, srv.received(http.MethodPost, "/api/v1/entities/definitions"))
+}
+
+func TestImportTerraformCommand_UnsupportedVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "terraform.tfstate")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 3, "modules": []}`), 0600))
+
+	cmd := ImportTerraformCommand{Source: path}
+	_, err := captureOutput(t, cmd.Run)
+	assert.ErrorContains(t, err, "unsupported Terraform state version 3")
+}
+
+func TestTerraformEntityName(t *testing.T) {
+	assert.Equal(t, "module-net-aws-subnet-private-a", terraformEntityName(`module.net.aws_subnet.private["a"]`))
+	assert.Equal(t, "aws-instance-web-0", terraformEntityName("aws_instance.web[0]"))
+}
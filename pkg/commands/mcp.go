@@ -2,9 +2,11 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
+	"github.com/arctir/devgraph-cli/pkg/output"
 	"github.com/arctir/devgraph-cli/pkg/util"
 	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
 	"github.com/google/uuid"
@@ -16,6 +18,7 @@ type MCPCommand struct {
 	List   MCPListCommand   `cmd:"" help:"List MCP resources."`
 	Update MCPUpdateCommand `cmd:"update" help:"Update an existing MCP resource by ID."`
 	Delete MCPDeleteCommand `cmd:"delete" help:"Delete an MCP resource by ID."`
+	Test   MCPTestCommand   `cmd:"test" help:"Check that Devgraph can reach an MCP endpoint and list its tools."`
 }
 
 type MCPCreateCommand struct {
@@ -31,7 +34,6 @@ type MCPCreateCommand struct {
 
 type MCPListCommand struct {
 	EnvWrapperCommand
-	Output string `short:"o" help:"Output format: table, json, yaml" default:"table"`
 }
 
 type MCPGetCommand struct {
@@ -56,6 +58,21 @@ type MCPDeleteCommand struct {
 	Id string `arg:"" required:"" help:"ID of the MCP resource to delete."`
 }
 
+// MCPTestCommand lists the tools of an MCP endpoint as seen by the Devgraph
+// server, which connects using the endpoint's headers and linked OAuth
+// service token
+type MCPTestCommand struct {
+	EnvWrapperCommand
+	Id string `arg:"" required:"" help:"ID of the MCP resource to test."`
+}
+
+// mcpTool is a tool advertised by an MCP endpoint
+type mcpTool struct {
+	Name        string         `json:"name" yaml:"name"`
+	Description string         `json:"description,omitempty" yaml:"description,omitempty"`
+	InputSchema map[string]any `json:"inputSchema,omitempty" yaml:"inputSchema,omitempty"`
+}
+
 func (e *MCPCreateCommand) Run() error {
 	client, err := util.GetAuthenticatedClient(e.Config)
 	if err != nil {
@@ -139,6 +156,12 @@ func (e *MCPGetCommand) Run() error {
 	// Check the response type
 	switch r := resp.(type) {
 	case *api.MCPEndpointResponse:
+		if format := e.OutputFormat(output.Table); format != output.Table {
+			result := mcpResult([]api.MCPEndpointResponse{*r})
+			result.Data = newMCPOutput(*r)
+			return output.Print(format, result)
+		}
+
 		description := ""
 		if desc, ok := r.Description.Get(); ok {
 			description = desc
@@ -178,47 +201,7 @@ func (e *MCPListCommand) Run() error {
 	// Check the response type
 	switch r := resp.(type) {
 	case *api.GetMcpendpointsOKApplicationJSON:
-		endpoints := []api.MCPEndpointResponse(*r)
-		if len(endpoints) == 0 {
-			fmt.Println("No MCP endpoints found.")
-			return nil
-		}
-
-		type mcpOutput struct {
-			ID             string `json:"id" yaml:"id"`
-			Name           string `json:"name" yaml:"name"`
-			URL            string `json:"url" yaml:"url"`
-			OAuthServiceID string `json:"oauth_service_id,omitempty" yaml:"oauth_service_id,omitempty"`
-		}
-
-		structured := make([]mcpOutput, len(endpoints))
-		tableData := make([]map[string]any, len(endpoints))
-		for i, endpoint := range endpoints {
-			oauthServiceID := ""
-			if oauth, ok := endpoint.OAuthServiceID.Get(); ok {
-				oauthServiceID = oauth.String()
-			} else if endpoint.OAuthServiceID.IsNull() {
-				oauthServiceID = "(null)"
-			} else {
-				oauthServiceID = "(not set)"
-			}
-
-			structured[i] = mcpOutput{
-				ID:             endpoint.ID.String(),
-				Name:           endpoint.Name,
-				URL:            endpoint.URL,
-				OAuthServiceID: oauthServiceID,
-			}
-			tableData[i] = map[string]any{
-				"ID":               endpoint.ID.String(),
-				"Name":             endpoint.Name,
-				"URL":              endpoint.URL,
-				"OAuth Service ID": oauthServiceID,
-			}
-		}
-
-		headers := []string{"ID", "Name", "URL", "OAuth Service ID"}
-		return util.FormatOutput(e.Output, structured, headers, tableData)
+		return output.Print(e.OutputFormat(output.Table), mcpResult(*r))
 	default:
 		return fmt.Errorf("failed to list MCP endpoints")
 	}
@@ -343,3 +326,130 @@ func (e *MCPUpdateCommand) Run() error {
 
 	return nil
 }
+
+func (e *MCPTestCommand) Run() error {
+	format := e.OutputFormat(output.Table)
+	if err := output.Check(format, output.Table, output.JSON, output.YAML, output.Name); err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	mcpUUID, err := uuid.Parse(e.Id)
+	if err != nil {
+		return fmt.Errorf("invalid UUID: %w", err)
+	}
+
+	resp, err := client.ListMcpendpointTools(context.Background(), api.ListMcpendpointToolsParams{
+		McpendpointID: mcpUUID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list MCP endpoint tools: %w", err)
+	}
+
+	var tools []mcpTool
+	switch r := resp.(type) {
+	case *api.ListMcpendpointToolsOKApplicationJSON:
+		tools = make([]mcpTool, 0, len(*r))
+		for _, item := range *r {
+			var tool mcpTool
+			for key, target := range map[string]any{
+				"name":        &tool.Name,
+				"description": &tool.Description,
+				"inputSchema": &tool.InputSchema,
+			} {
+				if raw, ok := item[key]; ok {
+					if err := json.Unmarshal(raw, target); err != nil {
+						return fmt.Errorf("failed to parse tool %s: %w", key, err)
+					}
+				}
+			}
+			tools = append(tools, tool)
+		}
+	case *api.ListMcpendpointToolsNotFound:
+		return fmt.Errorf("MCP endpoint with ID '%s' not found", e.Id)
+	case *api.HTTPValidationError:
+		return fmt.Errorf("validation error: %v", r.Detail)
+	default:
+		return fmt.Errorf("unexpected response type: %T", resp)
+	}
+
+	if format != output.Table {
+		names := make([]string, len(tools))
+		for i, tool := range tools {
+			names[i] = tool.Name
+		}
+		return output.Print(format, output.Result{Data: tools, Names: names})
+	}
+
+	fmt.Printf("✅ Devgraph connected to MCP endpoint '%s'.\n", e.Id)
+	if len(tools) == 0 {
+		fmt.Println("No tools available.")
+		return nil
+	}
+
+	tableData := make([]map[string]any, len(tools))
+	for i, tool := range tools {
+		tableData[i] = map[string]any{
+			"Tool":        tool.Name,
+			"Description": tool.Description,
+		}
+	}
+	output.PrintTable([]string{"Tool", "Description"}, tableData)
+	return nil
+}
+
+// mcpOutput is an MCP endpoint as printed as JSON or YAML
+type mcpOutput struct {
+	ID             string `json:"id" yaml:"id"`
+	Name           string `json:"name" yaml:"name"`
+	URL            string `json:"url" yaml:"url"`
+	Description    string `json:"description,omitempty" yaml:"description,omitempty"`
+	OAuthServiceID string `json:"oauth_service_id,omitempty" yaml:"oauth_service_id,omitempty"`
+}
+
+func newMCPOutput(endpoint api.MCPEndpointResponse) mcpOutput {
+	oauthServiceID := ""
+	if oauth, ok := endpoint.OAuthServiceID.Get(); ok {
+		oauthServiceID = oauth.String()
+	} else if endpoint.OAuthServiceID.IsNull() {
+		oauthServiceID = "(null)"
+	} else {
+		oauthServiceID = "(not set)"
+	}
+	description, _ := endpoint.Description.Get()
+
+	return mcpOutput{
+		ID:             endpoint.ID.String(),
+		Name:           endpoint.Name,
+		URL:            endpoint.URL,
+		Description:    description,
+		OAuthServiceID: oauthServiceID,
+	}
+}
+
+// mcpResult is how MCP endpoints print in each output format. Endpoints are
+// named by ID, which the other mcp commands take.
+func mcpResult(endpoints []api.MCPEndpointResponse) output.Result {
+	result := output.Result{
+		Headers: []string{"ID", "Name", "URL", "OAuth Service ID"},
+		Empty:   "No MCP endpoints found.",
+		Names:   []string{},
+	}
+	structured := make([]mcpOutput, len(endpoints))
+	for i, endpoint := range endpoints {
+		structured[i] = newMCPOutput(endpoint)
+		result.Rows = append(result.Rows, map[string]any{
+			"ID":               structured[i].ID,
+			"Name":             endpoint.Name,
+			"URL":              endpoint.URL,
+			"OAuth Service ID": structured[i].OAuthServiceID,
+		})
+		result.Names = append(result.Names, structured[i].ID)
+	}
+	result.Data = structured
+	return result
+}
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/arctir/devgraph-cli/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMCPEndpointID = "22222222-2222-2222-2222-222222222222"
+
+func TestMCPTestCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/mcp/endpoints/{id}/tools", http.StatusOK, []map[string]any{
+		{
+			"name":        "search",
+			"description": "Search the catalog",
+			"inputSchema": map[string]any{"type": "object"},
+		},
+		{"name": "ping"},
+	})
+
+	cmd := MCPTestCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Id:                testMCPEndpointID,
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	req := srv.requireRequest(http.MethodGet, "/api/v1/mcp/endpoints/"+testMCPEndpointID+"/tools")
+	assert.Equal(t, testEnvironmentID, req.Header.Get("Devgraph-Environment"))
+	assert.Contains(t, output, "search")
+	assert.Contains(t, output, "Search the catalog")
+	assert.Contains(t, output, "ping")
+}
+
+func TestMCPTestCommand_NotFound(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/mcp/endpoints/{id}/tools", http.StatusNotFound, map[string]any{"detail": "not found"})
+
+	cmd := MCPTestCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Id:                testMCPEndpointID,
+	}
+	_, err := captureOutput(t, cmd.Run)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestMCPCreateCommand_DryRun(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+
+	cfg.DryRun = true
+	cmd := MCPCreateCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Name:              "search",
+		Url:               "https://mcp.example.com",
+		Headers:           []string{"Authorization: Bearer secret"},
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.ErrorIs(t, err, util.ErrDryRun)
+
+	assert.Empty(t, srv.received(http.MethodPost, "/api/v1/mcp/endpoints"))
+	assert.Contains(t, output, "Dry run: Would create (POST /api/v1/mcp/endpoints)")
+	assert.Contains(t, output, `"name":"search"`)
+	assert.Contains(t, output, `"url":"https://mcp.example.com"`)
+	assert.NotContains(t, output, "secret")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/arctir/devgraph-cli/pkg/output"
 	"github.com/arctir/devgraph-cli/pkg/util"
 	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
 	"github.com/google/uuid"
@@ -26,7 +27,6 @@ type ModelCreateCommand struct {
 
 type ModelListCommand struct {
 	EnvWrapperCommand
-	Output string `short:"o" help:"Output format: table, json, yaml" default:"table"`
 }
 
 type ModelGetCommand struct {
@@ -96,12 +96,12 @@ func (e *ModelGetCommand) Run() error {
 	// Check the response type
 	switch r := resp.(type) {
 	case *api.ModelResponse:
-		models := []api.ModelResponse{*r}
-		displayModels(&models)
+		result := modelResult([]api.ModelResponse{*r})
+		result.Data = newModelOutput(*r)
+		return output.Print(e.OutputFormat(output.Table), result)
 	default:
 		return fmt.Errorf("model with name '%s' not found", e.Id)
 	}
-	return nil
 }
 
 func (e *ModelListCommand) Run() error {
@@ -118,35 +118,7 @@ func (e *ModelListCommand) Run() error {
 	// Check the response type
 	switch r := resp.(type) {
 	case *api.GetModelsOKApplicationJSON:
-		models := []api.ModelResponse(*r)
-		if len(models) == 0 {
-			fmt.Println("No models found.")
-			return nil
-		}
-
-		type modelOutput struct {
-			ID         string `json:"id" yaml:"id"`
-			Name       string `json:"name" yaml:"name"`
-			ProviderID string `json:"provider_id" yaml:"provider_id"`
-		}
-
-		structured := make([]modelOutput, len(models))
-		tableData := make([]map[string]any, len(models))
-		for i, model := range models {
-			structured[i] = modelOutput{
-				ID:         model.ID.String(),
-				Name:       model.Name,
-				ProviderID: model.ProviderID.String(),
-			}
-			tableData[i] = map[string]any{
-				"ID":          model.ID.String(),
-				"Name":        model.Name,
-				"Provider ID": model.ProviderID.String(),
-			}
-		}
-
-		headers := []string{"ID", "Name", "Provider ID"}
-		return util.FormatOutput(e.Output, structured, headers, tableData)
+		return output.Print(e.OutputFormat(output.Table), modelResult(*r))
 	default:
 		return fmt.Errorf("failed to list models")
 	}
@@ -175,20 +147,44 @@ func (e *ModelDeleteCommand) Run() error {
 	return nil
 }
 
-func displayModels(models *[]api.ModelResponse) {
-	if models == nil || len(*models) == 0 {
-		fmt.Println("No models found.")
-		return
+// modelOutput is a model as printed as JSON or YAML
+type modelOutput struct {
+	ID         string `json:"id" yaml:"id"`
+	Name       string `json:"name" yaml:"name"`
+	ProviderID string `json:"provider_id" yaml:"provider_id"`
+}
+
+func newModelOutput(model api.ModelResponse) modelOutput {
+	return modelOutput{
+		ID:         model.ID.String(),
+		Name:       model.Name,
+		ProviderID: model.ProviderID.String(),
 	}
+}
 
-	headers := []string{"ID", "Name", "Provider ID"}
-	data := make([]map[string]interface{}, len(*models))
-	for i, model := range *models {
-		data[i] = map[string]interface{}{
+// modelResult is how models print in each output format. Models are named
+// by name, which the other model commands take.
+func modelResult(models []api.ModelResponse) output.Result {
+	result := output.Result{
+		Headers: []string{"ID", "Name", "Provider ID"},
+		Empty:   "No models found.",
+		Names:   []string{},
+	}
+	structured := make([]modelOutput, len(models))
+	for i, model := range models {
+		structured[i] = newModelOutput(model)
+		result.Rows = append(result.Rows, map[string]any{
+			"ID":          structured[i].ID,
 			"Name":        model.Name,
-			"ID":          model.ID,
-			"Provider ID": model.ProviderID,
-		}
+			"Provider ID": structured[i].ProviderID,
+		})
+		result.Names = append(result.Names, model.Name)
 	}
-	util.DisplaySimpleTable(data, headers)
+	result.Data = structured
+	return result
+}
+
+func displayModels(models *[]api.ModelResponse) {
+	result := modelResult(*models)
+	output.PrintTable(result.Headers, result.Rows)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/arctir/devgraph-cli/pkg/output"
 	"github.com/arctir/devgraph-cli/pkg/util"
 	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
 	"github.com/google/uuid"
@@ -26,7 +27,6 @@ type ModelProviderCreateCommand struct {
 
 type ModelProviderListCommand struct {
 	EnvWrapperCommand
-	Output string `short:"o" help:"Output format: table, json, yaml" default:"table"`
 }
 
 type ModelProviderGetCommand struct {
@@ -124,12 +124,12 @@ func (e *ModelProviderGetCommand) Run() error {
 	// Check the response type
 	switch r := resp.(type) {
 	case *api.ModelProviderResponse:
-		fmt.Printf("Model provider found: %v\n", *r)
+		result := modelProviderResult([]api.ModelProviderResponse{*r})
+		result.Data = newModelProviderOutput(*r)
+		return output.Print(e.OutputFormat(output.Table), result)
 	default:
 		return fmt.Errorf("model provider with ID '%s' not found", e.Id)
 	}
-
-	return nil
 }
 
 func (e *ModelProviderListCommand) Run() error {
@@ -146,44 +146,7 @@ func (e *ModelProviderListCommand) Run() error {
 	// Check the response type
 	switch r := resp.(type) {
 	case *api.GetModelprovidersOKApplicationJSON:
-		providers := []api.ModelProviderResponse(*r)
-		if len(providers) == 0 {
-			fmt.Println("No model providers found.")
-			return nil
-		}
-
-		type providerOutput struct {
-			ID   string `json:"id" yaml:"id"`
-			Name string `json:"name" yaml:"name"`
-			Type string `json:"type" yaml:"type"`
-		}
-
-		structured := make([]providerOutput, len(providers))
-		tableData := make([]map[string]any, len(providers))
-		for i, provider := range providers {
-			var name, id, providerType string
-			if provider.IsXAIModelProviderResponse() {
-				if p, ok := provider.GetXAIModelProviderResponse(); ok {
-					name, id, providerType = p.Name, p.ID.String(), "xai"
-				}
-			} else if provider.IsOpenAIModelProviderResponse() {
-				if p, ok := provider.GetOpenAIModelProviderResponse(); ok {
-					name, id, providerType = p.Name, p.ID.String(), "openai"
-				}
-			} else if provider.IsAnthropicModelProviderResponse() {
-				if p, ok := provider.GetAnthropicModelProviderResponse(); ok {
-					name, id, providerType = p.Name, p.ID.String(), "anthropic"
-				}
-			} else {
-				name, id, providerType = "Unknown", "Unknown", "unknown"
-			}
-
-			structured[i] = providerOutput{ID: id, Name: name, Type: providerType}
-			tableData[i] = map[string]any{"ID": id, "Name": name, "Type": providerType}
-		}
-
-		headers := []string{"ID", "Name", "Type"}
-		return util.FormatOutput(e.Output, structured, headers, tableData)
+		return output.Print(e.OutputFormat(output.Table), modelProviderResult(*r))
 	default:
 		return fmt.Errorf("failed to list model providers")
 	}
@@ -215,3 +178,45 @@ func (e *ModelProviderDeleteCommand) Run() error {
 	fmt.Printf("✅ Model provider '%s' deleted successfully.\n", e.Id)
 	return nil
 }
+
+// modelProviderOutput is a model provider as printed as JSON or YAML
+type modelProviderOutput struct {
+	ID   string `json:"id" yaml:"id"`
+	Name string `json:"name" yaml:"name"`
+	Type string `json:"type" yaml:"type"`
+}
+
+func newModelProviderOutput(provider api.ModelProviderResponse) modelProviderOutput {
+	if p, ok := provider.GetXAIModelProviderResponse(); ok {
+		return modelProviderOutput{ID: p.ID.String(), Name: p.Name, Type: "xai"}
+	}
+	if p, ok := provider.GetOpenAIModelProviderResponse(); ok {
+		return modelProviderOutput{ID: p.ID.String(), Name: p.Name, Type: "openai"}
+	}
+	if p, ok := provider.GetAnthropicModelProviderResponse(); ok {
+		return modelProviderOutput{ID: p.ID.String(), Name: p.Name, Type: "anthropic"}
+	}
+	return modelProviderOutput{ID: "Unknown", Name: "Unknown", Type: "unknown"}
+}
+
+// modelProviderResult is how model providers print in each output format.
+// Providers are named by ID, which the other model provider commands take.
+func modelProviderResult(providers []api.ModelProviderResponse) output.Result {
+	result := output.Result{
+		Headers: []string{"ID", "Name", "Type"},
+		Empty:   "No model providers found.",
+		Names:   []string{},
+	}
+	structured := make([]modelProviderOutput, len(providers))
+	for i, provider := range providers {
+		structured[i] = newModelProviderOutput(provider)
+		result.Rows = append(result.Rows, map[string]any{
+			"ID":   structured[i].ID,
+			"Name": structured[i].Name,
+			"Type": structured[i].Type,
+		})
+		result.Names = append(result.Names, structured[i].ID)
+	}
+	result.Data = structured
+	return result
+}
@@ -2,18 +2,29 @@ package commands
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/arctir/devgraph-cli/pkg/logging"
+	"github.com/arctir/devgraph-cli/pkg/output"
 	"github.com/arctir/devgraph-cli/pkg/util"
 	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
 	"github.com/google/uuid"
 	"github.com/int128/oauth2cli"
+	"github.com/int128/oauth2cli/oauth2params"
+	"github.com/pkg/browser"
 	"golang.org/x/oauth2"
+	"gopkg.in/yaml.v3"
 )
 
 type OAuthServiceCommand struct {
@@ -23,16 +34,21 @@ type OAuthServiceCommand struct {
 	Delete    OAuthServiceDeleteCommand    `cmd:"delete" help:"Delete an OAuth service by ID."`
 	Update    OAuthServiceUpdateCommand    `cmd:"update" help:"Update an OAuth service by ID."`
 	Authorize OAuthServiceAuthorizeCommand `cmd:"authorize" help:"Authorize against an OAuth provider."`
+	Connect   OAuthServiceConnectCommand   `cmd:"connect" help:"Connect your account to an OAuth service so Devgraph can act on your behalf."`
+	Tokens    OAuthServiceTokensCommand    `cmd:"tokens" help:"List the OAuth services your account is connected to."`
+	Revoke    OAuthServiceRevokeCommand    `cmd:"revoke" help:"Revoke the token Devgraph holds for an OAuth service."`
+	Test      OAuthServiceTestCommand      `cmd:"test" help:"Validate the endpoints and grant types of an OAuth service."`
+	Export    OAuthServiceExportCommand    `cmd:"export" help:"Export an OAuth service as a spec file for use with --from-file."`
 }
 
 type OAuthServiceCreateCommand struct {
 	EnvWrapperCommand
-	Name                string   `arg:"" required:"" help:"Unique name identifier for the OAuth service."`
-	DisplayName         string   `arg:"" required:"" help:"Human-readable display name for the OAuth service."`
-	OAuthClientID       string   `arg:"" required:"" help:"OAuth client ID."`
-	OAuthClientSecret   string   `arg:"" required:"" help:"OAuth client secret."`
-	AuthorizationURL    string   `arg:"" required:"" help:"OAuth authorization endpoint URL."`
-	TokenURL            string   `arg:"" required:"" help:"OAuth token endpoint URL."`
+	Name                string   `arg:"" optional:"" help:"Unique name identifier for the OAuth service."`
+	DisplayName         string   `arg:"" optional:"" help:"Human-readable display name for the OAuth service."`
+	OAuthClientID       string   `arg:"" optional:"" help:"OAuth client ID."`
+	OAuthClientSecret   string   `arg:"" optional:"" help:"OAuth client secret."`
+	AuthorizationURL    string   `arg:"" optional:"" help:"OAuth authorization endpoint URL."`
+	TokenURL            string   `arg:"" optional:"" help:"OAuth token endpoint URL."`
 	SupportedGrantTypes []string `arg:"" optional:"" help:"Supported OAuth grant types (defaults to: authorization_code)."`
 	Description         *string  `flag:"description" optional:"" help:"Optional description of the OAuth service."`
 	UserinfoURL         *string  `flag:"userinfo-url" optional:"" help:"Optional userinfo endpoint URL."`
@@ -40,12 +56,12 @@ type OAuthServiceCreateCommand struct {
 	IsActive            *bool    `flag:"is-active" optional:"" help:"Whether the OAuth service is active."`
 	IconURL             *string  `flag:"icon-url" optional:"" help:"Optional icon URL."`
 	HomepageURL         *string  `flag:"homepage-url" optional:"" help:"Optional homepage URL."`
+	FromFile            string   `flag:"from-file,f" optional:"" help:"Create from a YAML/JSON spec file, or - to read it from stdin. Updates the service instead if one with the same name exists."`
 }
 
 type OAuthServiceListCommand struct {
 	EnvWrapperCommand
-	ActiveOnly *bool  `flag:"active-only" optional:"" help:"Only return active services."`
-	Output     string `short:"o" help:"Output format: table, json, yaml" default:"table"`
+	ActiveOnly *bool `flag:"active-only" optional:"" help:"Only return active services."`
 }
 
 type OAuthServiceGetCommand struct {
@@ -73,18 +89,169 @@ type OAuthServiceUpdateCommand struct {
 	IsActive            *bool    `flag:"update-is-active" optional:"" help:"Whether the OAuth service is active."`
 	IconURL             *string  `flag:"update-icon-url" optional:"" help:"Icon URL."`
 	HomepageURL         *string  `flag:"update-homepage-url" optional:"" help:"Homepage URL."`
+	FromFile            string   `flag:"from-file,f" optional:"" help:"Apply fields from a YAML/JSON spec file, or - to read it from stdin."`
+}
+
+type OAuthServiceExportCommand struct {
+	EnvWrapperCommand
+	ID   string `arg:"" required:"" help:"ID of the OAuth service to export."`
+	File string `flag:"file" optional:"" help:"Write the spec to a file instead of stdout."`
+}
+
+// oauthServiceSpec is the on-disk representation of an OAuth service used by
+// --from-file and export. Client credentials may reference environment
+// variables as ${NAME} (e.g. ${GITHUB_CLIENT_SECRET}) so secrets stay out of
+// git. A bare $ is kept as is, since secrets often contain one.
+type oauthServiceSpec struct {
+	Name                string   `json:"name" yaml:"name"`
+	DisplayName         string   `json:"display_name" yaml:"display_name"`
+	Description         *string  `json:"description,omitempty" yaml:"description,omitempty"`
+	ClientID            string   `json:"client_id,omitempty" yaml:"client_id,omitempty"`
+	ClientSecret        string   `json:"client_secret,omitempty" yaml:"client_secret,omitempty"`
+	AuthorizationURL    string   `json:"authorization_url" yaml:"authorization_url"`
+	TokenURL            string   `json:"token_url" yaml:"token_url"`
+	UserinfoURL         *string  `json:"userinfo_url,omitempty" yaml:"userinfo_url,omitempty"`
+	SupportedGrantTypes []string `json:"supported_grant_types,omitempty" yaml:"supported_grant_types,omitempty"`
+	DefaultScopes       []string `json:"default_scopes,omitempty" yaml:"default_scopes,omitempty"`
+	IsActive            *bool    `json:"is_active,omitempty" yaml:"is_active,omitempty"`
+	IconURL             *string  `json:"icon_url,omitempty" yaml:"icon_url,omitempty"`
+	HomepageURL         *string  `json:"homepage_url,omitempty" yaml:"homepage_url,omitempty"`
+}
+
+// loadOAuthServiceSpec reads a YAML or JSON spec file. YAML is a superset of
+// JSON so a single decoder handles both.
+func loadOAuthServiceSpec(path string) (*oauthServiceSpec, error) {
+	data, err := util.ReadFileOrStdin(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec file: %w", err)
+	}
+
+	var spec oauthServiceSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse spec file: %w", err)
+	}
+
+	if spec.ClientID, err = expandSpecEnv(spec.ClientID); err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+	if spec.ClientSecret, err = expandSpecEnv(spec.ClientSecret); err != nil {
+		return nil, fmt.Errorf("invalid client_secret: %w", err)
+	}
+
+	if spec.Name == "" {
+		return nil, fmt.Errorf("spec file is missing required field 'name'")
+	}
+
+	return &spec, nil
+}
+
+var specEnvPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandSpecEnv replaces ${NAME} references with the value of the
+// environment variable NAME. Unlike os.ExpandEnv it leaves $NAME alone, so
+// literal secrets containing $ survive.
+func expandSpecEnv(value string) (string, error) {
+	var missing []string
+	expanded := specEnvPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		name := specEnvPattern.FindStringSubmatch(ref)[1]
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// applyTo copies the spec onto an update command, leaving credentials
+// untouched when the spec doesn't provide them
+func (s *oauthServiceSpec) applyTo(c *OAuthServiceUpdateCommand) {
+	if s.DisplayName != "" {
+		c.DisplayName = &s.DisplayName
+	}
+	if s.ClientID != "" {
+		c.OAuthClientID = &s.ClientID
+	}
+	if s.ClientSecret != "" {
+		c.OAuthClientSecret = &s.ClientSecret
+	}
+	if s.AuthorizationURL != "" {
+		c.AuthorizationURL = &s.AuthorizationURL
+	}
+	if s.TokenURL != "" {
+		c.TokenURL = &s.TokenURL
+	}
+	if s.SupportedGrantTypes != nil {
+		c.SupportedGrantTypes = s.SupportedGrantTypes
+	}
+	if s.Description != nil {
+		c.Description = s.Description
+	}
+	if s.UserinfoURL != nil {
+		c.UserinfoURL = s.UserinfoURL
+	}
+	if s.DefaultScopes != nil {
+		c.DefaultScopes = s.DefaultScopes
+	}
+	if s.IsActive != nil {
+		c.IsActive = s.IsActive
+	}
+	if s.IconURL != nil {
+		c.IconURL = s.IconURL
+	}
+	if s.HomepageURL != nil {
+		c.HomepageURL = s.HomepageURL
+	}
 }
 
 type OAuthServiceAuthorizeCommand struct {
 	EnvWrapperCommand
 	ServiceID    string   `arg:"" required:"" help:"ID of the OAuth service to authorize against."`
 	ClientID     string   `arg:"" required:"" help:"OAuth client ID (not returned from API for security)."`
-	ClientSecret string   `arg:"" required:"" help:"OAuth client secret (not stored for security)."`
+	ClientSecret string   `arg:"" optional:"" help:"OAuth client secret (not stored for security). Omit for public clients using PKCE."`
 	Scopes       []string `flag:"scopes" optional:"" help:"OAuth scopes to request (uses service defaults if not specified)."`
 	RedirectPort *int     `flag:"redirect-port" default:"40000" help:"Local port for OAuth callback (default: 40000)."`
+	PKCE         *bool    `flag:"pkce" negatable:"" optional:"" help:"Use PKCE (S256 code challenge). Defaults to on unless the provider advertises no S256 support."`
+}
+
+// OAuthServiceConnectCommand authorizes an OAuth service through the
+// Devgraph API. The server builds the authorization URL and exchanges the
+// code, so the resulting token is held by Devgraph rather than on disk.
+type OAuthServiceConnectCommand struct {
+	EnvWrapperCommand
+	ServiceID    string        `arg:"" required:"" help:"ID of the OAuth service to connect."`
+	Scopes       []string      `flag:"scopes" optional:"" help:"OAuth scopes to request (uses service defaults if not specified)."`
+	RedirectPort int           `flag:"redirect-port" default:"40000" help:"Local port for OAuth callback (default: 40000)."`
+	Wait         time.Duration `flag:"wait" default:"5m" help:"How long to wait for the authorization to complete."`
+}
+
+type OAuthServiceTokensCommand struct {
+	EnvWrapperCommand
+}
+
+type OAuthServiceRevokeCommand struct {
+	EnvWrapperCommand
+	ServiceName string `arg:"" required:"" help:"Name of the OAuth service to revoke the token for."`
+}
+
+type OAuthServiceTestCommand struct {
+	EnvWrapperCommand
+	ID string `arg:"" required:"" help:"ID of the OAuth service to test."`
 }
 
 func (c *OAuthServiceCreateCommand) Run() error {
+	if c.FromFile != "" {
+		return c.runFromFile()
+	}
+
+	if c.Name == "" || c.DisplayName == "" || c.OAuthClientID == "" || c.OAuthClientSecret == "" ||
+		c.AuthorizationURL == "" || c.TokenURL == "" {
+		return fmt.Errorf("name, display name, client ID, client secret, authorization URL and token URL are required (or use --from-file)")
+	}
+
 	// Set default grant type if none provided
 	if len(c.SupportedGrantTypes) == 0 {
 		c.SupportedGrantTypes = []string{"authorization_code"}
@@ -171,6 +338,55 @@ func (c *OAuthServiceCreateCommand) Run() error {
 	return nil
 }
 
+// runFromFile creates the service described by the spec file, or updates it
+// in place when a service with the same name already exists
+func (c *OAuthServiceCreateCommand) runFromFile() error {
+	spec, err := loadOAuthServiceSpec(c.FromFile)
+	if err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(c.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	response, err := client.ListOAuthServices(context.Background(), api.ListOAuthServicesParams{})
+	if err != nil {
+		return fmt.Errorf("failed to list oauth services: %w", err)
+	}
+
+	if r, ok := response.(*api.OAuthServiceListResponse); ok {
+		for _, service := range r.Services {
+			if service.Name == spec.Name {
+				update := OAuthServiceUpdateCommand{
+					EnvWrapperCommand: c.EnvWrapperCommand,
+					ID:                service.ID.String(),
+				}
+				spec.applyTo(&update)
+				return update.Run()
+			}
+		}
+	}
+
+	c.Name = spec.Name
+	c.DisplayName = spec.DisplayName
+	c.OAuthClientID = spec.ClientID
+	c.OAuthClientSecret = spec.ClientSecret
+	c.AuthorizationURL = spec.AuthorizationURL
+	c.TokenURL = spec.TokenURL
+	c.SupportedGrantTypes = spec.SupportedGrantTypes
+	c.Description = spec.Description
+	c.UserinfoURL = spec.UserinfoURL
+	c.DefaultScopes = spec.DefaultScopes
+	c.IsActive = spec.IsActive
+	c.IconURL = spec.IconURL
+	c.HomepageURL = spec.HomepageURL
+	c.FromFile = ""
+
+	return c.Run()
+}
+
 func (c *OAuthServiceListCommand) Run() error {
 	client, err := util.GetAuthenticatedClient(c.Config)
 	if err != nil {
@@ -192,46 +408,11 @@ func (c *OAuthServiceListCommand) Run() error {
 	// Handle response
 	switch r := response.(type) {
 	case *api.OAuthServiceListResponse:
-		if len(r.Services) == 0 {
-			fmt.Println("No OAuth services found.")
-			return nil
-		}
-
-		type oauthOutput struct {
-			ID          string   `json:"id" yaml:"id"`
-			Name        string   `json:"name" yaml:"name"`
-			DisplayName string   `json:"display_name" yaml:"display_name"`
-			Active      bool     `json:"active" yaml:"active"`
-			GrantTypes  []string `json:"grant_types" yaml:"grant_types"`
-		}
-
-		structured := make([]oauthOutput, len(r.Services))
-		tableData := make([]map[string]any, len(r.Services))
-		for i, service := range r.Services {
-			isActive := service.IsActive
-			grantTypes := "None"
-			if len(service.SupportedGrantTypes) > 0 {
-				grantTypes = fmt.Sprintf("%v", service.SupportedGrantTypes)
-			}
-
-			structured[i] = oauthOutput{
-				ID:          service.ID.String(),
-				Name:        service.Name,
-				DisplayName: service.DisplayName,
-				Active:      isActive,
-				GrantTypes:  service.SupportedGrantTypes,
-			}
-			tableData[i] = map[string]any{
-				"ID":           service.ID.String(),
-				"Name":         service.Name,
-				"Display Name": service.DisplayName,
-				"Active":       map[bool]string{true: "Yes", false: "No"}[isActive],
-				"Grant Types":  grantTypes,
-			}
+		result, err := oauthServiceResult(r.Services)
+		if err != nil {
+			return err
 		}
-
-		headers := []string{"ID", "Name", "Display Name", "Active", "Grant Types"}
-		return util.FormatOutput(c.Output, structured, headers, tableData)
+		return output.Print(c.OutputFormat(output.Table), result)
 	default:
 		return fmt.Errorf("failed to list oauth services")
 	}
@@ -262,13 +443,15 @@ func (c *OAuthServiceGetCommand) Run() error {
 	// Handle response
 	switch r := response.(type) {
 	case *api.OAuthServiceResponse:
-		services := []api.OAuthServiceResponse{*r}
-		displayOAuthServices(&services)
+		result, err := oauthServiceResult([]api.OAuthServiceResponse{*r})
+		if err != nil {
+			return err
+		}
+		result.Data = result.Data.([]map[string]any)[0]
+		return output.Print(c.OutputFormat(output.Table), result)
 	default:
 		return fmt.Errorf("oauth service with ID '%s' not found", c.ID)
 	}
-
-	return nil
 }
 
 func (c *OAuthServiceDeleteCommand) Run() error {
@@ -316,6 +499,14 @@ func (c *OAuthServiceUpdateCommand) Run() error {
 		return fmt.Errorf("invalid UUID: %w", err)
 	}
 
+	if c.FromFile != "" {
+		spec, err := loadOAuthServiceSpec(c.FromFile)
+		if err != nil {
+			return err
+		}
+		spec.applyTo(c)
+	}
+
 	// Create update request with only set fields
 	oauthUpdate := api.OAuthServiceUpdate{}
 
@@ -445,6 +636,21 @@ func (c *OAuthServiceAuthorizeCommand) Run() error {
 		Scopes: scopes,
 	}
 
+	httpClient, err := c.Config.HTTPClient()
+	if err != nil {
+		return err
+	}
+
+	usePKCE := true
+	if c.PKCE != nil {
+		usePKCE = *c.PKCE
+	} else if supported, known := discoverPKCESupport(httpClient, service.AuthorizationURL); known {
+		usePKCE = supported
+	}
+	if !usePKCE && c.ClientSecret == "" {
+		return fmt.Errorf("a client secret is required when PKCE is disabled")
+	}
+
 	// Determine redirect port
 	redirectPort := 40000
 	if c.RedirectPort != nil {
@@ -463,16 +669,28 @@ func (c *OAuthServiceAuthorizeCommand) Run() error {
 	if len(scopes) > 0 {
 		fmt.Printf("Requested Scopes: %s\n", strings.Join(scopes, ", "))
 	}
+	fmt.Printf("PKCE: %t\n", usePKCE)
 
-	// Use oauth2cli to handle the flow
-	token, err := oauth2cli.GetToken(context.Background(), oauth2cli.Config{
+	cliConfig := oauth2cli.Config{
 		OAuth2Config: oauth2Config,
 		LocalServerBindAddress: []string{
 			fmt.Sprintf("127.0.0.1:%d", redirectPort),
 			fmt.Sprintf("::1:%d", redirectPort),
 		},
 		LocalServerReadyChan: make(chan string, 1),
-	})
+	}
+
+	if usePKCE {
+		pkce, err := oauth2params.NewPKCE()
+		if err != nil {
+			return fmt.Errorf("failed to generate PKCE parameters: %w", err)
+		}
+		cliConfig.AuthCodeOptions = pkce.AuthCodeOptions()
+		cliConfig.TokenRequestOptions = pkce.TokenRequestOptions()
+	}
+
+	// Use oauth2cli to handle the flow
+	token, err := oauth2cli.GetToken(context.WithValue(context.Background(), oauth2.HTTPClient, httpClient), cliConfig)
 	if err != nil {
 		return fmt.Errorf("oauth authorization failed: %w", err)
 	}
@@ -489,16 +707,600 @@ func (c *OAuthServiceAuthorizeCommand) Run() error {
 	// If there's a userinfo endpoint, fetch user info
 	if !service.UserinfoURL.Null && service.UserinfoURL.Value != "" {
 		fmt.Println("\nFetching user information...")
-		if err := c.fetchUserInfo(token, service.UserinfoURL.Value); err != nil {
-			fmt.Printf("Warning: Failed to fetch user info: %v\n", err)
+		if err := c.fetchUserInfo(httpClient, token, service.UserinfoURL.Value); err != nil {
+			logging.Warn("failed to fetch user info", "error", err)
+		}
+	}
+
+	return nil
+}
+
+// openBrowser opens a URL in the user's browser; replaced in tests
+var openBrowser = browser.OpenURL
+
+func (c *OAuthServiceConnectCommand) Run() error {
+	client, err := util.GetAuthenticatedClient(c.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	serviceID, err := uuid.Parse(c.ServiceID)
+	if err != nil {
+		return fmt.Errorf("invalid service ID: %w", err)
+	}
+
+	// Listen before requesting the authorization URL so the redirect URI
+	// names the port actually in use
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", c.RedirectPort))
+	if err != nil {
+		return fmt.Errorf("failed to listen for OAuth callback: %w", err)
+	}
+	defer listener.Close()
+	redirectURI := fmt.Sprintf("http://localhost:%d/callback", listener.Addr().(*net.TCPAddr).Port)
+
+	state, err := oauthState()
+	if err != nil {
+		return err
+	}
+
+	request := &api.OAuthAuthorizationRequest{
+		ServiceID:   serviceID,
+		RedirectURI: api.NewOptNilString(redirectURI),
+		State:       api.NewOptNilString(state),
+	}
+	if len(c.Scopes) > 0 {
+		request.Scopes = api.NewOptNilStringArray(c.Scopes)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.Wait)
+	defer cancel()
+
+	response, err := client.GetOAuthAuthorizationURL(ctx, request)
+	if err != nil {
+		return fmt.Errorf("failed to get authorization URL: %w", err)
+	}
+
+	var authorization *api.OAuthAuthorizationResponse
+	switch r := response.(type) {
+	case *api.OAuthAuthorizationResponse:
+		authorization = r
+	case *api.GetOAuthAuthorizationURLNotFound:
+		return fmt.Errorf("oauth service with ID '%s' not found", c.ServiceID)
+	case *api.HTTPValidationError:
+		return fmt.Errorf("validation error: %v", r.Detail)
+	default:
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+
+	// The server may substitute its own state; the callback must match it
+	if authorization.State != "" {
+		state = authorization.State
+	}
+
+	fmt.Println("Opening browser to authorize the OAuth service...")
+	fmt.Printf("URL: %s\n", authorization.AuthorizationURL)
+	if err := openBrowser(authorization.AuthorizationURL); err != nil {
+		logging.Warn("could not open browser automatically", "error", err)
+		fmt.Println("Please open the URL above manually in your browser.")
+	}
+	fmt.Println("⏳ Waiting for authorization to complete...")
+
+	code, err := waitForOAuthCallback(ctx, listener, state)
+	if err != nil {
+		return err
+	}
+
+	exchange, err := client.ExchangeOAuthToken(ctx, &api.OAuthTokenExchange{
+		ServiceID:   serviceID,
+		Code:        code,
+		State:       api.NewOptNilString(state),
+		RedirectURI: api.NewOptNilString(redirectURI),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	switch r := exchange.(type) {
+	case *api.OAuthTokenResponse:
+		fmt.Printf("✅ Connected to OAuth service '%s' successfully.\n", c.ServiceID)
+		if len(r.Scopes) > 0 {
+			fmt.Printf("Granted Scopes: %s\n", strings.Join(r.Scopes, ", "))
+		}
+		if r.ExpiresIn.Set && !r.ExpiresIn.Null {
+			fmt.Printf("Token Expires: %s\n", time.Now().Add(time.Duration(r.ExpiresIn.Value)*time.Second).Format(time.RFC3339))
+		}
+		return nil
+	case *api.ExchangeOAuthTokenNotFound:
+		return fmt.Errorf("oauth service with ID '%s' not found", c.ServiceID)
+	case *api.HTTPValidationError:
+		return fmt.Errorf("validation error: %v", r.Detail)
+	default:
+		return fmt.Errorf("unexpected response type: %T", exchange)
+	}
+}
+
+// oauthState returns a random value for the OAuth state parameter
+func oauthState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate state: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// waitForOAuthCallback serves the OAuth redirect on listener and returns the
+// authorization code once a callback with the expected state arrives
+func waitForOAuthCallback(ctx context.Context, listener net.Listener, state string) (string, error) {
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		var res result
+		switch {
+		case query.Get("error") != "":
+			res.err = fmt.Errorf("authorization denied: %s %s", query.Get("error"), query.Get("error_description"))
+		case query.Get("state") != state:
+			res.err = fmt.Errorf("authorization failed: state mismatch")
+		case query.Get("code") == "":
+			res.err = fmt.Errorf("authorization failed: no code in callback")
+		default:
+			res.code = query.Get("code")
+		}
+
+		if res.err != nil {
+			http.Error(w, res.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Authorization complete. You can close this window and return to your terminal.")
+		}
+
+		select {
+		case results <- res:
+		default:
+		}
+	})
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	select {
+	case res := <-results:
+		return res.code, res.err
+	case <-ctx.Done():
+		return "", fmt.Errorf("timed out waiting for authorization: %w", ctx.Err())
+	}
+}
+
+func (c *OAuthServiceTokensCommand) Run() error {
+	format := c.OutputFormat(output.Table)
+	if err := output.Check(format, output.Table, output.JSON, output.YAML, output.Name); err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(c.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	response, err := client.ListOAuthTokens(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to list oauth tokens: %w", err)
+	}
+
+	var raw []byte
+	switch r := response.(type) {
+	case *api.ListOAuthTokensOKApplicationJSON:
+		raw = *r
+	case *api.ListOAuthTokensNotFound:
+		raw = []byte("[]")
+	default:
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+
+	tokens, err := parseOAuthTokens(raw)
+	if err != nil {
+		return err
+	}
+
+	// Tokens are named by service, which revoke takes
+	result := output.Result{Data: tokens, Empty: "No connected OAuth services.", Names: []string{}}
+	for _, token := range tokens {
+		if name, ok := token["service_name"].(string); ok {
+			result.Names = append(result.Names, name)
+		}
+	}
+	if format != output.Table || len(tokens) == 0 {
+		return output.Print(format, result)
+	}
+
+	headers := oauthTokenHeaders(tokens)
+	tableData := make([]map[string]any, len(tokens))
+	for i, token := range tokens {
+		row := make(map[string]any, len(headers))
+		for _, h := range headers {
+			switch value := token[h].(type) {
+			case nil:
+				row[h] = "-"
+			case []any:
+				parts := make([]string, len(value))
+				for j, v := range value {
+					parts[j] = fmt.Sprint(v)
+				}
+				row[h] = strings.Join(parts, ", ")
+			default:
+				row[h] = fmt.Sprint(value)
+			}
+		}
+		tableData[i] = row
+	}
+	output.PrintTable(headers, tableData)
+	return nil
+}
+
+// parseOAuthTokens decodes the token list returned by the API, which is
+// either a list or an object wrapping one under "tokens". Token values are
+// dropped so they are never printed.
+func parseOAuthTokens(raw []byte) ([]map[string]any, error) {
+	var tokens []map[string]any
+	if err := json.Unmarshal(raw, &tokens); err != nil {
+		var wrapped struct {
+			Tokens []map[string]any `json:"tokens"`
+		}
+		if err := json.Unmarshal(raw, &wrapped); err != nil {
+			return nil, fmt.Errorf("failed to parse oauth tokens: %w", err)
+		}
+		tokens = wrapped.Tokens
+	}
+
+	for _, token := range tokens {
+		for key := range token {
+			if isOAuthSecretField(key) {
+				delete(token, key)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// isOAuthSecretField reports whether a token field holds credential material
+func isOAuthSecretField(key string) bool {
+	key = strings.ToLower(key)
+	switch key {
+	case "token_type":
+		return false
+	}
+	return strings.HasSuffix(key, "_token") || key == "token" || strings.Contains(key, "secret")
+}
+
+// oauthTokenHeaders returns the table columns for a token list, leading
+// with the service name
+func oauthTokenHeaders(tokens []map[string]any) []string {
+	seen := map[string]bool{}
+	var headers []string
+	for _, token := range tokens {
+		for key := range token {
+			if !seen[key] {
+				seen[key] = true
+				headers = append(headers, key)
+			}
+		}
+	}
+	sort.Slice(headers, func(i, j int) bool {
+		if (headers[i] == "service_name") != (headers[j] == "service_name") {
+			return headers[i] == "service_name"
+		}
+		return headers[i] < headers[j]
+	})
+	return headers
+}
+
+func (c *OAuthServiceRevokeCommand) Run() error {
+	client, err := util.GetAuthenticatedClient(c.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	response, err := client.RevokeOAuthToken(context.Background(), api.RevokeOAuthTokenParams{
+		ServiceName: c.ServiceName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke oauth token: %w", err)
+	}
+
+	switch r := response.(type) {
+	case *api.RevokeOAuthTokenNoContent:
+		fmt.Printf("✅ Token for OAuth service '%s' revoked successfully.\n", c.ServiceName)
+		return nil
+	case *api.RevokeOAuthTokenNotFound:
+		return fmt.Errorf("no token found for oauth service '%s'", c.ServiceName)
+	case *api.HTTPValidationError:
+		return fmt.Errorf("validation error: %v", r.Detail)
+	default:
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+}
+
+func (c *OAuthServiceTestCommand) Run() error {
+	client, err := util.GetAuthenticatedClient(c.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	serviceID, err := uuid.Parse(c.ID)
+	if err != nil {
+		return fmt.Errorf("invalid UUID: %w", err)
+	}
+
+	response, err := client.GetOAuthService(context.Background(), api.GetOAuthServiceParams{
+		ServiceID: serviceID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get oauth service: %w", err)
+	}
+
+	var service *api.OAuthServiceResponse
+	switch r := response.(type) {
+	case *api.OAuthServiceResponse:
+		service = r
+	default:
+		return fmt.Errorf("oauth service with ID '%s' not found", c.ID)
+	}
+
+	fmt.Printf("Testing OAuth service '%s' (%s)...\n\n", service.DisplayName, service.ID)
+
+	// Don't follow redirects: an authorization endpoint redirecting to a
+	// login page is the expected behavior and proves the URL is live
+	httpClient, err := c.Config.HTTPClient()
+	if err != nil {
+		return err
+	}
+	httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	failures := 0
+	report := func(name string, err error) {
+		if err != nil {
+			failures++
+			fmt.Printf("❌ %s: %v\n", name, err)
+			return
 		}
+		fmt.Printf("✅ %s\n", name)
+	}
+
+	report("Authorization URL", checkOAuthEndpoint(httpClient, http.MethodGet, service.AuthorizationURL, false))
+	report("Token URL", checkOAuthEndpoint(httpClient, http.MethodPost, service.TokenURL, true))
+	if !service.UserinfoURL.Null && service.UserinfoURL.Value != "" {
+		report("Userinfo URL", checkOAuthEndpoint(httpClient, http.MethodGet, service.UserinfoURL.Value, true))
 	}
 
+	problems := checkOAuthGrantTypes(service.SupportedGrantTypes, service.AuthorizationURL, service.TokenURL)
+	if len(problems) == 0 {
+		report("Grant types", nil)
+	} else {
+		for _, problem := range problems {
+			report("Grant types", fmt.Errorf("%s", problem))
+		}
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d check(s) failed for oauth service '%s'", failures, service.Name)
+	}
+
+	fmt.Println("\nAll checks passed.")
 	return nil
 }
 
-func (c *OAuthServiceAuthorizeCommand) fetchUserInfo(token *oauth2.Token, userinfoURL string) error {
-	client := &http.Client{Timeout: 10 * time.Second}
+// checkOAuthEndpoint makes an unauthenticated request to an OAuth endpoint.
+// Client errors are expected since no credentials are sent; server errors,
+// network failures and (when expectJSON is set) non-JSON responses are not.
+func checkOAuthEndpoint(client *http.Client, method, endpoint string, expectJSON bool) error {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if parsed.Scheme != "https" && parsed.Hostname() != "localhost" && parsed.Hostname() != "127.0.0.1" {
+		return fmt.Errorf("URL must use https: %s", endpoint)
+	}
+
+	var body *strings.Reader
+	if method == http.MethodPost {
+		body = strings.NewReader(url.Values{"grant_type": {"authorization_code"}}.Encode())
+	} else {
+		body = strings.NewReader("")
+	}
+
+	req, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("endpoint not found (HTTP 404)")
+	}
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("server error (HTTP %d)", resp.StatusCode)
+	}
+
+	if expectJSON {
+		contentType := resp.Header.Get("Content-Type")
+		if !strings.Contains(contentType, "json") {
+			return fmt.Errorf("expected a JSON response, got content type '%s' (HTTP %d)", contentType, resp.StatusCode)
+		}
+	}
+
+	return nil
+}
+
+// checkOAuthGrantTypes returns a description of each inconsistency between
+// the supported grant types and the configured endpoints
+func checkOAuthGrantTypes(grantTypes []string, authorizationURL, tokenURL string) []string {
+	known := map[string]bool{
+		"authorization_code": true,
+		"refresh_token":      true,
+		"client_credentials": true,
+		"device_code":        true,
+		"urn:ietf:params:oauth:grant-type:device_code": true,
+		"urn:ietf:params:oauth:grant-type:jwt-bearer":  true,
+	}
+
+	var problems []string
+	if len(grantTypes) == 0 {
+		problems = append(problems, "no supported grant types configured")
+	}
+
+	supported := make(map[string]bool)
+	for _, grantType := range grantTypes {
+		if !known[grantType] {
+			problems = append(problems, fmt.Sprintf("unknown grant type '%s'", grantType))
+		}
+		supported[grantType] = true
+	}
+
+	if supported["authorization_code"] && authorizationURL == "" {
+		problems = append(problems, "authorization_code requires an authorization URL")
+	}
+	if len(grantTypes) > 0 && tokenURL == "" {
+		problems = append(problems, "a token URL is required")
+	}
+	if supported["refresh_token"] && !supported["authorization_code"] && !supported["device_code"] &&
+		!supported["urn:ietf:params:oauth:grant-type:device_code"] {
+		problems = append(problems, "refresh_token is listed without a grant type that issues refresh tokens")
+	}
+
+	return problems
+}
+
+func (c *OAuthServiceExportCommand) Run() error {
+	client, err := util.GetAuthenticatedClient(c.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	serviceID, err := uuid.Parse(c.ID)
+	if err != nil {
+		return fmt.Errorf("invalid UUID: %w", err)
+	}
+
+	response, err := client.GetOAuthService(context.Background(), api.GetOAuthServiceParams{
+		ServiceID: serviceID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get oauth service: %w", err)
+	}
+
+	service, ok := response.(*api.OAuthServiceResponse)
+	if !ok {
+		return fmt.Errorf("oauth service with ID '%s' not found", c.ID)
+	}
+
+	// Client credentials are never returned by the API, so the exported spec
+	// can be committed as-is and credentials supplied on apply
+	isActive := service.IsActive
+	spec := oauthServiceSpec{
+		Name:                service.Name,
+		DisplayName:         service.DisplayName,
+		AuthorizationURL:    service.AuthorizationURL,
+		TokenURL:            service.TokenURL,
+		SupportedGrantTypes: service.SupportedGrantTypes,
+		DefaultScopes:       service.DefaultScopes,
+		IsActive:            &isActive,
+	}
+	if v, ok := service.Description.Get(); ok {
+		spec.Description = &v
+	}
+	if v, ok := service.UserinfoURL.Get(); ok {
+		spec.UserinfoURL = &v
+	}
+	if v, ok := service.IconURL.Get(); ok {
+		spec.IconURL = &v
+	}
+	if v, ok := service.HomepageURL.Get(); ok {
+		spec.HomepageURL = &v
+	}
+
+	var data []byte
+	switch format := c.OutputFormat(output.YAML); format {
+	case output.JSON:
+		data, err = json.MarshalIndent(spec, "", "  ")
+		data = append(data, '\n')
+	case output.YAML, "yml":
+		data, err = yaml.Marshal(spec)
+	default:
+		return output.UnsupportedFormatError(format, output.JSON, output.YAML)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal oauth service: %w", err)
+	}
+
+	if c.File == "" {
+		fmt.Print(string(data))
+		return nil
+	}
+
+	if err := os.WriteFile(c.File, data, 0600); err != nil {
+		return fmt.Errorf("failed to write spec file: %w", err)
+	}
+	fmt.Printf("✅ OAuth service '%s' exported to %s\n", service.Name, c.File)
+	return nil
+}
+
+// discoverPKCESupport looks up the authorization server metadata for the
+// host of authorizationURL. known is false when no metadata is published,
+// in which case the caller should fall back to its default.
+func discoverPKCESupport(httpClient *http.Client, authorizationURL string) (supported bool, known bool) {
+	parsed, err := url.Parse(authorizationURL)
+	if err != nil || parsed.Host == "" {
+		return false, false
+	}
+	base := parsed.Scheme + "://" + parsed.Host
+
+	for _, path := range []string{"/.well-known/oauth-authorization-server", "/.well-known/openid-configuration"} {
+		resp, err := httpClient.Get(base + path)
+		if err != nil {
+			continue
+		}
+
+		var metadata struct {
+			CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&metadata)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || err != nil {
+			continue
+		}
+
+		// Metadata without the field doesn't tell us the provider lacks PKCE
+		if metadata.CodeChallengeMethodsSupported == nil {
+			return false, false
+		}
+		for _, method := range metadata.CodeChallengeMethodsSupported {
+			if method == "S256" {
+				return true, true
+			}
+		}
+		return false, true
+	}
+
+	return false, false
+}
+
+func (c *OAuthServiceAuthorizeCommand) fetchUserInfo(client *http.Client, token *oauth2.Token, userinfoURL string) error {
 	req, err := http.NewRequest("GET", userinfoURL, nil)
 	if err != nil {
 		return err
@@ -530,34 +1332,55 @@ func (c *OAuthServiceAuthorizeCommand) fetchUserInfo(token *oauth2.Token, userin
 	return nil
 }
 
-func displayOAuthServices(services *[]api.OAuthServiceResponse) {
-	if services == nil || len(*services) == 0 {
-		fmt.Println("No OAuth services found.")
-		return
+// oauthServiceOutput converts a service into a generic map for structured
+// output. Going through the API type's own JSON encoding keeps every field
+// the server returns (scopes, URLs, timestamps, ...) while the client secret
+// is always removed.
+func oauthServiceOutput(service *api.OAuthServiceResponse) (map[string]any, error) {
+	data, err := json.Marshal(service)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal oauth service: %w", err)
 	}
 
-	headers := []string{"ID", "Name", "Display Name", "Active", "Grant Types"}
-	data := make([]map[string]interface{}, len(*services))
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal oauth service: %w", err)
+	}
+	delete(fields, "client_secret")
 
-	for i, service := range *services {
-		isActive := "No"
-		if service.IsActive {
-			isActive = "Yes"
+	return fields, nil
+}
+
+// oauthServiceResult is how OAuth services print in each output format,
+// without their client secrets. Services are named by ID, which the other
+// oauthservice commands take.
+func oauthServiceResult(services []api.OAuthServiceResponse) (output.Result, error) {
+	result := output.Result{
+		Headers: []string{"ID", "Name", "Display Name", "Active", "Grant Types"},
+		Empty:   "No OAuth services found.",
+		Names:   []string{},
+	}
+	structured := make([]map[string]any, len(services))
+	for i, service := range services {
+		var err error
+		structured[i], err = oauthServiceOutput(&services[i])
+		if err != nil {
+			return output.Result{}, err
 		}
 
 		grantTypes := "None"
 		if len(service.SupportedGrantTypes) > 0 {
 			grantTypes = fmt.Sprintf("%v", service.SupportedGrantTypes)
 		}
-
-		data[i] = map[string]interface{}{
-			"ID":           service.ID,
+		result.Rows = append(result.Rows, map[string]any{
+			"ID":           service.ID.String(),
 			"Name":         service.Name,
 			"Display Name": service.DisplayName,
-			"Active":       isActive,
+			"Active":       map[bool]string{true: "Yes", false: "No"}[service.IsActive],
 			"Grant Types":  grantTypes,
-		}
+		})
+		result.Names = append(result.Names, service.ID.String())
 	}
-
-	util.DisplaySimpleTable(data, headers)
+	result.Data = structured
+	return result, nil
 }
@@ -0,0 +1,289 @@
+package commands
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/browser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOAuthServiceCommand_Structure tests the oauthservice command structure
+func TestOAuthServiceCommand_Structure(t *testing.T) {
+	cmd := OAuthServiceCommand{}
+
+	assert.NotNil(t, &cmd.Authorize, "Authorize command should be available")
+	assert.NotNil(t, &cmd.Connect, "Connect command should be available")
+	assert.NotNil(t, &cmd.Tokens, "Tokens command should be available")
+	assert.NotNil(t, &cmd.Revoke, "Revoke command should be available")
+	assert.NotNil(t, &cmd.Test, "Test command should be available")
+}
+
+func TestCheckOAuthGrantTypes(t *testing.T) {
+	tests := []struct {
+		name       string
+		grantTypes []string
+		authURL    string
+		tokenURL   string
+		problems   int
+	}{
+		{
+			name:       "authorization code with refresh",
+			grantTypes: []string{"authorization_code", "refresh_token"},
+			authURL:    "https://example.com/authorize",
+			tokenURL:   "https://example.com/token",
+			problems:   0,
+		},
+		{
+			name:       "client credentials without authorization URL",
+			grantTypes: []string{"client_credentials"},
+			tokenURL:   "https://example.com/token",
+			problems:   0,
+		},
+		{
+			name:       "authorization code without authorization URL",
+			grantTypes: []string{"authorization_code"},
+			tokenURL:   "https://example.com/token",
+			problems:   1,
+		},
+		{
+			name:       "misspelled grant type",
+			grantTypes: []string{"authorisation_code"},
+			authURL:    "https://example.com/authorize",
+			tokenURL:   "https://example.com/token",
+			problems:   1,
+		},
+		{
+			name:       "refresh token alone",
+			grantTypes: []string{"refresh_token"},
+			tokenURL:   "https://example.com/token",
+			problems:   1,
+		},
+		{
+			name:     "no grant types",
+			problems: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := checkOAuthGrantTypes(tt.grantTypes, tt.authURL, tt.tokenURL)
+			assert.Len(t, problems, tt.problems, "problems: %v", problems)
+		})
+	}
+}
+
+func TestCheckOAuthEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_request"}`))
+		case "/html":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html></html>"))
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := server.Client()
+
+	assert.NoError(t, checkOAuthEndpoint(client, http.MethodPost, server.URL+"/token", true))
+	assert.NoError(t, checkOAuthEndpoint(client, http.MethodGet, server.URL+"/html", false))
+	assert.Error(t, checkOAuthEndpoint(client, http.MethodGet, server.URL+"/html", true))
+	assert.Error(t, checkOAuthEndpoint(client, http.MethodGet, server.URL+"/broken", false))
+	assert.Error(t, checkOAuthEndpoint(client, http.MethodGet, server.URL+"/missing", false))
+	assert.Error(t, checkOAuthEndpoint(client, http.MethodGet, "http://example.com/authorize", false))
+}
+
+func TestLoadOAuthServiceSpec(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TEST_OAUTH_SECRET", "s3cret")
+
+	path := filepath.Join(dir, "service.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`name: github
+display_name: GitHub
+client_id: abc
+client_secret: ${TEST_OAUTH_SECRET}
+authorization_url: https://github.com/login/oauth/authorize
+token_url: https://github.com/login/oauth/access_token
+default_scopes: [repo, read:org]
+is_active: true
+`), 0600))
+
+	spec, err := loadOAuthServiceSpec(path)
+	require.NoError(t, err)
+	assert.Equal(t, "github", spec.Name)
+	assert.Equal(t, "s3cret", spec.ClientSecret)
+	assert.Equal(t, []string{"repo", "read:org"}, spec.DefaultScopes)
+	require.NotNil(t, spec.IsActive)
+	assert.True(t, *spec.IsActive)
+
+	update := OAuthServiceUpdateCommand{}
+	spec.applyTo(&update)
+	require.NotNil(t, update.DisplayName)
+	assert.Equal(t, "GitHub", *update.DisplayName)
+	assert.Nil(t, update.Description)
+
+	jsonPath := filepath.Join(dir, "service.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{"display_name": "No Name"}`), 0600))
+	_, err = loadOAuthServiceSpec(jsonPath)
+	assert.Error(t, err)
+}
+
+func TestExpandSpecEnv(t *testing.T) {
+	t.Setenv("TEST_OAUTH_SECRET", "s3cret")
+	t.Setenv("HOME_PART", "should-not-appear")
+
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "braced reference", value: "${TEST_OAUTH_SECRET}", expected: "s3cret"},
+		{name: "embedded reference", value: "prefix-${TEST_OAUTH_SECRET}-suffix", expected: "prefix-s3cret-suffix"},
+		{name: "bare dollar kept", value: "ab$HOME_PART$cd", expected: "ab$HOME_PART$cd"},
+		{name: "trailing dollar kept", value: "secret$", expected: "secret$"},
+		{name: "unset variable", value: "${TEST_OAUTH_UNSET}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandSpecEnv(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+const testOAuthServiceID = "33333333-3333-3333-3333-333333333333"
+
+func TestOAuthServiceConnectCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("POST /api/v1/oauth/authorize", http.StatusOK, map[string]any{
+		"authorization_url": "https://provider.example.com/authorize",
+		"state":             "server-state",
+	})
+	srv.handle("POST /api/v1/oauth/token", http.StatusOK, map[string]any{
+		"access_token": "secret-access-token",
+		"token_type":   "bearer",
+		"scopes":       []string{"repo"},
+	})
+
+	// Stand in for the browser: the provider redirects back to the
+	// redirect URI that was sent to the API
+	openBrowser = func(string) error {
+		body := srv.requireRequest(http.MethodPost, "/api/v1/oauth/authorize").JSON(t)
+		callback, err := url.Parse(body["redirect_uri"].(string))
+		require.NoError(t, err)
+		callback.RawQuery = url.Values{"code": {"auth-code"}, "state": {"server-state"}}.Encode()
+		go func() {
+			resp, err := http.Get(callback.String())
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+	t.Cleanup(func() { openBrowser = browser.OpenURL })
+
+	cmd := OAuthServiceConnectCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		ServiceID:         testOAuthServiceID,
+		Scopes:            []string{"repo"},
+		Wait:              10 * time.Second,
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	authorize := srv.requireRequest(http.MethodPost, "/api/v1/oauth/authorize").JSON(t)
+	assert.Equal(t, testOAuthServiceID, authorize["service_id"])
+	assert.Equal(t, []any{"repo"}, authorize["scopes"])
+	assert.NotEmpty(t, authorize["state"])
+
+	exchange := srv.requireRequest(http.MethodPost, "/api/v1/oauth/token").JSON(t)
+	assert.Equal(t, testOAuthServiceID, exchange["service_id"])
+	assert.Equal(t, "auth-code", exchange["code"])
+	assert.Equal(t, "server-state", exchange["state"])
+	assert.Equal(t, authorize["redirect_uri"], exchange["redirect_uri"])
+
+	assert.Contains(t, output, "Connected to OAuth service")
+	assert.NotContains(t, output, "secret-access-token")
+}
+
+func TestWaitForOAuthCallback_StateMismatch(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/callback?code=abc&state=wrong")
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err = waitForOAuthCallback(ctx, listener, "expected")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "state mismatch")
+}
+
+func TestOAuthServiceTokensCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/oauth/tokens", http.StatusOK, []map[string]any{
+		{
+			"service_name":  "github",
+			"scopes":        []string{"repo", "read:org"},
+			"access_token":  "secret-access-token",
+			"refresh_token": "secret-refresh-token",
+		},
+	})
+
+	cfg.Output = "json"
+	cmd := OAuthServiceTokensCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	srv.requireRequest(http.MethodGet, "/api/v1/oauth/tokens")
+	assert.Contains(t, output, "github")
+	assert.Contains(t, output, "read:org")
+	assert.NotContains(t, output, "secret-access-token")
+	assert.NotContains(t, output, "secret-refresh-token")
+}
+
+func TestOAuthServiceRevokeCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("DELETE /api/v1/oauth/tokens/{name}", http.StatusNoContent, nil)
+
+	cmd := OAuthServiceRevokeCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		ServiceName:       "github",
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	srv.requireRequest(http.MethodDelete, "/api/v1/oauth/tokens/github")
+	assert.Contains(t, output, "revoked successfully")
+}
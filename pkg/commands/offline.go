@@ -0,0 +1,215 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/arctir/devgraph-cli/pkg/logging"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+	"github.com/fatih/color"
+)
+
+// offlineSnapshot is the catalog of an environment as 'dg entity backup'
+// last saw it, kept in the cache directory for --offline
+type offlineSnapshot struct {
+	Environment string                       `json:"environment"`
+	SavedAt     time.Time                    `json:"savedAt"`
+	Source      string                       `json:"source"`
+	Entities    []api.EntityResponse         `json:"entities"`
+	Relations   []api.EntityRelationResponse `json:"relations"`
+}
+
+// offlineSnapshotPath returns where the snapshot of environment is kept
+func offlineSnapshotPath(environment string) (string, error) {
+	dir, err := config.GetUserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "snapshots", environment+".json"), nil
+}
+
+// saveOfflineSnapshot replaces the snapshot of cfg's environment with the
+// given catalog, backed up to source. Failures are only logged, since the
+// backup itself succeeded.
+func saveOfflineSnapshot(cfg config.Config, source string, entities []api.EntityResponse, relations []api.EntityRelationResponse) {
+	environment, err := getDefaultEnvironment(cfg)
+	if err != nil {
+		return
+	}
+	path, err := offlineSnapshotPath(environment)
+	if err != nil {
+		logging.Warn("failed to save offline snapshot", "error", err)
+		return
+	}
+	if abs, err := filepath.Abs(source); err == nil {
+		source = abs
+	}
+
+	data, err := json.Marshal(offlineSnapshot{
+		Environment: environment,
+		SavedAt:     time.Now(),
+		Source:      source,
+		Entities:    entities,
+		Relations:   relations,
+	})
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(path), 0700); err == nil {
+			err = os.WriteFile(path, data, 0600)
+		}
+	}
+	if err != nil {
+		logging.Warn("failed to save offline snapshot", "error", err)
+	}
+}
+
+// loadOfflineSnapshot returns the snapshot of cfg's environment and writes
+// a notice of how old it is to stderr, so offline output isn't mistaken for
+// the live catalog
+func loadOfflineSnapshot(cfg config.Config) (*offlineSnapshot, error) {
+	environment, err := getDefaultEnvironment(cfg)
+	if err != nil {
+		return nil, err
+	}
+	path, err := offlineSnapshotPath(environment)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path) // #nosec G304 - path is in the user cache directory
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no offline snapshot of environment %s: run 'dg entity backup <dir>' while online to save one", environment)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read offline snapshot: %w", err)
+	}
+	var snapshot offlineSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse offline snapshot %s: %w", path, err)
+	}
+
+	snapshot.printNotice(os.Stderr, time.Now())
+	return &snapshot, nil
+}
+
+// printNotice writes when the snapshot was saved, and where from
+func (s *offlineSnapshot) printNotice(w io.Writer, now time.Time) {
+	age := "less than a minute"
+	if elapsed := now.Sub(s.SavedAt); elapsed >= time.Minute {
+		age = elapsed.Truncate(time.Minute).String()
+		age = strings.TrimSuffix(age, "0s")
+	}
+	fmt.Fprintln(w, color.YellowString("⚠️  Offline: showing the backup of %s saved %s ago (%s) to %s. It may be out of date.",
+		s.Environment, age, s.SavedAt.Local().Format("2006-01-02 15:04"), s.Source))
+}
+
+// entity returns the entity with the given ID components
+func (s *offlineSnapshot) entity(group, version, plural, namespace, name string) (api.EntityResponse, bool) {
+	for _, entity := range s.Entities {
+		if entity.Group == group && entity.Version == version && entity.Plural == plural &&
+			entity.Namespace == namespace && entity.Name == name {
+			return entity, true
+		}
+	}
+	return api.EntityResponse{}, false
+}
+
+// listEntities returns the entities matching the filters of 'dg entity
+// list'. Field selectors are evaluated by the API, so they can't be used
+// offline.
+func (s *offlineSnapshot) listEntities(name, label, fieldSelector string, limit, offset int) ([]api.EntityResponse, error) {
+	if fieldSelector != "" {
+		return nil, fmt.Errorf("--field-selector can't be used with --offline")
+	}
+	selector, err := parseLabelSelector(label)
+	if err != nil {
+		return nil, err
+	}
+
+	var entities []api.EntityResponse
+	for _, entity := range s.Entities {
+		if name != "" && entity.Name != name {
+			continue
+		}
+		labels, _ := entity.Metadata.Labels.Get()
+		if !selector.matches(labels) {
+			continue
+		}
+		entities = append(entities, entity)
+	}
+
+	entities = entities[min(offset, len(entities)):]
+	if limit > 0 && limit < len(entities) {
+		entities = entities[:limit]
+	}
+	return entities, nil
+}
+
+// labelRequirement is one clause of a label selector: key=value,
+// key!=value, or just key for the label being set
+type labelRequirement struct {
+	key    string
+	value  string
+	negate bool
+	exists bool
+}
+
+type labelSelector []labelRequirement
+
+// parseLabelSelector parses the equality-based selectors the API accepts,
+// like "team=payments,tier!=frontend"
+func parseLabelSelector(selector string) (labelSelector, error) {
+	var requirements labelSelector
+	for _, clause := range strings.Split(selector, ",") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+		var requirement labelRequirement
+		switch {
+		case strings.Contains(clause, "!="):
+			requirement.key, requirement.value, _ = strings.Cut(clause, "!=")
+			requirement.negate = true
+		case strings.Contains(clause, "=="):
+			requirement.key, requirement.value, _ = strings.Cut(clause, "==")
+		case strings.Contains(clause, "="):
+			requirement.key, requirement.value, _ = strings.Cut(clause, "=")
+		default:
+			requirement.key = clause
+			requirement.exists = true
+		}
+		requirement.key = strings.TrimSpace(requirement.key)
+		requirement.value = strings.TrimSpace(requirement.value)
+		if requirement.key == "" {
+			return nil, fmt.Errorf("invalid label selector %q", selector)
+		}
+		requirements = append(requirements, requirement)
+	}
+	return requirements, nil
+}
+
+func (s labelSelector) matches(labels map[string]string) bool {
+	for _, requirement := range s {
+		value, ok := labels[requirement.key]
+		switch {
+		case requirement.exists:
+			if !ok {
+				return false
+			}
+		case requirement.negate:
+			if ok && value == requirement.value {
+				return false
+			}
+		default:
+			if !ok || value != requirement.value {
+				return false
+			}
+		}
+	}
+	return true
+}
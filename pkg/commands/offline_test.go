@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/arctir/devgraph-cli/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffline_ReadsLastBackup(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	payments := testEntity("payments-api")
+	payments["metadata"] = map[string]any{"name": "payments-api", "namespace": "default", "labels": map[string]any{"team": "payments"}}
+	srv.handle("GET /api/v1/entities/definitions", http.StatusOK, []map[string]any{})
+	srv.handle("GET /api/v1/entities/", http.StatusOK, map[string]any{
+		"primary_entities": []any{payments, testEntity("web")},
+		"relations":        []any{testRelation("DEPENDS_ON", "web", "payments-api")},
+	})
+
+	backup := EntityBackupCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, OutputDir: t.TempDir(), Format: "yaml"}
+	_, err := captureOutput(t, backup.Run)
+	require.NoError(t, err)
+	require.NotEmpty(t, srv.received(http.MethodGet, "/api/v1/entities/"))
+
+	srv.mu.Lock()
+	sent := len(srv.requests)
+	srv.mu.Unlock()
+
+	cfg.Offline = true
+	list := EntityListCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Label: "team=payments"}
+	output, err := captureOutput(t, list.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "payments-api")
+	assert.NotContains(t, output, "web")
+
+	cfg.Output = "json"
+	get := EntityGetCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, EntityID: "core/v1/services/default/web"}
+	output, err = captureOutput(t, get.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, `"name": "web"`)
+
+	get.EntityID = "core/v1/services/default/missing"
+	_, err = captureOutput(t, get.Run)
+	assert.ErrorContains(t, err, "entity not found")
+
+	cfg.Output = "table"
+	relationships := EntityRelationshipsCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, EntityID: "core/v1/service/default/payments-api"}
+	output, err = captureOutput(t, relationships.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Incoming")
+	assert.Contains(t, output, "core/v1/service/default/web")
+
+	list = EntityListCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, FieldSelector: "spec.owner=payments"}
+	_, err = captureOutput(t, list.Run)
+	assert.ErrorContains(t, err, "--field-selector can't be used with --offline")
+
+	// Nothing offline goes to the API
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	assert.Len(t, srv.requests, sent)
+}
+
+func TestOffline_NoBackup(t *testing.T) {
+	_, cfg := newTestAPI(t)
+	cfg.Offline = true
+
+	cmd := EntityListCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}}
+	_, err := captureOutput(t, cmd.Run)
+	assert.ErrorContains(t, err, "no offline snapshot of environment "+testEnvironmentID)
+}
+
+func TestOffline_CommandsNeedingTheAPI(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	cfg.Offline = true
+
+	cmd := EntityDeleteCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, EntityID: "core/v1/services/default/web"}
+	_, err := captureOutput(t, cmd.Run)
+	assert.ErrorIs(t, err, util.ErrOffline)
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	assert.Empty(t, srv.requests)
+}
+
+func TestEnvWrapperCommand_Offline(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	cfg.Offline = true
+
+	cmd := EnvWrapperCommand{Config: cfg, Env: "22222222-2222-2222-2222-222222222222"}
+	require.NoError(t, cmd.AfterApply())
+	assert.Equal(t, "22222222-2222-2222-2222-222222222222", cmd.Config.EnvOverride)
+
+	cmd = EnvWrapperCommand{Config: cfg, Env: "staging"}
+	assert.ErrorContains(t, cmd.AfterApply(), "use the environment's UUID with --offline")
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	assert.Empty(t, srv.requests)
+}
+
+func TestOfflineSnapshot_PrintNotice(t *testing.T) {
+	saved := time.Date(2026, 10, 15, 9, 0, 0, 0, time.Local)
+	snapshot := offlineSnapshot{Environment: testEnvironmentID, SavedAt: saved, Source: "/backups/catalog"}
+
+	var out bytes.Buffer
+	snapshot.printNotice(&out, saved.Add(3*time.Hour+12*time.Minute+30*time.Second))
+	assert.Contains(t, out.String(), "Offline: showing the backup of "+testEnvironmentID+" saved 3h12m ago (2026-10-15 09:00) to /backups/catalog")
+
+	out.Reset()
+	snapshot.printNotice(&out, saved.Add(20*time.Second))
+	assert.Contains(t, out.String(), "saved less than a minute ago")
+}
+
+func TestLabelSelector(t *testing.T) {
+	labels := map[string]string{"team": "payments", "tier": "backend"}
+	tests := []struct {
+		selector string
+		matches  bool
+	}{
+		{"", true},
+		{"team=payments", true},
+		{"team==payments,tier=backend", true},
+		{"team=search", false},
+		{"tier!=frontend", true},
+		{"tier!=backend", false},
+		{"team", true},
+		{"owner", false},
+	}
+	for _, tt := range tests {
+		selector, err := parseLabelSelector(tt.selector)
+		require.NoError(t, err, tt.selector)
+		assert.Equal(t, tt.matches, selector.matches(labels), tt.selector)
+	}
+
+	_, err := parseLabelSelector("=payments")
+	assert.ErrorContains(t, err, "invalid label selector")
+}
@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"github.com/arctir/devgraph-cli/pkg/output"
+	"github.com/arctir/devgraph-cli/pkg/plugin"
+)
+
+// PluginCommand inspects dg-* extension executables
+type PluginCommand struct {
+	List PluginListCommand `cmd:"list" help:"List plugins found on PATH."`
+}
+
+type PluginListCommand struct {
+	Output string `short:"o" help:"Output format: table, json, yaml or name" default:"table"`
+}
+
+func (p *PluginListCommand) Run() error {
+	plugins := plugin.List()
+	data := make([]map[string]any, 0, len(plugins))
+	names := make([]string, 0, len(plugins))
+	for _, pl := range plugins {
+		data = append(data, map[string]any{
+			"Name": pl.Name,
+			"Path": pl.Path,
+		})
+		names = append(names, pl.Name)
+	}
+	return output.Print(p.Output, output.Result{
+		Data:    plugins,
+		Headers: []string{"Name", "Path"},
+		Rows:    data,
+		Empty:   "No plugins found. Plugins are executables on PATH named dg-<name>.",
+		Names:   names,
+	})
+}
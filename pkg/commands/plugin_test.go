@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginListCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+
+	output, err := captureOutput(t, (&PluginListCommand{Output: "table"}).Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "No plugins found")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dg-hello"), []byte("#!/bin/sh\n"), 0755))
+	output, err = captureOutput(t, (&PluginListCommand{Output: "json"}).Run)
+	require.NoError(t, err)
+
+	var plugins []map[string]string
+	require.NoError(t, json.Unmarshal([]byte(output), &plugins))
+	require.Len(t, plugins, 1)
+	assert.Equal(t, "hello", plugins[0]["name"])
+	assert.Equal(t, filepath.Join(dir, "dg-hello"), plugins[0]["path"])
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/arctir/devgraph-cli/pkg/util"
+)
+
+// PromptCommand prints the current context and environment for a shell
+// prompt. It only reads local files, so it's fast enough to run for every
+// prompt.
+type PromptCommand struct {
+	Format string `short:"f" default:"{context}:{environment}" help:"Format of the output. Placeholders: {context}, {environment} (slug, or short UUID if not yet known), {environment-id}, {server}."`
+}
+
+func (p *PromptCommand) Run() error {
+	fmt.Println(promptSegment(p.Format))
+	return nil
+}
+
+// promptSegment fills in format from the current context. It's empty when
+// there is no current context, so prompts show nothing until one is set up.
+func promptSegment(format string) string {
+	userConfig, err := config.LoadUserConfig()
+	if err != nil || userConfig.CurrentContext == "" {
+		return ""
+	}
+	context, ok := userConfig.Contexts[userConfig.CurrentContext]
+	if !ok {
+		return ""
+	}
+
+	environment := util.EnvironmentSlug(context.Environment)
+	if environment == "" {
+		environment = context.Environment
+		if len(environment) > 8 {
+			environment = environment[:8]
+		}
+	}
+	server := ""
+	if cluster, ok := userConfig.Clusters[context.Cluster]; ok {
+		if serverURL, err := url.Parse(cluster.Server); err == nil {
+			server = serverURL.Host
+		}
+	}
+
+	return strings.NewReplacer(
+		"{context}", userConfig.CurrentContext,
+		"{environment}", environment,
+		"{environment-id}", context.Environment,
+		"{server}", server,
+	).Replace(format)
+}
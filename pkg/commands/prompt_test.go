@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/arctir/devgraph-cli/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	serverURL, err := url.Parse(srv.server.URL)
+	require.NoError(t, err)
+
+	// The slug isn't known until environments have been listed
+	cmd := PromptCommand{Format: "{context}:{environment}"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Equal(t, "test:11111111\n", output)
+
+	srv.handle("GET /api/v1/environments", http.StatusOK, []map[string]any{testEnvironment()})
+	_, err = util.GetEnvironments(cfg)
+	require.NoError(t, err)
+
+	srv.mu.Lock()
+	sent := len(srv.requests)
+	srv.mu.Unlock()
+
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Equal(t, "test:production\n", output)
+
+	cmd.Format = "dg({environment-id}@{server})"
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Equal(t, "dg("+testEnvironmentID+"@"+serverURL.Host+")\n", output)
+
+	// Prompts never wait on the API
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	assert.Len(t, srv.requests, sent)
+}
+
+func TestPromptCommand_NoContext(t *testing.T) {
+	t.Cleanup(setupTempConfig(t))
+
+	cmd := PromptCommand{Format: "{context}:{environment}"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Equal(t, "\n", output)
+}
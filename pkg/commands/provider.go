@@ -1,34 +1,44 @@
 package commands
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/arctir/devgraph-cli/pkg/output"
 	"github.com/arctir/devgraph-cli/pkg/util"
 	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
 	"github.com/google/uuid"
-	"gopkg.in/yaml.v3"
+	"golang.org/x/term"
 )
 
 // ProviderCommand handles discovery provider management
 type ProviderCommand struct {
-	List   ProviderListCommand   `cmd:"" help:"List all configured discovery providers."`
-	Get    ProviderGetCommand    `cmd:"get" help:"Get a specific configured discovery provider."`
-	Delete ProviderDeleteCommand `cmd:"delete" help:"Delete a configured discovery provider."`
+	List     ProviderListCommand     `cmd:"" help:"List all configured discovery providers."`
+	Get      ProviderGetCommand      `cmd:"get" help:"Get a specific configured discovery provider."`
+	Create   ProviderCreateCommand   `cmd:"create" help:"Create a discovery provider (prompts for type-specific settings)."`
+	Update   ProviderUpdateCommand   `cmd:"update" help:"Update a discovery provider's name, credentials or configuration."`
+	Delete   ProviderDeleteCommand   `cmd:"delete" help:"Delete a configured discovery provider."`
+	Status   ProviderStatusCommand   `cmd:"status" help:"Show the outcome of a provider's most recent discovery run."`
+	Schedule ProviderScheduleCommand `cmd:"schedule" help:"View or change a provider's sync schedule."`
+	Pause    ProviderPauseCommand    `cmd:"pause" help:"Pause syncing for a provider by disabling it."`
+	Resume   ProviderResumeCommand   `cmd:"resume" help:"Resume syncing for a provider by enabling it."`
 }
 
 // ProviderListCommand lists all configured discovery providers
 type ProviderListCommand struct {
 	EnvWrapperCommand
-	Output string `flag:"output,o" default:"table" help:"Output format: table, json, yaml."`
 }
 
 // ProviderGetCommand gets a specific configured discovery provider
 type ProviderGetCommand struct {
 	EnvWrapperCommand
 	ProviderID string `arg:"" required:"" help:"Provider ID (UUID)."`
-	Output     string `flag:"output,o" default:"json" help:"Output format: json, yaml."`
 }
 
 // ProviderDeleteCommand deletes a configured discovery provider
@@ -38,83 +48,348 @@ type ProviderDeleteCommand struct {
 	Yes        bool   `flag:"yes,y" help:"Skip confirmation prompt."`
 }
 
-func (p *ProviderListCommand) Run() error {
+// ProviderCreateCommand creates a discovery provider. The fields to ask for
+// come from the provider type's config schema; anything not given on the
+// command line is prompted for when running in a terminal.
+type ProviderCreateCommand struct {
+	EnvWrapperCommand
+	Type           string `arg:"" optional:"" help:"Provider type (github, gitlab, ...). Prompted for if omitted."`
+	Name           string `flag:"name" optional:"" help:"Name of the provider."`
+	Enabled        bool   `flag:"enabled" default:"true" negatable:"" help:"Enable the provider after creation."`
+	NoInput        bool   `flag:"no-input" help:"Never prompt; fail if required values are missing."`
+	SkipValidation bool   `flag:"skip-validation" help:"Don't check credentials against the source system before creating."`
+	ProviderConfigFlags
+}
+
+// ProviderUpdateCommand updates a discovery provider in place so its
+// discovered entities stay associated with it
+type ProviderUpdateCommand struct {
+	EnvWrapperCommand
+	ProviderID     string   `arg:"" required:"" help:"Provider ID (UUID)."`
+	Name           *string  `flag:"name" optional:"" help:"New name for the provider."`
+	Enabled        *bool    `flag:"enabled" negatable:"" optional:"" help:"Enable or disable the provider."`
+	Unset          []string `flag:"unset" optional:"" help:"Config keys to remove (can be specified multiple times)."`
+	SkipValidation bool     `flag:"skip-validation" help:"Don't check new credentials against the source system."`
+	ProviderConfigFlags
+}
+
+// ProviderConfigFlags are the config flags shared by create and update. The
+// grouped flags are shortcuts for config keys common to well-known provider
+// types and are only accepted when the type's schema declares the key.
+type ProviderConfigFlags struct {
+	Set     []string `flag:"set" optional:"" help:"Config values as key=value (can be specified multiple times)."`
+	SetFile []string `flag:"set-file" optional:"" help:"Config values read from files as key=path (can be specified multiple times)."`
+
+	Token        string `flag:"token" optional:"" group:"GitHub/GitLab" help:"Access token (or set GITHUB_TOKEN / GITLAB_TOKEN)."`
+	BaseURL      string `flag:"base-url" optional:"" group:"GitHub/GitLab" help:"API base URL for self-hosted instances."`
+	Organization string `flag:"organization" optional:"" group:"GitHub" help:"GitHub organization to discover."`
+	Group        string `flag:"group" optional:"" group:"GitLab" help:"GitLab group to discover."`
+	Kubeconfig   string `flag:"kubeconfig" optional:"" group:"Kubernetes" help:"Path to a kubeconfig file; its contents are sent to the server."`
+	KubeContext  string `flag:"kube-context" optional:"" group:"Kubernetes" help:"Kubeconfig context to use."`
+	Namespace    string `flag:"namespace" optional:"" group:"Kubernetes" help:"Namespace to discover (default: all)."`
+}
+
+// ProviderStatusCommand shows the latest discovery run for a provider
+type ProviderStatusCommand struct {
+	EnvWrapperCommand
+	ProviderID string `arg:"" required:"" help:"Provider ID (UUID)."`
+}
+
+// ProviderScheduleCommand shows or updates a provider's sync interval
+type ProviderScheduleCommand struct {
+	EnvWrapperCommand
+	ProviderID string        `arg:"" required:"" help:"Provider ID (UUID)."`
+	Interval   time.Duration `flag:"interval" optional:"" help:"Set the sync interval (e.g. 6h, minimum 1m)."`
+}
+
+// ProviderPauseCommand pauses syncing
+type ProviderPauseCommand struct {
+	EnvWrapperCommand
+	ProviderID string `arg:"" required:"" help:"Provider ID (UUID)."`
+}
+
+// ProviderResumeCommand resumes syncing
+type ProviderResumeCommand struct {
+	EnvWrapperCommand
+	ProviderID string `arg:"" required:"" help:"Provider ID (UUID)."`
+}
+
+// providerSchedule is a provider's sync schedule. NextRunAt is estimated
+// from the last run and the interval.
+type providerSchedule struct {
+	IntervalSeconds int        `json:"interval_seconds" yaml:"interval_seconds"`
+	Enabled         bool       `json:"enabled" yaml:"enabled"`
+	LastRunAt       string     `json:"last_run_at,omitempty" yaml:"last_run_at,omitempty"`
+	NextRunAt       *time.Time `json:"next_run_at,omitempty" yaml:"next_run_at,omitempty"`
+}
+
+func newProviderSchedule(provider *api.ConfiguredProviderResponse) providerSchedule {
+	schedule := providerSchedule{
+		IntervalSeconds: provider.Interval,
+		Enabled:         provider.Enabled,
+	}
+	schedule.LastRunAt, _ = provider.LastRunAt.Get()
+	if lastRun, err := time.Parse(time.RFC3339, schedule.LastRunAt); err == nil && provider.Enabled && provider.Interval > 0 {
+		next := lastRun.Add(time.Duration(provider.Interval) * time.Second)
+		schedule.NextRunAt = &next
+	}
+	return schedule
+}
+
+// providerStatus is the sync state of a provider as reported by the API
+type providerStatus struct {
+	ID               string `json:"id" yaml:"id"`
+	Name             string `json:"name" yaml:"name"`
+	Enabled          bool   `json:"enabled" yaml:"enabled"`
+	IntervalSeconds  int    `json:"interval_seconds" yaml:"interval_seconds"`
+	LastRunAt        string `json:"last_run_at,omitempty" yaml:"last_run_at,omitempty"`
+	LastRunStatus    string `json:"last_run_status,omitempty" yaml:"last_run_status,omitempty"`
+	LastErrorMessage string `json:"last_error_message,omitempty" yaml:"last_error_message,omitempty"`
+}
+
+// providerStatusOutput is a provider as shown by 'dg provider list'
+type providerStatusOutput struct {
+	providerStatus `yaml:",inline"`
+	ProviderType   string `json:"provider_type" yaml:"provider_type"`
+	EnvironmentID  string `json:"environment_id" yaml:"environment_id"`
+}
+
+func newProviderStatus(provider *api.ConfiguredProviderResponse) providerStatus {
+	status := providerStatus{
+		ID:              provider.ID.String(),
+		Name:            provider.Name,
+		Enabled:         provider.Enabled,
+		IntervalSeconds: provider.Interval,
+	}
+	status.LastRunAt, _ = provider.LastRunAt.Get()
+	status.LastRunStatus, _ = provider.LastRunStatus.Get()
+	status.LastErrorMessage, _ = provider.LastErrorMessage.Get()
+	return status
+}
+
+// getConfiguredProvider fetches a provider by ID, turning the API's error
+// responses into errors
+func getConfiguredProvider(client *api.Client, id string) (*api.ConfiguredProviderResponse, error) {
+	providerID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid provider ID: %w", err)
+	}
+
+	resp, err := client.GetConfiguredProvider(context.Background(), api.GetConfiguredProviderParams{
+		ProviderID: providerID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider: %w", err)
+	}
+
+	switch r := resp.(type) {
+	case *api.ConfiguredProviderResponse:
+		return r, nil
+	case *api.GetConfiguredProviderNotFound:
+		return nil, fmt.Errorf("provider not found: %s", id)
+	case *api.HTTPValidationError:
+		return nil, fmt.Errorf("validation error: %v", r.Detail)
+	default:
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
+	}
+}
+
+func (p *ProviderCreateCommand) Run() error {
+	interactive := !p.NoInput && term.IsTerminal(int(os.Stdin.Fd()))
+	reader := bufio.NewReader(os.Stdin)
+
 	client, err := util.GetAuthenticatedClient(p.Config)
 	if err != nil {
 		return fmt.Errorf("failed to create authenticated client: %w", err)
 	}
 
-	resp, err := client.ListConfiguredProviders(context.Background())
+	if p.Type == "" {
+		types, err := listProviderTypes(client)
+		if err != nil {
+			return err
+		}
+		if len(types) == 0 {
+			return fmt.Errorf("no discovery provider types are available")
+		}
+		if !interactive {
+			names := make([]string, len(types))
+			for i, t := range types {
+				names[i] = t.Type
+			}
+			return fmt.Errorf("provider type is required (one of: %s)", strings.Join(names, ", "))
+		}
+		fmt.Println("Available provider types:")
+		for i, t := range types {
+			fmt.Printf("  %d. %s - %s\n", i+1, t.Type, t.Description)
+		}
+		for p.Type == "" {
+			fmt.Print("\nSelect a provider type (enter number): ")
+			input, _ := reader.ReadString('\n')
+			choice, err := strconv.Atoi(strings.TrimSpace(input))
+			if err != nil || choice < 1 || choice > len(types) {
+				fmt.Printf("Invalid choice. Please enter a number between 1 and %d.\n", len(types))
+				continue
+			}
+			p.Type = types[choice-1].Type
+		}
+	}
+
+	schema, err := getProviderSchema(client, p.Type)
 	if err != nil {
-		return fmt.Errorf("failed to list providers: %w", err)
+		return err
 	}
 
-	switch r := resp.(type) {
-	case *api.ConfiguredProvidersListResponse:
-		if len(r.Providers) == 0 {
-			fmt.Println("No configured providers found.")
-			return nil
+	values, err := p.values(schema, p.Type)
+	if err != nil {
+		return err
+	}
+
+	if p.Name == "" && interactive {
+		fmt.Print("Provider name: ")
+		input, _ := reader.ReadString('\n')
+		p.Name = strings.TrimSpace(input)
+	}
+	if p.Name == "" {
+		return fmt.Errorf("provider name is required (--name)")
+	}
+
+	var prompt func(providerField) (string, error)
+	if interactive {
+		prompt = func(field providerField) (string, error) {
+			if field.Description != "" {
+				fmt.Printf("# %s\n", field.Description)
+			}
+			fmt.Printf("%s: ", field.prompt())
+			if field.Secret {
+				secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+				fmt.Println()
+				return strings.TrimSpace(string(secret)), err
+			}
+			input, _ := reader.ReadString('\n')
+			return strings.TrimSpace(input), nil
 		}
+	}
+	if err := resolveProviderValues(schema, values, prompt); err != nil {
+		return err
+	}
 
-		switch p.Output {
-		case "json":
-			return p.displayAsJSON(r.Providers)
-		case "yaml", "yml":
-			return p.displayAsYAML(r.Providers)
-		case "table":
-			return p.displayAsTable(r.Providers)
-		default:
-			return fmt.Errorf("unsupported output format: %s", p.Output)
+	if check, ok := providerCredentialChecks[p.Type]; ok && values["token"] != "" && !p.SkipValidation {
+		httpClient, err := p.Config.HTTPClient()
+		if err != nil {
+			return err
 		}
-	case *api.ListConfiguredProvidersNotFound:
-		fmt.Println("No configured providers found.")
+		fmt.Printf("Validating %s credentials...\n", p.Type)
+		if err := check(httpClient, values); err != nil {
+			return fmt.Errorf("credential check failed (use --skip-validation to create anyway): %w", err)
+		}
+	}
+
+	config, err := schema.encode(values)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.CreateConfiguredProvider(context.Background(), &api.ConfiguredProviderCreate{
+		Name:         p.Name,
+		ProviderType: p.Type,
+		Enabled:      api.NewOptBool(p.Enabled),
+		Config:       config,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create provider: %w", err)
+	}
+
+	switch r := resp.(type) {
+	case *api.ConfiguredProviderResponse:
+		fmt.Printf("✅ Provider '%s' created successfully with ID: %s\n", r.Name, r.ID)
 		return nil
+	case *api.CreateConfiguredProviderNotFound:
+		return fmt.Errorf("unknown provider type '%s'", p.Type)
+	case *api.HTTPValidationError:
+		return fmt.Errorf("validation error: %v", r.Detail)
 	default:
 		return fmt.Errorf("unexpected response type: %T", resp)
 	}
 }
 
-func (p *ProviderListCommand) displayAsTable(providers []api.ConfiguredProviderResponse) error {
-	headers := []string{"ID", "Provider Type", "Name", "Enabled", "Environment ID"}
-	data := make([]map[string]interface{}, len(providers))
+// values collects the shortcut flags, --set and --set-file values into a
+// config map, rejecting shortcut flags the provider type's schema doesn't
+// declare
+func (p *ProviderConfigFlags) values(schema *providerSchema, providerType string) (map[string]string, error) {
+	values := make(map[string]string)
 
-	for i, provider := range providers {
-		enabled := "false"
-		if provider.Enabled {
-			enabled = "true"
+	flags := []struct {
+		flag, key, value string
+	}{
+		{"--token", "token", p.Token},
+		{"--base-url", "base_url", p.BaseURL},
+		{"--organization", "organization", p.Organization},
+		{"--group", "group", p.Group},
+		{"--kubeconfig", "kubeconfig", p.Kubeconfig},
+		{"--kube-context", "context", p.KubeContext},
+		{"--namespace", "namespace", p.Namespace},
+	}
+	for _, f := range flags {
+		if f.value == "" {
+			continue
+		}
+		if _, ok := schema.field(f.key); !ok {
+			return nil, fmt.Errorf("%s is not used by provider type '%s'", f.flag, providerType)
 		}
+		values[f.key] = f.value
+	}
 
-		data[i] = map[string]interface{}{
-			"ID":             provider.ID.String(),
-			"Provider Type":  provider.ProviderType,
-			"Name":           provider.Name,
-			"Enabled":        enabled,
-			"Environment ID": provider.EnvironmentID.String(),
+	// The server can't read files on this machine, so send the contents
+	if p.Kubeconfig != "" {
+		contents, err := readProviderFile(p.Kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --kubeconfig: %w", err)
 		}
+		values["kubeconfig"] = contents
 	}
 
-	displayEntityTable(data, headers)
-	return nil
-}
+	for _, pair := range p.Set {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid --set format '%s', expected 'key=value'", pair)
+		}
+		values[strings.TrimSpace(parts[0])] = parts[1]
+	}
 
-func (p *ProviderListCommand) displayAsJSON(providers []api.ConfiguredProviderResponse) error {
-	jsonData, err := json.MarshalIndent(providers, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal providers to JSON: %w", err)
+	for _, pair := range p.SetFile {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid --set-file format '%s', expected 'key=path'", pair)
+		}
+		contents, err := readProviderFile(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid --set-file value for '%s': %w", parts[0], err)
+		}
+		values[strings.TrimSpace(parts[0])] = contents
 	}
 
-	fmt.Println(string(jsonData))
-	return nil
+	return values, nil
 }
 
-func (p *ProviderListCommand) displayAsYAML(providers []api.ConfiguredProviderResponse) error {
-	yamlData, err := yaml.Marshal(providers)
+func (p *ProviderListCommand) Run() error {
+	client, err := util.GetAuthenticatedClient(p.Config)
 	if err != nil {
-		return fmt.Errorf("failed to marshal providers to YAML: %w", err)
+		return fmt.Errorf("failed to create authenticated client: %w", err)
 	}
 
-	fmt.Print(string(yamlData))
-	return nil
+	resp, err := client.ListConfiguredProviders(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to list providers: %w", err)
+	}
+
+	switch r := resp.(type) {
+	case *api.ConfiguredProvidersListResponse:
+		return output.Print(p.OutputFormat(output.Table), providerResult(r.Providers))
+	case *api.ListConfiguredProvidersNotFound:
+		return output.Print(p.OutputFormat(output.Table), providerResult(nil))
+	default:
+		return fmt.Errorf("unexpected response type: %T", resp)
+	}
 }
 
 func (p *ProviderGetCommand) Run() error {
@@ -139,23 +414,9 @@ func (p *ProviderGetCommand) Run() error {
 
 	switch r := resp.(type) {
 	case *api.ConfiguredProviderResponse:
-		switch p.Output {
-		case "json":
-			jsonData, err := json.MarshalIndent(r, "", "  ")
-			if err != nil {
-				return fmt.Errorf("failed to marshal provider to JSON: %w", err)
-			}
-			fmt.Println(string(jsonData))
-		case "yaml", "yml":
-			yamlData, err := yaml.Marshal(r)
-			if err != nil {
-				return fmt.Errorf("failed to marshal provider to YAML: %w", err)
-			}
-			fmt.Print(string(yamlData))
-		default:
-			return fmt.Errorf("unsupported output format: %s", p.Output)
-		}
-		return nil
+		result := providerResult([]api.ConfiguredProviderResponse{*r})
+		result.Data = r
+		return output.Print(p.OutputFormat(output.JSON), result)
 	case *api.GetConfiguredProviderNotFound:
 		return fmt.Errorf("provider not found: %s", p.ProviderID)
 	case *api.HTTPValidationError:
@@ -208,3 +469,294 @@ func (p *ProviderDeleteCommand) Run() error {
 		return fmt.Errorf("unexpected response type: %T", resp)
 	}
 }
+
+func (p *ProviderStatusCommand) Run() error {
+	format := p.OutputFormat(output.Table)
+	if err := output.Check(format, output.Table, output.JSON, output.YAML); err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(p.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	provider, err := getConfiguredProvider(client, p.ProviderID)
+	if err != nil {
+		return err
+	}
+
+	status := newProviderStatus(provider)
+	if format != output.Table {
+		return output.Print(format, output.Result{Data: status})
+	}
+
+	fmt.Printf("Provider:  %s (%s)\n", status.Name, status.ID)
+	fmt.Printf("Enabled:   %t\n", status.Enabled)
+	if status.LastRunAt == "" {
+		fmt.Println("Last run:  never")
+		return nil
+	}
+	fmt.Printf("Last run:  %s\n", status.LastRunAt)
+	if status.LastRunStatus != "" {
+		fmt.Printf("Status:    %s\n", status.LastRunStatus)
+	}
+	if status.LastErrorMessage != "" {
+		fmt.Printf("Error:     %s\n", status.LastErrorMessage)
+	}
+	return nil
+}
+
+func (p *ProviderScheduleCommand) Run() error {
+	format := p.OutputFormat(output.Table)
+	if err := output.Check(format, output.Table, output.JSON, output.YAML); err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(p.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	var provider *api.ConfiguredProviderResponse
+	if p.Interval != 0 {
+		if p.Interval < time.Minute || p.Interval%time.Second != 0 {
+			return fmt.Errorf("sync interval must be a whole number of seconds and at least 1m")
+		}
+		provider, err = updateConfiguredProvider(client, p.ProviderID, &api.ConfiguredProviderUpdate{
+			Interval: api.NewOptNilInt(int(p.Interval / time.Second)),
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Sync schedule for provider '%s' updated successfully.\n", p.ProviderID)
+	} else {
+		provider, err = getConfiguredProvider(client, p.ProviderID)
+		if err != nil {
+			return err
+		}
+	}
+
+	schedule := newProviderSchedule(provider)
+	if format != output.Table {
+		return output.Print(format, output.Result{Data: schedule})
+	}
+
+	fmt.Printf("Interval: every %s\n", time.Duration(schedule.IntervalSeconds)*time.Second)
+	fmt.Printf("Paused:   %t\n", !schedule.Enabled)
+	if schedule.NextRunAt != nil {
+		fmt.Printf("Next run: %s\n", schedule.NextRunAt.Local().Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (p *ProviderPauseCommand) Run() error {
+	return setProviderEnabled(p.EnvWrapperCommand, p.ProviderID, false)
+}
+
+func (p *ProviderResumeCommand) Run() error {
+	return setProviderEnabled(p.EnvWrapperCommand, p.ProviderID, true)
+}
+
+// setProviderEnabled pauses or resumes syncing. A disabled provider keeps
+// its configuration and discovered entities but isn't run.
+func setProviderEnabled(e EnvWrapperCommand, id string, enabled bool) error {
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	if _, err := updateConfiguredProvider(client, id, &api.ConfiguredProviderUpdate{
+		Enabled: api.NewOptNilBool(enabled),
+	}); err != nil {
+		return err
+	}
+
+	if enabled {
+		fmt.Printf("✅ Syncing resumed for provider '%s'.\n", id)
+	} else {
+		fmt.Printf("✅ Syncing paused for provider '%s'.\n", id)
+	}
+	return nil
+}
+
+// updateConfiguredProvider applies a partial update to a provider, turning
+// the API's error responses into errors
+func updateConfiguredProvider(client *api.Client, id string, update *api.ConfiguredProviderUpdate) (*api.ConfiguredProviderResponse, error) {
+	providerID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid provider ID: %w", err)
+	}
+
+	resp, err := client.UpdateConfiguredProvider(context.Background(), update, api.UpdateConfiguredProviderParams{
+		ProviderID: providerID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update provider: %w", err)
+	}
+
+	switch r := resp.(type) {
+	case *api.ConfiguredProviderResponse:
+		return r, nil
+	case *api.UpdateConfiguredProviderNotFound:
+		return nil, fmt.Errorf("provider not found: %s", id)
+	case *api.HTTPValidationError:
+		return nil, fmt.Errorf("validation error: %v", r.Detail)
+	default:
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
+	}
+}
+
+func (p *ProviderUpdateCommand) Run() error {
+	client, err := util.GetAuthenticatedClient(p.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	provider, err := getConfiguredProvider(client, p.ProviderID)
+	if err != nil {
+		return err
+	}
+
+	schema, err := getProviderSchema(client, provider.ProviderType)
+	if err != nil {
+		return err
+	}
+
+	values, err := p.values(schema, provider.ProviderType)
+	if err != nil {
+		return err
+	}
+
+	if p.Name == nil && p.Enabled == nil && len(values) == 0 && len(p.Unset) == 0 {
+		return fmt.Errorf("no fields specified to update")
+	}
+
+	// Only check credentials when they're being changed, against the
+	// provider's stored settings (e.g. a self-hosted base_url) with the new
+	// values on top
+	if credentialCheck, ok := providerCredentialChecks[provider.ProviderType]; ok && values["token"] != "" && !p.SkipValidation {
+		check := make(map[string]string)
+		for _, field := range schema.Fields {
+			check[field.Name] = field.Default
+		}
+		for key, raw := range provider.Config {
+			var value string
+			if json.Unmarshal(raw, &value) == nil && value != "" {
+				check[key] = value
+			}
+		}
+		for key, value := range values {
+			check[key] = value
+		}
+		httpClient, err := p.Config.HTTPClient()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Validating %s credentials...\n", provider.ProviderType)
+		if err := credentialCheck(httpClient, check); err != nil {
+			return fmt.Errorf("credential check failed (use --skip-validation to update anyway): %w", err)
+		}
+	}
+
+	update := &api.ConfiguredProviderUpdate{}
+	if p.Name != nil {
+		update.Name = api.NewOptNilString(*p.Name)
+	}
+	if p.Enabled != nil {
+		update.Enabled = api.NewOptNilBool(*p.Enabled)
+	}
+	if len(values) > 0 || len(p.Unset) > 0 {
+		config, err := p.mergeConfig(schema, provider, values)
+		if err != nil {
+			return err
+		}
+		update.Config = api.NewOptNilConfiguredProviderUpdateConfig(config)
+	}
+
+	if _, err := updateConfiguredProvider(client, p.ProviderID, update); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Provider '%s' updated successfully.\n", p.ProviderID)
+	return nil
+}
+
+// mergeConfig builds the full config sent on update, since the API replaces
+// the config as a whole. Stored secrets come back masked, so any secret in
+// the stored config has to be given again rather than sent back masked.
+func (p *ProviderUpdateCommand) mergeConfig(schema *providerSchema, provider *api.ConfiguredProviderResponse, values map[string]string) (api.ConfiguredProviderUpdateConfig, error) {
+	unset := make(map[string]bool, len(p.Unset))
+	for _, key := range p.Unset {
+		unset[key] = true
+	}
+
+	config := make(api.ConfiguredProviderUpdateConfig, len(provider.Config)+len(values))
+	for key, raw := range provider.Config {
+		if unset[key] {
+			continue
+		}
+		if field, ok := schema.field(key); ok && field.Secret {
+			if _, given := values[key]; !given {
+				return nil, fmt.Errorf("updating the config replaces it as a whole; give secret '%s' again (e.g. --set %s=...)", key, key)
+			}
+		}
+		config[key] = raw
+	}
+
+	changed, err := schema.encode(values)
+	if err != nil {
+		return nil, err
+	}
+	for key, raw := range changed {
+		config[key] = []byte(raw)
+	}
+	return config, nil
+}
+
+// providerResult is how configured providers print in each output format.
+// Providers are named by ID, which the other provider commands take.
+func providerResult(providers []api.ConfiguredProviderResponse) output.Result {
+	result := output.Result{
+		Headers: []string{"ID", "Name", "Type", "Enabled", "Status", "Last Sync", "Errors"},
+		Empty:   "No configured providers found.",
+		Names:   []string{},
+	}
+	structured := make([]providerStatusOutput, len(providers))
+	for i := range providers {
+		provider := &providers[i]
+		status := newProviderStatus(provider)
+		structured[i] = providerStatusOutput{
+			providerStatus: status,
+			ProviderType:   provider.ProviderType,
+			EnvironmentID:  provider.EnvironmentID.String(),
+		}
+
+		lastSync, errorsCol := "-", "-"
+		if lastRun, err := time.Parse(time.RFC3339, status.LastRunAt); err == nil {
+			lastSync = lastRun.Local().Format("2006-01-02 15:04")
+		} else if status.LastRunAt != "" {
+			lastSync = status.LastRunAt
+		}
+		if status.LastErrorMessage != "" {
+			errorsCol = truncate(status.LastErrorMessage, 40)
+		}
+		statusCol := status.LastRunStatus
+		if statusCol == "" {
+			statusCol = "-"
+		}
+
+		result.Rows = append(result.Rows, map[string]any{
+			"ID":        status.ID,
+			"Name":      status.Name,
+			"Type":      provider.ProviderType,
+			"Enabled":   map[bool]string{true: "Yes", false: "No"}[provider.Enabled],
+			"Status":    statusCol,
+			"Last Sync": lastSync,
+			"Errors":    errorsCol,
+		})
+		result.Names = append(result.Names, status.ID)
+	}
+	result.Data = structured
+	return result
+}
@@ -0,0 +1,399 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testGitHubSchema is a provider config schema in the form the API returns
+const testGitHubSchema = `{
+	"title": "GitHubConfig",
+	"type": "object",
+	"properties": {
+		"token": {"title": "GitHub token", "type": "string", "format": "password", "writeOnly": true},
+		"organization": {"title": "Organization", "type": "string"},
+		"base_url": {"title": "API base URL", "type": "string", "format": "uri", "default": "https://api.github.com"},
+		"include_archived": {"anyOf": [{"type": "boolean"}, {"type": "null"}], "default": false},
+		"topics": {"type": "array", "items": {"type": "string"}},
+		"visibility": {"type": "string", "enum": ["public", "private", "all"]}
+	},
+	"required": ["token", "organization"]
+}`
+
+func testProviderSchema(t *testing.T) *providerSchema {
+	t.Helper()
+	schema, err := parseProviderSchema("github", []byte(testGitHubSchema))
+	require.NoError(t, err)
+	return schema
+}
+
+func TestParseProviderSchema(t *testing.T) {
+	schema := testProviderSchema(t)
+
+	require.Len(t, schema.Fields, 6)
+	assert.Equal(t, "organization", schema.Fields[0].Name, "required fields come first")
+	assert.Equal(t, "token", schema.Fields[1].Name)
+
+	token, ok := schema.field("token")
+	require.True(t, ok)
+	assert.True(t, token.Secret)
+	assert.True(t, token.Required)
+	assert.Equal(t, "GITHUB_TOKEN", token.Env)
+
+	archived, ok := schema.field("include_archived")
+	require.True(t, ok)
+	assert.Equal(t, "boolean", archived.Type, "optional fields are unwrapped from anyOf")
+	assert.Equal(t, "false", archived.Default)
+}
+
+func TestProviderSchemaEncode(t *testing.T) {
+	schema := testProviderSchema(t)
+
+	config, err := schema.encode(map[string]string{
+		"organization":     "arctir",
+		"include_archived": "true",
+		"topics":           "platform, infra",
+		"extra":            "42",
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `"arctir"`, string(config["organization"]))
+	assert.JSONEq(t, `true`, string(config["include_archived"]))
+	assert.JSONEq(t, `["platform","infra"]`, string(config["topics"]))
+	assert.JSONEq(t, `42`, string(config["extra"]), "undeclared keys keep their JSON type")
+
+	_, err = schema.encode(map[string]string{"include_archived": "maybe"})
+	assert.Error(t, err)
+}
+
+func TestResolveProviderValues(t *testing.T) {
+	schema := testProviderSchema(t)
+	t.Setenv("GITHUB_TOKEN", "from-env")
+
+	values := map[string]string{"organization": "arctir"}
+	require.NoError(t, resolveProviderValues(schema, values, nil))
+	assert.Equal(t, "from-env", values["token"])
+	_, ok := values["base_url"]
+	assert.False(t, ok, "defaults are left to the server")
+
+	// Prompted values are used for anything not set explicitly
+	t.Setenv("GITHUB_TOKEN", "")
+	prompted := map[string]string{}
+	err := resolveProviderValues(schema, prompted, func(field providerField) (string, error) {
+		if field.Name == "token" || field.Name == "organization" {
+			return field.Name + "-value", nil
+		}
+		return "", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "token-value", prompted["token"])
+	assert.Equal(t, "organization-value", prompted["organization"])
+
+	// Missing required values fail without a prompt
+	err = resolveProviderValues(schema, map[string]string{"token": "x"}, nil)
+	assert.Error(t, err)
+
+	// Values are validated against format and enum
+	err = resolveProviderValues(schema, map[string]string{
+		"token": "x", "organization": "arctir", "base_url": "not a url",
+	}, nil)
+	assert.Error(t, err)
+	err = resolveProviderValues(schema, map[string]string{
+		"token": "x", "organization": "arctir", "visibility": "internal",
+	}, nil)
+	assert.Error(t, err)
+}
+
+func TestProviderConfigFlags_Values(t *testing.T) {
+	schema := testProviderSchema(t)
+
+	flags := ProviderConfigFlags{
+		Organization: "arctir",
+		Set:          []string{"include_archived=true"},
+	}
+	values, err := flags.values(schema, "github")
+	require.NoError(t, err)
+	assert.Equal(t, "arctir", values["organization"])
+	assert.Equal(t, "true", values["include_archived"])
+
+	flags = ProviderConfigFlags{Namespace: "default"}
+	_, err = flags.values(schema, "github")
+	assert.Error(t, err, "flags for keys the schema doesn't declare should be rejected")
+
+	flags = ProviderConfigFlags{Set: []string{"missing-separator"}}
+	_, err = flags.values(schema, "github")
+	assert.Error(t, err)
+
+	// Files are read so the server gets their contents
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(path, []byte("apiVersion: v1\n"), 0600))
+	kubeSchema, err := parseProviderSchema("kubernetes", []byte(`{"properties": {"kubeconfig": {"type": "string"}}}`))
+	require.NoError(t, err)
+	values, err = (&ProviderConfigFlags{Kubeconfig: path}).values(kubeSchema, "kubernetes")
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1\n", values["kubeconfig"])
+
+	values, err = (&ProviderConfigFlags{SetFile: []string{"ca_cert=" + path}}).values(schema, "github")
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1\n", values["ca_cert"])
+}
+
+func TestProviderCreateCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal([]byte(testGitHubSchema), &schema))
+	srv.handle("GET /api/v1/discovery/providers/github/config-schema", http.StatusOK, schema)
+	srv.handle("POST /api/v1/discovery/configured-providers", http.StatusCreated, testConfiguredProvider())
+
+	cmd := ProviderCreateCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Type:              "github",
+		Name:              "github-arctir",
+		Enabled:           true,
+		NoInput:           true,
+		SkipValidation:    true,
+		ProviderConfigFlags: ProviderConfigFlags{
+			Token:        "ghp_test",
+			Organization: "arctir",
+			Set:          []string{"include_archived=true"},
+		},
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	body := srv.requireRequest(http.MethodPost, "/api/v1/discovery/configured-providers").JSON(t)
+	assert.Equal(t, "github-arctir", body["name"])
+	assert.Equal(t, "github", body["provider_type"])
+	assert.Equal(t, true, body["enabled"])
+	assert.Equal(t, map[string]any{
+		"token":            "ghp_test",
+		"organization":     "arctir",
+		"include_archived": true,
+	}, body["config"])
+	assert.Contains(t, output, testProviderID)
+}
+
+func TestProviderCreateCommand_UnknownType(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/discovery/providers/{type}/config-schema", http.StatusNotFound, nil)
+
+	cmd := ProviderCreateCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Type:              "nope",
+		Name:              "x",
+		NoInput:           true,
+	}
+	_, err := captureOutput(t, cmd.Run)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown provider type 'nope'")
+	assert.Empty(t, srv.received(http.MethodPost, "/api/v1/discovery/configured-providers"))
+}
+
+const testProviderID = "44444444-4444-4444-4444-444444444444"
+
+// testConfiguredProvider returns a configured provider as the API reports it
+func testConfiguredProvider() map[string]any {
+	return map[string]any{
+		"id":             testProviderID,
+		"environment_id": testEnvironmentID,
+		"name":           "github-arctir",
+		"provider_type":  "github",
+		"enabled":        true,
+		"interval":       3600,
+		"config":         map[string]any{"organization": "arctir", "base_url": "https://github.example.com/api/v3"},
+	}
+}
+
+func TestProviderStatusCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	provider := testConfiguredProvider()
+	provider["last_run_at"] = "2026-10-01T12:00:00Z"
+	provider["last_run_status"] = "failed"
+	provider["last_error_message"] = "bad credentials"
+	srv.handle("GET /api/v1/discovery/configured-providers/{id}", http.StatusOK, provider)
+
+	cmd := ProviderStatusCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		ProviderID:        testProviderID,
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	srv.requireRequest(http.MethodGet, "/api/v1/discovery/configured-providers/"+testProviderID)
+	assert.Contains(t, output, "2026-10-01T12:00:00Z")
+	assert.Contains(t, output, "failed")
+	assert.Contains(t, output, "bad credentials")
+}
+
+func TestProviderStatusCommand_NeverRun(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/discovery/configured-providers/{id}", http.StatusOK, testConfiguredProvider())
+
+	cmd := ProviderStatusCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		ProviderID:        testProviderID,
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "never")
+}
+
+func TestProviderScheduleCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	provider := testConfiguredProvider()
+	provider["interval"] = 21600
+	provider["last_run_at"] = "2026-10-01T12:00:00Z"
+	srv.handle("PUT /api/v1/discovery/configured-providers/{id}", http.StatusOK, provider)
+
+	cfg.Output = "json"
+	cmd := ProviderScheduleCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		ProviderID:        testProviderID,
+		Interval:          6 * time.Hour,
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	body := srv.requireRequest(http.MethodPut, "/api/v1/discovery/configured-providers/"+testProviderID).JSON(t)
+	assert.Equal(t, map[string]any{"interval": float64(21600)}, body)
+	assert.Contains(t, output, `"interval_seconds": 21600`)
+	assert.Contains(t, output, "2026-10-01T18:00:00Z")
+
+	cmd.Interval = 30 * time.Second
+	_, err = captureOutput(t, cmd.Run)
+	assert.Error(t, err, "intervals under a minute are rejected")
+}
+
+func TestProviderPauseResumeCommands(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("PUT /api/v1/discovery/configured-providers/{id}", http.StatusOK, testConfiguredProvider())
+
+	pause := ProviderPauseCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, ProviderID: testProviderID}
+	_, err := captureOutput(t, pause.Run)
+	require.NoError(t, err)
+
+	resume := ProviderResumeCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, ProviderID: testProviderID}
+	_, err = captureOutput(t, resume.Run)
+	require.NoError(t, err)
+
+	requests := srv.received(http.MethodPut, "/api/v1/discovery/configured-providers/"+testProviderID)
+	require.Len(t, requests, 2)
+	assert.Equal(t, map[string]any{"enabled": false}, requests[0].JSON(t))
+	assert.Equal(t, map[string]any{"enabled": true}, requests[1].JSON(t))
+}
+
+func TestProviderListCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	failed := testConfiguredProvider()
+	failed["last_run_at"] = "2026-10-01T12:00:00Z"
+	failed["last_run_status"] = "failed"
+	failed["last_error_message"] = "bad credentials"
+	srv.handle("GET /api/v1/discovery/configured-providers", http.StatusOK, map[string]any{
+		"providers": []any{failed},
+	})
+
+	cfg.Output = "json"
+	cmd := ProviderListCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	srv.requireRequest(http.MethodGet, "/api/v1/discovery/configured-providers")
+	assert.Len(t, srv.received(http.MethodGet, "/api/v1/discovery/configured-providers/"+testProviderID), 0,
+		"status comes from the list response")
+
+	var providers []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(output), &providers))
+	require.Len(t, providers, 1)
+	assert.Equal(t, "github", providers[0]["provider_type"])
+	assert.Equal(t, "failed", providers[0]["last_run_status"])
+	assert.Equal(t, "bad credentials", providers[0]["last_error_message"])
+}
+
+func TestProviderUpdateCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal([]byte(testGitHubSchema), &schema))
+	srv.handle("GET /api/v1/discovery/providers/github/config-schema", http.StatusOK, schema)
+	stored := testConfiguredProvider()
+	stored["config"] = map[string]any{"token": "********", "organization": "arctir"}
+	srv.handle("GET /api/v1/discovery/configured-providers/{id}", http.StatusOK, stored)
+	srv.handle("PUT /api/v1/discovery/configured-providers/{id}", http.StatusOK, testConfiguredProvider())
+
+	name := "renamed"
+	cmd := ProviderUpdateCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		ProviderID:        testProviderID,
+		Name:              &name,
+		SkipValidation:    true,
+		ProviderConfigFlags: ProviderConfigFlags{
+			Token: "ghp_new",
+			Set:   []string{"include_archived=true"},
+		},
+	}
+	_, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	body := srv.requireRequest(http.MethodPut, "/api/v1/discovery/configured-providers/"+testProviderID).JSON(t)
+	assert.Equal(t, "renamed", body["name"])
+	assert.Equal(t, map[string]any{
+		"token":            "ghp_new",
+		"organization":     "arctir",
+		"include_archived": true,
+	}, body["config"], "config is sent whole with the changes applied")
+	assert.NotContains(t, body, "enabled")
+}
+
+func TestProviderUpdateCommand_RequiresSecretsForConfigChanges(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal([]byte(testGitHubSchema), &schema))
+	srv.handle("GET /api/v1/discovery/providers/github/config-schema", http.StatusOK, schema)
+	stored := testConfiguredProvider()
+	stored["config"] = map[string]any{"token": "********", "organization": "arctir"}
+	srv.handle("GET /api/v1/discovery/configured-providers/{id}", http.StatusOK, stored)
+
+	cmd := ProviderUpdateCommand{
+		EnvWrapperCommand:   EnvWrapperCommand{Config: cfg},
+		ProviderID:          testProviderID,
+		ProviderConfigFlags: ProviderConfigFlags{Organization: "other"},
+	}
+	_, err := captureOutput(t, cmd.Run)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "token")
+	assert.Empty(t, srv.received(http.MethodPut, "/api/v1/discovery/configured-providers/"+testProviderID))
+}
+
+func TestProviderUpdateCommand_ChecksStoredBaseURL(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal([]byte(testGitHubSchema), &schema))
+	srv.handle("GET /api/v1/discovery/providers/github/config-schema", http.StatusOK, schema)
+	srv.handle("PUT /api/v1/discovery/configured-providers/{id}", http.StatusOK, testConfiguredProvider())
+
+	// The fake API doubles as the self-hosted GitHub the provider points at
+	srv.handle("GET /github/user", http.StatusUnauthorized, map[string]any{"message": "Bad credentials"})
+	stored := testConfiguredProvider()
+	stored["config"] = map[string]any{"token": "********", "organization": "arctir", "base_url": srv.server.URL + "/github"}
+	srv.handle("GET /api/v1/discovery/configured-providers/{id}", http.StatusOK, stored)
+
+	cmd := ProviderUpdateCommand{
+		EnvWrapperCommand:   EnvWrapperCommand{Config: cfg},
+		ProviderID:          testProviderID,
+		ProviderConfigFlags: ProviderConfigFlags{Token: "ghp_bad"},
+	}
+	_, err := captureOutput(t, cmd.Run)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "credentials were rejected")
+
+	check := srv.requireRequest(http.MethodGet, "/github/user")
+	assert.Equal(t, "Bearer ghp_bad", check.Header.Get("Authorization"))
+	assert.Empty(t, srv.received(http.MethodPut, "/api/v1/discovery/configured-providers/"+testProviderID))
+}
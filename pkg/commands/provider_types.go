@@ -0,0 +1,411 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+)
+
+// providerField describes one configuration value a discovery provider
+// needs, as declared by the provider type's config schema
+type providerField struct {
+	Name        string // key in the provider config
+	Title       string
+	Description string
+	Type        string // JSON schema type: string, integer, number, boolean, array, object
+	Format      string
+	Default     string
+	Enum        []string
+	Required    bool
+	Secret      bool
+	Env         string // environment variable used as a fallback
+}
+
+// providerSchema is the config schema of a discovery provider type
+type providerSchema struct {
+	Fields []providerField
+}
+
+// providerEnvFallbacks maps config keys of well-known provider types to the
+// environment variables conventionally holding them
+var providerEnvFallbacks = map[string]map[string]string{
+	"github": {"token": "GITHUB_TOKEN"},
+	"gitlab": {"token": "GITLAB_TOKEN"},
+}
+
+// providerCredentialChecks confirm credentials against the source system
+// before a provider is saved, for the types where that's a single request
+var providerCredentialChecks = map[string]func(client *http.Client, values map[string]string) error{
+	"github": func(client *http.Client, values map[string]string) error {
+		baseURL := values["base_url"]
+		if baseURL == "" {
+			baseURL = "https://api.github.com"
+		}
+		return checkProviderCredentials(client, strings.TrimSuffix(baseURL, "/")+"/user", "Authorization", "Bearer "+values["token"])
+	},
+	"gitlab": func(client *http.Client, values map[string]string) error {
+		baseURL := values["base_url"]
+		if baseURL == "" {
+			baseURL = "https://gitlab.com"
+		}
+		return checkProviderCredentials(client, strings.TrimSuffix(baseURL, "/")+"/api/v4/user", "PRIVATE-TOKEN", values["token"])
+	},
+}
+
+// jsonSchema is the subset of JSON schema used to describe provider config
+type jsonSchema struct {
+	Type        any                    `json:"type"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Format      string                 `json:"format"`
+	Default     any                    `json:"default"`
+	Enum        []any                  `json:"enum"`
+	WriteOnly   bool                   `json:"writeOnly"`
+	AnyOf       []jsonSchema           `json:"anyOf"`
+	Properties  map[string]*jsonSchema `json:"properties"`
+	Required    []string               `json:"required"`
+}
+
+// resolve returns the schema describing the value itself, unwrapping the
+// anyOf-with-null form used for optional fields
+func (s *jsonSchema) resolve() *jsonSchema {
+	for i := range s.AnyOf {
+		if t, _ := s.AnyOf[i].Type.(string); t != "null" {
+			resolved := s.AnyOf[i]
+			if resolved.Title == "" {
+				resolved.Title = s.Title
+			}
+			if resolved.Description == "" {
+				resolved.Description = s.Description
+			}
+			if resolved.Default == nil {
+				resolved.Default = s.Default
+			}
+			resolved.WriteOnly = resolved.WriteOnly || s.WriteOnly
+			return &resolved
+		}
+	}
+	return s
+}
+
+// parseProviderSchema builds the field list of a provider type from its JSON
+// schema. Required fields come first, then the rest by name.
+func parseProviderSchema(providerType string, raw []byte) (*providerSchema, error) {
+	var schema jsonSchema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse config schema: %w", err)
+	}
+
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	fields := make([]providerField, 0, len(schema.Properties))
+	for name, property := range schema.Properties {
+		p := property.resolve()
+		field := providerField{
+			Name:        name,
+			Title:       p.Title,
+			Description: p.Description,
+			Format:      p.Format,
+			Required:    required[name],
+			Secret:      p.WriteOnly || p.Format == "password",
+			Env:         providerEnvFallbacks[providerType][name],
+		}
+		field.Type, _ = p.Type.(string)
+		if p.Default != nil {
+			field.Default = schemaValueString(p.Default)
+		}
+		for _, v := range p.Enum {
+			field.Enum = append(field.Enum, schemaValueString(v))
+		}
+		fields = append(fields, field)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].Required != fields[j].Required {
+			return fields[i].Required
+		}
+		return fields[i].Name < fields[j].Name
+	})
+
+	return &providerSchema{Fields: fields}, nil
+}
+
+// schemaValueString renders a schema default or enum value the way a user
+// would type it
+func schemaValueString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = schemaValueString(item)
+		}
+		return strings.Join(parts, ",")
+	case map[string]any:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// field returns the schema field with the given name
+func (s *providerSchema) field(name string) (providerField, bool) {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return providerField{}, false
+}
+
+// prompt returns the label shown when asking for the field's value
+func (f providerField) prompt() string {
+	label := f.Title
+	if label == "" {
+		label = f.Name
+	}
+	if len(f.Enum) > 0 {
+		label += fmt.Sprintf(" (%s)", strings.Join(f.Enum, ", "))
+	}
+	if f.Default != "" {
+		label += fmt.Sprintf(" [%s]", f.Default)
+	}
+	return label
+}
+
+// validate checks a value against the field's type, format and enum
+func (f providerField) validate(value string) error {
+	if len(f.Enum) > 0 {
+		allowed := false
+		for _, v := range f.Enum {
+			allowed = allowed || v == value
+		}
+		if !allowed {
+			return fmt.Errorf("must be one of: %s", strings.Join(f.Enum, ", "))
+		}
+	}
+	if f.Format == "uri" {
+		if err := validateProviderURL(value); err != nil {
+			return err
+		}
+	}
+	_, err := f.encode(value)
+	return err
+}
+
+// encode converts a value typed on the command line to JSON of the
+// field's schema type
+func (f providerField) encode(value string) (json.RawMessage, error) {
+	var v any
+	switch f.Type {
+	case "integer":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer, got '%s'", value)
+		}
+		v = n
+	case "number":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number, got '%s'", value)
+		}
+		v = n
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("expected true or false, got '%s'", value)
+		}
+		v = b
+	case "array":
+		if strings.HasPrefix(strings.TrimSpace(value), "[") {
+			return rawJSON(value, "a JSON array")
+		}
+		items := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v = items
+	case "object":
+		return rawJSON(value, "a JSON object")
+	case "string":
+		v = value
+	default:
+		// Untyped fields keep the type of anything that parses as JSON
+		if raw, err := rawJSON(value, ""); err == nil {
+			return raw, nil
+		}
+		v = value
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(data), nil
+}
+
+func rawJSON(value, expected string) (json.RawMessage, error) {
+	if !json.Valid([]byte(value)) {
+		return nil, fmt.Errorf("expected %s", expected)
+	}
+	return json.RawMessage(value), nil
+}
+
+// encode converts config values to JSON using the schema's field types.
+// Keys the schema doesn't declare are passed through, typed as JSON when
+// they parse as JSON.
+func (s *providerSchema) encode(values map[string]string) (api.ConfiguredProviderCreateConfig, error) {
+	config := make(api.ConfiguredProviderCreateConfig, len(values))
+	for key, value := range values {
+		field, ok := s.field(key)
+		if !ok {
+			field = providerField{Name: key}
+		}
+		raw, err := field.encode(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for '%s': %w", key, err)
+		}
+		config[key] = []byte(raw)
+	}
+	return config, nil
+}
+
+// resolveProviderValues fills in field values from explicit values, the
+// environment and defaults, prompting for anything still missing when
+// prompt is non-nil. It returns an error for missing or invalid values.
+// Defaults are left to the server and not added to values.
+func resolveProviderValues(schema *providerSchema, values map[string]string, prompt func(providerField) (string, error)) error {
+	for _, field := range schema.Fields {
+		value := values[field.Name]
+		if value == "" && field.Env != "" {
+			value = os.Getenv(field.Env)
+		}
+		if value == "" && prompt != nil {
+			input, err := prompt(field)
+			if err != nil {
+				return err
+			}
+			value = input
+		}
+
+		if value == "" {
+			if field.Required && field.Default == "" {
+				return fmt.Errorf("missing required value '%s'", field.Name)
+			}
+			continue
+		}
+		if err := field.validate(value); err != nil {
+			return fmt.Errorf("invalid value for '%s': %w", field.Name, err)
+		}
+		values[field.Name] = value
+	}
+	return nil
+}
+
+// getProviderSchema fetches the config schema of a discovery provider type
+func getProviderSchema(client *api.Client, providerType string) (*providerSchema, error) {
+	resp, err := client.GetDiscoveryProviderConfigSchema(context.Background(), api.GetDiscoveryProviderConfigSchemaParams{
+		ProviderType: providerType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config schema for provider type '%s': %w", providerType, err)
+	}
+
+	switch r := resp.(type) {
+	case *api.GetDiscoveryProviderConfigSchemaOKApplicationJSON:
+		return parseProviderSchema(providerType, *r)
+	case *api.GetDiscoveryProviderConfigSchemaNotFound:
+		return nil, fmt.Errorf("unknown provider type '%s'", providerType)
+	case *api.HTTPValidationError:
+		return nil, fmt.Errorf("validation error: %v", r.Detail)
+	default:
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
+	}
+}
+
+// listProviderTypes returns the discovery provider types the server
+// supports, sorted by type
+func listProviderTypes(client *api.Client) ([]api.DiscoveryProviderMetadata, error) {
+	resp, err := client.ListDiscoveryProviders(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list provider types: %w", err)
+	}
+
+	var providers []api.DiscoveryProviderMetadata
+	switch r := resp.(type) {
+	case *api.DiscoveryProvidersListResponse:
+		providers = r.Providers
+	case *api.ListDiscoveryProvidersNotFound:
+	default:
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
+	}
+
+	sort.Slice(providers, func(i, j int) bool { return providers[i].Type < providers[j].Type })
+	return providers, nil
+}
+
+func validateProviderURL(value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "https" && parsed.Scheme != "http" || parsed.Host == "" {
+		return fmt.Errorf("expected an http(s) URL, got '%s'", value)
+	}
+	return nil
+}
+
+// readProviderFile returns the contents of a file given for a config value,
+// such as a kubeconfig, which the server can't read from the user's disk
+func readProviderFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("'%s' is a directory", path)
+	}
+	data, err := os.ReadFile(path) // #nosec G304 - user-specified config file
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// checkProviderCredentials makes an authenticated request to the source
+// system to confirm the credentials work before the provider is created
+func checkProviderCredentials(client *http.Client, endpoint, header, value string) error {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(endpoint, "/"), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set(header, value)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("credentials were rejected (HTTP %d)", resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return fmt.Errorf("unexpected response from %s (HTTP %d)", endpoint, resp.StatusCode)
+	}
+	return nil
+}
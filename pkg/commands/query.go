@@ -0,0 +1,405 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
+	"github.com/arctir/devgraph-cli/pkg/output"
+	"github.com/arctir/devgraph-cli/pkg/util"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+)
+
+// QueryCommand finds entities with a small query language, e.g.
+//
+//	kind=Service and label.team=payments related-to kind=Database
+//
+// A query is one or more clauses joined by related-to, optionally limited to
+// one relation type with related-to:DEPENDS_ON. Each clause is one or more
+// terms joined by and. A term compares a field to a value with = or !=,
+// where values may contain * wildcards. Fields are kind, name, namespace,
+// id, label.<key>, annotation.<key>, or a dotted path into the entity such
+// as spec.owner. The result is the entities matching the first clause that
+// are related, in either direction, to an entity matching the rest of the
+// query.
+type QueryCommand struct {
+	EnvWrapperCommand
+	Query   string `arg:"" help:"Query, e.g. 'kind=Service and label.team=payments related-to kind=Database'."`
+	Explain bool   `flag:"explain" help:"Show how the query is evaluated instead of running it."`
+}
+
+// queryTerm compares an entity field to a value
+type queryTerm struct {
+	Field string
+	Op    string
+	Value string
+}
+
+// queryClause is a set of terms that all have to match
+type queryClause struct {
+	Terms []queryTerm
+}
+
+// entityQuery is a parsed query. Clauses[i] is related to Clauses[i+1]
+// through a relation of type Relations[i], or any type when it's empty.
+type entityQuery struct {
+	Clauses   []queryClause
+	Relations []string
+}
+
+func (q *QueryCommand) Run() error {
+	format := q.OutputFormat(output.Table)
+	if err := output.Check(format, output.Table, output.JSON, output.YAML, output.Name); err != nil {
+		return err
+	}
+
+	query, err := parseEntityQuery(q.Query)
+	if err != nil {
+		return err
+	}
+
+	if q.Explain {
+		explainEntityQuery(query)
+		return nil
+	}
+
+	client, err := util.GetAuthenticatedClient(q.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	entities, err := runEntityQuery(context.Background(), client, query)
+	if err != nil {
+		return err
+	}
+
+	return displayEntityList(entities, format)
+}
+
+// parseEntityQuery parses a query like
+// "kind=Service and label.team=payments related-to kind=Database"
+func parseEntityQuery(input string) (entityQuery, error) {
+	words, err := splitQuery(input)
+	if err != nil {
+		return entityQuery{}, err
+	}
+	if len(words) == 0 {
+		return entityQuery{}, fmt.Errorf("query is empty")
+	}
+
+	var query entityQuery
+	clause := queryClause{}
+	expectTerm := true
+	for _, word := range words {
+		lower := strings.ToLower(word)
+		switch {
+		case lower == "and":
+			if expectTerm {
+				return entityQuery{}, fmt.Errorf("expected a term before %q", word)
+			}
+			expectTerm = true
+		case lower == "related-to" || strings.HasPrefix(lower, "related-to:"):
+			if expectTerm {
+				return entityQuery{}, fmt.Errorf("expected a term before %q", word)
+			}
+			_, relation, _ := strings.Cut(word, ":")
+			query.Clauses = append(query.Clauses, clause)
+			query.Relations = append(query.Relations, relation)
+			clause = queryClause{}
+			expectTerm = true
+		default:
+			if !expectTerm {
+				return entityQuery{}, fmt.Errorf("expected 'and' or 'related-to' before %q", word)
+			}
+			term, err := parseQueryTerm(word)
+			if err != nil {
+				return entityQuery{}, err
+			}
+			clause.Terms = append(clause.Terms, term)
+			expectTerm = false
+		}
+	}
+	if expectTerm {
+		return entityQuery{}, fmt.Errorf("query ends without a term")
+	}
+	query.Clauses = append(query.Clauses, clause)
+	return query, nil
+}
+
+// splitQuery splits a query into words at spaces outside of quotes,
+// removing the quotes
+func splitQuery(input string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	for _, r := range input {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in query")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+func parseQueryTerm(word string) (queryTerm, error) {
+	op := "="
+	field, value, found := strings.Cut(word, "!=")
+	if found {
+		op = "!="
+	} else if field, value, found = strings.Cut(word, "="); !found {
+		return queryTerm{}, fmt.Errorf("invalid term %q: expected <field>=<value> or <field>!=<value>", word)
+	}
+	if field == "" {
+		return queryTerm{}, fmt.Errorf("invalid term %q: missing field", word)
+	}
+	return queryTerm{Field: field, Op: op, Value: value}, nil
+}
+
+// serverParams returns the API filters that narrow the entities listed for
+// a clause. Terms the API can't express are only evaluated client side.
+func (c queryClause) serverParams() api.GetEntitiesParams {
+	params := api.GetEntitiesParams{}
+	var labels, fields []string
+	for _, term := range c.Terms {
+		if term.Op != "=" || strings.Contains(term.Value, "*") {
+			continue
+		}
+		switch {
+		case term.Field == "name":
+			params.Name = api.NewOptString(term.Value)
+		case strings.HasPrefix(term.Field, "label."):
+			labels = append(labels, strings.TrimPrefix(term.Field, "label.")+"="+term.Value)
+		case strings.HasPrefix(term.Field, "spec."):
+			fields = append(fields, term.Field+"="+term.Value)
+		}
+	}
+	if len(labels) > 0 {
+		params.Label = api.NewOptString(strings.Join(labels, ","))
+	}
+	if len(fields) > 0 {
+		params.FieldSelector = api.NewOptString(strings.Join(fields, ","))
+	}
+	return params
+}
+
+// matches reports whether entity satisfies every term of the clause
+func (c queryClause) matches(entity api.EntityResponse) bool {
+	for _, term := range c.Terms {
+		value, ok := entityField(entity, term.Field)
+		matched := ok && matchQueryValue(term.Field, term.Value, value)
+		if matched != (term.Op == "=") {
+			return false
+		}
+	}
+	return true
+}
+
+func matchQueryValue(field, pattern, value string) bool {
+	// Kinds are compared case-insensitively, so kind=service matches Service
+	if field == "kind" {
+		pattern, value = strings.ToLower(pattern), strings.ToLower(value)
+	}
+	if strings.Contains(pattern, "*") {
+		matched, err := path.Match(pattern, value)
+		return err == nil && matched
+	}
+	return pattern == value
+}
+
+// entityField returns the value of a query field for entity
+func entityField(entity api.EntityResponse, field string) (string, bool) {
+	switch field {
+	case "kind":
+		return entity.Kind, true
+	case "name":
+		return entity.Name, true
+	case "namespace":
+		return entity.Namespace, true
+	case "id":
+		return entity.ID, true
+	case "apiVersion":
+		return entity.ApiVersion, true
+	}
+
+	filtered := devgraph.NewEntity(entity)
+	var current any
+	var parts []string
+	switch {
+	case strings.HasPrefix(field, "label."):
+		current = filtered.Metadata
+		parts = []string{"labels", strings.TrimPrefix(field, "label.")}
+	case strings.HasPrefix(field, "annotation."):
+		current = filtered.Metadata
+		parts = []string{"annotations", strings.TrimPrefix(field, "annotation.")}
+	default:
+		current = map[string]any{
+			"metadata": filtered.Metadata,
+			"spec":     filtered.Spec,
+			"status":   filtered.Status,
+		}
+		parts = strings.Split(field, ".")
+	}
+
+	for _, part := range parts {
+		current = lookupField(current, part)
+		if current == nil {
+			return "", false
+		}
+	}
+	return fmt.Sprint(current), true
+}
+
+// lookupField returns the field called name of a map, whatever its value type
+func lookupField(value any, name string) any {
+	switch m := value.(type) {
+	case map[string]any:
+		return m[name]
+	case map[string]string:
+		if v, ok := m[name]; ok {
+			return v
+		}
+	case api.EntityMetadataLabels:
+		if v, ok := m[name]; ok {
+			return v
+		}
+	case api.EntityMetadataAnnotations:
+		if v, ok := m[name]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// runEntityQuery evaluates a query from its last clause back to its first,
+// keeping at each step the entities related to the previous step's matches
+func runEntityQuery(ctx context.Context, client *api.Client, query entityQuery) ([]api.EntityResponse, error) {
+	last := len(query.Clauses) - 1
+	params := query.Clauses[last].serverParams()
+	params.IncludeRelations = api.NewOptBool(false)
+	matched, _, err := queryEntities(ctx, client, params, query.Clauses[last])
+	if err != nil {
+		return nil, err
+	}
+
+	for i := last - 1; i >= 0; i-- {
+		ids := make(map[string]bool, len(matched))
+		for _, entity := range matched {
+			ids[entity.ID] = true
+		}
+
+		params := query.Clauses[i].serverParams()
+		params.IncludeRelations = api.NewOptBool(true)
+		candidates, relations, err := queryEntities(ctx, client, params, query.Clauses[i])
+		if err != nil {
+			return nil, err
+		}
+
+		related := make(map[string]bool)
+		for _, rel := range relations {
+			if query.Relations[i] != "" && !strings.EqualFold(rel.Relation, query.Relations[i]) {
+				continue
+			}
+			if ids[rel.Target.ID] {
+				related[rel.Source.ID] = true
+			}
+			if ids[rel.Source.ID] {
+				related[rel.Target.ID] = true
+			}
+		}
+
+		matched = matched[:0:0]
+		for _, entity := range candidates {
+			if related[entity.ID] {
+				matched = append(matched, entity)
+			}
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	return matched, nil
+}
+
+// queryEntities lists the entities matching params and clause, with their
+// relations when params includes them
+func queryEntities(ctx context.Context, client *api.Client, params api.GetEntitiesParams, clause queryClause) ([]api.EntityResponse, []api.EntityRelationResponse, error) {
+	all, relations, err := devgraph.ListAllEntities(ctx, client, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var entities []api.EntityResponse
+	for _, entity := range all {
+		if clause.matches(entity) {
+			entities = append(entities, entity)
+		}
+	}
+	return entities, relations, nil
+}
+
+// explainEntityQuery prints the API requests a query makes and the terms
+// evaluated on their results
+func explainEntityQuery(query entityQuery) {
+	for i := len(query.Clauses) - 1; i >= 0; i-- {
+		clause := query.Clauses[i]
+		step := len(query.Clauses) - i
+		params := clause.serverParams()
+
+		var filters []string
+		if name, ok := params.Name.Get(); ok {
+			filters = append(filters, "name="+name)
+		}
+		if label, ok := params.Label.Get(); ok {
+			filters = append(filters, "label="+label)
+		}
+		if fields, ok := params.FieldSelector.Get(); ok {
+			filters = append(filters, "field_selector="+fields)
+		}
+		if i < len(query.Clauses)-1 {
+			filters = append(filters, "include_relations=true")
+		}
+		if len(filters) == 0 {
+			filters = append(filters, "all entities")
+		}
+
+		terms := make([]string, 0, len(clause.Terms))
+		for _, term := range clause.Terms {
+			terms = append(terms, term.Field+term.Op+term.Value)
+		}
+
+		fmt.Printf("%d. List entities (%s)\n", step, strings.Join(filters, ", "))
+		fmt.Printf("   Keep those matching %s\n", strings.Join(terms, " and "))
+		if i < len(query.Clauses)-1 {
+			relation := "any relation"
+			if query.Relations[i] != "" {
+				relation = "a " + query.Relations[i] + " relation"
+			}
+			fmt.Printf("   Keep those with %s to a result of step %d\n", relation, step-1)
+		}
+	}
+}
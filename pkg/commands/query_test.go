@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEntityQuery(t *testing.T) {
+	query, err := parseEntityQuery(`kind=Service and label.team=payments related-to:DEPENDS_ON kind=Database and name!="legacy db"`)
+	require.NoError(t, err)
+	assert.Equal(t, entityQuery{
+		Clauses: []queryClause{
+			{Terms: []queryTerm{{Field: "kind", Op: "=", Value: "Service"}, {Field: "label.team", Op: "=", Value: "payments"}}},
+			{Terms: []queryTerm{{Field: "kind", Op: "=", Value: "Database"}, {Field: "name", Op: "!=", Value: "legacy db"}}},
+		},
+		Relations: []string{"DEPENDS_ON"},
+	}, query)
+
+	for input, message := range map[string]string{
+		"":                        "query is empty",
+		"and kind=Service":        "expected a term",
+		"kind=Service related-to": "query ends without a term",
+		"kind=Service name=api":   "expected 'and' or 'related-to'",
+		"kind":                    "invalid term",
+		"=Service":                "missing field",
+		`name="api`:               "unterminated quote",
+	} {
+		_, err := parseEntityQuery(input)
+		assert.ErrorContains(t, err, message, input)
+	}
+}
+
+func TestQueryClause_Matches(t *testing.T) {
+	entity := testEntity("payments-api")
+	entity["metadata"] = map[string]any{
+		"name":        "payments-api",
+		"namespace":   "default",
+		"labels":      map[string]any{"team": "payments"},
+		"annotations": map[string]any{"owner": "alice"},
+	}
+	entity["spec"] = map[string]any{"lifecycle": "production", "tier": 1}
+	var response api.EntityResponse
+	data, err := json.Marshal(entity)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &response))
+
+	for query, want := range map[string]bool{
+		"kind=service":                              true,
+		"name=payments-*":                           true,
+		"name!=payments-*":                          false,
+		"label.team=payments":                       true,
+		"label.team=billing":                        false,
+		"label.missing!=x":                          true,
+		"annotation.owner=alice":                    true,
+		"spec.lifecycle=production and spec.tier=1": true,
+		"namespace=other":                           false,
+	} {
+		parsed, err := parseEntityQuery(query)
+		require.NoError(t, err)
+		assert.Equal(t, want, parsed.Clauses[0].matches(response), query)
+	}
+}
+
+func TestQueryCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	db := testEntity("orders-db")
+	db["kind"] = "Database"
+	db["id"] = "core/v1/database/default/orders-db"
+	payments := testEntity("payments-api")
+	payments["metadata"] = map[string]any{"name": "payments-api", "namespace": "default", "labels": map[string]any{"team": "payments"}}
+	billing := testEntity("billing-api")
+	billing["metadata"] = map[string]any{"name": "billing-api", "namespace": "default", "labels": map[string]any{"team": "payments"}}
+	dependsOnDB := testRelation("DEPENDS_ON", "payments-api", "orders-db")
+	dependsOnDB["target"] = map[string]any{"apiVersion": "core/v1", "kind": "Database", "name": "orders-db", "id": "core/v1/database/default/orders-db"}
+
+	var queries []url.Values
+	srv.mux.HandleFunc("GET /api/v1/entities/", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		queries = append(queries, query)
+		w.Header().Set("Content-Type", "application/json")
+		if query.Get("include_relations") == "true" {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"primary_entities": []map[string]any{payments, billing, db},
+				"relations":        []map[string]any{dependsOnDB},
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"primary_entities": []map[string]any{payments, billing, db},
+		})
+	})
+
+	cfg.Output = "name"
+	cmd := QueryCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Query:             "kind=Service and label.team=payments related-to kind=Database",
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Equal(t, "core/v1/service/default/payments-api\n", output)
+
+	require.Len(t, queries, 2)
+	assert.Equal(t, "false", queries[0].Get("include_relations"))
+	assert.Empty(t, queries[0].Get("label"))
+	assert.Equal(t, "true", queries[1].Get("include_relations"))
+	assert.Equal(t, "team=payments", queries[1].Get("label"))
+	assert.Equal(t, "0", queries[1].Get("offset"))
+
+	// Only relations of the given type count
+	queries = nil
+	cmd.Query = "kind=Service related-to:OWNS kind=Database"
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Empty(t, output)
+}
+
+func TestQueryCommand_Explain(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+
+	cmd := QueryCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Query:             "kind=Service and label.team=payments related-to name=orders-db",
+		Explain:           true,
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "1. List entities (name=orders-db)")
+	assert.Contains(t, output, "2. List entities (label=team=payments, include_relations=true)")
+	assert.Contains(t, output, "Keep those with any relation to a result of step 1")
+	assert.Empty(t, srv.received(http.MethodGet, "/api/v1/entities/"))
+}
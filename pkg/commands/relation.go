@@ -1,12 +1,22 @@
 package commands
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
+	"github.com/arctir/devgraph-cli/pkg/logging"
+	"github.com/arctir/devgraph-cli/pkg/output"
 	"github.com/arctir/devgraph-cli/pkg/util"
 	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
 	"github.com/fatih/color"
@@ -18,26 +28,60 @@ type RelationCommand struct {
 	Create RelationCreateCommand `cmd:"create" help:"Create a new relation between entities."`
 	List   RelationListCommand   `cmd:"" help:"List entity relations."`
 	Delete RelationDeleteCommand `cmd:"delete" help:"Delete a relation between entities."`
+	Apply  RelationApplyCommand  `cmd:"apply" help:"Create relations listed in a CSV or YAML file."`
+	Graph  RelationGraphCommand  `cmd:"graph" help:"Export the relation graph as DOT, Mermaid, or GraphML."`
+	Path   RelationPathCommand   `cmd:"path" help:"Find the shortest relation paths between two entities."`
+	Types  RelationTypesCommand  `cmd:"types" help:"List the relation types in use."`
+	Update RelationUpdateCommand `cmd:"update" help:"Change a relation's type, labels, or annotations."`
 }
 
 // RelationCreateCommand creates a new relation between two entities
 type RelationCreateCommand struct {
 	EnvWrapperCommand
-	Relation  string `arg:"" required:"" help:"Type of relation (e.g., DEPENDS_ON, USES, OWNS)."`
-	Source    string `arg:"" required:"" help:"Source entity ID in format: <group>/<version>/<plural>/<namespace>/<name>"`
-	Target    string `arg:"" required:"" help:"Target entity ID in format: <group>/<version>/<plural>/<namespace>/<name>"`
-	Namespace string `flag:"namespace,n" help:"Namespace for the relation (optional)."`
+	Relation     string `arg:"" required:"" help:"Type of relation (e.g., DEPENDS_ON, USES, OWNS)."`
+	Source       string `arg:"" required:"" help:"Source entity ID in format: <group>/<version>/<plural>/<namespace>/<name>"`
+	Target       string `arg:"" required:"" help:"Target entity ID in format: <group>/<version>/<plural>/<namespace>/<name>"`
+	Namespace    string `flag:"namespace,n" help:"Namespace for the relation (optional)."`
+	AllowUnknown bool   `flag:"allow-unknown" help:"Create the relation even if its type isn't used by any other relation in the namespace."`
+}
+
+// RelationUpdateCommand changes a relation in place. The API has no update
+// operation, so the relation is deleted and recreated.
+type RelationUpdateCommand struct {
+	EnvWrapperCommand
+	Relation   string   `arg:"" required:"" help:"Current type of the relation."`
+	Source     string   `arg:"" required:"" help:"Source entity ID in format: <group>/<version>/<plural>/<namespace>/<name>"`
+	Target     string   `arg:"" required:"" help:"Target entity ID in format: <group>/<version>/<plural>/<namespace>/<name>"`
+	Namespace  string   `flag:"namespace,n" help:"Namespace of the relation (defaults to the source entity's)."`
+	Type       string   `flag:"type" help:"New relation type."`
+	Label      []string `flag:"label" help:"Label to set as key=value, or key- to remove it (can be specified multiple times)."`
+	Annotation []string `flag:"annotation" help:"Annotation to set as key=value, or key- to remove it (can be specified multiple times)."`
+}
+
+// RelationTypesCommand lists the relation types in use and how many
+// relations have each
+type RelationTypesCommand struct {
+	EnvWrapperCommand
+	Namespace string `flag:"namespace,n" help:"Only count relations in this namespace."`
 }
 
-// RelationListCommand lists entity relations with optional filtering
+// RelationListCommand lists entity relations with optional filtering. When
+// the namespace is known, relations are queried directly and filtered by the
+// server; otherwise they are read alongside entities.
 type RelationListCommand struct {
 	EnvWrapperCommand
-	Source string `flag:"source,s" help:"Filter by source entity ID."`
-	Target string `flag:"target,t" help:"Filter by target entity ID."`
-	Label  string `flag:"label,l" help:"Filter relations by label selector."`
-	Limit  int    `flag:"limit" default:"1000" help:"Maximum number of relations to return."`
-	Offset int    `flag:"offset" default:"0" help:"Offset for pagination."`
-	Output string `flag:"output,o" default:"table" help:"Output format: table, json, yaml."`
+	Source     string        `flag:"source,s" help:"Filter by source entity ID."`
+	Target     string        `flag:"target,t" help:"Filter by target entity ID."`
+	Type       string        `flag:"type" help:"Filter by relation type (e.g., DEPENDS_ON)."`
+	Namespace  string        `flag:"namespace,n" help:"Namespace to list relations in (defaults to the namespace of --source or --target)."`
+	ManagedBy  string        `flag:"managed-by" help:"Filter by managed-by label (requires a namespace)."`
+	SourceType string        `flag:"source-type" help:"Filter by source-type label (requires a namespace)."`
+	Label      string        `flag:"label,l" help:"Only include relations between entities matching this label selector."`
+	Limit      int           `flag:"limit" default:"1000" help:"Maximum number of relations to return."`
+	Offset     int           `flag:"offset" default:"0" help:"Number of matching relations to skip."`
+	All        bool          `flag:"all" help:"Return every matching relation, ignoring --limit."`
+	Watch      bool          `flag:"watch,w" help:"Keep polling and print relations as they are created or deleted."`
+	Interval   time.Duration `flag:"interval" default:"5s" help:"How often to poll when watching."`
 }
 
 // RelationDeleteCommand deletes a relation between two entities
@@ -49,6 +93,15 @@ type RelationDeleteCommand struct {
 	Namespace string `flag:"namespace,n" help:"Namespace for the relation (optional)."`
 }
 
+// RelationApplyCommand creates the relations listed in a file. CSV files have
+// source,target,relation rows; YAML files hold a list of relations in the
+// format written by entity backup.
+type RelationApplyCommand struct {
+	EnvWrapperCommand
+	File    string `arg:"" required:"" help:"Path to a .csv, .yaml, .yml, or .json file of relations, or - to read YAML or JSON from stdin."`
+	Workers int    `flag:"workers,w" help:"Number of concurrent workers (defaults to the default_workers setting, or 10)."`
+}
+
 // parseEntityReference converts an entity ID string to an EntityReference
 func parseEntityReference(entityID string) (api.EntityReference, error) {
 	group, version, plural, namespace, name, err := parseEntityID(entityID)
@@ -107,6 +160,12 @@ func (r *RelationCreateCommand) Run() error {
 		relation.Namespace = api.NewOptString(namespace)
 	}
 
+	if !r.AllowUnknown && namespace != "" {
+		if err := validateRelationType(client, namespace, r.Relation); err != nil {
+			return err
+		}
+	}
+
 	params := api.CreateEntityRelationParams{
 		Namespace: namespace,
 	}
@@ -139,60 +198,226 @@ func (r *RelationCreateCommand) Run() error {
 	}
 }
 
+// relationNamespace returns the namespace to query relations in: the
+// --namespace flag, or else the namespace of the source or target filter
+func (r *RelationListCommand) relationNamespace() (string, error) {
+	if r.Namespace != "" {
+		return r.Namespace, nil
+	}
+	for _, id := range []string{r.Source, r.Target} {
+		if id == "" {
+			continue
+		}
+		_, _, _, namespace, _, err := parseEntityID(id)
+		if err != nil {
+			return "", err
+		}
+		return namespace, nil
+	}
+	return "", nil
+}
+
 // Run executes the list relations command
 func (r *RelationListCommand) Run() error {
+	format := r.OutputFormat(output.Table)
+	if err := output.Check(format, output.Table, output.JSON, output.YAML); err != nil {
+		return err
+	}
+	if r.Offset < 0 || r.Limit < 0 {
+		return fmt.Errorf("--offset and --limit must not be negative")
+	}
+	if r.Watch {
+		if format != output.Table {
+			return fmt.Errorf("--watch only supports table output")
+		}
+		if r.Interval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+	}
+
 	client, err := util.GetAuthenticatedClient(r.Config)
 	if err != nil {
 		return fmt.Errorf("failed to create authenticated client: %w", err)
 	}
 
-	// Build the parameters for the API call
-	// Relations are retrieved through the GetEntities endpoint
-	params := api.GetEntitiesParams{}
+	filteredRelations, err := r.fetchRelations(client)
+	if err != nil {
+		return err
+	}
 
-	// Set optional filters if provided
-	if r.Label != "" {
-		params.Label = api.NewOptString(r.Label)
+	if r.Watch {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		return watchRelations(ctx, filteredRelations, r.Interval, func() ([]api.EntityRelationResponse, error) {
+			return r.fetchRelations(client)
+		})
 	}
-	if r.Limit > 0 {
-		params.Limit = api.NewOptInt(r.Limit)
+
+	if len(filteredRelations) == 0 {
+		if r.Source != "" || r.Target != "" {
+			fmt.Println("No relations found matching the specified filters.")
+		} else {
+			fmt.Println("No relations found.")
+		}
+		return nil
 	}
-	if r.Offset > 0 {
-		params.Offset = api.NewOptInt(r.Offset)
+
+	total := len(filteredRelations)
+	start := min(r.Offset, total)
+	end := total
+	if !r.All && r.Limit > 0 {
+		end = min(start+r.Limit, total)
 	}
+	return displayRelationList(filteredRelations[start:end], format, start, total)
+}
 
-	// If source or target is specified, we need to use field selectors
-	// However, for now we'll retrieve all relations and filter client-side
-	// TODO: Add server-side filtering when API supports it
+// fetchRelations returns every relation matching the command's filters
+func (r *RelationListCommand) fetchRelations(client *api.Client) ([]api.EntityRelationResponse, error) {
+	namespace, err := r.relationNamespace()
+	if err != nil {
+		return nil, err
+	}
 
-	resp, err := client.GetEntities(context.Background(), params)
+	var relations []api.EntityRelationResponse
+	if namespace != "" {
+		relations, err = r.queryRelations(client, namespace)
+	} else {
+		if r.ManagedBy != "" || r.SourceType != "" {
+			return nil, fmt.Errorf("--managed-by and --source-type require --namespace")
+		}
+		relations, err = r.entityRelations(client)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to list relations: %w", err)
+		return nil, err
 	}
 
-	// Handle the response
-	switch result := resp.(type) {
-	case *api.EntityResultSetResponse:
-		relations := result.Relations
-		if len(relations) == 0 {
-			fmt.Println("No relations found.")
+	// The relations API doesn't filter by entity, so that's done here
+	return filterRelations(relations, r.Source, r.Target), nil
+}
+
+// watchRelations prints the current relations, then polls fetch every
+// interval and prints relations that were created (+) or deleted (-) since
+// the previous poll. It returns when ctx is done.
+func watchRelations(ctx context.Context, initial []api.EntityRelationResponse, interval time.Duration, fetch func() ([]api.EntityRelationResponse, error)) error {
+	current := make(map[FilteredEntityRelation]bool, len(initial))
+	filtered := make([]FilteredEntityRelation, len(initial))
+	for i, rel := range initial {
+		filtered[i] = devgraph.NewEntityRelation(rel)
+		current[filtered[i]] = true
+	}
+	if err := displayRelationTable(filtered, 0, len(filtered)); err != nil {
+		return err
+	}
+	fmt.Printf("\nWatching for changes every %s (Ctrl+C to stop)...\n", interval)
+
+	addedColor := color.New(color.FgGreen)
+	deletedColor := color.New(color.FgRed)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
 			return nil
+		case <-ticker.C:
 		}
 
-		// Filter relations if source or target is specified
-		filteredRelations := filterRelations(relations, r.Source, r.Target)
-		if len(filteredRelations) == 0 {
-			fmt.Println("No relations found matching the specified filters.")
-			return nil
+		relations, err := fetch()
+		if err != nil {
+			logging.Warn("failed to refresh relations", "error", err)
+			continue
 		}
 
-		return displayRelationList(filteredRelations, r.Output)
-	case *api.GetEntitiesNotFound:
-		fmt.Println("No relations found.")
-		return nil
+		next := make(map[FilteredEntityRelation]bool, len(relations))
+		var added, deleted []FilteredEntityRelation
+		for _, rel := range relations {
+			f := devgraph.NewEntityRelation(rel)
+			next[f] = true
+			if !current[f] {
+				added = append(added, f)
+			}
+		}
+		for f := range current {
+			if !next[f] {
+				deleted = append(deleted, f)
+			}
+		}
+		sortRelations(deleted)
+
+		timestamp := time.Now().Format("15:04:05")
+		for _, f := range added {
+			fmt.Print(addedColor.Sprintf("%s + %-20s %s -> %s\n", timestamp, f.Relation, f.Source, f.Target))
+		}
+		for _, f := range deleted {
+			fmt.Print(deletedColor.Sprintf("%s - %-20s %s -> %s\n", timestamp, f.Relation, f.Source, f.Target))
+		}
+		current = next
+	}
+}
+
+// sortRelations orders relations by type, source and target
+func sortRelations(relations []FilteredEntityRelation) {
+	sort.Slice(relations, func(i, j int) bool {
+		a, b := relations[i], relations[j]
+		if a.Relation != b.Relation {
+			return a.Relation < b.Relation
+		}
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.Target < b.Target
+	})
+}
+
+func (r *RelationListCommand) queryRelations(client *api.Client, namespace string) ([]api.EntityRelationResponse, error) {
+	params := api.ListEntityRelationsParams{Namespace: namespace}
+	if r.Type != "" {
+		params.RelationType = api.NewOptNilString(r.Type)
+	}
+	if r.ManagedBy != "" {
+		params.ManagedBy = api.NewOptNilString(r.ManagedBy)
+	}
+	if r.SourceType != "" {
+		params.SourceType = api.NewOptNilString(r.SourceType)
+	}
+
+	resp, err := client.ListEntityRelations(context.Background(), params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relations: %w", err)
+	}
+
+	switch result := resp.(type) {
+	case *api.ListEntityRelationsOKApplicationJSON:
+		return []api.EntityRelationResponse(*result), nil
+	case *api.ListEntityRelationsNotFound:
+		return nil, nil
+	case *api.HTTPValidationError:
+		return nil, fmt.Errorf("validation error: %v", result.Detail)
 	default:
-		return fmt.Errorf("unexpected response type: %T", resp)
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
+	}
+}
+
+// entityRelations reads relations across all namespaces from the relations
+// included with the entity listing
+func (r *RelationListCommand) entityRelations(client *api.Client) ([]api.EntityRelationResponse, error) {
+	params := api.GetEntitiesParams{IncludeRelations: api.NewOptBool(true)}
+	if r.Label != "" {
+		params.Label = api.NewOptString(r.Label)
+	}
+
+	_, all, err := devgraph.ListAllEntities(context.Background(), client, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relations: %w", err)
+	}
+
+	var relations []api.EntityRelationResponse
+	for _, rel := range all {
+		if r.Type == "" || rel.Relation == r.Type {
+			relations = append(relations, rel)
+		}
 	}
+	return relations, nil
 }
 
 // Run executes the delete relation command
@@ -263,13 +488,16 @@ func filterRelations(relations []api.EntityRelationResponse, sourceFilter, targe
 		return relations
 	}
 
+	sourceFilter = strings.TrimPrefix(sourceFilter, "entity://")
+	targetFilter = strings.TrimPrefix(targetFilter, "entity://")
+
 	var filtered []api.EntityRelationResponse
 	for _, rel := range relations {
 		matches := true
-		if sourceFilter != "" && rel.Source.ID != sourceFilter {
+		if sourceFilter != "" && strings.TrimPrefix(rel.Source.ID, "entity://") != sourceFilter {
 			matches = false
 		}
-		if targetFilter != "" && rel.Target.ID != targetFilter {
+		if targetFilter != "" && strings.TrimPrefix(rel.Target.ID, "entity://") != targetFilter {
 			matches = false
 		}
 		if matches {
@@ -279,31 +507,33 @@ func filterRelations(relations []api.EntityRelationResponse, sourceFilter, targe
 	return filtered
 }
 
-// displayRelationList displays a list of relations in the specified format
-func displayRelationList(relations []api.EntityRelationResponse, outputFormat string) error {
+// displayRelationList displays a page of relations in the specified format.
+// offset and total place the page within all matching relations.
+func displayRelationList(relations []api.EntityRelationResponse, outputFormat string, offset, total int) error {
 	// Filter relations to only show required fields
 	filtered := make([]FilteredEntityRelation, len(relations))
 	for i, rel := range relations {
-		filtered[i] = filterEntityRelation(rel)
+		filtered[i] = devgraph.NewEntityRelation(rel)
 	}
 
 	switch outputFormat {
-	case "json":
+	case output.JSON:
 		encoder := json.NewEncoder(os.Stdout)
 		encoder.SetIndent("", "  ")
 		return encoder.Encode(filtered)
-	case "yaml":
+	case output.YAML, "yml":
 		encoder := yaml.NewEncoder(os.Stdout)
 		encoder.SetIndent(2)
 		return encoder.Encode(filtered)
+	case output.Table, "":
+		return displayRelationTable(filtered, offset, total)
 	default:
-		// Table format
-		return displayRelationTable(filtered)
+		return output.Print(outputFormat, output.Result{Data: filtered})
 	}
 }
 
 // displayRelationTable displays relations in a formatted table
-func displayRelationTable(relations []FilteredEntityRelation) error {
+func displayRelationTable(relations []FilteredEntityRelation, offset, total int) error {
 	if len(relations) == 0 {
 		fmt.Println("No relations found.")
 		return nil
@@ -327,7 +557,11 @@ func displayRelationTable(relations []FilteredEntityRelation) error {
 		fmt.Printf(" %-50s %-50s %s\n", truncate(rel.Source, 50), truncate(rel.Target, 50), namespace)
 	}
 
-	fmt.Printf("\nTotal: %d relations\n", len(relations))
+	if len(relations) < total {
+		fmt.Printf("\nShowing %d-%d of %d relations\n", offset+1, offset+len(relations), total)
+	} else {
+		fmt.Printf("\nTotal: %d relations\n", total)
+	}
 	return nil
 }
 
@@ -338,3 +572,474 @@ func truncate(s string, maxLen int) string {
 	}
 	return s[:maxLen-3] + "..."
 }
+
+// parseRelationsFile reads relations from CSV or YAML/JSON, chosen by the
+// file extension
+func parseRelationsFile(path string, data []byte) ([]FilteredEntityRelation, error) {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return parseRelationsCSV(data)
+	}
+
+	var relations []FilteredEntityRelation
+	if err := yaml.Unmarshal(data, &relations); err != nil {
+		return nil, fmt.Errorf("failed to parse relations: %w", err)
+	}
+	for i, rel := range relations {
+		if rel.Source == "" || rel.Target == "" || rel.Relation == "" {
+			return nil, fmt.Errorf("relation %d: source, target, and relation are required", i+1)
+		}
+	}
+	return relations, nil
+}
+
+// parseRelationsCSV reads source,target,relation rows. A header row is
+// skipped if present.
+func parseRelationsCSV(data []byte) ([]FilteredEntityRelation, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV: %w", err)
+	}
+
+	var relations []FilteredEntityRelation
+	for i, record := range records {
+		if i == 0 && strings.EqualFold(record[0], "source") {
+			continue
+		}
+		if len(record) != 3 {
+			return nil, fmt.Errorf("line %d: expected source,target,relation", i+1)
+		}
+		rel := FilteredEntityRelation{
+			Source:   strings.TrimSpace(record[0]),
+			Target:   strings.TrimSpace(record[1]),
+			Relation: strings.TrimSpace(record[2]),
+		}
+		if rel.Source == "" || rel.Target == "" || rel.Relation == "" {
+			return nil, fmt.Errorf("line %d: source, target, and relation are required", i+1)
+		}
+		relations = append(relations, rel)
+	}
+	return relations, nil
+}
+
+// newEntityRelation builds a create request for rel along with the namespace
+// to create it in, which is the source entity's (relations don't cross
+// namespaces) unless rel names one
+func newEntityRelation(rel FilteredEntityRelation) (*api.EntityRelation, string, error) {
+	source, err := parseEntityReference(rel.Source)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid source entity ID: %w", err)
+	}
+	target, err := parseEntityReference(rel.Target)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid target entity ID: %w", err)
+	}
+
+	namespace := rel.Namespace
+	if namespace == "" {
+		namespace, _ = source.Namespace.Get()
+	}
+
+	return &api.EntityRelation{
+		Relation:  rel.Relation,
+		Source:    source,
+		Target:    target,
+		Namespace: api.NewOptString(namespace),
+	}, namespace, nil
+}
+
+// Run executes the apply relations command
+func (r *RelationApplyCommand) Run() error {
+	data, err := util.ReadFileOrStdin(r.File)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", r.File, err)
+	}
+	relations, err := parseRelationsFile(r.File, data)
+	if err != nil {
+		return err
+	}
+	for i, rel := range relations {
+		if _, _, err := newEntityRelation(rel); err != nil {
+			return fmt.Errorf("relation %d: %w", i+1, err)
+		}
+	}
+
+	if r.DryRun {
+		fmt.Printf("Dry run: Would create %d relations:\n", len(relations))
+		for _, rel := range relations {
+			fmt.Printf("  Relation: %s -> %s (%s)\n", rel.Source, rel.Target, rel.Relation)
+		}
+		return nil
+	}
+
+	client, err := util.GetAuthenticatedClient(r.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	workers := r.Config.WorkerCount(r.Workers)
+
+	type relResult struct {
+		row       int
+		rel       FilteredEntityRelation
+		cancelled bool
+		err       error
+	}
+
+	rows := make(chan int, len(relations))
+	results := make([]relResult, len(relations))
+
+	// Ctrl+C lets requests in progress finish but starts no more
+	ctx, stop := interruptContext()
+	defer stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range rows {
+				rel := relations[row]
+				if ctx.Err() != nil {
+					results[row] = relResult{row: row + 1, rel: rel, cancelled: true}
+					continue
+				}
+				results[row] = relResult{row: row + 1, rel: rel, err: createRelation(client, rel)}
+			}
+		}()
+	}
+	for row := range relations {
+		rows <- row
+	}
+	close(rows)
+	wg.Wait()
+
+	failed, cancelled := 0, 0
+	for _, result := range results {
+		if result.cancelled {
+			cancelled++
+		} else if result.err != nil {
+			fmt.Printf("✗ Row %d: %s -> %s (%s): %v\n", result.row, result.rel.Source, result.rel.Target, result.rel.Relation, result.err)
+			failed++
+		} else {
+			fmt.Printf("✅ Row %d: %s -> %s (%s)\n", result.row, result.rel.Source, result.rel.Target, result.rel.Relation)
+		}
+	}
+
+	if cancelled > 0 {
+		fmt.Printf("\nApply interrupted: %d succeeded, %d failed, %d cancelled\n", len(relations)-failed-cancelled, failed, cancelled)
+		return fmt.Errorf("interrupted: %d relations were not created", failed+cancelled)
+	}
+	fmt.Printf("\nApply complete: %d succeeded, %d failed\n", len(relations)-failed, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d relations failed to create", failed)
+	}
+	return nil
+}
+
+// createRelation creates a single relation
+func createRelation(client *api.Client, rel FilteredEntityRelation) error {
+	relation, namespace, err := newEntityRelation(rel)
+	if err != nil {
+		return err
+	}
+	return createRelationRequest(context.Background(), client, relation, namespace)
+}
+
+// relationTypeCount is the number of relations of one type
+type relationTypeCount struct {
+	Type  string `json:"type" yaml:"type"`
+	Count int    `json:"count" yaml:"count"`
+}
+
+// countRelationTypes returns the relation types used in relations, sorted
+func countRelationTypes(relations []FilteredEntityRelation) []relationTypeCount {
+	counts := make(map[string]int)
+	for _, rel := range relations {
+		counts[rel.Relation]++
+	}
+
+	types := make([]relationTypeCount, 0, len(counts))
+	for relationType, count := range counts {
+		types = append(types, relationTypeCount{Type: relationType, Count: count})
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Type < types[j].Type })
+	return types
+}
+
+// validateRelationType checks that relationType is already used in the
+// namespace, to catch typos like DEPENDSON. Namespaces without any relations
+// accept any type.
+func validateRelationType(client *api.Client, namespace, relationType string) error {
+	relations, err := fetchRelationGraph(context.Background(), client, namespace, "")
+	if err != nil {
+		return fmt.Errorf("failed to check relation type (use --allow-unknown to skip): %w", err)
+	}
+
+	types := countRelationTypes(relations)
+	if len(types) == 0 {
+		return nil
+	}
+	known := make([]string, len(types))
+	for i, t := range types {
+		if t.Type == relationType {
+			return nil
+		}
+		known[i] = t.Type
+	}
+	return fmt.Errorf("unknown relation type '%s' in namespace '%s' (known types: %s); use --allow-unknown to create it anyway",
+		relationType, namespace, strings.Join(known, ", "))
+}
+
+// Run executes the relation types command
+func (r *RelationTypesCommand) Run() error {
+	format := r.OutputFormat(output.Table)
+	if err := output.Check(format, output.Table, output.JSON, output.YAML, output.Name); err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(r.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	relations, err := fetchRelationGraph(context.Background(), client, r.Namespace, "")
+	if err != nil {
+		return err
+	}
+
+	types := countRelationTypes(relations)
+	tableData := make([]map[string]any, len(types))
+	names := make([]string, len(types))
+	for i, t := range types {
+		tableData[i] = map[string]any{
+			"Type":      t.Type,
+			"Relations": t.Count,
+		}
+		names[i] = t.Type
+	}
+	return output.Print(format, output.Result{
+		Data:    types,
+		Headers: []string{"Type", "Relations"},
+		Rows:    tableData,
+		Empty:   "No relations found.",
+		Names:   names,
+	})
+}
+
+// applyMetadataChanges applies key=value (set) and key- (remove) changes to
+// a label or annotation map
+func applyMetadataChanges(values map[string]string, changes []string) (map[string]string, error) {
+	result := make(map[string]string, len(values))
+	for k, v := range values {
+		result[k] = v
+	}
+	for _, change := range changes {
+		if key, ok := strings.CutSuffix(change, "-"); ok && !strings.Contains(change, "=") {
+			delete(result, key)
+			continue
+		}
+		key, value, ok := strings.Cut(change, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid change '%s', expected 'key=value' or 'key-'", change)
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// findRelation returns the relation of the given type between source and
+// target in a namespace, or nil if there isn't one
+func findRelation(ctx context.Context, client *api.Client, namespace, relationType, source, target string) (*api.EntityRelationResponse, error) {
+	resp, err := client.ListEntityRelations(ctx, api.ListEntityRelationsParams{
+		Namespace:    namespace,
+		RelationType: api.NewOptNilString(relationType),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relations: %w", err)
+	}
+
+	var relations []api.EntityRelationResponse
+	switch r := resp.(type) {
+	case *api.ListEntityRelationsOKApplicationJSON:
+		relations = *r
+	case *api.ListEntityRelationsNotFound:
+	case *api.HTTPValidationError:
+		return nil, fmt.Errorf("validation error: %v", r.Detail)
+	default:
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
+	}
+
+	matches := filterRelations(relations, source, target)
+	for i := range matches {
+		if matches[i].Relation == relationType {
+			return &matches[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Run executes the update relation command
+func (r *RelationUpdateCommand) Run() error {
+	if r.Type == "" && len(r.Label) == 0 && len(r.Annotation) == 0 {
+		return fmt.Errorf("nothing to update: specify at least one of --type, --label, or --annotation")
+	}
+
+	current, namespace, err := newEntityRelation(FilteredEntityRelation{
+		Namespace: r.Namespace,
+		Relation:  r.Relation,
+		Source:    r.Source,
+		Target:    r.Target,
+	})
+	if err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(r.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	ctx := context.Background()
+	existing, err := findRelation(ctx, client, namespace, r.Relation, r.Source, r.Target)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("relation not found")
+	}
+
+	// Keep everything the update doesn't change
+	updated := *current
+	if r.Type != "" {
+		updated.Relation = r.Type
+	}
+	metadata := existing.Metadata.Value
+	labels, err := applyMetadataChanges(metadata.Labels.Value, r.Label)
+	if err != nil {
+		return fmt.Errorf("invalid --label: %w", err)
+	}
+	annotations, err := applyMetadataChanges(metadata.Annotations.Value, r.Annotation)
+	if err != nil {
+		return fmt.Errorf("invalid --annotation: %w", err)
+	}
+	if len(labels) > 0 || len(annotations) > 0 {
+		updated.Metadata = api.NewOptRelationMetadata(api.RelationMetadata{
+			Labels:      api.NewOptRelationMetadataLabels(labels),
+			Annotations: api.NewOptRelationMetadataAnnotations(annotations),
+		})
+	}
+	if spec, ok := existing.Spec.Get(); ok {
+		updated.Spec = api.NewOptEntityRelationSpec(api.EntityRelationSpec(spec))
+	}
+
+	if err := deleteRelation(ctx, client, current, namespace); err != nil {
+		return fmt.Errorf("failed to delete relation: %w", err)
+	}
+	if err := createRelationRequest(ctx, client, &updated, namespace); err != nil {
+		// Put the original back so a failed update doesn't lose it
+		original := *current
+		original.Metadata = existing.Metadata
+		original.Spec = updated.Spec
+		if restoreErr := createRelationRequest(ctx, client, &original, namespace); restoreErr != nil {
+			return fmt.Errorf("failed to create updated relation: %w (restoring the original also failed: %v)", err, restoreErr)
+		}
+		return fmt.Errorf("failed to create updated relation, original restored: %w", err)
+	}
+
+	fmt.Printf("✅ Relation updated successfully\n")
+	fmt.Printf("   Relation: %s\n", updated.Relation)
+	fmt.Printf("   Source:   %s\n", r.Source)
+	fmt.Printf("   Target:   %s\n", r.Target)
+	return nil
+}
+
+// createRelationRequest creates a relation in a namespace. A create that
+// gets no response may still have been handled, so it's only sent again
+// once the relation is known not to exist.
+func createRelationRequest(ctx context.Context, client *api.Client, relation *api.EntityRelation, namespace string) error {
+	params := api.CreateEntityRelationParams{Namespace: namespace}
+	resp, err := client.CreateEntityRelation(ctx, relation, params)
+	if err != nil && ctx.Err() == nil {
+		exists, lookupErr := relationExists(ctx, client, relation, namespace)
+		switch {
+		case lookupErr != nil:
+			logging.Debug("failed to check whether relation was created", "relation", relation.Relation, "error", lookupErr)
+		case exists:
+			return nil
+		default:
+			logging.Info("retrying relation create", "relation", relation.Relation, "error", err)
+			resp, err = client.CreateEntityRelation(ctx, relation, params)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	switch r := resp.(type) {
+	case *api.EntityRelationResponse:
+		return nil
+	case *api.CreateEntityRelationNotFound:
+		return fmt.Errorf("entity not found")
+	case *api.HTTPValidationError:
+		return fmt.Errorf("validation error: %v", r.Detail)
+	default:
+		return fmt.Errorf("unexpected response type: %T", resp)
+	}
+}
+
+// relationExists reports whether a relation of the same type between the
+// same entities as relation is in the namespace
+func relationExists(ctx context.Context, client *api.Client, relation *api.EntityRelation, namespace string) (bool, error) {
+	resp, err := client.ListEntityRelations(ctx, api.ListEntityRelationsParams{
+		Namespace:    namespace,
+		RelationType: api.NewOptNilString(relation.Relation),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	var relations []api.EntityRelationResponse
+	switch r := resp.(type) {
+	case *api.ListEntityRelationsOKApplicationJSON:
+		relations = *r
+	case *api.ListEntityRelationsNotFound:
+	default:
+		return false, fmt.Errorf("unexpected response type: %T", resp)
+	}
+
+	// Relations read from entity IDs name the kind by its plural
+	sameEntity := func(ref api.EntityReferenceResponse, want api.EntityReference) bool {
+		sameKind := strings.EqualFold(ref.Kind, want.Kind) || strings.EqualFold(ref.Kind+"s", want.Kind)
+		return ref.ApiVersion == want.ApiVersion && sameKind && ref.Name == want.Name
+	}
+	for _, rel := range relations {
+		if rel.Relation == relation.Relation && sameEntity(rel.Source, relation.Source) && sameEntity(rel.Target, relation.Target) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// deleteRelation deletes a relation in a namespace
+func deleteRelation(ctx context.Context, client *api.Client, relation *api.EntityRelation, namespace string) error {
+	resp, err := client.DeleteEntityRelation(ctx, relation, api.DeleteEntityRelationParams{Namespace: namespace})
+	if err != nil {
+		return err
+	}
+
+	switch r := resp.(type) {
+	case *api.DeleteEntityRelationNoContent:
+		return nil
+	case *api.DeleteEntityRelationNotFound:
+		return fmt.Errorf("relation not found")
+	case *api.HTTPValidationError:
+		return fmt.Errorf("validation error: %v", r.Detail)
+	default:
+		return fmt.Errorf("unexpected response type: %T", resp)
+	}
+}
@@ -0,0 +1,475 @@
+package commands
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
+	"github.com/arctir/devgraph-cli/pkg/output"
+	"github.com/arctir/devgraph-cli/pkg/util"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+)
+
+// RelationGraphCommand writes the relation graph to stdout in a format read
+// by graph visualization tools
+type RelationGraphCommand struct {
+	EnvWrapperCommand
+	Namespace string `flag:"namespace,n" help:"Only include relations in this namespace."`
+	Type      string `flag:"type" help:"Only include relations of this type (e.g., DEPENDS_ON)."`
+	Format    string `flag:"format" default:"dot" enum:"dot,mermaid,graphml" help:"Output format: dot, mermaid, graphml."`
+}
+
+// EntityGraphCommand writes the relation graph, or the part of it around one
+// entity, in a format read by graph visualization tools
+type EntityGraphCommand struct {
+	EnvWrapperCommand
+	Root   string `help:"Entity ID to start from; only entities within --depth relations of it are included."`
+	Depth  int    `default:"1" help:"Number of relations to follow from --root, in either direction."`
+	Format string `flag:"format" default:"dot" enum:"dot,mermaid,graphml" help:"Output format: dot, mermaid, graphml."`
+}
+
+// RelationPathCommand finds how one entity is connected to another through
+// relations
+type RelationPathCommand struct {
+	EnvWrapperCommand
+	Source     string `arg:"" required:"" help:"Entity ID to start from: <group>/<version>/<plural>/<namespace>/<name>"`
+	Target     string `arg:"" required:"" help:"Entity ID to reach: <group>/<version>/<plural>/<namespace>/<name>"`
+	Namespace  string `flag:"namespace,n" help:"Only follow relations in this namespace."`
+	Type       string `flag:"type" help:"Only follow relations of this type (e.g., DEPENDS_ON)."`
+	Undirected bool   `flag:"undirected" help:"Also follow relations from target to source."`
+	MaxPaths   int    `flag:"max-paths" default:"10" help:"Maximum number of shortest paths to show."`
+}
+
+// fetchRelationGraph returns every relation of the given type ("" for any
+// type), either in one namespace or, when namespace is empty, across all of
+// them
+func fetchRelationGraph(ctx context.Context, client *api.Client, namespace, relationType string) ([]FilteredEntityRelation, error) {
+	if namespace != "" {
+		params := api.ListEntityRelationsParams{Namespace: namespace}
+		if relationType != "" {
+			params.RelationType = api.NewOptNilString(relationType)
+		}
+		resp, err := client.ListEntityRelations(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list relations: %w", err)
+		}
+
+		switch r := resp.(type) {
+		case *api.ListEntityRelationsOKApplicationJSON:
+			relations := make([]FilteredEntityRelation, len(*r))
+			for i, rel := range *r {
+				relations[i] = devgraph.NewEntityRelation(rel)
+			}
+			return relations, nil
+		case *api.ListEntityRelationsNotFound:
+			return nil, nil
+		case *api.HTTPValidationError:
+			return nil, fmt.Errorf("validation error: %v", r.Detail)
+		default:
+			return nil, fmt.Errorf("unexpected response type: %T", resp)
+		}
+	}
+
+	_, all, err := fetchCatalogEntities(ctx, client, api.GetEntitiesParams{IncludeRelations: api.NewOptBool(true)})
+	if err != nil {
+		return nil, err
+	}
+	if relationType == "" {
+		return all, nil
+	}
+	var relations []FilteredEntityRelation
+	for _, rel := range all {
+		if rel.Relation == relationType {
+			relations = append(relations, rel)
+		}
+	}
+	return relations, nil
+}
+
+// graphNodes returns the entities in relations, sorted
+func graphNodes(relations []FilteredEntityRelation) []string {
+	seen := make(map[string]bool)
+	var nodes []string
+	for _, rel := range relations {
+		for _, id := range []string{rel.Source, rel.Target} {
+			if !seen[id] {
+				seen[id] = true
+				nodes = append(nodes, id)
+			}
+		}
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// writeDOT writes relations as a Graphviz digraph
+func writeDOT(w io.Writer, relations []FilteredEntityRelation) error {
+	fmt.Fprintln(w, "digraph relations {")
+	for _, node := range graphNodes(relations) {
+		fmt.Fprintf(w, "  %q;\n", node)
+	}
+	for _, rel := range relations {
+		fmt.Fprintf(w, "  %q -> %q [label=%q];\n", rel.Source, rel.Target, rel.Relation)
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// writeMermaid writes relations as a Mermaid flowchart
+func writeMermaid(w io.Writer, relations []FilteredEntityRelation) error {
+	// Mermaid node IDs can't contain slashes, so nodes are numbered and
+	// labeled with the entity ID
+	ids := make(map[string]string)
+	fmt.Fprintln(w, "graph LR")
+	for i, node := range graphNodes(relations) {
+		ids[node] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(w, "  %s[\"%s\"]\n", ids[node], mermaidEscape(node))
+	}
+	for _, rel := range relations {
+		fmt.Fprintf(w, "  %s -->|%s| %s\n", ids[rel.Source], mermaidEscape(rel.Relation), ids[rel.Target])
+	}
+	return nil
+}
+
+// mermaidEscape escapes the characters that end a Mermaid label
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "|", "#124;").Replace(s)
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID string `xml:"id,attr"`
+}
+
+type graphMLEdge struct {
+	Source string      `xml:"source,attr"`
+	Target string      `xml:"target,attr"`
+	Data   graphMLData `xml:"data"`
+}
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+// writeGraphML writes relations as a GraphML document with the relation type
+// as an edge attribute
+func writeGraphML(w io.Writer, relations []FilteredEntityRelation) error {
+	doc := graphMLDocument{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  []graphMLKey{{ID: "relation", For: "edge", AttrName: "relation", AttrType: "string"}},
+	}
+	doc.Graph.ID = "relations"
+	doc.Graph.EdgeDefault = "directed"
+	for _, node := range graphNodes(relations) {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{ID: node})
+	}
+	for _, rel := range relations {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: rel.Source,
+			Target: rel.Target,
+			Data:   graphMLData{Key: "relation", Value: rel.Relation},
+		})
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal GraphML: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, data)
+	return err
+}
+
+// graphWriter returns the function that writes relations in format
+func graphWriter(format string) (func(io.Writer, []FilteredEntityRelation) error, error) {
+	switch format {
+	case "dot":
+		return writeDOT, nil
+	case "mermaid":
+		return writeMermaid, nil
+	case "graphml":
+		return writeGraphML, nil
+	default:
+		return nil, fmt.Errorf("unsupported graph format: %s", format)
+	}
+}
+
+// Run executes the relation graph command
+func (r *RelationGraphCommand) Run() error {
+	write, err := graphWriter(r.Format)
+	if err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(r.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	relations, err := fetchRelationGraph(context.Background(), client, r.Namespace, r.Type)
+	if err != nil {
+		return err
+	}
+
+	return write(os.Stdout, relations)
+}
+
+// Run executes the entity graph command
+func (e *EntityGraphCommand) Run() error {
+	write, err := graphWriter(e.Format)
+	if err != nil {
+		return err
+	}
+	if e.Root == "" {
+		client, err := util.GetAuthenticatedClient(e.Config)
+		if err != nil {
+			return fmt.Errorf("failed to create authenticated client: %w", err)
+		}
+		relations, err := fetchRelationGraph(context.Background(), client, "", "")
+		if err != nil {
+			return err
+		}
+		return write(os.Stdout, relations)
+	}
+
+	if e.Depth < 1 {
+		return fmt.Errorf("--depth must be at least 1")
+	}
+	root, err := entityParams(e.Root)
+	if err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(e.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+	relations, err := walkRelationGraph(context.Background(), client, root, e.Depth)
+	if err != nil {
+		return err
+	}
+	return write(os.Stdout, relations)
+}
+
+// walkRelationGraph returns the relations within depth hops of root,
+// following them in both directions. Each entity on the way is read with its
+// relations, so only the part of the graph being drawn is fetched.
+func walkRelationGraph(ctx context.Context, client *api.Client, root api.GetEntityParams, depth int) ([]FilteredEntityRelation, error) {
+	entity, err := getEntityWithRelations(ctx, client, root)
+	if err != nil {
+		return nil, err
+	}
+	if entity == nil {
+		return nil, fmt.Errorf("entity not found: %s", formatEntityID(root))
+	}
+
+	seen := make(map[FilteredEntityRelation]bool)
+	var relations []FilteredEntityRelation
+	rootID := entity.Entity.ID
+	if rootID == "" {
+		rootID = formatEntityID(root)
+	}
+	visited := map[string]bool{rootID: true}
+	level := []*api.EntityWithRelationsResponse{entity}
+	for hop := 1; hop <= depth && len(level) > 0; hop++ {
+		var next []string
+		for _, current := range level {
+			for _, rel := range current.Relations {
+				f := devgraph.NewEntityRelation(rel)
+				if !seen[f] {
+					seen[f] = true
+					relations = append(relations, f)
+				}
+				for _, id := range []string{f.Source, f.Target} {
+					if !visited[id] {
+						visited[id] = true
+						next = append(next, id)
+					}
+				}
+			}
+		}
+
+		// The entities reached on the last hop are drawn, but their own
+		// relations lead further than asked
+		if hop == depth {
+			break
+		}
+		level = nil
+		for _, id := range next {
+			params, err := entityParams(id)
+			if err != nil {
+				return nil, err
+			}
+			related, err := getEntityWithRelations(ctx, client, params)
+			if err != nil {
+				return nil, err
+			}
+			if related != nil {
+				level = append(level, related)
+			}
+		}
+	}
+
+	sortRelations(relations)
+	return relations, nil
+}
+
+// pathStep is one hop along a relation path. Reverse is set when the relation
+// was followed from its target to its source.
+type pathStep struct {
+	Relation string `json:"relation" yaml:"relation"`
+	Entity   string `json:"entity" yaml:"entity"`
+	Reverse  bool   `json:"reverse,omitempty" yaml:"reverse,omitempty"`
+}
+
+// shortestPaths returns up to limit of the shortest paths from source to
+// target, each as the steps taken after leaving source. Relations are only
+// followed backwards when undirected is set.
+func shortestPaths(relations []FilteredEntityRelation, source, target string, undirected bool, limit int) [][]pathStep {
+	edges := make(map[string][]pathStep)
+	for _, rel := range relations {
+		from := strings.TrimPrefix(rel.Source, "entity://")
+		to := strings.TrimPrefix(rel.Target, "entity://")
+		edges[from] = append(edges[from], pathStep{Relation: rel.Relation, Entity: to})
+		if undirected {
+			edges[to] = append(edges[to], pathStep{Relation: rel.Relation, Entity: from, Reverse: true})
+		}
+	}
+
+	// Breadth-first search recording every way of reaching each entity at
+	// its shortest distance
+	type arrival struct {
+		from string
+		step pathStep
+	}
+	distance := map[string]int{source: 0}
+	arrivals := make(map[string][]arrival)
+	queue := []string{source}
+	for len(queue) > 0 {
+		entity := queue[0]
+		queue = queue[1:]
+		if entity == target {
+			continue
+		}
+		for _, step := range edges[entity] {
+			d, seen := distance[step.Entity]
+			if !seen {
+				distance[step.Entity] = distance[entity] + 1
+				queue = append(queue, step.Entity)
+			} else if d != distance[entity]+1 {
+				continue
+			}
+			arrivals[step.Entity] = append(arrivals[step.Entity], arrival{from: entity, step: step})
+		}
+	}
+
+	if _, found := distance[target]; !found || source == target {
+		return nil
+	}
+
+	// Walk back from the target to enumerate the paths
+	var paths [][]pathStep
+	var walk func(entity string, suffix []pathStep)
+	walk = func(entity string, suffix []pathStep) {
+		if len(paths) >= limit {
+			return
+		}
+		if entity == source {
+			path := make([]pathStep, len(suffix))
+			for i := range suffix {
+				path[i] = suffix[len(suffix)-1-i]
+			}
+			paths = append(paths, path)
+			return
+		}
+		for _, a := range arrivals[entity] {
+			walk(a.from, append(suffix, a.step))
+		}
+	}
+	walk(target, nil)
+	return paths
+}
+
+// Run executes the relation path command
+func (r *RelationPathCommand) Run() error {
+	format := r.OutputFormat(output.Table)
+	if err := output.Check(format, output.Table, output.JSON, output.YAML); err != nil {
+		return err
+	}
+	if r.MaxPaths < 1 {
+		return fmt.Errorf("--max-paths must be at least 1")
+	}
+
+	source := strings.TrimPrefix(r.Source, "entity://")
+	target := strings.TrimPrefix(r.Target, "entity://")
+	for _, id := range []string{source, target} {
+		if _, _, _, _, _, err := parseEntityID(id); err != nil {
+			return err
+		}
+	}
+
+	client, err := util.GetAuthenticatedClient(r.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create authenticated client: %w", err)
+	}
+
+	relations, err := fetchRelationGraph(context.Background(), client, r.Namespace, r.Type)
+	if err != nil {
+		return err
+	}
+
+	paths := shortestPaths(relations, source, target, r.Undirected, r.MaxPaths)
+	if format != output.Table {
+		if paths == nil {
+			paths = [][]pathStep{}
+		}
+		return output.Print(format, output.Result{Data: paths})
+	}
+
+	if len(paths) == 0 {
+		fmt.Printf("No path found from %s to %s.\n", source, target)
+		if !r.Undirected {
+			fmt.Println("Use --undirected to also follow relations backwards.")
+		}
+		return nil
+	}
+
+	for i, path := range paths {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Path %d (%d hops):\n", i+1, len(path))
+		fmt.Printf("  %s\n", source)
+		for _, step := range path {
+			if step.Reverse {
+				fmt.Printf("    <-%s-- %s\n", step.Relation, step.Entity)
+			} else {
+				fmt.Printf("    --%s-> %s\n", step.Relation, step.Entity)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package commands
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testGraph = []FilteredEntityRelation{
+	{Relation: "DEPENDS_ON", Source: "core/v1/service/default/api", Target: "core/v1/service/default/db"},
+	{Relation: "DEPENDS_ON", Source: "core/v1/service/default/web", Target: "core/v1/service/default/api"},
+}
+
+func TestWriteDOT(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, writeDOT(&b, testGraph))
+	assert.Equal(t, `digraph relations {
+  "core/v1/service/default/api";
+  "core/v1/service/default/db";
+  "core/v1/service/default/web";
+  "core/v1/service/default/api" -> "core/v1/service/default/db" [label="DEPENDS_ON"];
+  "core/v1/service/default/web" -> "core/v1/service/default/api" [label="DEPENDS_ON"];
+}
+`, b.String())
+}
+
+func TestWriteMermaid(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, writeMermaid(&b, testGraph))
+	assert.Equal(t, `graph LR
+  n0["core/v1/service/default/api"]
+  n1["core/v1/service/default/db"]
+  n2["core/v1/service/default/web"]
+  n0 -->|DEPENDS_ON| n1
+  n2 -->|DEPENDS_ON| n0
+`, b.String())
+}
+
+func TestWriteGraphML(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, writeGraphML(&b, testGraph[:1]))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="relation" for="edge" attr.name="relation" attr.type="string"></key>
+  <graph id="relations" edgedefault="directed">
+    <node id="core/v1/service/default/api"></node>
+    <node id="core/v1/service/default/db"></node>
+    <edge source="core/v1/service/default/api" target="core/v1/service/default/db">
+      <data key="relation">DEPENDS_ON</data>
+    </edge>
+  </graph>
+</graphml>
+`, b.String())
+}
+
+func TestRelationGraphCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/relations", http.StatusOK, []map[string]any{
+		testRelation("DEPENDS_ON", "api", "db"),
+	})
+
+	cmd := RelationGraphCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Namespace: "default", Type: "DEPENDS_ON", Format: "mermaid"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	request := srv.requireRequest(http.MethodGet, "/api/v1/entities/relations")
+	assert.Equal(t, "namespace=default&relation_type=DEPENDS_ON", request.Query)
+	assert.Contains(t, output, "n0 -->|DEPENDS_ON| n1")
+}
+
+func TestRelationGraphCommand_AllNamespaces(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/", http.StatusOK, map[string]any{
+		"primary_entities": []map[string]any{testEntity("api"), testEntity("db")},
+		"relations": []map[string]any{
+			testRelation("DEPENDS_ON", "api", "db"),
+			testRelation("OWNS", "api", "db"),
+		},
+	})
+
+	cmd := RelationGraphCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Type: "OWNS", Format: "dot"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	request := srv.requireRequest(http.MethodGet, "/api/v1/entities/")
+	assert.Contains(t, request.Query, "include_relations=true")
+	assert.Contains(t, output, `[label="OWNS"]`)
+	assert.NotContains(t, output, "DEPENDS_ON")
+}
+
+func TestEntityGraphCommand_Root(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	// frontend -> web -> db -> disk
+	graph := map[string][]map[string]any{
+		"frontend": {testRelation("CALLS", "frontend", "web")},
+		"web":      {testRelation("CALLS", "frontend", "web"), testRelation("DEPENDS_ON", "web", "db")},
+		"db":       {testRelation("DEPENDS_ON", "web", "db"), testRelation("STORES_ON", "db", "disk")},
+	}
+	srv.mux.HandleFunc("GET /api/v1/entities/core/v1/service/default/{name}", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		response := testEntityWithRelations(testEntity(name))
+		response["relations"] = graph[name]
+		writeJSON(w, http.StatusOK, response)
+	})
+
+	cmd := EntityGraphCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Root:              "core/v1/service/default/web",
+		Depth:             1,
+		Format:            "dot",
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Equal(t, `digraph relations {
+  "core/v1/service/default/db";
+  "core/v1/service/default/frontend";
+  "core/v1/service/default/web";
+  "core/v1/service/default/frontend" -> "core/v1/service/default/web" [label="CALLS"];
+  "core/v1/service/default/web" -> "core/v1/service/default/db" [label="DEPENDS_ON"];
+}
+`, output)
+	srv.requireRequest(http.MethodGet, "/api/v1/entities/core/v1/service/default/web")
+	assert.Empty(t, srv.received(http.MethodGet, "/api/v1/entities/core/v1/service/default/db"), "entities on the last hop aren't read")
+
+	// Another hop reads the entities the first reached, in both directions
+	cmd.Depth = 2
+	cmd.Format = "mermaid"
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "|STORES_ON|")
+	assert.Len(t, srv.received(http.MethodGet, "/api/v1/entities/core/v1/service/default/db"), 1)
+	assert.Len(t, srv.received(http.MethodGet, "/api/v1/entities/core/v1/service/default/frontend"), 1)
+	assert.Empty(t, srv.received(http.MethodGet, "/api/v1/entities/core/v1/service/default/disk"))
+	assert.Empty(t, srv.received(http.MethodGet, "/api/v1/entities/"), "the catalog isn't read")
+
+	cmd.Depth = 0
+	assert.EqualError(t, cmd.Run(), "--depth must be at least 1")
+}
+
+func TestEntityGraphCommand_All(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/", http.StatusOK, map[string]any{
+		"primary_entities": []map[string]any{testEntity("api"), testEntity("db")},
+		"relations":        []map[string]any{testRelation("DEPENDS_ON", "api", "db")},
+	})
+
+	cmd := EntityGraphCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Depth: 1, Format: "graphml"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	request := srv.requireRequest(http.MethodGet, "/api/v1/entities/")
+	assert.Contains(t, request.Query, "include_relations=true")
+	assert.Contains(t, output, `<edge source="core/v1/service/default/api" target="core/v1/service/default/db">`)
+}
+
+func TestShortestPaths(t *testing.T) {
+	relations := []FilteredEntityRelation{
+		{Relation: "DEPENDS_ON", Source: "a", Target: "b"},
+		{Relation: "DEPENDS_ON", Source: "a", Target: "c"},
+		{Relation: "USES", Source: "b", Target: "d"},
+		{Relation: "USES", Source: "c", Target: "d"},
+		{Relation: "OWNS", Source: "e", Target: "a"},
+		{Relation: "DEPENDS_ON", Source: "d", Target: "f"},
+		{Relation: "DEPENDS_ON", Source: "a", Target: "f"},
+	}
+
+	assert.Equal(t, [][]pathStep{
+		{{Relation: "DEPENDS_ON", Entity: "b"}, {Relation: "USES", Entity: "d"}},
+		{{Relation: "DEPENDS_ON", Entity: "c"}, {Relation: "USES", Entity: "d"}},
+	}, shortestPaths(relations, "a", "d", false, 10))
+
+	assert.Len(t, shortestPaths(relations, "a", "d", false, 1), 1)
+	assert.Equal(t, [][]pathStep{{{Relation: "DEPENDS_ON", Entity: "f"}}}, shortestPaths(relations, "a", "f", false, 10))
+
+	assert.Nil(t, shortestPaths(relations, "d", "e", false, 10))
+	assert.Equal(t, [][]pathStep{
+		{{Relation: "DEPENDS_ON", Entity: "a", Reverse: true}, {Relation: "OWNS", Entity: "e", Reverse: true}},
+	}, shortestPaths(relations, "f", "e", true, 10))
+}
+
+func TestRelationPathCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/relations", http.StatusOK, []map[string]any{
+		testRelation("DEPENDS_ON", "web", "api"),
+		testRelation("DEPENDS_ON", "api", "db"),
+	})
+
+	cmd := RelationPathCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Source:            "entity://core/v1/service/default/web",
+		Target:            "core/v1/service/default/db",
+		Namespace:         "default",
+		MaxPaths:          10,
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	srv.requireRequest(http.MethodGet, "/api/v1/entities/relations")
+	assert.Equal(t, `Path 1 (2 hops):
+  core/v1/service/default/web
+    --DEPENDS_ON-> core/v1/service/default/api
+    --DEPENDS_ON-> core/v1/service/default/db
+`, output)
+
+	cmd.Source, cmd.Target = cmd.Target, cmd.Source
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "No path found")
+}
@@ -0,0 +1,511 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/arctir/devgraph-cli/pkg/devgraph"
+	"github.com/arctir/devgraph-cli/pkg/util"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRelation returns a relation between two services in the default
+// namespace as returned by the relations API
+func testRelation(relation, source, target string) map[string]any {
+	return map[string]any{
+		"namespace": "default",
+		"relation":  relation,
+		"source":    map[string]any{"apiVersion": "core/v1", "kind": "Service", "name": source, "namespace": "default", "id": "core/v1/service/default/" + source},
+		"target":    map[string]any{"apiVersion": "core/v1", "kind": "Service", "name": target, "namespace": "default", "id": "core/v1/service/default/" + target},
+	}
+}
+
+func TestRelationListCommand_ServerSideFilters(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/relations", http.StatusOK, []map[string]any{
+		testRelation("DEPENDS_ON", "api", "db"),
+		testRelation("DEPENDS_ON", "web", "api"),
+	})
+
+	cfg.Output = "json"
+	cmd := RelationListCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Source:            "entity://core/v1/service/default/api",
+		Type:              "DEPENDS_ON",
+		ManagedBy:         "github",
+	}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	request := srv.requireRequest(http.MethodGet, "/api/v1/entities/relations")
+	assert.Equal(t, "managed_by=github&namespace=default&relation_type=DEPENDS_ON", request.Query)
+	assert.Empty(t, srv.received(http.MethodGet, "/api/v1/entities/"))
+
+	var relations []FilteredEntityRelation
+	require.NoError(t, json.Unmarshal([]byte(output), &relations), output)
+	assert.Equal(t, []FilteredEntityRelation{{
+		Namespace: "default",
+		Relation:  "DEPENDS_ON",
+		Source:    "core/v1/service/default/api",
+		Target:    "core/v1/service/default/db",
+	}}, relations)
+}
+
+func TestRelationListCommand_AllNamespaces(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/", http.StatusOK, map[string]any{
+		"primary_entities": []map[string]any{testEntity("api"), testEntity("db")},
+		"relations": []map[string]any{
+			testRelation("DEPENDS_ON", "api", "db"),
+			testRelation("OWNS", "api", "db"),
+		},
+	})
+
+	cfg.Output = "json"
+	cmd := RelationListCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Type: "OWNS", Limit: 1000}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	srv.requireRequest(http.MethodGet, "/api/v1/entities/")
+	var relations []FilteredEntityRelation
+	require.NoError(t, json.Unmarshal([]byte(output), &relations), output)
+	require.Len(t, relations, 1)
+	assert.Equal(t, "OWNS", relations[0].Relation)
+
+	cmd.ManagedBy = "github"
+	_, err = captureOutput(t, cmd.Run)
+	assert.ErrorContains(t, err, "require --namespace")
+}
+
+func TestRelationApplyCommand_CSV(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.mux.HandleFunc("POST /api/v1/entities/relations", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if body["target"].(map[string]any)["name"] == "missing" {
+			writeJSON(w, http.StatusNotFound, nil)
+			return
+		}
+		writeJSON(w, http.StatusCreated, testRelation("DEPENDS_ON", "api", "db"))
+	})
+
+	file := filepath.Join(t.TempDir(), "relations.csv")
+	require.NoError(t, os.WriteFile(file, []byte(`source,target,relation
+core/v1/services/default/api,core/v1/services/default/db,DEPENDS_ON
+core/v1/services/default/web,core/v1/services/default/missing,DEPENDS_ON
+`), 0o600))
+
+	cmd := RelationApplyCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, File: file, Workers: 2}
+	output, err := captureOutput(t, cmd.Run)
+	require.ErrorContains(t, err, "1 relations failed to create")
+	assert.Contains(t, output, "✅ Row 1: core/v1/services/default/api -> core/v1/services/default/db (DEPENDS_ON)")
+	assert.Contains(t, output, "✗ Row 2: core/v1/services/default/web -> core/v1/services/default/missing (DEPENDS_ON): entity not found")
+
+	requests := srv.received(http.MethodPost, "/api/v1/entities/relations")
+	require.Len(t, requests, 2)
+	for _, request := range requests {
+		assert.Equal(t, "namespace=default", request.Query)
+		body := request.JSON(t)
+		assert.Equal(t, "DEPENDS_ON", body["relation"])
+		assert.Equal(t, "core/v1", body["source"].(map[string]any)["apiVersion"])
+		assert.Equal(t, "services", body["source"].(map[string]any)["kind"])
+	}
+}
+
+func TestRelationApplyCommand_NoResponse(t *testing.T) {
+	for name, created := range map[string]bool{"created": true, "not created": false} {
+		t.Run(name, func(t *testing.T) {
+			srv, cfg := newTestAPI(t)
+			posts := 0
+			srv.mux.HandleFunc("POST /api/v1/entities/relations", func(w http.ResponseWriter, r *http.Request) {
+				posts++
+				if posts == 1 {
+					// Drop the connection, as if it failed after the create
+					panic(http.ErrAbortHandler)
+				}
+				writeJSON(w, http.StatusCreated, testRelation("DEPENDS_ON", "api", "db"))
+			})
+			srv.mux.HandleFunc("GET /api/v1/entities/relations", func(w http.ResponseWriter, r *http.Request) {
+				relations := []map[string]any{testRelation("DEPENDS_ON", "api", "cache")}
+				if created {
+					relations = append(relations, testRelation("DEPENDS_ON", "api", "db"))
+				}
+				writeJSON(w, http.StatusOK, relations)
+			})
+
+			file := filepath.Join(t.TempDir(), "relations.csv")
+			require.NoError(t, os.WriteFile(file, []byte("core/v1/services/default/api,core/v1/services/default/db,DEPENDS_ON\n"), 0o600))
+
+			cmd := RelationApplyCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, File: file, Workers: 1}
+			_, err := captureOutput(t, cmd.Run)
+			require.NoError(t, err)
+
+			// The create is only sent again when the relation isn't there
+			lookup := srv.requireRequest(http.MethodGet, "/api/v1/entities/relations")
+			assert.Equal(t, "namespace=default&relation_type=DEPENDS_ON", lookup.Query)
+			if created {
+				assert.Equal(t, 1, posts)
+			} else {
+				assert.Equal(t, 2, posts)
+			}
+		})
+	}
+}
+
+func TestRelationApplyCommand_DefaultWorkers(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	var mu sync.Mutex
+	inFlight, most := 0, 0
+	srv.mux.HandleFunc("POST /api/v1/entities/relations", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		most = max(most, inFlight)
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		writeJSON(w, http.StatusCreated, testRelation("DEPENDS_ON", "api", "db"))
+	})
+
+	file := filepath.Join(t.TempDir(), "relations.csv")
+	require.NoError(t, os.WriteFile(file, []byte(`core/v1/services/default/api,core/v1/services/default/db,DEPENDS_ON
+core/v1/services/default/web,core/v1/services/default/api,DEPENDS_ON
+core/v1/services/default/web,core/v1/services/default/db,DEPENDS_ON
+`), 0o600))
+
+	// Without --workers, the default_workers setting applies
+	cfg.Workers = 1
+	cmd := RelationApplyCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, File: file}
+	_, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Len(t, srv.received(http.MethodPost, "/api/v1/entities/relations"), 3)
+	assert.Equal(t, 1, most)
+}
+
+func TestRelationApplyCommand_RateLimited(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	var mu sync.Mutex
+	calls := 0
+	srv.mux.HandleFunc("POST /api/v1/entities/relations", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		limited := calls <= 3
+		mu.Unlock()
+		if limited {
+			w.Header().Set("Retry-After", "0")
+			writeJSON(w, http.StatusTooManyRequests, map[string]any{"detail": "Too many requests"})
+			return
+		}
+		writeJSON(w, http.StatusCreated, testRelation("DEPENDS_ON", "api", "db"))
+	})
+
+	var rows strings.Builder
+	rows.WriteString("source,target,relation\n")
+	for i := 0; i < 4; i++ {
+		fmt.Fprintf(&rows, "core/v1/services/default/api%d,core/v1/services/default/db,DEPENDS_ON\n", i)
+	}
+	file := filepath.Join(t.TempDir(), "relations.csv")
+	require.NoError(t, os.WriteFile(file, []byte(rows.String()), 0o600))
+
+	// Rate limited requests are retried rather than reported as failed
+	cmd := RelationApplyCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, File: file, Workers: 4}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.NotContains(t, output, "✗")
+	assert.Len(t, srv.received(http.MethodPost, "/api/v1/entities/relations"), 7)
+}
+
+func TestRelationApplyCommand_DryRunYAML(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+
+	file := filepath.Join(t.TempDir(), "relations.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+- relation: OWNS
+  source: core/v1/teams/default/platform
+  target: core/v1/services/default/api
+`), 0o600))
+
+	cmd := RelationApplyCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, File: file}
+	cmd.DryRun = true
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Would create 1 relations")
+	assert.Empty(t, srv.received(http.MethodPost, "/api/v1/entities/relations"))
+}
+
+func TestParseRelationsFile(t *testing.T) {
+	_, err := parseRelationsFile("r.csv", []byte("a,b\n"))
+	assert.ErrorContains(t, err, "line 1: expected source,target,relation")
+
+	_, err = parseRelationsFile("r.yaml", []byte("- {source: a, target: b}\n"))
+	assert.ErrorContains(t, err, "relation 1: source, target, and relation are required")
+
+	relations, err := parseRelationsFile("r.csv", []byte("# comment\na, b, USES\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []FilteredEntityRelation{{Source: "a", Target: "b", Relation: "USES"}}, relations)
+}
+
+func TestRelationTypesCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/relations", http.StatusOK, []map[string]any{
+		testRelation("DEPENDS_ON", "api", "db"),
+		testRelation("OWNS", "team", "api"),
+		testRelation("DEPENDS_ON", "web", "api"),
+	})
+
+	cfg.Output = "json"
+	cmd := RelationTypesCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Namespace: "default"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	request := srv.requireRequest(http.MethodGet, "/api/v1/entities/relations")
+	assert.Equal(t, "namespace=default", request.Query)
+	assert.JSONEq(t, `[{"type": "DEPENDS_ON", "count": 2}, {"type": "OWNS", "count": 1}]`, output)
+}
+
+func TestRelationCreateCommand_UnknownType(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/relations", http.StatusOK, []map[string]any{
+		testRelation("DEPENDS_ON", "api", "db"),
+	})
+	srv.handle("POST /api/v1/entities/relations", http.StatusCreated, testRelation("DEPENDSON", "web", "api"))
+
+	cmd := RelationCreateCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Relation:          "DEPENDSON",
+		Source:            "core/v1/services/default/web",
+		Target:            "core/v1/services/default/api",
+	}
+	_, err := captureOutput(t, cmd.Run)
+	require.ErrorContains(t, err, "unknown relation type 'DEPENDSON' in namespace 'default' (known types: DEPENDS_ON)")
+	assert.Empty(t, srv.received(http.MethodPost, "/api/v1/entities/relations"))
+
+	cmd.AllowUnknown = true
+	_, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	body := srv.requireRequest(http.MethodPost, "/api/v1/entities/relations").JSON(t)
+	assert.Equal(t, "DEPENDSON", body["relation"])
+	assert.Len(t, srv.received(http.MethodGet, "/api/v1/entities/relations"), 1, "--allow-unknown skips the lookup")
+
+	cmd.AllowUnknown = false
+	cmd.Relation = "DEPENDS_ON"
+	_, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Len(t, srv.received(http.MethodPost, "/api/v1/entities/relations"), 2)
+}
+
+func TestRelationUpdateCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	existing := testRelation("DEPENDS_ON", "api", "db")
+	existing["metadata"] = map[string]any{
+		"labels":      map[string]any{"team": "platform", "tier": "1"},
+		"annotations": map[string]any{"note": "critical"},
+	}
+	existing["spec"] = map[string]any{"port": 5432}
+	srv.handle("GET /api/v1/entities/relations", http.StatusOK, []map[string]any{existing})
+	srv.handle("DELETE /api/v1/entities/relations", http.StatusNoContent, nil)
+	srv.handle("POST /api/v1/entities/relations", http.StatusCreated, testRelation("USES", "api", "db"))
+
+	cmd := RelationUpdateCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Relation:          "DEPENDS_ON",
+		Source:            "core/v1/service/default/api",
+		Target:            "core/v1/service/default/db",
+		Type:              "USES",
+		Label:             []string{"tier-", "owner=alice"},
+	}
+	_, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	list := srv.requireRequest(http.MethodGet, "/api/v1/entities/relations")
+	assert.Equal(t, "namespace=default&relation_type=DEPENDS_ON", list.Query)
+
+	deleted := srv.requireRequest(http.MethodDelete, "/api/v1/entities/relations").JSON(t)
+	assert.Equal(t, "DEPENDS_ON", deleted["relation"])
+
+	created := srv.requireRequest(http.MethodPost, "/api/v1/entities/relations").JSON(t)
+	assert.Equal(t, "USES", created["relation"])
+	assert.Equal(t, map[string]any{
+		"labels":      map[string]any{"team": "platform", "owner": "alice"},
+		"annotations": map[string]any{"note": "critical"},
+	}, created["metadata"])
+	assert.Equal(t, map[string]any{"port": 5432.0}, created["spec"])
+}
+
+func TestRelationUpdateCommand_RestoresOnFailure(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/relations", http.StatusOK, []map[string]any{testRelation("DEPENDS_ON", "api", "db")})
+	srv.handle("DELETE /api/v1/entities/relations", http.StatusNoContent, nil)
+	srv.mux.HandleFunc("POST /api/v1/entities/relations", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if body["relation"] == "BROKEN" {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"detail": []any{}})
+			return
+		}
+		writeJSON(w, http.StatusCreated, testRelation("DEPENDS_ON", "api", "db"))
+	})
+
+	cmd := RelationUpdateCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Relation:          "DEPENDS_ON",
+		Source:            "core/v1/service/default/api",
+		Target:            "core/v1/service/default/db",
+		Type:              "BROKEN",
+	}
+	_, err := captureOutput(t, cmd.Run)
+	require.ErrorContains(t, err, "original restored")
+
+	creates := srv.received(http.MethodPost, "/api/v1/entities/relations")
+	require.Len(t, creates, 2)
+	assert.Equal(t, "DEPENDS_ON", creates[1].JSON(t)["relation"])
+}
+
+func TestRelationUpdateCommand_NotFound(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/relations", http.StatusOK, []map[string]any{})
+
+	cmd := RelationUpdateCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Relation:          "DEPENDS_ON",
+		Source:            "core/v1/service/default/api",
+		Target:            "core/v1/service/default/db",
+		Type:              "USES",
+	}
+	_, err := captureOutput(t, cmd.Run)
+	require.ErrorContains(t, err, "relation not found")
+	assert.Empty(t, srv.received(http.MethodDelete, "/api/v1/entities/relations"))
+
+	cmd.Type = ""
+	assert.ErrorContains(t, cmd.Run(), "nothing to update")
+}
+
+func TestApplyMetadataChanges(t *testing.T) {
+	result, err := applyMetadataChanges(map[string]string{"a": "1", "b": "2"}, []string{"a-", "c=3", "d="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"b": "2", "c": "3", "d": ""}, result)
+
+	_, err = applyMetadataChanges(nil, []string{"nokey"})
+	assert.Error(t, err)
+}
+
+func TestRelationListCommand_Pagination(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entities/relations", http.StatusOK, []map[string]any{
+		testRelation("DEPENDS_ON", "a", "b"),
+		testRelation("DEPENDS_ON", "b", "c"),
+		testRelation("DEPENDS_ON", "c", "d"),
+	})
+
+	cmd := RelationListCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Namespace: "default", Offset: 1, Limit: 1}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "core/v1/service/default/b")
+	assert.NotContains(t, output, "core/v1/service/default/a ")
+	assert.Contains(t, output, "Showing 2-2 of 3 relations")
+
+	cmd.All = true
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Showing 2-3 of 3 relations")
+
+	cmd.Offset = 0
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Total: 3 relations")
+}
+
+func TestRelationListCommand_PagesEntities(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.mux.HandleFunc("GET /api/v1/entities/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("offset") != "0" {
+			writeJSON(w, http.StatusOK, map[string]any{
+				"primary_entities": []map[string]any{testEntity("last")},
+				"relations":        []map[string]any{testRelation("DEPENDS_ON", "svc-0", "last")},
+			})
+			return
+		}
+		entities := make([]map[string]any, devgraph.PageSize)
+		for i := range entities {
+			entities[i] = testEntity(fmt.Sprintf("svc-%d", i))
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"primary_entities": entities,
+			"relations": []map[string]any{
+				testRelation("DEPENDS_ON", "svc-0", "svc-1"),
+				testRelation("DEPENDS_ON", "svc-0", "last"),
+			},
+		})
+	})
+
+	cfg.Output = "json"
+	cmd := RelationListCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, All: true}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	requests := srv.received(http.MethodGet, "/api/v1/entities/")
+	require.Len(t, requests, 1+devgraph.PageRequests)
+	assert.Contains(t, requests[0].Query, "offset=0")
+
+	var relations []FilteredEntityRelation
+	require.NoError(t, json.Unmarshal([]byte(output), &relations), output)
+	assert.Len(t, relations, 2, "relations across pages are only listed once")
+}
+
+func TestWatchRelations(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	polls := [][]map[string]any{
+		{testRelation("DEPENDS_ON", "api", "db")},
+		{testRelation("DEPENDS_ON", "api", "db"), testRelation("OWNS", "team", "api")},
+		{testRelation("OWNS", "team", "api")},
+	}
+	var calls int
+	srv.mux.HandleFunc("GET /api/v1/entities/relations", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, polls[min(calls, len(polls)-1)])
+		calls++
+	})
+
+	client, err := util.GetAuthenticatedClient(cfg)
+	require.NoError(t, err)
+	cmd := RelationListCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Namespace: "default"}
+	initial, err := cmd.fetchRelations(client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	output, err := captureOutput(t, func() error {
+		return watchRelations(ctx, initial, time.Millisecond, func() ([]api.EntityRelationResponse, error) {
+			relations, err := cmd.fetchRelations(client)
+			if calls == len(polls) {
+				cancel()
+			}
+			return relations, err
+		})
+	})
+	require.NoError(t, err)
+
+	for _, request := range srv.received(http.MethodGet, "/api/v1/entities/relations") {
+		assert.Equal(t, "namespace=default", request.Query)
+	}
+	assert.Contains(t, output, "Total: 1 relations")
+	assert.Regexp(t, `\+ OWNS\s+core/v1/service/default/team -> core/v1/service/default/api`, output)
+	assert.Regexp(t, `- DEPENDS_ON\s+core/v1/service/default/api -> core/v1/service/default/db`, output)
+	assert.Equal(t, 1, strings.Count(output, "+ OWNS"))
+}
+
+func TestRelationListCommand_WatchRequiresTable(t *testing.T) {
+	cmd := RelationListCommand{EnvWrapperCommand: EnvWrapperCommand{Config: config.Config{Output: "json"}}, Watch: true, Interval: time.Second}
+	assert.ErrorContains(t, cmd.Run(), "--watch only supports table output")
+}
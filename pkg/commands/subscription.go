@@ -3,20 +3,179 @@ package commands
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/arctir/devgraph-cli/pkg/logging"
+	"github.com/arctir/devgraph-cli/pkg/output"
 	"github.com/arctir/devgraph-cli/pkg/util"
 	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+	"github.com/google/uuid"
 )
 
 type SubscriptionListCommand struct {
 	config.Config
-	Output string `short:"o" help:"Output format: table, json, yaml" default:"table"`
+}
+
+// SubscriptionUsageCommand shows current-period usage against plan limits
+type SubscriptionUsageCommand struct {
+	EnvWrapperCommand
+}
+
+// SubscriptionGetCommand shows the plan, billing period, and entitlements of
+// a subscription
+type SubscriptionGetCommand struct {
+	EnvWrapperCommand
+	SubscriptionID string `arg:"" optional:"" help:"Subscription ID or Stripe subscription ID (defaults to the current environment's subscription)"`
 }
 
 type SubscriptionCommand struct {
-	List SubscriptionListCommand `cmd:"list" help:"List subscriptions"`
+	List  SubscriptionListCommand  `cmd:"list" help:"List subscriptions"`
+	Get   SubscriptionGetCommand   `cmd:"get" help:"Show details of a subscription"`
+	Usage SubscriptionUsageCommand `cmd:"usage" help:"Show usage for the current billing period against plan limits"`
+}
+
+// environmentSubscription returns the subscription covering the given
+// environment, or nil if there isn't one
+func environmentSubscription(ctx context.Context, client *api.Client, environmentID uuid.UUID) (*api.SubscriptionResponse, error) {
+	response, err := client.GetSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
+	}
+
+	okResp, ok := response.(*api.GetSubscriptionsOKApplicationJSON)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type")
+	}
+
+	subscriptions := []api.SubscriptionResponse(*okResp)
+	for i, sub := range subscriptions {
+		for _, id := range sub.EnvironmentIds {
+			if id == environmentID {
+				return &subscriptions[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// usageMetric is the consumption of one metered resource. Limit is nil when
+// the plan doesn't cap it.
+type usageMetric struct {
+	Used  int64  `json:"used" yaml:"used"`
+	Limit *int64 `json:"limit,omitempty" yaml:"limit,omitempty"`
+}
+
+// percentUsed returns usage as a percentage of the limit, or nil if unlimited
+func (m usageMetric) percentUsed() *float64 {
+	if m.Limit == nil || *m.Limit <= 0 {
+		return nil
+	}
+	percent := float64(m.Used) / float64(*m.Limit) * 100
+	return &percent
+}
+
+// overage returns how far adding n more of the resource would go past the
+// limit, or 0 if it stays within the plan
+func (m usageMetric) overage(n int64) int64 {
+	if m.Limit == nil || n <= 0 {
+		return 0
+	}
+	over := m.Used + n - *m.Limit
+	if over < 0 {
+		return 0
+	}
+	return over
+}
+
+// getEntitlementUsage fetches current usage of the metered entitlements
+// (those with a usage count or limit) in the environment the client targets,
+// keyed by entitlement type
+func getEntitlementUsage(ctx context.Context, client *api.Client) (map[string]usageMetric, error) {
+	response, err := client.GetEntitlements(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entitlements: %w", err)
+	}
+
+	okResp, ok := response.(*api.UserEntitlementsResponse)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type: %T", response)
+	}
+
+	usage := make(map[string]usageMetric)
+	for name, detail := range okResp.Entitlements {
+		if !detail.CurrentUsage.Set && !detail.LimitValue.Set {
+			continue
+		}
+		var metric usageMetric
+		if detail.CurrentUsage.Set && !detail.CurrentUsage.Null {
+			metric.Used = int64(detail.CurrentUsage.Value)
+		}
+		if detail.LimitValue.Set && !detail.LimitValue.Null {
+			limit := int64(detail.LimitValue.Value)
+			metric.Limit = &limit
+		}
+		usage[name] = metric
+	}
+	return usage, nil
+}
+
+// checkQuota is a pre-flight check for bulk operations that will create up
+// to n of a metered resource ("entities" or "seats") in the environment the
+// client targets. Going over the plan limit is reported as a warning, or as
+// an error when enforce is set. When the limits can't be determined the
+// operation goes ahead with a warning, unless enforce is set.
+func checkQuota(client *api.Client, resource string, n int64, enforce bool) error {
+	if n <= 0 {
+		return nil
+	}
+
+	usage, err := getEntitlementUsage(context.Background(), client)
+	if err != nil {
+		if enforce {
+			return fmt.Errorf("unable to check plan limits: %w; aborting because --enforce-limits is set", err)
+		}
+		logging.Warn("unable to check plan limits", "error", err)
+		return nil
+	}
+
+	// Entitlements the plan doesn't meter are unlimited
+	metric := usage[resource]
+	over := metric.overage(n)
+	if over == 0 {
+		return nil
+	}
+
+	message := fmt.Sprintf("this operation adds up to %d %s, which would exceed the plan limit of %d (%d in use) by %d",
+		n, resource, *metric.Limit, metric.Used, over)
+	if enforce {
+		return fmt.Errorf("%s; aborting because --enforce-limits is set", message)
+	}
+	logging.Warn(message + "; requests over the limit are likely to fail")
+	return nil
+}
+
+// currentSubscription returns the subscription covering the environment
+// targeted by cfg
+func currentSubscription(cfg config.Config, client *api.Client) (*api.SubscriptionResponse, error) {
+	environment, err := getDefaultEnvironment(cfg)
+	if err != nil {
+		return nil, err
+	}
+	envUUID, err := uuid.Parse(environment)
+	if err != nil {
+		return nil, fmt.Errorf("invalid environment UUID: %w", err)
+	}
+
+	sub, err := environmentSubscription(context.Background(), client, envUUID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, fmt.Errorf("no subscription found for the current environment")
+	}
+	return sub, nil
 }
 
 func (s *SubscriptionListCommand) Run() error {
@@ -55,10 +214,6 @@ func (s *SubscriptionListCommand) Run() error {
 	}
 
 	subscriptions := []api.SubscriptionResponse(*okResp)
-	if len(subscriptions) == 0 {
-		fmt.Println("No subscriptions found.")
-		return nil
-	}
 
 	// Build table data
 	type subOutput struct {
@@ -72,6 +227,7 @@ func (s *SubscriptionListCommand) Run() error {
 
 	structured := make([]subOutput, len(subscriptions))
 	tableData := make([]map[string]any, len(subscriptions))
+	names := make([]string, len(subscriptions))
 
 	for i, sub := range subscriptions {
 		plan := ""
@@ -118,8 +274,233 @@ func (s *SubscriptionListCommand) Run() error {
 			"Period End":   periodEnd,
 			"Environments": environments,
 		}
+		names[i] = sub.ID.String()
+	}
+
+	return output.Print(s.OutputFormat(output.Table), output.Result{
+		Data:    structured,
+		Headers: []string{"ID", "Status", "Plan", "Period Start", "Period End", "Environments"},
+		Rows:    tableData,
+		Empty:   "No subscriptions found.",
+		Names:   names,
+	})
+}
+
+func (s *SubscriptionUsageCommand) Run() error {
+	client, err := util.GetAuthenticatedClient(s.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	sub, err := currentSubscription(s.Config, client)
+	if err != nil {
+		return err
+	}
+
+	usage, err := getEntitlementUsage(context.Background(), client)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(usage))
+	for name := range usage {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	type metricOutput struct {
+		Used        int64    `json:"used" yaml:"used"`
+		Limit       *int64   `json:"limit,omitempty" yaml:"limit,omitempty"`
+		PercentUsed *float64 `json:"percent_used,omitempty" yaml:"percent_used,omitempty"`
+	}
+	type usageOutput struct {
+		SubscriptionID string                  `json:"subscription_id" yaml:"subscription_id"`
+		PeriodStart    *time.Time              `json:"period_start,omitempty" yaml:"period_start,omitempty"`
+		PeriodEnd      *time.Time              `json:"period_end,omitempty" yaml:"period_end,omitempty"`
+		Metrics        map[string]metricOutput `json:"metrics" yaml:"metrics"`
+	}
+
+	structured := usageOutput{
+		SubscriptionID: sub.ID.String(),
+		Metrics:        make(map[string]metricOutput, len(usage)),
+	}
+	if sub.CurrentPeriodStart.Set && !sub.CurrentPeriodStart.Null {
+		start := time.Unix(int64(sub.CurrentPeriodStart.Value), 0).UTC()
+		structured.PeriodStart = &start
+	}
+	if sub.CurrentPeriodEnd.Set && !sub.CurrentPeriodEnd.Null {
+		end := time.Unix(int64(sub.CurrentPeriodEnd.Value), 0).UTC()
+		structured.PeriodEnd = &end
+	}
+
+	tableData := make([]map[string]any, len(names))
+	for i, name := range names {
+		metric := usage[name]
+		structured.Metrics[name] = metricOutput{
+			Used:        metric.Used,
+			Limit:       metric.Limit,
+			PercentUsed: metric.percentUsed(),
+		}
+
+		limit, percent := "unlimited", "-"
+		if metric.Limit != nil {
+			limit = fmt.Sprintf("%d", *metric.Limit)
+		}
+		if p := metric.percentUsed(); p != nil {
+			percent = fmt.Sprintf("%.0f%%", *p)
+		}
+		tableData[i] = map[string]any{
+			"Metric": name,
+			"Used":   fmt.Sprintf("%d", metric.Used),
+			"Limit":  limit,
+			"% Used": percent,
+		}
+	}
+
+	format := s.OutputFormat(output.Table)
+	if format == output.Table {
+		if structured.PeriodStart != nil && structured.PeriodEnd != nil {
+			fmt.Printf("Billing period: %s to %s\n", structured.PeriodStart.Format("2006-01-02"), structured.PeriodEnd.Format("2006-01-02"))
+		}
+		if len(names) == 0 {
+			fmt.Println("No metered entitlements found.")
+			return nil
+		}
+	}
+
+	return output.Print(format, output.Result{
+		Data:    structured,
+		Headers: []string{"Metric", "Used", "Limit", "% Used"},
+		Rows:    tableData,
+	})
+}
+
+// entitlementOutput is a plan entitlement as shown by subscription get
+type entitlementOutput struct {
+	Type    string `json:"type" yaml:"type"`
+	Limit   *int   `json:"limit,omitempty" yaml:"limit,omitempty"`
+	Enabled *bool  `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Config  string `json:"config,omitempty" yaml:"config,omitempty"`
+}
+
+// value renders the entitlement for table output
+func (e entitlementOutput) value() string {
+	switch {
+	case e.Limit != nil:
+		return fmt.Sprintf("%d", *e.Limit)
+	case e.Enabled != nil && *e.Enabled:
+		return "enabled"
+	case e.Enabled != nil:
+		return "disabled"
+	case e.Config != "":
+		return e.Config
+	}
+	return "-"
+}
+
+func (s *SubscriptionGetCommand) Run() error {
+	format := s.OutputFormat(output.Table)
+	if err := output.Check(format, output.Table, output.JSON, output.YAML, output.Name); err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(s.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	var sub *api.SubscriptionResponse
+	if s.SubscriptionID == "" {
+		sub, err = currentSubscription(s.Config, client)
+		if err != nil {
+			return err
+		}
+	} else {
+		response, err := client.GetSubscriptions(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to get subscriptions: %w", err)
+		}
+		okResp, ok := response.(*api.GetSubscriptionsOKApplicationJSON)
+		if !ok {
+			return fmt.Errorf("unexpected response type: %T", response)
+		}
+		subscriptions := []api.SubscriptionResponse(*okResp)
+		for i, candidate := range subscriptions {
+			if candidate.ID.String() == s.SubscriptionID || candidate.StripeSubscriptionID == s.SubscriptionID {
+				sub = &subscriptions[i]
+				break
+			}
+		}
+		if sub == nil {
+			return fmt.Errorf("subscription '%s' not found", s.SubscriptionID)
+		}
 	}
 
-	headers := []string{"ID", "Status", "Plan", "Period Start", "Period End", "Environments"}
-	return util.FormatOutput(s.Output, structured, headers, tableData)
+	type subscriptionOutput struct {
+		ID                   string              `json:"id" yaml:"id"`
+		StripeSubscriptionID string              `json:"stripe_subscription_id" yaml:"stripe_subscription_id"`
+		Status               string              `json:"status" yaml:"status"`
+		Plan                 string              `json:"plan,omitempty" yaml:"plan,omitempty"`
+		PeriodStart          string              `json:"period_start,omitempty" yaml:"period_start,omitempty"`
+		PeriodEnd            string              `json:"period_end,omitempty" yaml:"period_end,omitempty"`
+		Environments         []string            `json:"environments" yaml:"environments"`
+		Entitlements         []entitlementOutput `json:"entitlements,omitempty" yaml:"entitlements,omitempty"`
+	}
+
+	detail := subscriptionOutput{
+		ID:                   sub.ID.String(),
+		StripeSubscriptionID: sub.StripeSubscriptionID,
+		Status:               sub.Status,
+		Environments:         make([]string, len(sub.EnvironmentIds)),
+	}
+	for i, id := range sub.EnvironmentIds {
+		detail.Environments[i] = id.String()
+	}
+	if sub.PlanName.Set && !sub.PlanName.Null {
+		detail.Plan = sub.PlanName.Value
+	}
+	if sub.CurrentPeriodStart.Set && !sub.CurrentPeriodStart.Null {
+		detail.PeriodStart = time.Unix(int64(sub.CurrentPeriodStart.Value), 0).UTC().Format("2006-01-02")
+	}
+	if sub.CurrentPeriodEnd.Set && !sub.CurrentPeriodEnd.Null {
+		detail.PeriodEnd = time.Unix(int64(sub.CurrentPeriodEnd.Value), 0).UTC().Format("2006-01-02")
+	}
+	for _, entitlement := range sub.Entitlements {
+		e := entitlementOutput{Type: entitlement.EntitlementType}
+		if entitlement.LimitValue.Set && !entitlement.LimitValue.Null {
+			limit := entitlement.LimitValue.Value
+			e.Limit = &limit
+		}
+		if entitlement.Enabled.Set && !entitlement.Enabled.Null {
+			enabled := entitlement.Enabled.Value
+			e.Enabled = &enabled
+		}
+		if entitlement.ConfigValue.Set && !entitlement.ConfigValue.Null {
+			e.Config = entitlement.ConfigValue.Value
+		}
+		detail.Entitlements = append(detail.Entitlements, e)
+	}
+	sort.Slice(detail.Entitlements, func(i, j int) bool {
+		return detail.Entitlements[i].Type < detail.Entitlements[j].Type
+	})
+
+	if format != output.Table {
+		return output.Print(format, output.Result{Data: detail, Names: []string{detail.ID}})
+	}
+
+	fmt.Printf("ID:            %s\n", detail.ID)
+	fmt.Printf("Stripe ID:     %s\n", detail.StripeSubscriptionID)
+	fmt.Printf("Plan:          %s\n", util.OrDash(detail.Plan))
+	fmt.Printf("Status:        %s\n", detail.Status)
+	fmt.Printf("Period start:  %s\n", util.OrDash(detail.PeriodStart))
+	fmt.Printf("Period end:    %s\n", util.OrDash(detail.PeriodEnd))
+	fmt.Printf("Environments:  %d\n", len(detail.Environments))
+
+	if len(detail.Entitlements) > 0 {
+		fmt.Println("\nEntitlements:")
+		for _, entitlement := range detail.Entitlements {
+			fmt.Printf("  %-24s %s\n", entitlement.Type, entitlement.value())
+		}
+	}
+	return nil
 }
@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/arctir/devgraph-cli/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageMetricPercentUsed(t *testing.T) {
+	limit := int64(400)
+	percent := usageMetric{Used: 100, Limit: &limit}.percentUsed()
+	require.NotNil(t, percent)
+	assert.Equal(t, 25.0, *percent)
+
+	assert.Nil(t, usageMetric{Used: 100}.percentUsed())
+
+	zero := int64(0)
+	assert.Nil(t, usageMetric{Used: 100, Limit: &zero}.percentUsed())
+}
+
+func TestUsageMetricOverage(t *testing.T) {
+	limit := int64(100)
+	metric := usageMetric{Used: 90, Limit: &limit}
+
+	assert.Equal(t, int64(0), metric.overage(10))
+	assert.Equal(t, int64(5), metric.overage(15))
+	assert.Equal(t, int64(0), metric.overage(0))
+	assert.Equal(t, int64(0), usageMetric{Used: 90}.overage(1000))
+
+	full := usageMetric{Used: 120, Limit: &limit}
+	assert.Equal(t, int64(21), full.overage(1))
+}
+
+// testSubscription returns the subscription covering the test environment as
+// returned by the subscriptions API
+func testSubscription() map[string]any {
+	return map[string]any{
+		"id":                     "55555555-5555-5555-5555-555555555555",
+		"stripe_subscription_id": "sub_123",
+		"environment_ids":        []string{testEnvironmentID},
+		"status":                 "active",
+		"plan_name":              "Team",
+		"current_period_start":   1740830400,
+		"current_period_end":     1743508800,
+	}
+}
+
+func TestSubscriptionUsageCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/subscriptions", http.StatusOK, []map[string]any{testSubscription()})
+	srv.handle("GET /api/v1/entitlements", http.StatusOK, map[string]any{
+		"entitlements": map[string]any{
+			"entities": map[string]any{"limit_value": 1000, "current_usage": 250, "remaining": 750},
+			"seats":    map[string]any{"current_usage": 12},
+			"sso":      map[string]any{"enabled": true},
+		},
+	})
+
+	cfg.Output = "json"
+	cmd := SubscriptionUsageCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	request := srv.requireRequest(http.MethodGet, "/api/v1/entitlements")
+	assert.Equal(t, testEnvironmentID, request.Header.Get("Devgraph-Environment"))
+
+	var usage struct {
+		SubscriptionID string `json:"subscription_id"`
+		PeriodStart    string `json:"period_start"`
+		PeriodEnd      string `json:"period_end"`
+		Metrics        map[string]struct {
+			Used        int64    `json:"used"`
+			Limit       *int64   `json:"limit"`
+			PercentUsed *float64 `json:"percent_used"`
+		} `json:"metrics"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(output), &usage), output)
+	assert.Equal(t, "55555555-5555-5555-5555-555555555555", usage.SubscriptionID)
+	assert.Equal(t, "2025-03-01T12:00:00Z", usage.PeriodStart)
+	assert.Equal(t, "2025-04-01T12:00:00Z", usage.PeriodEnd)
+
+	require.Len(t, usage.Metrics, 2, "feature flags aren't metered")
+	entities := usage.Metrics["entities"]
+	assert.Equal(t, int64(250), entities.Used)
+	require.NotNil(t, entities.Limit)
+	assert.Equal(t, int64(1000), *entities.Limit)
+	require.NotNil(t, entities.PercentUsed)
+	assert.Equal(t, 25.0, *entities.PercentUsed)
+
+	seats := usage.Metrics["seats"]
+	assert.Equal(t, int64(12), seats.Used)
+	assert.Nil(t, seats.Limit)
+}
+
+func TestSubscriptionUsageCommand_NoSubscription(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/subscriptions", http.StatusOK, []map[string]any{})
+
+	cfg.Output = "json"
+	cmd := SubscriptionUsageCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}}
+	_, err := captureOutput(t, cmd.Run)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no subscription found")
+	assert.Empty(t, srv.received(http.MethodGet, "/api/v1/entitlements"))
+}
+
+func TestSubscriptionGetCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	sub := testSubscription()
+	sub["entitlements"] = []map[string]any{
+		{"entitlement_type": "seats", "limit_value": 25},
+		{"entitlement_type": "sso", "enabled": true},
+		{"entitlement_type": "entities", "limit_value": 1000},
+	}
+	srv.handle("GET /api/v1/subscriptions", http.StatusOK, []map[string]any{sub})
+
+	cfg.Output = "json"
+	cmd := SubscriptionGetCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, SubscriptionID: "sub_123"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	srv.requireRequest(http.MethodGet, "/api/v1/subscriptions")
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal([]byte(output), &got), output)
+	assert.Equal(t, "55555555-5555-5555-5555-555555555555", got["id"])
+	assert.Equal(t, "sub_123", got["stripe_subscription_id"])
+	assert.Equal(t, "Team", got["plan"])
+	assert.Equal(t, "2025-03-01", got["period_start"])
+	assert.Equal(t, "2025-04-01", got["period_end"])
+	assert.Equal(t, []any{testEnvironmentID}, got["environments"])
+	assert.Equal(t, []any{
+		map[string]any{"type": "entities", "limit": 1000.0},
+		map[string]any{"type": "seats", "limit": 25.0},
+		map[string]any{"type": "sso", "enabled": true},
+	}, got["entitlements"])
+}
+
+func TestSubscriptionGetCommand_NotFound(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/subscriptions", http.StatusOK, []map[string]any{testSubscription()})
+
+	cmd := SubscriptionGetCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, SubscriptionID: "sub_missing"}
+	_, err := captureOutput(t, cmd.Run)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "subscription 'sub_missing' not found")
+}
+
+func TestEntitlementOutputValue(t *testing.T) {
+	limit, enabled, disabled := 10, true, false
+	assert.Equal(t, "10", entitlementOutput{Limit: &limit}.value())
+	assert.Equal(t, "enabled", entitlementOutput{Enabled: &enabled}.value())
+	assert.Equal(t, "disabled", entitlementOutput{Enabled: &disabled}.value())
+	assert.Equal(t, "gpt-4o", entitlementOutput{Config: "gpt-4o"}.value())
+	assert.Equal(t, "-", entitlementOutput{}.value())
+}
+
+func TestCheckQuota(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entitlements", http.StatusOK, map[string]any{
+		"entitlements": map[string]any{
+			"entities": map[string]any{"limit_value": 100, "current_usage": 90},
+		},
+	})
+	client, err := util.GetAuthenticatedClient(cfg)
+	require.NoError(t, err)
+
+	require.NoError(t, checkQuota(client, "entities", 10, true))
+	require.NoError(t, checkQuota(client, "seats", 1000, true), "unmetered resources are unlimited")
+
+	logs := captureLogs(t)
+	_, err = captureOutput(t, func() error { return checkQuota(client, "entities", 15, false) })
+	require.NoError(t, err)
+	assert.Contains(t, logs.String(), "exceed the plan limit of 100 (90 in use) by 5")
+
+	err = checkQuota(client, "entities", 15, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--enforce-limits")
+
+	request := srv.received(http.MethodGet, "/api/v1/entitlements")[0]
+	assert.Equal(t, testEnvironmentID, request.Header.Get("Devgraph-Environment"))
+}
+
+func TestCheckQuota_LookupFailure(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/entitlements", http.StatusNotFound, nil)
+	client, err := util.GetAuthenticatedClient(cfg)
+	require.NoError(t, err)
+
+	logs := captureLogs(t)
+	_, err = captureOutput(t, func() error { return checkQuota(client, "entities", 10, false) })
+	require.NoError(t, err)
+	assert.Contains(t, logs.String(), "Warning: unable to check plan limits")
+
+	err = checkQuota(client, "entities", 10, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to check plan limits")
+}
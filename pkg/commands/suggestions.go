@@ -3,10 +3,13 @@ package commands
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/arctir/devgraph-cli/pkg/output"
 	"github.com/arctir/devgraph-cli/pkg/util"
 	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
 	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
 )
 
 // SuggestionCommand manages chat suggestions
@@ -14,18 +17,24 @@ type SuggestionCommand struct {
 	List   SuggestionListCommand   `cmd:"list" help:"List chat suggestions"`
 	Create SuggestionCreateCommand `cmd:"create" help:"Create a chat suggestion"`
 	Delete SuggestionDeleteCommand `cmd:"delete" help:"Delete a chat suggestion"`
+	Export SuggestionExportCommand `cmd:"export" help:"Export chat suggestions as YAML"`
+	Import SuggestionImportCommand `cmd:"import" help:"Create or replace chat suggestions from a YAML file"`
 }
 
 type SuggestionListCommand struct {
 	EnvWrapperCommand
-	Output string `short:"o" help:"Output format: table, json, yaml" default:"table"`
+	Search string `flag:"search" help:"Only show suggestions whose title, label, or action contains this text (case-insensitive)"`
+	Limit  int    `flag:"limit" default:"0" help:"Maximum number of suggestions to show (0 for no limit)"`
+	All    bool   `flag:"all" help:"Include inactive suggestions"`
+	Custom bool   `flag:"custom" help:"Only show this environment's own suggestions, not system ones"`
 }
 
 type SuggestionCreateCommand struct {
 	EnvWrapperCommand
-	Title  string `arg:"" required:"" help:"Suggestion title"`
-	Label  string `short:"l" required:"" help:"Button label for the suggestion"`
-	Action string `short:"a" required:"" help:"Action/prompt to execute when selected"`
+	Title    string `arg:"" required:"" help:"Suggestion title"`
+	Label    string `short:"l" required:"" help:"Button label for the suggestion"`
+	Action   string `short:"a" required:"" help:"Action/prompt to execute when selected"`
+	Inactive bool   `flag:"inactive" help:"Create the suggestion without surfacing it in chat"`
 }
 
 type SuggestionDeleteCommand struct {
@@ -33,7 +42,28 @@ type SuggestionDeleteCommand struct {
 	ID string `arg:"" required:"" help:"Suggestion ID to delete"`
 }
 
+// SuggestionExportCommand writes the environment's chat suggestions to stdout
+// in the format read by SuggestionImportCommand
+type SuggestionExportCommand struct {
+	EnvWrapperCommand
+}
+
+// SuggestionImportCommand syncs chat suggestions from a file written by
+// SuggestionExportCommand, matching existing suggestions by title
+type SuggestionImportCommand struct {
+	EnvWrapperCommand
+	File string `arg:"" required:"" help:"Path to the suggestions YAML file, or - to read it from stdin"`
+}
+
 func (s *SuggestionListCommand) Run() error {
+	format := s.OutputFormat(output.Table)
+	if err := output.Check(format, output.Table, output.JSON, output.YAML, output.Name); err != nil {
+		return err
+	}
+	if s.Limit < 0 {
+		return fmt.Errorf("--limit must not be negative")
+	}
+
 	client, err := util.GetAuthenticatedClient(s.Config)
 	if err != nil {
 		return err
@@ -42,6 +72,9 @@ func (s *SuggestionListCommand) Run() error {
 	ctx := context.TODO()
 
 	params := api.ListChatSuggestionsParams{}
+	if s.All {
+		params.ActiveOnly = api.NewOptBool(false)
+	}
 	resp, err := client.ListChatSuggestions(ctx, params)
 	if err != nil {
 		return err
@@ -50,42 +83,81 @@ func (s *SuggestionListCommand) Run() error {
 	switch r := resp.(type) {
 	case *api.ListChatSuggestionsOKApplicationJSON:
 		suggestions := []api.ChatSuggestionResponse(*r)
-		if len(suggestions) == 0 {
-			fmt.Println("No chat suggestions found.")
-			return nil
+		if s.Custom {
+			custom := []api.ChatSuggestionResponse{}
+			for _, sug := range suggestions {
+				if !sug.IsSystem.Value {
+					custom = append(custom, sug)
+				}
+			}
+			suggestions = custom
 		}
-
+		suggestions = filterSuggestions(suggestions, s.Search, s.Limit)
 		type suggestionOutput struct {
 			ID     string `json:"id" yaml:"id"`
 			Title  string `json:"title" yaml:"title"`
 			Label  string `json:"label" yaml:"label"`
 			Action string `json:"action" yaml:"action"`
+			Active bool   `json:"active" yaml:"active"`
+			System bool   `json:"system" yaml:"system"`
 		}
 
 		structured := make([]suggestionOutput, len(suggestions))
 		tableData := make([]map[string]any, len(suggestions))
+		names := make([]string, len(suggestions))
 		for i, sug := range suggestions {
+			spec := newSuggestionSpec(sug)
 			structured[i] = suggestionOutput{
 				ID:     sug.ID.String(),
 				Title:  sug.Title,
 				Label:  sug.Label,
 				Action: sug.Action,
+				Active: spec.Active,
+				System: sug.IsSystem.Value,
 			}
 			tableData[i] = map[string]any{
 				"ID":     sug.ID.String(),
 				"Title":  sug.Title,
 				"Label":  sug.Label,
 				"Action": sug.Action,
+				"Active": spec.Active,
+				"System": sug.IsSystem.Value,
 			}
+			names[i] = sug.ID.String()
 		}
 
-		headers := []string{"ID", "Title", "Label", "Action"}
-		return util.FormatOutput(s.Output, structured, headers, tableData)
+		return output.Print(format, output.Result{
+			Data:    structured,
+			Headers: []string{"ID", "Title", "Label", "Action", "Active", "System"},
+			Rows:    tableData,
+			Empty:   "No chat suggestions found.",
+			Names:   names,
+		})
 	default:
 		return fmt.Errorf("failed to list chat suggestions")
 	}
 }
 
+// filterSuggestions returns the suggestions matching search, up to limit of
+// them. An empty search matches everything and a limit of 0 means no limit.
+func filterSuggestions(suggestions []api.ChatSuggestionResponse, search string, limit int) []api.ChatSuggestionResponse {
+	search = strings.ToLower(search)
+	filtered := []api.ChatSuggestionResponse{}
+	for _, sug := range suggestions {
+		if limit > 0 && len(filtered) >= limit {
+			break
+		}
+		if search != "" &&
+			!strings.Contains(strings.ToLower(sug.Title), search) &&
+			!strings.Contains(strings.ToLower(sug.Label), search) &&
+			!strings.Contains(strings.ToLower(sug.Action), search) {
+			continue
+		}
+		filtered = append(filtered, sug)
+	}
+	return filtered
+}
+
 func (s *SuggestionCreateCommand) Run() error {
 	client, err := util.GetAuthenticatedClient(s.Config)
 	if err != nil {
@@ -98,6 +170,7 @@ func (s *SuggestionCreateCommand) Run() error {
 		Title:  s.Title,
 		Label:  s.Label,
 		Action: s.Action,
+		Active: api.NewOptBool(!s.Inactive),
 	}
 
 	resp, err := client.CreateChatSuggestion(ctx, &suggestion)
@@ -142,3 +215,197 @@ func (s *SuggestionDeleteCommand) Run() error {
 	}
 	return nil
 }
+
+// suggestionSpec is a chat suggestion as stored in an export file
+type suggestionSpec struct {
+	Title  string `json:"title" yaml:"title"`
+	Label  string `json:"label" yaml:"label"`
+	Action string `json:"action" yaml:"action"`
+	Active bool   `json:"active" yaml:"active"`
+}
+
+// newSuggestionSpec returns the exportable fields of a suggestion. The API
+// treats suggestions without an active flag as active.
+func newSuggestionSpec(sug api.ChatSuggestionResponse) suggestionSpec {
+	return suggestionSpec{
+		Title:  sug.Title,
+		Label:  sug.Label,
+		Action: sug.Action,
+		Active: !sug.Active.Set || sug.Active.Value,
+	}
+}
+
+// listSuggestions returns all chat suggestions in the environment, including
+// inactive ones
+func listSuggestions(ctx context.Context, client *api.Client) ([]api.ChatSuggestionResponse, error) {
+	resp, err := client.ListChatSuggestions(ctx, api.ListChatSuggestionsParams{
+		ActiveOnly: api.NewOptBool(false),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chat suggestions: %w", err)
+	}
+
+	switch r := resp.(type) {
+	case *api.ListChatSuggestionsOKApplicationJSON:
+		return []api.ChatSuggestionResponse(*r), nil
+	case *api.ListChatSuggestionsNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
+	}
+}
+
+func (s *SuggestionExportCommand) Run() error {
+	client, err := util.GetAuthenticatedClient(s.Config)
+	if err != nil {
+		return err
+	}
+
+	suggestions, err := listSuggestions(context.Background(), client)
+	if err != nil {
+		return err
+	}
+
+	// System suggestions are built in to every environment, so they are
+	// neither exported nor imported
+	specs := []suggestionSpec{}
+	for _, sug := range suggestions {
+		if sug.IsSystem.Value {
+			continue
+		}
+		specs = append(specs, newSuggestionSpec(sug))
+	}
+
+	return output.Print(output.YAML, output.Result{Data: specs})
+}
+
+// parseSuggestionSpecs reads and validates an export file
+func parseSuggestionSpecs(data []byte) ([]suggestionSpec, error) {
+	var specs []suggestionSpec
+	if err := yaml.Unmarshal(data, &specs); err != nil {
+		return nil, fmt.Errorf("failed to parse suggestions: %w", err)
+	}
+
+	titles := make(map[string]bool, len(specs))
+	for i, spec := range specs {
+		if spec.Title == "" || spec.Label == "" || spec.Action == "" {
+			return nil, fmt.Errorf("suggestion %d: title, label, and action are required", i+1)
+		}
+		if titles[spec.Title] {
+			return nil, fmt.Errorf("suggestion %d: duplicate title '%s'", i+1, spec.Title)
+		}
+		titles[spec.Title] = true
+	}
+	return specs, nil
+}
+
+func (s *SuggestionImportCommand) Run() error {
+	data, err := util.ReadFileOrStdin(s.File)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", s.File, err)
+	}
+	specs, err := parseSuggestionSpecs(data)
+	if err != nil {
+		return err
+	}
+
+	client, err := util.GetAuthenticatedClient(s.Config)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	suggestions, err := listSuggestions(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]api.ChatSuggestionResponse, len(suggestions))
+	for _, sug := range suggestions {
+		if !sug.IsSystem.Value {
+			existing[sug.Title] = sug
+		}
+	}
+
+	var created, replaced, unchanged int
+	for _, spec := range specs {
+		current, found := existing[spec.Title]
+		if found && newSuggestionSpec(current) == spec {
+			unchanged++
+			continue
+		}
+
+		if s.DryRun {
+			if found {
+				fmt.Printf("Would replace: %s\n", spec.Title)
+			} else {
+				fmt.Printf("Would create: %s\n", spec.Title)
+			}
+		} else if found {
+			// There is no update operation, so changed suggestions are
+			// replaced, which gives them a new ID
+			if err := deleteSuggestion(ctx, client, current.ID); err != nil {
+				return fmt.Errorf("failed to replace '%s': %w", spec.Title, err)
+			}
+			if err := createSuggestion(ctx, client, spec); err != nil {
+				return fmt.Errorf("failed to replace '%s': %w", spec.Title, err)
+			}
+			fmt.Printf("Replaced: %s\n", spec.Title)
+		} else {
+			if err := createSuggestion(ctx, client, spec); err != nil {
+				return fmt.Errorf("failed to create '%s': %w", spec.Title, err)
+			}
+			fmt.Printf("Created: %s\n", spec.Title)
+		}
+
+		if found {
+			replaced++
+		} else {
+			created++
+		}
+	}
+
+	if s.DryRun {
+		fmt.Printf("Dry run: %d to create, %d to replace, %d unchanged.\n", created, replaced, unchanged)
+		return nil
+	}
+	fmt.Printf("✅ Imported chat suggestions: %d created, %d replaced, %d unchanged.\n", created, replaced, unchanged)
+	return nil
+}
+
+// createSuggestion creates a chat suggestion from spec
+func createSuggestion(ctx context.Context, client *api.Client, spec suggestionSpec) error {
+	resp, err := client.CreateChatSuggestion(ctx, &api.ChatSuggestionCreate{
+		Title:  spec.Title,
+		Label:  spec.Label,
+		Action: spec.Action,
+		Active: api.NewOptBool(spec.Active),
+	})
+	if err != nil {
+		return err
+	}
+	switch r := resp.(type) {
+	case *api.ChatSuggestionResponse:
+		return nil
+	case *api.HTTPValidationError:
+		return fmt.Errorf("validation error: %v", r.Detail)
+	default:
+		return fmt.Errorf("unexpected response type: %T", resp)
+	}
+}
+
+// deleteSuggestion deletes the chat suggestion with the given ID
+func deleteSuggestion(ctx context.Context, client *api.Client, id uuid.UUID) error {
+	resp, err := client.DeleteChatSuggestion(ctx, api.DeleteChatSuggestionParams{SuggestionID: id})
+	if err != nil {
+		return err
+	}
+	switch r := resp.(type) {
+	case *api.DeleteChatSuggestionNoContent:
+		return nil
+	case *api.HTTPValidationError:
+		return fmt.Errorf("validation error: %v", r.Detail)
+	default:
+		return fmt.Errorf("unexpected response type: %T", resp)
+	}
+}
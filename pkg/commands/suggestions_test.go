@@ -0,0 +1,219 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSuggestion returns a chat suggestion as returned by the suggestions API
+func testSuggestion(id, title, action string) map[string]any {
+	return map[string]any{
+		"id":         id,
+		"title":      title,
+		"label":      title,
+		"action":     action,
+		"active":     true,
+		"is_system":  false,
+		"created_at": "2025-03-01T12:00:00Z",
+		"updated_at": "2025-03-01T12:00:00Z",
+	}
+}
+
+func TestSuggestionExportCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	system := testSuggestion("33333333-3333-3333-3333-333333333333", "Help", "What can you do?")
+	system["is_system"] = true
+	srv.handle("GET /api/v1/chat/suggestions", http.StatusOK, []map[string]any{
+		testSuggestion("44444444-4444-4444-4444-444444444444", "Owners", "Who owns this service?"),
+		system,
+	})
+
+	cmd := SuggestionExportCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	request := srv.requireRequest(http.MethodGet, "/api/v1/chat/suggestions")
+	assert.Equal(t, "active_only=false", request.Query)
+
+	specs, err := parseSuggestionSpecs([]byte(output))
+	require.NoError(t, err)
+	assert.Equal(t, []suggestionSpec{
+		{Title: "Owners", Label: "Owners", Action: "Who owns this service?", Active: true},
+	}, specs)
+}
+
+func TestSuggestionImportCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/chat/suggestions", http.StatusOK, []map[string]any{
+		testSuggestion("44444444-4444-4444-4444-444444444444", "Owners", "Who owns this service?"),
+		testSuggestion("55555555-5555-5555-5555-555555555555", "Incidents", "Show open incidents"),
+	})
+	srv.handle("POST /api/v1/chat/suggestions", http.StatusCreated,
+		testSuggestion("66666666-6666-6666-6666-666666666666", "New", "New"))
+	srv.handle("DELETE /api/v1/chat/suggestions/{id}", http.StatusNoContent, nil)
+
+	file := filepath.Join(t.TempDir(), "suggestions.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+- title: Owners
+  label: Owners
+  action: Who owns this service?
+  active: true
+- title: Incidents
+  label: Incidents
+  action: Show incidents from the last week
+  active: true
+- title: Deploys
+  label: Deploys
+  action: What was deployed today?
+  active: false
+`), 0o600))
+
+	cmd := SuggestionImportCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, File: file}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "1 created, 1 replaced, 1 unchanged")
+
+	srv.requireRequest(http.MethodDelete, "/api/v1/chat/suggestions/55555555-5555-5555-5555-555555555555")
+	creates := srv.received(http.MethodPost, "/api/v1/chat/suggestions")
+	require.Len(t, creates, 2)
+	assert.Equal(t, map[string]any{
+		"title":  "Incidents",
+		"label":  "Incidents",
+		"action": "Show incidents from the last week",
+		"active": true,
+	}, creates[0].JSON(t))
+	assert.Equal(t, false, creates[1].JSON(t)["active"])
+}
+
+func TestSuggestionImportCommand_Stdin(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/chat/suggestions", http.StatusOK, []map[string]any{})
+	srv.handle("POST /api/v1/chat/suggestions", http.StatusCreated,
+		testSuggestion("66666666-6666-6666-6666-666666666666", "Owners", "Who owns this service?"))
+	withStdin(t, "- title: Owners\n  label: Owners\n  action: Who owns this service?\n")
+
+	cmd := SuggestionImportCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, File: "-"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "1 created")
+
+	body := srv.requireRequest(http.MethodPost, "/api/v1/chat/suggestions").JSON(t)
+	assert.Equal(t, "Owners", body["title"])
+	assert.Equal(t, "Who owns this service?", body["action"])
+}
+
+func TestSuggestionImportCommand_DryRun(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/chat/suggestions", http.StatusOK, []map[string]any{})
+
+	file := filepath.Join(t.TempDir(), "suggestions.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("- {title: Owners, label: Owners, action: List owners}\n"), 0o600))
+
+	cmd := SuggestionImportCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, File: file}
+	cmd.DryRun = true
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Would create: Owners")
+	assert.Empty(t, srv.received(http.MethodPost, "/api/v1/chat/suggestions"))
+}
+
+func TestParseSuggestionSpecs(t *testing.T) {
+	_, err := parseSuggestionSpecs([]byte("- {title: A, label: A}\n"))
+	assert.ErrorContains(t, err, "suggestion 1: title, label, and action are required")
+
+	_, err = parseSuggestionSpecs([]byte("- {title: A, label: A, action: a}\n- {title: A, label: B, action: b}\n"))
+	assert.ErrorContains(t, err, "duplicate title 'A'")
+
+	_, err = parseSuggestionSpecs([]byte("title: A\n"))
+	assert.Error(t, err)
+}
+
+func TestSuggestionListCommand_Search(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /api/v1/chat/suggestions", http.StatusOK, []map[string]any{
+		testSuggestion("44444444-4444-4444-4444-444444444444", "Owners", "Who owns this service?"),
+		testSuggestion("55555555-5555-5555-5555-555555555555", "Incidents", "Show open incidents"),
+		testSuggestion("66666666-6666-6666-6666-666666666666", "Services", "List all services"),
+	})
+
+	cfg.Output = "json"
+	cmd := SuggestionListCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, Search: "SERVICE"}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	var listed []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(output), &listed), output)
+	require.Len(t, listed, 2)
+	assert.Equal(t, "Owners", listed[0]["title"])
+	assert.Equal(t, "Services", listed[1]["title"])
+
+	cmd.Limit = 1
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(output), &listed), output)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "Owners", listed[0]["title"])
+
+	cmd.Search = "nothing"
+	output, err = captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+	assert.JSONEq(t, "[]", output)
+}
+
+func TestSuggestionListCommand_InvalidFlags(t *testing.T) {
+	cmd := SuggestionListCommand{EnvWrapperCommand: EnvWrapperCommand{Config: config.Config{Output: "xml"}}}
+	assert.ErrorContains(t, cmd.Run(), "unsupported output format")
+
+	cmd = SuggestionListCommand{Limit: -1}
+	assert.ErrorContains(t, cmd.Run(), "--limit")
+}
+
+func TestSuggestionListCommand_AllCustom(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	system := testSuggestion("33333333-3333-3333-3333-333333333333", "Help", "What can you do?")
+	system["is_system"] = true
+	inactive := testSuggestion("44444444-4444-4444-4444-444444444444", "Owners", "Who owns this service?")
+	inactive["active"] = false
+	srv.handle("GET /api/v1/chat/suggestions", http.StatusOK, []map[string]any{system, inactive})
+
+	cfg.Output = "json"
+	cmd := SuggestionListCommand{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, All: true, Custom: true}
+	output, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	request := srv.requireRequest(http.MethodGet, "/api/v1/chat/suggestions")
+	assert.Equal(t, "active_only=false", request.Query)
+
+	var listed []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(output), &listed), output)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "Owners", listed[0]["title"])
+	assert.Equal(t, false, listed[0]["active"])
+	assert.Equal(t, false, listed[0]["system"])
+}
+
+func TestSuggestionCreateCommand_Inactive(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("POST /api/v1/chat/suggestions", http.StatusCreated,
+		testSuggestion("44444444-4444-4444-4444-444444444444", "Owners", "Who owns this service?"))
+
+	cmd := SuggestionCreateCommand{
+		EnvWrapperCommand: EnvWrapperCommand{Config: cfg},
+		Title:             "Owners",
+		Label:             "Owners",
+		Action:            "Who owns this service?",
+		Inactive:          true,
+	}
+	_, err := captureOutput(t, cmd.Run)
+	require.NoError(t, err)
+
+	body := srv.requireRequest(http.MethodPost, "/api/v1/chat/suggestions").JSON(t)
+	assert.Equal(t, false, body["active"])
+}
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/arctir/devgraph-cli/pkg/telemetry"
+	"github.com/fatih/color"
+)
+
+// TelemetryCommand manages anonymous usage reporting
+type TelemetryCommand struct {
+	Enable  TelemetryEnableCommand  `cmd:"enable" help:"Send anonymous usage data to help improve the CLI."`
+	Disable TelemetryDisableCommand `cmd:"disable" help:"Stop sending usage data."`
+	Status  TelemetryStatusCommand  `cmd:"status" help:"Show whether usage data is sent and what it contains."`
+	Send    TelemetrySendCommand    `cmd:"send" hidden:"" help:"Send a usage event. Commands run this in the background so they don't wait for it."`
+}
+
+type TelemetryEnableCommand struct {
+	Endpoint string `help:"URL to send usage events to. Required unless a telemetry_endpoint is already configured."`
+}
+
+type TelemetryDisableCommand struct{}
+
+type TelemetryStatusCommand struct{}
+
+// TelemetrySendCommand sends one event, as JSON, to the configured endpoint
+type TelemetrySendCommand struct {
+	Event string `arg:"" help:"Event to send, as JSON."`
+}
+
+func (t *TelemetryEnableCommand) Run() error {
+	if err := setTelemetry(true, t.Endpoint); err != nil {
+		return err
+	}
+	fmt.Println("✅ Telemetry enabled successfully")
+	printTelemetryContents()
+	return nil
+}
+
+func (t *TelemetryDisableCommand) Run() error {
+	if err := setTelemetry(false, ""); err != nil {
+		return err
+	}
+	fmt.Println("✅ Telemetry disabled successfully")
+	return nil
+}
+
+func (t *TelemetryStatusCommand) Run() error {
+	userConfig, err := config.LoadUserConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	switch {
+	case telemetry.Enabled(userConfig.Settings):
+		fmt.Println("Telemetry: " + color.GreenString("enabled"))
+		fmt.Printf("Endpoint: %s\n", userConfig.Settings.TelemetryEndpoint)
+		printTelemetryContents()
+	case userConfig.Settings.Telemetry && userConfig.Settings.TelemetryEndpoint == "":
+		fmt.Println("Telemetry: " + color.YellowString("disabled") + " (no telemetry_endpoint configured)")
+		fmt.Println("Run 'dg telemetry enable --endpoint <url>' to send anonymous usage data.")
+	case userConfig.Settings.Telemetry:
+		fmt.Println("Telemetry: " + color.YellowString("disabled") + " (by DO_NOT_TRACK or DEVGRAPH_TELEMETRY)")
+	default:
+		fmt.Println("Telemetry: " + color.YellowString("disabled"))
+		fmt.Println("Run 'dg telemetry enable' to send anonymous usage data.")
+	}
+	return nil
+}
+
+func (t *TelemetrySendCommand) Run() error {
+	var event telemetry.Event
+	if err := json.Unmarshal([]byte(t.Event), &event); err != nil {
+		return fmt.Errorf("invalid event: %w", err)
+	}
+
+	userConfig, err := config.LoadUserConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !telemetry.Enabled(userConfig.Settings) {
+		return nil
+	}
+
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	client, err := cfg.HTTPClient()
+	if err != nil {
+		return err
+	}
+	return telemetry.Send(client, userConfig.Settings.TelemetryEndpoint, event)
+}
+
+// setTelemetry turns telemetry on or off, and sets the endpoint events are
+// sent to when one is given. Enabling needs an endpoint, as there is no
+// default one.
+func setTelemetry(enabled bool, endpoint string) error {
+	userConfig, err := config.LoadUserConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if endpoint != "" {
+		userConfig.Settings.TelemetryEndpoint = endpoint
+	}
+	if enabled && userConfig.Settings.TelemetryEndpoint == "" {
+		return fmt.Errorf("no telemetry endpoint configured; pass --endpoint with the URL to send usage events to")
+	}
+	userConfig.Settings.Telemetry = enabled
+	if err := config.SaveUserConfig(userConfig); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+func printTelemetryContents() {
+	fmt.Println("Each command sends its name, duration, exit status and the CLI version.")
+	fmt.Println("Arguments, flags and output are never sent.")
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelemetryCommands(t *testing.T) {
+	t.Cleanup(setupTempConfig(t))
+	t.Setenv("DO_NOT_TRACK", "")
+	t.Setenv("DEVGRAPH_TELEMETRY", "")
+
+	output, err := captureOutput(t, (&TelemetryStatusCommand{}).Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Telemetry: disabled")
+
+	_, err = captureOutput(t, (&TelemetryEnableCommand{}).Run)
+	require.ErrorContains(t, err, "no telemetry endpoint configured")
+
+	output, err = captureOutput(t, (&TelemetryEnableCommand{Endpoint: "https://collector.example/events"}).Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "✅ Telemetry enabled successfully")
+	assert.Contains(t, output, "Arguments, flags and output are never sent")
+
+	userConfig, err := config.LoadUserConfig()
+	require.NoError(t, err)
+	assert.True(t, userConfig.Settings.Telemetry)
+
+	output, err = captureOutput(t, (&TelemetryStatusCommand{}).Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Telemetry: enabled")
+	assert.Contains(t, output, "Endpoint: https://collector.example/events")
+
+	t.Setenv("DO_NOT_TRACK", "1")
+	output, err = captureOutput(t, (&TelemetryStatusCommand{}).Run)
+	require.NoError(t, err)
+	assert.Contains(t, output, "disabled (by DO_NOT_TRACK or DEVGRAPH_TELEMETRY)")
+
+	_, err = captureOutput(t, (&TelemetryDisableCommand{}).Run)
+	require.NoError(t, err)
+	userConfig, err = config.LoadUserConfig()
+	require.NoError(t, err)
+	assert.False(t, userConfig.Settings.Telemetry)
+
+	// Re-enabling keeps the endpoint configured before
+	_, err = captureOutput(t, (&TelemetryEnableCommand{}).Run)
+	require.NoError(t, err)
+}
+
+func TestTelemetrySendCommand(t *testing.T) {
+	t.Cleanup(setupTempConfig(t))
+	t.Setenv("DO_NOT_TRACK", "")
+	t.Setenv("DEVGRAPH_TELEMETRY", "")
+
+	var requests []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, body)
+	}))
+	defer srv.Close()
+
+	event := `{"command":"entity list","duration_ms":20,"exit_status":0,"version":"dev"}`
+	require.NoError(t, (&TelemetrySendCommand{Event: event}).Run())
+	assert.Empty(t, requests, "nothing is sent until the user opts in")
+
+	require.NoError(t, config.SaveUserConfig(&config.UserConfig{Settings: config.UserSettings{Telemetry: true, TelemetryEndpoint: srv.URL}}))
+	require.NoError(t, (&TelemetrySendCommand{Event: event}).Run())
+	require.Len(t, requests, 1)
+	assert.Equal(t, "entity list", requests[0]["command"])
+
+	assert.ErrorContains(t, (&TelemetrySendCommand{Event: "{"}).Run(), "invalid event")
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/arctir/devgraph-cli/pkg/output"
 	"github.com/arctir/devgraph-cli/pkg/util"
 	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
 	"github.com/google/uuid"
@@ -31,7 +32,6 @@ type TokenGet struct {
 
 type TokenList struct {
 	EnvWrapperCommand
-	Output string `short:"o" help:"Output format: table, json, yaml" default:"table"`
 }
 
 type TokenUpdate struct {
@@ -124,53 +124,7 @@ func (a *TokenList) Run() error {
 	// Check the response type
 	switch r := response.(type) {
 	case *api.GetTokensOKApplicationJSON:
-		tokens := []api.ApiTokenResponse(*r)
-		if len(tokens) == 0 {
-			fmt.Println("No tokens found.")
-			return nil
-		}
-
-		type tokenOutput struct {
-			ID        string   `json:"id" yaml:"id"`
-			Name      string   `json:"name" yaml:"name"`
-			Scopes    []string `json:"scopes" yaml:"scopes"`
-			Token     string   `json:"token" yaml:"token"`
-			ExpiresAt string   `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
-		}
-
-		structured := make([]tokenOutput, len(tokens))
-		tableData := make([]map[string]any, len(tokens))
-		for i, token := range tokens {
-			expiresAt := "Never"
-			if expires, ok := token.ExpiresAt.Get(); ok && expires != "" {
-				expiresAt = expires
-			}
-
-			scopes := []string{}
-			scopesStr := "None"
-			if scopesArray, ok := token.Scopes.Get(); ok && len(scopesArray) > 0 {
-				scopes = scopesArray
-				scopesStr = strings.Join(scopesArray, ", ")
-			}
-
-			structured[i] = tokenOutput{
-				ID:        token.ID.String(),
-				Name:      token.Name,
-				Scopes:    scopes,
-				Token:     token.Token,
-				ExpiresAt: expiresAt,
-			}
-			tableData[i] = map[string]any{
-				"ID":         token.ID.String(),
-				"Name":       token.Name,
-				"Scopes":     scopesStr,
-				"Token":      token.Token,
-				"Expires At": expiresAt,
-			}
-		}
-
-		headers := []string{"ID", "Name", "Scopes", "Token", "Expires At"}
-		return util.FormatOutput(a.Output, structured, headers, tableData)
+		return output.Print(a.OutputFormat(output.Table), tokenResult(*r))
 	default:
 		return fmt.Errorf("failed to list tokens")
 	}
@@ -194,8 +148,9 @@ func (a *TokenGet) Run() error {
 		// Find the token with matching ID
 		for _, token := range tokens {
 			if token.ID.String() == a.ID {
-				displayTokens(&[]api.ApiTokenResponse{token})
-				return nil
+				result := tokenResult([]api.ApiTokenResponse{token})
+				result.Data = newTokenOutput(token)
+				return output.Print(a.OutputFormat(output.Table), result)
 			}
 		}
 		return fmt.Errorf("token with ID %s not found", a.ID)
@@ -293,30 +248,63 @@ func (a *TokenDelete) Run() error {
 	return nil
 }
 
-func displayTokens(tokens *[]api.ApiTokenResponse) {
-	headers := []string{"ID", "Name", "Scopes", "Token", "Expires At"}
+// tokenOutput is a token as printed as JSON or YAML
+type tokenOutput struct {
+	ID        string   `json:"id" yaml:"id"`
+	Name      string   `json:"name" yaml:"name"`
+	Scopes    []string `json:"scopes" yaml:"scopes"`
+	Token     string   `json:"token" yaml:"token"`
+	ExpiresAt string   `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+}
 
-	data := make([]map[string]interface{}, 0, len(*tokens))
-	for _, token := range *tokens {
-		expiresAt := "Never"
-		if expires, ok := token.ExpiresAt.Get(); ok && expires != "" {
-			expiresAt = expires
-		}
+func newTokenOutput(token api.ApiTokenResponse) tokenOutput {
+	expiresAt := "Never"
+	if expires, ok := token.ExpiresAt.Get(); ok && expires != "" {
+		expiresAt = expires
+	}
+	scopes, _ := token.Scopes.Get()
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return tokenOutput{
+		ID:        token.ID.String(),
+		Name:      token.Name,
+		Scopes:    scopes,
+		Token:     token.Token,
+		ExpiresAt: expiresAt,
+	}
+}
+
+// tokenResult is how tokens print in each output format. Tokens are named
+// by ID, which the other token commands take.
+func tokenResult(tokens []api.ApiTokenResponse) output.Result {
+	result := output.Result{
+		Headers: []string{"ID", "Name", "Scopes", "Token", "Expires At"},
+		Empty:   "No tokens found.",
+		Names:   []string{},
+	}
+	structured := make([]tokenOutput, len(tokens))
+	for i, token := range tokens {
+		structured[i] = newTokenOutput(token)
 
 		scopes := "None"
-		if scopesArray, ok := token.Scopes.Get(); ok && len(scopesArray) > 0 {
-			scopes = strings.Join(scopesArray, ", ")
+		if len(structured[i].Scopes) > 0 {
+			scopes = strings.Join(structured[i].Scopes, ", ")
 		}
-
-		data = append(data, map[string]interface{}{
-			"ID":         token.ID,
+		result.Rows = append(result.Rows, map[string]any{
+			"ID":         structured[i].ID,
 			"Name":       token.Name,
 			"Scopes":     scopes,
 			"Token":      token.Token,
-			"Expires At": expiresAt,
+			"Expires At": structured[i].ExpiresAt,
 		})
+		result.Names = append(result.Names, structured[i].ID)
 	}
+	result.Data = structured
+	return result
+}
 
-	util.DisplaySimpleTable(data, headers)
-
+func displayTokens(tokens *[]api.ApiTokenResponse) {
+	result := tokenResult(*tokens)
+	output.PrintTable(result.Headers, result.Rows)
 }
@@ -1,8 +1,11 @@
 package commands
 
 import (
+	"net/http"
 	"testing"
+	"time"
 
+	"github.com/arctir/devgraph-cli/pkg/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -396,3 +399,37 @@ func BenchmarkCheckScopeInput_Invalid(b *testing.B) {
 		checkScopeInput(scopes)
 	}
 }
+
+func TestTokenList_Timeout(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.mux.HandleFunc("GET /api/v1/tokens", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+
+	cfg.Timeout = 50 * time.Millisecond
+	cmd := TokenList{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}}
+
+	start := time.Now()
+	_, err := captureOutput(t, cmd.Run)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Client.Timeout exceeded")
+	assert.Less(t, time.Since(start), 2*time.Second)
+	srv.requireRequest(http.MethodGet, "/api/v1/tokens")
+}
+
+func TestTokenDelete_DryRun(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("DELETE /api/v1/tokens/{id}", http.StatusNoContent, nil)
+
+	cfg.DryRun = true
+	cmd := TokenDelete{EnvWrapperCommand: EnvWrapperCommand{Config: cfg}, ID: "550e8400-e29b-41d4-a716-446655440000"}
+
+	output, err := captureOutput(t, cmd.Run)
+	require.ErrorIs(t, err, util.ErrDryRun)
+	assert.Contains(t, output, "Dry run: Would delete (DELETE /api/v1/tokens/550e8400-e29b-41d4-a716-446655440000)")
+	assert.NotContains(t, output, "deleted successfully")
+	assert.Empty(t, srv.received(http.MethodDelete, "/api/v1/tokens/550e8400-e29b-41d4-a716-446655440000"))
+}
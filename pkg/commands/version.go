@@ -0,0 +1,290 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/arctir/devgraph-cli/pkg/logging"
+	"github.com/arctir/devgraph-cli/pkg/output"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+	"github.com/fatih/color"
+)
+
+// latestReleaseURL is where the newest CLI release is looked up
+var latestReleaseURL = "https://api.github.com/repos/arctir/devgraph-cli/releases/latest"
+
+// updateCheckInterval is how long a release lookup is reused before asking
+// again
+const updateCheckInterval = 24 * time.Hour
+
+// BuildInfo describes how the CLI binary was built. It's bound by main from
+// the values set with ldflags.
+type BuildInfo struct {
+	Version string
+	Commit  string
+	Date    string
+}
+
+// VersionCommand shows the CLI version and, unless --client is given, the
+// version of the API it's connected to
+type VersionCommand struct {
+	config.Config
+	Client      bool `help:"Only show the CLI version, without contacting the server"`
+	CheckUpdate bool `help:"Check whether a newer CLI release is available"`
+}
+
+// versionOutput is the json and yaml output of 'dg version'
+type versionOutput struct {
+	Version       string `json:"version" yaml:"version"`
+	Commit        string `json:"commit,omitempty" yaml:"commit,omitempty"`
+	Date          string `json:"date,omitempty" yaml:"date,omitempty"`
+	APIVersion    string `json:"api_version" yaml:"api_version"`
+	ServerURL     string `json:"server_url,omitempty" yaml:"server_url,omitempty"`
+	ServerVersion string `json:"server_version,omitempty" yaml:"server_version,omitempty"`
+	Compatible    *bool  `json:"compatible,omitempty" yaml:"compatible,omitempty"`
+	LatestVersion string `json:"latest_version,omitempty" yaml:"latest_version,omitempty"`
+	UpdateAvail   *bool  `json:"update_available,omitempty" yaml:"update_available,omitempty"`
+}
+
+// updateCheck is the cached result of the last release lookup
+type updateCheck struct {
+	CheckedAt     time.Time `json:"checked_at"`
+	LatestVersion string    `json:"latest_version"`
+}
+
+func (v *VersionCommand) Run(build BuildInfo) error {
+	format := v.OutputFormat(output.Table)
+	if err := output.Check(format, output.Table, output.JSON, output.YAML); err != nil {
+		return err
+	}
+
+	out := versionOutput{
+		Version:    build.Version,
+		APIVersion: api.APIVersion,
+	}
+	if build.Commit != "none" {
+		out.Commit = build.Commit
+	}
+	if build.Date != "unknown" {
+		out.Date = build.Date
+	}
+
+	client, err := v.Config.HTTPClient()
+	if err != nil {
+		return err
+	}
+
+	if !v.Client && v.ApiURL != "" {
+		out.ServerURL = v.ApiURL
+		serverVersion, err := fetchServerVersion(client, v.ApiURL)
+		if err != nil {
+			logging.Warn("failed to get server version", "error", err)
+		} else {
+			out.ServerVersion = serverVersion
+			compatible := apiCompatible(api.APIVersion, serverVersion)
+			out.Compatible = &compatible
+		}
+	}
+
+	if v.CheckUpdate {
+		latest, err := latestRelease(client)
+		if err != nil {
+			logging.Warn("failed to check for updates", "error", err)
+		} else {
+			out.LatestVersion = latest
+			available := build.Version != "dev" && compareVersions(latest, build.Version) > 0
+			out.UpdateAvail = &available
+		}
+	}
+
+	if format != output.Table {
+		return output.Print(format, output.Result{Data: out})
+	}
+	displayVersion(out)
+	return nil
+}
+
+func displayVersion(out versionOutput) {
+	fmt.Printf("devgraph version %s\n", out.Version)
+	if out.Commit != "" {
+		fmt.Printf("  commit: %s\n", out.Commit)
+	}
+	if out.Date != "" {
+		fmt.Printf("  built: %s\n", out.Date)
+	}
+	fmt.Printf("  api: %s\n", out.APIVersion)
+
+	if out.ServerVersion != "" {
+		fmt.Printf("server version %s (%s)\n", out.ServerVersion, out.ServerURL)
+		if out.Compatible != nil && !*out.Compatible {
+			fmt.Println(color.YellowString("⚠️  The server's API version is incompatible with this CLI. Some commands may fail."))
+		}
+	}
+
+	if out.UpdateAvail != nil {
+		if *out.UpdateAvail {
+			fmt.Println(color.YellowString("A newer release is available: %s", out.LatestVersion))
+		} else {
+			fmt.Println("✅ The CLI is up to date")
+		}
+	}
+}
+
+// fetchServerVersion returns the version of the API served at apiURL, as
+// published in its OpenAPI document
+func fetchServerVersion(client *http.Client, apiURL string) (string, error) {
+	var spec struct {
+		Info struct {
+			Version string `json:"version"`
+		} `json:"info"`
+	}
+	if err := getJSON(client, strings.TrimSuffix(apiURL, "/")+"/openapi.json", &spec); err != nil {
+		return "", err
+	}
+	if spec.Info.Version == "" {
+		return "", fmt.Errorf("server did not report a version")
+	}
+	return spec.Info.Version, nil
+}
+
+// latestRelease returns the version of the newest CLI release. Lookups are
+// cached in the config directory for updateCheckInterval.
+func latestRelease(client *http.Client) (string, error) {
+	cachePath := ""
+	if dir, err := config.GetUserConfigDir(); err == nil {
+		cachePath = filepath.Join(dir, "update-check.json")
+		var cached updateCheck
+		if data, err := os.ReadFile(cachePath); err == nil && json.Unmarshal(data, &cached) == nil {
+			if cached.LatestVersion != "" && time.Since(cached.CheckedAt) < updateCheckInterval {
+				return cached.LatestVersion, nil
+			}
+		}
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := getJSON(client, latestReleaseURL, &release); err != nil {
+		return "", err
+	}
+	latest := strings.TrimPrefix(release.TagName, "v")
+	if latest == "" {
+		return "", fmt.Errorf("release has no tag")
+	}
+
+	if cachePath != "" {
+		data, err := json.Marshal(updateCheck{CheckedAt: time.Now(), LatestVersion: latest})
+		if err == nil {
+			if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err == nil {
+				_ = os.WriteFile(cachePath, data, 0644)
+			}
+		}
+	}
+	return latest, nil
+}
+
+func getJSON(client *http.Client, url string, value any) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status from %s: %s", url, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(value); err != nil {
+		return fmt.Errorf("failed to parse response from %s: %w", url, err)
+	}
+	return nil
+}
+
+// apiCompatible reports whether a CLI built against clientVersion of the API
+// can talk to a server running serverVersion. Versions are compatible when
+// their major versions match.
+func apiCompatible(clientVersion, serverVersion string) bool {
+	client, _ := splitVersion(clientVersion)
+	server, _ := splitVersion(serverVersion)
+	return len(client) > 0 && len(server) > 0 && client[0] == server[0]
+}
+
+// compareVersions compares two semantic versions like "1.2.0" or
+// "1.0.0-beta.49", returning -1, 0 or 1
+func compareVersions(a, b string) int {
+	aCore, aPre := splitVersion(a)
+	bCore, bPre := splitVersion(b)
+
+	for i := 0; i < max(len(aCore), len(bCore)); i++ {
+		var x, y int
+		if i < len(aCore) {
+			x = aCore[i]
+		}
+		if i < len(bCore) {
+			y = bCore[i]
+		}
+		if x != y {
+			return compareInts(x, y)
+		}
+	}
+
+	// A release is newer than any of its pre-releases
+	switch {
+	case aPre == "" && bPre == "":
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+
+	aParts, bParts := strings.Split(aPre, "."), strings.Split(bPre, ".")
+	for i := 0; i < min(len(aParts), len(bParts)); i++ {
+		x, xErr := strconv.Atoi(aParts[i])
+		y, yErr := strconv.Atoi(bParts[i])
+		switch {
+		case xErr == nil && yErr == nil:
+			if x != y {
+				return compareInts(x, y)
+			}
+		case aParts[i] != bParts[i]:
+			return strings.Compare(aParts[i], bParts[i])
+		}
+	}
+	return compareInts(len(aParts), len(bParts))
+}
+
+// splitVersion returns the numeric parts and the pre-release of a version
+func splitVersion(version string) ([]int, string) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexByte(version, '+'); i >= 0 {
+		version = version[:i]
+	}
+	core, pre, _ := strings.Cut(version, "-")
+
+	var parts []int
+	for _, part := range strings.Split(core, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, ""
+		}
+		parts = append(parts, n)
+	}
+	return parts, pre
+}
+
+func compareInts(x, y int) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
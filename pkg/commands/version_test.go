@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testBuild = BuildInfo{Version: "1.2.0", Commit: "abc123", Date: "2025-01-01"}
+
+func TestVersionCommand(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /openapi.json", http.StatusOK, map[string]any{
+		"info": map[string]any{"version": api.APIVersion},
+	})
+
+	cmd := VersionCommand{Config: cfg}
+	output, err := captureOutput(t, func() error { return cmd.Run(testBuild) })
+	require.NoError(t, err)
+
+	srv.requireRequest(http.MethodGet, "/openapi.json")
+	assert.Contains(t, output, "devgraph version 1.2.0")
+	assert.Contains(t, output, "commit: abc123")
+	assert.Contains(t, output, "api: "+api.APIVersion)
+	assert.Contains(t, output, "server version "+api.APIVersion)
+	assert.NotContains(t, output, "incompatible")
+}
+
+func TestVersionCommand_Incompatible(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /openapi.json", http.StatusOK, map[string]any{
+		"info": map[string]any{"version": "99.0.0"},
+	})
+
+	cfg.Output = "json"
+	cmd := VersionCommand{Config: cfg}
+	output, err := captureOutput(t, func() error { return cmd.Run(testBuild) })
+	require.NoError(t, err)
+
+	var result map[string]any
+	require.NoError(t, json.Unmarshal([]byte(output), &result))
+	assert.Equal(t, "1.2.0", result["version"])
+	assert.Equal(t, "99.0.0", result["server_version"])
+	assert.Equal(t, false, result["compatible"])
+}
+
+func TestVersionCommand_ServerUnavailable(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /openapi.json", http.StatusNotFound, map[string]any{"detail": "Not Found"})
+	logs := captureLogs(t)
+
+	cmd := VersionCommand{Config: cfg}
+	output, err := captureOutput(t, func() error { return cmd.Run(testBuild) })
+	require.NoError(t, err)
+
+	assert.Contains(t, output, "devgraph version 1.2.0")
+	assert.NotContains(t, output, "server version")
+	assert.Contains(t, logs.String(), "Warning: failed to get server version")
+}
+
+func TestVersionCommand_ClientOnly(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+
+	cmd := VersionCommand{Config: cfg, Client: true}
+	_, err := captureOutput(t, func() error { return cmd.Run(testBuild) })
+	require.NoError(t, err)
+
+	assert.Empty(t, srv.received(http.MethodGet, "/openapi.json"))
+}
+
+func TestVersionCommand_CheckUpdate(t *testing.T) {
+	srv, cfg := newTestAPI(t)
+	srv.handle("GET /releases/latest", http.StatusOK, map[string]any{"tag_name": "v1.3.0"})
+
+	old := latestReleaseURL
+	latestReleaseURL = srv.server.URL + "/releases/latest"
+	t.Cleanup(func() { latestReleaseURL = old })
+
+	cfg.Output = "json"
+	cmd := VersionCommand{Config: cfg, Client: true, CheckUpdate: true}
+	output, err := captureOutput(t, func() error { return cmd.Run(testBuild) })
+	require.NoError(t, err)
+
+	var result map[string]any
+	require.NoError(t, json.Unmarshal([]byte(output), &result))
+	assert.Equal(t, "1.3.0", result["latest_version"])
+	assert.Equal(t, true, result["update_available"])
+
+	// The second check is answered from the cache
+	_, err = captureOutput(t, func() error { return cmd.Run(testBuild) })
+	require.NoError(t, err)
+	assert.Len(t, srv.received(http.MethodGet, "/releases/latest"), 1)
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.0", "1.2.0", 0},
+		{"v1.3.0", "1.2.9", 1},
+		{"1.2.0", "1.10.0", -1},
+		{"1.0.0", "1.0.0-beta.49", 1},
+		{"1.0.0-beta.49", "1.0.0-beta.5", 1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, compareVersions(tt.a, tt.b), "%s vs %s", tt.a, tt.b)
+	}
+
+	assert.True(t, apiCompatible("1.0.0-beta.49", "1.0.0-beta.52"))
+	assert.False(t, apiCompatible("1.0.0-beta.49", "2.0.0"))
+	assert.False(t, apiCompatible("1.0.0", "unknown"))
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// WebhookCommand works with webhook deliveries. The API doesn't expose
+// change events yet, so there's nothing to register webhooks with; listen
+// is for developing receivers against.
+type WebhookCommand struct {
+	Listen WebhookListenCommand `cmd:"listen" help:"Print webhook deliveries received on a local port."`
+}
+
+// WebhookListenCommand runs a local receiver that prints each request it
+// gets, for developing and debugging webhook consumers
+type WebhookListenCommand struct {
+	Address string `flag:"address" default:"127.0.0.1:8080" help:"Address to listen on."`
+	Status  int    `flag:"status" default:"204" help:"Status code to respond to deliveries with."`
+}
+
+func (w *WebhookListenCommand) Run() error {
+	if w.Status < 100 || w.Status > 599 {
+		return fmt.Errorf("invalid --status %d", w.Status)
+	}
+
+	listener, err := net.Listen("tcp", w.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", w.Address, err)
+	}
+	fmt.Printf("Listening for webhook deliveries on http://%s (Ctrl+C to stop)\n", listener.Addr())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return serveWebhooks(ctx, listener, os.Stdout, w.Status)
+}
+
+// serveWebhooks writes each request received on listener to out, responding
+// with status, until ctx is done
+func serveWebhooks(ctx context.Context, listener net.Listener, out io.Writer, status int) error {
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(rw, "failed to read body", http.StatusBadRequest)
+			return
+		}
+
+		fmt.Fprintf(out, "%s %s %s\n", time.Now().Format(time.RFC3339), r.Method, r.URL.RequestURI())
+		if contentType := r.Header.Get("Content-Type"); contentType != "" {
+			fmt.Fprintf(out, "Content-Type: %s\n", contentType)
+		}
+		var indented bytes.Buffer
+		if json.Indent(&indented, body, "", "  ") == nil {
+			body = indented.Bytes()
+		}
+		if len(body) > 0 {
+			fmt.Fprintf(out, "%s\n", bytes.TrimRight(body, "\n"))
+		}
+		fmt.Fprintln(out)
+		rw.WriteHeader(status)
+	})
+
+	server := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
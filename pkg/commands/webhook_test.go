@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer safe to write from a server goroutine
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestServeWebhooks(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var out syncBuffer
+	done := make(chan error, 1)
+	go func() { done <- serveWebhooks(ctx, listener, &out, http.StatusAccepted) }()
+
+	url := "http://" + listener.Addr().String()
+	resp, err := http.Post(url+"/hooks/devgraph?source=test", "application/json", strings.NewReader(`{"event":"entity.created","entity":{"name":"web"}}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	resp, err = http.Post(url, "text/plain", strings.NewReader("ping"))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	cancel()
+	require.NoError(t, <-done)
+
+	output := out.String()
+	assert.Contains(t, output, " POST /hooks/devgraph?source=test\nContent-Type: application/json\n{\n  \"event\": \"entity.created\",\n  \"entity\": {\n    \"name\": \"web\"\n  }\n}\n\n")
+	assert.Contains(t, output, " POST /\nContent-Type: text/plain\nping\n\n")
+}
+
+func TestWebhookListenCommand_InvalidStatus(t *testing.T) {
+	cmd := WebhookListenCommand{Address: "127.0.0.1:0", Status: 42}
+	assert.ErrorContains(t, cmd.Run(), "invalid --status 42")
+}
@@ -8,9 +8,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"gopkg.in/yaml.v3"
@@ -46,21 +48,140 @@ type Config struct {
 	IssuerURL string `kong:"-"`
 	ClientID  string `kong:"-"`
 
+	// EnvOverride is the UUID of an environment to use for API calls made
+	// with this config instead of the one from user settings
+	EnvOverride string `kong:"-"`
+
 	// Debug enables verbose HTTP request/response logging
 	Debug bool `kong:"short='d',help='Enable debug logging (HTTP requests/responses)'"`
+
+	// Verbose lowers the log level by one step per use: -v shows info
+	// messages and -vv debug messages
+	Verbose int `kong:"short='v',type='counter',help='Show more log messages (-v for info, -vv for debug)'"`
+
+	// LogLevel is the least severe level of message shown
+	LogLevel string `kong:"name='log-level',enum='error,warn,info,debug',default='warn',help='Log level: error, warn, info or debug'"`
+
+	// LogFormat is how log messages are written to stderr
+	LogFormat string `kong:"name='log-format',enum='text,json',default='text',help='Log format: text or json'"`
+
+	// DebugFile is where to write structured debug logs instead of stdout
+	DebugFile string `kong:"name='debug-file',type='path',help='Write structured debug logs (HTTP requests/responses) to a file'"`
+
+	// Output is the format commands print their results in. Empty means
+	// the command's own default. See OutputFormat.
+	Output string `kong:"short='o',placeholder='FORMAT',help='Output format: table, json, yaml, name, jsonpath=TEMPLATE or go-template=TEMPLATE (defaults to table, or what the command prints otherwise)'"`
+
+	// DryRun prints the changes a command would make instead of making them
+	DryRun bool `kong:"name='dry-run',help='Show what would be created, updated or deleted without changing anything'"`
+
+	// Offline keeps commands from reaching the API. Entity lookups read the
+	// snapshot saved by the last backup instead, and definition validation
+	// skips its checks against the server.
+	Offline bool `kong:"help='Work without the API: entity list, get and relationships read the last backup, and entity-definition validate skips server checks'"`
+
+	// Retries is how many times to retry API requests that fail transiently.
+	// A negative value means the retries setting, or DefaultRetries.
+	Retries int `kong:"default='-1',help='Times to retry API requests that fail transiently (defaults to the retries setting, or 3)'"`
+
+	// Timeout bounds each request to the API and identity provider. Zero
+	// means the timeout setting, or DefaultTimeout.
+	Timeout time.Duration `kong:"help='Time to wait for each API request before giving up, e.g. 10s or 2m (defaults to the timeout setting, or 30s)'"`
+
+	// MaxRequestsPerSecond caps how many API requests are sent each second.
+	// Zero means the max_requests_per_second setting, or no limit.
+	MaxRequestsPerSecond float64 `kong:"name='max-requests-per-second',placeholder='N',help='Most API requests to send per second (defaults to the max_requests_per_second setting, or no limit)'"`
+
+	// Workers is how many items commands that work through many of them
+	// handle at once. It is set from the default_workers setting, and
+	// commands' --workers flags override it. See WorkerCount.
+	Workers int `kong:"-"`
+
+	// CACert is a PEM file of certificate authorities to trust in addition
+	// to the system ones, for networks that intercept TLS
+	CACert string `kong:"name='ca-cert',type='path',help='PEM file of additional certificate authorities to trust (defaults to the cluster certificate-authority)'"`
+
+	// InsecureSkipTLSVerify disables certificate verification. It is set from
+	// the current context's cluster.
+	InsecureSkipTLSVerify bool `kong:"-"`
+
+	// ConsoleURL is the web console of the current context's cluster, if it
+	// was configured. See WebConsoleURL.
+	ConsoleURL string `kong:"-"`
+
+	// CacheTTL is how long cached GET responses are used without asking the
+	// API again. Zero disables the cache. It is set from the cache_ttl
+	// setting.
+	CacheTTL time.Duration `kong:"-"`
+
+	// CacheResponses lets GET responses for requests made with this config
+	// come from the cache. It is set for lookups repeated in quick
+	// succession, like completions and environment resolution.
+	CacheResponses bool `kong:"-"`
 }
 
+// DefaultRetries is the number of retries used when neither --retries nor
+// the retries setting is given
+const DefaultRetries = 3
+
+// DefaultTimeout is the request timeout used when neither --timeout nor the
+// timeout setting is given
+const DefaultTimeout = 30 * time.Second
+
+// DefaultWorkers is the number of concurrent workers used when neither
+// --workers nor the default_workers setting is given
+const DefaultWorkers = 10
+
+// DefaultCacheTTL is how long cached responses are used when the cache_ttl
+// setting isn't given
+const DefaultCacheTTL = time.Minute
+
 // ApplyDefaults populates the API/OAuth fields from the current context's cluster
 // Falls back to staging environment config if no context is configured
 func (c *Config) ApplyDefaults() {
 	// Try to load from current context's cluster
 	userConfig, err := LoadUserConfig()
+
+	if c.Retries < 0 {
+		c.Retries = DefaultRetries
+		if err == nil && userConfig.Settings.Retries != nil {
+			c.Retries = *userConfig.Settings.Retries
+		}
+	}
+
+	if c.Timeout <= 0 && err == nil && userConfig.Settings.Timeout != "" {
+		if timeout, parseErr := time.ParseDuration(userConfig.Settings.Timeout); parseErr == nil && timeout > 0 {
+			c.Timeout = timeout
+		}
+	}
+
+	c.Workers = DefaultWorkers
+	if err == nil && userConfig.Settings.DefaultWorkers > 0 {
+		c.Workers = userConfig.Settings.DefaultWorkers
+	}
+
+	if c.MaxRequestsPerSecond <= 0 && err == nil && userConfig.Settings.MaxRequestsPerSecond > 0 {
+		c.MaxRequestsPerSecond = userConfig.Settings.MaxRequestsPerSecond
+	}
+
+	c.CacheTTL = DefaultCacheTTL
+	if err == nil && userConfig.Settings.CacheTTL != "" {
+		if ttl, parseErr := time.ParseDuration(userConfig.Settings.CacheTTL); parseErr == nil && ttl >= 0 {
+			c.CacheTTL = ttl
+		}
+	}
+
 	if err == nil && userConfig.CurrentContext != "" {
 		_, cluster, _, err := userConfig.GetCurrentContext()
 		if err == nil && cluster != nil {
 			c.ApiURL = cluster.Server
 			c.IssuerURL = cluster.IssuerURL
 			c.ClientID = cluster.ClientID
+			if c.CACert == "" {
+				c.CACert = cluster.CertificateAuthority
+			}
+			c.InsecureSkipTLSVerify = cluster.InsecureSkipTLSVerify
+			c.ConsoleURL = cluster.ConsoleURL
 			return
 		}
 	}
@@ -72,6 +193,55 @@ func (c *Config) ApplyDefaults() {
 	c.ClientID = envConfig.ClientID
 }
 
+// WebConsoleURL returns the URL of the web console for the API, which is the
+// cluster's console-url if one is set. Otherwise it's the API URL with an
+// api. host prefix replaced by app., as with https://api.devgraph.ai and
+// https://app.devgraph.ai.
+func (c Config) WebConsoleURL() (string, error) {
+	if c.ConsoleURL != "" {
+		return strings.TrimSuffix(c.ConsoleURL, "/"), nil
+	}
+
+	apiURL, err := url.Parse(c.ApiURL)
+	if err != nil || apiURL.Host == "" {
+		return "", fmt.Errorf("invalid API URL %q", c.ApiURL)
+	}
+	if !strings.HasPrefix(apiURL.Host, "api.") {
+		return "", fmt.Errorf("can't tell the web console URL for %s: set it with 'dg config set-cluster <name> --console-url <url>'", c.ApiURL)
+	}
+	return apiURL.Scheme + "://app." + strings.TrimPrefix(apiURL.Host, "api."), nil
+}
+
+// RequestTimeout returns how long to wait for each request, falling back to
+// DefaultTimeout when no timeout was configured
+func (c Config) RequestTimeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return DefaultTimeout
+}
+
+// OutputFormat returns the format to print results in: Output when
+// --output was given, otherwise defaultFormat
+func (c Config) OutputFormat(defaultFormat string) string {
+	if c.Output != "" {
+		return c.Output
+	}
+	return defaultFormat
+}
+
+// WorkerCount returns how many workers to use: flag when a command's
+// --workers flag was given, otherwise Workers, or DefaultWorkers
+func (c Config) WorkerCount(flag int) int {
+	if flag > 0 {
+		return flag
+	}
+	if c.Workers > 0 {
+		return c.Workers
+	}
+	return DefaultWorkers
+}
+
 // UserConfig represents the unified user configuration file
 type UserConfig struct {
 	// User preferences
@@ -85,6 +255,63 @@ type UserConfig struct {
 	Clusters       map[string]*Cluster `yaml:"clusters,omitempty"`
 	Users          map[string]*User    `yaml:"users,omitempty"`
 	CurrentContext string              `yaml:"current-context,omitempty"`
+
+	// override is what an overriding context replaced. See OverrideContext.
+	override *contextOverrideState
+}
+
+// contextOverrideState records the values from the config file that an
+// overriding context replaced, to be saved in place of its own
+type contextOverrideState struct {
+	context     string
+	environment string
+
+	// overrideEnvironment is the overriding context's environment
+	overrideEnvironment string
+}
+
+// contextOverride names a context used in place of the current one for the
+// rest of the process. See OverrideContext.
+var contextOverride string
+
+// OverrideContext makes the named context current for the rest of the
+// process without changing the config file, so a command can target a
+// context other than the current one. The context's environment becomes the
+// default environment. An empty name removes the override.
+func OverrideContext(name string) error {
+	if name == "" {
+		contextOverride = ""
+		return nil
+	}
+
+	userConfig, err := LoadUserConfig()
+	if err != nil {
+		return err
+	}
+	if _, ok := userConfig.Contexts[name]; !ok {
+		return fmt.Errorf("context '%s' not found", name)
+	}
+	contextOverride = name
+	return nil
+}
+
+// applyContextOverride makes the overriding context current, remembering
+// what it replaced
+func (uc *UserConfig) applyContextOverride() {
+	context, ok := uc.Contexts[contextOverride]
+	if contextOverride == "" || !ok {
+		return
+	}
+
+	uc.override = &contextOverrideState{
+		context:             uc.CurrentContext,
+		environment:         uc.Settings.DefaultEnvironment,
+		overrideEnvironment: context.Environment,
+	}
+	uc.CurrentContext = contextOverride
+	if context.Environment != "" {
+		uc.Settings.DefaultEnvironment = context.Environment
+	}
 }
 
 // UserSettings represents persistent user preferences
@@ -92,6 +319,14 @@ type UserSettings struct {
 	DefaultEnvironment string `yaml:"default_environment,omitempty"`
 	DefaultModel       string `yaml:"default_model,omitempty"`
 	DefaultMaxTokens   int    `yaml:"default_max_tokens,omitempty"`
+	Retries            *int   `yaml:"retries,omitempty"`
+	Timeout            string `yaml:"timeout,omitempty"`
+	Telemetry          bool   `yaml:"telemetry,omitempty"`
+	TelemetryEndpoint  string `yaml:"telemetry_endpoint,omitempty"`
+	CacheTTL           string `yaml:"cache_ttl,omitempty"`
+
+	DefaultWorkers       int     `yaml:"default_workers,omitempty"`
+	MaxRequestsPerSecond float64 `yaml:"max_requests_per_second,omitempty"`
 }
 
 // Credentials represents authentication tokens
@@ -114,6 +349,16 @@ type Cluster struct {
 	Server    string `yaml:"server"`     // API URL
 	IssuerURL string `yaml:"issuer-url"` // OIDC issuer URL
 	ClientID  string `yaml:"client-id"`  // OAuth client ID
+
+	// CertificateAuthority is a PEM file of extra certificate authorities
+	// to trust when talking to the cluster
+	CertificateAuthority string `yaml:"certificate-authority,omitempty"`
+	// InsecureSkipTLSVerify disables certificate verification for the
+	// cluster. Only meant for testing against self-signed deployments.
+	InsecureSkipTLSVerify bool `yaml:"insecure-skip-tls-verify,omitempty"`
+	// ConsoleURL is the cluster's web console, for when it can't be told
+	// from the server URL
+	ConsoleURL string `yaml:"console-url,omitempty"`
 }
 
 // User defines authentication credentials for a user
@@ -201,6 +446,15 @@ func GetUserConfigDir() (string, error) {
 	return filepath.Join(configDir, "devgraph"), nil
 }
 
+// GetUserCacheDir returns the path to the user's cache directory
+func GetUserCacheDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user cache dir: %w", err)
+	}
+	return filepath.Join(cacheDir, "devgraph"), nil
+}
+
 // GetUserConfigPath returns the full path to the unified user config file
 func GetUserConfigPath() (string, error) {
 	configDir, err := GetUserConfigDir()
@@ -231,6 +485,7 @@ func LoadUserConfig() (*UserConfig, error) {
 	if err := yaml.Unmarshal(data, &userConfig); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal user config: %w", err)
 	}
+	userConfig.applyContextOverride()
 
 	return &userConfig, nil
 }
@@ -242,6 +497,19 @@ func SaveUserConfig(userConfig *UserConfig) error {
 		return err
 	}
 
+	// An overriding context only applies to this process, so what it
+	// replaced is saved unless it was changed since
+	if o := userConfig.override; o != nil {
+		saved := *userConfig
+		if saved.CurrentContext == contextOverride {
+			saved.CurrentContext = o.context
+		}
+		if o.overrideEnvironment != "" && saved.Settings.DefaultEnvironment == o.overrideEnvironment {
+			saved.Settings.DefaultEnvironment = o.environment
+		}
+		userConfig = &saved
+	}
+
 	data, err := yaml.Marshal(userConfig)
 	if err != nil {
 		return fmt.Errorf("failed to marshal user config: %w", err)
@@ -295,12 +563,12 @@ func SaveCredentials(creds Credentials) error {
 // FetchOIDCConfig fetches OIDC configuration from the API server
 // This replaces the hardcoded EnvironmentConfigMap by dynamically fetching
 // the issuer URL and client ID from the server
-func FetchOIDCConfig(apiURL string) (issuerURL string, clientID string, err error) {
+func FetchOIDCConfig(client *http.Client, apiURL string) (issuerURL string, clientID string, err error) {
 	// Construct the OIDC config endpoint URL
 	configURL := apiURL + "/api/v1/oauth/oidc-config"
 
 	// Make unauthenticated HTTP GET request
-	resp, err := http.Get(configURL)
+	resp, err := client.Get(configURL)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to fetch OIDC config from %s: %w", configURL, err)
 	}
@@ -1,9 +1,13 @@
 package config
 
 import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -125,3 +129,202 @@ func TestUserSettings(t *testing.T) {
 	assert.Equal(t, "gpt-4", settings.DefaultModel)
 	assert.Equal(t, 2000, settings.DefaultMaxTokens)
 }
+
+func TestApplyDefaults_Retries(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// No flag and no setting uses the default
+	cfg := Config{Retries: -1}
+	cfg.ApplyDefaults()
+	assert.Equal(t, DefaultRetries, cfg.Retries)
+
+	// The setting applies when the flag isn't given
+	retries := 5
+	require.NoError(t, SaveUserConfig(&UserConfig{Settings: UserSettings{Retries: &retries}}))
+	cfg = Config{Retries: -1}
+	cfg.ApplyDefaults()
+	assert.Equal(t, 5, cfg.Retries)
+
+	// The flag wins over the setting
+	cfg = Config{Retries: 0}
+	cfg.ApplyDefaults()
+	assert.Equal(t, 0, cfg.Retries)
+}
+
+func TestApplyDefaults_Timeout(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg := Config{}
+	cfg.ApplyDefaults()
+	assert.Equal(t, DefaultTimeout, cfg.RequestTimeout())
+
+	require.NoError(t, SaveUserConfig(&UserConfig{Settings: UserSettings{Timeout: "45s"}}))
+	cfg = Config{}
+	cfg.ApplyDefaults()
+	assert.Equal(t, 45*time.Second, cfg.RequestTimeout())
+
+	// The flag wins over the setting
+	cfg = Config{Timeout: 5 * time.Second}
+	cfg.ApplyDefaults()
+	assert.Equal(t, 5*time.Second, cfg.RequestTimeout())
+}
+
+func TestApplyDefaults_CacheTTL(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg := Config{}
+	cfg.ApplyDefaults()
+	assert.Equal(t, DefaultCacheTTL, cfg.CacheTTL)
+
+	require.NoError(t, SaveUserConfig(&UserConfig{Settings: UserSettings{CacheTTL: "5m"}}))
+	cfg = Config{}
+	cfg.ApplyDefaults()
+	assert.Equal(t, 5*time.Minute, cfg.CacheTTL)
+
+	// Zero turns the cache off
+	require.NoError(t, SaveUserConfig(&UserConfig{Settings: UserSettings{CacheTTL: "0"}}))
+	cfg = Config{}
+	cfg.ApplyDefaults()
+	assert.Zero(t, cfg.CacheTTL)
+}
+
+func TestApplyDefaults_Workers(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg := Config{}
+	cfg.ApplyDefaults()
+	assert.Equal(t, DefaultWorkers, cfg.WorkerCount(0))
+	assert.Zero(t, cfg.MaxRequestsPerSecond)
+
+	require.NoError(t, SaveUserConfig(&UserConfig{Settings: UserSettings{DefaultWorkers: 4, MaxRequestsPerSecond: 20}}))
+	cfg = Config{}
+	cfg.ApplyDefaults()
+	assert.Equal(t, 4, cfg.WorkerCount(0))
+	assert.Equal(t, 20.0, cfg.MaxRequestsPerSecond)
+
+	// Flags win over the settings
+	assert.Equal(t, 2, cfg.WorkerCount(2))
+	cfg = Config{MaxRequestsPerSecond: 5}
+	cfg.ApplyDefaults()
+	assert.Equal(t, 5.0, cfg.MaxRequestsPerSecond)
+
+	// A config that wasn't given defaults still has workers
+	assert.Equal(t, DefaultWorkers, Config{}.WorkerCount(0))
+}
+
+func TestHTTPClient_CACert(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	// The test server's certificate isn't trusted by default
+	client, err := Config{}.HTTPClient()
+	require.NoError(t, err)
+	_, err = client.Get(srv.URL)
+	assert.Error(t, err)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, cert, 0600))
+
+	client, err = Config{CACert: caFile}.HTTPClient()
+	require.NoError(t, err)
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	client, err = Config{InsecureSkipTLSVerify: true}.HTTPClient()
+	require.NoError(t, err)
+	resp, err = client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+func TestHTTPClient_InvalidCACert(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0600))
+
+	_, err := Config{CACert: caFile}.HTTPClient()
+	assert.ErrorContains(t, err, "no certificates found")
+
+	_, err = Config{CACert: filepath.Join(t.TempDir(), "missing.pem")}.HTTPClient()
+	assert.ErrorContains(t, err, "failed to read CA certificate")
+}
+
+func TestApplyDefaults_ClusterTLS(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	userConfig := &UserConfig{}
+	userConfig.SetCluster("corp", "https://api.corp.example", "https://issuer.corp.example", "client")
+	userConfig.Clusters["corp"].CertificateAuthority = "/etc/corp-ca.pem"
+	userConfig.Clusters["corp"].InsecureSkipTLSVerify = true
+	userConfig.SetUser("me", "", "", "", nil)
+	userConfig.SetContext("corp", "corp", "me", "")
+	require.NoError(t, userConfig.UseContext("corp"))
+	require.NoError(t, SaveUserConfig(userConfig))
+
+	cfg := Config{}
+	cfg.ApplyDefaults()
+	assert.Equal(t, "/etc/corp-ca.pem", cfg.CACert)
+	assert.True(t, cfg.InsecureSkipTLSVerify)
+
+	// --ca-cert wins over the cluster's
+	cfg = Config{CACert: "/tmp/mine.pem"}
+	cfg.ApplyDefaults()
+	assert.Equal(t, "/tmp/mine.pem", cfg.CACert)
+}
+
+func TestWebConsoleURL(t *testing.T) {
+	for _, tc := range []struct {
+		config   Config
+		expected string
+	}{
+		{Config{ApiURL: "https://api.devgraph.ai"}, "https://app.devgraph.ai"},
+		{Config{ApiURL: "https://api.staging.devgraph.ai/"}, "https://app.staging.devgraph.ai"},
+		{Config{ApiURL: "http://api.localhost:8000"}, "http://app.localhost:8000"},
+		{Config{ApiURL: "https://devgraph.corp.example", ConsoleURL: "https://ui.corp.example/"}, "https://ui.corp.example"},
+	} {
+		url, err := tc.config.WebConsoleURL()
+		require.NoError(t, err, tc.config.ApiURL)
+		assert.Equal(t, tc.expected, url)
+	}
+
+	_, err := Config{ApiURL: "https://devgraph.corp.example"}.WebConsoleURL()
+	assert.ErrorContains(t, err, "--console-url")
+}
+
+func TestOverrideContext(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Cleanup(func() { _ = OverrideContext("") })
+
+	userConfig := &UserConfig{}
+	userConfig.SetCluster("prod", "https://api.prod.example", "https://issuer.prod.example", "client")
+	userConfig.SetCluster("staging", "https://api.staging.example", "https://issuer.staging.example", "client")
+	userConfig.SetUser("me", "", "", "", nil)
+	userConfig.SetContext("prod", "prod", "me", "prod-env")
+	userConfig.SetContext("staging", "staging", "me", "staging-env")
+	require.NoError(t, userConfig.UseContext("prod"))
+	userConfig.Settings.DefaultEnvironment = "prod-env"
+	require.NoError(t, SaveUserConfig(userConfig))
+
+	assert.EqualError(t, OverrideContext("qa"), "context 'qa' not found")
+
+	require.NoError(t, OverrideContext("staging"))
+	cfg := Config{}
+	cfg.ApplyDefaults()
+	assert.Equal(t, "https://api.staging.example", cfg.ApiURL)
+
+	loaded, err := LoadUserConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "staging", loaded.CurrentContext)
+	assert.Equal(t, "staging-env", loaded.Settings.DefaultEnvironment)
+
+	// Saving keeps the file's current context, along with other changes
+	loaded.Settings.Timeout = "10s"
+	require.NoError(t, SaveUserConfig(loaded))
+	require.NoError(t, OverrideContext(""))
+	loaded, err = LoadUserConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "prod", loaded.CurrentContext)
+	assert.Equal(t, "prod-env", loaded.Settings.DefaultEnvironment)
+	assert.Equal(t, "10s", loaded.Settings.Timeout)
+}
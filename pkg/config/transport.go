@@ -0,0 +1,54 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// HTTPTransport returns the transport used for requests to Devgraph, its
+// identity provider and the services the CLI checks on the user's behalf.
+// Proxies are taken from HTTPS_PROXY, HTTP_PROXY and NO_PROXY, and CACert is
+// trusted in addition to the system certificate authorities.
+func (c Config) HTTPTransport() (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	if c.CACert == "" && !c.InsecureSkipTLSVerify {
+		return transport, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.InsecureSkipTLSVerify, // #nosec G402 - opted into per cluster
+	}
+	if c.CACert != "" {
+		pem, err := os.ReadFile(c.CACert) // #nosec G304 - path is provided by the user
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", c.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
+
+// HTTPClient returns a client using HTTPTransport that gives up on requests
+// after RequestTimeout
+func (c Config) HTTPClient() (*http.Client, error) {
+	transport, err := c.HTTPTransport()
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport, Timeout: c.RequestTimeout()}, nil
+}
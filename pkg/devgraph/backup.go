@@ -0,0 +1,167 @@
+package devgraph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/arctir/devgraph-cli/pkg/logging"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+	"gopkg.in/yaml.v3"
+)
+
+// BackupOptions choose what BackupEnvironment writes. Definitions and
+// relations are always backed up in full; the filters apply to entities.
+type BackupOptions struct {
+	// Format is json or yaml, the default
+	Format string
+
+	Name          string
+	Label         string
+	FieldSelector string
+
+	// Progress, if set, is called with the number of entities read so far
+	// as pages of them are read
+	Progress func(entities int)
+}
+
+// BackupResult describes a backup written by BackupEnvironment
+type BackupResult struct {
+	// Definitions, Entities and Relations are the numbers written
+	Definitions int
+	Entities    int
+	Relations   int
+
+	// Catalog and CatalogRelations are every entity in the environment and
+	// the relations between them, whatever the filters. They're nil if the
+	// catalog couldn't be read, in which case no relations were written.
+	Catalog          []api.EntityResponse
+	CatalogRelations []api.EntityRelationResponse
+}
+
+// BackupEnvironment writes the client's environment to dir in the layout
+// 'dg entity restore' reads: a file per definition in definitions/, a file
+// per entity in entities/, and every relation in relations/. Files that
+// can't be written are logged and skipped.
+func BackupEnvironment(ctx context.Context, client *api.Client, dir string, opts BackupOptions) (*BackupResult, error) {
+	format := opts.Format
+	if format == "" {
+		format = "yaml"
+	}
+	if format != "json" && format != "yaml" {
+		return nil, fmt.Errorf("unsupported format: %s (use json or yaml)", format)
+	}
+	marshal := func(value any) ([]byte, error) {
+		if format == "json" {
+			return json.MarshalIndent(value, "", "  ")
+		}
+		return yaml.Marshal(value)
+	}
+	ext := "." + format
+
+	// Create backup directory structure
+	definitionsDir := filepath.Join(dir, "definitions")
+	entitiesDir := filepath.Join(dir, "entities")
+	relationsDir := filepath.Join(dir, "relations")
+	for _, d := range []string{dir, definitionsDir, entitiesDir, relationsDir} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create backup directory: %w", err)
+		}
+	}
+
+	var result BackupResult
+
+	// Fetch and backup entity definitions
+	defResp, err := client.GetEntityDefinitions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity definitions: %w", err)
+	}
+	var definitions []api.EntityDefinitionResponse
+	switch r := defResp.(type) {
+	case *api.GetEntityDefinitionsOKApplicationJSON:
+		definitions = *r
+	case *api.GetEntityDefinitionsNotFound:
+	default:
+		return nil, fmt.Errorf("unexpected response type for definitions: %T", defResp)
+	}
+
+	for _, def := range definitions {
+		// <group>_<kind>.<ext>
+		filename := fmt.Sprintf("%s_%s%s", def.Group, strings.ToLower(def.Kind), ext)
+		if err := writeBackupFile(filepath.Join(definitionsDir, filename), NewEntityDefinition(def), marshal); err != nil {
+			logging.Warn("failed to write definition", "definition", def.Group+"/"+def.Kind, "error", err)
+			continue
+		}
+		result.Definitions++
+	}
+
+	// Fetch and backup the entities matching the filters
+	params := api.GetEntitiesParams{}
+	if opts.Name != "" {
+		params.Name = api.NewOptString(opts.Name)
+	}
+	if opts.Label != "" {
+		params.Label = api.NewOptString(opts.Label)
+	}
+	if opts.FieldSelector != "" {
+		params.FieldSelector = api.NewOptString(opts.FieldSelector)
+	}
+	entities, _, err := ListAllEntitiesWithProgress(ctx, client, params, opts.Progress)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entity := range entities {
+		// <group>_<version>_<namespace>_<kind>_<name>.<ext>
+		filename := fmt.Sprintf("%s_%s_%s_%s_%s%s",
+			entity.Group,
+			entity.Version,
+			entity.Namespace,
+			strings.ToLower(entity.Kind),
+			entity.Name,
+			ext)
+		if err := writeBackupFile(filepath.Join(entitiesDir, filename), NewEntity(entity), marshal); err != nil {
+			logging.Warn("failed to write entity", "entity", entity.Namespace+"/"+entity.Name, "error", err)
+			continue
+		}
+		result.Entities++
+	}
+
+	// Relations aren't filtered, so read them from the whole catalog
+	catalog, relations, err := ListAllEntities(ctx, client, api.GetEntitiesParams{})
+	if err != nil {
+		logging.Warn("failed to get relations", "error", err)
+		return &result, nil
+	}
+	if catalog == nil {
+		catalog = []api.EntityResponse{}
+	}
+	result.Catalog = catalog
+	result.CatalogRelations = relations
+
+	if len(relations) > 0 {
+		var filtered []EntityRelation
+		for _, rel := range relations {
+			filtered = append(filtered, NewEntityRelation(rel))
+		}
+		if err := writeBackupFile(filepath.Join(relationsDir, "relations"+ext), filtered, marshal); err != nil {
+			logging.Warn("failed to write relations", "error", err)
+		} else {
+			result.Relations = len(filtered)
+		}
+	}
+
+	return &result, nil
+}
+
+// writeBackupFile writes value to path with marshal
+func writeBackupFile(path string, value any, marshal func(any) ([]byte, error)) error {
+	data, err := marshal(value)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,83 @@
+package devgraph
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestBackupEnvironment(t *testing.T) {
+	srv := newTestServer(t)
+	srv.handle("GET /api/v1/entities/definitions", []map[string]any{{
+		"id":          "00000000-0000-0000-0000-000000000001",
+		"group":       "core",
+		"kind":        "Service",
+		"list_kind":   "ServiceList",
+		"plural":      "services",
+		"singular":    "service",
+		"description": "A service",
+		"spec":        map[string]any{"type": "object"},
+	}})
+	srv.mux.HandleFunc("GET /api/v1/entities/", func(w http.ResponseWriter, r *http.Request) {
+		entities := []any{testEntity("web"), testEntity("api")}
+		if r.URL.Query().Get("label") != "" {
+			entities = entities[:1]
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"primary_entities": entities,
+			"relations":        []any{testRelation("DEPENDS_ON", "web", "api")},
+		})
+	})
+
+	dir := t.TempDir()
+	var progress []int
+	result, err := BackupEnvironment(context.Background(), srv.client(t), dir, BackupOptions{
+		Label:    "team=web",
+		Progress: func(entities int) { progress = append(progress, entities) },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, progress, "progress is reported for the entities written")
+	assert.Equal(t, 1, result.Definitions)
+	assert.Equal(t, 1, result.Entities)
+	assert.Equal(t, 1, result.Relations)
+	assert.Len(t, result.Catalog, 2, "the whole catalog is read for relations")
+
+	// Filters apply to the entities written
+	requests := srv.received(http.MethodGet, "/api/v1/entities/")
+	require.Len(t, requests, 2)
+	assert.Equal(t, "label=team%3Dweb&limit=1000&offset=0", requests[0].Query)
+	assert.Equal(t, "limit=1000&offset=0", requests[1].Query)
+
+	assert.FileExists(t, filepath.Join(dir, "definitions", "core_service.yaml"))
+	data, err := os.ReadFile(filepath.Join(dir, "entities", "core_v1_default_service_web.yaml"))
+	require.NoError(t, err)
+	var entity Entity
+	require.NoError(t, yaml.Unmarshal(data, &entity))
+	assert.Equal(t, "Service", entity.Kind)
+	assert.Equal(t, map[string]any{"name": "web", "namespace": "default"}, entity.Metadata)
+
+	data, err = os.ReadFile(filepath.Join(dir, "relations", "relations.yaml"))
+	require.NoError(t, err)
+	var relations []EntityRelation
+	require.NoError(t, yaml.Unmarshal(data, &relations))
+	assert.Equal(t, []EntityRelation{{
+		Namespace: "default",
+		Relation:  "DEPENDS_ON",
+		Source:    "core/v1/service/default/web",
+		Target:    "core/v1/service/default/api",
+	}}, relations)
+}
+
+func TestBackupEnvironment_Format(t *testing.T) {
+	srv := newTestServer(t)
+
+	_, err := BackupEnvironment(context.Background(), srv.client(t), t.TempDir(), BackupOptions{Format: "toml"})
+	assert.EqualError(t, err, "unsupported format: toml (use json or yaml)")
+	assert.Empty(t, srv.received(http.MethodGet, "/api/v1/entities/definitions"))
+}
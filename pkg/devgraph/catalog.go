@@ -0,0 +1,274 @@
+package devgraph
+
+import (
+	"encoding/json"
+
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+)
+
+// Entity is an entity as written to backups: the fields needed to create it
+// again, without those the API fills in
+type Entity struct {
+	ApiVersion string      `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string      `json:"kind" yaml:"kind"`
+	Metadata   interface{} `json:"metadata" yaml:"metadata"`
+	Spec       interface{} `json:"spec,omitempty" yaml:"spec,omitempty"`
+	Status     interface{} `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+// EntityDefinition is an entity definition as written to backups
+type EntityDefinition struct {
+	Group       string      `json:"group" yaml:"group"`
+	Kind        string      `json:"kind" yaml:"kind"`
+	ListKind    string      `json:"listKind" yaml:"listKind"`
+	Plural      string      `json:"plural,omitempty" yaml:"plural,omitempty"`
+	Singular    string      `json:"singular" yaml:"singular"`
+	Name        string      `json:"name,omitempty" yaml:"name,omitempty"`
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Spec        interface{} `json:"spec" yaml:"spec"`
+	Storage     bool        `json:"storage,omitempty" yaml:"storage,omitempty"`
+	Served      bool        `json:"served,omitempty" yaml:"served,omitempty"`
+}
+
+// EntityRelation is a relation between two entities as written to backups
+type EntityRelation struct {
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Relation  string `json:"relation" yaml:"relation"`
+	Source    string `json:"source" yaml:"source"`
+	Target    string `json:"target" yaml:"target"`
+}
+
+// NewEntity returns the backup form of entity
+func NewEntity(entity api.EntityResponse) Entity {
+	filtered := Entity{
+		ApiVersion: entity.ApiVersion,
+		Kind:       entity.Kind,
+		Metadata:   cleanMetadata(entity.Metadata),
+	}
+
+	// Extract actual values from optional types
+	if entity.Spec.IsSet() {
+		if spec, ok := entity.Spec.Get(); ok {
+			filtered.Spec = CleanSpec(spec)
+		}
+	}
+
+	if entity.Status.IsSet() {
+		if status, ok := entity.Status.Get(); ok {
+			filtered.Status = cleanStatus(status)
+		}
+	}
+
+	return filtered
+}
+
+// NewEntityDefinition returns the backup form of def
+func NewEntityDefinition(def api.EntityDefinitionResponse) EntityDefinition {
+	filtered := EntityDefinition{
+		Group:    def.Group,
+		Kind:     def.Kind,
+		ListKind: def.ListKind,
+		Singular: def.Singular,
+		Spec:     CleanDefinitionSpec(def.Spec),
+	}
+
+	// Handle optional plural
+	if def.Plural.IsSet() {
+		if plural, ok := def.Plural.Get(); ok {
+			filtered.Plural = plural
+		}
+	}
+
+	// Handle optional name
+	if def.Name.IsSet() {
+		if name, ok := def.Name.Get(); ok {
+			filtered.Name = name
+		}
+	}
+
+	// Handle optional description
+	if def.Description.IsSet() {
+		if desc, ok := def.Description.Get(); ok {
+			filtered.Description = desc
+		}
+	}
+
+	// Handle optional storage
+	if def.Storage.IsSet() {
+		if storage, ok := def.Storage.Get(); ok {
+			filtered.Storage = storage
+		}
+	}
+
+	// Handle optional served
+	if def.Served.IsSet() {
+		if served, ok := def.Served.Get(); ok {
+			filtered.Served = served
+		}
+	}
+
+	return filtered
+}
+
+// CleanDefinitionSpec returns a definition spec as plain values, for
+// encoding as JSON or YAML
+func CleanDefinitionSpec(spec api.EntityDefinitionResponseSpec) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	// Convert spec to map for processing
+	specBytes, err := json.Marshal(spec)
+	if err != nil {
+		return result
+	}
+
+	var specMap map[string]interface{}
+	if err := json.Unmarshal(specBytes, &specMap); err != nil {
+		return result
+	}
+
+	// Process each field to clean up byte arrays and optional wrappers
+	for key, value := range specMap {
+		result[key] = cleanValue(value)
+	}
+
+	return result
+}
+
+// NewEntityRelation returns the backup form of rel
+func NewEntityRelation(rel api.EntityRelationResponse) EntityRelation {
+	filtered := EntityRelation{
+		Relation: rel.Relation,
+		Source:   rel.Source.ID,
+		Target:   rel.Target.ID,
+	}
+
+	// Handle optional namespace
+	if rel.Namespace.IsSet() {
+		if ns, ok := rel.Namespace.Get(); ok {
+			filtered.Namespace = ns
+		}
+	}
+
+	return filtered
+}
+
+// cleanMetadata removes the 'set' wrapper from optional fields in metadata
+func cleanMetadata(metadata api.EntityMetadata) map[string]interface{} {
+	result := map[string]interface{}{
+		"name":      metadata.Name,
+		"namespace": metadata.Namespace,
+	}
+
+	// Handle optional labels
+	if metadata.Labels.IsSet() {
+		if labels, ok := metadata.Labels.Get(); ok && len(labels) > 0 {
+			result["labels"] = labels
+		}
+	}
+
+	// Handle optional annotations
+	if metadata.Annotations.IsSet() {
+		if annotations, ok := metadata.Annotations.Get(); ok && len(annotations) > 0 {
+			result["annotations"] = annotations
+		}
+	}
+
+	return result
+}
+
+// CleanSpec returns an entity spec as plain values, for encoding as JSON or
+// YAML
+func CleanSpec(spec api.EntityResponseSpec) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	// Convert spec to map for processing
+	specBytes, err := json.Marshal(spec)
+	if err != nil {
+		return result
+	}
+
+	var specMap map[string]interface{}
+	if err := json.Unmarshal(specBytes, &specMap); err != nil {
+		return result
+	}
+
+	// Process each field to clean up byte arrays and optional wrappers
+	for key, value := range specMap {
+		result[key] = cleanValue(value)
+	}
+
+	return result
+}
+
+// cleanStatus processes status fields to ensure proper serialization
+func cleanStatus(status api.EntityStatus) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	// Convert status to map for processing
+	statusBytes, err := json.Marshal(status)
+	if err != nil {
+		return result
+	}
+
+	var statusMap map[string]interface{}
+	if err := json.Unmarshal(statusBytes, &statusMap); err != nil {
+		return result
+	}
+
+	// Process each field to clean up byte arrays and optional wrappers
+	for key, value := range statusMap {
+		result[key] = cleanValue(value)
+	}
+
+	return result
+}
+
+// cleanValue recursively cleans values by converting byte arrays to strings
+// and removing 'set' wrappers from optional fields
+func cleanValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		// Check if this is an optional wrapper with 'set' and 'value' fields
+		if set, hasSet := v["set"].(bool); hasSet && set {
+			if val, hasValue := v["value"]; hasValue {
+				return cleanValue(val)
+			}
+		}
+
+		// Otherwise, recursively clean the map
+		cleaned := make(map[string]interface{})
+		for key, val := range v {
+			cleaned[key] = cleanValue(val)
+		}
+		return cleaned
+
+	case []interface{}:
+		// Check if this looks like a byte array (all numbers 0-255)
+		if len(v) > 0 {
+			allBytes := true
+			bytes := make([]byte, len(v))
+			for i, item := range v {
+				if num, ok := item.(float64); ok && num >= 0 && num <= 255 && num == float64(int(num)) {
+					bytes[i] = byte(num)
+				} else {
+					allBytes = false
+					break
+				}
+			}
+
+			if allBytes {
+				return string(bytes)
+			}
+		}
+
+		// Otherwise, recursively clean the array
+		cleaned := make([]interface{}, len(v))
+		for i, val := range v {
+			cleaned[i] = cleanValue(val)
+		}
+		return cleaned
+
+	default:
+		return v
+	}
+}
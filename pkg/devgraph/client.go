@@ -0,0 +1,92 @@
+// Package devgraph gives Go programs the Devgraph access dg itself uses:
+// clients authenticated with the credentials from 'dg auth login', the
+// current context's cluster and environment, and catalog operations like
+// reading every entity, applying entities and backing up an environment.
+//
+//	client, err := devgraph.NewClient(devgraph.Options{Environment: "staging"})
+//	if err != nil {
+//		return err
+//	}
+//	entities, relations, err := devgraph.ListAllEntities(ctx, client, api.GetEntitiesParams{})
+package devgraph
+
+import (
+	"errors"
+	"time"
+
+	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/arctir/devgraph-cli/pkg/util"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+)
+
+// ErrNotLoggedIn is returned by NewClient when there are no usable
+// credentials
+var ErrNotLoggedIn = errors.New("not logged in: run 'dg auth login'")
+
+// Options choose how a client reaches Devgraph. The zero value uses the
+// current context and its environment, like dg commands run without flags.
+type Options struct {
+	// Environment is the name, slug or UUID of the environment to use
+	// instead of the current context's
+	Environment string
+
+	// Retries is how many times to retry requests that fail transiently.
+	// Zero means the retries setting, or config.DefaultRetries, and a
+	// negative value turns retries off.
+	Retries int
+
+	// Timeout bounds each request. Zero means the timeout setting, or
+	// config.DefaultTimeout.
+	Timeout time.Duration
+}
+
+// NewClient returns an API client for the environment opts choose,
+// authenticated as the logged in user
+func NewClient(opts Options) (*api.Client, error) {
+	cfg, err := ResolveConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	return util.GetAuthenticatedClient(cfg)
+}
+
+// ResolveConfig returns the configuration dg commands would use with opts:
+// the current context's cluster and settings, and the environment to send
+// requests to
+func ResolveConfig(opts Options) (config.Config, error) {
+	cfg := config.Config{Retries: opts.Retries, Timeout: opts.Timeout}
+	switch {
+	case opts.Retries == 0:
+		cfg.Retries = -1
+	case opts.Retries < 0:
+		cfg.Retries = 0
+	}
+	cfg.ApplyDefaults()
+
+	if !util.IsAuthenticated() {
+		return cfg, ErrNotLoggedIn
+	}
+	if err := ResolveEnvironment(&cfg, opts.Environment); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// ResolveEnvironment points cfg at environment, a name, slug or UUID. When
+// environment is empty, it checks that the environment in the user's
+// settings still exists instead.
+func ResolveEnvironment(cfg *config.Config, environment string) error {
+	// An explicit environment replaces the saved one, so the saved one
+	// doesn't need to be usable
+	if environment != "" {
+		environmentID, err := util.ResolveEnvironmentUUID(*cfg, environment)
+		if err != nil {
+			return err
+		}
+		cfg.EnvOverride = environmentID
+		return nil
+	}
+
+	_, err := util.CheckEnvironment(cfg)
+	return err
+}
@@ -0,0 +1,222 @@
+package devgraph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arctir/devgraph-cli/pkg/config"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	productionID = "11111111-1111-1111-1111-111111111111"
+	stagingID    = "22222222-2222-2222-2222-222222222222"
+)
+
+// received is a request made to a testServer
+type received struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   []byte
+}
+
+// testServer is a fake Devgraph API and OIDC issuer, with a user config and
+// credentials for it written to a temporary config directory
+type testServer struct {
+	server *httptest.Server
+	mux    *http.ServeMux
+
+	mu       sync.Mutex
+	requests []received
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	srv := &testServer{mux: http.NewServeMux()}
+	srv.server = httptest.NewServer(http.HandlerFunc(srv.serve))
+	t.Cleanup(srv.server.Close)
+
+	srv.handle("GET /.well-known/openid-configuration", map[string]any{
+		"issuer":                 srv.server.URL,
+		"authorization_endpoint": srv.server.URL + "/authorize",
+		"token_endpoint":         srv.server.URL + "/token",
+		"jwks_uri":               srv.server.URL + "/jwks",
+	})
+	environment := func(id, name, slug string) map[string]any {
+		return map[string]any{
+			"id":                    id,
+			"name":                  name,
+			"slug":                  slug,
+			"clerk_organization_id": "org_1",
+			"customer_id":           "cus_1",
+			"subscription_id":       "55555555-5555-5555-5555-555555555555",
+		}
+	}
+	srv.handle("GET /api/v1/environments", []map[string]any{
+		environment(productionID, "Production", "production"),
+		environment(stagingID, "Staging", "staging"),
+	})
+
+	claims := jwt.MapClaims{"exp": float64(time.Now().Add(time.Hour).Unix())}
+	userConfig, err := config.LoadUserConfig()
+	require.NoError(t, err)
+	userConfig.SetCluster("test", srv.server.URL, srv.server.URL, "test-client")
+	userConfig.SetUser("test", "test-access-token", "", "test-id-token", &claims)
+	userConfig.SetContext("test", "test", "test", productionID)
+	require.NoError(t, userConfig.UseContext("test"))
+	userConfig.Settings.DefaultEnvironment = productionID
+	require.NoError(t, config.SaveUserConfig(userConfig))
+	require.NoError(t, config.SaveCredentials(config.Credentials{
+		AccessToken: "test-access-token",
+		IDToken:     "test-id-token",
+		Claims:      &claims,
+	}))
+
+	return srv
+}
+
+func (s *testServer) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	s.mu.Lock()
+	s.requests = append(s.requests, received{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	s.mu.Unlock()
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// handle responds to requests matching pattern with body encoded as JSON
+func (s *testServer) handle(pattern string, body any) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, body)
+	})
+}
+
+// received returns the requests made with the given method and path
+func (s *testServer) received(method, path string) []received {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matched []received
+	for _, r := range s.requests {
+		if r.Method == method && r.Path == path {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// client returns a client for the current context's environment
+func (s *testServer) client(t *testing.T) *api.Client {
+	t.Helper()
+	client, err := NewClient(Options{})
+	require.NoError(t, err)
+	return client
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// testEntity is an entity as returned by the entities API
+func testEntity(name string) map[string]any {
+	return map[string]any{
+		"apiVersion": "core/v1",
+		"kind":       "Service",
+		"metadata":   map[string]any{"name": name, "namespace": "default"},
+		"id":         "core/v1/service/default/" + name,
+		"plural":     "services",
+		"group":      "core",
+		"version":    "v1",
+		"name":       name,
+		"namespace":  "default",
+	}
+}
+
+// testRelation is a relation between two testEntity entities
+func testRelation(relation, source, target string) map[string]any {
+	return map[string]any{
+		"relation": relation,
+		"source":   map[string]any{"apiVersion": "core/v1", "kind": "Service", "name": source, "id": "core/v1/service/default/" + source},
+		"target":   map[string]any{"apiVersion": "core/v1", "kind": "Service", "name": target, "id": "core/v1/service/default/" + target},
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	srv := newTestServer(t)
+	srv.handle("GET /api/v1/entities/", map[string]any{"primary_entities": []any{}})
+
+	// Requests go to the current context's environment
+	client := srv.client(t)
+	_, _, err := ListAllEntities(context.Background(), client, api.GetEntitiesParams{})
+	require.NoError(t, err)
+
+	requests := srv.received(http.MethodGet, "/api/v1/entities/")
+	require.Len(t, requests, 1)
+	assert.Equal(t, productionID, requests[0].Header.Get("Devgraph-Environment"))
+	assert.Equal(t, "Bearer test-id-token", requests[0].Header.Get("Authorization"))
+}
+
+func TestNewClient_Environment(t *testing.T) {
+	srv := newTestServer(t)
+	srv.handle("GET /api/v1/entities/", map[string]any{"primary_entities": []any{}})
+
+	client, err := NewClient(Options{Environment: "staging"})
+	require.NoError(t, err)
+	_, _, err = ListAllEntities(context.Background(), client, api.GetEntitiesParams{})
+	require.NoError(t, err)
+
+	requests := srv.received(http.MethodGet, "/api/v1/entities/")
+	require.Len(t, requests, 1)
+	assert.Equal(t, stagingID, requests[0].Header.Get("Devgraph-Environment"))
+
+	_, err = NewClient(Options{Environment: "qa"})
+	assert.ErrorContains(t, err, "environment 'qa' not found")
+}
+
+func TestNewClient_NotLoggedIn(t *testing.T) {
+	newTestServer(t)
+	require.NoError(t, config.SaveCredentials(config.Credentials{}))
+
+	_, err := NewClient(Options{})
+	assert.ErrorIs(t, err, ErrNotLoggedIn)
+}
+
+func TestResolveConfig(t *testing.T) {
+	srv := newTestServer(t)
+
+	cfg, err := ResolveConfig(Options{Environment: stagingID, Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, srv.server.URL, cfg.ApiURL)
+	assert.Equal(t, stagingID, cfg.EnvOverride)
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	assert.Equal(t, config.DefaultRetries, cfg.Retries)
+
+	cfg, err = ResolveConfig(Options{Retries: -1})
+	require.NoError(t, err)
+	assert.Empty(t, cfg.EnvOverride)
+	assert.Zero(t, cfg.Retries)
+}
@@ -0,0 +1,206 @@
+package devgraph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/arctir/devgraph-cli/pkg/logging"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+	"golang.org/x/sync/errgroup"
+)
+
+// PageSize is the number of entities requested per page when reading a
+// whole catalog
+const PageSize = 1000
+
+// PageRequests is the number of pages requested at once after the first.
+// The API doesn't report a total, so pages are requested in batches until
+// one comes back short.
+const PageRequests = 4
+
+// ListAllEntities reads every entity matching params, along with the
+// relations included with them. Pages after the first are requested
+// concurrently and merged in order. Relations between entities on different
+// pages come back with both, so they are only included once.
+func ListAllEntities(ctx context.Context, client *api.Client, params api.GetEntitiesParams) ([]api.EntityResponse, []api.EntityRelationResponse, error) {
+	return ListAllEntitiesWithProgress(ctx, client, params, nil)
+}
+
+// ListAllEntitiesWithProgress is ListAllEntities, calling progress with the
+// number of entities read so far after each page. progress may be nil.
+func ListAllEntitiesWithProgress(ctx context.Context, client *api.Client, params api.GetEntitiesParams, progress func(entities int)) ([]api.EntityResponse, []api.EntityRelationResponse, error) {
+	params.Limit = api.NewOptInt(PageSize)
+
+	var entities []api.EntityResponse
+	var relations []api.EntityRelationResponse
+	seen := make(map[EntityRelation]bool)
+	merge := func(page *api.EntityResultSetResponse) bool {
+		if page == nil {
+			return false
+		}
+		entities = append(entities, page.PrimaryEntities...)
+		if progress != nil {
+			progress(len(entities))
+		}
+		for _, rel := range page.Relations {
+			key := NewEntityRelation(rel)
+			if !seen[key] {
+				seen[key] = true
+				relations = append(relations, rel)
+			}
+		}
+		return len(page.PrimaryEntities) == PageSize
+	}
+
+	// Most catalogs fit in one page, so only fan out once the first is full
+	first, err := fetchEntityPage(ctx, client, params, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !merge(first) {
+		return entities, relations, nil
+	}
+
+	for next := PageSize; ; next += PageSize * PageRequests {
+		pages := make([]*api.EntityResultSetResponse, PageRequests)
+		group, groupCtx := errgroup.WithContext(ctx)
+		for i := range pages {
+			offset := next + i*PageSize
+			group.Go(func() error {
+				page, err := fetchEntityPage(groupCtx, client, params, offset)
+				pages[i] = page
+				return err
+			})
+		}
+		if err := group.Wait(); err != nil {
+			return nil, nil, err
+		}
+
+		// Pages past the first short one are empty, or hold entities created
+		// since it was read
+		for _, page := range pages {
+			if !merge(page) {
+				return entities, relations, nil
+			}
+		}
+	}
+}
+
+// fetchEntityPage reads the page of entities at offset. The page is nil when
+// the API reports no entities.
+func fetchEntityPage(ctx context.Context, client *api.Client, params api.GetEntitiesParams, offset int) (*api.EntityResultSetResponse, error) {
+	params.Offset = api.NewOptInt(offset)
+	resp, err := client.GetEntities(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entities: %w", err)
+	}
+
+	switch r := resp.(type) {
+	case *api.EntityResultSetResponse:
+		return r, nil
+	case *api.GetEntitiesNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
+	}
+}
+
+// ApplyEntity writes entity to the catalog under its namespace and name, as
+// restores and imports do. plural is the kind's plural from its definition;
+// when it's empty, the kind is pluralized by adding an s. A create that gets
+// no response may still have been handled, so it's only sent again once the
+// entity is known not to exist.
+func ApplyEntity(ctx context.Context, client *api.Client, entity Entity, plural string) (*api.EntityResponse, error) {
+	apiEntity := api.Entity{
+		ApiVersion: entity.ApiVersion,
+		Kind:       entity.Kind,
+	}
+	if err := convert(entity.Metadata, &apiEntity.Metadata); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+	if entity.Spec != nil {
+		var spec api.EntitySpec
+		if err := convert(entity.Spec, &spec); err != nil {
+			return nil, fmt.Errorf("invalid spec: %w", err)
+		}
+		apiEntity.Spec.SetTo(spec)
+	}
+	if entity.Status != nil {
+		var status api.EntityStatus
+		if err := convert(entity.Status, &status); err != nil {
+			return nil, fmt.Errorf("invalid status: %w", err)
+		}
+		apiEntity.Status.SetTo(status)
+	}
+
+	// An apiVersion without a group is in the core group
+	group, version, ok := strings.Cut(entity.ApiVersion, "/")
+	if !ok {
+		group, version = "core", entity.ApiVersion
+	}
+	if plural == "" {
+		plural = strings.ToLower(entity.Kind) + "s"
+	}
+
+	params := api.CreateEntityParams{
+		Group:     group,
+		Version:   version,
+		Namespace: apiEntity.Metadata.Namespace,
+		Plural:    plural,
+	}
+	resp, err := client.CreateEntity(ctx, &apiEntity, params)
+	if err != nil && ctx.Err() == nil {
+		existing, lookupErr := getEntity(ctx, client, api.GetEntityParams{
+			Group:     group,
+			Version:   version,
+			Kind:      plural,
+			Namespace: params.Namespace,
+			Name:      apiEntity.Metadata.Name,
+		})
+		switch {
+		case lookupErr != nil:
+			logging.Debug("failed to check whether entity was created", "entity", apiEntity.Metadata.Name, "error", lookupErr)
+		case existing != nil:
+			return existing, nil
+		default:
+			logging.Info("retrying entity create", "entity", apiEntity.Metadata.Name, "error", err)
+			resp, err = client.CreateEntity(ctx, &apiEntity, params)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	created, ok := resp.(*api.EntityResponse)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
+	}
+	return created, nil
+}
+
+// getEntity returns the entity named by params, or nil if there isn't one
+func getEntity(ctx context.Context, client *api.Client, params api.GetEntityParams) (*api.EntityResponse, error) {
+	resp, err := client.GetEntity(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	switch r := resp.(type) {
+	case *api.EntityWithRelationsResponse:
+		return &r.Entity, nil
+	case *api.GetEntityNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
+	}
+}
+
+// convert copies value into the API type target through JSON, which knows
+// how to read the API's optional fields
+func convert(value any, target any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
@@ -0,0 +1,181 @@
+package devgraph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// servePagedCatalog serves total entities a page at a time. Earlier pages
+// answer more slowly, so concurrent pages arrive out of order.
+func servePagedCatalog(srv *testServer, total int) {
+	srv.mux.HandleFunc("GET /api/v1/entities/", func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		time.Sleep(time.Duration(max(0, 5-offset/PageSize)) * 10 * time.Millisecond)
+
+		entities := []map[string]any{}
+		for i := offset; i < min(offset+limit, total); i++ {
+			entities = append(entities, testEntity(fmt.Sprintf("svc-%d", i)))
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"primary_entities": entities,
+			"relations":        []map[string]any{testRelation("DEPENDS_ON", "svc-0", "svc-1")},
+		})
+	})
+}
+
+func TestFetchAllEntities(t *testing.T) {
+	srv := newTestServer(t)
+	servePagedCatalog(srv, 5*PageSize+10)
+	client := srv.client(t)
+
+	entities, relations, err := ListAllEntities(context.Background(), client, api.GetEntitiesParams{Label: api.NewOptString("team=payments")})
+	require.NoError(t, err)
+
+	// Pages are merged in order, whichever answered first
+	require.Len(t, entities, 5*PageSize+10)
+	for i, entity := range entities {
+		require.Equal(t, fmt.Sprintf("svc-%d", i), entity.Name)
+	}
+	assert.Len(t, relations, 1, "relations repeated across pages are only included once")
+
+	// One page, then two batches: the second ends with the short page
+	requests := srv.received(http.MethodGet, "/api/v1/entities/")
+	assert.Len(t, requests, 1+2*PageRequests)
+	assert.Equal(t, "label=team%3Dpayments&limit=1000&offset=0", requests[0].Query)
+}
+
+func TestFetchAllEntities_Progress(t *testing.T) {
+	srv := newTestServer(t)
+	servePagedCatalog(srv, 5*PageSize+10)
+	client := srv.client(t)
+
+	// Progress counts pages as they're merged, in order
+	var reported []int
+	entities, _, err := ListAllEntitiesWithProgress(context.Background(), client, api.GetEntitiesParams{}, func(n int) {
+		reported = append(reported, n)
+	})
+	require.NoError(t, err)
+	assert.Len(t, entities, 5*PageSize+10)
+	assert.Equal(t, []int{PageSize, 2 * PageSize, 3 * PageSize, 4 * PageSize, 5 * PageSize, 5*PageSize + 10}, reported)
+}
+
+func TestFetchAllEntities_OnePage(t *testing.T) {
+	srv := newTestServer(t)
+	servePagedCatalog(srv, 3)
+	client := srv.client(t)
+
+	entities, _, err := ListAllEntities(context.Background(), client, api.GetEntitiesParams{})
+	require.NoError(t, err)
+	assert.Len(t, entities, 3)
+	assert.Len(t, srv.received(http.MethodGet, "/api/v1/entities/"), 1)
+}
+
+func TestFetchAllEntities_PageFails(t *testing.T) {
+	srv := newTestServer(t)
+	srv.mux.HandleFunc("GET /api/v1/entities/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("offset") == "2000" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		entities := make([]map[string]any, PageSize)
+		for i := range entities {
+			entities[i] = testEntity(fmt.Sprintf("svc-%d", i))
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"primary_entities": entities})
+	})
+	client := srv.client(t)
+
+	_, _, err := ListAllEntities(context.Background(), client, api.GetEntitiesParams{})
+	assert.ErrorContains(t, err, "failed to get entities")
+}
+
+func TestApplyEntity(t *testing.T) {
+	srv := newTestServer(t)
+	srv.mux.HandleFunc("POST /api/v1/entities/{group}/{version}/namespace/{namespace}/{plural}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusCreated, testEntity("payments"))
+	})
+	client := srv.client(t)
+
+	entity := Entity{
+		ApiVersion: "core/v1",
+		Kind:       "Service",
+		Metadata:   map[string]any{"name": "payments", "namespace": "shop", "labels": map[string]any{"team": "payments"}},
+		Spec:       map[string]any{"owner": "payments"},
+	}
+	created, err := ApplyEntity(context.Background(), client, entity, "")
+	require.NoError(t, err)
+	assert.Equal(t, "core/v1/service/default/payments", created.ID)
+
+	// Without a plural from a definition, the kind is pluralized
+	requests := srv.received(http.MethodPost, "/api/v1/entities/core/v1/namespace/shop/services")
+	require.Len(t, requests, 1)
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(requests[0].Body, &body))
+	assert.Equal(t, "Service", body["kind"])
+	assert.Equal(t, map[string]any{"team": "payments"}, body["metadata"].(map[string]any)["labels"])
+	assert.Equal(t, map[string]any{"owner": "payments"}, body["spec"])
+
+	// An apiVersion without a group is in the core group
+	entity.ApiVersion = "v1"
+	_, err = ApplyEntity(context.Background(), client, entity, "svcs")
+	require.NoError(t, err)
+	assert.Len(t, srv.received(http.MethodPost, "/api/v1/entities/core/v1/namespace/shop/svcs"), 1)
+}
+
+func TestApplyEntity_NoResponse(t *testing.T) {
+	for name, created := range map[string]bool{"created": true, "not created": false} {
+		t.Run(name, func(t *testing.T) {
+			srv := newTestServer(t)
+			posts := 0
+			srv.mux.HandleFunc("POST /api/v1/entities/core/v1/namespace/default/services", func(w http.ResponseWriter, r *http.Request) {
+				posts++
+				if posts == 1 {
+					// Drop the connection, as if it failed after the create
+					panic(http.ErrAbortHandler)
+				}
+				writeJSON(w, http.StatusCreated, testEntity("payments"))
+			})
+			srv.mux.HandleFunc("GET /api/v1/entities/core/v1/services/default/payments", func(w http.ResponseWriter, r *http.Request) {
+				if !created {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				writeJSON(w, http.StatusOK, map[string]any{"entity": testEntity("payments"), "related_entities": []any{}, "relations": []any{}})
+			})
+
+			entity := Entity{ApiVersion: "core/v1", Kind: "Service", Metadata: map[string]any{"name": "payments", "namespace": "default"}}
+			result, err := ApplyEntity(context.Background(), srv.client(t), entity, "services")
+			require.NoError(t, err)
+			assert.Equal(t, "core/v1/service/default/payments", result.ID)
+
+			// The create is only sent again when the entity isn't there
+			assert.Len(t, srv.received(http.MethodGet, "/api/v1/entities/core/v1/services/default/payments"), 1)
+			if created {
+				assert.Equal(t, 1, posts)
+			} else {
+				assert.Equal(t, 2, posts)
+			}
+		})
+	}
+}
+
+func TestApplyEntity_Rejected(t *testing.T) {
+	srv := newTestServer(t)
+	srv.mux.HandleFunc("POST /api/v1/entities/{group}/{version}/namespace/{namespace}/{plural}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"detail": []any{}})
+	})
+
+	entity := Entity{ApiVersion: "core/v1", Kind: "Service", Metadata: map[string]any{"name": "payments", "namespace": "default"}}
+	_, err := ApplyEntity(context.Background(), srv.client(t), entity, "services")
+	assert.ErrorContains(t, err, "unexpected response type: *v1.HTTPValidationError")
+}
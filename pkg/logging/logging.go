@@ -0,0 +1,140 @@
+// Package logging provides the leveled logger shared by the Devgraph CLI.
+// Messages go to stderr so they don't mix with command output, either as
+// readable lines ("Warning: ...") or as JSON objects.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+var (
+	mu     sync.Mutex
+	logger = slog.New(newTextHandler(os.Stderr, slog.LevelWarn))
+)
+
+// ParseLevel converts a level name (error, warn, info or debug) to a level
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "error":
+		return slog.LevelError, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	}
+	return 0, fmt.Errorf("invalid log level %q: must be error, warn, info or debug", name)
+}
+
+// Configure sets the level and format of messages and where they're written
+func Configure(w io.Writer, level slog.Level, format string) error {
+	var handler slog.Handler
+	switch format {
+	case FormatText, "":
+		handler = newTextHandler(w, level)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})
+	default:
+		return fmt.Errorf("invalid log format %q: must be text or json", format)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	logger = slog.New(handler)
+	return nil
+}
+
+// Logger returns the shared logger
+func Logger() *slog.Logger {
+	mu.Lock()
+	defer mu.Unlock()
+	return logger
+}
+
+// Error logs a failure that doesn't stop the command
+func Error(msg string, args ...any) {
+	Logger().Error(msg, args...)
+}
+
+// Warn logs something the user should know about, like a skipped item
+func Warn(msg string, args ...any) {
+	Logger().Warn(msg, args...)
+}
+
+// Info logs progress, shown with -v
+func Info(msg string, args ...any) {
+	Logger().Info(msg, args...)
+}
+
+// Debug logs details for troubleshooting, shown with -vv
+func Debug(msg string, args ...any) {
+	Logger().Debug(msg, args...)
+}
+
+// textHandler writes each record as a single line prefixed by its level,
+// e.g. "Warning: failed to read file a.yaml error=..."
+type textHandler struct {
+	mu    *sync.Mutex
+	w     io.Writer
+	level slog.Level
+	attrs []slog.Attr
+}
+
+func newTextHandler(w io.Writer, level slog.Level) *textHandler {
+	return &textHandler{mu: &sync.Mutex{}, w: w, level: level}
+}
+
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *textHandler) Handle(_ context.Context, record slog.Record) error {
+	var b strings.Builder
+	switch {
+	case record.Level >= slog.LevelError:
+		b.WriteString("Error: ")
+	case record.Level >= slog.LevelWarn:
+		b.WriteString("Warning: ")
+	case record.Level < slog.LevelInfo:
+		b.WriteString("Debug: ")
+	}
+	b.WriteString(record.Message)
+
+	writeAttr := func(attr slog.Attr) bool {
+		fmt.Fprintf(&b, " %s=%v", attr.Key, attr.Value)
+		return true
+	}
+	for _, attr := range h.attrs {
+		writeAttr(attr)
+	}
+	record.Attrs(writeAttr)
+	b.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	copied := *h
+	copied.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &copied
+}
+
+func (h *textHandler) WithGroup(_ string) slog.Handler {
+	return h
+}
@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetLogger(t *testing.T) {
+	t.Cleanup(func() {
+		_ = Configure(os.Stderr, slog.LevelWarn, FormatText)
+	})
+}
+
+func TestConfigure_Text(t *testing.T) {
+	resetLogger(t)
+	var out bytes.Buffer
+	require.NoError(t, Configure(&out, slog.LevelInfo, FormatText))
+
+	Error("request failed", "status", 500)
+	Warn("failed to read file", "file", "a.yaml")
+	Info("retrying request")
+	Debug("not shown")
+
+	assert.Equal(t, "Error: request failed status=500\nWarning: failed to read file file=a.yaml\nretrying request\n", out.String())
+}
+
+func TestConfigure_JSON(t *testing.T) {
+	resetLogger(t)
+	var out bytes.Buffer
+	require.NoError(t, Configure(&out, slog.LevelDebug, FormatJSON))
+
+	Debug("resolved context", "cluster", "prod")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+	assert.Equal(t, "DEBUG", record["level"])
+	assert.Equal(t, "resolved context", record["msg"])
+	assert.Equal(t, "prod", record["cluster"])
+}
+
+func TestConfigure_InvalidFormat(t *testing.T) {
+	assert.ErrorContains(t, Configure(&bytes.Buffer{}, slog.LevelWarn, "xml"), "invalid log format")
+}
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]slog.Level{"error": slog.LevelError, "warn": slog.LevelWarn, "WARNING": slog.LevelWarn, "info": slog.LevelInfo, "debug": slog.LevelDebug} {
+		level, err := ParseLevel(name)
+		require.NoError(t, err)
+		assert.Equal(t, want, level, name)
+	}
+
+	_, err := ParseLevel("trace")
+	assert.ErrorContains(t, err, "invalid log level")
+}
@@ -0,0 +1,613 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a kubectl-style JSONPath template: text with expressions in
+// braces, like {.metadata.name}, {range .items[*]}...{end} and {"\n"}.
+// Expressions are evaluated against the JSON form of what's printed, and
+// print every value they select, separated by spaces. Fields that don't
+// exist select nothing.
+type jsonPath struct {
+	nodes []pathNode
+}
+
+type pathNodeKind int
+
+const (
+	textNode pathNodeKind = iota
+	exprNode
+	rangeNode
+)
+
+// pathNode is literal text, an expression, or a range over an expression's
+// values with body evaluated for each
+type pathNode struct {
+	kind pathNodeKind
+	text string
+	expr pathExpr
+	body []pathNode
+}
+
+// pathExpr is an expression like $.items[0].name. Relative expressions
+// start from the current value, which is the value being ranged over inside
+// a range.
+type pathExpr struct {
+	root  bool
+	steps []pathStep
+}
+
+type pathStepKind int
+
+const (
+	fieldStep pathStepKind = iota
+	recursiveStep
+	wildcardStep
+	indexStep
+	sliceStep
+	filterStep
+)
+
+// pathStep selects values from each value selected by the steps before it
+type pathStep struct {
+	kind   pathStepKind
+	name   string
+	index  int
+	start  *int
+	end    *int
+	filter *pathFilter
+}
+
+// pathFilter keeps the elements for which left compares to right with op,
+// as in [?(@.kind=="Service")], or that have left when op is empty
+type pathFilter struct {
+	left  pathExpr
+	op    string
+	right any
+}
+
+func parseJSONPath(template string) (*jsonPath, error) {
+	actions, err := splitJSONPath(template)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ranges nest, so nodes are collected on a stack of bodies
+	stack := [][]pathNode{{}}
+	for _, a := range actions {
+		top := len(stack) - 1
+		if !a.expr {
+			stack[top] = append(stack[top], pathNode{kind: textNode, text: a.text})
+			continue
+		}
+
+		switch {
+		case a.text == "end":
+			if top == 0 {
+				return nil, fmt.Errorf("{end} without {range}")
+			}
+			body := stack[top]
+			stack = stack[:top]
+			last := len(stack[top-1]) - 1
+			stack[top-1][last].body = body
+		case strings.HasPrefix(a.text, "range "):
+			expr, err := parsePathExpr(strings.TrimSpace(strings.TrimPrefix(a.text, "range ")))
+			if err != nil {
+				return nil, err
+			}
+			stack[top] = append(stack[top], pathNode{kind: rangeNode, expr: expr})
+			stack = append(stack, nil)
+		case strings.HasPrefix(a.text, `"`) || strings.HasPrefix(a.text, "'"):
+			text, err := unquote(a.text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s", a.text)
+			}
+			stack[top] = append(stack[top], pathNode{kind: textNode, text: text})
+		default:
+			expr, err := parsePathExpr(a.text)
+			if err != nil {
+				return nil, err
+			}
+			stack[top] = append(stack[top], pathNode{kind: exprNode, expr: expr})
+		}
+	}
+	if len(stack) > 1 {
+		return nil, fmt.Errorf("{range} without {end}")
+	}
+	return &jsonPath{nodes: stack[0]}, nil
+}
+
+// jsonPathAction is a piece of a template: text, or the expression inside
+// a pair of braces
+type jsonPathAction struct {
+	text string
+	expr bool
+}
+
+func splitJSONPath(template string) ([]jsonPathAction, error) {
+	var actions []jsonPathAction
+	for template != "" {
+		open := strings.IndexByte(template, '{')
+		if open < 0 {
+			actions = append(actions, jsonPathAction{text: template})
+			break
+		}
+		if open > 0 {
+			actions = append(actions, jsonPathAction{text: template[:open]})
+		}
+		template = template[open+1:]
+
+		// Braces in quoted strings don't close the expression
+		end := -1
+		var quote byte
+		for i := 0; i < len(template) && end < 0; i++ {
+			switch c := template[i]; {
+			case quote != 0:
+				if c == '\\' {
+					i++
+				} else if c == quote {
+					quote = 0
+				}
+			case c == '"' || c == '\'':
+				quote = c
+			case c == '}':
+				end = i
+			}
+		}
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed { in %q", "{"+template)
+		}
+		actions = append(actions, jsonPathAction{text: strings.TrimSpace(template[:end]), expr: true})
+		template = template[end+1:]
+	}
+	return actions, nil
+}
+
+func parsePathExpr(text string) (pathExpr, error) {
+	var expr pathExpr
+	rest := text
+	switch {
+	case strings.HasPrefix(rest, "$"):
+		expr.root = true
+		rest = rest[1:]
+	case strings.HasPrefix(rest, "@"):
+		rest = rest[1:]
+	}
+
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			name, remaining := pathName(rest[2:])
+			if name == "" {
+				return expr, fmt.Errorf("missing field name after .. in %q", text)
+			}
+			expr.steps = append(expr.steps, pathStep{kind: recursiveStep, name: name})
+			rest = remaining
+		case strings.HasPrefix(rest, ".*"):
+			expr.steps = append(expr.steps, pathStep{kind: wildcardStep})
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "."):
+			// A lone . is the current value
+			name, remaining := pathName(rest[1:])
+			if name != "" {
+				expr.steps = append(expr.steps, pathStep{kind: fieldStep, name: name})
+			}
+			rest = remaining
+		case strings.HasPrefix(rest, "["):
+			end := closingBracket(rest)
+			if end < 0 {
+				return expr, fmt.Errorf("unclosed [ in %q", text)
+			}
+			step, err := parseBracketStep(strings.TrimSpace(rest[1:end]))
+			if err != nil {
+				return expr, fmt.Errorf("%w in %q", err, text)
+			}
+			expr.steps = append(expr.steps, step)
+			rest = rest[end+1:]
+		default:
+			return expr, fmt.Errorf("unexpected %q in %q (fields start with a .)", rest, text)
+		}
+	}
+	return expr, nil
+}
+
+// pathName returns the field name at the start of text and what follows it
+func pathName(text string) (string, string) {
+	end := strings.IndexAny(text, ".[")
+	if end < 0 {
+		return text, ""
+	}
+	return text[:end], text[end:]
+}
+
+// closingBracket returns the index of the ] closing the [ text starts
+// with, skipping over quoted strings and nested brackets
+func closingBracket(text string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func parseBracketStep(inner string) (pathStep, error) {
+	switch {
+	case inner == "*":
+		return pathStep{kind: wildcardStep}, nil
+	case strings.HasPrefix(inner, "?(") && strings.HasSuffix(inner, ")"):
+		filter, err := parsePathFilter(strings.TrimSpace(inner[2 : len(inner)-1]))
+		if err != nil {
+			return pathStep{}, err
+		}
+		return pathStep{kind: filterStep, filter: filter}, nil
+	case strings.HasPrefix(inner, `"`) || strings.HasPrefix(inner, "'"):
+		name, err := unquote(inner)
+		if err != nil {
+			return pathStep{}, fmt.Errorf("invalid field name %s", inner)
+		}
+		return pathStep{kind: fieldStep, name: name}, nil
+	case strings.Contains(inner, ":"):
+		bounds := strings.Split(inner, ":")
+		if len(bounds) != 2 {
+			return pathStep{}, fmt.Errorf("invalid slice [%s]", inner)
+		}
+		step := pathStep{kind: sliceStep}
+		for i, bound := range bounds {
+			bound = strings.TrimSpace(bound)
+			if bound == "" {
+				continue
+			}
+			n, err := strconv.Atoi(bound)
+			if err != nil {
+				return pathStep{}, fmt.Errorf("invalid slice [%s]", inner)
+			}
+			if i == 0 {
+				step.start = &n
+			} else {
+				step.end = &n
+			}
+		}
+		return step, nil
+	default:
+		n, err := strconv.Atoi(inner)
+		if err != nil {
+			return pathStep{}, fmt.Errorf("invalid index [%s]", inner)
+		}
+		return pathStep{kind: indexStep, index: n}, nil
+	}
+}
+
+// filterOperators are checked longest first so <= isn't read as <
+var filterOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func parsePathFilter(text string) (*pathFilter, error) {
+	left, op, right := text, "", ""
+	var quote byte
+	for i := 0; i < len(text) && op == ""; i++ {
+		c := text[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '"' || c == '\'' {
+			quote = c
+			continue
+		}
+		for _, candidate := range filterOperators {
+			if strings.HasPrefix(text[i:], candidate) {
+				left, op, right = strings.TrimSpace(text[:i]), candidate, strings.TrimSpace(text[i+len(candidate):])
+				break
+			}
+		}
+	}
+
+	if !strings.HasPrefix(left, "@") {
+		return nil, fmt.Errorf("filter %q must compare a field of @", text)
+	}
+	leftExpr, err := parsePathExpr(left)
+	if err != nil {
+		return nil, err
+	}
+	filter := &pathFilter{left: leftExpr, op: op}
+	if op == "" {
+		return filter, nil
+	}
+
+	switch {
+	case strings.HasPrefix(right, `"`) || strings.HasPrefix(right, "'"):
+		if filter.right, err = unquote(right); err != nil {
+			return nil, fmt.Errorf("invalid string %s in filter", right)
+		}
+	case right == "true" || right == "false":
+		filter.right = right == "true"
+	case strings.HasPrefix(right, "@") || strings.HasPrefix(right, "$"):
+		if filter.right, err = parsePathExpr(right); err != nil {
+			return nil, err
+		}
+	default:
+		n, err := strconv.ParseFloat(right, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q in filter (quote strings)", right)
+		}
+		filter.right = n
+	}
+	return filter, nil
+}
+
+// unquote returns the string in double or single quotes
+func unquote(text string) (string, error) {
+	if len(text) >= 2 && text[0] == '\'' && text[len(text)-1] == '\'' {
+		return text[1 : len(text)-1], nil
+	}
+	return strconv.Unquote(text)
+}
+
+func (p *jsonPath) execute(w io.Writer, data any) error {
+	return executePathNodes(w, p.nodes, data, data)
+}
+
+func executePathNodes(w io.Writer, nodes []pathNode, root, current any) error {
+	for _, node := range nodes {
+		switch node.kind {
+		case textNode:
+			if _, err := io.WriteString(w, node.text); err != nil {
+				return err
+			}
+		case exprNode:
+			values := node.expr.evaluate(root, current)
+			text := make([]string, len(values))
+			for i, value := range values {
+				text[i] = formatPathValue(value)
+			}
+			if _, err := io.WriteString(w, strings.Join(text, " ")); err != nil {
+				return err
+			}
+		case rangeNode:
+			values := node.expr.evaluate(root, current)
+			// Ranging over a list ranges over its elements
+			if len(values) == 1 {
+				if list, ok := values[0].([]any); ok {
+					values = list
+				}
+			}
+			for _, value := range values {
+				if err := executePathNodes(w, node.body, root, value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (e pathExpr) evaluate(root, current any) []any {
+	values := []any{current}
+	if e.root {
+		values = []any{root}
+	}
+	for _, step := range e.steps {
+		var next []any
+		for _, value := range values {
+			next = append(next, step.apply(root, value)...)
+		}
+		values = next
+	}
+	return values
+}
+
+func (s pathStep) apply(root, value any) []any {
+	switch s.kind {
+	case fieldStep:
+		if m, ok := value.(map[string]any); ok {
+			if v, ok := m[s.name]; ok {
+				return []any{v}
+			}
+		}
+	case recursiveStep:
+		return descendants(value, s.name)
+	case wildcardStep:
+		return children(value)
+	case indexStep:
+		if list, ok := value.([]any); ok {
+			i := s.index
+			if i < 0 {
+				i += len(list)
+			}
+			if i >= 0 && i < len(list) {
+				return []any{list[i]}
+			}
+		}
+	case sliceStep:
+		if list, ok := value.([]any); ok {
+			start, end := 0, len(list)
+			if s.start != nil {
+				start = clampIndex(*s.start, len(list))
+			}
+			if s.end != nil {
+				end = clampIndex(*s.end, len(list))
+			}
+			if start < end {
+				return list[start:end]
+			}
+		}
+	case filterStep:
+		var kept []any
+		for _, child := range children(value) {
+			if s.filter.matches(root, child) {
+				kept = append(kept, child)
+			}
+		}
+		return kept
+	}
+	return nil
+}
+
+// clampIndex resolves a slice bound, counting negative ones from the end
+func clampIndex(i, length int) int {
+	if i < 0 {
+		i += length
+	}
+	return min(max(i, 0), length)
+}
+
+// children returns the elements of a list, or the values of a map in key
+// order
+func children(value any) []any {
+	switch v := value.(type) {
+	case []any:
+		return v
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		values := make([]any, len(keys))
+		for i, key := range keys {
+			values[i] = v[key]
+		}
+		return values
+	}
+	return nil
+}
+
+// descendants returns the values of the name fields in value and
+// everything beneath it
+func descendants(value any, name string) []any {
+	var found []any
+	if m, ok := value.(map[string]any); ok {
+		if v, ok := m[name]; ok {
+			found = append(found, v)
+		}
+	}
+	for _, child := range children(value) {
+		found = append(found, descendants(child, name)...)
+	}
+	return found
+}
+
+func (f *pathFilter) matches(root, value any) bool {
+	left := f.left.evaluate(root, value)
+	if f.op == "" {
+		return len(left) > 0 && left[0] != nil && left[0] != false
+	}
+	if len(left) == 0 {
+		return false
+	}
+
+	right := f.right
+	if expr, ok := right.(pathExpr); ok {
+		values := expr.evaluate(root, value)
+		if len(values) == 0 {
+			return false
+		}
+		right = values[0]
+	}
+	return comparePathValues(left[0], f.op, right)
+}
+
+func comparePathValues(a any, op string, b any) bool {
+	if x, ok := pathNumber(a); ok {
+		if y, ok := pathNumber(b); ok {
+			switch op {
+			case "==":
+				return x == y
+			case "!=":
+				return x != y
+			case "<":
+				return x < y
+			case ">":
+				return x > y
+			case "<=":
+				return x <= y
+			case ">=":
+				return x >= y
+			}
+		}
+	}
+
+	x, y := formatPathValue(a), formatPathValue(b)
+	switch op {
+	case "==":
+		return x == y
+	case "!=":
+		return x != y
+	}
+	// Strings are ordered, other values only compare as equal or not
+	_, aString := a.(string)
+	_, bString := b.(string)
+	if !aString || !bString {
+		return false
+	}
+	switch op {
+	case "<":
+		return x < y
+	case ">":
+		return x > y
+	case "<=":
+		return x <= y
+	case ">=":
+		return x >= y
+	}
+	return false
+}
+
+func pathNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		n, err := v.Float64()
+		return n, err == nil
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// formatPathValue prints strings and numbers as they are and lists and
+// objects as JSON. Nulls print nothing, like fields that don't exist.
+func formatPathValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,95 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testEntityJSON = `{
+	"apiVersion": "core/v1",
+	"kind": "Service",
+	"metadata": {"name": "web", "namespace": "default", "labels": {"team": "payments", "tier": "frontend"}},
+	"spec": {
+		"replicas": 3,
+		"ports": [
+			{"name": "http", "port": 80},
+			{"name": "https", "port": 443},
+			{"name": "admin", "port": 8080}
+		]
+	},
+	"status": null
+}`
+
+func TestJSONPath(t *testing.T) {
+	decoder := json.NewDecoder(strings.NewReader(testEntityJSON))
+	decoder.UseNumber()
+	var entity any
+	require.NoError(t, decoder.Decode(&entity))
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{`{.metadata.name}`, "web"},
+		{`{$.metadata.name}`, "web"},
+		{`{@.kind}`, "Service"},
+		{`name={.metadata.name} kind={.kind}`, "name=web kind=Service"},
+		{`{.metadata['name']}`, "web"},
+		{`{.metadata.labels}`, `{"team":"payments","tier":"frontend"}`},
+		{`{.metadata.labels.*}`, "payments frontend"},
+		{`{.spec.replicas}`, "3"},
+		{`{.spec.ports[0].port}`, "80"},
+		{`{.spec.ports[-1].name}`, "admin"},
+		{`{.spec.ports[*].name}`, "http https admin"},
+		{`{.spec.ports[1:].port}`, "443 8080"},
+		{`{.spec.ports[:-2].port}`, "80"},
+		{`{.spec.ports[?(@.port>100)].name}`, "https admin"},
+		{`{.spec.ports[?(@.name=="http")].port}`, "80"},
+		{`{.spec.ports[?(@.name!='http')].port}`, "443 8080"},
+		{`{..port}`, "80 443 8080"},
+		{`{range .spec.ports[*]}{.name}:{.port}{","}{end}`, "http:80,https:443,admin:8080,"},
+		{`{range .spec.ports}{.name}{"\n"}{end}`, "http\nhttps\nadmin\n"},
+		{`{.spec.ports[?(@.port>100)]}`, `{"name":"https","port":443} {"name":"admin","port":8080}`},
+		{`{.metadata.owner}`, ""},
+		{`{.spec.ports[9].name}`, ""},
+		{`{.status}`, ""},
+		{`{"}"}`, "}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			path, err := parseJSONPath(tt.template)
+			require.NoError(t, err)
+			var b bytes.Buffer
+			require.NoError(t, path.execute(&b, entity))
+			assert.Equal(t, tt.want, b.String())
+		})
+	}
+}
+
+func TestJSONPath_Invalid(t *testing.T) {
+	tests := []struct {
+		template string
+		err      string
+	}{
+		{`{.metadata.name`, "unclosed {"},
+		{`{.spec.ports[0}`, "unclosed ["},
+		{`{metadata.name}`, "fields start with a ."},
+		{`{.spec.ports[x]}`, "invalid index [x]"},
+		{`{.spec.ports[1:2:3]}`, "invalid slice [1:2:3]"},
+		{`{.spec.ports[?(.port>1)]}`, "must compare a field of @"},
+		{`{.spec.ports[?(@.name==http)]}`, "quote strings"},
+		{`{range .spec.ports[*]}{.name}`, "{range} without {end}"},
+		{`{.name}{end}`, "{end} without {range}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			_, err := parseJSONPath(tt.template)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
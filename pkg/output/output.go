@@ -0,0 +1,217 @@
+// Package output prints command results in the formats chosen with
+// --output: a table, JSON, YAML, the name of each item, or fields picked
+// out with a JSONPath or Go template.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats every list and get command accepts
+const (
+	Table = "table"
+	JSON  = "json"
+	YAML  = "yaml"
+	Name  = "name"
+)
+
+// maxColumnWidth is the widest a table column gets before values in it are
+// truncated
+const maxColumnWidth = 60
+
+// Result is what a command prints, in each of the formats
+type Result struct {
+	// Data is what the json and yaml formats encode
+	Data any
+
+	// Headers and Rows are the table. Rows are keyed by header; a row
+	// without a value for a header shows "-".
+	Headers []string
+	Rows    []map[string]any
+	// Wide are the columns whose values are never truncated, like IDs that
+	// are meant to be copied
+	Wide []string
+	// Empty is printed instead of a table with no rows. It defaults to "No
+	// data to display."
+	Empty string
+
+	// Names are printed one per line by the name format, and identify each
+	// item the way the resource's other commands take it. Results without
+	// names don't support the name format.
+	Names []string
+}
+
+// Print writes result to stdout in format
+func Print(format string, result Result) error {
+	return Write(os.Stdout, format, result)
+}
+
+// Write writes result to w in format
+func Write(w io.Writer, format string, result Result) error {
+	if r, ok, err := parseTemplateFormat(format); ok {
+		if err != nil {
+			return err
+		}
+		return writeTemplate(w, r, result.Data)
+	}
+
+	switch strings.ToLower(format) {
+	case JSON:
+		data, err := json.MarshalIndent(result.Data, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case YAML, "yml":
+		data, err := yaml.Marshal(result.Data)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		_, err = w.Write(data)
+		return err
+	case Name:
+		if result.Names == nil {
+			return fmt.Errorf("output format name isn't supported by this command (use table, json or yaml)")
+		}
+		for _, name := range result.Names {
+			if _, err := fmt.Fprintln(w, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	case Table, "":
+		if len(result.Rows) == 0 {
+			empty := result.Empty
+			if empty == "" {
+				empty = "No data to display."
+			}
+			_, err := fmt.Fprintln(w, empty)
+			return err
+		}
+		WriteTable(w, result.Headers, result.Rows, result.Wide...)
+		return nil
+	default:
+		return UnsupportedFormatError(format, append([]string{Table, JSON, YAML, Name}, templateFormats...)...)
+	}
+}
+
+// Check returns an error unless format is one of supported, for commands
+// to check before doing any work. Commands that print JSON also print the
+// template formats, whose templates are checked too.
+func Check(format string, supported ...string) error {
+	if slices.Contains(supported, JSON) {
+		if _, ok, err := parseTemplateFormat(format); ok {
+			return err
+		}
+		supported = append(slices.Clone(supported), templateFormats...)
+	}
+
+	normalized := strings.ToLower(format)
+	if normalized == "yml" {
+		normalized = YAML
+	}
+	if slices.Contains(supported, normalized) {
+		return nil
+	}
+	return UnsupportedFormatError(format, supported...)
+}
+
+// UnsupportedFormatError is the error for an --output format a command
+// doesn't print, listing the ones it does
+func UnsupportedFormatError(format string, supported ...string) error {
+	list := strings.Join(supported[:len(supported)-1], ", ")
+	if len(supported) > 1 {
+		list += " or "
+	}
+	list += supported[len(supported)-1]
+	return fmt.Errorf("unsupported output format: %s (use %s)", format, list)
+}
+
+// PrintTable prints rows as a table with headers to stdout. It's for output
+// that's always a table, like the details beneath a resource.
+func PrintTable(headers []string, rows []map[string]any, wide ...string) {
+	WriteTable(os.Stdout, headers, rows, wide...)
+}
+
+// WriteTable writes rows as a borderless table with colored headers to w.
+// Values longer than 60 characters are truncated unless their column is
+// one of wide.
+func WriteTable(w io.Writer, headers []string, rows []map[string]any, wide ...string) {
+	cell := func(header string, value any) string {
+		var s string
+		switch v := value.(type) {
+		case string:
+			s = v
+		case int:
+			s = fmt.Sprintf("%d", v)
+		case float64:
+			s = fmt.Sprintf("%.2f", v)
+		default:
+			s = fmt.Sprintf("%v", v)
+		}
+		// Truncate by characters, so multi-byte ones aren't cut in half
+		if runes := []rune(s); len(runes) > maxColumnWidth && !slices.Contains(wide, header) {
+			s = string(runes[:maxColumnWidth-3]) + "..."
+		}
+		return s
+	}
+
+	// Widths are in characters, as fmt pads values by them
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = utf8.RuneCountInString(header)
+		for _, row := range rows {
+			if value, ok := row[header]; ok {
+				widths[i] = max(widths[i], utf8.RuneCountInString(cell(header, value)))
+			}
+		}
+	}
+
+	fmt.Fprintln(w)
+
+	// Colors don't take up columns, so headers are padded by their length
+	headerColor := color.New(color.FgBlue, color.Bold)
+	for i, header := range headers {
+		if i > 0 {
+			fmt.Fprint(w, "  ")
+		}
+		fmt.Fprint(w, headerColor.Sprint(header)+strings.Repeat(" ", widths[i]-utf8.RuneCountInString(header)))
+	}
+	fmt.Fprintln(w)
+
+	for i := range headers {
+		if i > 0 {
+			fmt.Fprint(w, "  ")
+		}
+		fmt.Fprint(w, strings.Repeat("─", widths[i]))
+	}
+	fmt.Fprintln(w)
+
+	gray := color.New(color.FgHiBlack)
+	for _, row := range rows {
+		for i, header := range headers {
+			if i > 0 {
+				fmt.Fprint(w, "  ")
+			}
+			value, ok := row[header]
+			if !ok {
+				fmt.Fprint(w, gray.Sprint("-")+strings.Repeat(" ", max(widths[i]-1, 0)))
+				continue
+			}
+			fmt.Fprintf(w, "%-*s", widths[i], cell(header, value))
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w)
+}
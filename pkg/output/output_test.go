@@ -0,0 +1,169 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type testItem struct {
+	ID   string `json:"id" yaml:"id"`
+	Name string `json:"name" yaml:"name"`
+}
+
+func testResult() Result {
+	items := []testItem{{ID: "1", Name: "web"}, {ID: "2", Name: "api"}}
+	return Result{
+		Data:    items,
+		Headers: []string{"ID", "Name"},
+		Rows: []map[string]any{
+			{"ID": "1", "Name": "web"},
+			{"ID": "2", "Name": "api"},
+		},
+		Names: []string{"1", "2"},
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, Write(&b, JSON, testResult()))
+	var items []testItem
+	require.NoError(t, json.Unmarshal(b.Bytes(), &items))
+	assert.Equal(t, []testItem{{ID: "1", Name: "web"}, {ID: "2", Name: "api"}}, items)
+
+	for _, format := range []string{YAML, "yml", "YAML"} {
+		b.Reset()
+		require.NoError(t, Write(&b, format, testResult()))
+		items = nil
+		require.NoError(t, yaml.Unmarshal(b.Bytes(), &items))
+		assert.Len(t, items, 2, format)
+	}
+
+	b.Reset()
+	require.NoError(t, Write(&b, Name, testResult()))
+	assert.Equal(t, "1\n2\n", b.String())
+
+	b.Reset()
+	require.NoError(t, Write(&b, Table, testResult()))
+	assert.Regexp(t, `ID\S*\s+\S*Name`, b.String())
+	assert.Regexp(t, `1\s+web`, b.String())
+	assert.Regexp(t, `2\s+api`, b.String())
+}
+
+func TestWrite_Templates(t *testing.T) {
+	// Lists print each item through the template, one per line
+	var b bytes.Buffer
+	require.NoError(t, Write(&b, "jsonpath={.name}", testResult()))
+	assert.Equal(t, "web\napi\n", b.String())
+
+	b.Reset()
+	require.NoError(t, Write(&b, `jsonpath={.id}{"\t"}{.name}{"\n"}`, testResult()))
+	assert.Equal(t, "1\tweb\n2\tapi\n", b.String())
+
+	b.Reset()
+	require.NoError(t, Write(&b, "go-template={{.id}}: {{.name}}", testResult()))
+	assert.Equal(t, "1: web\n2: api\n", b.String())
+
+	// Other results print through it once, with the fields json prints
+	b.Reset()
+	require.NoError(t, Write(&b, "jsonpath={.name}", Result{Data: testItem{ID: "1", Name: "web"}}))
+	assert.Equal(t, "web\n", b.String())
+
+	b.Reset()
+	assert.ErrorContains(t, Write(&b, "jsonpath={.name", testResult()), "invalid jsonpath template: unclosed {")
+	assert.ErrorContains(t, Write(&b, "go-template={{.name}", testResult()), "invalid go-template")
+	assert.ErrorContains(t, Write(&b, "jsonpath", testResult()), "output format jsonpath needs a template")
+	assert.Empty(t, b.String())
+}
+
+func TestWrite_Empty(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, Write(&b, Table, Result{Data: []testItem{}, Names: []string{}}))
+	assert.Equal(t, "No data to display.\n", b.String())
+
+	b.Reset()
+	require.NoError(t, Write(&b, Table, Result{Empty: "No widgets found."}))
+	assert.Equal(t, "No widgets found.\n", b.String())
+
+	// Other formats print an empty list rather than the message
+	b.Reset()
+	require.NoError(t, Write(&b, JSON, Result{Data: []testItem{}, Empty: "No widgets found."}))
+	assert.JSONEq(t, "[]", b.String())
+
+	b.Reset()
+	require.NoError(t, Write(&b, Name, Result{Names: []string{}}))
+	assert.Empty(t, b.String())
+}
+
+func TestWrite_Unsupported(t *testing.T) {
+	var b bytes.Buffer
+	err := Write(&b, "xml", testResult())
+	assert.EqualError(t, err, "unsupported output format: xml (use table, json, yaml, name, jsonpath=TEMPLATE or go-template=TEMPLATE)")
+
+	// Results without names don't print the name format
+	result := testResult()
+	result.Names = nil
+	assert.ErrorContains(t, Write(&b, Name, result), "output format name isn't supported")
+	assert.Empty(t, b.String())
+}
+
+func TestCheck(t *testing.T) {
+	assert.NoError(t, Check("json", Table, JSON, YAML))
+	assert.NoError(t, Check("yml", Table, JSON, YAML))
+	assert.NoError(t, Check("TABLE", Table, JSON, YAML))
+	assert.EqualError(t, Check("name", Table, JSON, YAML), "unsupported output format: name (use table, json, yaml, jsonpath=TEMPLATE or go-template=TEMPLATE)")
+	assert.EqualError(t, Check("table", YAML), "unsupported output format: table (use yaml)")
+
+	// Commands that print JSON print templates, which are checked up front
+	assert.NoError(t, Check("jsonpath={.name}", Table, JSON))
+	assert.NoError(t, Check("go-template={{.name}}", JSON))
+	assert.ErrorContains(t, Check("jsonpath={.name", Table, JSON), "invalid jsonpath template")
+	assert.Error(t, Check("jsonpath={.name}", Table, YAML))
+}
+
+func TestWriteTable_Wide(t *testing.T) {
+	long := strings.Repeat("x", 80)
+	rows := []map[string]any{{"ID": long, "Description": long}}
+
+	var b bytes.Buffer
+	WriteTable(&b, []string{"ID", "Description"}, rows, "ID")
+	out := b.String()
+
+	// The wide column is printed whole, the other is truncated
+	assert.Contains(t, out, long)
+	assert.Contains(t, out, strings.Repeat("x", 57)+"...")
+	assert.Equal(t, 1, strings.Count(out, long))
+}
+
+func TestWriteTable_Multibyte(t *testing.T) {
+	long := strings.Repeat("é", 80)
+	rows := []map[string]any{{"Name": "web", "Description": long}, {"Name": "api", "Description": "—"}}
+
+	var b bytes.Buffer
+	WriteTable(&b, []string{"Name", "Description"}, rows)
+	out := b.String()
+
+	// Truncation keeps whole characters, and columns line up by them
+	assert.True(t, utf8.ValidString(out))
+	assert.Contains(t, out, strings.Repeat("é", 57)+"...")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, utf8.RuneCountInString(lines[1]), utf8.RuneCountInString(lines[2]))
+}
+
+func TestWriteTable_Missing(t *testing.T) {
+	var b bytes.Buffer
+	WriteTable(&b, []string{"Name", "Owner"}, []map[string]any{{"Name": "web"}, {"Name": "api", "Owner": "payments"}})
+	out := b.String()
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 4)
+	assert.Regexp(t, `^web\s+\S*-`, lines[2])
+	assert.Regexp(t, `^api\s+payments`, lines[3])
+}
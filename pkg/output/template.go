@@ -0,0 +1,104 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// Template formats print fields picked out of what the json format prints,
+// given as jsonpath=TEMPLATE or go-template=TEMPLATE
+const (
+	JSONPath   = "jsonpath"
+	GoTemplate = "go-template"
+)
+
+// templateFormats are the template formats as listed in errors
+var templateFormats = []string{JSONPath + "=TEMPLATE", GoTemplate + "=TEMPLATE"}
+
+// renderer prints a value through a template
+type renderer interface {
+	execute(w io.Writer, data any) error
+}
+
+type goTemplate struct {
+	template *template.Template
+}
+
+func (t goTemplate) execute(w io.Writer, data any) error {
+	if err := t.template.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to execute go-template: %w", err)
+	}
+	return nil
+}
+
+// parseTemplateFormat returns the renderer for a jsonpath=... or
+// go-template=... format. ok is false for other formats.
+func parseTemplateFormat(format string) (r renderer, ok bool, err error) {
+	kind, text, found := strings.Cut(format, "=")
+	if !found {
+		// A template format without a template is still one, so the error
+		// says what's missing
+		kind = format
+	}
+
+	switch strings.ToLower(kind) {
+	case JSONPath:
+		if text == "" {
+			return nil, true, fmt.Errorf("output format jsonpath needs a template, as in -o jsonpath='{.name}'")
+		}
+		path, err := parseJSONPath(text)
+		if err != nil {
+			return nil, true, fmt.Errorf("invalid jsonpath template: %w", err)
+		}
+		return path, true, nil
+	case GoTemplate:
+		if text == "" {
+			return nil, true, fmt.Errorf("output format go-template needs a template, as in -o go-template='{{.name}}'")
+		}
+		tmpl, err := template.New("output").Parse(text)
+		if err != nil {
+			return nil, true, fmt.Errorf("invalid go-template: %w", err)
+		}
+		return goTemplate{template: tmpl}, true, nil
+	}
+	return nil, false, nil
+}
+
+// writeTemplate prints data through r. Lists print each item through it,
+// so {.name} prints the name of every item, and every item ends with a
+// newline.
+func writeTemplate(w io.Writer, r renderer, data any) error {
+	// Templates see the fields the json format prints
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var generic any
+	if err := decoder.Decode(&generic); err != nil {
+		return fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+
+	items := []any{generic}
+	if list, ok := generic.([]any); ok {
+		items = list
+	}
+	for _, item := range items {
+		var b bytes.Buffer
+		if err := r.execute(&b, item); err != nil {
+			return err
+		}
+		if !bytes.HasSuffix(b.Bytes(), []byte("\n")) {
+			b.WriteByte('\n')
+		}
+		if _, err := w.Write(b.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
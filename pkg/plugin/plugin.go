@@ -0,0 +1,170 @@
+// Package plugin runs kubectl-style extensions of the Devgraph CLI. A plugin
+// is any executable on PATH named "dg-<name>"; 'dg <name>' runs it when no
+// built-in command has that name. Dashes in the executable name are
+// subcommands, so dg-foo-bar runs for 'dg foo bar'.
+package plugin
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arctir/devgraph-cli/pkg/auth"
+	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/arctir/devgraph-cli/pkg/logging"
+)
+
+// Prefix starts the name of every plugin executable
+const Prefix = "dg-"
+
+// Plugin is an executable found on PATH
+type Plugin struct {
+	Name string `json:"name" yaml:"name"`
+	Path string `json:"path" yaml:"path"`
+}
+
+// Find returns the plugin for the longest run of leading args that names
+// one, and the args to pass it. Args after the first flag are never part of
+// the name.
+func Find(args []string) (string, []string, bool) {
+	var words []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		words = append(words, arg)
+	}
+
+	for n := len(words); n > 0; n-- {
+		path, err := exec.LookPath(Prefix + strings.Join(words[:n], "-"))
+		if err == nil {
+			return path, args[n:], true
+		}
+	}
+	return "", nil, false
+}
+
+// List returns the plugins on PATH, sorted by name. When several
+// directories hold a plugin of the same name, the first one wins, as it does
+// when running it.
+func List() []Plugin {
+	seen := map[string]bool{}
+	var plugins []Plugin
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !strings.HasPrefix(name, Prefix) || entry.IsDir() {
+				continue
+			}
+			path := filepath.Join(dir, name)
+			if !isExecutable(path) {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			name = strings.ReplaceAll(strings.TrimPrefix(name, Prefix), "-", " ")
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, Plugin{Name: name, Path: path})
+		}
+	}
+
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		_, err := exec.LookPath(path)
+		return err == nil
+	}
+	return info.Mode()&0111 != 0
+}
+
+// Environment returns the variables passed to a plugin, describing the
+// current context so the plugin can call the API as the user:
+//
+//	DEVGRAPH_CONTEXT        name of the current context
+//	DEVGRAPH_API_URL        API server of the current context
+//	DEVGRAPH_ISSUER_URL     identity provider of the current context
+//	DEVGRAPH_ENVIRONMENT    UUID of the default environment
+//	DEVGRAPH_TOKEN          bearer token for the API, when logged in
+//	DEVGRAPH_TOKEN_EXPIRY   when the token expires, in RFC 3339 format
+//	DEVGRAPH_CLI            path of the dg executable
+func Environment(cfg config.Config) []string {
+	env := map[string]string{
+		"DEVGRAPH_API_URL":    cfg.ApiURL,
+		"DEVGRAPH_ISSUER_URL": cfg.IssuerURL,
+	}
+
+	if userConfig, err := config.LoadUserConfig(); err == nil {
+		env["DEVGRAPH_CONTEXT"] = userConfig.CurrentContext
+		env["DEVGRAPH_ENVIRONMENT"] = userConfig.Settings.DefaultEnvironment
+		if env["DEVGRAPH_ENVIRONMENT"] == "" {
+			if ctx, _, _, err := userConfig.GetCurrentContext(); err == nil {
+				env["DEVGRAPH_ENVIRONMENT"] = ctx.Environment
+			}
+		}
+	}
+
+	if cfg.EnvOverride != "" {
+		env["DEVGRAPH_ENVIRONMENT"] = cfg.EnvOverride
+	}
+
+	if token, err := auth.CurrentToken(cfg); err != nil {
+		logging.Info("not passing a token to plugin", "error", err)
+	} else {
+		env["DEVGRAPH_TOKEN"] = token.AccessToken
+		if !token.Expiry.IsZero() {
+			env["DEVGRAPH_TOKEN_EXPIRY"] = token.Expiry.Format(time.RFC3339)
+		}
+	}
+
+	if executable, err := os.Executable(); err == nil {
+		env["DEVGRAPH_CLI"] = executable
+	}
+
+	vars := os.Environ()
+	for name, value := range env {
+		if value != "" {
+			vars = append(vars, name+"="+value)
+		}
+	}
+	return vars
+}
+
+// Run runs the plugin at path with args and env, connected to the CLI's
+// standard streams, and returns its exit code
+func Run(path string, args []string, env []string) (int, error) {
+	cmd := exec.Command(path, args...) // #nosec G204 - plugins are executables the user installed on PATH
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = env
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 1, err
+	}
+	return 0, nil
+}
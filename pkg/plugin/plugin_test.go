@@ -0,0 +1,135 @@
+package plugin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePlugins creates executable scripts with the given names in a
+// directory that becomes the only one on PATH
+func writePlugins(t *testing.T, scripts map[string]string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+
+	dir := t.TempDir()
+	for name, script := range scripts {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0755))
+	}
+	t.Setenv("PATH", dir)
+	return dir
+}
+
+func TestFind(t *testing.T) {
+	dir := writePlugins(t, map[string]string{
+		"dg-foo":     "exit 0",
+		"dg-foo-bar": "exit 0",
+	})
+
+	path, args, ok := Find([]string{"foo", "bar", "baz"})
+	require.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "dg-foo-bar"), path)
+	assert.Equal(t, []string{"baz"}, args)
+
+	path, args, ok = Find([]string{"foo", "--flag", "bar"})
+	require.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "dg-foo"), path)
+	assert.Equal(t, []string{"--flag", "bar"}, args)
+
+	_, _, ok = Find([]string{"missing"})
+	assert.False(t, ok)
+
+	_, _, ok = Find([]string{"--foo"})
+	assert.False(t, ok)
+}
+
+func TestList(t *testing.T) {
+	dir := writePlugins(t, map[string]string{
+		"dg-foo-bar": "exit 0",
+		"dg-baz":     "exit 0",
+		"other":      "exit 0",
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dg-notexec"), []byte("data"), 0644))
+
+	assert.Equal(t, []Plugin{
+		{Name: "baz", Path: filepath.Join(dir, "dg-baz")},
+		{Name: "foo bar", Path: filepath.Join(dir, "dg-foo-bar")},
+	}, List())
+}
+
+func TestRun(t *testing.T) {
+	dir := writePlugins(t, map[string]string{
+		"dg-fail": `echo "$1 $DEVGRAPH_API_URL" > "$0.out"; exit 3`,
+	})
+
+	code, err := Run(filepath.Join(dir, "dg-fail"), []string{"hello"}, []string{"DEVGRAPH_API_URL=https://api.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, code)
+
+	out, err := os.ReadFile(filepath.Join(dir, "dg-fail.out"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello https://api.example.com\n", string(out))
+}
+
+func TestEnvironment(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// An identity provider for the token manager to discover
+	var issuer *httptest.Server
+	issuer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                 issuer.URL,
+			"authorization_endpoint": issuer.URL + "/authorize",
+			"token_endpoint":         issuer.URL + "/token",
+			"jwks_uri":               issuer.URL + "/jwks",
+		})
+	}))
+	t.Cleanup(issuer.Close)
+
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	claims := jwt.MapClaims{"exp": float64(expiry.Unix())}
+	userConfig, err := config.LoadUserConfig()
+	require.NoError(t, err)
+	userConfig.SetCluster("test", "https://api.example.com", issuer.URL, "test-client")
+	userConfig.SetUser("test", "test-access-token", "", "test-id-token", &claims)
+	userConfig.SetContext("test", "test", "test", "")
+	require.NoError(t, userConfig.UseContext("test"))
+	userConfig.Settings.DefaultEnvironment = "env-uuid"
+	require.NoError(t, config.SaveUserConfig(userConfig))
+	require.NoError(t, config.SaveCredentials(config.Credentials{
+		AccessToken: "test-access-token",
+		IDToken:     "test-id-token",
+		Claims:      &claims,
+	}))
+
+	cfg := config.Config{Retries: -1}
+	cfg.ApplyDefaults()
+
+	env := map[string]string{}
+	for _, v := range Environment(cfg) {
+		name, value, _ := strings.Cut(v, "=")
+		env[name] = value
+	}
+
+	assert.Equal(t, "test", env["DEVGRAPH_CONTEXT"])
+	assert.Equal(t, "https://api.example.com", env["DEVGRAPH_API_URL"])
+	assert.Equal(t, issuer.URL, env["DEVGRAPH_ISSUER_URL"])
+	assert.Equal(t, "env-uuid", env["DEVGRAPH_ENVIRONMENT"])
+	assert.Equal(t, "test-id-token", env["DEVGRAPH_TOKEN"])
+	assert.Equal(t, expiry.Add(-30*time.Second).Format(time.RFC3339), env["DEVGRAPH_TOKEN_EXPIRY"])
+	assert.NotEmpty(t, env["DEVGRAPH_CLI"])
+}
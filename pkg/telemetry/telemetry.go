@@ -0,0 +1,109 @@
+// Package telemetry reports anonymous, opt-in usage of the Devgraph CLI.
+// Only the command name, how long it took, its exit status and the CLI
+// version are sent: never arguments, flags, output or anything identifying
+// the user. Events go to the telemetry_endpoint setting, and nothing is sent
+// until one is configured.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/arctir/devgraph-cli/pkg/config"
+)
+
+// sendTimeout bounds how long the background process waits for an event to
+// be sent
+const sendTimeout = 2 * time.Second
+
+// Event describes one run of a command
+type Event struct {
+	Command    string `json:"command"`
+	DurationMS int64  `json:"duration_ms"`
+	ExitStatus int    `json:"exit_status"`
+	Version    string `json:"version"`
+}
+
+// NewEvent returns the event for a command run. Argument placeholders in
+// command, like "<id>", are dropped.
+func NewEvent(command string, duration time.Duration, exitStatus int, version string) Event {
+	var words []string
+	for _, word := range strings.Fields(command) {
+		if !strings.HasPrefix(word, "<") {
+			words = append(words, word)
+		}
+	}
+
+	return Event{
+		Command:    strings.Join(words, " "),
+		DurationMS: duration.Milliseconds(),
+		ExitStatus: exitStatus,
+		Version:    version,
+	}
+}
+
+// Enabled reports whether the user opted in and configured an endpoint to
+// send events to. DO_NOT_TRACK or DEVGRAPH_TELEMETRY=0 turn telemetry off
+// regardless of the setting.
+func Enabled(settings config.UserSettings) bool {
+	if value := os.Getenv("DO_NOT_TRACK"); value != "" && value != "0" {
+		return false
+	}
+	switch strings.ToLower(os.Getenv("DEVGRAPH_TELEMETRY")) {
+	case "0", "false", "off":
+		return false
+	}
+	return settings.Telemetry && settings.TelemetryEndpoint != ""
+}
+
+// Start sends event from a background 'telemetry send' run of executable,
+// which should be the CLI itself, so the caller can exit without waiting
+// for the endpoint to respond
+func Start(executable string, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(executable, "telemetry", "send", string(body))
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+// Send posts event to endpoint. Telemetry never gets in the way of the
+// command, so callers should ignore the error beyond logging it.
+func Send(client *http.Client, endpoint string, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return nil
+}
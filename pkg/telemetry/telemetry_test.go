@@ -0,0 +1,75 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEvent(t *testing.T) {
+	event := NewEvent("entity get <id>", 1500*time.Millisecond, 1, "1.2.3")
+	assert.Equal(t, Event{
+		Command:    "entity get",
+		DurationMS: 1500,
+		ExitStatus: 1,
+		Version:    "1.2.3",
+	}, event)
+}
+
+func TestEnabled(t *testing.T) {
+	t.Setenv("DO_NOT_TRACK", "")
+	t.Setenv("DEVGRAPH_TELEMETRY", "")
+
+	optedIn := config.UserSettings{Telemetry: true, TelemetryEndpoint: "https://collector.example/events"}
+	assert.False(t, Enabled(config.UserSettings{}))
+	assert.True(t, Enabled(optedIn))
+	assert.False(t, Enabled(config.UserSettings{Telemetry: true}), "there is no default endpoint")
+
+	t.Setenv("DEVGRAPH_TELEMETRY", "off")
+	assert.False(t, Enabled(optedIn))
+
+	t.Setenv("DEVGRAPH_TELEMETRY", "")
+	t.Setenv("DO_NOT_TRACK", "1")
+	assert.False(t, Enabled(optedIn))
+}
+
+func TestSend(t *testing.T) {
+	var method, path, contentType string
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, contentType = r.Method, r.URL.Path, r.Header.Get("Content-Type")
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	err := Send(http.DefaultClient, srv.URL+"/events", NewEvent("token list", time.Second, 0, "dev"))
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodPost, method)
+	assert.Equal(t, "/events", path)
+	assert.Equal(t, "application/json", contentType)
+	assert.Equal(t, "token list", body["command"])
+	assert.Equal(t, float64(1000), body["duration_ms"])
+	assert.Equal(t, float64(0), body["exit_status"])
+	assert.Equal(t, "dev", body["version"])
+	assert.Len(t, body, 4)
+}
+
+func TestSend_Failure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	err := Send(http.DefaultClient, srv.URL, NewEvent("token list", time.Second, 0, "dev"))
+	assert.ErrorContains(t, err, "404")
+}
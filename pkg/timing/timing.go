@@ -0,0 +1,116 @@
+// Package timing measures where a command spends its time: how long it
+// waits on authentication and the API for --timings, and CPU and memory
+// profiles for --profile-cpu and --profile-mem.
+package timing
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Phase is a kind of work a command waits on
+type Phase string
+
+// Phases measured while a command runs. Time not spent in either is local
+// work, mostly rendering output.
+const (
+	Auth Phase = "auth"
+	API  Phase = "api"
+)
+
+// phase is the time spent in a Phase so far
+type phase struct {
+	active  int
+	started time.Time
+	total   time.Duration
+	count   int
+}
+
+var (
+	mu      sync.Mutex
+	enabled bool
+	phases  = map[Phase]*phase{}
+)
+
+// Enable starts recording phases. Until it's called, Start does nothing.
+func Enable() {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = true
+}
+
+// Reset stops recording and forgets the phases recorded so far
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = false
+	phases = map[Phase]*phase{}
+}
+
+// Start records the start of work in p and returns a function recording its
+// end. Overlapping work in the same phase, like concurrent requests, counts
+// once, so a phase's total is the time something was waiting on it.
+func Start(p Phase) func() {
+	mu.Lock()
+	defer mu.Unlock()
+	if !enabled {
+		return func() {}
+	}
+
+	current := phases[p]
+	if current == nil {
+		current = &phase{}
+		phases[p] = current
+	}
+	current.count++
+	if current.active == 0 {
+		current.started = time.Now()
+	}
+	current.active++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			mu.Lock()
+			defer mu.Unlock()
+			current.active--
+			if current.active == 0 {
+				current.total += time.Since(current.started)
+			}
+		})
+	}
+}
+
+// Total returns the time spent in p and how many times it was started
+func Total(p Phase) (time.Duration, int) {
+	mu.Lock()
+	defer mu.Unlock()
+	if current := phases[p]; current != nil {
+		return current.total, current.count
+	}
+	return 0, 0
+}
+
+// Report writes how long the command took: startup before it ran, the
+// phases recorded while it ran for run, and the rest of run
+func Report(w io.Writer, startup, run time.Duration) {
+	auth, _ := Total(Auth)
+	api, requests := Total(API)
+	other := max(run-auth-api, 0)
+
+	fmt.Fprintln(w, "Timings:")
+	fmt.Fprintf(w, "  startup  %8s  parsing flags and loading config\n", formatDuration(startup))
+	fmt.Fprintf(w, "  auth     %8s  identity provider discovery and token refresh\n", formatDuration(auth))
+	fmt.Fprintf(w, "  api      %8s  %d API requests\n", formatDuration(api), requests)
+	fmt.Fprintf(w, "  other    %8s  rendering and other local work\n", formatDuration(other))
+	fmt.Fprintf(w, "  total    %8s\n", formatDuration(startup+run))
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
@@ -0,0 +1,73 @@
+package timing
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStart_Disabled(t *testing.T) {
+	t.Cleanup(Reset)
+
+	Start(API)()
+	total, count := Total(API)
+	assert.Zero(t, total)
+	assert.Zero(t, count)
+}
+
+func TestStart_OverlappingWorkCountsOnce(t *testing.T) {
+	t.Cleanup(Reset)
+	Enable()
+
+	first := Start(API)
+	time.Sleep(10 * time.Millisecond)
+	second := Start(API)
+	time.Sleep(10 * time.Millisecond)
+	first()
+	second()
+	second()
+
+	total, count := Total(API)
+	assert.Equal(t, 2, count)
+	assert.GreaterOrEqual(t, total, 20*time.Millisecond)
+	assert.Less(t, total, 40*time.Millisecond)
+
+	auth, _ := Total(Auth)
+	assert.Zero(t, auth)
+}
+
+func TestReport(t *testing.T) {
+	t.Cleanup(Reset)
+	Enable()
+	Start(API)()
+	Start(API)()
+
+	var out bytes.Buffer
+	Report(&out, 5*time.Millisecond, 120*time.Millisecond)
+	assert.Contains(t, out.String(), "startup       5ms")
+	assert.Contains(t, out.String(), "2 API requests")
+	assert.Contains(t, out.String(), "total       125ms")
+}
+
+func TestStartProfiles(t *testing.T) {
+	dir := t.TempDir()
+	cpu, mem := filepath.Join(dir, "cpu.pprof"), filepath.Join(dir, "mem.pprof")
+
+	stop, err := StartProfiles(cpu, mem)
+	require.NoError(t, err)
+	require.NoError(t, stop())
+
+	for _, path := range []string{cpu, mem} {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.NotZero(t, info.Size(), path)
+	}
+
+	_, err = StartProfiles(filepath.Join(dir, "missing", "cpu.pprof"), "")
+	assert.ErrorContains(t, err, "failed to create CPU profile")
+}
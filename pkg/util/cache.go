@@ -0,0 +1,148 @@
+package util
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/arctir/devgraph-cli/pkg/logging"
+)
+
+// cacheTransport wraps an http.RoundTripper and keeps successful GET
+// responses on disk. Cached responses are used for ttl, then revalidated
+// with their ETag when they have one. Entries are keyed by URL, credentials
+// and environment, so users and environments never see each other's
+// responses. Any change made through the API invalidates the whole cache.
+type cacheTransport struct {
+	transport http.RoundTripper
+	dir       string
+	ttl       time.Duration
+	// read is whether responses may come from the cache. When false, the
+	// transport only invalidates it.
+	read bool
+	// apiHost is the API server, whose changes invalidate the cache
+	apiHost string
+}
+
+// cacheEntry is a cached response
+type cacheEntry struct {
+	URL      string      `json:"url"`
+	StoredAt time.Time   `json:"stored_at"`
+	ETag     string      `json:"etag,omitempty"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		resp, err := t.transport.RoundTrip(req)
+		if err == nil && !isReadOnly(req.Method) && req.URL.Host == t.apiHost && resp.StatusCode < 300 {
+			t.clear()
+		}
+		return resp, err
+	}
+	if !t.read {
+		return t.transport.RoundTrip(req)
+	}
+
+	path := filepath.Join(t.dir, cacheKey(req)+".json")
+	entry, ok := t.load(path)
+	if ok && time.Since(entry.StoredAt) < t.ttl {
+		logging.Debug("using cached response", "url", redactURL(req.URL), "age", time.Since(entry.StoredAt).Round(time.Millisecond))
+		return entry.response(req), nil
+	}
+
+	if ok && entry.ETag != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case ok && resp.StatusCode == http.StatusNotModified:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		entry.StoredAt = time.Now()
+		t.store(path, entry)
+		logging.Debug("using revalidated cached response", "url", redactURL(req.URL))
+		return entry.response(req), nil
+	case resp.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		t.store(path, cacheEntry{
+			URL:      redactURL(req.URL),
+			StoredAt: time.Now(),
+			ETag:     resp.Header.Get("ETag"),
+			Header:   resp.Header.Clone(),
+			Body:     body,
+		})
+	}
+	return resp, nil
+}
+
+// cacheKey identifies a request's response among those of every URL, user
+// and environment
+func cacheKey(req *http.Request) string {
+	hash := sha256.New()
+	for _, part := range []string{req.URL.String(), req.Header.Get("Authorization"), req.Header.Get("Devgraph-Environment")} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (t *cacheTransport) load(path string) (cacheEntry, bool) {
+	var entry cacheEntry
+	data, err := os.ReadFile(path) // #nosec G304 - path is derived from a hash
+	if err != nil || json.Unmarshal(data, &entry) != nil {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+// store saves entry, ignoring failures: the cache only saves time
+func (t *cacheTransport) store(path string, entry cacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(t.dir, 0700); err != nil {
+		return
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		logging.Debug("failed to cache response", "error", err)
+	}
+}
+
+func (t *cacheTransport) clear() {
+	if err := os.RemoveAll(t.dir); err != nil {
+		logging.Debug("failed to clear response cache", "error", err)
+	}
+}
+
+func (e cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
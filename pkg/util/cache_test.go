@@ -0,0 +1,111 @@
+package util
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cacheServer serves a body with an ETag, answering If-None-Match with 304,
+// and counts the requests it gets by method
+func cacheServer(t *testing.T) (*httptest.Server, map[string]int) {
+	t.Helper()
+	var mu sync.Mutex
+	counts := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		counts[r.Method]++
+		mu.Unlock()
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = io.WriteString(w, "body for "+r.Header.Get("Authorization"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, counts
+}
+
+func newCacheClient(t *testing.T, srv *httptest.Server, ttl time.Duration) (*http.Client, *cacheTransport) {
+	t.Helper()
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	cache := &cacheTransport{transport: http.DefaultTransport, dir: t.TempDir(), ttl: ttl, read: true, apiHost: u.Host}
+	return &http.Client{Transport: cache}, cache
+}
+
+func get(t *testing.T, client *http.Client, url, token string) string {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", token)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestCacheTransport_ServesFreshResponses(t *testing.T) {
+	srv, counts := cacheServer(t)
+	client, _ := newCacheClient(t, srv, time.Minute)
+
+	assert.Equal(t, "body for a", get(t, client, srv.URL+"/api/v1/environments", "a"))
+	assert.Equal(t, "body for a", get(t, client, srv.URL+"/api/v1/environments", "a"))
+	assert.Equal(t, 1, counts[http.MethodGet])
+
+	// Other credentials don't share entries
+	assert.Equal(t, "body for b", get(t, client, srv.URL+"/api/v1/environments", "b"))
+	assert.Equal(t, 2, counts[http.MethodGet])
+}
+
+func TestCacheTransport_RevalidatesWithETag(t *testing.T) {
+	srv, counts := cacheServer(t)
+	client, _ := newCacheClient(t, srv, time.Nanosecond)
+
+	assert.Equal(t, "body for a", get(t, client, srv.URL+"/api/v1/models", "a"))
+	time.Sleep(time.Millisecond)
+	assert.Equal(t, "body for a", get(t, client, srv.URL+"/api/v1/models", "a"))
+	assert.Equal(t, 2, counts[http.MethodGet])
+}
+
+func TestCacheTransport_ChangesClearCache(t *testing.T) {
+	srv, counts := cacheServer(t)
+	client, cache := newCacheClient(t, srv, time.Minute)
+
+	get(t, client, srv.URL+"/api/v1/environments", "a")
+	resp, err := client.Post(srv.URL+"/api/v1/environments", "application/json", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	_, err = os.Stat(cache.dir)
+	assert.True(t, os.IsNotExist(err))
+	get(t, client, srv.URL+"/api/v1/environments", "a")
+	assert.Equal(t, 2, counts[http.MethodGet])
+}
+
+func TestCacheTransport_ReadDisabled(t *testing.T) {
+	srv, counts := cacheServer(t)
+	client, cache := newCacheClient(t, srv, time.Minute)
+	cache.read = false
+
+	get(t, client, srv.URL+"/api/v1/environments", "a")
+	get(t, client, srv.URL+"/api/v1/environments", "a")
+	assert.Equal(t, 2, counts[http.MethodGet])
+}
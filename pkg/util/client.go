@@ -1,92 +1,108 @@
 package util
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/arctir/devgraph-cli/pkg/auth"
 	"github.com/arctir/devgraph-cli/pkg/config"
+	"github.com/arctir/devgraph-cli/pkg/timing"
 	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
 )
 
-// debugTransport wraps an http.RoundTripper and logs requests/responses when debug is enabled
-type debugTransport struct {
-	transport http.RoundTripper
-	debug     bool
-}
+// ErrOffline is returned instead of a client when --offline is given, so
+// commands that need the API fail before trying to reach it
+var ErrOffline = errors.New("this command needs the API and can't run with --offline; only entity list, get and relationships can read the last backup")
 
-func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	if t.debug {
-		fmt.Printf("\n--- HTTP Request ---\n")
-		fmt.Printf("%s %s\n", req.Method, req.URL.String())
-		fmt.Printf("Headers:\n")
-		for k, v := range req.Header {
-			// Don't log sensitive headers
-			if k == "Authorization" {
-				fmt.Printf("  %s: [REDACTED]\n", k)
-			} else {
-				fmt.Printf("  %s: %v\n", k, v)
-			}
-		}
+// GetAuthenticatedHTTPClient returns an HTTP client configured with authentication
+// for making requests to Devgraph API endpoints. The client automatically handles
+// token refresh and includes necessary headers for API communication.
+func GetAuthenticatedHTTPClient(cfg config.Config) (*http.Client, error) {
+	if cfg.Offline {
+		return nil, ErrOffline
+	}
 
-		if req.Body != nil {
-			bodyBytes, _ := io.ReadAll(req.Body)
-			req.Body.Close()
-			req.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
-			if len(bodyBytes) > 0 {
-				fmt.Printf("Body: %s\n", string(bodyBytes))
+	// Log requests beneath authentication, so the logged headers are the
+	// ones sent. Logs written to a file are structured, one JSON object per
+	// request and response.
+	var wrap []func(http.RoundTripper) http.RoundTripper
+	if cfg.Debug || cfg.DebugFile != "" {
+		debug := &debugTransport{out: os.Stdout}
+		if cfg.DebugFile != "" {
+			file, err := os.OpenFile(cfg.DebugFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600) // #nosec G304 - path is provided by the user
+			if err != nil {
+				return nil, fmt.Errorf("failed to open debug file: %w", err)
 			}
+			debug.out = file
+			debug.structured = true
 		}
+		wrap = append(wrap, func(transport http.RoundTripper) http.RoundTripper {
+			debug.transport = transport
+			return debug
+		})
 	}
 
-	resp, err := t.transport.RoundTrip(req)
-
-	if t.debug && resp != nil {
-		fmt.Printf("\n--- HTTP Response ---\n")
-		fmt.Printf("Status: %s\n", resp.Status)
-		fmt.Printf("Headers:\n")
-		for k, v := range resp.Header {
-			fmt.Printf("  %s: %v\n", k, v)
-		}
-
-		if resp.Body != nil {
-			bodyBytes, _ := io.ReadAll(resp.Body)
-			resp.Body.Close()
-			resp.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
-			if len(bodyBytes) > 0 && len(bodyBytes) < 10000 { // Only log if not too large
-				fmt.Printf("Body: %s\n", string(bodyBytes))
-			} else if len(bodyBytes) > 0 {
-				fmt.Printf("Body: [%d bytes]\n", len(bodyBytes))
+	// Cache GET responses for lookups repeated in quick succession, beneath
+	// authentication so entries are keyed by the credentials sent
+	if cfg.CacheTTL > 0 {
+		if dir, err := config.GetUserCacheDir(); err == nil {
+			cache := &cacheTransport{
+				dir:  filepath.Join(dir, "responses"),
+				ttl:  cfg.CacheTTL,
+				read: cfg.CacheResponses,
 			}
+			if apiURL, err := url.Parse(cfg.ApiURL); err == nil {
+				cache.apiHost = apiURL.Host
+			}
+			wrap = append(wrap, func(transport http.RoundTripper) http.RoundTripper {
+				cache.transport = transport
+				return cache
+			})
 		}
-		fmt.Printf("---\n\n")
 	}
 
-	return resp, err
-}
+	// Time API requests beneath authentication, so token refreshes are
+	// counted as authentication
+	if apiURL, err := url.Parse(cfg.ApiURL); err == nil {
+		wrap = append(wrap, func(transport http.RoundTripper) http.RoundTripper {
+			return &timingTransport{transport: transport, apiHost: apiURL.Host}
+		})
+	}
 
-// GetAuthenticatedHTTPClient returns an HTTP client configured with authentication
-// for making requests to Devgraph API endpoints. The client automatically handles
-// token refresh and includes necessary headers for API communication.
-func GetAuthenticatedHTTPClient(cfg config.Config) (*http.Client, error) {
-	// Use the token manager for automatic refresh
-	client, err := auth.AuthenticatedClient(cfg)
+	// Use the token manager for automatic refresh. Creating it discovers
+	// the identity provider's endpoints.
+	stopAuth := timing.Start(timing.Auth)
+	client, err := auth.AuthenticatedClient(cfg, wrap...)
+	stopAuth()
 	if err != nil {
 		return nil, err
 	}
 
-	// Wrap transport with debug logging if enabled
-	if cfg.Debug {
+	// Retry transient failures, and slow down when rate limited or asked
+	// to with --max-requests-per-second
+	if client.Transport == nil {
+		client.Transport = http.DefaultTransport
+	}
+	client.Transport = &retryTransport{
+		transport: client.Transport,
+		retries:   max(cfg.Retries, 0),
+		limiter:   newRateLimiter(cfg.MaxRequestsPerSecond),
+	}
+
+	// Print changes instead of making them
+	if cfg.DryRun {
 		if client.Transport == nil {
 			client.Transport = http.DefaultTransport
 		}
-		client.Transport = &debugTransport{
+		client.Transport = &dryRunTransport{
 			transport: client.Transport,
-			debug:     true,
+			out:       os.Stdout,
 		}
 	}
 
@@ -116,15 +132,32 @@ func (s *DevgraphSecuritySource) OAuth2PasswordBearer(ctx context.Context, opera
 // methods for interacting with Devgraph API endpoints.
 // It supports both context-based and legacy configuration.
 func GetAuthenticatedClient(cfg config.Config) (*api.Client, error) {
+	cfg, err := ResolveContextConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := GetAuthenticatedHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	securitySource := &DevgraphSecuritySource{config: cfg}
+	return api.NewClient(cfg.ApiURL, securitySource, api.WithClient(httpClient))
+}
+
+// ResolveContextConfig returns cfg with the API URL, issuer, client ID and TLS
+// settings of the current context's cluster, when a context is in use
+func ResolveContextConfig(cfg config.Config) (config.Config, error) {
 	// Load user config to check for contexts
 	userConfig, err := config.LoadUserConfig()
 	if err != nil {
-		return nil, fmt.Errorf("failed to load user config: %w", err)
+		return cfg, fmt.Errorf("failed to load user config: %w", err)
 	}
 
 	// If using contexts, override config with context settings
 	if userConfig.CurrentContext != "" {
-		context, cluster, user, err := userConfig.GetCurrentContext()
+		_, cluster, _, err := userConfig.GetCurrentContext()
 		if err == nil {
 			// Override API URL from cluster
 			if cluster.Server != "" {
@@ -136,21 +169,15 @@ func GetAuthenticatedClient(cfg config.Config) (*api.Client, error) {
 			if cluster.ClientID != "" {
 				cfg.ClientID = cluster.ClientID
 			}
-
+			if cfg.CACert == "" {
+				cfg.CACert = cluster.CertificateAuthority
+			}
+			cfg.InsecureSkipTLSVerify = cluster.InsecureSkipTLSVerify
 			// Note: user credentials are loaded separately via LoadCredentials
 			// Environment UUID is loaded from userConfig.Settings.DefaultEnvironment
-			_ = user
-			_ = context
 		}
 	}
-
-	httpClient, err := GetAuthenticatedHTTPClient(cfg)
-	if err != nil {
-		return nil, err
-	}
-
-	securitySource := &DevgraphSecuritySource{config: cfg}
-	return api.NewClient(cfg.ApiURL, securitySource, api.WithClient(httpClient))
+	return cfg, nil
 }
 
 // IsAuthenticated checks if the user has valid authentication credentials.
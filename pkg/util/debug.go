@@ -0,0 +1,248 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// redacted replaces credentials in debug logs
+const redacted = "[REDACTED]"
+
+// maxLoggedBody is the largest response body printed in full
+const maxLoggedBody = 10000
+
+// debugTransport wraps an http.RoundTripper and logs requests and responses
+// with credentials redacted. Structured logs are written as one JSON object
+// per request and per response.
+type debugTransport struct {
+	transport  http.RoundTripper
+	out        io.Writer
+	structured bool
+}
+
+// debugEntry is a structured log of a request or response
+type debugEntry struct {
+	Time     time.Time           `json:"time"`
+	Type     string              `json:"type"`
+	Method   string              `json:"method,omitempty"`
+	URL      string              `json:"url,omitempty"`
+	Status   int                 `json:"status,omitempty"`
+	Duration string              `json:"duration,omitempty"`
+	Headers  map[string][]string `json:"headers,omitempty"`
+	Body     string              `json:"body,omitempty"`
+	Error    string              `json:"error,omitempty"`
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		reqBody, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	t.logRequest(req, reqBody)
+
+	start := time.Now()
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		t.logError(req, err)
+		return resp, err
+	}
+
+	var respBody []byte
+	if resp.Body != nil {
+		respBody, _ = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	}
+	t.logResponse(resp, respBody, time.Since(start))
+
+	return resp, err
+}
+
+func (t *debugTransport) logRequest(req *http.Request, body []byte) {
+	entry := debugEntry{
+		Time:    time.Now(),
+		Type:    "request",
+		Method:  req.Method,
+		URL:     redactURL(req.URL),
+		Headers: redactHeaders(req.Header),
+		Body:    redactBody(body, req.Header.Get("Content-Type")),
+	}
+	if t.structured {
+		t.write(entry)
+		return
+	}
+
+	fmt.Fprintf(t.out, "\n--- HTTP Request ---\n")
+	fmt.Fprintf(t.out, "%s %s\n", entry.Method, entry.URL)
+	t.printHeaders(entry.Headers)
+	if entry.Body != "" {
+		fmt.Fprintf(t.out, "Body: %s\n", entry.Body)
+	}
+}
+
+func (t *debugTransport) logResponse(resp *http.Response, body []byte, duration time.Duration) {
+	entry := debugEntry{
+		Time:     time.Now(),
+		Type:     "response",
+		Method:   resp.Request.Method,
+		URL:      redactURL(resp.Request.URL),
+		Status:   resp.StatusCode,
+		Duration: duration.String(),
+		Headers:  redactHeaders(resp.Header),
+	}
+	if len(body) < maxLoggedBody {
+		entry.Body = redactBody(body, resp.Header.Get("Content-Type"))
+	} else {
+		entry.Body = fmt.Sprintf("[%d bytes]", len(body))
+	}
+	if t.structured {
+		t.write(entry)
+		return
+	}
+
+	fmt.Fprintf(t.out, "\n--- HTTP Response ---\n")
+	fmt.Fprintf(t.out, "Status: %s\n", resp.Status)
+	t.printHeaders(entry.Headers)
+	if entry.Body != "" {
+		fmt.Fprintf(t.out, "Body: %s\n", entry.Body)
+	}
+	fmt.Fprintf(t.out, "---\n\n")
+}
+
+func (t *debugTransport) logError(req *http.Request, err error) {
+	entry := debugEntry{
+		Time:   time.Now(),
+		Type:   "error",
+		Method: req.Method,
+		URL:    redactURL(req.URL),
+		Error:  err.Error(),
+	}
+	if t.structured {
+		t.write(entry)
+		return
+	}
+	fmt.Fprintf(t.out, "\n--- HTTP Error ---\n%s\n---\n\n", entry.Error)
+}
+
+func (t *debugTransport) printHeaders(headers map[string][]string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(t.out, "Headers:\n")
+	for _, name := range names {
+		fmt.Fprintf(t.out, "  %s: %v\n", name, headers[name])
+	}
+}
+
+func (t *debugTransport) write(entry debugEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	_, _ = t.out.Write(append(data, '\n'))
+}
+
+// isSensitive reports whether a header, query parameter or field name holds
+// a credential
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range []string{"authorization", "cookie", "secret", "password", "credential", "api_key", "api-key", "apikey"} {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return strings.HasSuffix(name, "token")
+}
+
+// redactHeaders returns a copy of headers with credentials replaced
+func redactHeaders(headers http.Header) map[string][]string {
+	result := make(map[string][]string, len(headers))
+	for name, values := range headers {
+		if isSensitive(name) {
+			result[name] = []string{redacted}
+		} else {
+			result[name] = values
+		}
+	}
+	return result
+}
+
+// redactURL returns u with credential query parameters replaced
+func redactURL(u *url.URL) string {
+	query := u.Query()
+	changed := false
+	for name := range query {
+		if isSensitive(name) {
+			query.Set(name, redacted)
+			changed = true
+		}
+	}
+	if !changed {
+		return u.String()
+	}
+
+	copied := *u
+	copied.RawQuery = query.Encode()
+	return copied.String()
+}
+
+// redactBody returns body with credential fields of JSON and form encoded
+// content replaced
+func redactBody(body []byte, contentType string) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		form, err := url.ParseQuery(string(body))
+		if err == nil {
+			for name := range form {
+				if isSensitive(name) {
+					form.Set(name, redacted)
+				}
+			}
+			return form.Encode()
+		}
+	}
+
+	var value any
+	if json.Unmarshal(body, &value) == nil {
+		if data, err := json.Marshal(redactValue(value)); err == nil {
+			return string(data)
+		}
+	}
+
+	return string(body)
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if isSensitive(key) {
+				if _, ok := field.(string); ok {
+					v[key] = redacted
+					continue
+				}
+			}
+			v[key] = redactValue(field)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,65 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugTransport_Structured(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc")
+		_, _ = w.Write([]byte(`{"access_token":"issued-token","token_type":"Bearer","max_tokens":100}`))
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	client := &http.Client{Transport: &debugTransport{transport: http.DefaultTransport, out: &out, structured: true}}
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/api/v1/providers?api_key=query-key&limit=5", strings.NewReader(`{"name":"github","config":{"token":"ghp_secret","base_url":"https://api.github.com"}}`))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer id-token")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	log := out.String()
+	for _, secret := range []string{"id-token", "query-key", "ghp_secret", "issued-token", "session=abc"} {
+		assert.NotContains(t, log, secret)
+	}
+
+	lines := strings.Split(strings.TrimSpace(log), "\n")
+	require.Len(t, lines, 2)
+
+	var request, response debugEntry
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &request))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &response))
+
+	assert.Equal(t, "request", request.Type)
+	assert.Equal(t, http.MethodPost, request.Method)
+	assert.Contains(t, request.URL, "limit=5")
+	assert.Equal(t, []string{redacted}, request.Headers["Authorization"])
+	assert.Equal(t, `{"config":{"base_url":"https://api.github.com","token":"[REDACTED]"},"name":"github"}`, request.Body)
+
+	assert.Equal(t, "response", response.Type)
+	assert.Equal(t, http.StatusOK, response.Status)
+	assert.NotEmpty(t, response.Duration)
+	assert.Equal(t, `{"access_token":"[REDACTED]","max_tokens":100,"token_type":"Bearer"}`, response.Body)
+}
+
+func TestRedactBody_Form(t *testing.T) {
+	body := redactBody([]byte("grant_type=refresh_token&refresh_token=abc&client_secret=def"), "application/x-www-form-urlencoded")
+	assert.Equal(t, "client_secret=%5BREDACTED%5D&grant_type=refresh_token&refresh_token=%5BREDACTED%5D", body)
+}
+
+func TestRedactBody_PlainText(t *testing.T) {
+	assert.Equal(t, "not json", redactBody([]byte("not json"), "text/plain"))
+}
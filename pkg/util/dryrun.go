@@ -0,0 +1,61 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrDryRun is returned for requests that would have changed something
+// when --dry-run is given. Commands stop at the first one, and main treats
+// it as success.
+var ErrDryRun = errors.New("dry run: no changes made")
+
+// dryRunTransport wraps an http.RoundTripper and prints requests that would
+// create, update or delete something instead of sending them. Reads are sent
+// as usual, so commands can still look up what they would change.
+type dryRunTransport struct {
+	transport http.RoundTripper
+	out       io.Writer
+}
+
+func (t *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if isReadOnly(req.Method) {
+		return t.transport.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+	}
+
+	fmt.Fprintf(t.out, "Dry run: Would %s (%s %s)\n", describeMethod(req.Method), req.Method, req.URL.Path)
+	if len(body) > 0 {
+		fmt.Fprintf(t.out, "  Body: %s\n", redactBody(body, req.Header.Get("Content-Type")))
+	}
+	return nil, ErrDryRun
+}
+
+func isReadOnly(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// describeMethod names the change a request method makes
+func describeMethod(method string) string {
+	switch method {
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut, http.MethodPatch:
+		return "update"
+	case http.MethodDelete:
+		return "delete"
+	}
+	return strings.ToLower(method)
+}
@@ -0,0 +1,41 @@
+package util
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRunTransport(t *testing.T) {
+	var methods []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+	}))
+	t.Cleanup(srv.Close)
+
+	var out bytes.Buffer
+	client := &http.Client{Transport: &dryRunTransport{transport: http.DefaultTransport, out: &out}}
+
+	resp, err := client.Get(srv.URL + "/api/v1/tokens")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	body := `{"name":"ci","token":"secret-value"}`
+	_, err = client.Post(srv.URL+"/api/v1/tokens", "application/json", strings.NewReader(body))
+	require.ErrorIs(t, err, ErrDryRun)
+
+	req, err := http.NewRequest(http.MethodDelete, srv.URL+"/api/v1/tokens/abc", nil)
+	require.NoError(t, err)
+	_, err = client.Do(req)
+	require.ErrorIs(t, err, ErrDryRun)
+
+	assert.Equal(t, []string{http.MethodGet}, methods)
+	assert.Equal(t, "Dry run: Would create (POST /api/v1/tokens)\n"+
+		`  Body: {"name":"ci","token":"[REDACTED]"}`+"\n"+
+		"Dry run: Would delete (DELETE /api/v1/tokens/abc)\n", out.String())
+}
@@ -11,6 +11,17 @@ import (
 // OpenEditor opens a temporary file in the user's preferred editor
 // and returns the content after the user closes the editor.
 func OpenEditor(initialContent string) (string, error) {
+	return openEditor(initialContent, "devgraph-prompt-*.txt")
+}
+
+// EditYAML opens YAML in the user's preferred editor, in a .yaml file so
+// editors highlight it, and returns the content after the editor closes
+func EditYAML(content string) (string, error) {
+	return openEditor(content, "devgraph-edit-*.yaml")
+}
+
+// openEditor edits initialContent in a temporary file named by pattern
+func openEditor(initialContent, pattern string) (string, error) {
 	// Get the editor command from environment variables
 	editor := getEditorCommand()
 	if editor == "" {
@@ -18,7 +29,7 @@ func OpenEditor(initialContent string) (string, error) {
 	}
 
 	// Create a temporary file
-	tmpFile, err := ioutil.TempFile("", "devgraph-prompt-*.txt")
+	tmpFile, err := ioutil.TempFile("", pattern)
 	if err != nil {
 		return "", fmt.Errorf("failed to create temporary file: %w", err)
 	}
@@ -2,7 +2,10 @@ package util
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/arctir/devgraph-cli/pkg/config"
 	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
@@ -41,12 +44,52 @@ func GetEnvironments(config config.Config) (*[]api.EnvironmentResponse, error) {
 	switch r := resp.(type) {
 	case *api.GetEnvironmentsOKApplicationJSON:
 		envs := []api.EnvironmentResponse(*r)
+		saveEnvironmentSlugs(envs)
 		return &envs, nil
 	default:
 		return nil, fmt.Errorf("failed to fetch environments")
 	}
 }
 
+// saveEnvironmentSlugs remembers the slug of each environment in the cache
+// directory, for showing environments without asking the API
+func saveEnvironmentSlugs(envs []api.EnvironmentResponse) {
+	dir, err := config.GetUserCacheDir()
+	if err != nil {
+		return
+	}
+	slugs := make(map[string]string, len(envs))
+	for _, env := range envs {
+		slugs[env.ID.String()] = env.Slug
+	}
+	data, err := json.Marshal(slugs)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(dir, 0700); err == nil {
+		_ = os.WriteFile(filepath.Join(dir, "environments.json"), data, 0600)
+	}
+}
+
+// EnvironmentSlug returns the slug of the environment with the given UUID
+// as of the last time environments were listed, or "" if it isn't known.
+// It only reads the cache directory, so it's cheap enough for shell prompts.
+func EnvironmentSlug(environmentID string) string {
+	dir, err := config.GetUserCacheDir()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "environments.json")) // #nosec G304 - path is in the user cache directory
+	if err != nil {
+		return ""
+	}
+	var slugs map[string]string
+	if json.Unmarshal(data, &slugs) != nil {
+		return ""
+	}
+	return slugs[environmentID]
+}
+
 // CheckEnvironment validates that an environment is set in user settings.
 // Returns true if an environment is configured, false otherwise.
 func CheckEnvironment(cfg *config.Config) (bool, error) {
@@ -85,22 +128,39 @@ func ValidateEnvironment(config config.Config, environmentID string) error {
 //
 // Returns the UUID of the matching environment, or an error if no match is found.
 func ResolveEnvironmentUUID(config config.Config, environmentIdentifier string) (string, error) {
+	// Environments rarely change, so resolving names for every command can
+	// use a recent list
+	config.CacheResponses = true
 	envs, err := GetEnvironments(config)
 	if err != nil {
 		return "", fmt.Errorf("failed to get environments: %w", err)
 	}
 
-	if envs == nil || len(*envs) == 0 {
+	if envs == nil {
 		return "", &NoEnvironmentError{}
 	}
 
-	for _, env := range *envs {
+	env, err := FindEnvironment(*envs, environmentIdentifier)
+	if err != nil {
+		return "", err
+	}
+	return env.ID.String(), nil
+}
+
+// FindEnvironment returns the environment whose UUID, slug, or name matches
+// environmentIdentifier from an already-fetched list of environments.
+func FindEnvironment(envs []api.EnvironmentResponse, environmentIdentifier string) (*api.EnvironmentResponse, error) {
+	if len(envs) == 0 {
+		return nil, &NoEnvironmentError{}
+	}
+
+	for i, env := range envs {
 		if env.ID.String() == environmentIdentifier || env.Slug == environmentIdentifier || env.Name == environmentIdentifier {
-			return env.ID.String(), nil
+			return &envs[i], nil
 		}
 	}
 
-	return "", fmt.Errorf("environment '%s' not found. Available environments: %v", environmentIdentifier, getEnvironmentList(*envs))
+	return nil, fmt.Errorf("environment '%s' not found. Available environments: %v", environmentIdentifier, getEnvironmentList(envs))
 }
 
 // getEnvironmentList returns a list of environment names/slugs for error messages.
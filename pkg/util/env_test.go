@@ -5,7 +5,10 @@ import (
 	"testing"
 
 	"github.com/arctir/devgraph-cli/pkg/config"
+	api "github.com/arctir/go-devgraph/pkg/apis/devgraph/v1"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNoEnvironmentError(t *testing.T) {
@@ -104,3 +107,23 @@ func TestGetEnvironmentList(t *testing.T) {
 		// to test fully, but this verifies the function signature exists
 	})
 }
+
+func TestFindEnvironment(t *testing.T) {
+	prodID := uuid.New()
+	envs := []api.EnvironmentResponse{
+		{ID: uuid.New(), Name: "Staging", Slug: "staging"},
+		{ID: prodID, Name: "Production", Slug: "prod"},
+	}
+
+	for _, identifier := range []string{prodID.String(), "prod", "Production"} {
+		env, err := FindEnvironment(envs, identifier)
+		require.NoError(t, err)
+		assert.Equal(t, prodID, env.ID)
+	}
+
+	_, err := FindEnvironment(envs, "missing")
+	assert.Error(t, err)
+
+	_, err = FindEnvironment(nil, "prod")
+	assert.IsType(t, &NoEnvironmentError{}, err)
+}
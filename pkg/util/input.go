@@ -0,0 +1,19 @@
+package util
+
+import (
+	"io"
+	"os"
+)
+
+// StdinFile is the file name that commands read as stdin, as in
+// 'dg entity create -'
+const StdinFile = "-"
+
+// ReadFileOrStdin reads the named file, or stdin when name is StdinFile, so
+// commands that take a file can be used in pipelines
+func ReadFileOrStdin(name string) ([]byte, error) {
+	if name == StdinFile {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(name) // #nosec G304 - reading user-specified files
+}
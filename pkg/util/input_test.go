@@ -0,0 +1,34 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFileOrStdin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "entity.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"kind":"File"}`), 0600))
+
+	data, err := ReadFileOrStdin(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"kind":"File"}`, string(data))
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+	_, _ = w.WriteString(`{"kind":"Stdin"}`)
+	w.Close()
+
+	data, err = ReadFileOrStdin("-")
+	require.NoError(t, err)
+	assert.Equal(t, `{"kind":"Stdin"}`, string(data))
+
+	_, err = ReadFileOrStdin(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
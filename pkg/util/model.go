@@ -10,6 +10,7 @@ import (
 
 // GetModels retrieves all available models
 func GetModels(config config.Config) (*[]api.ModelResponse, error) {
+	config.CacheResponses = true
 	client, err := GetAuthenticatedClient(config)
 	if err != nil {
 		return nil, err
@@ -4,46 +4,15 @@
 package util
 
 import (
-	"encoding/json"
 	"fmt"
-	"log"
+
 	"os"
 
+	"github.com/arctir/devgraph-cli/pkg/logging"
 	"github.com/fatih/color"
 	"github.com/olekukonko/tablewriter"
-	"gopkg.in/yaml.v3"
 )
 
-// FormatOutput outputs data in the specified format (table, json, yaml)
-// For table output, pass tableData and headers. For json/yaml, pass structuredData.
-func FormatOutput(format string, structuredData interface{}, headers []string, tableData []map[string]any) error {
-	switch format {
-	case "json":
-		output, err := json.MarshalIndent(structuredData, "", "  ")
-		if err != nil {
-			return fmt.Errorf("failed to marshal JSON: %w", err)
-		}
-		fmt.Println(string(output))
-	case "yaml":
-		output, err := yaml.Marshal(structuredData)
-		if err != nil {
-			return fmt.Errorf("failed to marshal YAML: %w", err)
-		}
-		fmt.Print(string(output))
-	case "name":
-		// For name-only output, expect structuredData to be a slice of strings
-		if names, ok := structuredData.([]string); ok {
-			for _, name := range names {
-				fmt.Println(name)
-			}
-		}
-	default:
-		// Table output
-		DisplaySimpleTable(tableData, headers)
-	}
-	return nil
-}
-
 // DisplayTable takes a slice of maps (data) and headers, and displays it as a formatted table.
 // Each map represents a row of data, with keys corresponding to column headers.
 // The function handles different data types (string, int, float64) and formats them appropriately.
@@ -85,7 +54,7 @@ func DisplayTable(data []map[string]interface{}, headers []string) {
 			rowData = append(rowData, value)
 		}
 		if err := table.Append(rowData); err != nil {
-			log.Printf("Warning: Failed to append table row: %v", err)
+			logging.Warn("failed to append table row", "error", err)
 		}
 	}
 
@@ -94,7 +63,7 @@ func DisplayTable(data []map[string]interface{}, headers []string) {
 
 	// Render the table
 	if err := table.Render(); err != nil {
-		log.Printf("Warning: Failed to render table: %v", err)
+		logging.Warn("failed to render table", "error", err)
 	}
 
 	// Add some spacing after the table
@@ -114,3 +83,12 @@ func truncateString(s string, maxLen int) string {
 	}
 	return s[:maxLen-3] + "..."
 }
+
+// OrDash returns s, or "-" when s is empty, for fields in detail views that
+// may be unset.
+func OrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
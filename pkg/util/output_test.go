@@ -207,3 +207,8 @@ func TestDisplayTable_DifferentDataTypes(t *testing.T) {
 	assert.Contains(t, output, "<nil>") // nil value
 	assert.Contains(t, output, "-")     // missing value
 }
+
+func TestOrDash(t *testing.T) {
+	assert.Equal(t, "-", OrDash(""))
+	assert.Equal(t, "pro", OrDash("pro"))
+}
@@ -0,0 +1,105 @@
+package util
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter throttles the requests sent through a client once the API
+// starts rate limiting them, so worker pools slow down instead of failing.
+// A 429 pauses every request until its Retry-After has passed and halves how
+// many requests may be in flight. Once as many requests as the limit have
+// gone through without being rate limited, it's raised by one. Requests are
+// also spaced out to keep under the configured requests per second.
+type rateLimiter struct {
+	mu        sync.Mutex
+	until     time.Time     // no requests are sent before this
+	interval  time.Duration // least time between requests, or 0 for none
+	next      time.Time     // when the next request may be sent
+	limit     int           // most requests in flight, or 0 for no limit
+	inFlight  int           // requests sent and not yet released
+	successes int           // requests not rate limited since the limit last changed
+	wake      chan struct{} // closed when waiting requests should check again
+}
+
+// newRateLimiter returns a limiter sending at most perSecond requests each
+// second, or any number when perSecond is 0
+func newRateLimiter(perSecond float64) *rateLimiter {
+	l := &rateLimiter{wake: make(chan struct{})}
+	if perSecond > 0 {
+		l.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+	return l
+}
+
+// acquire waits until a request may be sent. Every acquire must be followed
+// by a release. A nil rateLimiter never waits.
+func (l *rateLimiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		wait := max(l.until.Sub(now), l.next.Sub(now))
+		if wait <= 0 && (l.limit == 0 || l.inFlight < l.limit) {
+			l.inFlight++
+			l.next = now.Add(l.interval)
+			l.mu.Unlock()
+			return nil
+		}
+		wake := l.wake
+		l.mu.Unlock()
+
+		var timer <-chan time.Time
+		if wait > 0 {
+			timer = time.After(wait)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		case <-timer:
+		}
+	}
+}
+
+// release records whether an acquired request was rate limited, and if so
+// for how long the server asked to wait
+func (l *rateLimiter) release(rateLimited bool, retryAfter time.Duration) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if rateLimited {
+		// Only the first 429 of a burst lowers the limit; the others were
+		// sent before the client knew to slow down
+		now := time.Now()
+		if !now.Before(l.until) {
+			current := l.inFlight
+			if l.limit > 0 && l.limit < current {
+				current = l.limit
+			}
+			l.limit = max(1, current/2)
+			l.successes = 0
+		}
+		if until := now.Add(retryAfter); until.After(l.until) {
+			l.until = until
+		}
+	} else if l.limit > 0 {
+		l.successes++
+		if l.successes >= l.limit {
+			l.limit++
+			l.successes = 0
+		}
+	}
+
+	l.inFlight--
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
@@ -0,0 +1,64 @@
+package util
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_ThrottlesAfterRateLimit(t *testing.T) {
+	l := newRateLimiter(0)
+	ctx := context.Background()
+
+	// Eight requests in flight when one is rate limited
+	for i := 0; i < 8; i++ {
+		require.NoError(t, l.acquire(ctx))
+	}
+	l.release(true, 50*time.Millisecond)
+	assert.Equal(t, 4, l.limit)
+
+	// Further 429s from the same burst don't lower the limit again
+	l.release(true, 50*time.Millisecond)
+	assert.Equal(t, 4, l.limit)
+
+	// Nothing is sent until the Retry-After has passed and fewer than the
+	// limit are in flight
+	for i := 0; i < 3; i++ {
+		l.release(false, 0)
+	}
+	start := time.Now()
+	require.NoError(t, l.acquire(ctx))
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, l.acquire(short), context.DeadlineExceeded)
+
+	// Once as many requests as the limit went through, one more is allowed
+	l.release(false, 0)
+	assert.Equal(t, 5, l.limit)
+	require.NoError(t, l.acquire(ctx))
+	require.NoError(t, l.acquire(ctx))
+}
+
+func TestRateLimiter_RequestsPerSecond(t *testing.T) {
+	l := newRateLimiter(50)
+	ctx := context.Background()
+
+	// Requests are spaced 20ms apart however many may be in flight
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		require.NoError(t, l.acquire(ctx))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond)
+	assert.Equal(t, 4, l.inFlight)
+}
+
+func TestRateLimiter_Nil(t *testing.T) {
+	var l *rateLimiter
+	require.NoError(t, l.acquire(context.Background()))
+	l.release(true, time.Second)
+}
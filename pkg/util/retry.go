@@ -0,0 +1,154 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arctir/devgraph-cli/pkg/logging"
+)
+
+// retryBackoff is the delay before the first retry. Each further retry waits
+// twice as long, up to maxRetryBackoff. maxRetryAfter caps how long a
+// Retry-After header can make a request wait.
+var (
+	retryBackoff    = 500 * time.Millisecond
+	maxRetryBackoff = 8 * time.Second
+	maxRetryAfter   = time.Minute
+)
+
+// maxRateLimitRetries is how many times a rate limited request is sent
+// again. These don't count against the retries setting, since the server
+// asked for them.
+const maxRateLimitRetries = 10
+
+// retryTransport wraps an http.RoundTripper and retries requests that fail
+// transiently: dropped connections and 502, 503 and 504 responses. Requests
+// that aren't idempotent are only retried on 503, when the server is known
+// not to have handled them. Rate limited requests are retried after the
+// response's Retry-After, while limiter holds back the client's other
+// requests.
+type retryTransport struct {
+	transport http.RoundTripper
+	retries   int
+	limiter   *rateLimiter
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Buffer the body so it can be sent again
+	var body []byte
+	if req.Body != nil && req.GetBody == nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	delay := retryBackoff
+	attempt, rateLimited := 0, 0
+	for {
+		if err := t.limiter.acquire(req.Context()); err != nil {
+			return nil, err
+		}
+		resp, err := t.transport.RoundTrip(req)
+
+		wait := delay
+		if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+			wait = retryAfter(resp, delay)
+			t.limiter.release(true, wait)
+			if rateLimited >= maxRateLimitRetries {
+				return resp, err
+			}
+			rateLimited++
+			logging.Info("rate limited, waiting before retrying", "method", req.Method, "url", redactURL(req.URL), "attempt", rateLimited, "delay", wait)
+		} else {
+			t.limiter.release(false, 0)
+			if attempt >= t.retries || !shouldRetry(req, resp, err) {
+				return resp, err
+			}
+			attempt++
+			if resp != nil {
+				wait = retryAfter(resp, delay)
+				logging.Info("retrying request", "method", req.Method, "url", redactURL(req.URL), "status", resp.StatusCode, "attempt", attempt, "delay", wait)
+			} else {
+				logging.Info("retrying request", "method", req.Method, "url", redactURL(req.URL), "error", err, "attempt", attempt, "delay", wait)
+			}
+		}
+
+		// Don't wait past the request's timeout
+		if deadline, ok := req.Context().Deadline(); ok && time.Until(deadline) < wait {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+		delay = min(delay*2, maxRetryBackoff)
+
+		if req.GetBody != nil {
+			rewound, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = rewound
+		} else if body != nil {
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+	}
+}
+
+// retryAfter returns how long resp asks the client to wait before trying
+// again, or fallback when it doesn't say
+func retryAfter(resp *http.Response, fallback time.Duration) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		wait = time.Until(date)
+	} else {
+		return fallback
+	}
+	return max(0, min(wait, maxRetryAfter))
+}
+
+// shouldRetry reports whether a request that got resp or err is worth
+// sending again
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	idempotent := isIdempotent(req.Method)
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		return idempotent
+	}
+
+	switch resp.StatusCode {
+	case http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	}
+	return false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
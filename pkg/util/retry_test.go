@@ -0,0 +1,159 @@
+package util
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// retryServer responds with each status in turn, then 200, recording the
+// bodies it receives
+func retryServer(t *testing.T, statuses ...int) (*httptest.Server, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		status := http.StatusOK
+		if len(bodies) < len(statuses) {
+			status = statuses[len(bodies)]
+		}
+		bodies = append(bodies, string(body))
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &bodies
+}
+
+func fastRetries(t *testing.T) {
+	t.Helper()
+	oldBackoff, oldMax := retryBackoff, maxRetryBackoff
+	retryBackoff, maxRetryBackoff = time.Millisecond, 2*time.Millisecond
+	t.Cleanup(func() { retryBackoff, maxRetryBackoff = oldBackoff, oldMax })
+}
+
+func TestRetryTransport_RetriesTransientFailures(t *testing.T) {
+	fastRetries(t)
+	srv, bodies := retryServer(t, http.StatusServiceUnavailable, http.StatusBadGateway)
+
+	client := &http.Client{Transport: &retryTransport{transport: http.DefaultTransport, retries: 3}}
+	req, err := http.NewRequest(http.MethodPut, srv.URL+"/api/v1/entities", strings.NewReader(`{"name":"a"}`))
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{`{"name":"a"}`, `{"name":"a"}`, `{"name":"a"}`}, *bodies)
+}
+
+func TestRetryTransport_GivesUpAfterRetries(t *testing.T) {
+	fastRetries(t)
+	srv, bodies := retryServer(t, 503, 503, 503, 503)
+
+	client := &http.Client{Transport: &retryTransport{transport: http.DefaultTransport, retries: 2}}
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Len(t, *bodies, 3)
+}
+
+func TestRetryTransport_DoesNotRetryUnsafeRequests(t *testing.T) {
+	fastRetries(t)
+	srv, bodies := retryServer(t, http.StatusGatewayTimeout)
+
+	client := &http.Client{Transport: &retryTransport{transport: http.DefaultTransport, retries: 3}}
+	resp, err := client.Post(srv.URL, "application/json", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// The server may have handled the POST, so a 504 is returned as is
+	assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+	assert.Len(t, *bodies, 1)
+}
+
+func TestRetryTransport_DoesNotRetryClientErrors(t *testing.T) {
+	fastRetries(t)
+	srv, bodies := retryServer(t, http.StatusNotFound)
+
+	client := &http.Client{Transport: &retryTransport{transport: http.DefaultTransport, retries: 3}}
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Len(t, *bodies, 1)
+}
+
+func TestRetryTransport_HonorsRetryAfter(t *testing.T) {
+	fastRetries(t)
+	var mu sync.Mutex
+	var times []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		times = append(times, time.Now())
+		if len(times) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	// Rate limited POSTs are retried, even with retries disabled
+	client := &http.Client{Transport: &retryTransport{transport: http.DefaultTransport, limiter: newRateLimiter(0)}}
+	resp, err := client.Post(srv.URL, "application/json", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, times, 2)
+	assert.GreaterOrEqual(t, times[1].Sub(times[0]), time.Second)
+}
+
+func TestRetryTransport_GivesUpWhenRetryAfterExceedsTimeout(t *testing.T) {
+	fastRetries(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := &http.Client{
+		Transport: &retryTransport{transport: http.DefaultTransport, retries: 3, limiter: newRateLimiter(0)},
+		Timeout:   time.Second,
+	}
+	start := time.Now()
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRetryAfter(t *testing.T) {
+	header := func(value string) *http.Response {
+		return &http.Response{Header: http.Header{"Retry-After": []string{value}}}
+	}
+
+	assert.Equal(t, 5*time.Second, retryAfter(header("5"), time.Millisecond))
+	assert.Equal(t, time.Minute, retryAfter(header("3600"), time.Millisecond))
+	assert.Equal(t, time.Millisecond, retryAfter(header(""), time.Millisecond))
+	assert.Equal(t, time.Millisecond, retryAfter(header("soon"), time.Millisecond))
+
+	wait := retryAfter(header(time.Now().Add(10*time.Second).UTC().Format(http.TimeFormat)), time.Millisecond)
+	assert.InDelta(t, 10*time.Second, wait, float64(2*time.Second))
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package util
+
+// EnableVirtualTerminal reports whether the terminal shows ANSI escape
+// sequences. Terminals outside Windows always do.
+func EnableVirtualTerminal() bool {
+	return true
+}
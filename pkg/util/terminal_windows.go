@@ -0,0 +1,26 @@
+package util
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// EnableVirtualTerminal turns on ANSI escape sequences in the Windows
+// console for stdout and stderr, reporting whether the console supports
+// them. Consoles before Windows 10 don't, so colors should be turned off.
+func EnableVirtualTerminal() bool {
+	enabled := true
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		handle := windows.Handle(f.Fd())
+		var mode uint32
+		if err := windows.GetConsoleMode(handle, &mode); err != nil {
+			// Not a console, such as a pipe or file
+			continue
+		}
+		if err := windows.SetConsoleMode(handle, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING); err != nil {
+			enabled = false
+		}
+	}
+	return enabled
+}
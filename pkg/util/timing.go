@@ -0,0 +1,23 @@
+package util
+
+import (
+	"net/http"
+
+	"github.com/arctir/devgraph-cli/pkg/timing"
+)
+
+// timingTransport records the time spent on API requests for --timings.
+// Requests to other hosts, like the identity provider's, are part of
+// authentication and timed there.
+type timingTransport struct {
+	transport http.RoundTripper
+	apiHost   string
+}
+
+func (t *timingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.apiHost {
+		return t.transport.RoundTrip(req)
+	}
+	defer timing.Start(timing.API)()
+	return t.transport.RoundTrip(req)
+}
@@ -0,0 +1,39 @@
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/arctir/devgraph-cli/pkg/timing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimingTransport(t *testing.T) {
+	t.Cleanup(timing.Reset)
+	timing.Enable()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	apiURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: &timingTransport{transport: http.DefaultTransport, apiHost: apiURL.Host}}
+	for _, path := range []string{"/api/v1/entities", "/api/v1/tokens"} {
+		resp, err := client.Get(server.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	// Requests to other hosts aren't API requests
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer other.Close()
+	resp, err := client.Get(other.URL + "/.well-known/openid-configuration")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	_, count := timing.Total(timing.API)
+	assert.Equal(t, 2, count)
+}